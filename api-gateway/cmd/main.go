@@ -2,11 +2,8 @@ package main
 
 import (
 	"context"
-	"crypto/rsa"
-	"crypto/x509"
-	"encoding/pem"
+	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"os/signal"
@@ -25,16 +22,23 @@ import (
 	"github.com/v-egorov/service-boilerplate/common/logging"
 	commonMiddleware "github.com/v-egorov/service-boilerplate/common/middleware"
 	"github.com/v-egorov/service-boilerplate/common/tracing"
+	"golang.org/x/sync/singleflight"
 )
 
-// jwtKeyCache holds the cached JWT public key with TTL
+// jwtKeyCache holds the cached JWT verification keys, indexed by key ID, with TTL.
+// The mutex only guards the fields; key set fetches never run while it is held.
 type jwtKeyCache struct {
-	key       interface{}
-	fetchedAt time.Time
-	ttl       time.Duration
-	mutex     sync.RWMutex
+	keys                map[string]*commonMiddleware.VerificationKey
+	activeKeyID         string
+	fetchedAt           time.Time
+	unknownKeyRefreshAt time.Time // Last refresh attempt triggered by an unknown key ID
+	ttl                 time.Duration
+	mutex               sync.RWMutex
 }
 
+// minKeyRefreshInterval limits refreshes triggered by tokens with unknown key IDs
+const minKeyRefreshInterval = 30 * time.Second
+
 var (
 	globalKeyCache = &jwtKeyCache{
 		ttl: 1 * time.Hour, // Refresh key every hour
@@ -137,48 +141,37 @@ func main() {
 		// For now, no revocation checker when using configured key
 		revocationChecker = nil
 	} else {
-		// Try to fetch public key from auth-service (with caching)
+		// Try to fetch public keys from auth-service (with caching)
 		logger.Info("JWT public key not configured, attempting to fetch from auth-service")
-		publicKey, err := getCachedKey(authServiceURL, logger.Logger)
-		if err != nil {
-			logger.Warn("Failed to fetch public key from auth-service, attempting JWT_PUBLIC_KEY environment fallback", err)
+		if err := getCachedKeys(authServiceURL, logger.Logger); err != nil {
+			logger.Warn("Failed to fetch public keys from auth-service, attempting JWT_PUBLIC_KEY environment fallback", err)
 
 			// Try JWT_PUBLIC_KEY environment variable as fallback
 			if envKey := os.Getenv("JWT_PUBLIC_KEY"); envKey != "" {
 				logger.Info("Using JWT_PUBLIC_KEY environment variable as fallback")
-				// Parse the PEM-encoded public key from environment
-				block, _ := pem.Decode([]byte(envKey))
-				if block == nil {
-					logger.Warn("Failed to decode PEM block from JWT_PUBLIC_KEY environment variable")
+				// Parse the PEM-encoded public key (RSA, ECDSA P-256 or Ed25519) from environment;
+				// the middleware derives the accepted algorithm from the key type
+				envPublicKey, err := commonMiddleware.ParsePublicKeyPEM([]byte(envKey))
+				if err != nil {
+					logger.WithError(err).Warn("Failed to parse public key from JWT_PUBLIC_KEY environment variable")
 					jwtPublicKey = nil
-					revocationChecker = nil
 				} else {
-					envPublicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
-					if err != nil {
-						logger.WithError(err).Warn("Failed to parse public key from JWT_PUBLIC_KEY environment variable")
-						jwtPublicKey = nil
-						revocationChecker = nil
-					} else {
-						if rsaKey, ok := envPublicKey.(*rsa.PublicKey); ok {
-							jwtPublicKey = rsaKey
-							logger.Info("Successfully loaded JWT public key from JWT_PUBLIC_KEY environment variable")
-							// Note: No revocation checker for env-based keys
-							revocationChecker = nil
-						} else {
-							logger.Warn("JWT_PUBLIC_KEY environment variable contains non-RSA key")
-							jwtPublicKey = nil
-							revocationChecker = nil
-						}
-					}
+					jwtPublicKey = envPublicKey
+					logger.Info("Successfully loaded JWT public key from JWT_PUBLIC_KEY environment variable")
 				}
+				// Note: No revocation checker for env-based keys
+				revocationChecker = nil
 			} else {
 				logger.Warn("JWT_PUBLIC_KEY environment variable not set, JWT validation disabled")
 				jwtPublicKey = nil
 				revocationChecker = nil
 			}
 		} else {
-			jwtPublicKey = publicKey
-			logger.Info("Successfully fetched JWT public key from auth-service")
+			jwtPublicKey = &cachedKeyResolver{
+				authServiceURL: authServiceURL,
+				logger:         logger.Logger,
+			}
+			logger.Info("Successfully fetched JWT public keys from auth-service")
 
 			// Create HTTP-based revocation checker
 			revocationChecker = &httpTokenRevocationChecker{
@@ -266,6 +259,15 @@ func main() {
 				admin.DELETE("/users/:user_id/roles/:role_id", gatewayHandler.ProxyRequest("auth-service"))
 				admin.GET("/users/:user_id/roles", gatewayHandler.ProxyRequest("auth-service"))
 				admin.PUT("/users/:user_id/roles", gatewayHandler.ProxyRequest("auth-service"))
+
+				// Team-Role management; team members inherit these roles
+				admin.POST("/teams/:team_id/roles", gatewayHandler.ProxyRequest("auth-service"))
+				admin.DELETE("/teams/:team_id/roles/:role_id", gatewayHandler.ProxyRequest("auth-service"))
+				admin.GET("/teams/:team_id/roles", gatewayHandler.ProxyRequest("auth-service"))
+
+				// Audit trail
+				admin.GET("/audit-events", gatewayHandler.ProxyRequest("auth-service"))
+				admin.GET("/audit-events/verify", gatewayHandler.ProxyRequest("auth-service"))
 			}
 		}
 
@@ -278,6 +280,40 @@ func main() {
 			users.PATCH("/:id", gatewayHandler.ProxyRequest("user-service"))
 			users.DELETE("/:id", gatewayHandler.ProxyRequest("user-service"))
 			users.GET("", gatewayHandler.ProxyRequest("user-service"))
+			users.GET("/audit-events", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			users.GET("/audit-events/verify", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			users.POST("/import", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			users.GET("/export", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			users.GET("/me", gatewayHandler.ProxyRequest("user-service"))
+			users.PATCH("/me", gatewayHandler.ProxyRequest("user-service"))
+			users.POST("/me/email", gatewayHandler.ProxyRequest("user-service"))
+			users.POST("/me/email/verify", gatewayHandler.ProxyRequest("user-service"))
+			users.POST("/:id/suspend", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			users.POST("/:id/reactivate", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			users.POST("/:id/restore", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			users.POST("/:id/erase", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			users.GET("/me/memberships", gatewayHandler.ProxyRequest("user-service"))
+			users.GET("/:id/memberships", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+		}
+
+		// Organization and team routes; reads are open to authenticated users, changes need admin
+		organizations := api.Group("/v1/organizations")
+		{
+			organizations.GET("", gatewayHandler.ProxyRequest("user-service"))
+			organizations.GET("/:org_id", gatewayHandler.ProxyRequest("user-service"))
+			organizations.GET("/:org_id/members", gatewayHandler.ProxyRequest("user-service"))
+			organizations.GET("/:org_id/teams", gatewayHandler.ProxyRequest("user-service"))
+			organizations.GET("/:org_id/teams/:team_id", gatewayHandler.ProxyRequest("user-service"))
+			organizations.GET("/:org_id/teams/:team_id/members", gatewayHandler.ProxyRequest("user-service"))
+			organizations.POST("", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			organizations.PATCH("/:org_id", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			organizations.DELETE("/:org_id", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			organizations.POST("/:org_id/members", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			organizations.DELETE("/:org_id/members/:user_id", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			organizations.POST("/:org_id/teams", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			organizations.DELETE("/:org_id/teams/:team_id", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			organizations.POST("/:org_id/teams/:team_id/members", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
+			organizations.DELETE("/:org_id/teams/:team_id/members/:user_id", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("user-service"))
 		}
 
 		// Object Types service routes
@@ -297,6 +333,12 @@ func main() {
 			objectTypes.GET("/:id/path", gatewayHandler.ProxyRequest("objects-service"))
 			objectTypes.GET("/:id/subtree-count", gatewayHandler.ProxyRequest("objects-service"))
 			objectTypes.POST("/:id/validate-move", gatewayHandler.ProxyRequest("objects-service"))
+			objectTypes.POST("/:id/move", gatewayHandler.ProxyRequest("objects-service"))
+			objectTypes.GET("/:id/metadata-schema", gatewayHandler.ProxyRequest("objects-service"))
+			objectTypes.POST("/:id/metadata-schema/validate", gatewayHandler.ProxyRequest("objects-service"))
+			objectTypes.GET("/:id/attributes", gatewayHandler.ProxyRequest("objects-service"))
+			objectTypes.POST("/:id/attributes", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+			objectTypes.GET("/:id/attributes/ddl", gatewayHandler.ProxyRequest("objects-service"))
 		}
 
 		// Objects service routes
@@ -306,10 +348,16 @@ func main() {
 			objects.GET("", gatewayHandler.ProxyRequest("objects-service"))
 			objects.GET("/search", gatewayHandler.ProxyRequest("objects-service"))
 			objects.GET("/stats", gatewayHandler.ProxyRequest("objects-service"))
+			objects.POST("/aggregate", gatewayHandler.ProxyRequest("objects-service"))
+			objects.GET("/export", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+			objects.POST("/import", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
 			objects.GET("/:id", gatewayHandler.ProxyRequest("objects-service"))
 			objects.PUT("/:id", gatewayHandler.ProxyRequest("objects-service"))
 			objects.DELETE("/:id", gatewayHandler.ProxyRequest("objects-service"))
 			objects.GET("/public-id/:public_id", gatewayHandler.ProxyRequest("objects-service"))
+			objects.GET("/public-id/:public_id/graph", gatewayHandler.ProxyRequest("objects-service"))
+			objects.GET("/public-id/:public_id/graph/path/:target_public_id", gatewayHandler.ProxyRequest("objects-service"))
+			objects.GET("/public-id/:public_id/reachable/:type_key", gatewayHandler.ProxyRequest("objects-service"))
 			objects.GET("/name/:name", gatewayHandler.ProxyRequest("objects-service"))
 			objects.GET("/:id/children", gatewayHandler.ProxyRequest("objects-service"))
 			objects.GET("/:id/descendants", gatewayHandler.ProxyRequest("objects-service"))
@@ -318,9 +366,20 @@ func main() {
 			objects.PUT("/:id/metadata", gatewayHandler.ProxyRequest("objects-service"))
 			objects.POST("/:id/tags", gatewayHandler.ProxyRequest("objects-service"))
 			objects.DELETE("/:id/tags", gatewayHandler.ProxyRequest("objects-service"))
+			objects.GET("/:id/versions", gatewayHandler.ProxyRequest("objects-service"))
+			objects.GET("/:id/versions/diff", gatewayHandler.ProxyRequest("objects-service"))
+			objects.GET("/:id/versions/:revision", gatewayHandler.ProxyRequest("objects-service"))
+			objects.POST("/:id/versions/:revision/restore", gatewayHandler.ProxyRequest("objects-service"))
+			objects.POST("/:id/move", gatewayHandler.ProxyRequest("objects-service"))
+			objects.POST("/:id/clone", gatewayHandler.ProxyRequest("objects-service"))
+			objects.GET("/trash", gatewayHandler.ProxyRequest("objects-service"))
+			objects.POST("/:id/restore", gatewayHandler.ProxyRequest("objects-service"))
+			objects.DELETE("/:id/purge", gatewayHandler.ProxyRequest("objects-service"))
 			objects.POST("/bulk", gatewayHandler.ProxyRequest("objects-service"))
 			objects.PUT("/bulk", gatewayHandler.ProxyRequest("objects-service"))
 			objects.DELETE("/bulk", gatewayHandler.ProxyRequest("objects-service"))
+			objects.GET("/audit-events", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+			objects.GET("/audit-events/verify", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
 		}
 
 		// Relationship Types service routes
@@ -331,6 +390,7 @@ func main() {
 			relationshipTypes.GET("/:type_key", gatewayHandler.ProxyRequest("objects-service"))
 			relationshipTypes.PUT("/:type_key", gatewayHandler.ProxyRequest("objects-service"))
 			relationshipTypes.DELETE("/:type_key", gatewayHandler.ProxyRequest("objects-service"))
+			relationshipTypes.POST("/:type_key/validation-rules/validate", gatewayHandler.ProxyRequest("objects-service"))
 		}
 
 		// Relationships service routes
@@ -338,10 +398,37 @@ func main() {
 		{
 			relationships.POST("", gatewayHandler.ProxyRequest("objects-service"))
 			relationships.GET("", gatewayHandler.ProxyRequest("objects-service"))
+			relationships.GET("/export", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+			relationships.POST("/import", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
 			relationships.GET("/:public_id", gatewayHandler.ProxyRequest("objects-service"))
 			relationships.PUT("/:public_id", gatewayHandler.ProxyRequest("objects-service"))
 			relationships.DELETE("/:public_id", gatewayHandler.ProxyRequest("objects-service"))
 		}
+
+		// Domain event log service routes
+		events := api.Group("/v1/events")
+		{
+			events.GET("", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+		}
+
+		// Webhook subscription service routes
+		webhooks := api.Group("/v1/webhooks")
+		{
+			webhooks.POST("", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+			webhooks.GET("", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+			webhooks.GET("/:id", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+			webhooks.PUT("/:id", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+			webhooks.DELETE("/:id", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+			webhooks.GET("/:id/deliveries", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+			webhooks.POST("/:id/deliveries/:delivery_id/replay", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+		}
+
+		// Taxonomy bundle service routes
+		taxonomy := api.Group("/v1/taxonomy")
+		{
+			taxonomy.GET("/export", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+			taxonomy.POST("/import", commonMiddleware.RequireRole("admin"), gatewayHandler.ProxyRequest("objects-service"))
+		}
 	}
 
 	// Start server
@@ -422,7 +509,62 @@ func checkAuthServiceHealth(authServiceURL string, logger *logrus.Logger) error
 	return nil
 }
 
-func fetchPublicKeyFromAuthService(authServiceURL string, logger *logrus.Logger) (interface{}, error) {
+// publicKeySet is the response of the auth-service /public-keys endpoint
+type publicKeySet struct {
+	Keys []struct {
+		KeyID        string `json:"kid"`
+		Algorithm    string `json:"alg"`
+		PublicKeyPEM string `json:"public_key"`
+		Active       bool   `json:"active"`
+	} `json:"keys"`
+}
+
+// fetchPublicKeysOnce performs a single request for the auth-service verification key set
+func fetchPublicKeysOnce(authServiceURL string) (map[string]*commonMiddleware.VerificationKey, string, error) {
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(authServiceURL + "/public-keys")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch public keys: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("auth-service returned status %d for public keys", resp.StatusCode)
+	}
+
+	var keySet publicKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&keySet); err != nil {
+		return nil, "", fmt.Errorf("failed to decode public keys response: %w", err)
+	}
+
+	keys := make(map[string]*commonMiddleware.VerificationKey, len(keySet.Keys))
+	activeKeyID := ""
+	for _, k := range keySet.Keys {
+		if !commonMiddleware.IsSupportedAlgorithm(k.Algorithm) {
+			return nil, "", fmt.Errorf("key %s uses unsupported algorithm %q", k.KeyID, k.Algorithm)
+		}
+		publicKey, err := commonMiddleware.ParsePublicKeyPEM([]byte(k.PublicKeyPEM))
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse public key %s: %w", k.KeyID, err)
+		}
+		keys[k.KeyID] = &commonMiddleware.VerificationKey{
+			KeyID:     k.KeyID,
+			Algorithm: k.Algorithm,
+			PublicKey: publicKey,
+		}
+		if k.Active {
+			activeKeyID = k.KeyID
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, "", fmt.Errorf("auth-service returned no public keys")
+	}
+
+	return keys, activeKeyID, nil
+}
+
+func fetchPublicKeysFromAuthService(authServiceURL string, logger *logrus.Logger) (map[string]*commonMiddleware.VerificationKey, string, error) {
 	const maxRetries = 10
 	const initialDelay = time.Second
 	const maxDelay = 30 * time.Second
@@ -432,9 +574,10 @@ func fetchPublicKeyFromAuthService(authServiceURL string, logger *logrus.Logger)
 	// First, check if auth-service is healthy
 	if err := checkAuthServiceHealth(authServiceURL, logger); err != nil {
 		logger.WithError(err).Warn("Skipping JWT public key fetch due to auth-service health check failure")
-		return nil, fmt.Errorf("auth-service health check failed: %w", err)
+		return nil, "", fmt.Errorf("auth-service health check failed: %w", err)
 	}
 
+	var lastErr error
 	for attempt := 0; attempt < maxRetries; attempt++ {
 		startTime := time.Now()
 
@@ -455,136 +598,124 @@ func fetchPublicKeyFromAuthService(authServiceURL string, logger *logrus.Logger)
 		logger.WithFields(logrus.Fields{
 			"attempt":     attempt + 1,
 			"max_retries": maxRetries,
-		}).Debug("Attempting to fetch JWT public key from auth-service")
-
-		// Fetch the public key from auth-service
-		resp, err := http.Get(authServiceURL + "/public-key")
-		if err != nil {
-			duration := time.Since(startTime)
-			logger.WithError(err).WithFields(logrus.Fields{
-				"attempt":  attempt + 1,
-				"duration": duration.String(),
-			}).Warn("Failed to fetch public key from auth-service")
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("failed to fetch public key after %d attempts: %w", maxRetries, err)
-			}
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			duration := time.Since(startTime)
-			logger.WithFields(logrus.Fields{
-				"attempt":  attempt + 1,
-				"status":   resp.StatusCode,
-				"duration": duration.String(),
-			}).Warn("Auth-service returned non-200 status for public key")
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("auth-service returned status %d after %d attempts", resp.StatusCode, maxRetries)
-			}
-			continue
-		}
+		}).Debug("Attempting to fetch JWT public keys from auth-service")
 
-		// Read the PEM-encoded public key
-		pemData, err := io.ReadAll(resp.Body)
+		keys, activeKeyID, err := fetchPublicKeysOnce(authServiceURL)
 		if err != nil {
-			duration := time.Since(startTime)
+			lastErr = err
 			logger.WithError(err).WithFields(logrus.Fields{
 				"attempt":  attempt + 1,
-				"duration": duration.String(),
-			}).Warn("Failed to read public key response")
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("failed to read public key response after %d attempts: %w", maxRetries, err)
-			}
-			continue
-		}
-
-		// Parse the PEM-encoded public key
-		block, _ := pem.Decode(pemData)
-		if block == nil {
-			duration := time.Since(startTime)
-			logger.WithFields(logrus.Fields{
-				"attempt":  attempt + 1,
-				"duration": duration.String(),
-			}).Warn("Failed to decode PEM block")
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("invalid PEM data after %d attempts", maxRetries)
-			}
-			continue
-		}
-
-		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
-		if err != nil {
-			duration := time.Since(startTime)
-			logger.WithError(err).WithFields(logrus.Fields{
-				"attempt":  attempt + 1,
-				"duration": duration.String(),
-			}).Warn("Failed to parse public key")
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("failed to parse public key after %d attempts: %w", maxRetries, err)
-			}
+				"duration": time.Since(startTime).String(),
+			}).Warn("Failed to fetch public keys from auth-service")
 			continue
 		}
 
-		rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
-		if !ok {
-			duration := time.Since(startTime)
-			logger.WithFields(logrus.Fields{
-				"attempt":  attempt + 1,
-				"duration": duration.String(),
-			}).Warn("Public key is not RSA")
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("public key is not RSA after %d attempts", maxRetries)
-			}
-			continue
-		}
-
-		duration := time.Since(startTime)
 		logger.WithFields(logrus.Fields{
-			"attempt":  attempt + 1,
-			"duration": duration.String(),
-		}).Info("Successfully fetched and parsed JWT public key from auth-service")
-		return rsaPublicKey, nil
+			"attempt":       attempt + 1,
+			"duration":      time.Since(startTime).String(),
+			"key_count":     len(keys),
+			"active_key_id": activeKeyID,
+		}).Info("Successfully fetched and parsed JWT public keys from auth-service")
+		return keys, activeKeyID, nil
 	}
 
-	// This should never be reached, but just in case
-	return nil, fmt.Errorf("unexpected error: exceeded max retries without proper error handling")
+	return nil, "", fmt.Errorf("failed to fetch public keys after %d attempts: %w", maxRetries, lastErr)
 }
 
-// getCachedKey returns the cached key if it's still valid, otherwise refreshes it
-func getCachedKey(authServiceURL string, logger *logrus.Logger) (interface{}, error) {
+// getCachedKeys returns the cached key set if it's still valid, otherwise refreshes it
+func getCachedKeys(authServiceURL string, logger *logrus.Logger) error {
 	globalKeyCache.mutex.RLock()
-	if globalKeyCache.key != nil && time.Since(globalKeyCache.fetchedAt) < globalKeyCache.ttl {
-		key := globalKeyCache.key
+	if globalKeyCache.keys != nil && time.Since(globalKeyCache.fetchedAt) < globalKeyCache.ttl {
 		globalKeyCache.mutex.RUnlock()
-		return key, nil
+		return nil
 	}
 	globalKeyCache.mutex.RUnlock()
 
-	// Key is expired or missing, refresh it
+	// Key set is expired or missing, refresh it. The fetch retries for minutes when
+	// auth-service is down, so it runs without the lock and token verification keeps
+	// using the expired keys meanwhile.
+	logger.Info("Refreshing JWT public key cache")
+	keys, activeKeyID, err := fetchPublicKeysFromAuthService(authServiceURL, logger)
+
 	globalKeyCache.mutex.Lock()
 	defer globalKeyCache.mutex.Unlock()
 
-	// Double-check after acquiring write lock
-	if globalKeyCache.key != nil && time.Since(globalKeyCache.fetchedAt) < globalKeyCache.ttl {
-		return globalKeyCache.key, nil
-	}
-
-	logger.Info("Refreshing JWT public key cache")
-	key, err := fetchPublicKeyFromAuthService(authServiceURL, logger)
 	if err != nil {
-		// If fetch fails, try to use existing key if available (better than nothing)
-		if globalKeyCache.key != nil {
-			logger.WithError(err).Warn("Failed to refresh JWT key, using expired cached key")
-			return globalKeyCache.key, nil
+		// If fetch fails, keep using the existing keys if available (better than nothing)
+		if globalKeyCache.keys != nil {
+			logger.WithError(err).Warn("Failed to refresh JWT keys, using expired cached keys")
+			return nil
 		}
-		return nil, err
+		return err
 	}
 
-	globalKeyCache.key = key
+	globalKeyCache.keys = keys
+	globalKeyCache.activeKeyID = activeKeyID
 	globalKeyCache.fetchedAt = time.Now()
 	logger.Info("Successfully refreshed JWT public key cache")
-	return key, nil
+	return nil
+}
+
+// cachedKeyResolver resolves token verification keys from the gateway key cache.
+// Tokens signed with a key that is not cached yet (e.g. right after rotation) trigger
+// a single, rate-limited refresh instead of being rejected until the next TTL refresh.
+type cachedKeyResolver struct {
+	authServiceURL string
+	logger         *logrus.Logger
+	refreshes      singleflight.Group
+}
+
+func (r *cachedKeyResolver) ResolveVerificationKey(keyID string) (*commonMiddleware.VerificationKey, error) {
+	if key := lookupCachedKey(keyID); key != nil {
+		return key, nil
+	}
+
+	// Concurrent requests with unknown key IDs share one in-flight refresh and wait
+	// for it, while requests with cached keys keep resolving them without blocking
+	r.refreshes.Do("unknown-key", func() (interface{}, error) {
+		r.refreshForUnknownKey()
+		return nil, nil
+	})
+
+	if key := lookupCachedKey(keyID); key != nil {
+		return key, nil
+	}
+	return nil, commonMiddleware.ErrUnknownKeyID
+}
+
+// refreshForUnknownKey refetches the key set at most once per minKeyRefreshInterval
+func (r *cachedKeyResolver) refreshForUnknownKey() {
+	globalKeyCache.mutex.Lock()
+	if time.Since(globalKeyCache.unknownKeyRefreshAt) < minKeyRefreshInterval {
+		globalKeyCache.mutex.Unlock()
+		return
+	}
+	// Rate-limit refreshes even on failure so bad tokens can't hammer auth-service
+	globalKeyCache.unknownKeyRefreshAt = time.Now()
+	globalKeyCache.mutex.Unlock()
+
+	keys, activeKeyID, err := fetchPublicKeysOnce(r.authServiceURL)
+	if err != nil {
+		r.logger.WithError(err).Warn("Failed to refresh JWT keys for unknown key ID")
+		return
+	}
+
+	globalKeyCache.mutex.Lock()
+	globalKeyCache.keys = keys
+	globalKeyCache.activeKeyID = activeKeyID
+	globalKeyCache.fetchedAt = time.Now()
+	globalKeyCache.mutex.Unlock()
+}
+
+// lookupCachedKey returns the cached key for keyID; an empty keyID selects the active key
+func lookupCachedKey(keyID string) *commonMiddleware.VerificationKey {
+	globalKeyCache.mutex.RLock()
+	defer globalKeyCache.mutex.RUnlock()
+
+	if keyID == "" {
+		keyID = globalKeyCache.activeKeyID
+	}
+	return globalKeyCache.keys[keyID]
 }
 
 // startKeyRefreshRoutine starts a background goroutine to periodically refresh the key
@@ -595,8 +726,7 @@ func startKeyRefreshRoutine(authServiceURL string, logger *logrus.Logger) {
 
 		for range ticker.C {
 			logger.Debug("Periodic JWT key refresh check")
-			_, err := getCachedKey(authServiceURL, logger)
-			if err != nil {
+			if err := getCachedKeys(authServiceURL, logger); err != nil {
 				logger.WithError(err).Warn("Periodic JWT key refresh failed")
 			}
 		}
@@ -115,8 +115,8 @@ func (h *GatewayHandler) ProxyRequest(serviceName string) gin.HandlerFunc {
 			// Inject trace context headers
 			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
-			// Read request body
-			if req.Body != nil {
+			// Read request body; streamed record imports are passed through as they arrive
+			if req.Body != nil && !isRecordStream(req) {
 				bodyBytes, err := io.ReadAll(req.Body)
 				if err != nil {
 					h.logger.WithError(err).Error("Failed to read request body")
@@ -137,6 +137,13 @@ func (h *GatewayHandler) ProxyRequest(serviceName string) gin.HandlerFunc {
 	}
 }
 
+// isRecordStream reports whether a request body is an NDJSON or CSV stream of records, which
+// can be too large to hold in memory
+func isRecordStream(req *http.Request) bool {
+	contentType := req.Header.Get("Content-Type")
+	return strings.HasPrefix(contentType, "application/x-ndjson") || strings.HasPrefix(contentType, "text/csv")
+}
+
 // LivenessHandler provides basic liveness check
 func (h *GatewayHandler) LivenessHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
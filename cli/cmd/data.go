@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
 )
 
 // newDataCmd creates the data command
@@ -22,6 +26,7 @@ func newDataCmd() *cobra.Command {
 		newDataExportCmd(),
 		newDataValidateCmd(),
 		newDataCleanupCmd(),
+		newDataTaxonomyCmd(),
 	)
 
 	return cmd
@@ -117,26 +122,12 @@ func newDataExportCmd() *cobra.Command {
 
 			// For user-service, we can export users
 			if service == "user-service" && table == "users" {
-				// Make API call to get all users
-				resp, err := apiClient.CallService("user-service", "GET", "/api/v1/users?limit=1000", nil, nil)
+				// Page through every user matching the filters
+				users, err := fetchAllUsers(userFilterQuery(cmd), userAuthHeaders(cmd))
 				if err != nil {
 					return fmt.Errorf("failed to export users: %w", err)
 				}
 
-				if resp.StatusCode != http.StatusOK {
-					return fmt.Errorf("API call failed with status %d: %s", resp.StatusCode, resp.Error)
-				}
-
-				// Extract users from response
-				var users []interface{}
-				if response, ok := resp.Body.(map[string]interface{}); ok {
-					if usersData, exists := response["users"]; exists {
-						if userList, ok := usersData.([]interface{}); ok {
-							users = userList
-						}
-					}
-				}
-
 				if jsonOut {
 					result := map[string]interface{}{
 						"service": service,
@@ -195,6 +186,7 @@ func newDataExportCmd() *cobra.Command {
 
 	cmd.Flags().StringP("format", "f", "json", "Export format (json, csv)")
 	cmd.Flags().StringP("output", "o", "", "Output file path")
+	addUserFilterFlags(cmd)
 
 	return cmd
 }
@@ -219,25 +211,11 @@ func newDataValidateCmd() *cobra.Command {
 				}
 
 				// Get all users to validate
-				resp, err := apiClient.CallService("user-service", "GET", "/api/v1/users?limit=1000", nil, nil)
+				users, err := fetchAllUsers(url.Values{}, nil)
 				if err != nil {
 					return fmt.Errorf("failed to fetch users for validation: %w", err)
 				}
 
-				if resp.StatusCode != http.StatusOK {
-					return fmt.Errorf("API call failed with status %d: %s", resp.StatusCode, resp.Error)
-				}
-
-				// Extract and validate users
-				var users []interface{}
-				if response, ok := resp.Body.(map[string]interface{}); ok {
-					if usersData, exists := response["users"]; exists {
-						if userList, ok := usersData.([]interface{}); ok {
-							users = userList
-						}
-					}
-				}
-
 				if jsonOut {
 					result := map[string]interface{}{
 						"service":     service,
@@ -352,3 +330,196 @@ func newDataCleanupCmd() *cobra.Command {
 
 	return cmd
 }
+
+func newDataTaxonomyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "taxonomy",
+		Short: "Export and import the objects-service taxonomy",
+		Long:  `Export object types and relationship types as a bundle, and import a bundle as a reviewed plan.`,
+	}
+
+	cmd.AddCommand(
+		newDataTaxonomyExportCmd(),
+		newDataTaxonomyImportCmd(),
+	)
+
+	return cmd
+}
+
+func newDataTaxonomyExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the taxonomy as a bundle",
+		Long:  `Export the object type tree and relationship types as a YAML or JSON bundle.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			output, _ := cmd.Flags().GetString("output")
+
+			if format != "yaml" && format != "json" {
+				return fmt.Errorf("unknown format: %s (available: yaml, json)", format)
+			}
+
+			query := url.Values{}
+			query.Set("format", format)
+			resp, err := apiClient.CallService("objects-service", "GET", "/api/v1/taxonomy/export?"+query.Encode(), nil, userAuthHeaders(cmd))
+			if err != nil {
+				return fmt.Errorf("failed to export taxonomy: %w", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("API call failed with status %d: %s", resp.StatusCode, resp.Error)
+			}
+
+			// YAML bundles come back as text, JSON bundles as a decoded document
+			var bundle []byte
+			if text, ok := resp.Body.(string); ok {
+				bundle = []byte(text)
+			} else {
+				bundle, err = json.MarshalIndent(resp.Body, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to encode bundle: %w", err)
+				}
+				bundle = append(bundle, '\n')
+			}
+
+			if output == "" {
+				cmd.Print(string(bundle))
+				return nil
+			}
+
+			if err := os.WriteFile(output, bundle, 0o644); err != nil {
+				return fmt.Errorf("failed to write bundle: %w", err)
+			}
+			if jsonOut {
+				return printJSON(map[string]interface{}{
+					"format": format,
+					"file":   output,
+				})
+			}
+			cmd.Printf("✅ Taxonomy exported to %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("format", "f", "yaml", "Bundle format (yaml, json)")
+	cmd.Flags().StringP("output", "o", "", "Output file path (defaults to stdout)")
+	cmd.Flags().String("token", "", "Bearer token of an admin, forwarded to the objects service")
+
+	return cmd
+}
+
+func newDataTaxonomyImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import <bundle>",
+		Short: "Import a taxonomy bundle",
+		Long: `Compare a YAML or JSON bundle with the live taxonomy and show the plan.
+The plan is only applied with --apply; types missing from the bundle are deleted only with --prune.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file := args[0]
+			apply, _ := cmd.Flags().GetBool("apply")
+			prune, _ := cmd.Flags().GetBool("prune")
+
+			bundle, err := readTaxonomyBundle(file)
+			if err != nil {
+				return err
+			}
+
+			query := url.Values{}
+			query.Set("dry_run", fmt.Sprintf("%t", !apply))
+			query.Set("prune", fmt.Sprintf("%t", prune))
+			resp, err := apiClient.CallService("objects-service", "POST", "/api/v1/taxonomy/import?"+query.Encode(), bundle, userAuthHeaders(cmd))
+			if err != nil {
+				return fmt.Errorf("failed to import taxonomy: %w", err)
+			}
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("API call failed with status %d: %s", resp.StatusCode, resp.Error)
+			}
+
+			plan := map[string]interface{}{}
+			if response, ok := resp.Body.(map[string]interface{}); ok {
+				if data, ok := response["data"].(map[string]interface{}); ok {
+					plan = data
+				}
+			}
+
+			if jsonOut {
+				return printJSON(plan)
+			}
+
+			changes, _ := plan["changes"].([]interface{})
+			if len(changes) == 0 {
+				cmd.Println("✅ Taxonomy is up to date")
+				return nil
+			}
+
+			cmd.Println("📋 Taxonomy Plan:")
+			cmd.Println("=================")
+			for _, change := range changes {
+				if c, ok := change.(map[string]interface{}); ok {
+					cmd.Printf("• %s\n", describeTaxonomyChange(c))
+				}
+			}
+			cmd.Println("=================")
+
+			if applied, _ := plan["applied"].(bool); applied {
+				cmd.Printf("✅ Applied %d changes\n", len(changes))
+			} else {
+				cmd.Printf("💡 %d changes planned; run again with --apply to apply them\n", len(changes))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("apply", false, "Apply the plan instead of only showing it")
+	cmd.Flags().Bool("prune", false, "Delete types that are missing from the bundle")
+	cmd.Flags().String("token", "", "Bearer token of an admin, forwarded to the objects service")
+
+	return cmd
+}
+
+// readTaxonomyBundle reads a bundle file, decoding YAML files so the bundle can be sent as JSON
+func readTaxonomyBundle(file string) (interface{}, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	var bundle map[string]interface{}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &bundle)
+	default:
+		err = json.Unmarshal(data, &bundle)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bundle %s: %w", file, err)
+	}
+	return bundle, nil
+}
+
+// describeTaxonomyChange formats one plan step for the console
+func describeTaxonomyChange(change map[string]interface{}) string {
+	line := fmt.Sprintf("%s %s %v", change["action"], change["kind"], change["key"])
+
+	if change["action"] == "move" {
+		from, _ := change["from_parent"].(string)
+		to, _ := change["to_parent"].(string)
+		line += fmt.Sprintf(" (%s → %s)", taxonomyParentName(from), taxonomyParentName(to))
+	}
+	if fields, ok := change["fields"].([]interface{}); ok && len(fields) > 0 {
+		names := make([]string, 0, len(fields))
+		for _, field := range fields {
+			names = append(names, fmt.Sprint(field))
+		}
+		line += " [" + strings.Join(names, ", ") + "]"
+	}
+	return line
+}
+
+func taxonomyParentName(name string) string {
+	if name == "" {
+		return "root"
+	}
+	return name
+}
@@ -3,6 +3,8 @@ package cmd
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/spf13/cobra"
 	"github.com/v-egorov/service-boilerplate/cli/internal/workflows"
@@ -182,17 +184,28 @@ func newOpsUserCreateCmd() *cobra.Command {
 func newOpsUserListCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
-		Short: "List users with pagination",
-		Long:  `List users from the user service with optional pagination.`,
+		Short: "List users with filters and pagination",
+		Long: `List users from the user service.
+
+Filter by email or name prefix, status, role and creation time, sort by any listed field,
+and page either with --offset or with the --cursor printed after each page.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			limit, _ := cmd.Flags().GetInt("limit")
 			offset, _ := cmd.Flags().GetInt("offset")
+			cursor, _ := cmd.Flags().GetString("cursor")
 
 			// Build query parameters
-			endpoint := fmt.Sprintf("/api/v1/users?limit=%d&offset=%d", limit, offset)
+			query := userFilterQuery(cmd)
+			query.Set("limit", strconv.Itoa(limit))
+			if cursor != "" {
+				query.Set("cursor", cursor)
+			} else {
+				query.Set("offset", strconv.Itoa(offset))
+			}
+			endpoint := "/api/v1/users?" + query.Encode()
 
 			// Make API call to user service
-			resp, err := apiClient.CallService("user-service", "GET", endpoint, nil, nil)
+			resp, err := apiClient.CallService("user-service", "GET", endpoint, nil, userAuthHeaders(cmd))
 			if err != nil {
 				return fmt.Errorf("failed to list users: %w", err)
 			}
@@ -202,31 +215,29 @@ func newOpsUserListCmd() *cobra.Command {
 			}
 
 			if resp.StatusCode == http.StatusOK {
-				if response, ok := resp.Body.(map[string]interface{}); ok {
-					if users, exists := response["users"]; exists {
-						if userList, ok := users.([]interface{}); ok {
-							cmd.Printf("📋 Users (showing %d, offset %d):\n", limit, offset)
-							cmd.Println("=====================================")
-
-							for i, user := range userList {
-								if userMap, ok := user.(map[string]interface{}); ok {
-									id := userMap["id"]
-									email := userMap["email"]
-									firstName := userMap["first_name"]
-									lastName := userMap["last_name"]
-
-									cmd.Printf("%d. %v - %s %s (%s)\n",
-										offset+i+1, id, firstName, lastName, email)
-								}
-							}
-
-							// Show pagination info
-							if len(userList) == limit {
-								cmd.Printf("\n💡 Use --offset %d to see more users\n", offset+limit)
-							}
-						}
+				userList, pagination := parseUserListResponse(resp.Body)
+				total := pagination["total"]
+
+				cmd.Printf("📋 Users (showing %d of %v, offset %d):\n", len(userList), total, offset)
+				cmd.Println("=====================================")
+
+				for i, user := range userList {
+					if userMap, ok := user.(map[string]interface{}); ok {
+						id := userMap["id"]
+						email := userMap["email"]
+						firstName := userMap["first_name"]
+						lastName := userMap["last_name"]
+						status := userMap["status"]
+
+						cmd.Printf("%d. %v - %s %s (%s) [%v]\n",
+							offset+i+1, id, firstName, lastName, email, status)
 					}
 				}
+
+				// Show pagination info
+				if next, ok := pagination["next_cursor"].(string); ok && next != "" {
+					cmd.Printf("\n💡 Use --cursor %s to see more users\n", next)
+				}
 			} else {
 				cmd.Printf("❌ Failed to list users (Status: %d)\n", resp.StatusCode)
 				if resp.Error != "" {
@@ -238,12 +249,99 @@ func newOpsUserListCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().IntP("limit", "l", 10, "Number of users to retrieve")
+	cmd.Flags().IntP("limit", "l", 10, "Number of users to retrieve (max 100)")
 	cmd.Flags().IntP("offset", "o", 0, "Offset for pagination")
+	cmd.Flags().String("cursor", "", "Cursor from a previous page (replaces --offset)")
+	addUserFilterFlags(cmd)
 
 	return cmd
 }
 
+// addUserFilterFlags registers the user listing filters shared by list, export and validate
+func addUserFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("email", "", "Filter by email prefix")
+	cmd.Flags().String("name", "", "Filter by first or last name prefix")
+	cmd.Flags().String("status", "", "Filter by account status (pending, active, suspended, deactivated)")
+	cmd.Flags().String("role", "", "Filter by role name (requires --token of an admin)")
+	cmd.Flags().String("created-after", "", "Only users created at or after this RFC 3339 time")
+	cmd.Flags().String("created-before", "", "Only users created before this RFC 3339 time")
+	cmd.Flags().String("sort", "", "Sort field (created_at, updated_at, email, first_name, last_name)")
+	cmd.Flags().String("order", "", "Sort order (asc, desc)")
+	cmd.Flags().String("token", "", "Bearer token forwarded to the user service")
+}
+
+// userFilterQuery converts the filter flags into listing query parameters
+func userFilterQuery(cmd *cobra.Command) url.Values {
+	query := url.Values{}
+	for flag, param := range map[string]string{
+		"email":          "email",
+		"name":           "name",
+		"status":         "status",
+		"role":           "role",
+		"created-after":  "created_after",
+		"created-before": "created_before",
+		"sort":           "sort",
+		"order":          "order",
+	} {
+		if value, _ := cmd.Flags().GetString(flag); value != "" {
+			query.Set(param, value)
+		}
+	}
+	return query
+}
+
+// userAuthHeaders returns the Authorization header for --token, if given
+func userAuthHeaders(cmd *cobra.Command) map[string]string {
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+// parseUserListResponse extracts the users and pagination block of a listing response
+func parseUserListResponse(body interface{}) ([]interface{}, map[string]interface{}) {
+	var users []interface{}
+	pagination := map[string]interface{}{}
+
+	if response, ok := body.(map[string]interface{}); ok {
+		if userList, ok := response["data"].([]interface{}); ok {
+			users = userList
+		}
+		if p, ok := response["pagination"].(map[string]interface{}); ok {
+			pagination = p
+		}
+	}
+
+	return users, pagination
+}
+
+// fetchAllUsers pages through the user listing with cursors until every matching user is fetched
+func fetchAllUsers(query url.Values, headers map[string]string) ([]interface{}, error) {
+	var all []interface{}
+	query.Set("limit", "100")
+
+	for {
+		resp, err := apiClient.CallService("user-service", "GET", "/api/v1/users?"+query.Encode(), nil, headers)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("API call failed with status %d: %s", resp.StatusCode, resp.Error)
+		}
+
+		users, pagination := parseUserListResponse(resp.Body)
+		all = append(all, users...)
+
+		next, _ := pagination["next_cursor"].(string)
+		if next == "" {
+			return all, nil
+		}
+		query.Set("cursor", next)
+	}
+}
+
 func newOpsUserUpdateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "update <id> <email> <first_name> <last_name>",
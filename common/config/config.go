@@ -17,6 +17,12 @@ type Config struct {
 	JWT             JWTConfig             `mapstructure:"jwt"`
 	PermissionCache PermissionCacheConfig `mapstructure:"permission_cache"`
 	AuthService     AuthServiceConfig     `mapstructure:"auth_service"`
+	Audit           AuditConfig           `mapstructure:"audit"`
+	Outbox          OutboxConfig          `mapstructure:"outbox"`
+	Webhooks        WebhookConfig         `mapstructure:"webhooks"`
+	Trash           TrashConfig           `mapstructure:"trash"`
+	UserLifecycle   UserLifecycleConfig   `mapstructure:"user_lifecycle"`
+	Email           EmailConfig           `mapstructure:"email"`
 }
 
 type AppConfig struct {
@@ -72,7 +78,9 @@ type TracingConfig struct {
 }
 
 type JWTConfig struct {
-	PublicKey string `mapstructure:"public_key"`
+	PublicKey         string `mapstructure:"public_key"`
+	SigningAlgorithm  string `mapstructure:"signing_algorithm"`   // RS256, ES256 or EdDSA
+	KeyOverlapMinutes int    `mapstructure:"key_overlap_minutes"` // How long retired keys still verify tokens
 }
 
 type PermissionCacheConfig struct {
@@ -85,6 +93,50 @@ type AuthServiceConfig struct {
 	Timeout int    `mapstructure:"timeout_seconds"`
 }
 
+type AuditConfig struct {
+	Enabled         bool `mapstructure:"enabled"`           // Persist audit events to the database
+	BufferSize      int  `mapstructure:"buffer_size"`       // Events queued before new events are dropped
+	BatchSize       int  `mapstructure:"batch_size"`        // Events written per database round trip
+	FlushIntervalMs int  `mapstructure:"flush_interval_ms"` // Maximum delay before queued events are written
+}
+
+type OutboxConfig struct {
+	DispatchEnabled bool `mapstructure:"dispatch_enabled"` // Deliver outbox events to sinks in the background
+	BatchSize       int  `mapstructure:"batch_size"`       // Events delivered per transaction
+	PollIntervalMs  int  `mapstructure:"poll_interval_ms"` // Wait between polls once the outbox is drained
+	MaxBackoffMs    int  `mapstructure:"max_backoff_ms"`   // Longest wait between retries of a failing delivery
+}
+
+type WebhookConfig struct {
+	DeliveryEnabled  bool `mapstructure:"delivery_enabled"`   // Send queued webhook deliveries in the background
+	BatchSize        int  `mapstructure:"batch_size"`         // Deliveries sent concurrently per poll
+	PollIntervalMs   int  `mapstructure:"poll_interval_ms"`   // Wait between polls when nothing is due
+	TimeoutMs        int  `mapstructure:"timeout_ms"`         // Timeout of a single webhook request
+	MaxAttempts      int  `mapstructure:"max_attempts"`       // Attempts before a delivery is dead-lettered
+	InitialBackoffMs int  `mapstructure:"initial_backoff_ms"` // Wait before the first retry, doubled per retry
+	MaxBackoffMs     int  `mapstructure:"max_backoff_ms"`     // Longest wait between retries
+}
+
+type TrashConfig struct {
+	RetentionEnabled     bool `mapstructure:"retention_enabled"`      // Purge objects that stayed in the trash past retention_days
+	RetentionDays        int  `mapstructure:"retention_days"`         // How long deleted objects can be restored
+	PurgeIntervalMinutes int  `mapstructure:"purge_interval_minutes"` // Wait between purges of expired objects
+	BatchSize            int  `mapstructure:"batch_size"`             // Objects purged per transaction
+}
+
+type UserLifecycleConfig struct {
+	RestoreWindowDays int `mapstructure:"restore_window_days"` // How long soft-deleted users can be restored
+}
+
+type EmailConfig struct {
+	SMTPHost     string `mapstructure:"smtp_host"`       // SMTP server mail is sent through; unset disables mail
+	SMTPPort     int    `mapstructure:"smtp_port"`       // SMTP submission port
+	SMTPUsername string `mapstructure:"smtp_username"`   // PLAIN auth user; unset sends without authentication
+	SMTPPassword string `mapstructure:"smtp_password"`   // PLAIN auth password
+	From         string `mapstructure:"from"`            // Sender address of outgoing mail
+	Timeout      int    `mapstructure:"timeout_seconds"` // Deadline for delivering one message
+}
+
 func Load(configPath string) (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -117,8 +169,22 @@ func Load(configPath string) (*Config, error) {
 	_ = viper.BindEnv("tracing.collector_url", "TRACING_COLLECTOR_URL")
 	_ = viper.BindEnv("tracing.sampling_rate", "TRACING_SAMPLING_RATE")
 	_ = viper.BindEnv("jwt.public_key", "JWT_PUBLIC_KEY")
+	_ = viper.BindEnv("jwt.signing_algorithm", "JWT_SIGNING_ALGORITHM")
+	_ = viper.BindEnv("jwt.key_overlap_minutes", "JWT_KEY_OVERLAP_MINUTES")
 	_ = viper.BindEnv("auth_service.url", "AUTH_SERVICE_URL")
 	_ = viper.BindEnv("auth_service.timeout_seconds", "AUTH_SERVICE_TIMEOUT")
+	_ = viper.BindEnv("audit.enabled", "AUDIT_ENABLED")
+	_ = viper.BindEnv("outbox.dispatch_enabled", "OUTBOX_DISPATCH_ENABLED")
+	_ = viper.BindEnv("webhooks.delivery_enabled", "WEBHOOKS_DELIVERY_ENABLED")
+	_ = viper.BindEnv("trash.retention_enabled", "TRASH_RETENTION_ENABLED")
+	_ = viper.BindEnv("trash.retention_days", "TRASH_RETENTION_DAYS")
+	_ = viper.BindEnv("user_lifecycle.restore_window_days", "USER_RESTORE_WINDOW_DAYS")
+	_ = viper.BindEnv("email.smtp_host", "EMAIL_SMTP_HOST")
+	_ = viper.BindEnv("email.smtp_port", "EMAIL_SMTP_PORT")
+	_ = viper.BindEnv("email.smtp_username", "EMAIL_SMTP_USERNAME")
+	_ = viper.BindEnv("email.smtp_password", "EMAIL_SMTP_PASSWORD")
+	_ = viper.BindEnv("email.from", "EMAIL_FROM")
+	_ = viper.BindEnv("email.timeout_seconds", "EMAIL_SMTP_TIMEOUT")
 
 	// Set environment variable defaults for Docker
 	if os.Getenv("DOCKER_ENV") == "true" {
@@ -189,6 +255,10 @@ func setDefaults() {
 	viper.SetDefault("tracing.collector_url", "http://jaeger:4318/v1/traces")
 	viper.SetDefault("tracing.sampling_rate", 1.0)
 
+	// JWT defaults
+	viper.SetDefault("jwt.signing_algorithm", "RS256")
+	viper.SetDefault("jwt.key_overlap_minutes", 60)
+
 	// Permission cache defaults
 	viper.SetDefault("permission_cache.ttl", 60)
 	viper.SetDefault("permission_cache.max_entries", 10000)
@@ -196,4 +266,39 @@ func setDefaults() {
 	// Auth service defaults
 	viper.SetDefault("auth_service.url", "http://auth-service:8083")
 	viper.SetDefault("auth_service.timeout_seconds", 10)
+
+	// Audit trail defaults
+	viper.SetDefault("audit.enabled", true)
+	viper.SetDefault("audit.buffer_size", 1000)
+	viper.SetDefault("audit.batch_size", 100)
+	viper.SetDefault("audit.flush_interval_ms", 1000)
+
+	// Outbox dispatch defaults
+	viper.SetDefault("outbox.dispatch_enabled", true)
+	viper.SetDefault("outbox.batch_size", 100)
+	viper.SetDefault("outbox.poll_interval_ms", 1000)
+	viper.SetDefault("outbox.max_backoff_ms", 60000)
+
+	// Webhook delivery defaults
+	viper.SetDefault("webhooks.delivery_enabled", true)
+	viper.SetDefault("webhooks.batch_size", 20)
+	viper.SetDefault("webhooks.poll_interval_ms", 1000)
+	viper.SetDefault("webhooks.timeout_ms", 10000)
+	viper.SetDefault("webhooks.max_attempts", 8)
+	viper.SetDefault("webhooks.initial_backoff_ms", 10000)
+	viper.SetDefault("webhooks.max_backoff_ms", 3600000)
+
+	// Trash retention defaults
+	viper.SetDefault("trash.retention_enabled", true)
+	viper.SetDefault("trash.retention_days", 30)
+	viper.SetDefault("trash.purge_interval_minutes", 60)
+	viper.SetDefault("trash.batch_size", 100)
+
+	// User lifecycle defaults
+	viper.SetDefault("user_lifecycle.restore_window_days", 30)
+
+	// Email defaults; mail is only sent once email.smtp_host is set
+	viper.SetDefault("email.smtp_port", 587)
+	viper.SetDefault("email.from", "no-reply@service-boilerplate.local")
+	viper.SetDefault("email.timeout_seconds", 10)
 }
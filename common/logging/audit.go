@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"context"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -29,6 +30,7 @@ type AuditEvent struct {
 type AuditLogger struct {
 	logger      *logrus.Logger
 	serviceName string
+	sink        AuditSink
 }
 
 // NewAuditLogger creates a new audit logger
@@ -39,6 +41,11 @@ func NewAuditLogger(logger *logrus.Logger, serviceName string) *AuditLogger {
 	}
 }
 
+// SetSink persists every subsequent audit event to sink in addition to logging it
+func (al *AuditLogger) SetSink(sink AuditSink) {
+	al.sink = sink
+}
+
 // LogAuthAttempt logs authentication attempts
 func (al *AuditLogger) LogAuthAttempt(userID, requestID, ipAddress, userAgent, email, traceID, spanID string, success bool, errorMsg string) {
 	event := AuditEvent{
@@ -257,6 +264,67 @@ func (al *AuditLogger) LogAdminAction(actorUserID, requestID, entityID, ipAddres
 	al.logEvent(event)
 }
 
+// LogAccountStatusChange logs suspension, reactivation and deactivation of user accounts
+func (al *AuditLogger) LogAccountStatusChange(actorUserID, requestID, targetUserID, ipAddress, userAgent, newStatus, reason, traceID, spanID string, success bool, errorMsg string) {
+	event := AuditEvent{
+		Timestamp: time.Now().UTC(),
+		EventType: "account_status_change",
+		Service:   al.serviceName,
+		UserID:    actorUserID,  // Who changed the status
+		EntityID:  targetUserID, // The account whose status changed
+		RequestID: requestID,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Resource:  "user",
+		Action:    "status_change",
+		TraceID:   traceID,
+		SpanID:    spanID,
+		Details: map[string]interface{}{
+			"status": newStatus,
+		},
+	}
+
+	if reason != "" {
+		event.Details["reason"] = reason
+	}
+
+	if success {
+		event.Result = "success"
+	} else {
+		event.Result = "failure"
+		event.Error = errorMsg
+	}
+
+	al.logEvent(event)
+}
+
+// LogEmailChange logs the request and confirmation stages of a user email change
+func (al *AuditLogger) LogEmailChange(actorUserID, requestID, targetUserID, ipAddress, userAgent, stage, traceID, spanID string, success bool, errorMsg string) {
+	event := AuditEvent{
+		Timestamp: time.Now().UTC(),
+		EventType: "email_change",
+		Service:   al.serviceName,
+		UserID:    actorUserID,  // Who requested the change
+		EntityID:  targetUserID, // The user whose email changes
+		RequestID: requestID,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Resource:  "user",
+		Action:    "email_change_" + stage,
+		TraceID:   traceID,
+		SpanID:    spanID,
+	}
+
+	if success {
+		event.Result = "success"
+	} else {
+		event.Result = "failure"
+		event.Error = errorMsg
+	}
+
+	al.logEvent(event)
+}
+
 // LogSuspiciousActivity logs potentially suspicious activities
 func (al *AuditLogger) LogSuspiciousActivity(actorUserID, requestID, ipAddress, userAgent, activityType, traceID, spanID string, details map[string]interface{}) {
 	event := AuditEvent{
@@ -318,4 +386,10 @@ func (al *AuditLogger) logEvent(event AuditEvent) {
 	default:
 		logEntry.Info("Security event: " + event.EventType)
 	}
+
+	if al.sink != nil {
+		if err := al.sink.WriteEvents(context.Background(), []AuditEvent{event}); err != nil {
+			al.logger.WithError(err).WithField("event_type", event.EventType).Warn("Failed to persist audit event")
+		}
+	}
 }
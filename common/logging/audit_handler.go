@@ -0,0 +1,164 @@
+package logging
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AuditQueryHandler exposes the persisted audit trail to administrators
+type AuditQueryHandler struct {
+	store  AuditStore
+	logger *logrus.Logger
+}
+
+// NewAuditQueryHandler creates a handler for querying and verifying an audit store
+func NewAuditQueryHandler(store AuditStore, logger *logrus.Logger) *AuditQueryHandler {
+	return &AuditQueryHandler{
+		store:  store,
+		logger: logger,
+	}
+}
+
+func auditValidationError(c *gin.Context, message, field string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": message,
+		"type":  "validation_error",
+		"field": field,
+		"meta":  gin.H{"request_id": c.GetHeader("X-Request-ID")},
+	})
+}
+
+// parseAuditTime parses an RFC3339 query parameter; empty values yield nil
+func parseAuditTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListEvents returns stored audit events filtered by user, entity, event type and time range
+func (h *AuditQueryHandler) ListEvents(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	query := &AuditQuery{
+		UserID:    c.Query("user_id"),
+		EntityID:  c.Query("entity_id"),
+		EventType: c.Query("event_type"),
+		Resource:  c.Query("resource"),
+		Action:    c.Query("action"),
+		Result:    c.Query("result"),
+		Limit:     50,
+	}
+
+	from, err := parseAuditTime(c.Query("from"))
+	if err != nil {
+		auditValidationError(c, "Invalid from time, expected RFC3339", "from")
+		return
+	}
+	to, err := parseAuditTime(c.Query("to"))
+	if err != nil {
+		auditValidationError(c, "Invalid to time, expected RFC3339", "to")
+		return
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		auditValidationError(c, "from must be before to", "from")
+		return
+	}
+	query.From, query.To = from, to
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > maxAuditQueryLimit {
+			auditValidationError(c, "Limit must be between 1 and 500", "limit")
+			return
+		}
+		query.Limit = limit
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			auditValidationError(c, "Offset must be a non-negative integer", "offset")
+			return
+		}
+		query.Offset = offset
+	}
+
+	events, total, err := h.store.QueryEvents(c.Request.Context(), query)
+	if err != nil {
+		h.logger.WithError(err).WithField(FieldRequestID, requestID).Error("Failed to query audit events")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to query audit events",
+			"type":  "internal_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": events,
+		"pagination": gin.H{
+			"limit":  query.Limit,
+			"offset": query.Offset,
+			"total":  total,
+			"count":  len(events),
+		},
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// VerifyChain checks the hash chain and reports the first tampered event, if any
+func (h *AuditQueryHandler) VerifyChain(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	var fromID int64
+	if fromStr := c.Query("from_id"); fromStr != "" {
+		id, err := strconv.ParseInt(fromStr, 10, 64)
+		if err != nil || id < 0 {
+			auditValidationError(c, "from_id must be a non-negative integer", "from_id")
+			return
+		}
+		fromID = id
+	}
+
+	limit := 10000
+	if limitStr := c.Query("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 || l > 100000 {
+			auditValidationError(c, "Limit must be between 1 and 100000", "limit")
+			return
+		}
+		limit = l
+	}
+
+	result, err := h.store.VerifyChain(c.Request.Context(), fromID, limit)
+	if err != nil {
+		h.logger.WithError(err).WithField(FieldRequestID, requestID).Error("Failed to verify audit chain")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to verify audit chain",
+			"type":  "internal_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	if !result.Valid {
+		h.logger.WithFields(logrus.Fields{
+			FieldRequestID: requestID,
+			"broken_at_id": result.BrokenAtID,
+			"reason":       result.Reason,
+		}).Error("Audit chain verification failed")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queryCapturingAuditStore records the parameters the handler passes to the store
+type queryCapturingAuditStore struct {
+	query       *AuditQuery
+	verifyCalls int
+	fromID      int64
+	limit       int
+}
+
+func (s *queryCapturingAuditStore) WriteEvents(ctx context.Context, events []AuditEvent) error {
+	return nil
+}
+
+func (s *queryCapturingAuditStore) QueryEvents(ctx context.Context, query *AuditQuery) ([]AuditRecord, int64, error) {
+	s.query = query
+	return []AuditRecord{}, 0, nil
+}
+
+func (s *queryCapturingAuditStore) VerifyChain(ctx context.Context, fromID int64, limit int) (*AuditChainVerification, error) {
+	s.verifyCalls++
+	s.fromID, s.limit = fromID, limit
+	return &AuditChainVerification{Valid: true}, nil
+}
+
+func serveAuditQuery(t *testing.T, handler gin.HandlerFunc, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/audit", handler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestAuditQueryHandler_ListEvents(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		expectedStatus int
+		expectedField  string
+		expectedLimit  int
+		expectedOffset int
+	}{
+		{name: "defaults", target: "/audit", expectedStatus: http.StatusOK, expectedLimit: 50},
+		{name: "time range", target: "/audit?from=2026-01-01T00:00:00Z&to=2026-01-02T00:00:00Z", expectedStatus: http.StatusOK, expectedLimit: 50},
+		{name: "invalid from", target: "/audit?from=yesterday", expectedStatus: http.StatusBadRequest, expectedField: "from"},
+		{name: "invalid to", target: "/audit?to=2026-01-02", expectedStatus: http.StatusBadRequest, expectedField: "to"},
+		{name: "from after to", target: "/audit?from=2026-01-02T00:00:00Z&to=2026-01-01T00:00:00Z", expectedStatus: http.StatusBadRequest, expectedField: "from"},
+		{name: "empty time range", target: "/audit?from=2026-01-01T00:00:00Z&to=2026-01-01T00:00:00Z", expectedStatus: http.StatusBadRequest, expectedField: "from"},
+		{name: "minimum limit", target: "/audit?limit=1", expectedStatus: http.StatusOK, expectedLimit: 1},
+		{name: "maximum limit", target: "/audit?limit=500&offset=20", expectedStatus: http.StatusOK, expectedLimit: 500, expectedOffset: 20},
+		{name: "zero limit", target: "/audit?limit=0", expectedStatus: http.StatusBadRequest, expectedField: "limit"},
+		{name: "limit above maximum", target: "/audit?limit=501", expectedStatus: http.StatusBadRequest, expectedField: "limit"},
+		{name: "non-numeric limit", target: "/audit?limit=ten", expectedStatus: http.StatusBadRequest, expectedField: "limit"},
+		{name: "negative offset", target: "/audit?offset=-1", expectedStatus: http.StatusBadRequest, expectedField: "offset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, _ := test.NewNullLogger()
+			store := &queryCapturingAuditStore{}
+			handler := NewAuditQueryHandler(store, logger)
+
+			w := serveAuditQuery(t, handler.ListEvents, tt.target)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, "validation_error", body["type"])
+				assert.Equal(t, tt.expectedField, body["field"])
+				assert.Nil(t, store.query, "store must not be queried")
+				return
+			}
+			require.NotNil(t, store.query)
+			assert.Equal(t, tt.expectedLimit, store.query.Limit)
+			assert.Equal(t, tt.expectedOffset, store.query.Offset)
+		})
+	}
+}
+
+func TestAuditQueryHandler_VerifyChain(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		expectedStatus int
+		expectedField  string
+		expectedFromID int64
+		expectedLimit  int
+	}{
+		{name: "defaults", target: "/audit", expectedStatus: http.StatusOK, expectedLimit: 10000},
+		{name: "range", target: "/audit?from_id=42&limit=100000", expectedStatus: http.StatusOK, expectedFromID: 42, expectedLimit: 100000},
+		{name: "negative from_id", target: "/audit?from_id=-1", expectedStatus: http.StatusBadRequest, expectedField: "from_id"},
+		{name: "zero limit", target: "/audit?limit=0", expectedStatus: http.StatusBadRequest, expectedField: "limit"},
+		{name: "limit above maximum", target: "/audit?limit=100001", expectedStatus: http.StatusBadRequest, expectedField: "limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, _ := test.NewNullLogger()
+			store := &queryCapturingAuditStore{}
+			handler := NewAuditQueryHandler(store, logger)
+
+			w := serveAuditQuery(t, handler.VerifyChain, tt.target)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, tt.expectedField, body["field"])
+				assert.Zero(t, store.verifyCalls, "chain must not be verified")
+				return
+			}
+			assert.Equal(t, tt.expectedFromID, store.fromID)
+			assert.Equal(t, tt.expectedLimit, store.limit)
+		})
+	}
+}
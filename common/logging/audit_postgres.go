@@ -0,0 +1,259 @@
+package logging
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// genesisAuditHash is the previous hash of the first event in a chain
+const genesisAuditHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// maxAuditQueryLimit caps the page size of audit event queries
+const maxAuditQueryLimit = 500
+
+var auditTablePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*\.[a-z_][a-z0-9_]*$`)
+
+// auditDB is the subset of *pgxpool.Pool the audit store uses
+type auditDB interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// PostgresAuditStore stores audit events in an append-only, hash-chained table.
+//
+// Each row stores the exact JSON payload that was hashed; hash = sha256(prev_hash || payload).
+// Editing or deleting a row breaks the chain, which VerifyChain detects. The table itself
+// rejects UPDATE and DELETE through a trigger (see the service audit_events migrations).
+type PostgresAuditStore struct {
+	db    auditDB
+	table string
+}
+
+// NewPostgresAuditStore creates a store for a schema-qualified table such as "auth_service.audit_events"
+func NewPostgresAuditStore(db *pgxpool.Pool, table string) (*PostgresAuditStore, error) {
+	if !auditTablePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid audit table name: %q", table)
+	}
+	return &PostgresAuditStore{db: db, table: table}, nil
+}
+
+// computeAuditHash chains a payload to the previous hash
+func computeAuditHash(prevHash string, payload []byte) string {
+	h := sha256.New()
+	h.Write([]byte(prevHash))
+	h.Write(payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// WriteEvents appends events to the chain. Writers are serialized with a transaction-scoped
+// advisory lock so concurrent service instances extend the same chain without forking it.
+func (s *PostgresAuditStore) WriteEvents(ctx context.Context, events []AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin audit transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", s.table); err != nil {
+		return fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	prevHash := genesisAuditHash
+	err = tx.QueryRow(ctx, fmt.Sprintf("SELECT hash FROM %s ORDER BY id DESC LIMIT 1", s.table)).Scan(&prevHash)
+	if err != nil && err != pgx.ErrNoRows {
+		return fmt.Errorf("failed to read audit chain head: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (occurred_at, event_type, service, user_id, entity_id, resource, action, result, request_id, trace_id, payload, prev_hash, hash)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), $7, $8, NULLIF($9, ''), NULLIF($10, ''), $11, $12, $13)
+	`, s.table)
+
+	batch := &pgx.Batch{}
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit event: %w", err)
+		}
+
+		hash := computeAuditHash(prevHash, payload)
+		batch.Queue(query,
+			event.Timestamp, event.EventType, event.Service, event.UserID, event.EntityID, event.Resource,
+			event.Action, event.Result, event.RequestID, event.TraceID, string(payload), prevHash, hash)
+		prevHash = hash
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to insert audit events: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit audit events: %w", err)
+	}
+
+	return nil
+}
+
+// QueryEvents returns events matching the query, newest first, with the total match count
+func (s *PostgresAuditStore) QueryEvents(ctx context.Context, query *AuditQuery) ([]AuditRecord, int64, error) {
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(clause string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(clause, len(args)))
+	}
+
+	if query.UserID != "" {
+		addCondition("user_id = $%d", query.UserID)
+	}
+	if query.EntityID != "" {
+		addCondition("entity_id = $%d", query.EntityID)
+	}
+	if query.EventType != "" {
+		addCondition("event_type = $%d", query.EventType)
+	}
+	if query.Resource != "" {
+		addCondition("resource = $%d", query.Resource)
+	}
+	if query.Action != "" {
+		addCondition("action = $%d", query.Action)
+	}
+	if query.Result != "" {
+		addCondition("result = $%d", query.Result)
+	}
+	if query.From != nil {
+		addCondition("occurred_at >= $%d", *query.From)
+	}
+	if query.To != nil {
+		addCondition("occurred_at < $%d", *query.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", s.table, where)
+	if err := s.db.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > maxAuditQueryLimit {
+		limit = maxAuditQueryLimit
+	}
+	offset := query.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	args = append(args, limit, offset)
+	selectQuery := fmt.Sprintf(`
+		SELECT id, payload, prev_hash, hash
+		FROM %s
+		%s
+		ORDER BY occurred_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, s.table, where, len(args)-1, len(args))
+
+	rows, err := s.db.Query(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query audit events: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]AuditRecord, 0)
+	for rows.Next() {
+		var record AuditRecord
+		var payload string
+		if err := rows.Scan(&record.ID, &payload, &record.PrevHash, &record.Hash); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		// The hashed payload is the source of truth; indexed columns only serve filtering
+		if err := json.Unmarshal([]byte(payload), &record.AuditEvent); err != nil {
+			return nil, 0, fmt.Errorf("failed to decode audit event %d: %w", record.ID, err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate audit events: %w", err)
+	}
+
+	return records, total, nil
+}
+
+// VerifyChain recomputes hashes for up to limit events starting at fromID (0 = from the beginning)
+func (s *PostgresAuditStore) VerifyChain(ctx context.Context, fromID int64, limit int) (*AuditChainVerification, error) {
+	if limit <= 0 {
+		limit = 10000
+	}
+
+	// The expected previous hash is the hash of the row before fromID, or genesis
+	expectedPrev := genesisAuditHash
+	err := s.db.QueryRow(ctx,
+		fmt.Sprintf("SELECT hash FROM %s WHERE id < $1 ORDER BY id DESC LIMIT 1", s.table), fromID,
+	).Scan(&expectedPrev)
+	if err != nil && err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("failed to read audit chain anchor: %w", err)
+	}
+
+	rows, err := s.db.Query(ctx,
+		fmt.Sprintf("SELECT id, payload, prev_hash, hash FROM %s WHERE id >= $1 ORDER BY id ASC LIMIT $2", s.table),
+		fromID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit chain: %w", err)
+	}
+	defer rows.Close()
+
+	result := &AuditChainVerification{Valid: true}
+	for rows.Next() {
+		var id int64
+		var payload, prevHash, hash string
+		if err := rows.Scan(&id, &payload, &prevHash, &hash); err != nil {
+			return nil, fmt.Errorf("failed to scan audit chain row: %w", err)
+		}
+
+		if result.FirstID == 0 {
+			result.FirstID = id
+		}
+		result.LastID = id
+		result.CheckedEvents++
+
+		if prevHash != expectedPrev {
+			result.Valid = false
+			result.BrokenAtID = id
+			result.Reason = "previous hash does not match preceding event"
+			return result, nil
+		}
+		if computeAuditHash(prevHash, []byte(payload)) != hash {
+			result.Valid = false
+			result.BrokenAtID = id
+			result.Reason = "event payload does not match its hash"
+			return result, nil
+		}
+		expectedPrev = hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate audit chain: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,314 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryAuditTable is an in-memory audit table. Its advisory lock is a real mutex held
+// from pg_advisory_xact_lock until the transaction ends, as in PostgreSQL.
+type memoryAuditTable struct {
+	lock sync.Mutex
+	mu   sync.Mutex
+	rows []memoryAuditRow
+}
+
+type memoryAuditRow struct {
+	id       int64
+	payload  string
+	prevHash string
+	hash     string
+}
+
+func (t *memoryAuditTable) Begin(ctx context.Context) (pgx.Tx, error) {
+	return &memoryAuditTx{table: t}, nil
+}
+
+// QueryRow serves VerifyChain's anchor read: the last hash before id $1
+func (t *memoryAuditTable) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fromID := args[0].(int64)
+	for i := len(t.rows) - 1; i >= 0; i-- {
+		if t.rows[i].id < fromID {
+			return hashRow{hash: t.rows[i].hash}
+		}
+	}
+	return hashRow{err: pgx.ErrNoRows}
+}
+
+// Query serves VerifyChain's chain read: up to $2 rows from id $1 in id order
+func (t *memoryAuditTable) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fromID, limit := args[0].(int64), args[1].(int)
+	var rows []memoryAuditRow
+	for _, row := range t.rows {
+		if row.id >= fromID && len(rows) < limit {
+			rows = append(rows, row)
+		}
+	}
+	return &chainRows{rows: rows, index: -1}, nil
+}
+
+type memoryAuditTx struct {
+	pgx.Tx
+	table   *memoryAuditTable
+	locked  bool
+	pending []memoryAuditRow
+}
+
+func (tx *memoryAuditTx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	if strings.Contains(sql, "pg_advisory_xact_lock") {
+		tx.table.lock.Lock()
+		tx.locked = true
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+// QueryRow serves WriteEvents' chain head read, which is only consistent under the lock
+func (tx *memoryAuditTx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	if !tx.locked {
+		return hashRow{err: fmt.Errorf("chain head read without the advisory lock")}
+	}
+	tx.table.mu.Lock()
+	defer tx.table.mu.Unlock()
+	if len(tx.table.rows) == 0 {
+		return hashRow{err: pgx.ErrNoRows}
+	}
+	return hashRow{hash: tx.table.rows[len(tx.table.rows)-1].hash}
+}
+
+func (tx *memoryAuditTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	for _, query := range b.QueuedQueries {
+		args := query.Arguments
+		tx.pending = append(tx.pending, memoryAuditRow{
+			payload:  args[10].(string),
+			prevHash: args[11].(string),
+			hash:     args[12].(string),
+		})
+	}
+	return closedBatch{}
+}
+
+func (tx *memoryAuditTx) Commit(ctx context.Context) error {
+	tx.table.mu.Lock()
+	for _, row := range tx.pending {
+		row.id = int64(len(tx.table.rows) + 1)
+		tx.table.rows = append(tx.table.rows, row)
+	}
+	tx.table.mu.Unlock()
+	return tx.end()
+}
+
+func (tx *memoryAuditTx) Rollback(ctx context.Context) error {
+	return tx.end()
+}
+
+func (tx *memoryAuditTx) end() error {
+	if tx.locked {
+		tx.locked = false
+		tx.table.lock.Unlock()
+	}
+	return nil
+}
+
+type closedBatch struct {
+	pgx.BatchResults
+}
+
+func (closedBatch) Close() error { return nil }
+
+type hashRow struct {
+	hash string
+	err  error
+}
+
+func (r hashRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*string) = r.hash
+	return nil
+}
+
+type chainRows struct {
+	pgx.Rows
+	rows  []memoryAuditRow
+	index int
+}
+
+func (r *chainRows) Next() bool {
+	r.index++
+	return r.index < len(r.rows)
+}
+
+func (r *chainRows) Scan(dest ...interface{}) error {
+	row := r.rows[r.index]
+	*dest[0].(*int64) = row.id
+	*dest[1].(*string) = row.payload
+	*dest[2].(*string) = row.prevHash
+	*dest[3].(*string) = row.hash
+	return nil
+}
+
+func (r *chainRows) Err() error { return nil }
+func (r *chainRows) Close()     {}
+
+func auditEvents(n int, action string) []AuditEvent {
+	events := make([]AuditEvent, n)
+	for i := range events {
+		events[i] = AuditEvent{
+			Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+			EventType: "user_action",
+			Service:   "user-service",
+			Action:    fmt.Sprintf("%s-%d", action, i),
+			Result:    "success",
+		}
+	}
+	return events
+}
+
+func TestPostgresAuditStore_WriteEventsChainsHashes(t *testing.T) {
+	table := &memoryAuditTable{}
+	store := &PostgresAuditStore{db: table, table: "user_service.audit_events"}
+
+	require.NoError(t, store.WriteEvents(context.Background(), auditEvents(2, "first")))
+	require.NoError(t, store.WriteEvents(context.Background(), auditEvents(1, "second")))
+
+	require.Len(t, table.rows, 3)
+	prevHash := genesisAuditHash
+	for _, row := range table.rows {
+		assert.Equal(t, prevHash, row.prevHash, "row %d", row.id)
+		assert.Equal(t, computeAuditHash(row.prevHash, []byte(row.payload)), row.hash, "row %d", row.id)
+		prevHash = row.hash
+	}
+
+	var event AuditEvent
+	require.NoError(t, json.Unmarshal([]byte(table.rows[2].payload), &event))
+	assert.Equal(t, "second-0", event.Action)
+}
+
+func TestPostgresAuditStore_ConcurrentWritersExtendOneChain(t *testing.T) {
+	table := &memoryAuditTable{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		// Each writer is a separate store, as with several service instances
+		store := &PostgresAuditStore{db: table, table: "user_service.audit_events"}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(t, store.WriteEvents(context.Background(), auditEvents(3, fmt.Sprintf("writer%d", i))))
+		}(i)
+	}
+	wg.Wait()
+
+	store := &PostgresAuditStore{db: table, table: "user_service.audit_events"}
+	result, err := store.VerifyChain(context.Background(), 0, 0)
+	require.NoError(t, err)
+	assert.True(t, result.Valid, result.Reason)
+	assert.Equal(t, 24, result.CheckedEvents)
+}
+
+func TestPostgresAuditStore_VerifyChain(t *testing.T) {
+	tests := []struct {
+		name           string
+		tamper         func(rows []memoryAuditRow) []memoryAuditRow
+		fromID         int64
+		expectValid    bool
+		expectChecked  int
+		expectBrokenAt int64
+		expectReason   string
+	}{
+		{
+			name:          "intact chain",
+			tamper:        func(rows []memoryAuditRow) []memoryAuditRow { return rows },
+			expectValid:   true,
+			expectChecked: 5,
+		},
+		{
+			name:          "intact chain from a later id",
+			tamper:        func(rows []memoryAuditRow) []memoryAuditRow { return rows },
+			fromID:        3,
+			expectValid:   true,
+			expectChecked: 3,
+		},
+		{
+			name: "edited payload",
+			tamper: func(rows []memoryAuditRow) []memoryAuditRow {
+				rows[2].payload = strings.Replace(rows[2].payload, `"success"`, `"failure"`, 1)
+				return rows
+			},
+			expectBrokenAt: 3,
+			expectChecked:  3,
+			expectReason:   "event payload does not match its hash",
+		},
+		{
+			name: "edited payload with recomputed hash",
+			tamper: func(rows []memoryAuditRow) []memoryAuditRow {
+				rows[1].payload = strings.Replace(rows[1].payload, `"success"`, `"failure"`, 1)
+				rows[1].hash = computeAuditHash(rows[1].prevHash, []byte(rows[1].payload))
+				return rows
+			},
+			expectBrokenAt: 3,
+			expectChecked:  3,
+			expectReason:   "previous hash does not match preceding event",
+		},
+		{
+			name: "deleted row",
+			tamper: func(rows []memoryAuditRow) []memoryAuditRow {
+				return append(rows[:2:2], rows[3:]...)
+			},
+			expectBrokenAt: 4,
+			expectChecked:  3,
+			expectReason:   "previous hash does not match preceding event",
+		},
+		{
+			name: "deleted first row",
+			tamper: func(rows []memoryAuditRow) []memoryAuditRow {
+				return rows[1:]
+			},
+			expectBrokenAt: 2,
+			expectChecked:  1,
+			expectReason:   "previous hash does not match preceding event",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := &memoryAuditTable{}
+			store := &PostgresAuditStore{db: table, table: "user_service.audit_events"}
+			require.NoError(t, store.WriteEvents(context.Background(), auditEvents(5, "action")))
+			table.rows = tt.tamper(table.rows)
+
+			result, err := store.VerifyChain(context.Background(), tt.fromID, 0)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectValid, result.Valid)
+			assert.Equal(t, tt.expectChecked, result.CheckedEvents)
+			assert.Equal(t, tt.expectBrokenAt, result.BrokenAtID)
+			assert.Equal(t, tt.expectReason, result.Reason)
+		})
+	}
+}
+
+func TestNewPostgresAuditStore_RejectsUnqualifiedTable(t *testing.T) {
+	for _, table := range []string{"audit_events", "user_service.audit_events; DROP TABLE x", "User.Audit"} {
+		_, err := NewPostgresAuditStore(nil, table)
+		assert.Error(t, err, table)
+	}
+
+	_, err := NewPostgresAuditStore(nil, "user_service.audit_events")
+	assert.NoError(t, err)
+}
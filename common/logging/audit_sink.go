@@ -0,0 +1,203 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrAuditBufferFull is returned when the buffered sink drops an event because its queue is full
+var ErrAuditBufferFull = errors.New("audit buffer full, event dropped")
+
+// AuditSink persists audit events in addition to the structured log output
+type AuditSink interface {
+	WriteEvents(ctx context.Context, events []AuditEvent) error
+}
+
+// AuditQuery filters stored audit events. Zero values mean "no filter".
+type AuditQuery struct {
+	UserID    string
+	EntityID  string
+	EventType string
+	Resource  string
+	Action    string
+	Result    string
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+	Offset    int
+}
+
+// AuditRecord is a stored audit event together with its position in the hash chain
+type AuditRecord struct {
+	ID       int64  `json:"id"`
+	PrevHash string `json:"prev_hash"`
+	Hash     string `json:"hash"`
+	AuditEvent
+}
+
+// AuditChainVerification reports the result of checking the tamper-evident hash chain
+type AuditChainVerification struct {
+	Valid         bool   `json:"valid"`
+	CheckedEvents int    `json:"checked_events"`
+	FirstID       int64  `json:"first_id,omitempty"`
+	LastID        int64  `json:"last_id,omitempty"`
+	BrokenAtID    int64  `json:"broken_at_id,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+}
+
+// AuditStore is an AuditSink that can also be queried and verified
+type AuditStore interface {
+	AuditSink
+	QueryEvents(ctx context.Context, query *AuditQuery) ([]AuditRecord, int64, error)
+	VerifyChain(ctx context.Context, fromID int64, limit int) (*AuditChainVerification, error)
+}
+
+// BufferedAuditSinkConfig configures the asynchronous audit writer
+type BufferedAuditSinkConfig struct {
+	BufferSize    int           // Events queued before new events are dropped
+	BatchSize     int           // Events written per call to the underlying sink
+	FlushInterval time.Duration // Maximum time an event waits in the queue
+	WriteTimeout  time.Duration // Timeout for a single batch write
+}
+
+// BufferedAuditSink queues audit events in memory and writes them in batches on a
+// background goroutine, so request handlers never wait on the audit store
+type BufferedAuditSink struct {
+	sink    AuditSink
+	config  BufferedAuditSinkConfig
+	logger  *logrus.Logger
+	events  chan AuditEvent
+	done    chan struct{}
+	wg      sync.WaitGroup
+	closed  atomic.Bool
+	dropped atomic.Int64
+	once    sync.Once
+}
+
+// NewBufferedAuditSink creates a buffered sink and starts its writer goroutine
+func NewBufferedAuditSink(sink AuditSink, config BufferedAuditSinkConfig, logger *logrus.Logger) *BufferedAuditSink {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1000
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 5 * time.Second
+	}
+
+	b := &BufferedAuditSink{
+		sink:   sink,
+		config: config,
+		logger: logger,
+		events: make(chan AuditEvent, config.BufferSize),
+		done:   make(chan struct{}),
+	}
+
+	b.wg.Add(1)
+	go b.run()
+
+	return b
+}
+
+// WriteEvents enqueues events without blocking; events that don't fit are dropped
+func (b *BufferedAuditSink) WriteEvents(ctx context.Context, events []AuditEvent) error {
+	if b.closed.Load() {
+		return errors.New("audit sink closed")
+	}
+
+	for _, event := range events {
+		select {
+		case b.events <- event:
+		default:
+			b.dropped.Add(1)
+			return ErrAuditBufferFull
+		}
+	}
+	return nil
+}
+
+// Dropped returns the number of events dropped because the buffer was full
+func (b *BufferedAuditSink) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// Close stops accepting events and flushes everything still queued
+func (b *BufferedAuditSink) Close(ctx context.Context) error {
+	b.once.Do(func() {
+		b.closed.Store(true)
+		close(b.done)
+	})
+
+	finished := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run batches queued events and writes them to the underlying sink
+func (b *BufferedAuditSink) run() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]AuditEvent, 0, b.config.BatchSize)
+	for {
+		select {
+		case event := <-b.events:
+			batch = append(batch, event)
+			if len(batch) >= b.config.BatchSize {
+				batch = b.flush(batch)
+			}
+		case <-ticker.C:
+			batch = b.flush(batch)
+		case <-b.done:
+			// Drain whatever is still queued before exiting
+			for {
+				select {
+				case event := <-b.events:
+					batch = append(batch, event)
+					if len(batch) >= b.config.BatchSize {
+						batch = b.flush(batch)
+					}
+				default:
+					b.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush writes a batch and returns an empty slice for reuse
+func (b *BufferedAuditSink) flush(batch []AuditEvent) []AuditEvent {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.config.WriteTimeout)
+	defer cancel()
+
+	if err := b.sink.WriteEvents(ctx, batch); err != nil {
+		b.logger.WithError(err).WithField(FieldCount, len(batch)).Error("Failed to persist audit events")
+	}
+
+	return batch[:0]
+}
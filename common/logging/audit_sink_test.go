@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAuditSink records each batch it is asked to write. Once started is set, the
+// first write signals it and then blocks until release is closed.
+type recordingAuditSink struct {
+	mu      sync.Mutex
+	batches [][]AuditEvent
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *recordingAuditSink) WriteEvents(ctx context.Context, events []AuditEvent) error {
+	if s.started != nil {
+		close(s.started)
+		s.started = nil
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, append([]AuditEvent(nil), events...))
+	return nil
+}
+
+func (s *recordingAuditSink) batchSizes() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sizes := make([]int, len(s.batches))
+	for i, batch := range s.batches {
+		sizes[i] = len(batch)
+	}
+	return sizes
+}
+
+func TestBufferedAuditSink_BatchesAndDrainsOnClose(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	sink := &recordingAuditSink{}
+	buffered := NewBufferedAuditSink(sink, BufferedAuditSinkConfig{BatchSize: 2, FlushInterval: time.Hour}, logger)
+
+	require.NoError(t, buffered.WriteEvents(context.Background(), auditEvents(5, "action")))
+	require.NoError(t, buffered.Close(context.Background()))
+
+	assert.Equal(t, []int{2, 2, 1}, sink.batchSizes())
+	var actions []string
+	for _, batch := range sink.batches {
+		for _, event := range batch {
+			actions = append(actions, event.Action)
+		}
+	}
+	assert.Equal(t, []string{"action-0", "action-1", "action-2", "action-3", "action-4"}, actions)
+
+	assert.Error(t, buffered.WriteEvents(context.Background(), auditEvents(1, "late")))
+	assert.NoError(t, buffered.Close(context.Background()))
+}
+
+func TestBufferedAuditSink_FlushesOnInterval(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	sink := &recordingAuditSink{}
+	buffered := NewBufferedAuditSink(sink, BufferedAuditSinkConfig{BatchSize: 100, FlushInterval: 10 * time.Millisecond}, logger)
+	defer buffered.Close(context.Background())
+
+	require.NoError(t, buffered.WriteEvents(context.Background(), auditEvents(1, "action")))
+
+	assert.Eventually(t, func() bool {
+		sizes := sink.batchSizes()
+		return len(sizes) == 1 && sizes[0] == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestBufferedAuditSink_DropsWhenBufferFull(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	sink := &recordingAuditSink{started: make(chan struct{}), release: make(chan struct{})}
+	started := sink.started
+	buffered := NewBufferedAuditSink(sink, BufferedAuditSinkConfig{BufferSize: 1, BatchSize: 1, FlushInterval: time.Hour}, logger)
+
+	// The writer takes the first event and blocks in the sink, leaving the one-slot buffer empty
+	require.NoError(t, buffered.WriteEvents(context.Background(), auditEvents(1, "first")))
+	<-started
+
+	require.NoError(t, buffered.WriteEvents(context.Background(), auditEvents(1, "second")))
+	assert.ErrorIs(t, buffered.WriteEvents(context.Background(), auditEvents(1, "third")), ErrAuditBufferFull)
+	assert.ErrorIs(t, buffered.WriteEvents(context.Background(), auditEvents(1, "fourth")), ErrAuditBufferFull)
+	assert.Equal(t, int64(2), buffered.Dropped())
+
+	close(sink.release)
+	require.NoError(t, buffered.Close(context.Background()))
+
+	assert.Equal(t, []int{1, 1}, sink.batchSizes())
+	assert.Equal(t, "second-0", sink.batches[1][0].Action)
+}
+
+func TestBufferedAuditSink_CloseHonoursContext(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+	sink := &recordingAuditSink{started: make(chan struct{}), release: make(chan struct{})}
+	started := sink.started
+	buffered := NewBufferedAuditSink(sink, BufferedAuditSinkConfig{BatchSize: 1}, logger)
+	defer close(sink.release)
+
+	require.NoError(t, buffered.WriteEvents(context.Background(), auditEvents(1, "stuck")))
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, buffered.Close(ctx), context.DeadlineExceeded)
+}
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"crypto/rsa"
 	"net/http"
 	"strings"
 
@@ -13,19 +12,30 @@ import (
 
 // JWTClaims represents the JWT token claims
 type JWTClaims struct {
-	UserID    uuid.UUID `json:"user_id"`
-	Email     string    `json:"email"`
-	Roles     []string  `json:"roles"`
-	TokenType string    `json:"token_type"`
+	UserID      uuid.UUID         `json:"user_id"`
+	Email       string            `json:"email"`
+	Roles       []string          `json:"roles"`
+	Memberships []MembershipClaim `json:"memberships,omitempty"`
+	TokenType   string            `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
+// MembershipClaim is an organization or team membership carried in an access token
+type MembershipClaim struct {
+	OrganizationID   uuid.UUID  `json:"organization_id"`
+	OrganizationSlug string     `json:"organization_slug"`
+	TeamID           *uuid.UUID `json:"team_id,omitempty"`
+	TeamName         string     `json:"team_name,omitempty"`
+	Role             string     `json:"role"`
+}
+
 // TokenRevocationChecker interface for checking if a token has been revoked
 type TokenRevocationChecker interface {
 	IsTokenRevoked(tokenString string) bool
 }
 
-// JWTMiddleware creates JWT authentication middleware
+// JWTMiddleware creates JWT authentication middleware.
+// jwtSecret accepts anything supported by NewKeyFunc (key resolver, public key or HMAC secret).
 func JWTMiddleware(jwtSecret interface{}, logger *logrus.Logger, revocationChecker TokenRevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := c.GetHeader("X-Request-ID")
@@ -118,24 +128,8 @@ func JWTMiddleware(jwtSecret interface{}, logger *logrus.Logger, revocationCheck
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		// Parse and validate JWT token
-		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-			// Support both HMAC and RSA signing methods
-			switch token.Method {
-			case jwt.SigningMethodHS256, jwt.SigningMethodHS384, jwt.SigningMethodHS512:
-				// HMAC method - jwtSecret should be []byte
-				if secret, ok := jwtSecret.([]byte); ok {
-					return secret, nil
-				}
-			case jwt.SigningMethodRS256, jwt.SigningMethodRS384, jwt.SigningMethodRS512:
-				// RSA method - jwtSecret should be *rsa.PublicKey
-				if publicKey, ok := jwtSecret.(*rsa.PublicKey); ok {
-					return publicKey, nil
-				}
-			}
-			return nil, jwt.ErrSignatureInvalid
-		})
-
+		// Parse and validate JWT token; the key function rejects "none" and algorithm mismatches
+		token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, NewKeyFunc(jwtSecret))
 		if err != nil {
 			logger.WithFields(logrus.Fields{
 				"request_id": requestID,
@@ -188,6 +182,7 @@ func JWTMiddleware(jwtSecret interface{}, logger *logrus.Logger, revocationCheck
 		c.Set("user_id", userID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_roles", claims.Roles)
+		c.Set("user_memberships", claims.Memberships)
 		c.Set("token_type", claims.TokenType)
 
 		logger.WithFields(logrus.Fields{
@@ -233,6 +228,16 @@ func GetAuthenticatedUserRoles(c *gin.Context) []string {
 	return []string{}
 }
 
+// GetAuthenticatedUserMemberships extracts the authenticated user's organization and team memberships from the Gin context
+func GetAuthenticatedUserMemberships(c *gin.Context) []MembershipClaim {
+	if memberships, exists := c.Get("user_memberships"); exists {
+		if m, ok := memberships.([]MembershipClaim); ok {
+			return m
+		}
+	}
+	return []MembershipClaim{}
+}
+
 // RequireAuth middleware requires authentication for the endpoint
 func RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
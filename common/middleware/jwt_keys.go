@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported JWT signing algorithms
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// ErrUnknownKeyID is returned when a token references a key that is not known to the resolver
+var ErrUnknownKeyID = errors.New("unknown signing key")
+
+// VerificationKey is a public key together with the algorithm it was issued for.
+// Tokens are only accepted when their header algorithm matches Algorithm exactly.
+type VerificationKey struct {
+	KeyID     string
+	Algorithm string
+	PublicKey crypto.PublicKey
+}
+
+// KeyResolver resolves the verification key for the "kid" header of a token.
+// An empty keyID means the token carries no "kid" and the current key should be used.
+type KeyResolver interface {
+	ResolveVerificationKey(keyID string) (*VerificationKey, error)
+}
+
+// IsSupportedAlgorithm reports whether alg is an asymmetric algorithm issued by auth-service
+func IsSupportedAlgorithm(alg string) bool {
+	switch alg {
+	case AlgorithmRS256, AlgorithmES256, AlgorithmEdDSA:
+		return true
+	}
+	return false
+}
+
+// AlgorithmForKey returns the signing algorithm implied by a public key type
+func AlgorithmForKey(publicKey crypto.PublicKey) (string, error) {
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+		return AlgorithmRS256, nil
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return "", fmt.Errorf("unsupported ECDSA curve: %s", key.Curve.Params().Name)
+		}
+		return AlgorithmES256, nil
+	case ed25519.PublicKey:
+		return AlgorithmEdDSA, nil
+	default:
+		return "", fmt.Errorf("unsupported public key type: %T", publicKey)
+	}
+}
+
+// ParsePublicKeyPEM parses a PEM-encoded RSA, ECDSA or Ed25519 public key.
+// Both PKIX and PKCS#1 encodings are accepted regardless of the PEM block label.
+func ParsePublicKeyPEM(pemData []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block")
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		rsaKey, rsaErr := x509.ParsePKCS1PublicKey(block.Bytes)
+		if rsaErr != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		return rsaKey, nil
+	}
+
+	if _, err := AlgorithmForKey(publicKey); err != nil {
+		return nil, err
+	}
+
+	return publicKey, nil
+}
+
+// NewKeyFunc builds a jwt.Keyfunc for the given verification material.
+//
+// jwtSecret may be a KeyResolver, a *VerificationKey, a raw RSA/ECDSA/Ed25519
+// public key, or an HMAC secret ([]byte). The token's "alg" header must match
+// the algorithm declared by (or implied by) the key; "none" is always rejected,
+// which prevents algorithm confusion such as an RSA public key used as an HMAC secret.
+func NewKeyFunc(jwtSecret interface{}) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		alg := token.Method.Alg()
+		if alg == "" || alg == jwt.SigningMethodNone.Alg() {
+			return nil, fmt.Errorf("signing method %q is not allowed", alg)
+		}
+
+		switch secret := jwtSecret.(type) {
+		case []byte:
+			// HMAC secrets only ever verify HMAC tokens
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %s", alg)
+			}
+			return secret, nil
+		case KeyResolver:
+			keyID, _ := token.Header["kid"].(string)
+			key, err := secret.ResolveVerificationKey(keyID)
+			if err != nil {
+				return nil, err
+			}
+			return verifyKeyAlgorithm(key, alg)
+		case *VerificationKey:
+			return verifyKeyAlgorithm(secret, alg)
+		default:
+			keyAlg, err := AlgorithmForKey(jwtSecret)
+			if err != nil {
+				return nil, err
+			}
+			// Raw RSA keys keep accepting the whole RS family for backward compatibility
+			if keyAlg == AlgorithmRS256 {
+				if _, ok := token.Method.(*jwt.SigningMethodRSA); ok {
+					return jwtSecret, nil
+				}
+				return nil, fmt.Errorf("unexpected signing method: %s", alg)
+			}
+			return verifyKeyAlgorithm(&VerificationKey{Algorithm: keyAlg, PublicKey: jwtSecret}, alg)
+		}
+	}
+}
+
+// verifyKeyAlgorithm returns the key's public key if the token algorithm matches the declared one
+func verifyKeyAlgorithm(key *VerificationKey, tokenAlg string) (interface{}, error) {
+	if key == nil || key.PublicKey == nil {
+		return nil, ErrUnknownKeyID
+	}
+	if key.Algorithm != tokenAlg {
+		return nil, fmt.Errorf("token algorithm %s does not match key algorithm %s", tokenAlg, key.Algorithm)
+	}
+	if keyAlg, err := AlgorithmForKey(key.PublicKey); err != nil || keyAlg != key.Algorithm {
+		return nil, fmt.Errorf("key %s is not a valid %s key", key.KeyID, key.Algorithm)
+	}
+	return key.PublicKey, nil
+}
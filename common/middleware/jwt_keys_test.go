@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapKeyResolver resolves keys from a fixed set; an empty key ID selects activeKeyID
+type mapKeyResolver struct {
+	keys        map[string]*VerificationKey
+	activeKeyID string
+}
+
+func (r *mapKeyResolver) ResolveVerificationKey(keyID string) (*VerificationKey, error) {
+	if keyID == "" {
+		keyID = r.activeKeyID
+	}
+	key, ok := r.keys[keyID]
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+	return key, nil
+}
+
+type testSigningKeys struct {
+	rsa     *rsa.PrivateKey
+	ecdsa   *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func newTestSigningKeys(t *testing.T) testSigningKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return testSigningKeys{rsa: rsaKey, ecdsa: ecdsaKey, ed25519: edKey}
+}
+
+func (k testSigningKeys) resolver() *mapKeyResolver {
+	return &mapKeyResolver{
+		keys: map[string]*VerificationKey{
+			"rsa-1":   {KeyID: "rsa-1", Algorithm: AlgorithmRS256, PublicKey: &k.rsa.PublicKey},
+			"ecdsa-1": {KeyID: "ecdsa-1", Algorithm: AlgorithmES256, PublicKey: &k.ecdsa.PublicKey},
+			"ed-1":    {KeyID: "ed-1", Algorithm: AlgorithmEdDSA, PublicKey: k.ed25519.Public()},
+		},
+		activeKeyID: "ed-1",
+	}
+}
+
+func signTestToken(t *testing.T, method jwt.SigningMethod, keyID string, key interface{}) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, jwt.RegisteredClaims{
+		Subject:   "user-1",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	if keyID != "" {
+		token.Header["kid"] = keyID
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func parseTestToken(tokenString string, jwtSecret interface{}) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, NewKeyFunc(jwtSecret))
+}
+
+func TestNewKeyFunc_ResolvesKeyByKeyID(t *testing.T) {
+	keys := newTestSigningKeys(t)
+	resolver := keys.resolver()
+
+	tests := []struct {
+		name   string
+		method jwt.SigningMethod
+		keyID  string
+		key    crypto.Signer
+	}{
+		{name: "RS256", method: jwt.SigningMethodRS256, keyID: "rsa-1", key: keys.rsa},
+		{name: "ES256", method: jwt.SigningMethodES256, keyID: "ecdsa-1", key: keys.ecdsa},
+		{name: "EdDSA", method: jwt.SigningMethodEdDSA, keyID: "ed-1", key: keys.ed25519},
+		{name: "no kid uses active key", method: jwt.SigningMethodEdDSA, key: keys.ed25519},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := parseTestToken(signTestToken(t, tt.method, tt.keyID, tt.key), resolver)
+			require.NoError(t, err)
+			assert.True(t, token.Valid)
+		})
+	}
+}
+
+func TestNewKeyFunc_RejectsWrongKey(t *testing.T) {
+	keys := newTestSigningKeys(t)
+	resolver := keys.resolver()
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	t.Run("unknown kid", func(t *testing.T) {
+		_, err := parseTestToken(signTestToken(t, jwt.SigningMethodRS256, "rsa-2", keys.rsa), resolver)
+		assert.ErrorIs(t, err, ErrUnknownKeyID)
+	})
+
+	t.Run("kid of a different key of the same type", func(t *testing.T) {
+		_, err := parseTestToken(signTestToken(t, jwt.SigningMethodRS256, "rsa-1", otherKey), resolver)
+		assert.ErrorIs(t, err, jwt.ErrTokenSignatureInvalid)
+	})
+
+	t.Run("kid of a key with another algorithm", func(t *testing.T) {
+		_, err := parseTestToken(signTestToken(t, jwt.SigningMethodRS256, "ed-1", keys.rsa), resolver)
+		assert.ErrorContains(t, err, "token algorithm RS256 does not match key algorithm EdDSA")
+	})
+}
+
+func TestNewKeyFunc_RejectsNone(t *testing.T) {
+	keys := newTestSigningKeys(t)
+	tokenString := signTestToken(t, jwt.SigningMethodNone, "rsa-1", jwt.UnsafeAllowNoneSignatureType)
+
+	for name, jwtSecret := range map[string]interface{}{
+		"resolver":         keys.resolver(),
+		"verification key": &VerificationKey{Algorithm: AlgorithmRS256, PublicKey: &keys.rsa.PublicKey},
+		"raw public key":   &keys.rsa.PublicKey,
+		"hmac secret":      []byte("secret"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			keyFunc := NewKeyFunc(jwtSecret)
+			token, _, err := jwt.NewParser().ParseUnverified(tokenString, &jwt.RegisteredClaims{})
+			require.NoError(t, err)
+
+			key, err := keyFunc(token)
+			assert.Nil(t, key)
+			assert.ErrorContains(t, err, `signing method "none" is not allowed`)
+
+			_, err = parseTestToken(tokenString, jwtSecret)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewKeyFunc_RejectsAlgorithmConfusion(t *testing.T) {
+	keys := newTestSigningKeys(t)
+	publicPEM, err := x509.MarshalPKIXPublicKey(&keys.rsa.PublicKey)
+	require.NoError(t, err)
+	// An attacker who knows the RSA public key signs with it as an HMAC secret
+	hmacWithPublicKey := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicPEM})
+
+	tests := []struct {
+		name        string
+		tokenString string
+		jwtSecret   interface{}
+		expectedErr string
+	}{
+		{
+			name:        "HS256 against resolved RSA key",
+			tokenString: signTestToken(t, jwt.SigningMethodHS256, "rsa-1", hmacWithPublicKey),
+			jwtSecret:   keys.resolver(),
+			expectedErr: "token algorithm HS256 does not match key algorithm RS256",
+		},
+		{
+			name:        "HS256 against raw RSA key",
+			tokenString: signTestToken(t, jwt.SigningMethodHS256, "", hmacWithPublicKey),
+			jwtSecret:   &keys.rsa.PublicKey,
+			expectedErr: "unexpected signing method: HS256",
+		},
+		{
+			name:        "ES256 against raw Ed25519 key",
+			tokenString: signTestToken(t, jwt.SigningMethodES256, "", keys.ecdsa),
+			jwtSecret:   keys.ed25519.Public(),
+			expectedErr: "token algorithm ES256 does not match key algorithm EdDSA",
+		},
+		{
+			name:        "RS256 against HMAC secret",
+			tokenString: signTestToken(t, jwt.SigningMethodRS256, "", keys.rsa),
+			jwtSecret:   []byte("secret"),
+			expectedErr: "unexpected signing method: RS256",
+		},
+		{
+			name:        "key declared with another algorithm than its type",
+			tokenString: signTestToken(t, jwt.SigningMethodES256, "", keys.ecdsa),
+			jwtSecret:   &VerificationKey{KeyID: "mislabeled", Algorithm: AlgorithmES256, PublicKey: &keys.rsa.PublicKey},
+			expectedErr: "key mislabeled is not a valid ES256 key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := parseTestToken(tt.tokenString, tt.jwtSecret)
+			assert.ErrorContains(t, err, tt.expectedErr)
+			assert.True(t, token == nil || !token.Valid)
+		})
+	}
+}
+
+func TestVerifyKeyAlgorithm(t *testing.T) {
+	keys := newTestSigningKeys(t)
+
+	key, err := verifyKeyAlgorithm(&VerificationKey{KeyID: "ed-1", Algorithm: AlgorithmEdDSA, PublicKey: keys.ed25519.Public()}, AlgorithmEdDSA)
+	require.NoError(t, err)
+	assert.Equal(t, keys.ed25519.Public(), key)
+
+	_, err = verifyKeyAlgorithm(nil, AlgorithmRS256)
+	assert.ErrorIs(t, err, ErrUnknownKeyID)
+
+	_, err = verifyKeyAlgorithm(&VerificationKey{KeyID: "rsa-1", Algorithm: AlgorithmRS256, PublicKey: &keys.rsa.PublicKey}, "RS512")
+	assert.ErrorContains(t, err, "token algorithm RS512 does not match key algorithm RS256")
+
+	_, err = verifyKeyAlgorithm(&VerificationKey{KeyID: "ecdsa-1", Algorithm: AlgorithmEdDSA, PublicKey: &keys.ecdsa.PublicKey}, AlgorithmEdDSA)
+	assert.ErrorContains(t, err, "key ecdsa-1 is not a valid EdDSA key")
+}
@@ -98,6 +98,8 @@ func main() {
 	var jwtUtils *utils.JWTUtils
 	var keyRotationManager *services.KeyRotationManager
 	var permCache cache.PermissionCache
+	var auditSink *logging.BufferedAuditSink
+	var auditQueryHandler *logging.AuditQueryHandler
 
 	if db != nil {
 		// Initialize permission cache
@@ -109,7 +111,10 @@ func main() {
 
 		// Initialize JWT utils with database connection
 		var err error
-		jwtUtils, err = utils.NewJWTUtils(db.Pool)
+		jwtUtils, err = utils.NewJWTUtilsWithConfig(db.Pool, utils.KeyConfig{
+			Algorithm: cfg.JWT.SigningAlgorithm,
+			Overlap:   time.Duration(cfg.JWT.KeyOverlapMinutes) * time.Minute,
+		})
 		if err != nil {
 			logger.Fatal("Failed to initialize JWT utils", err)
 		}
@@ -127,7 +132,7 @@ func main() {
 			Type:           "time",
 			IntervalDays:   30,
 			MaxTokens:      100000,
-			OverlapMinutes: 60,
+			OverlapMinutes: cfg.JWT.KeyOverlapMinutes,
 			CheckInterval:  1 * time.Hour, // Check every hour
 		}
 		keyRotationManager = services.NewKeyRotationManager(jwtUtils, db.Pool, rotationConfig, logger.Logger)
@@ -150,6 +155,22 @@ func main() {
 		healthHandler = handlers.NewHealthHandler(db.GetPool(), jwtUtils, keyRotationManager, logger.Logger, cfg)
 		permissionHandler = handlers.NewPermissionHandler(authService, permCache, logger.Logger)
 
+		// Persist audit events to the append-only audit trail
+		if cfg.Audit.Enabled {
+			auditStore, err := logging.NewPostgresAuditStore(db.GetPool(), "auth_service.audit_events")
+			if err != nil {
+				logger.Fatal("Failed to initialize audit store", err)
+			}
+			auditSink = logging.NewBufferedAuditSink(auditStore, logging.BufferedAuditSinkConfig{
+				BufferSize:    cfg.Audit.BufferSize,
+				BatchSize:     cfg.Audit.BatchSize,
+				FlushInterval: time.Duration(cfg.Audit.FlushIntervalMs) * time.Millisecond,
+			}, logger.Logger)
+			authHandler.SetAuditSink(auditSink)
+			auditQueryHandler = logging.NewAuditQueryHandler(auditStore, logger.Logger)
+			logger.Info("Persistent audit trail enabled")
+		}
+
 		// Create token revocation checker for JWT middleware
 		revocationChecker = &authServiceRevocationChecker{
 			authService: authService,
@@ -230,8 +251,13 @@ func main() {
 	if cfg.Tracing.Enabled {
 		router.Use(tracing.HTTPMiddleware(cfg.Tracing.ServiceName))
 	}
-	// JWT middleware for authentication
-	router.Use(middleware.JWTMiddleware(jwtUtils.GetPublicKey(), logger.Logger, revocationChecker))
+	// JWT middleware for authentication; JWTUtils resolves keys by "kid" so retired keys
+	// keep verifying during the rotation overlap window
+	var jwtKeys interface{}
+	if jwtUtils != nil {
+		jwtKeys = jwtUtils
+	}
+	router.Use(middleware.JWTMiddleware(jwtKeys, logger.Logger, revocationChecker))
 	router.Use(serviceLogger.RequestResponseLogger())
 
 	// Health check endpoints (public, no auth required)
@@ -244,6 +270,7 @@ func main() {
 	// Public key endpoint (public, no auth required)
 	if authHandler != nil {
 		router.GET("/public-key", authHandler.GetPublicKey)
+		router.GET("/public-keys", authHandler.GetPublicKeys)
 	}
 
 	// API routes
@@ -316,10 +343,25 @@ func main() {
 					admin.GET("/roles/:role_id/permissions", authHandler.GetRolePermissions)
 
 					// User-Role management
+					admin.GET("/users", authHandler.ListUserIDsByRole)
 					admin.POST("/users/:user_id/roles", authHandler.AssignRoleToUser)
 					admin.DELETE("/users/:user_id/roles/:role_id", authHandler.RemoveRoleFromUser)
 					admin.GET("/users/:user_id/roles", authHandler.GetUserRoles)
 					admin.PUT("/users/:user_id/roles", authHandler.UpdateUserRoles)
+
+					// Team-Role management; members of the team inherit its roles
+					admin.POST("/teams/:team_id/roles", authHandler.AssignRoleToTeam)
+					admin.DELETE("/teams/:team_id/roles/:role_id", authHandler.RemoveRoleFromTeam)
+					admin.GET("/teams/:team_id/roles", authHandler.GetTeamRoles)
+
+					// Account erasure cascade (called by user-service, not exposed through the gateway)
+					admin.DELETE("/users/:user_id/data", authHandler.PurgeUserData)
+
+					// Audit trail
+					if auditQueryHandler != nil {
+						admin.GET("/audit-events", auditQueryHandler.ListEvents)
+						admin.GET("/audit-events/verify", auditQueryHandler.VerifyChain)
+					}
 				}
 			}
 		}
@@ -354,6 +396,13 @@ func main() {
 		logger.Error("auth-service service forced to shutdown", err)
 	}
 
+	// Flush queued audit events before the database connection closes
+	if auditSink != nil {
+		if err := auditSink.Close(ctx); err != nil {
+			logger.Error("Failed to flush audit events", err)
+		}
+	}
+
 	logger.Info("auth-service service exited")
 }
 
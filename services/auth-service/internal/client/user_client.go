@@ -30,6 +30,7 @@ type UserData struct {
 	Email     string    `json:"email"`
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
+	Status    string    `json:"status"`
 }
 
 type UserLoginResponse struct {
@@ -232,3 +233,57 @@ func (c *UserClient) GetUserByID(ctx context.Context, id uuid.UUID) (*UserData,
 
 	return response.Data, nil
 }
+
+// UserMembership is an organization or team membership as reported by user-service
+type UserMembership struct {
+	OrganizationID   uuid.UUID  `json:"organization_id"`
+	OrganizationSlug string     `json:"organization_slug"`
+	TeamID           *uuid.UUID `json:"team_id,omitempty"`
+	TeamName         string     `json:"team_name,omitempty"`
+	Role             string     `json:"role"`
+}
+
+type userMembershipsResponse struct {
+	Data []UserMembership `json:"data"`
+}
+
+// GetUserMemberships returns the organizations and teams the user belongs to
+func (c *UserClient) GetUserMemberships(ctx context.Context, userID uuid.UUID) ([]UserMembership, error) {
+	url := fmt.Sprintf("%s/api/v1/users/%s/memberships", c.baseURL, userID.String())
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Extract request ID from context and set as header
+	if requestID, ok := ctx.Value("request_id").(string); ok {
+		httpReq.Header.Set("X-Request-ID", requestID)
+	}
+
+	// Inject trace context headers
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		c.logger.WithError(err).Error("Failed to call user service")
+		return nil, fmt.Errorf("failed to call user service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		c.logger.WithFields(logrus.Fields{
+			"status_code": resp.StatusCode,
+			"response":    string(body),
+		}).Error("User service returned error")
+		return nil, fmt.Errorf("user service returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response userMembershipsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return response.Data, nil
+}
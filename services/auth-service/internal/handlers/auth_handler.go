@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -56,6 +57,11 @@ func NewAuthHandler(authService services.AuthServiceInterface, logger *logrus.Lo
 	}
 }
 
+// SetAuditSink persists the handler's audit events to sink
+func (h *AuthHandler) SetAuditSink(sink logging.AuditSink) {
+	h.auditLogger.SetSink(sink)
+}
+
 func (h *AuthHandler) Login(c *gin.Context) {
 	// Extract trace information
 	span := trace.SpanFromContext(c.Request.Context())
@@ -78,6 +84,11 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	if err != nil {
 		h.standardLogger.AuthOperation(requestID, "", req.Email, "login", false, err)
 		h.auditLogger.LogAuthAttempt("", requestID, ipAddress, userAgent, req.Email, traceID, spanID, false, err.Error())
+		var statusErr models.AccountStatusError
+		if errors.As(err, &statusErr) {
+			h.errorResponse(c, http.StatusForbidden, "account_suspended", "Account is "+statusErr.Status)
+			return
+		}
 		h.errorResponse(c, http.StatusUnauthorized, "unauthorized", "Invalid credentials")
 		return
 	}
@@ -194,6 +205,11 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	if err != nil {
 		h.logger.WithError(err).Warn("Token refresh failed")
 		h.auditLogger.LogTokenOperation(actorUserID, requestID, "", ipAddress, userAgent, "refresh", traceID, spanID, false, err.Error())
+		var statusErr models.AccountStatusError
+		if errors.As(err, &statusErr) {
+			h.errorResponse(c, http.StatusForbidden, "account_suspended", "Account is "+statusErr.Status)
+			return
+		}
 		h.errorResponse(c, http.StatusUnauthorized, "unauthorized", "Invalid refresh token")
 		return
 	}
@@ -284,6 +300,19 @@ func (h *AuthHandler) GetPublicKey(c *gin.Context) {
 	c.String(http.StatusOK, string(publicKeyPEM))
 }
 
+// GetPublicKeys returns every key currently accepted for token verification, including
+// retired keys still inside their rotation overlap window
+func (h *AuthHandler) GetPublicKeys(c *gin.Context) {
+	keys, err := h.authService.GetPublicKeys()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get public keys")
+		h.errorResponse(c, http.StatusInternalServerError, "internal_error", "Failed to get public keys")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"keys": keys})
+}
+
 func (h *AuthHandler) RotateKeys(c *gin.Context) {
 	// Extract trace information
 	span := trace.SpanFromContext(c.Request.Context())
@@ -849,6 +878,108 @@ func (h *AuthHandler) GetUserRoles(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"roles": roles})
 }
 
+// Team Role Management Handlers
+
+// AssignRoleToTeam assigns a role to a user-service team; all team members inherit it
+func (h *AuthHandler) AssignRoleToTeam(c *gin.Context) {
+	// Extract trace information
+	span := trace.SpanFromContext(c.Request.Context())
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+
+	// Get authenticated user ID
+	actorUserID := middleware.GetAuthenticatedUserID(c)
+
+	teamID, err := uuid.Parse(c.Param("team_id"))
+	if err != nil {
+		h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), c.Param("team_id"), c.ClientIP(), c.GetHeader("User-Agent"), "assign_role_to_team", traceID, spanID, false, "Invalid team ID")
+		h.validationError(c, "Invalid team ID")
+		return
+	}
+
+	var req struct {
+		RoleID string `json:"role_id" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), teamID.String(), c.ClientIP(), c.GetHeader("User-Agent"), "assign_role_to_team", traceID, spanID, false, "Invalid request data")
+		h.validationError(c, "Invalid request data")
+		return
+	}
+
+	roleID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), teamID.String(), c.ClientIP(), c.GetHeader("User-Agent"), "assign_role_to_team", traceID, spanID, false, "Invalid role ID")
+		h.validationError(c, "Invalid role ID")
+		return
+	}
+
+	err = h.authService.AssignRoleToTeam(c.Request.Context(), teamID, roleID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to assign role to team")
+		h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), teamID.String(), c.ClientIP(), c.GetHeader("User-Agent"), "assign_role_to_team", traceID, spanID, false, err.Error())
+		h.errorResponse(c, http.StatusInternalServerError, "internal_error", "Failed to assign role to team")
+		return
+	}
+
+	h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), teamID.String(), c.ClientIP(), c.GetHeader("User-Agent"), "assign_role_to_team", traceID, spanID, true, fmt.Sprintf("role_id: %s", roleID.String()))
+	c.JSON(http.StatusOK, gin.H{"message": "Role assigned to team successfully"})
+}
+
+// RemoveRoleFromTeam removes a role from a team
+func (h *AuthHandler) RemoveRoleFromTeam(c *gin.Context) {
+	// Extract trace information
+	span := trace.SpanFromContext(c.Request.Context())
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+
+	// Get authenticated user ID
+	actorUserID := middleware.GetAuthenticatedUserID(c)
+
+	teamID, err := uuid.Parse(c.Param("team_id"))
+	if err != nil {
+		h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), c.Param("team_id"), c.ClientIP(), c.GetHeader("User-Agent"), "remove_role_from_team", traceID, spanID, false, "Invalid team ID")
+		h.validationError(c, "Invalid team ID")
+		return
+	}
+
+	roleID, err := uuid.Parse(c.Param("role_id"))
+	if err != nil {
+		h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), teamID.String(), c.ClientIP(), c.GetHeader("User-Agent"), "remove_role_from_team", traceID, spanID, false, "Invalid role ID")
+		h.validationError(c, "Invalid role ID")
+		return
+	}
+
+	err = h.authService.RemoveRoleFromTeam(c.Request.Context(), teamID, roleID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to remove role from team")
+		h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), teamID.String(), c.ClientIP(), c.GetHeader("User-Agent"), "remove_role_from_team", traceID, spanID, false, err.Error())
+		h.errorResponse(c, http.StatusInternalServerError, "internal_error", "Failed to remove role from team")
+		return
+	}
+
+	h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), teamID.String(), c.ClientIP(), c.GetHeader("User-Agent"), "remove_role_from_team", traceID, spanID, true, fmt.Sprintf("role_id: %s", roleID.String()))
+	c.JSON(http.StatusOK, gin.H{"message": "Role removed from team successfully"})
+}
+
+// GetTeamRoles returns the roles assigned to a team
+func (h *AuthHandler) GetTeamRoles(c *gin.Context) {
+	teamID, err := uuid.Parse(c.Param("team_id"))
+	if err != nil {
+		h.validationError(c, "Invalid team ID")
+		return
+	}
+
+	roles, err := h.authService.GetTeamRoles(c.Request.Context(), teamID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to get team roles")
+		h.errorResponse(c, http.StatusInternalServerError, "internal_error", "Failed to get team roles")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
 // UpdateUserRoles updates all roles for a user (bulk operation)
 func (h *AuthHandler) UpdateUserRoles(c *gin.Context) {
 	// Extract trace information
@@ -898,3 +1029,50 @@ func (h *AuthHandler) UpdateUserRoles(c *gin.Context) {
 	h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), userID.String(), c.ClientIP(), c.GetHeader("User-Agent"), "update_user_roles", traceID, spanID, true, fmt.Sprintf("role_count: %d", len(roleIDs)))
 	c.JSON(http.StatusOK, gin.H{"message": "User roles updated successfully"})
 }
+
+// ListUserIDsByRole returns the IDs of users holding the role given by the "role" query parameter.
+// user-service uses it to filter its user listing by role.
+func (h *AuthHandler) ListUserIDsByRole(c *gin.Context) {
+	roleName := c.Query("role")
+	if roleName == "" {
+		h.validationError(c, "role query parameter is required")
+		return
+	}
+
+	userIDs, err := h.authService.ListUserIDsByRole(c.Request.Context(), roleName)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list users by role")
+		h.errorResponse(c, http.StatusInternalServerError, "internal_error", "Failed to list users by role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"role": roleName, "user_ids": userIDs})
+}
+
+// PurgeUserData removes a user's tokens, sessions and roles; called by user-service on account erasure
+func (h *AuthHandler) PurgeUserData(c *gin.Context) {
+	// Extract trace information
+	span := trace.SpanFromContext(c.Request.Context())
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+
+	// Get authenticated user ID
+	actorUserID := middleware.GetAuthenticatedUserID(c)
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), c.Param("user_id"), c.ClientIP(), c.GetHeader("User-Agent"), "purge_user_data", traceID, spanID, false, "Invalid user ID")
+		h.validationError(c, "Invalid user ID")
+		return
+	}
+
+	if err := h.authService.PurgeUserData(c.Request.Context(), userID); err != nil {
+		h.logger.WithError(err).Error("Failed to purge user data")
+		h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), userID.String(), c.ClientIP(), c.GetHeader("User-Agent"), "purge_user_data", traceID, spanID, false, err.Error())
+		h.errorResponse(c, http.StatusInternalServerError, "internal_error", "Failed to purge user data")
+		return
+	}
+
+	h.auditLogger.LogAdminAction(actorUserID, c.GetHeader("X-Request-ID"), userID.String(), c.ClientIP(), c.GetHeader("User-Agent"), "purge_user_data", traceID, spanID, true, "")
+	c.Status(http.StatusNoContent)
+}
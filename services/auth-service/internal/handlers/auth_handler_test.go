@@ -47,6 +47,12 @@ type MockAuthService struct {
 	checkPermissionFunc          func(ctx context.Context, userID, permission string) (bool, error)
 	getUserPermissionsFunc       func(ctx context.Context, userID string) ([]string, error)
 	getUserRolesSimpleFunc       func(ctx context.Context, userID string) ([]string, error)
+	getPublicKeysFunc            func() ([]models.PublicKeyInfo, error)
+	purgeUserDataFunc            func(ctx context.Context, userID uuid.UUID) error
+	listUserIDsByRoleFunc        func(ctx context.Context, roleName string) ([]uuid.UUID, error)
+	assignRoleToTeamFunc         func(ctx context.Context, teamID, roleID uuid.UUID) error
+	removeRoleFromTeamFunc       func(ctx context.Context, teamID, roleID uuid.UUID) error
+	getTeamRolesFunc             func(ctx context.Context, teamID uuid.UUID) ([]models.Role, error)
 }
 
 func (m *MockAuthService) Login(ctx context.Context, req *models.LoginRequest, ipAddress, userAgent string) (*models.TokenResponse, error) {
@@ -105,6 +111,13 @@ func (m *MockAuthService) RotateKeys(ctx context.Context) error {
 	return errors.New("not implemented")
 }
 
+func (m *MockAuthService) GetPublicKeys() ([]models.PublicKeyInfo, error) {
+	if m.getPublicKeysFunc != nil {
+		return m.getPublicKeysFunc()
+	}
+	return nil, errors.New("not implemented")
+}
+
 func (m *MockAuthService) CreateRole(ctx context.Context, name, description string) (*models.Role, error) {
 	if m.createRoleFunc != nil {
 		return m.createRoleFunc(ctx, name, description)
@@ -217,6 +230,41 @@ func (m *MockAuthService) GetUserRoles(ctx context.Context, userID uuid.UUID) ([
 	return nil, errors.New("not implemented")
 }
 
+func (m *MockAuthService) PurgeUserData(ctx context.Context, userID uuid.UUID) error {
+	if m.purgeUserDataFunc != nil {
+		return m.purgeUserDataFunc(ctx, userID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAuthService) ListUserIDsByRole(ctx context.Context, roleName string) ([]uuid.UUID, error) {
+	if m.listUserIDsByRoleFunc != nil {
+		return m.listUserIDsByRoleFunc(ctx, roleName)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *MockAuthService) AssignRoleToTeam(ctx context.Context, teamID, roleID uuid.UUID) error {
+	if m.assignRoleToTeamFunc != nil {
+		return m.assignRoleToTeamFunc(ctx, teamID, roleID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAuthService) RemoveRoleFromTeam(ctx context.Context, teamID, roleID uuid.UUID) error {
+	if m.removeRoleFromTeamFunc != nil {
+		return m.removeRoleFromTeamFunc(ctx, teamID, roleID)
+	}
+	return errors.New("not implemented")
+}
+
+func (m *MockAuthService) GetTeamRoles(ctx context.Context, teamID uuid.UUID) ([]models.Role, error) {
+	if m.getTeamRolesFunc != nil {
+		return m.getTeamRolesFunc(ctx, teamID)
+	}
+	return nil, errors.New("not implemented")
+}
+
 func (m *MockAuthService) UpdateUserRoles(ctx context.Context, userID uuid.UUID, roleIDs []uuid.UUID) error {
 	if m.updateUserRolesFunc != nil {
 		return m.updateUserRolesFunc(ctx, userID, roleIDs)
@@ -1448,6 +1496,88 @@ func TestAuthHandler_GetUserRoles(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_AssignRoleToTeam(t *testing.T) {
+	teamID := uuid.New()
+	roleID := uuid.New()
+	tests := []struct {
+		name           string
+		teamID         string
+		roleID         string
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful assignment",
+			teamID:         teamID.String(),
+			roleID:         roleID.String(),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid team ID",
+			teamID:         "invalid-uuid",
+			roleID:         roleID.String(),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "service error",
+			teamID:         teamID.String(),
+			roleID:         roleID.String(),
+			mockError:      errors.New("assignment failed"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var assignedTeam uuid.UUID
+			mockService := &MockAuthService{
+				assignRoleToTeamFunc: func(ctx context.Context, tid, rid uuid.UUID) error {
+					assignedTeam = tid
+					return tt.mockError
+				},
+			}
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+
+			handler := NewAuthHandler(mockService, logger)
+
+			c, w := createTestContext("POST", "/teams/"+tt.teamID+"/roles", map[string]string{"role_id": tt.roleID})
+			c.Params = []gin.Param{{Key: "team_id", Value: tt.teamID}}
+			c.Set("user_roles", []string{"admin"})
+
+			handler.AssignRoleToTeam(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, teamID, assignedTeam)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_GetTeamRoles(t *testing.T) {
+	teamID := uuid.New()
+	mockService := &MockAuthService{
+		getTeamRolesFunc: func(ctx context.Context, tid uuid.UUID) ([]models.Role, error) {
+			return []models.Role{{ID: uuid.New(), Name: "finance-approver"}}, nil
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	handler := NewAuthHandler(mockService, logger)
+
+	c, w := createTestContext("GET", "/teams/"+teamID.String()+"/roles", nil)
+	c.Params = []gin.Param{{Key: "team_id", Value: teamID.String()}}
+
+	handler.GetTeamRoles(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "finance-approver")
+}
+
 func TestAuthHandler_UpdateUserRoles(t *testing.T) {
 	userID := uuid.New()
 	roleID1 := uuid.New()
@@ -2114,3 +2244,62 @@ func TestAuthHandler_DeletePermission(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthHandler_ListUserIDsByRole(t *testing.T) {
+	memberID := uuid.New()
+
+	tests := []struct {
+		name           string
+		path           string
+		mockResponse   []uuid.UUID
+		mockError      error
+		expectedStatus int
+	}{
+		{
+			name:           "successful lookup",
+			path:           "/users?role=editor",
+			mockResponse:   []uuid.UUID{memberID},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "missing role parameter",
+			path:           "/users",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "service error",
+			path:           "/users?role=editor",
+			mockError:      errors.New("database error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockAuthService{
+				listUserIDsByRoleFunc: func(ctx context.Context, roleName string) ([]uuid.UUID, error) {
+					assert.Equal(t, "editor", roleName)
+					return tt.mockResponse, tt.mockError
+				},
+			}
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+
+			handler := NewAuthHandler(mockService, logger)
+			c, w := createTestContext("GET", tt.path, nil)
+
+			handler.ListUserIDsByRole(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response struct {
+					UserIDs []uuid.UUID `json:"user_ids"`
+				}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.mockResponse, response.UserIDs)
+			}
+		})
+	}
+}
@@ -63,6 +63,7 @@ type DatabaseHealth struct {
 type JWTKeyHealth struct {
 	Status       string `json:"status"`
 	KeyID        string `json:"key_id,omitempty"`
+	Algorithm    string `json:"algorithm,omitempty"`
 	ResponseTime string `json:"response_time"`
 	Error        string `json:"error,omitempty"`
 }
@@ -294,6 +295,7 @@ func (h *HealthHandler) checkJWTKeyHealth() JWTKeyHealth {
 	return JWTKeyHealth{
 		Status:       "healthy",
 		KeyID:        keyID,
+		Algorithm:    h.jwtUtils.GetAlgorithm(),
 		ResponseTime: responseTime.Round(time.Millisecond).String(),
 	}
 }
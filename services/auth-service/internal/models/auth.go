@@ -89,3 +89,11 @@ type RefreshTokenRequest struct {
 type LogoutRequest struct {
 	Token string `json:"token" binding:"required"`
 }
+
+// PublicKeyInfo describes a JWT verification key published for other services
+type PublicKeyInfo struct {
+	KeyID        string `json:"kid"`
+	Algorithm    string `json:"alg"`
+	PublicKeyPEM string `json:"public_key"`
+	Active       bool   `json:"active"` // Currently used for signing
+}
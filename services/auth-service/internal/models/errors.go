@@ -32,6 +32,15 @@ func (e NotFoundError) Error() string {
 	return fmt.Sprintf("%s with %s '%s' not found", e.Resource, e.Field, e.Value)
 }
 
+// AccountStatusError is returned when a suspended or deactivated account tries to authenticate
+type AccountStatusError struct {
+	Status string
+}
+
+func (e AccountStatusError) Error() string {
+	return fmt.Sprintf("account is %s", e.Status)
+}
+
 type InternalError struct {
 	Operation string
 	Err       error
@@ -58,6 +67,10 @@ func NewNotFoundError(resource, field, value string) NotFoundError {
 	return NotFoundError{Resource: resource, Field: field, Value: value}
 }
 
+func NewAccountStatusError(status string) AccountStatusError {
+	return AccountStatusError{Status: status}
+}
+
 func NewInternalError(operation string, err error) InternalError {
 	return InternalError{Operation: operation, Err: err}
 }
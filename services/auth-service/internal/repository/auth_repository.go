@@ -189,6 +189,85 @@ func (r *AuthRepository) RemoveRoleFromUser(ctx context.Context, userID, roleID
 	return err
 }
 
+// AssignRoleToTeam grants a role to every member of a user-service team
+func (r *AuthRepository) AssignRoleToTeam(ctx context.Context, teamID, roleID uuid.UUID) error {
+	query := `
+		INSERT INTO auth_service.team_roles (team_id, role_id)
+		VALUES ($1, $2)
+		ON CONFLICT (team_id, role_id) DO NOTHING`
+
+	_, err := r.db.Exec(ctx, query, teamID, roleID)
+	return err
+}
+
+func (r *AuthRepository) RemoveRoleFromTeam(ctx context.Context, teamID, roleID uuid.UUID) error {
+	query := `DELETE FROM auth_service.team_roles WHERE team_id = $1 AND role_id = $2`
+	_, err := r.db.Exec(ctx, query, teamID, roleID)
+	return err
+}
+
+// GetRolesForTeams returns the distinct roles assigned to any of the teams
+func (r *AuthRepository) GetRolesForTeams(ctx context.Context, teamIDs []uuid.UUID) ([]models.Role, error) {
+	if len(teamIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT DISTINCT r.id, r.name, r.description, r.created_at
+		FROM auth_service.roles r
+		JOIN auth_service.team_roles tr ON r.id = tr.role_id
+		WHERE tr.team_id = ANY($1)`
+
+	var roles []models.Role
+	err := database.TraceDBQuery(ctx, "roles,team_roles", query, func(ctx context.Context) error {
+		rows, err := r.db.Query(ctx, query, teamIDs)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var role models.Role
+			if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt); err != nil {
+				return err
+			}
+			roles = append(roles, role)
+		}
+		return rows.Err()
+	})
+	return roles, err
+}
+
+// GetPermissionsForTeams returns the distinct permissions granted through roles assigned to any of the teams
+func (r *AuthRepository) GetPermissionsForTeams(ctx context.Context, teamIDs []uuid.UUID) ([]models.Permission, error) {
+	if len(teamIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT DISTINCT p.id, p.name, p.resource, p.action, p.created_at
+		FROM auth_service.permissions p
+		JOIN auth_service.role_permissions rp ON p.id = rp.permission_id
+		JOIN auth_service.team_roles tr ON rp.role_id = tr.role_id
+		WHERE tr.team_id = ANY($1)`
+
+	rows, err := r.db.Query(ctx, query, teamIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []models.Permission
+	for rows.Next() {
+		var permission models.Permission
+		if err := rows.Scan(&permission.ID, &permission.Name, &permission.Resource, &permission.Action, &permission.CreatedAt); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, rows.Err()
+}
+
 func (r *AuthRepository) GetRoleByName(ctx context.Context, roleName string) (*models.Role, error) {
 	query := `SELECT id, name, description, created_at FROM auth_service.roles WHERE name = $1`
 
@@ -426,6 +505,57 @@ func (r *AuthRepository) UpdateUserRoles(ctx context.Context, userID uuid.UUID,
 	return tx.Commit(ctx)
 }
 
+// PurgeUserData removes every token, session and role assignment held by a user.
+// Used when user-service erases an account.
+func (r *AuthRepository) PurgeUserData(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, query := range []string{
+		`DELETE FROM auth_service.auth_tokens WHERE user_id = $1`,
+		`DELETE FROM auth_service.user_sessions WHERE user_id = $1`,
+		`DELETE FROM auth_service.user_roles WHERE user_id = $1`,
+	} {
+		if _, err := tx.Exec(ctx, query, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListUserIDsByRoleName returns the IDs of all users assigned the named role
+func (r *AuthRepository) ListUserIDsByRoleName(ctx context.Context, roleName string) ([]uuid.UUID, error) {
+	query := `
+		SELECT ur.user_id
+		FROM auth_service.user_roles ur
+		JOIN auth_service.roles r ON r.id = ur.role_id
+		WHERE r.name = $1
+		ORDER BY ur.user_id`
+
+	var userIDs []uuid.UUID
+	err := database.TraceDBQuery(ctx, "roles,user_roles", query, func(ctx context.Context) error {
+		rows, err := r.db.Query(ctx, query, roleName)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var userID uuid.UUID
+			if err := rows.Scan(&userID); err != nil {
+				return err
+			}
+			userIDs = append(userIDs, userID)
+		}
+		return rows.Err()
+	})
+	return userIDs, err
+}
+
 // Utility methods for validation
 func (r *AuthRepository) CountUsersWithRole(ctx context.Context, roleID uuid.UUID) (int, error) {
 	query := `SELECT COUNT(*) FROM auth_service.user_roles WHERE role_id = $1`
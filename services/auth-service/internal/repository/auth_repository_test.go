@@ -2419,3 +2419,39 @@ func TestAuthRepository_CountRolesWithPermission(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthRepository_GetRolesForTeams(t *testing.T) {
+	t.Run("no teams skips the query", func(t *testing.T) {
+		mockDB := &MockDBPool{
+			QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+				t.Fatal("query must not run without teams")
+				return nil, nil
+			},
+		}
+		repo := NewAuthRepositoryWithInterface(mockDB)
+
+		roles, err := repo.GetRolesForTeams(context.Background(), nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, roles)
+	})
+
+	t.Run("passes team IDs as an array", func(t *testing.T) {
+		teamIDs := []uuid.UUID{uuid.New(), uuid.New()}
+		var gotSQL string
+		var gotArgs []any
+		mockDB := &MockDBPool{
+			QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+				gotSQL, gotArgs = sql, args
+				return nil, errors.New("connection refused")
+			},
+		}
+		repo := NewAuthRepositoryWithInterface(mockDB)
+
+		_, err := repo.GetRolesForTeams(context.Background(), teamIDs)
+
+		assert.Error(t, err)
+		assert.Contains(t, gotSQL, "auth_service.team_roles")
+		assert.Equal(t, []any{teamIDs}, gotArgs)
+	})
+}
@@ -3,11 +3,14 @@ package services
 import (
 	"context"
 	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
 	"github.com/v-egorov/service-boilerplate/services/auth-service/internal/cache"
 	"github.com/v-egorov/service-boilerplate/services/auth-service/internal/client"
 	"github.com/v-egorov/service-boilerplate/services/auth-service/internal/models"
@@ -48,6 +51,12 @@ type RepositoryInterface interface {
 	AssignRoleToUser(ctx context.Context, userID, roleID uuid.UUID) error
 	RemoveRoleFromUser(ctx context.Context, userID, roleID uuid.UUID) error
 	UpdateUserRoles(ctx context.Context, userID uuid.UUID, roleIDs []uuid.UUID) error
+	PurgeUserData(ctx context.Context, userID uuid.UUID) error
+	ListUserIDsByRoleName(ctx context.Context, roleName string) ([]uuid.UUID, error)
+	AssignRoleToTeam(ctx context.Context, teamID, roleID uuid.UUID) error
+	RemoveRoleFromTeam(ctx context.Context, teamID, roleID uuid.UUID) error
+	GetRolesForTeams(ctx context.Context, teamIDs []uuid.UUID) ([]models.Role, error)
+	GetPermissionsForTeams(ctx context.Context, teamIDs []uuid.UUID) ([]models.Permission, error)
 }
 
 // UserClientInterface defines the interface for user client operations
@@ -56,14 +65,16 @@ type UserClientInterface interface {
 	GetUserByID(ctx context.Context, userID uuid.UUID) (*client.UserData, error)
 	GetUserByEmail(ctx context.Context, email string) (*client.UserData, error)
 	CreateUser(ctx context.Context, req *client.CreateUserRequest) (*client.UserData, error)
+	GetUserMemberships(ctx context.Context, userID uuid.UUID) ([]client.UserMembership, error)
 }
 
 // JWTUtilsInterface defines the interface for JWT utilities
 type JWTUtilsInterface interface {
-	GenerateAccessToken(userID uuid.UUID, email string, roles []string, duration time.Duration) (string, error)
+	GenerateAccessToken(userID uuid.UUID, email string, roles []string, memberships []middleware.MembershipClaim, duration time.Duration) (string, error)
 	GenerateRefreshToken(userID uuid.UUID, duration time.Duration) (string, error)
 	ValidateToken(tokenString string) (*utils.JWTClaims, error)
 	GetPublicKeyPEM() ([]byte, error)
+	GetVerificationKeys() []*middleware.VerificationKey
 	RotateKeys(ctx context.Context) error
 	GetKeyID() string
 }
@@ -77,6 +88,7 @@ type AuthServiceInterface interface {
 	GetCurrentUser(ctx context.Context, userID uuid.UUID, email string) (*models.UserInfo, error)
 	ValidateToken(ctx context.Context, tokenString string) (*utils.JWTClaims, error)
 	GetPublicKeyPEM() ([]byte, error)
+	GetPublicKeys() ([]models.PublicKeyInfo, error)
 	RotateKeys(ctx context.Context) error
 	CreateRole(ctx context.Context, name, description string) (*models.Role, error)
 	ListRoles(ctx context.Context) ([]models.Role, error)
@@ -98,6 +110,11 @@ type AuthServiceInterface interface {
 	CheckPermission(ctx context.Context, userID, permission string) (bool, error)
 	GetUserPermissions(ctx context.Context, userID string) ([]string, error)
 	GetUserRolesSimple(ctx context.Context, userID string) ([]string, error)
+	PurgeUserData(ctx context.Context, userID uuid.UUID) error
+	ListUserIDsByRole(ctx context.Context, roleName string) ([]uuid.UUID, error)
+	AssignRoleToTeam(ctx context.Context, teamID, roleID uuid.UUID) error
+	RemoveRoleFromTeam(ctx context.Context, teamID, roleID uuid.UUID) error
+	GetTeamRoles(ctx context.Context, teamID uuid.UUID) ([]models.Role, error)
 }
 
 type AuthService struct {
@@ -159,24 +176,27 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, ipAdd
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
+	if err := checkAccountStatus(userLogin.Data.User.Status); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"email":  req.Email,
+			"status": userLogin.Data.User.Status,
+		}).Warn("Login rejected for inactive account")
+		return nil, err
+	}
+
 	// Use the actual user ID from user service
 	userID := userLogin.Data.User.ID
 	email := userLogin.Data.User.Email
 
-	// Get user roles
-	roles, err := s.repo.GetUserRoles(ctx, userID)
+	// Get user roles, including those inherited through team membership
+	roleNames, memberships, err := s.resolveRoles(ctx, userID)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to get user roles")
 		return nil, fmt.Errorf("failed to get user roles: %w", err)
 	}
 
-	roleNames := make([]string, len(roles))
-	for i, role := range roles {
-		roleNames[i] = role.Name
-	}
-
 	// Generate tokens
-	accessToken, err := s.jwtUtils.GenerateAccessToken(userID, email, roleNames, 15*time.Minute)
+	accessToken, err := s.jwtUtils.GenerateAccessToken(userID, email, roleNames, memberships, 15*time.Minute)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to generate access token")
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
@@ -396,26 +416,35 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *models.RefreshToken
 		return nil, fmt.Errorf("refresh token has been revoked")
 	}
 
+	// Re-check the account so suspension takes effect at the next refresh; fail closed
+	user, err := s.userClient.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get user for token refresh")
+		return nil, fmt.Errorf("failed to verify account status: %w", err)
+	}
+	if err := checkAccountStatus(user.Status); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"user_id": claims.UserID,
+			"status":  user.Status,
+		}).Warn("Token refresh rejected for inactive account")
+		return nil, err
+	}
+
 	// Revoke old refresh token
 	if err := s.repo.RevokeAuthToken(ctx, token.ID); err != nil {
 		s.logger.WithError(err).Error("Failed to revoke old refresh token")
 		return nil, fmt.Errorf("failed to revoke old refresh token: %w", err)
 	}
 
-	// Get user roles
-	roles, err := s.repo.GetUserRoles(ctx, claims.UserID)
+	// Get user roles, including those inherited through team membership
+	roleNames, memberships, err := s.resolveRoles(ctx, claims.UserID)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to get user roles")
 		return nil, fmt.Errorf("failed to get user roles: %w", err)
 	}
 
-	roleNames := make([]string, len(roles))
-	for i, role := range roles {
-		roleNames[i] = role.Name
-	}
-
 	// Generate new tokens
-	accessToken, err := s.jwtUtils.GenerateAccessToken(claims.UserID, claims.Email, roleNames, 15*time.Minute)
+	accessToken, err := s.jwtUtils.GenerateAccessToken(claims.UserID, claims.Email, roleNames, memberships, 15*time.Minute)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to generate new access token")
 		return nil, fmt.Errorf("failed to generate new access token: %w", err)
@@ -532,6 +561,26 @@ func (s *AuthService) RotateKeysWithReason(ctx context.Context, reason string) e
 	return nil
 }
 
+// GetPublicKeys returns all keys currently accepted for token verification
+func (s *AuthService) GetPublicKeys() ([]models.PublicKeyInfo, error) {
+	keys := s.jwtUtils.GetVerificationKeys()
+	activeKeyID := s.jwtUtils.GetKeyID()
+	result := make([]models.PublicKeyInfo, 0, len(keys))
+	for _, key := range keys {
+		publicKeyBytes, err := x509.MarshalPKIXPublicKey(key.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode public key %s: %w", key.KeyID, err)
+		}
+		result = append(result, models.PublicKeyInfo{
+			KeyID:        key.KeyID,
+			Algorithm:    key.Algorithm,
+			PublicKeyPEM: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes})),
+			Active:       key.KeyID == activeKeyID,
+		})
+	}
+	return result, nil
+}
+
 func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*utils.JWTClaims, error) {
 	tracer := otel.Tracer("auth-service")
 
@@ -824,6 +873,58 @@ func (s *AuthService) RemoveRoleFromUser(ctx context.Context, userID, roleID uui
 	return nil
 }
 
+// AssignRoleToTeam grants a role to every member of a user-service team
+func (s *AuthService) AssignRoleToTeam(ctx context.Context, teamID, roleID uuid.UUID) error {
+	if err := s.repo.AssignRoleToTeam(ctx, teamID, roleID); err != nil {
+		s.logger.WithError(err).Error("Failed to assign role to team")
+		return fmt.Errorf("failed to assign role to team: %w", err)
+	}
+
+	// Team members are not known here, so drop every cached permission set
+	if s.cache != nil {
+		s.cache.InvalidateAll()
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"team_id": teamID,
+		"role_id": roleID,
+	}).Info("Role assigned to team successfully")
+
+	return nil
+}
+
+func (s *AuthService) RemoveRoleFromTeam(ctx context.Context, teamID, roleID uuid.UUID) error {
+	if err := s.repo.RemoveRoleFromTeam(ctx, teamID, roleID); err != nil {
+		s.logger.WithError(err).Error("Failed to remove role from team")
+		return fmt.Errorf("failed to remove role from team: %w", err)
+	}
+
+	if s.cache != nil {
+		s.cache.InvalidateAll()
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"team_id": teamID,
+		"role_id": roleID,
+	}).Info("Role removed from team successfully")
+
+	return nil
+}
+
+func (s *AuthService) GetTeamRoles(ctx context.Context, teamID uuid.UUID) ([]models.Role, error) {
+	roles, err := s.repo.GetRolesForTeams(ctx, []uuid.UUID{teamID})
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get team roles")
+		return nil, fmt.Errorf("failed to get team roles: %w", err)
+	}
+
+	if roles == nil {
+		roles = []models.Role{}
+	}
+
+	return roles, nil
+}
+
 func (s *AuthService) UpdateUserRoles(ctx context.Context, userID uuid.UUID, roleIDs []uuid.UUID) error {
 	err := s.repo.UpdateUserRoles(ctx, userID, roleIDs)
 	if err != nil {
@@ -839,6 +940,37 @@ func (s *AuthService) UpdateUserRoles(ctx context.Context, userID uuid.UUID, rol
 	return nil
 }
 
+// PurgeUserData deletes all tokens, sessions and role assignments of an erased user
+func (s *AuthService) PurgeUserData(ctx context.Context, userID uuid.UUID) error {
+	if err := s.repo.PurgeUserData(ctx, userID); err != nil {
+		s.logger.WithError(err).Error("Failed to purge user data")
+		return fmt.Errorf("failed to purge user data: %w", err)
+	}
+
+	if s.cache != nil {
+		s.cache.Invalidate(userID.String())
+	}
+
+	s.logger.WithField("user_id", userID).Info("User auth data purged successfully")
+
+	return nil
+}
+
+// ListUserIDsByRole returns the IDs of the users holding the named role; unknown roles have no members
+func (s *AuthService) ListUserIDsByRole(ctx context.Context, roleName string) ([]uuid.UUID, error) {
+	userIDs, err := s.repo.ListUserIDsByRoleName(ctx, roleName)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list users by role")
+		return nil, fmt.Errorf("failed to list users by role: %w", err)
+	}
+
+	if userIDs == nil {
+		userIDs = []uuid.UUID{}
+	}
+
+	return userIDs, nil
+}
+
 func (s *AuthService) CheckPermission(ctx context.Context, userID, permission string) (bool, error) {
 	if s.cache != nil {
 		if cachedPerms, found := s.cache.GetPermissions(userID); found {
@@ -846,16 +978,11 @@ func (s *AuthService) CheckPermission(ctx context.Context, userID, permission st
 		}
 	}
 
-	permissions, err := s.repo.GetUserPermissions(ctx, uuid.MustParse(userID))
+	permNames, err := s.resolvePermissions(ctx, uuid.MustParse(userID))
 	if err != nil {
 		return false, err
 	}
 
-	permNames := make([]string, len(permissions))
-	for i, p := range permissions {
-		permNames[i] = p.Name
-	}
-
 	if s.cache != nil {
 		s.cache.SetPermissions(userID, permNames)
 	}
@@ -870,16 +997,11 @@ func (s *AuthService) GetUserPermissions(ctx context.Context, userID string) ([]
 		}
 	}
 
-	permissions, err := s.repo.GetUserPermissions(ctx, uuid.MustParse(userID))
+	permNames, err := s.resolvePermissions(ctx, uuid.MustParse(userID))
 	if err != nil {
 		return nil, err
 	}
 
-	permNames := make([]string, len(permissions))
-	for i, p := range permissions {
-		permNames[i] = p.Name
-	}
-
 	if s.cache != nil {
 		s.cache.SetPermissions(userID, permNames)
 	}
@@ -894,16 +1016,11 @@ func (s *AuthService) GetUserRolesSimple(ctx context.Context, userID string) ([]
 		}
 	}
 
-	roles, err := s.repo.GetUserRoles(ctx, uuid.MustParse(userID))
+	roleNames, _, err := s.resolveRoles(ctx, uuid.MustParse(userID))
 	if err != nil {
 		return nil, err
 	}
 
-	roleNames := make([]string, len(roles))
-	for i, r := range roles {
-		roleNames[i] = r.Name
-	}
-
 	if s.cache != nil {
 		s.cache.SetRoles(userID, roleNames)
 	}
@@ -911,6 +1028,88 @@ func (s *AuthService) GetUserRolesSimple(ctx context.Context, userID string) ([]
 	return roleNames, nil
 }
 
+// resolveRoles returns the names of the user's own roles and of the roles assigned to their teams,
+// together with the memberships to embed in access tokens
+func (s *AuthService) resolveRoles(ctx context.Context, userID uuid.UUID) ([]string, []middleware.MembershipClaim, error) {
+	roles, err := s.repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	memberships, teamIDs := s.userMemberships(ctx, userID)
+	teamRoles, err := s.repo.GetRolesForTeams(ctx, teamIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roleNames := make([]string, 0, len(roles)+len(teamRoles))
+	seen := make(map[string]bool, len(roles)+len(teamRoles))
+	for _, role := range append(roles, teamRoles...) {
+		if !seen[role.Name] {
+			seen[role.Name] = true
+			roleNames = append(roleNames, role.Name)
+		}
+	}
+
+	return roleNames, memberships, nil
+}
+
+// resolvePermissions returns the names of the permissions granted by the user's own and team-inherited roles
+func (s *AuthService) resolvePermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	permissions, err := s.repo.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, teamIDs := s.userMemberships(ctx, userID)
+	teamPermissions, err := s.repo.GetPermissionsForTeams(ctx, teamIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	permNames := make([]string, 0, len(permissions)+len(teamPermissions))
+	seen := make(map[string]bool, len(permissions)+len(teamPermissions))
+	for _, p := range append(permissions, teamPermissions...) {
+		if !seen[p.Name] {
+			seen[p.Name] = true
+			permNames = append(permNames, p.Name)
+		}
+	}
+
+	return permNames, nil
+}
+
+// userMemberships fetches the user's organization and team memberships from user-service.
+// If user-service cannot answer, the user is treated as having none and keeps only their own roles.
+func (s *AuthService) userMemberships(ctx context.Context, userID uuid.UUID) ([]middleware.MembershipClaim, []uuid.UUID) {
+	if s.userClient == nil {
+		return nil, nil
+	}
+
+	found, err := s.userClient.GetUserMemberships(ctx, userID)
+	if err != nil {
+		s.logger.WithError(err).WithField("user_id", userID).Warn("Failed to get user memberships, ignoring team roles")
+		return nil, nil
+	}
+
+	memberships := make([]middleware.MembershipClaim, len(found))
+	var teamIDs []uuid.UUID
+	for i, m := range found {
+		memberships[i] = middleware.MembershipClaim{
+			OrganizationID:   m.OrganizationID,
+			OrganizationSlug: m.OrganizationSlug,
+			TeamID:           m.TeamID,
+			TeamName:         m.TeamName,
+			Role:             m.Role,
+		}
+		if m.TeamID != nil {
+			teamIDs = append(teamIDs, *m.TeamID)
+		}
+	}
+
+	return memberships, teamIDs
+}
+
 func hasPermission(permissions []string, required string) bool {
 	for _, p := range permissions {
 		if p == required {
@@ -919,3 +1118,13 @@ func hasPermission(permissions []string, required string) bool {
 	}
 	return false
 }
+
+// checkAccountStatus rejects accounts that may not authenticate.
+// An empty status comes from user-service versions without account status and is treated as active.
+func checkAccountStatus(status string) error {
+	switch status {
+	case "suspended", "deactivated":
+		return models.NewAccountStatusError(status)
+	}
+	return nil
+}
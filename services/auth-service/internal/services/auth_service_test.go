@@ -9,6 +9,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
+	"github.com/v-egorov/service-boilerplate/services/auth-service/internal/cache"
 	"github.com/v-egorov/service-boilerplate/services/auth-service/internal/client"
 	"github.com/v-egorov/service-boilerplate/services/auth-service/internal/models"
 	"github.com/v-egorov/service-boilerplate/services/auth-service/internal/utils"
@@ -42,6 +44,12 @@ type MockAuthRepository struct {
 	assignRoleToUserFunc         func(ctx context.Context, userID, roleID uuid.UUID) error
 	removeRoleFromUserFunc       func(ctx context.Context, userID, roleID uuid.UUID) error
 	updateUserRolesFunc          func(ctx context.Context, userID uuid.UUID, roleIDs []uuid.UUID) error
+	purgeUserDataFunc            func(ctx context.Context, userID uuid.UUID) error
+	listUserIDsByRoleNameFunc    func(ctx context.Context, roleName string) ([]uuid.UUID, error)
+	assignRoleToTeamFunc         func(ctx context.Context, teamID, roleID uuid.UUID) error
+	removeRoleFromTeamFunc       func(ctx context.Context, teamID, roleID uuid.UUID) error
+	getRolesForTeamsFunc         func(ctx context.Context, teamIDs []uuid.UUID) ([]models.Role, error)
+	getPermissionsForTeamsFunc   func(ctx context.Context, teamIDs []uuid.UUID) ([]models.Permission, error)
 }
 
 func (m *MockAuthRepository) CreateAuthToken(ctx context.Context, token *models.AuthToken) error {
@@ -226,12 +234,55 @@ func (m *MockAuthRepository) UpdateUserRoles(ctx context.Context, userID uuid.UU
 	return nil
 }
 
+func (m *MockAuthRepository) PurgeUserData(ctx context.Context, userID uuid.UUID) error {
+	if m.purgeUserDataFunc != nil {
+		return m.purgeUserDataFunc(ctx, userID)
+	}
+	return nil
+}
+
+func (m *MockAuthRepository) ListUserIDsByRoleName(ctx context.Context, roleName string) ([]uuid.UUID, error) {
+	if m.listUserIDsByRoleNameFunc != nil {
+		return m.listUserIDsByRoleNameFunc(ctx, roleName)
+	}
+	return nil, nil
+}
+
+func (m *MockAuthRepository) AssignRoleToTeam(ctx context.Context, teamID, roleID uuid.UUID) error {
+	if m.assignRoleToTeamFunc != nil {
+		return m.assignRoleToTeamFunc(ctx, teamID, roleID)
+	}
+	return nil
+}
+
+func (m *MockAuthRepository) RemoveRoleFromTeam(ctx context.Context, teamID, roleID uuid.UUID) error {
+	if m.removeRoleFromTeamFunc != nil {
+		return m.removeRoleFromTeamFunc(ctx, teamID, roleID)
+	}
+	return nil
+}
+
+func (m *MockAuthRepository) GetRolesForTeams(ctx context.Context, teamIDs []uuid.UUID) ([]models.Role, error) {
+	if m.getRolesForTeamsFunc != nil {
+		return m.getRolesForTeamsFunc(ctx, teamIDs)
+	}
+	return nil, nil
+}
+
+func (m *MockAuthRepository) GetPermissionsForTeams(ctx context.Context, teamIDs []uuid.UUID) ([]models.Permission, error) {
+	if m.getPermissionsForTeamsFunc != nil {
+		return m.getPermissionsForTeamsFunc(ctx, teamIDs)
+	}
+	return nil, nil
+}
+
 // MockUserClient is a mock implementation of UserClient for testing
 type MockUserClient struct {
 	getUserWithPasswordByEmailFunc func(ctx context.Context, email string) (*client.UserLoginResponse, error)
 	getUserByIDFunc                func(ctx context.Context, userID uuid.UUID) (*client.UserData, error)
 	getUserByEmailFunc             func(ctx context.Context, email string) (*client.UserData, error)
 	createUserFunc                 func(ctx context.Context, req *client.CreateUserRequest) (*client.UserData, error)
+	getUserMembershipsFunc         func(ctx context.Context, userID uuid.UUID) ([]client.UserMembership, error)
 }
 
 func (m *MockUserClient) GetUserWithPasswordByEmail(ctx context.Context, email string) (*client.UserLoginResponse, error) {
@@ -262,17 +313,27 @@ func (m *MockUserClient) CreateUser(ctx context.Context, req *client.CreateUserR
 	return nil, errors.New("not implemented")
 }
 
+func (m *MockUserClient) GetUserMemberships(ctx context.Context, userID uuid.UUID) ([]client.UserMembership, error) {
+	if m.getUserMembershipsFunc != nil {
+		return m.getUserMembershipsFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
 // MockJWTUtils is a mock implementation of JWTUtils for testing
 type MockJWTUtils struct {
 	generateAccessTokenFunc  func(userID uuid.UUID, email string, roles []string, duration time.Duration) (string, error)
+	lastMemberships          []middleware.MembershipClaim
 	generateRefreshTokenFunc func(userID uuid.UUID, duration time.Duration) (string, error)
 	validateTokenFunc        func(tokenString string) (*utils.JWTClaims, error)
 	getPublicKeyPEMFunc      func() ([]byte, error)
 	rotateKeysFunc           func(ctx context.Context) error
 	getKeyIDFunc             func() string
+	verificationKeys         []*middleware.VerificationKey
 }
 
-func (m *MockJWTUtils) GenerateAccessToken(userID uuid.UUID, email string, roles []string, duration time.Duration) (string, error) {
+func (m *MockJWTUtils) GenerateAccessToken(userID uuid.UUID, email string, roles []string, memberships []middleware.MembershipClaim, duration time.Duration) (string, error) {
+	m.lastMemberships = memberships
 	if m.generateAccessTokenFunc != nil {
 		return m.generateAccessTokenFunc(userID, email, roles, duration)
 	}
@@ -307,6 +368,10 @@ func (m *MockJWTUtils) RotateKeys(ctx context.Context) error {
 	return nil
 }
 
+func (m *MockJWTUtils) GetVerificationKeys() []*middleware.VerificationKey {
+	return m.verificationKeys
+}
+
 func (m *MockJWTUtils) GetKeyID() string {
 	if m.getKeyIDFunc != nil {
 		return m.getKeyIDFunc()
@@ -608,6 +673,31 @@ func TestAuthService_Login(t *testing.T) {
 			expectedError:    "failed to generate access token: token generation failed",
 			expectTokens:     false,
 		},
+		{
+			name: "suspended account",
+			request: &models.LoginRequest{
+				Email:    "user@example.com",
+				Password: "password123",
+			},
+			ipAddress: "192.168.1.1",
+			userAgent: "Mozilla/5.0",
+			mockUserLogin: &client.UserLoginResponse{
+				Data: &struct {
+					User         *client.UserData `json:"user"`
+					PasswordHash string           `json:"password_hash"`
+				}{
+					User: &client.UserData{
+						ID:     uuid.New(),
+						Email:  "user@example.com",
+						Status: "suspended",
+					},
+					PasswordHash: "$2a$10$oolyJReLQIIPPeH4XPtEhukeV9D115vs.XbyNQfw/zlTsF4/q8nly",
+				},
+			},
+			mockUserError: nil,
+			expectedError: "account is suspended",
+			expectTokens:  false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -903,6 +993,7 @@ func TestAuthService_RefreshToken(t *testing.T) {
 		mockRefreshToken  string
 		mockTokenGenError error
 		mockRepoError     error
+		mockUserStatus    string
 		expectedError     string
 		expectTokens      bool
 	}{
@@ -987,6 +1078,26 @@ func TestAuthService_RefreshToken(t *testing.T) {
 			expectedError:     "failed to generate new access token: token generation failed",
 			expectTokens:      false,
 		},
+		{
+			name: "suspended account",
+			request: &models.RefreshTokenRequest{
+				RefreshToken: "refresh.jwt.token",
+			},
+			mockToken: &models.AuthToken{
+				ID:        uuid.New(),
+				UserID:    uuid.New(),
+				TokenHash: "token.hash",
+			},
+			mockClaims: &utils.JWTClaims{
+				UserID:    uuid.New(),
+				Email:     "user@example.com",
+				Roles:     []string{"user"},
+				TokenType: "refresh",
+			},
+			mockUserStatus: "suspended",
+			expectedError:  "account is suspended",
+			expectTokens:   false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1007,6 +1118,13 @@ func TestAuthService_RefreshToken(t *testing.T) {
 				}
 
 				if tt.mockClaimsError == nil && tt.mockClaims != nil {
+					mockUserClient.getUserByIDFunc = func(ctx context.Context, userID uuid.UUID) (*client.UserData, error) {
+						status := tt.mockUserStatus
+						if status == "" {
+							status = "active"
+						}
+						return &client.UserData{ID: userID, Email: tt.mockClaims.Email, Status: status}, nil
+					}
 					mockRepo.getUserRolesFunc = func(ctx context.Context, userID uuid.UUID) ([]models.Role, error) {
 						return []models.Role{}, nil
 					}
@@ -2402,3 +2520,244 @@ func TestAuthService_UpdateUserRoles(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestAuthService_PurgeUserData(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name             string
+		mockPurgeError   error
+		expectError      bool
+		expectedErrorMsg string
+	}{
+		{
+			name:        "successful purge",
+			expectError: false,
+		},
+		{
+			name:             "purge error",
+			mockPurgeError:   errors.New("delete failed"),
+			expectError:      true,
+			expectedErrorMsg: "failed to purge user data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var purgedID uuid.UUID
+			mockRepo := &MockAuthRepository{
+				purgeUserDataFunc: func(ctx context.Context, uid uuid.UUID) error {
+					purgedID = uid
+					return tt.mockPurgeError
+				},
+			}
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+			service := NewAuthService(mockRepo, nil, nil, logger)
+
+			err := service.PurgeUserData(context.Background(), userID)
+
+			assert.Equal(t, userID, purgedID)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErrorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestAuthService_ListUserIDsByRole(t *testing.T) {
+	memberID := uuid.New()
+
+	tests := []struct {
+		name        string
+		mockIDs     []uuid.UUID
+		mockError   error
+		expectError bool
+		expectedIDs []uuid.UUID
+	}{
+		{
+			name:        "role with members",
+			mockIDs:     []uuid.UUID{memberID},
+			expectedIDs: []uuid.UUID{memberID},
+		},
+		{
+			name:        "role without members",
+			mockIDs:     nil,
+			expectedIDs: []uuid.UUID{},
+		},
+		{
+			name:        "repository error",
+			mockError:   errors.New("query failed"),
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockAuthRepository{
+				listUserIDsByRoleNameFunc: func(ctx context.Context, roleName string) ([]uuid.UUID, error) {
+					assert.Equal(t, "editor", roleName)
+					return tt.mockIDs, tt.mockError
+				},
+			}
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+			service := NewAuthService(mockRepo, nil, nil, logger)
+
+			ids, err := service.ListUserIDsByRole(context.Background(), "editor")
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func TestAuthService_Login_InheritsTeamRoles(t *testing.T) {
+	userID := uuid.New()
+	orgID := uuid.New()
+	teamID := uuid.New()
+
+	mockUserClient := &MockUserClient{
+		getUserWithPasswordByEmailFunc: func(ctx context.Context, email string) (*client.UserLoginResponse, error) {
+			return &client.UserLoginResponse{
+				Data: &struct {
+					User         *client.UserData `json:"user"`
+					PasswordHash string           `json:"password_hash"`
+				}{
+					User:         &client.UserData{ID: userID, Email: email},
+					PasswordHash: "$2a$10$oolyJReLQIIPPeH4XPtEhukeV9D115vs.XbyNQfw/zlTsF4/q8nly",
+				},
+			}, nil
+		},
+		getUserMembershipsFunc: func(ctx context.Context, uid uuid.UUID) ([]client.UserMembership, error) {
+			return []client.UserMembership{
+				{OrganizationID: orgID, OrganizationSlug: "acme", Role: "member"},
+				{OrganizationID: orgID, OrganizationSlug: "acme", TeamID: &teamID, TeamName: "Finance", Role: "member"},
+			}, nil
+		},
+	}
+
+	var queriedTeams []uuid.UUID
+	mockRepo := &MockAuthRepository{
+		getUserRolesFunc: func(ctx context.Context, uid uuid.UUID) ([]models.Role, error) {
+			return []models.Role{{Name: "user"}}, nil
+		},
+		getRolesForTeamsFunc: func(ctx context.Context, teamIDs []uuid.UUID) ([]models.Role, error) {
+			queriedTeams = teamIDs
+			return []models.Role{{Name: "finance-approver"}, {Name: "user"}}, nil
+		},
+	}
+
+	var grantedRoles []string
+	mockJWTUtils := &MockJWTUtils{
+		generateAccessTokenFunc: func(uid uuid.UUID, email string, roles []string, duration time.Duration) (string, error) {
+			grantedRoles = roles
+			return "access.jwt.token", nil
+		},
+		generateRefreshTokenFunc: func(uid uuid.UUID, duration time.Duration) (string, error) {
+			return "refresh.jwt.token", nil
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	service := NewAuthService(mockRepo, mockUserClient, mockJWTUtils, logger)
+
+	_, err := service.Login(context.Background(), &models.LoginRequest{Email: "user@example.com", Password: "password123"}, "127.0.0.1", "test")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{teamID}, queriedTeams)
+	assert.Equal(t, []string{"user", "finance-approver"}, grantedRoles)
+	assert.Len(t, mockJWTUtils.lastMemberships, 2)
+	assert.Equal(t, "Finance", mockJWTUtils.lastMemberships[1].TeamName)
+}
+
+func TestAuthService_GetUserPermissions_TeamInherited(t *testing.T) {
+	userID := uuid.New()
+	teamID := uuid.New()
+
+	tests := []struct {
+		name           string
+		membershipsErr error
+		expected       []string
+	}{
+		{
+			name:     "direct and team permissions are merged",
+			expected: []string{"users:read", "invoices:approve"},
+		},
+		{
+			name:           "user-service unavailable falls back to direct permissions",
+			membershipsErr: errors.New("connection refused"),
+			expected:       []string{"users:read"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserClient := &MockUserClient{
+				getUserMembershipsFunc: func(ctx context.Context, uid uuid.UUID) ([]client.UserMembership, error) {
+					if tt.membershipsErr != nil {
+						return nil, tt.membershipsErr
+					}
+					return []client.UserMembership{{OrganizationID: uuid.New(), TeamID: &teamID, Role: "member"}}, nil
+				},
+			}
+			mockRepo := &MockAuthRepository{
+				getUserPermissionsFunc: func(ctx context.Context, uid uuid.UUID) ([]models.Permission, error) {
+					return []models.Permission{{Name: "users:read"}}, nil
+				},
+				getPermissionsForTeamsFunc: func(ctx context.Context, teamIDs []uuid.UUID) ([]models.Permission, error) {
+					if len(teamIDs) == 0 {
+						return nil, nil
+					}
+					return []models.Permission{{Name: "invoices:approve"}, {Name: "users:read"}}, nil
+				},
+			}
+
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+			service := NewAuthService(mockRepo, mockUserClient, nil, logger)
+
+			permissions, err := service.GetUserPermissions(context.Background(), userID.String())
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, permissions)
+		})
+	}
+}
+
+func TestAuthService_AssignRoleToTeam(t *testing.T) {
+	teamID := uuid.New()
+	roleID := uuid.New()
+
+	var assignedTeam, assignedRole uuid.UUID
+	mockRepo := &MockAuthRepository{
+		assignRoleToTeamFunc: func(ctx context.Context, tid, rid uuid.UUID) error {
+			assignedTeam, assignedRole = tid, rid
+			return nil
+		},
+	}
+
+	permCache := cache.NewPermissionCache(cache.PermissionCacheConfig{})
+	permCache.SetPermissions(uuid.New().String(), []string{"users:read"})
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	service := NewAuthServiceWithCache(mockRepo, nil, nil, logger, permCache)
+
+	err := service.AssignRoleToTeam(context.Background(), teamID, roleID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, teamID, assignedTeam)
+	assert.Equal(t, roleID, assignedRole)
+	assert.Equal(t, 0, permCache.Size(), "team members are unknown, so every cached entry must be dropped")
+}
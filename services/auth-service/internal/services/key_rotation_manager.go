@@ -179,8 +179,9 @@ func (krm *KeyRotationManager) performRotation(ctx context.Context, reason strin
 	}
 
 	krm.logger.WithFields(logrus.Fields{
-		"reason":  reason,
-		"new_key": krm.jwtUtils.GetKeyID(),
+		"reason":    reason,
+		"new_key":   krm.jwtUtils.GetKeyID(),
+		"algorithm": krm.jwtUtils.GetAlgorithm(),
 	}).Info("JWT key rotation completed successfully")
 
 	return nil
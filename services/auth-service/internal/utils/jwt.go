@@ -2,6 +2,10 @@ package utils
 
 import (
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -16,13 +20,21 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
 )
 
+// DefaultKeyOverlap is how long a retired key keeps verifying tokens after rotation
+const DefaultKeyOverlap = 60 * time.Minute
+
+// minKeyReloadInterval throttles database reloads triggered by tokens with unknown key IDs
+const minKeyReloadInterval = 10 * time.Second
+
 type JWTClaims struct {
-	UserID    uuid.UUID `json:"user_id"`
-	Email     string    `json:"email"`
-	Roles     []string  `json:"roles"`
-	TokenType string    `json:"token_type"`
+	UserID      uuid.UUID                    `json:"user_id"`
+	Email       string                       `json:"email"`
+	Roles       []string                     `json:"roles"`
+	Memberships []middleware.MembershipClaim `json:"memberships,omitempty"`
+	TokenType   string                       `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
@@ -41,53 +53,113 @@ type JWTKey struct {
 	Metadata       json.RawMessage `db:"metadata"`
 }
 
+// KeyConfig holds signing key configuration for JWTUtils
+type KeyConfig struct {
+	Algorithm string        // RS256, ES256 or EdDSA
+	Overlap   time.Duration // How long retired keys remain valid for verification
+}
+
 type JWTUtils struct {
 	mu         sync.RWMutex
-	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
+	privateKey crypto.Signer
+	publicKey  crypto.PublicKey
 	keyID      string
-	db         *pgxpool.Pool
+	algorithm  string
+	config     KeyConfig
+	// verificationKeys holds the active key and retired keys still inside their overlap window
+	verificationKeys map[string]*middleware.VerificationKey
+	keysLoadedAt     time.Time
+	db               *pgxpool.Pool
 }
 
 func NewJWTUtils(db *pgxpool.Pool) (*JWTUtils, error) {
-	utils := &JWTUtils{db: db}
+	return NewJWTUtilsWithConfig(db, KeyConfig{Algorithm: middleware.AlgorithmRS256, Overlap: DefaultKeyOverlap})
+}
+
+// NewJWTUtilsWithConfig creates JWT utilities signing with the configured algorithm.
+// The configured algorithm is the only source of the signing algorithm: if the active key
+// in the database uses a different one, a new key is generated and the previous one stays
+// valid for verification during the overlap window.
+func NewJWTUtilsWithConfig(db *pgxpool.Pool, config KeyConfig) (*JWTUtils, error) {
+	if config.Algorithm == "" {
+		config.Algorithm = middleware.AlgorithmRS256
+	}
+	if !middleware.IsSupportedAlgorithm(config.Algorithm) {
+		return nil, fmt.Errorf("unsupported JWT signing algorithm: %s", config.Algorithm)
+	}
+	if config.Overlap <= 0 {
+		config.Overlap = DefaultKeyOverlap
+	}
+
+	utils := &JWTUtils{db: db, config: config}
+	ctx := context.Background()
 
 	// Try to load existing active key from database
-	existingKey, err := utils.loadActiveKey(context.Background())
-	if err == nil && existingKey != nil {
-		// Successfully loaded existing key
-		privateKey, err := utils.parsePrivateKeyPEM(existingKey.PrivateKeyPEM)
-		if err != nil {
+	existingKey, err := utils.loadActiveKey(ctx)
+	if err == nil && existingKey != nil && existingKey.Algorithm == config.Algorithm {
+		if err := utils.setActiveKey(existingKey); err != nil {
 			return nil, fmt.Errorf("failed to parse existing private key: %w", err)
 		}
-
-		utils.privateKey = privateKey
-		utils.publicKey = &privateKey.PublicKey
-		utils.keyID = existingKey.KeyID
+		if err := utils.reloadVerificationKeys(ctx); err != nil {
+			return nil, err
+		}
 		return utils, nil
 	}
 
-	// No existing key found, generate new one
-	return utils.generateAndStoreNewKey(context.Background())
+	reason := "manual"
+	if existingKey != nil {
+		reason = "algorithm_change"
+	}
+
+	// No usable key found, generate new one
+	if err := utils.rotateKeys(ctx, reason, config.Algorithm); err != nil {
+		return nil, err
+	}
+	return utils, nil
+}
+
+// generateSigningKey creates a new key pair for the given algorithm along with key metadata
+func generateSigningKey(algorithm string) (crypto.Signer, json.RawMessage, error) {
+	switch algorithm {
+	case middleware.AlgorithmRS256:
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		}
+		return privateKey, json.RawMessage(`{"key_size": 2048}`), nil
+	case middleware.AlgorithmES256:
+		privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate ECDSA key: %w", err)
+		}
+		return privateKey, json.RawMessage(`{"curve": "P-256"}`), nil
+	case middleware.AlgorithmEdDSA:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate Ed25519 key: %w", err)
+		}
+		return privateKey, json.RawMessage(`{"curve": "Ed25519"}`), nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported JWT signing algorithm: %s", algorithm)
+	}
 }
 
-func (j *JWTUtils) generateAndStoreNewKeyWithReason(ctx context.Context, reason string) (*JWTUtils, error) {
-	// Generate RSA key pair for JWT signing
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+func (j *JWTUtils) generateAndStoreNewKey(ctx context.Context, reason, algorithm string) (*JWTKey, error) {
+	privateKey, metadata, err := generateSigningKey(algorithm)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+		return nil, err
 	}
 
 	// Generate unique key ID
 	keyID := fmt.Sprintf("jwt-key-%s", uuid.New().String()[:8])
 
 	// Convert keys to PEM format
-	privateKeyPEM, err := j.privateKeyToPEM(privateKey)
+	privateKeyPEM, err := privateKeyToPEM(privateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode private key: %w", err)
 	}
 
-	publicKeyPEM, err := j.publicKeyToPEM(&privateKey.PublicKey)
+	publicKeyPEM, err := publicKeyToPEM(privateKey.Public())
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode public key: %w", err)
 	}
@@ -98,39 +170,44 @@ func (j *JWTUtils) generateAndStoreNewKeyWithReason(ctx context.Context, reason
 		KeyID:          keyID,
 		PrivateKeyPEM:  string(privateKeyPEM),
 		PublicKeyPEM:   string(publicKeyPEM),
-		Algorithm:      "RS256",
+		Algorithm:      algorithm,
 		IsActive:       true,
 		RotationReason: &reason,
 		RotatedAt:      &now,
-		Metadata:       json.RawMessage(`{"key_size": 2048}`),
+		Metadata:       metadata,
 	}
 
 	if err := j.storeKey(ctx, jwtKey); err != nil {
 		return nil, fmt.Errorf("failed to store JWT key: %w", err)
 	}
 
-	return &JWTUtils{
-		privateKey: privateKey,
-		publicKey:  &privateKey.PublicKey,
-		keyID:      keyID,
-		db:         j.db,
-	}, nil
+	return jwtKey, nil
 }
 
-func (j *JWTUtils) generateAndStoreNewKey(ctx context.Context) (*JWTUtils, error) {
-	return j.generateAndStoreNewKeyWithReason(ctx, "manual")
-}
+// setActiveKey parses a stored key and makes it the in-memory signing key.
+// Callers that share the instance must hold j.mu.
+func (j *JWTUtils) setActiveKey(key *JWTKey) error {
+	privateKey, err := parsePrivateKeyPEM(key.PrivateKeyPEM, key.Algorithm)
+	if err != nil {
+		return err
+	}
 
-func (j *JWTUtils) GenerateAccessToken(userID uuid.UUID, email string, roles []string, expiration time.Duration) (string, error) {
-	j.mu.RLock()
-	defer j.mu.RUnlock()
+	j.privateKey = privateKey
+	j.publicKey = privateKey.Public()
+	j.keyID = key.KeyID
+	j.algorithm = key.Algorithm
+	return nil
+}
 
+// GenerateAccessToken issues an access token carrying the user's roles and organization/team memberships
+func (j *JWTUtils) GenerateAccessToken(userID uuid.UUID, email string, roles []string, memberships []middleware.MembershipClaim, expiration time.Duration) (string, error) {
 	now := time.Now()
 	claims := JWTClaims{
-		UserID:    userID,
-		Email:     email,
-		Roles:     roles,
-		TokenType: "access",
+		UserID:      userID,
+		Email:       email,
+		Roles:       roles,
+		Memberships: memberships,
+		TokenType:   "access",
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    "auth-service",
 			Subject:   userID.String(),
@@ -142,8 +219,7 @@ func (j *JWTUtils) GenerateAccessToken(userID uuid.UUID, email string, roles []s
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	return token.SignedString(j.privateKey)
+	return j.sign(claims)
 }
 
 func (j *JWTUtils) GenerateRefreshToken(userID uuid.UUID, expiration time.Duration) (string, error) {
@@ -162,18 +238,26 @@ func (j *JWTUtils) GenerateRefreshToken(userID uuid.UUID, expiration time.Durati
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	return j.sign(claims)
+}
+
+// sign signs claims with the active key, recording the key ID in the "kid" header
+func (j *JWTUtils) sign(claims jwt.Claims) (string, error) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	method := jwt.GetSigningMethod(j.algorithm)
+	if method == nil {
+		return "", fmt.Errorf("unsupported JWT signing algorithm: %s", j.algorithm)
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = j.keyID
 	return token.SignedString(j.privateKey)
 }
 
 func (j *JWTUtils) ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return j.publicKey, nil
-	})
-
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, middleware.NewKeyFunc(j))
 	if err != nil {
 		return nil, err
 	}
@@ -185,32 +269,63 @@ func (j *JWTUtils) ValidateToken(tokenString string) (*JWTClaims, error) {
 	return nil, errors.New("invalid token")
 }
 
-func (j *JWTUtils) GetPublicKeyPEM() ([]byte, error) {
-	publicKeyBytes, err := x509.MarshalPKIXPublicKey(j.publicKey)
-	if err != nil {
-		return nil, err
+// ResolveVerificationKey implements middleware.KeyResolver.
+// Tokens without a "kid" (issued before key IDs were embedded) resolve to the active key.
+func (j *JWTUtils) ResolveVerificationKey(keyID string) (*middleware.VerificationKey, error) {
+	j.mu.RLock()
+	if keyID == "" {
+		keyID = j.keyID
+	}
+	key, ok := j.verificationKeys[keyID]
+	stale := time.Since(j.keysLoadedAt) >= minKeyReloadInterval
+	j.mu.RUnlock()
+	if ok {
+		return key, nil
+	}
+	if j.db == nil || !stale {
+		return nil, middleware.ErrUnknownKeyID
 	}
 
-	publicKeyPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PUBLIC KEY",
-		Bytes: publicKeyBytes,
-	})
+	// Another instance may have rotated keys since our last refresh
+	if err := j.reloadVerificationKeys(context.Background()); err != nil {
+		return nil, err
+	}
 
-	return publicKeyPEM, nil
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	if key, ok := j.verificationKeys[keyID]; ok {
+		return key, nil
+	}
+	return nil, middleware.ErrUnknownKeyID
 }
 
-func (j *JWTUtils) GetPrivateKeyPEM() ([]byte, error) {
-	privateKeyBytes := x509.MarshalPKCS1PrivateKey(j.privateKey)
+// GetVerificationKeys returns the active key and all retired keys still within their overlap window
+func (j *JWTUtils) GetVerificationKeys() []*middleware.VerificationKey {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
 
-	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: privateKeyBytes,
-	})
+	keys := make([]*middleware.VerificationKey, 0, len(j.verificationKeys))
+	for _, key := range j.verificationKeys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (j *JWTUtils) GetPublicKeyPEM() ([]byte, error) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return publicKeyToPEM(j.publicKey)
+}
 
-	return privateKeyPEM, nil
+func (j *JWTUtils) GetPrivateKeyPEM() ([]byte, error) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return privateKeyToPEM(j.privateKey)
 }
 
-func (j *JWTUtils) GetPublicKey() *rsa.PublicKey {
+func (j *JWTUtils) GetPublicKey() crypto.PublicKey {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
 	return j.publicKey
 }
 
@@ -220,34 +335,32 @@ func (j *JWTUtils) GetKeyID() string {
 	return j.keyID
 }
 
+// GetAlgorithm returns the signing algorithm of the active key
+func (j *JWTUtils) GetAlgorithm() string {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.algorithm
+}
+
 // RefreshActiveKey refreshes the active key from database if it has changed
 func (j *JWTUtils) RefreshActiveKey(ctx context.Context) error {
-	j.mu.Lock()
-	defer j.mu.Unlock()
-
 	// Load current active key from database
 	activeKey, err := j.loadActiveKey(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to refresh active key: %w", err)
 	}
 
-	// If no active key or key has changed, update in-memory instance
-	if activeKey != nil && activeKey.KeyID != j.keyID {
-		privateKey, err := j.parsePrivateKeyPEM(activeKey.PrivateKeyPEM)
+	// If the key has changed, update in-memory instance
+	if activeKey != nil && activeKey.KeyID != j.GetKeyID() {
+		j.mu.Lock()
+		err := j.setActiveKey(activeKey)
+		j.mu.Unlock()
 		if err != nil {
 			return fmt.Errorf("failed to parse refreshed key: %w", err)
 		}
-
-		j.mu.Lock()
-		defer j.mu.Unlock()
-
-		j.privateKey = privateKey
-		j.publicKey = &privateKey.PublicKey
-		j.keyID = activeKey.KeyID
-		return fmt.Errorf("JWT key refreshed from database - key_id changed from %s to %s", j.keyID, activeKey.KeyID)
 	}
 
-	return nil
+	return j.reloadVerificationKeys(ctx)
 }
 
 // StartKeyRefresher starts a background goroutine to periodically refresh JWT keys
@@ -270,24 +383,38 @@ func (j *JWTUtils) StartKeyRefresher(ctx context.Context, interval time.Duration
 	}()
 }
 
-// RotateKeys generates a new RSA key pair and replaces the current active key
+// RotateKeys generates a new key pair and replaces the current active key
 func (j *JWTUtils) RotateKeys(ctx context.Context) error {
 	return j.RotateKeysWithReason(ctx, "manual")
 }
 
-// RotateKeysWithReason generates a new RSA key pair and replaces the current active key with a specific reason
+// RotateKeysWithReason generates a new key pair for the configured algorithm and replaces the
+// current active key with a specific reason
 func (j *JWTUtils) RotateKeysWithReason(ctx context.Context, reason string) error {
-	newUtils, err := j.generateAndStoreNewKeyWithReason(ctx, reason)
+	return j.rotateKeys(ctx, reason, j.config.Algorithm)
+}
+
+// rotateKeys generates a new key pair for the given algorithm and makes it active.
+// The previous key keeps verifying already issued tokens until its overlap window ends.
+func (j *JWTUtils) rotateKeys(ctx context.Context, reason, algorithm string) error {
+	if !middleware.IsSupportedAlgorithm(algorithm) {
+		return fmt.Errorf("unsupported JWT signing algorithm: %s", algorithm)
+	}
+
+	newKey, err := j.generateAndStoreNewKey(ctx, reason, algorithm)
 	if err != nil {
 		return fmt.Errorf("failed to generate and store new key: %w", err)
 	}
 
 	// Update the current instance with the new key
-	j.privateKey = newUtils.privateKey
-	j.publicKey = newUtils.publicKey
-	j.keyID = newUtils.keyID
+	j.mu.Lock()
+	err = j.setActiveKey(newKey)
+	j.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to activate new key: %w", err)
+	}
 
-	return nil
+	return j.reloadVerificationKeys(ctx)
 }
 
 // loadActiveKey loads the active JWT key from the database
@@ -315,10 +442,64 @@ func (j *JWTUtils) loadActiveKey(ctx context.Context) (*JWTKey, error) {
 	return &key, nil
 }
 
-// storeKey stores a JWT key in the database
+// reloadVerificationKeys loads the active key and retired keys still inside their overlap window
+func (j *JWTUtils) reloadVerificationKeys(ctx context.Context) error {
+	query := `
+		SELECT key_id, public_key_pem, algorithm
+		FROM auth_service.jwt_keys
+		WHERE is_active = true OR expires_at > CURRENT_TIMESTAMP
+	`
+
+	rows, err := j.db.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT verification keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make(map[string]*middleware.VerificationKey)
+	for rows.Next() {
+		var keyID, publicKeyPEM, algorithm string
+		if err := rows.Scan(&keyID, &publicKeyPEM, &algorithm); err != nil {
+			return fmt.Errorf("failed to scan JWT verification key: %w", err)
+		}
+
+		publicKey, err := middleware.ParsePublicKeyPEM([]byte(publicKeyPEM))
+		if err != nil {
+			return fmt.Errorf("failed to parse public key %s: %w", keyID, err)
+		}
+
+		keys[keyID] = &middleware.VerificationKey{
+			KeyID:     keyID,
+			Algorithm: algorithm,
+			PublicKey: publicKey,
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate JWT verification keys: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.verificationKeys = keys
+	j.keysLoadedAt = time.Now()
+	return nil
+}
+
+// storeKey stores a JWT key in the database, retiring the current active key.
+// The retired key gets an expires_at of now plus the overlap window so it keeps verifying tokens.
 func (j *JWTUtils) storeKey(ctx context.Context, key *JWTKey) error {
+	tx, err := j.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	// First, deactivate any existing active keys
-	_, err := j.db.Exec(ctx, "UPDATE auth_service.jwt_keys SET is_active = false WHERE is_active = true")
+	_, err = tx.Exec(ctx, `
+		UPDATE auth_service.jwt_keys
+		SET is_active = false, expires_at = $1
+		WHERE is_active = true
+	`, time.Now().Add(j.config.Overlap))
 	if err != nil {
 		return fmt.Errorf("failed to deactivate existing keys: %w", err)
 	}
@@ -329,52 +510,92 @@ func (j *JWTUtils) storeKey(ctx context.Context, key *JWTKey) error {
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
-	_, err = j.db.Exec(ctx, query,
+	_, err = tx.Exec(ctx, query,
 		key.KeyID, key.PrivateKeyPEM, key.PublicKeyPEM, key.Algorithm, key.IsActive, key.RotationReason, key.RotatedAt, key.Metadata)
 	if err != nil {
 		return fmt.Errorf("failed to insert JWT key: %w", err)
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit JWT key: %w", err)
+	}
+
 	return nil
 }
 
-// parsePrivateKeyPEM parses a PEM-encoded private key
-func (j *JWTUtils) parsePrivateKeyPEM(pemData string) (*rsa.PrivateKey, error) {
+// parsePrivateKeyPEM parses a PEM-encoded private key and checks it matches the recorded algorithm
+func parsePrivateKeyPEM(pemData, algorithm string) (crypto.Signer, error) {
 	block, _ := pem.Decode([]byte(pemData))
 	if block == nil {
 		return nil, errors.New("failed to decode PEM block")
 	}
 
-	if block.Type != "RSA PRIVATE KEY" {
+	var privateKey interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
 		return nil, fmt.Errorf("unexpected PEM block type: %s", block.Type)
 	}
-
-	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse private key: %w", err)
 	}
 
-	return privateKey, nil
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type: %T", privateKey)
+	}
+
+	keyAlgorithm, err := middleware.AlgorithmForKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	if keyAlgorithm != algorithm {
+		return nil, fmt.Errorf("stored key is %s but recorded algorithm is %s", keyAlgorithm, algorithm)
+	}
+
+	return signer, nil
 }
 
-// privateKeyToPEM converts an RSA private key to PEM format
-func (j *JWTUtils) privateKeyToPEM(privateKey *rsa.PrivateKey) ([]byte, error) {
-	privateKeyBytes := x509.MarshalPKCS1PrivateKey(privateKey)
+// privateKeyToPEM converts a private key to PEM format.
+// RSA keys keep the PKCS#1 encoding used by existing stored keys; other keys use PKCS#8.
+func privateKeyToPEM(privateKey crypto.Signer) ([]byte, error) {
+	if rsaKey, ok := privateKey.(*rsa.PrivateKey); ok {
+		return pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+		}), nil
+	}
+
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
 	return pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PRIVATE KEY",
+		Type:  "PRIVATE KEY",
 		Bytes: privateKeyBytes,
 	}), nil
 }
 
-// publicKeyToPEM converts an RSA public key to PEM format
-func (j *JWTUtils) publicKeyToPEM(publicKey *rsa.PublicKey) ([]byte, error) {
+// publicKeyToPEM converts a public key to PKIX PEM format
+func publicKeyToPEM(publicKey crypto.PublicKey) ([]byte, error) {
 	publicKeyBytes, err := x509.MarshalPKIXPublicKey(publicKey)
 	if err != nil {
 		return nil, err
 	}
 
+	blockType := "PUBLIC KEY"
+	if _, ok := publicKey.(*rsa.PublicKey); ok {
+		// Existing consumers expect the historical label for RSA keys
+		blockType = "RSA PUBLIC KEY"
+	}
+
 	return pem.EncodeToMemory(&pem.Block{
-		Type:  "RSA PUBLIC KEY",
+		Type:  blockType,
 		Bytes: publicKeyBytes,
 	}), nil
 }
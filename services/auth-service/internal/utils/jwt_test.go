@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
+)
+
+// newTestJWTUtils builds JWTUtils with an in-memory key, bypassing database storage
+func newTestJWTUtils(t *testing.T, algorithm string) *JWTUtils {
+	t.Helper()
+
+	privateKey, _, err := generateSigningKey(algorithm)
+	require.NoError(t, err)
+
+	keyID := "test-" + algorithm
+	return &JWTUtils{
+		privateKey: privateKey,
+		publicKey:  privateKey.Public(),
+		keyID:      keyID,
+		algorithm:  algorithm,
+		config:     KeyConfig{Algorithm: algorithm, Overlap: DefaultKeyOverlap},
+		verificationKeys: map[string]*middleware.VerificationKey{
+			keyID: {KeyID: keyID, Algorithm: algorithm, PublicKey: privateKey.Public()},
+		},
+		keysLoadedAt: time.Now(),
+	}
+}
+
+func TestJWTUtils_SignAndValidate(t *testing.T) {
+	for _, algorithm := range []string{middleware.AlgorithmRS256, middleware.AlgorithmES256, middleware.AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			j := newTestJWTUtils(t, algorithm)
+			userID := uuid.New()
+
+			tokenString, err := j.GenerateAccessToken(userID, "user@example.com", []string{"user"}, nil, time.Minute)
+			require.NoError(t, err)
+
+			token, _, err := jwt.NewParser().ParseUnverified(tokenString, &JWTClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, token.Header["alg"])
+			assert.Equal(t, j.GetKeyID(), token.Header["kid"])
+
+			claims, err := j.ValidateToken(tokenString)
+			require.NoError(t, err)
+			assert.Equal(t, userID, claims.UserID)
+			assert.Equal(t, "access", claims.TokenType)
+		})
+	}
+}
+
+func TestJWTUtils_AccessTokenCarriesMemberships(t *testing.T) {
+	j := newTestJWTUtils(t, middleware.AlgorithmRS256)
+	teamID := uuid.New()
+	memberships := []middleware.MembershipClaim{
+		{OrganizationID: uuid.New(), OrganizationSlug: "acme", Role: "owner"},
+		{OrganizationID: uuid.New(), OrganizationSlug: "acme", TeamID: &teamID, TeamName: "Finance", Role: "member"},
+	}
+
+	tokenString, err := j.GenerateAccessToken(uuid.New(), "user@example.com", []string{"user"}, memberships, time.Minute)
+	require.NoError(t, err)
+
+	claims, err := j.ValidateToken(tokenString)
+	require.NoError(t, err)
+	assert.Equal(t, memberships, claims.Memberships)
+}
+
+func TestJWTUtils_OverlapKeyStillValid(t *testing.T) {
+	j := newTestJWTUtils(t, middleware.AlgorithmRS256)
+	oldToken, err := j.GenerateRefreshToken(uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	// Simulate a rotation to ES256 that keeps the retired RSA key for verification
+	next := newTestJWTUtils(t, middleware.AlgorithmES256)
+	j.privateKey, j.publicKey, j.keyID, j.algorithm = next.privateKey, next.publicKey, next.keyID, next.algorithm
+	j.verificationKeys[next.keyID] = next.verificationKeys[next.keyID]
+
+	newToken, err := j.GenerateRefreshToken(uuid.New(), time.Minute)
+	require.NoError(t, err)
+
+	_, err = j.ValidateToken(oldToken)
+	assert.NoError(t, err)
+	_, err = j.ValidateToken(newToken)
+	assert.NoError(t, err)
+
+	// Once the overlap window ends the retired key is dropped
+	delete(j.verificationKeys, "test-RS256")
+	_, err = j.ValidateToken(oldToken)
+	assert.Error(t, err)
+}
+
+func TestJWTUtils_RejectsNoneAndAlgorithmConfusion(t *testing.T) {
+	j := newTestJWTUtils(t, middleware.AlgorithmRS256)
+	claims := JWTClaims{
+		UserID:    uuid.New(),
+		TokenType: "access",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+
+	t.Run("none algorithm", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+		token.Header["kid"] = j.GetKeyID()
+		tokenString, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+
+		_, err = j.ValidateToken(tokenString)
+		assert.Error(t, err)
+	})
+
+	t.Run("HMAC signed with public key PEM", func(t *testing.T) {
+		publicKeyPEM, err := j.GetPublicKeyPEM()
+		require.NoError(t, err)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = j.GetKeyID()
+		tokenString, err := token.SignedString(publicKeyPEM)
+		require.NoError(t, err)
+
+		_, err = j.ValidateToken(tokenString)
+		assert.Error(t, err)
+	})
+
+	t.Run("algorithm differs from declared key algorithm", func(t *testing.T) {
+		other := newTestJWTUtils(t, middleware.AlgorithmES256)
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+		// Reference the RSA key ID while signing with an unrelated ES256 key
+		token.Header["kid"] = j.GetKeyID()
+		tokenString, err := token.SignedString(other.privateKey)
+		require.NoError(t, err)
+
+		_, err = j.ValidateToken(tokenString)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown key ID", func(t *testing.T) {
+		other := newTestJWTUtils(t, middleware.AlgorithmES256)
+		tokenString, err := other.GenerateAccessToken(uuid.New(), "user@example.com", nil, nil, time.Minute)
+		require.NoError(t, err)
+
+		_, err = j.ValidateToken(tokenString)
+		assert.Error(t, err)
+	})
+}
+
+func TestPrivateKeyPEMRoundTrip(t *testing.T) {
+	for _, algorithm := range []string{middleware.AlgorithmRS256, middleware.AlgorithmES256, middleware.AlgorithmEdDSA} {
+		t.Run(algorithm, func(t *testing.T) {
+			privateKey, _, err := generateSigningKey(algorithm)
+			require.NoError(t, err)
+
+			pemData, err := privateKeyToPEM(privateKey)
+			require.NoError(t, err)
+
+			parsed, err := parsePrivateKeyPEM(string(pemData), algorithm)
+			require.NoError(t, err)
+			assert.Equal(t, privateKey.Public(), parsed.Public())
+
+			publicKeyPEM, err := publicKeyToPEM(privateKey.Public())
+			require.NoError(t, err)
+			publicKey, err := middleware.ParsePublicKeyPEM(publicKeyPEM)
+			require.NoError(t, err)
+			assert.Equal(t, privateKey.Public(), publicKey)
+		})
+	}
+
+	t.Run("recorded algorithm mismatch", func(t *testing.T) {
+		privateKey, _, err := generateSigningKey(middleware.AlgorithmES256)
+		require.NoError(t, err)
+		pemData, err := privateKeyToPEM(privateKey)
+		require.NoError(t, err)
+
+		_, err = parsePrivateKeyPEM(string(pemData), middleware.AlgorithmRS256)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,5 @@
+-- Environment: all
+-- Migration Rollback: 000011_create_team_roles
+-- Description: Remove team role assignments from auth_service
+
+DROP TABLE IF EXISTS auth_service.team_roles;
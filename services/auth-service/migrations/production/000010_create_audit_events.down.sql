@@ -0,0 +1,6 @@
+-- Environment: all
+-- Migration Rollback: 000010_create_audit_events
+-- Description: Remove audit trail table from auth_service
+
+DROP TABLE IF EXISTS auth_service.audit_events CASCADE;
+DROP FUNCTION IF EXISTS auth_service.audit_events_append_only();
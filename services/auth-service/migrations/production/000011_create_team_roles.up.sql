@@ -0,0 +1,14 @@
+-- Environment: all
+-- Migration: 000011_create_team_roles
+-- Description: Role assignments targeting user-service teams; every team member inherits them
+
+-- team_id references user_service.teams, which lives in another service's schema,
+-- so it is not a foreign key. Rows of deleted teams are harmless: no user resolves to them.
+CREATE TABLE IF NOT EXISTS auth_service.team_roles (
+    team_id UUID NOT NULL,
+    role_id UUID NOT NULL REFERENCES auth_service.roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, role_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_team_roles_role_id ON auth_service.team_roles(role_id);
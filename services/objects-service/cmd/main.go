@@ -91,6 +91,16 @@ func main() {
 	var relationshipTypeHandler *handlers.RelationshipTypeHandler
 	var relationshipHandler *handlers.RelationshipHandler
 	var healthHandler *handlers.HealthHandler
+	var auditSink *logging.BufferedAuditSink
+	var auditQueryHandler *logging.AuditQueryHandler
+	var eventHandler *handlers.EventHandler
+	var webhookHandler *handlers.WebhookHandler
+	var taxonomyHandler *handlers.TaxonomyHandler
+	var transferHandler *handlers.TransferHandler
+	var concreteTableHandler *handlers.ConcreteTableHandler
+	var stopDispatcher context.CancelFunc
+	var stopWebhooks context.CancelFunc
+	var stopTrashPurger context.CancelFunc
 
 	if db != nil {
 		// Initialize new repository layer
@@ -102,19 +112,94 @@ func main() {
 		objectRepo := repository.NewObjectRepository(pgDatabase, repoOptions)
 		relationshipTypeRepo := repository.NewRelationshipTypeRepository(pgDatabase, repoOptions)
 
-		// Initialize services
-		objectTypeService := services.NewObjectTypeService(objectTypeRepo)
-		objectService := services.NewObjectService(objectRepo, objectTypeRepo)
-		relationshipTypeService := services.NewRelationshipTypeService(relationshipTypeRepo)
+		outboxRepo := repository.NewOutboxRepository(pgDatabase, repoOptions)
+		webhookRepo := repository.NewWebhookRepository(pgDatabase, repoOptions)
+		concreteTableRepo := repository.NewConcreteTableRepository(pgDatabase, repoOptions)
+
+		// Initialize services; changes run in transactions that also write their outbox events
+		txDB := services.NewTransactionalDB(db.GetPool())
+		objectTypeService := services.NewObjectTypeServiceWithTx(objectTypeRepo, txDB)
+		objectService := services.NewObjectServiceWithTx(objectRepo, objectTypeRepo, txDB)
+		relationshipTypeService := services.NewRelationshipTypeServiceWithTx(relationshipTypeRepo, txDB)
 		relationshipRepo := repository.NewRelationshipRepository(pgDatabase, repoOptions, objectRepo)
-		relationshipService := services.NewRelationshipService(relationshipRepo, relationshipTypeRepo, objectRepo)
+		relationshipService := services.NewRelationshipServiceWithTx(relationshipRepo, relationshipTypeRepo, objectRepo, txDB)
 
 		// Initialize handlers
 		objectTypeHandler = handlers.NewObjectTypeHandler(objectTypeService, logger.Logger)
 		objectHandler = handlers.NewObjectHandler(objectService, logger.Logger)
+		objectHandler.SetCloneService(services.NewCloneService(objectRepo, objectTypeRepo, relationshipRepo, relationshipTypeRepo, txDB))
+		trashService := services.NewTrashService(objectRepo, objectTypeRepo, relationshipRepo, relationshipTypeRepo, txDB)
+		objectHandler.SetTrashService(trashService)
 		relationshipTypeHandler = handlers.NewRelationshipTypeHandler(relationshipTypeService, logger.Logger)
 		relationshipHandler = handlers.NewRelationshipHandler(relationshipService, logger.Logger)
 		healthHandler = handlers.NewHealthHandler(db.GetPool(), logger.Logger, cfg)
+		eventHandler = handlers.NewEventHandler(services.NewEventService(outboxRepo), logger.Logger)
+		webhookHandler = handlers.NewWebhookHandler(services.NewWebhookService(webhookRepo), logger.Logger)
+		taxonomyHandler = handlers.NewTaxonomyHandler(services.NewTaxonomyService(objectTypeRepo, relationshipTypeRepo, txDB), logger.Logger)
+		transferHandler = handlers.NewTransferHandler(services.NewTransferService(objectRepo, objectTypeRepo, relationshipRepo, relationshipTypeRepo, txDB), logger.Logger)
+		concreteTableHandler = handlers.NewConcreteTableHandler(services.NewConcreteTableService(objectTypeRepo, concreteTableRepo, txDB), logger.Logger)
+
+		// Deliver committed domain events in the background
+		if cfg.Outbox.DispatchEnabled {
+			dispatcher := services.NewOutboxDispatcher(txDB, services.OutboxDispatcherConfig{
+				BatchSize:    cfg.Outbox.BatchSize,
+				PollInterval: time.Duration(cfg.Outbox.PollIntervalMs) * time.Millisecond,
+				MaxBackoff:   time.Duration(cfg.Outbox.MaxBackoffMs) * time.Millisecond,
+			}, logger.Logger, services.NewLogSink(logger.Logger), services.NewWebhookSink(webhookRepo))
+
+			var dispatchCtx context.Context
+			dispatchCtx, stopDispatcher = context.WithCancel(context.Background())
+			go dispatcher.Run(dispatchCtx)
+			logger.Info("Outbox event dispatch enabled")
+		}
+
+		// Send queued webhook deliveries in the background
+		if cfg.Webhooks.DeliveryEnabled {
+			deliverer := services.NewWebhookDeliverer(webhookRepo, services.WebhookDelivererConfig{
+				BatchSize:      cfg.Webhooks.BatchSize,
+				PollInterval:   time.Duration(cfg.Webhooks.PollIntervalMs) * time.Millisecond,
+				Timeout:        time.Duration(cfg.Webhooks.TimeoutMs) * time.Millisecond,
+				MaxAttempts:    cfg.Webhooks.MaxAttempts,
+				InitialBackoff: time.Duration(cfg.Webhooks.InitialBackoffMs) * time.Millisecond,
+				MaxBackoff:     time.Duration(cfg.Webhooks.MaxBackoffMs) * time.Millisecond,
+			}, logger.Logger)
+
+			var webhooksCtx context.Context
+			webhooksCtx, stopWebhooks = context.WithCancel(context.Background())
+			go deliverer.Run(webhooksCtx)
+			logger.Info("Webhook delivery enabled")
+		}
+
+		// Purge objects that stayed in the trash past the retention period
+		if cfg.Trash.RetentionEnabled {
+			purger := services.NewTrashPurger(trashService, services.TrashPurgerConfig{
+				RetentionPeriod: time.Duration(cfg.Trash.RetentionDays) * 24 * time.Hour,
+				Interval:        time.Duration(cfg.Trash.PurgeIntervalMinutes) * time.Minute,
+				BatchSize:       cfg.Trash.BatchSize,
+			}, logger.Logger)
+
+			var purgeCtx context.Context
+			purgeCtx, stopTrashPurger = context.WithCancel(context.Background())
+			go purger.Run(purgeCtx)
+			logger.Info("Trash retention enabled")
+		}
+
+		// Persist audit events to the append-only audit trail
+		if cfg.Audit.Enabled {
+			auditStore, err := logging.NewPostgresAuditStore(db.GetPool(), "objects_service.audit_events")
+			if err != nil {
+				logger.Fatal("Failed to initialize audit store", err)
+			}
+			auditSink = logging.NewBufferedAuditSink(auditStore, logging.BufferedAuditSinkConfig{
+				BufferSize:    cfg.Audit.BufferSize,
+				BatchSize:     cfg.Audit.BatchSize,
+				FlushInterval: time.Duration(cfg.Audit.FlushIntervalMs) * time.Millisecond,
+			}, logger.Logger)
+			objectTypeHandler.SetAuditSink(auditSink)
+			objectHandler.SetAuditSink(auditSink)
+			auditQueryHandler = logging.NewAuditQueryHandler(auditStore, logger.Logger)
+			logger.Info("Persistent audit trail enabled")
+		}
 	} else {
 		// Initialize handlers without database
 		healthHandler = handlers.NewHealthHandler(nil, logger.Logger, cfg)
@@ -163,6 +248,7 @@ func main() {
 	// JWT middleware for authentication (configure jwtSecret for token validation)
 	// For development, you may need to share JWT public key with auth-service
 	router.Use(middleware.JWTMiddleware(nil, logger.Logger, nil)) // nil disables JWT validation
+	router.Use(changeContextMiddleware())                         // Attribute recorded object history to the caller and request
 	router.Use(serviceLogger.RequestResponseLogger())
 
 	// Health check endpoints (public, no auth required)
@@ -201,6 +287,10 @@ func main() {
 				objectTypesAdmin.POST("", objectTypeHandler.Create)
 				objectTypesAdmin.PUT("/:id", objectTypeHandler.Update)
 				objectTypesAdmin.DELETE("/:id", objectTypeHandler.Delete)
+				objectTypesAdmin.POST("/:id/move", objectTypeHandler.Move)
+				objectTypesAdmin.POST("/:id/metadata-schema/validate", objectHandler.ValidateMetadataSchema)
+				// Adding attributes changes the database schema, so it is reserved to admins
+				objectTypesAdmin.POST("/:id/attributes", middleware.RequireRole("admin"), concreteTableHandler.AddAttributes)
 			}
 
 			// Object Types - Read (authenticated users)
@@ -217,8 +307,11 @@ func main() {
 				objectTypesRead.GET("/:id/descendants", objectTypeHandler.GetDescendants)
 				objectTypesRead.GET("/:id/ancestors", objectTypeHandler.GetAncestors)
 				objectTypesRead.GET("/:id/path", objectTypeHandler.GetPath)
+				objectTypesRead.GET("/:id/metadata-schema", objectTypeHandler.GetEffectiveMetadataSchema)
 				objectTypesRead.GET("/:id/subtree-count", objectTypeHandler.GetSubtreeObjectCount)
 				objectTypesRead.POST("/:id/validate-move", objectTypeHandler.ValidateMove)
+				objectTypesRead.GET("/:id/attributes", concreteTableHandler.ListAttributes)
+				objectTypesRead.GET("/:id/attributes/ddl", concreteTableHandler.GetDDL)
 			}
 		}
 
@@ -232,6 +325,13 @@ func main() {
 				objectsCreate.POST("", objectHandler.Create)
 			}
 
+			// Objects - Clone (creates objects from one the user can read)
+			objectsClone := v1.Group("/objects")
+			objectsClone.Use(permissionMiddleware("objects:create"), permissionMiddleware("objects:read:all", "objects:read:own"))
+			{
+				objectsClone.POST("/:id/clone", objectHandler.Clone)
+			}
+
 			// Objects - Read
 			// Note: RequireAuth removed - gateway forwards user info via X-User-* headers
 			objectsRead := v1.Group("/objects")
@@ -246,10 +346,18 @@ func main() {
 				objectsRead.GET("/:id/descendants", objectHandler.GetDescendants)
 				objectsRead.GET("/:id/ancestors", objectHandler.GetAncestors)
 				objectsRead.GET("/:id/path", objectHandler.GetPath)
+				objectsRead.GET("/:id/versions", objectHandler.ListVersions)
+				objectsRead.GET("/:id/versions/diff", objectHandler.DiffVersions)
+				objectsRead.GET("/:id/versions/:revision", objectHandler.GetVersion)
 				objectsRead.GET("/stats", objectHandler.GetStats)
+				objectsRead.POST("/aggregate", objectHandler.Aggregate)
 				// Relationships for object (using public-id for UUID-based lookup)
 				objectsRead.GET("/public-id/:public_id/relationships", relationshipHandler.GetForObject)
 				objectsRead.GET("/public-id/:public_id/relationships/:type_key", relationshipHandler.GetForObjectByType)
+				// Graph traversal over relationships
+				objectsRead.GET("/public-id/:public_id/graph", relationshipHandler.Traverse)
+				objectsRead.GET("/public-id/:public_id/graph/path/:target_public_id", relationshipHandler.ShortestPath)
+				objectsRead.GET("/public-id/:public_id/reachable/:type_key", relationshipHandler.Reachable)
 			}
 
 			// Objects - Update
@@ -261,6 +369,8 @@ func main() {
 				objectsUpdate.PUT("/:id/metadata", objectHandler.UpdateMetadata)
 				objectsUpdate.POST("/:id/tags", objectHandler.AddTags)
 				objectsUpdate.DELETE("/:id/tags", objectHandler.RemoveTags)
+				objectsUpdate.POST("/:id/versions/:revision/restore", objectHandler.RestoreVersion)
+				objectsUpdate.POST("/:id/move", objectHandler.Move)
 			}
 
 			// Objects - Delete
@@ -271,6 +381,16 @@ func main() {
 				objectsDelete.DELETE("/:id", objectHandler.Delete)
 			}
 
+			// Objects - Trash bin (deleted objects can be listed, restored and purged by whoever
+			// may delete them)
+			objectsTrash := v1.Group("/objects")
+			objectsTrash.Use(permissionMiddleware("objects:delete:all", "objects:delete:own"))
+			{
+				objectsTrash.GET("/trash", objectHandler.ListTrash)
+				objectsTrash.POST("/:id/restore", objectHandler.RestoreFromTrash)
+				objectsTrash.DELETE("/:id/purge", objectHandler.Purge)
+			}
+
 			// Objects - Bulk operations
 			objectsBulk := v1.Group("/objects")
 			objectsBulk.Use(middleware.RequireAuth())
@@ -281,6 +401,73 @@ func main() {
 				objectsBulk.DELETE("/bulk", objectHandler.BulkDelete)
 			}
 
+			// Objects - Audit trail (admin only)
+			if auditQueryHandler != nil {
+				objectsAudit := v1.Group("/objects")
+				objectsAudit.Use(middleware.RequireAuth())
+				objectsAudit.Use(middleware.RequireRole("admin"))
+				{
+					objectsAudit.GET("/audit-events", auditQueryHandler.ListEvents)
+					objectsAudit.GET("/audit-events/verify", auditQueryHandler.VerifyChain)
+				}
+			}
+
+			// Domain event log (admin only)
+			if eventHandler != nil {
+				events := v1.Group("/events")
+				events.Use(middleware.RequireAuth())
+				events.Use(middleware.RequireRole("admin"))
+				{
+					events.GET("", eventHandler.List)
+				}
+			}
+
+			// Webhook subscriptions and delivery logs (admin only)
+			if webhookHandler != nil {
+				webhooks := v1.Group("/webhooks")
+				webhooks.Use(middleware.RequireAuth())
+				webhooks.Use(middleware.RequireRole("admin"))
+				{
+					webhooks.POST("", webhookHandler.Create)
+					webhooks.GET("", webhookHandler.List)
+					webhooks.GET("/:id", webhookHandler.Get)
+					webhooks.PUT("/:id", webhookHandler.Update)
+					webhooks.DELETE("/:id", webhookHandler.Delete)
+					webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+					webhooks.POST("/:id/deliveries/:delivery_id/replay", webhookHandler.ReplayDelivery)
+				}
+			}
+
+			// Taxonomy bundle export and import (admin only)
+			if taxonomyHandler != nil {
+				taxonomy := v1.Group("/taxonomy")
+				taxonomy.Use(middleware.RequireAuth())
+				taxonomy.Use(middleware.RequireRole("admin"))
+				{
+					taxonomy.GET("/export", taxonomyHandler.Export)
+					taxonomy.POST("/import", taxonomyHandler.Import)
+				}
+			}
+
+			// Streaming export and import of objects and relationships (admin only)
+			if transferHandler != nil {
+				objectsTransfer := v1.Group("/objects")
+				objectsTransfer.Use(middleware.RequireAuth())
+				objectsTransfer.Use(middleware.RequireRole("admin"))
+				{
+					objectsTransfer.GET("/export", transferHandler.ExportObjects)
+					objectsTransfer.POST("/import", transferHandler.ImportObjects)
+				}
+
+				relationshipsTransfer := v1.Group("/relationships")
+				relationshipsTransfer.Use(middleware.RequireAuth())
+				relationshipsTransfer.Use(middleware.RequireRole("admin"))
+				{
+					relationshipsTransfer.GET("/export", transferHandler.ExportRelationships)
+					relationshipsTransfer.POST("/import", transferHandler.ImportRelationships)
+				}
+			}
+
 			// Relationship Types endpoints
 			if relationshipTypeHandler != nil {
 				// Relationship Types - Admin only (create, update, delete)
@@ -290,6 +477,7 @@ func main() {
 					relationshipTypesAdmin.POST("", relationshipTypeHandler.Create)
 					relationshipTypesAdmin.PUT("/:type_key", relationshipTypeHandler.Update)
 					relationshipTypesAdmin.DELETE("/:type_key", relationshipTypeHandler.Delete)
+					relationshipTypesAdmin.POST("/:type_key/validation-rules/validate", relationshipHandler.ValidateRules)
 				}
 
 				// Relationship Types - Read (authenticated users)
@@ -364,6 +552,24 @@ func main() {
 		logger.Error("objects-service service forced to shutdown", err)
 	}
 
+	// Stop delivering events before the database connection closes
+	if stopDispatcher != nil {
+		stopDispatcher()
+	}
+	if stopWebhooks != nil {
+		stopWebhooks()
+	}
+	if stopTrashPurger != nil {
+		stopTrashPurger()
+	}
+
+	// Flush queued audit events before the database connection closes
+	if auditSink != nil {
+		if err := auditSink.Close(ctx); err != nil {
+			logger.Error("Failed to flush audit events", err)
+		}
+	}
+
 	logger.Info("objects-service service exited")
 }
 
@@ -379,11 +585,25 @@ func requestIDMiddleware() gin.HandlerFunc {
 	}
 }
 
+// changeContextMiddleware attaches the request ID and authenticated user to the request context,
+// where the repositories pick them up when recording object history
+func changeContextMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := repository.WithChangeContext(c.Request.Context(), repository.ChangeContext{
+			RequestID: c.GetHeader("X-Request-ID"),
+			Actor:     middleware.GetAuthenticatedUserID(c),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
 func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match")
+		c.Header("Access-Control-Expose-Headers", "ETag")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -0,0 +1,262 @@
+package filterexpr
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type operator string
+
+const (
+	opEq       operator = "eq"
+	opNe       operator = "ne"
+	opGt       operator = "gt"
+	opGe       operator = "ge"
+	opLt       operator = "lt"
+	opLe       operator = "le"
+	opIn       operator = "in"
+	opContains operator = "contains"
+	opHas      operator = "has"
+	opExists   operator = "exists"
+)
+
+func (op operator) valid() bool {
+	switch op {
+	case opEq, opNe, opGt, opGe, opLt, opLe, opIn, opContains, opHas, opExists:
+		return true
+	}
+	return false
+}
+
+func (op operator) ordering() bool {
+	return op == opGt || op == opGe || op == opLt || op == opLe
+}
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindNumber
+	kindBool
+	kindNull
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindString:
+		return "string"
+	case kindNumber:
+		return "number"
+	case kindBool:
+		return "boolean"
+	default:
+		return "null"
+	}
+}
+
+type value struct {
+	kind valueKind
+	text string // unquoted string, or the literal of a number, boolean or null
+	pos  int
+
+	arg interface{} // the query argument, set once the value is checked against its field
+}
+
+type fieldType int
+
+const (
+	fieldInt fieldType = iota
+	fieldText
+	fieldTime
+	fieldUUID
+	fieldTags
+	fieldJSON
+)
+
+type column struct {
+	name     string
+	typ      fieldType
+	nullable bool
+}
+
+// columns lists the object fields that can be filtered on; metadata.<path> addresses JSON values
+var columns = map[string]column{
+	"id":               {name: "id", typ: fieldInt},
+	"public_id":        {name: "public_id", typ: fieldUUID},
+	"object_type_id":   {name: "object_type_id", typ: fieldInt},
+	"parent_object_id": {name: "parent_object_id", typ: fieldInt, nullable: true},
+	"name":             {name: "name", typ: fieldText},
+	"description":      {name: "description", typ: fieldText, nullable: true},
+	"status":           {name: "status", typ: fieldText},
+	"version":          {name: "version", typ: fieldInt},
+	"created_by":       {name: "created_by", typ: fieldText},
+	"updated_by":       {name: "updated_by", typ: fieldText},
+	"created_at":       {name: "created_at", typ: fieldTime},
+	"updated_at":       {name: "updated_at", typ: fieldTime},
+	"tags":             {name: "tags", typ: fieldTags},
+}
+
+// target is a resolved field: a column, or a path into the metadata document
+type target struct {
+	column column
+	path   []string
+}
+
+func resolveField(t token) (target, error) {
+	if col, ok := columns[t.text]; ok {
+		return target{column: col}, nil
+	}
+
+	if rest, ok := strings.CutPrefix(t.text, "metadata."); ok {
+		path := strings.Split(rest, ".")
+		if len(path) > MaxPathDepth {
+			return target{}, errorAt(t.pos, "metadata path is more than %d keys deep", MaxPathDepth)
+		}
+		for _, key := range path {
+			if key == "" {
+				return target{}, errorAt(t.pos, "metadata path %q has an empty key", t.text)
+			}
+		}
+		return target{column: column{name: "metadata", typ: fieldJSON}, path: path}, nil
+	}
+
+	if t.text == "metadata" {
+		return target{}, errorAt(t.pos, "metadata needs a key, as in metadata.amount")
+	}
+	return target{}, errorAt(t.pos, "unknown field %q", t.text)
+}
+
+// check verifies that the operator applies to the field and converts the values to query
+// arguments of the field's type
+func (c *comparison) check(field, op token) error {
+	typ := c.target.column.typ
+
+	switch c.op {
+	case opExists:
+		if typ != fieldJSON {
+			return errorAt(op.pos, "%q only applies to metadata paths", c.op)
+		}
+		return nil
+	case opHas:
+		if typ != fieldTags && typ != fieldJSON {
+			return errorAt(op.pos, "%q only applies to tags and metadata paths", c.op)
+		}
+	case opContains:
+		if typ != fieldText && typ != fieldJSON {
+			return errorAt(op.pos, "%q only applies to text fields and metadata paths", c.op)
+		}
+	case opEq, opNe, opIn:
+		if typ == fieldTags {
+			return errorAt(op.pos, "tags only support \"has\"")
+		}
+	default:
+		if typ == fieldTags || typ == fieldUUID {
+			return errorAt(op.pos, "%q does not apply to %s", c.op, field.text)
+		}
+	}
+
+	for i := range c.values {
+		v := &c.values[i]
+		if v.kind == kindNull {
+			if c.op != opEq && c.op != opNe {
+				return errorAt(v.pos, "null can only be compared with \"eq\" or \"ne\"")
+			}
+			if typ != fieldJSON && !c.target.column.nullable {
+				return errorAt(v.pos, "%s is never null", field.text)
+			}
+			continue
+		}
+
+		arg, err := c.convert(field, v)
+		if err != nil {
+			return err
+		}
+		v.arg = arg
+	}
+	return nil
+}
+
+func (c *comparison) convert(field token, v *value) (interface{}, error) {
+	switch c.target.column.typ {
+	case fieldInt:
+		n, err := strconv.ParseInt(v.text, 10, 64)
+		if v.kind != kindNumber || err != nil {
+			return nil, errorAt(v.pos, "%s expects an integer, got %s", field.text, v.describe())
+		}
+		return n, nil
+	case fieldText, fieldTags:
+		if v.kind != kindString {
+			return nil, errorAt(v.pos, "%s expects a string, got %s", field.text, v.describe())
+		}
+		if c.op == opContains {
+			return "%" + escapeLike(v.text) + "%", nil
+		}
+		return v.text, nil
+	case fieldTime:
+		if v.kind == kindString {
+			for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+				if t, err := time.Parse(layout, v.text); err == nil {
+					return t, nil
+				}
+			}
+		}
+		return nil, errorAt(v.pos, "%s expects an RFC 3339 timestamp or a date, got %s", field.text, v.describe())
+	case fieldUUID:
+		if v.kind == kindString {
+			if id, err := uuid.Parse(v.text); err == nil {
+				return id.String(), nil
+			}
+		}
+		return nil, errorAt(v.pos, "%s expects a UUID, got %s", field.text, v.describe())
+	default:
+		return c.convertJSON(field, v)
+	}
+}
+
+// convertJSON checks a value compared with a metadata path. Equality and membership compare
+// JSON documents, so 1000 matches 1000.0 but not "1000"; ordering compares numbers numerically
+// and strings lexically.
+func (c *comparison) convertJSON(field token, v *value) (interface{}, error) {
+	if c.op.ordering() {
+		if v.kind != kindNumber && v.kind != kindString {
+			return nil, errorAt(v.pos, "%q expects a number or a string, got %s", c.op, v.describe())
+		}
+		return v.text, nil
+	}
+
+	switch c.op {
+	case opContains:
+		if v.kind != kindString {
+			return nil, errorAt(v.pos, "\"contains\" expects a string, got %s", v.describe())
+		}
+		return "%" + escapeLike(v.text) + "%", nil
+	case opHas:
+		return "[" + v.json() + "]", nil
+	default:
+		return v.json(), nil
+	}
+}
+
+func (v value) json() string {
+	if v.kind == kindString {
+		encoded, _ := json.Marshal(v.text)
+		return string(encoded)
+	}
+	return v.text
+}
+
+func (v value) describe() string {
+	if v.kind == kindString {
+		return "string " + strconv.Quote(v.text)
+	}
+	return v.kind.String() + " " + v.text
+}
+
+// escapeLike escapes the ILIKE wildcards so that "contains" matches the text literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
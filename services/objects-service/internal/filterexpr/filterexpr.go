@@ -0,0 +1,280 @@
+// Package filterexpr parses filter expressions over object fields, such as
+//
+//	metadata.amount gt 1000 and (tags has "urgent" or status in ("pending", "active"))
+//
+// into a type-checked AST and compiles them to parameterized SQL conditions. Field names and
+// operators come from fixed tables and every value is passed as a query argument, so no part
+// of the input is ever spliced into SQL.
+package filterexpr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits that keep a single expression from producing an unreasonably expensive query
+const (
+	MaxLength      = 4096
+	MaxDepth       = 16
+	MaxComparisons = 50
+	MaxListValues  = 100
+	MaxPathDepth   = 10
+)
+
+// Error reports an invalid expression and the 1-based character position it was found at
+type Error struct {
+	Position int
+	Message  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
+
+func errorAt(pos int, format string, args ...interface{}) *Error {
+	return &Error{Position: pos + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+// Expr is a parsed and type-checked filter expression
+type Expr interface {
+	writeSQL(w *sqlWriter)
+}
+
+type logicalExpr struct {
+	op          string // AND or OR
+	left, right Expr
+}
+
+type notExpr struct {
+	expr Expr
+}
+
+// Parse parses and type-checks a filter expression
+func Parse(input string) (Expr, error) {
+	if len(input) > MaxLength {
+		return nil, &Error{Position: MaxLength + 1, Message: fmt.Sprintf("expression is longer than %d characters", MaxLength)}
+	}
+	if strings.TrimSpace(input) == "" {
+		return nil, &Error{Position: 1, Message: "expression is empty"}
+	}
+
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, errorAt(t.pos, "unexpected %s, expected \"and\", \"or\" or end of expression", t.describe())
+	}
+	return expr, nil
+}
+
+// SQL compiles expr into a condition. Its placeholders continue after args, which it returns
+// extended with the expression's values.
+func SQL(expr Expr, args []interface{}) (string, []interface{}) {
+	w := &sqlWriter{args: args}
+	expr.writeSQL(w)
+	return w.b.String(), w.args
+}
+
+// Compile parses input and compiles it with SQL
+func Compile(input string, args []interface{}) (string, []interface{}, error) {
+	expr, err := Parse(input)
+	if err != nil {
+		return "", nil, err
+	}
+	condition, args := SQL(expr, args)
+	return condition, args, nil
+}
+
+type parser struct {
+	tokens      []token
+	pos         int
+	depth       int
+	comparisons int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword reports whether the next token is the given keyword, matched case-insensitively
+func (p *parser) keyword(word string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && strings.EqualFold(t.text, word)
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "OR", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalExpr{op: "AND", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > MaxDepth {
+		return nil, errorAt(p.peek().pos, "expression is nested more than %d levels deep", MaxDepth)
+	}
+
+	if p.keyword("not") {
+		p.next()
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{expr: expr}, nil
+	}
+
+	if p.peek().kind == tokenLParen {
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.next(); t.kind != tokenRParen {
+			return nil, errorAt(t.pos, "unexpected %s, expected \")\"", t.describe())
+		}
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	fieldToken := p.next()
+	if fieldToken.kind != tokenWord || isKeyword(fieldToken.text) {
+		return nil, errorAt(fieldToken.pos, "unexpected %s, expected a field name", fieldToken.describe())
+	}
+	target, err := resolveField(fieldToken)
+	if err != nil {
+		return nil, err
+	}
+
+	p.comparisons++
+	if p.comparisons > MaxComparisons {
+		return nil, errorAt(fieldToken.pos, "expression has more than %d comparisons", MaxComparisons)
+	}
+
+	opToken := p.next()
+	if opToken.kind != tokenWord && opToken.kind != tokenOperator {
+		return nil, errorAt(opToken.pos, "unexpected %s, expected an operator after %q", opToken.describe(), fieldToken.text)
+	}
+	op := operator(strings.ToLower(opToken.text))
+	if !op.valid() {
+		return nil, errorAt(opToken.pos, "unknown operator %q", opToken.text)
+	}
+
+	c := &comparison{target: target, op: op}
+	switch op {
+	case opExists:
+	case opIn:
+		if c.values, err = p.parseList(); err != nil {
+			return nil, err
+		}
+	default:
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		c.values = []value{v}
+	}
+
+	if err := c.check(fieldToken, opToken); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p *parser) parseList() ([]value, error) {
+	if t := p.next(); t.kind != tokenLParen {
+		return nil, errorAt(t.pos, "unexpected %s, expected \"(\" to start the list", t.describe())
+	}
+
+	var values []value
+	for {
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		if len(values) > MaxListValues {
+			return nil, errorAt(v.pos, "list has more than %d values", MaxListValues)
+		}
+
+		t := p.next()
+		if t.kind == tokenRParen {
+			return values, nil
+		}
+		if t.kind != tokenComma {
+			return nil, errorAt(t.pos, "unexpected %s, expected \",\" or \")\"", t.describe())
+		}
+	}
+}
+
+func (p *parser) parseValue() (value, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return value{kind: kindString, text: t.text, pos: t.pos}, nil
+	case tokenNumber:
+		return value{kind: kindNumber, text: t.text, pos: t.pos}, nil
+	case tokenWord:
+		switch strings.ToLower(t.text) {
+		case "true", "false":
+			return value{kind: kindBool, text: strings.ToLower(t.text), pos: t.pos}, nil
+		case "null":
+			return value{kind: kindNull, text: "null", pos: t.pos}, nil
+		}
+		return value{}, errorAt(t.pos, "unexpected %s, expected a value (quote strings)", t.describe())
+	default:
+		return value{}, errorAt(t.pos, "unexpected %s, expected a value", t.describe())
+	}
+}
+
+func isKeyword(word string) bool {
+	switch strings.ToLower(word) {
+	case "and", "or", "not", "true", "false", "null":
+		return true
+	}
+	return operator(strings.ToLower(word)).valid()
+}
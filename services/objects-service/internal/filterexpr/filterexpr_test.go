@@ -0,0 +1,127 @@
+package filterexpr
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		condition string
+		args      []interface{}
+	}{
+		{
+			name:      "metadata number and tag",
+			input:     `metadata.amount gt 1000 and tags has "urgent"`,
+			condition: "((CASE WHEN jsonb_typeof(metadata #> $1::text[]) = 'number' THEN (metadata #>> $1::text[])::numeric END) > $2::numeric AND $3 = ANY(tags))",
+			args:      []interface{}{[]string{"amount"}, "1000", "urgent"},
+		},
+		{
+			name:      "and binds tighter than or",
+			input:     `status = "active" or status = "pending" and version >= 2`,
+			condition: "(status = $1 OR (status = $2 AND version >= $3))",
+			args:      []interface{}{"active", "pending", int64(2)},
+		},
+		{
+			name:      "parentheses and not",
+			input:     `NOT (name contains "50%_off" or description eq null)`,
+			condition: "NOT COALESCE((name ILIKE $1 OR description IS NULL), false)",
+			args:      []interface{}{`%50\%\_off%`},
+		},
+		{
+			name:      "metadata equality compares JSON",
+			input:     `metadata.customer.tier in ("gold", 'platinum') and metadata.active = true`,
+			condition: "((metadata #> $1::text[]) IN ($2::jsonb, $3::jsonb) AND (metadata #> $4::text[]) = $5::jsonb)",
+			args:      []interface{}{[]string{"customer", "tier"}, `"gold"`, `"platinum"`, []string{"active"}, "true"},
+		},
+		{
+			name:      "metadata exists and has",
+			input:     `metadata.labels exists and metadata.labels has "red"`,
+			condition: "((metadata #> $1::text[]) IS NOT NULL AND (metadata #> $2::text[]) @> $3::jsonb)",
+			args:      []interface{}{[]string{"labels"}, []string{"labels"}, `["red"]`},
+		},
+		{
+			name:      "metadata null",
+			input:     `metadata.closed_at != null`,
+			condition: "COALESCE(jsonb_typeof(metadata #> $1::text[]), 'null') <> 'null'",
+			args:      []interface{}{[]string{"closed_at"}},
+		},
+		{
+			name:      "uuid and time",
+			input:     `public_id ne "3f0d5e1c-6b2a-4b5e-9d1c-2a3b4c5d6e7f" and created_at < "2024-01-02"`,
+			condition: "(public_id IS DISTINCT FROM $1::uuid AND created_at < $2)",
+			args:      []interface{}{"3f0d5e1c-6b2a-4b5e-9d1c-2a3b4c5d6e7f", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition, args, err := Compile(tt.input, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.condition, condition)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+}
+
+func TestCompile_ContinuesPlaceholders(t *testing.T) {
+	condition, args, err := Compile(`version gt 3`, []interface{}{"existing"})
+	require.NoError(t, err)
+	assert.Equal(t, "version > $2", condition)
+	assert.Equal(t, []interface{}{"existing", int64(3)}, args)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		input    string
+		position int
+		message  string
+	}{
+		{``, 1, "expression is empty"},
+		{`colour = "red"`, 1, `unknown field "colour"`},
+		{`status ~ "x"`, 8, `unexpected character '~'`},
+		{`status = active`, 10, "quote strings"},
+		{`status = "active`, 10, "unterminated string"},
+		{`version = "2"`, 11, "version expects an integer"},
+		{`tags = "x"`, 6, `tags only support "has"`},
+		{`name exists`, 6, "only applies to metadata paths"},
+		{`created_at gt "yesterday"`, 15, "expects an RFC 3339 timestamp"},
+		{`status eq null`, 11, "status is never null"},
+		{`metadata.amount gt true`, 20, `"gt" expects a number or a string`},
+		{`(status = "a"`, 14, `expected ")"`},
+		{`status = "a" status = "b"`, 14, `expected "and", "or" or end of expression`},
+		{`status in "a"`, 11, `expected "(" to start the list`},
+		{`metadata..amount exists`, 1, "has an empty key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var filterErr *Error
+			require.True(t, errors.As(err, &filterErr), "expected *Error, got %v", err)
+			assert.Equal(t, tt.position, filterErr.Position)
+			assert.Contains(t, filterErr.Message, tt.message)
+		})
+	}
+}
+
+func TestParse_Limits(t *testing.T) {
+	_, err := Parse(strings.Repeat("(", MaxDepth+1) + `status = "a"` + strings.Repeat(")", MaxDepth+1))
+	assert.ErrorContains(t, err, "nested more than")
+
+	_, err = Parse(strings.Repeat(`version = 1 or `, MaxComparisons) + `version = 1`)
+	assert.ErrorContains(t, err, "more than 50 comparisons")
+
+	_, err = Parse(`metadata.` + strings.Repeat("a.", MaxPathDepth) + `b exists`)
+	assert.ErrorContains(t, err, "keys deep")
+
+	_, err = Parse(strings.Repeat(" ", MaxLength+1))
+	assert.ErrorContains(t, err, "longer than")
+}
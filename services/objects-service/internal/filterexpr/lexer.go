@@ -0,0 +1,189 @@
+package filterexpr
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // 0-based byte offset into the input
+}
+
+// symbolOperators maps the symbolic comparison operators to their word forms
+var symbolOperators = map[string]string{
+	"=":  "eq",
+	"!=": "ne",
+	">":  "gt",
+	">=": "ge",
+	"<":  "lt",
+	"<=": "le",
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(input) {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case c == '"' || c == '\'':
+			text, end, err := scanString(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: i})
+			i = end
+		case c == '-' || isDigit(c):
+			end, err := scanNumber(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: input[i:end], pos: i})
+			i = end
+		case c == '=' || c == '!' || c == '<' || c == '>':
+			end := i + 1
+			if end < len(input) && input[end] == '=' {
+				end++
+			}
+			op, ok := symbolOperators[input[i:end]]
+			if !ok {
+				return nil, errorAt(i, "unknown operator %q", input[i:end])
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i = end
+		case isWordStart(c):
+			end := i + 1
+			for end < len(input) && isWordPart(input[end]) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: input[i:end], pos: i})
+			i = end
+		default:
+			return nil, errorAt(i, "unexpected character %q", c)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(input)}), nil
+}
+
+// scanString reads a single- or double-quoted string with backslash escapes
+func scanString(input string, start int) (string, int, error) {
+	quote := input[start]
+	var b strings.Builder
+	for i := start + 1; i < len(input); i++ {
+		c := input[i]
+		switch c {
+		case quote:
+			return b.String(), i + 1, nil
+		case '\\':
+			if i+1 >= len(input) {
+				return "", 0, errorAt(i, "unterminated escape sequence")
+			}
+			i++
+			switch input[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '\\', '"', '\'':
+				b.WriteByte(input[i])
+			default:
+				return "", 0, errorAt(i-1, "unknown escape sequence \\%c", input[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, errorAt(start, "unterminated string")
+}
+
+// scanNumber reads a number in JSON syntax, so that it can be passed on as a JSON value as is
+func scanNumber(input string, start int) (int, error) {
+	i := start
+	if input[i] == '-' {
+		i++
+	}
+	if i >= len(input) || !isDigit(input[i]) {
+		return 0, errorAt(start, "invalid number")
+	}
+	if input[i] == '0' {
+		i++
+	} else {
+		for i < len(input) && isDigit(input[i]) {
+			i++
+		}
+	}
+	if i < len(input) && input[i] == '.' {
+		i++
+		if i >= len(input) || !isDigit(input[i]) {
+			return 0, errorAt(start, "invalid number")
+		}
+		for i < len(input) && isDigit(input[i]) {
+			i++
+		}
+	}
+	if i < len(input) && (input[i] == 'e' || input[i] == 'E') {
+		i++
+		if i < len(input) && (input[i] == '+' || input[i] == '-') {
+			i++
+		}
+		if i >= len(input) || !isDigit(input[i]) {
+			return 0, errorAt(start, "invalid number")
+		}
+		for i < len(input) && isDigit(input[i]) {
+			i++
+		}
+	}
+	if i < len(input) && isWordPart(input[i]) {
+		return 0, errorAt(start, "invalid number")
+	}
+	return i, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isWordStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isWordPart also accepts dots and dashes so that metadata paths such as metadata.order-no.0 are one word
+func isWordPart(c byte) bool {
+	return isWordStart(c) || isDigit(c) || c == '.' || c == '-'
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return fmt.Sprintf("string %q", t.text)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
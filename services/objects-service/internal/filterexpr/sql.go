@@ -0,0 +1,129 @@
+package filterexpr
+
+import (
+	"strconv"
+	"strings"
+)
+
+type comparison struct {
+	target target
+	op     operator
+	values []value
+}
+
+type sqlWriter struct {
+	b    strings.Builder
+	args []interface{}
+}
+
+// arg adds a query argument and returns its placeholder
+func (w *sqlWriter) arg(v interface{}) string {
+	w.args = append(w.args, v)
+	return "$" + strconv.Itoa(len(w.args))
+}
+
+func (e *logicalExpr) writeSQL(w *sqlWriter) {
+	w.b.WriteString("(")
+	e.left.writeSQL(w)
+	w.b.WriteString(" " + e.op + " ")
+	e.right.writeSQL(w)
+	w.b.WriteString(")")
+}
+
+func (e *notExpr) writeSQL(w *sqlWriter) {
+	w.b.WriteString("NOT COALESCE(")
+	e.expr.writeSQL(w)
+	w.b.WriteString(", false)")
+}
+
+var sqlOperators = map[operator]string{
+	opGt: ">",
+	opGe: ">=",
+	opLt: "<",
+	opLe: "<=",
+}
+
+func (c *comparison) writeSQL(w *sqlWriter) {
+	if c.target.column.typ == fieldJSON {
+		c.writeJSON(w)
+		return
+	}
+
+	col := c.target.column.name
+	cast := ""
+	if c.target.column.typ == fieldUUID {
+		cast = "::uuid"
+	}
+
+	switch c.op {
+	case opEq:
+		if c.values[0].kind == kindNull {
+			w.b.WriteString(col + " IS NULL")
+			return
+		}
+		w.b.WriteString(col + " = " + w.arg(c.values[0].arg) + cast)
+	case opNe:
+		if c.values[0].kind == kindNull {
+			w.b.WriteString(col + " IS NOT NULL")
+			return
+		}
+		w.b.WriteString(col + " IS DISTINCT FROM " + w.arg(c.values[0].arg) + cast)
+	case opIn:
+		placeholders := make([]string, len(c.values))
+		for i, v := range c.values {
+			placeholders[i] = w.arg(v.arg) + cast
+		}
+		w.b.WriteString(col + " IN (" + strings.Join(placeholders, ", ") + ")")
+	case opContains:
+		w.b.WriteString(col + " ILIKE " + w.arg(c.values[0].arg))
+	case opHas:
+		w.b.WriteString(w.arg(c.values[0].arg) + " = ANY(" + col + ")")
+	default:
+		w.b.WriteString(col + " " + sqlOperators[c.op] + " " + w.arg(c.values[0].arg))
+	}
+}
+
+// writeJSON compiles a comparison on a metadata path. Values are only cast inside CASE
+// expressions guarded by jsonb_typeof, so objects whose value has another type do not match
+// rather than failing the query.
+func (c *comparison) writeJSON(w *sqlWriter) {
+	path := w.arg(c.target.path) + "::text[]"
+	doc := "(metadata #> " + path + ")"
+	text := "(metadata #>> " + path + ")"
+
+	switch c.op {
+	case opExists:
+		w.b.WriteString(doc + " IS NOT NULL")
+	case opEq:
+		if c.values[0].kind == kindNull {
+			w.b.WriteString("COALESCE(jsonb_typeof" + doc + ", 'null') = 'null'")
+			return
+		}
+		w.b.WriteString(doc + " = " + w.arg(c.values[0].arg) + "::jsonb")
+	case opNe:
+		if c.values[0].kind == kindNull {
+			w.b.WriteString("COALESCE(jsonb_typeof" + doc + ", 'null') <> 'null'")
+			return
+		}
+		w.b.WriteString(doc + " IS DISTINCT FROM " + w.arg(c.values[0].arg) + "::jsonb")
+	case opIn:
+		placeholders := make([]string, len(c.values))
+		for i, v := range c.values {
+			placeholders[i] = w.arg(v.arg) + "::jsonb"
+		}
+		w.b.WriteString(doc + " IN (" + strings.Join(placeholders, ", ") + ")")
+	case opContains:
+		w.b.WriteString("(CASE WHEN jsonb_typeof" + doc + " = 'string' THEN " + text + " END) ILIKE " + w.arg(c.values[0].arg))
+	case opHas:
+		w.b.WriteString(doc + " @> " + w.arg(c.values[0].arg) + "::jsonb")
+	default:
+		v := c.values[0]
+		if v.kind == kindNumber {
+			w.b.WriteString("(CASE WHEN jsonb_typeof" + doc + " = 'number' THEN " + text + "::numeric END) " +
+				sqlOperators[c.op] + " " + w.arg(v.arg) + "::numeric")
+			return
+		}
+		w.b.WriteString("(CASE WHEN jsonb_typeof" + doc + " = 'string' THEN " + text + " END) " +
+			sqlOperators[c.op] + " " + w.arg(v.arg))
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+)
+
+// Aggregate handles POST /api/v1/objects/aggregate. The objects selected by filter are counted
+// in buckets of group_by and date_histogram, with metrics computed per bucket.
+func (h *ObjectHandler) Aggregate(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	var req models.ObjectAggregationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format: failed to parse request body",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	result, err := h.service.Aggregate(c.Request.Context(), &req)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to aggregate objects", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+)
+
+// writeCloneError answers failed clones whose cause the generic error handlers do not map, and
+// reports whether it did. A copied relationship breaking the rules of its type fails the clone.
+func writeCloneError(c *gin.Context, err error, requestID string) bool {
+	if writeRelationshipRulesError(c, err, requestID) {
+		return true
+	}
+
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"type":  "not_found",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, services.ErrObjectDeleted):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Deleted objects cannot be cloned",
+			"type":  "conflict",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, services.ErrDuplicateRelationship):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"type":  "conflict",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, services.ErrCardinalityViolation), errors.Is(err, services.ErrCircularRelationship):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// Clone handles POST /api/v1/objects/:id/clone. The copy is placed under parent_object_id, or
+// next to the original; include_descendants copies the whole subtree.
+func (h *ObjectHandler) Clone(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	if h.cloneService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Cloning is not available",
+			"type":  "internal_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid id format: id must be a positive integer",
+			"type":  "validation_error",
+			"field": "id",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	object, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if !writeCloneError(c, err, requestID) {
+			h.handleServiceError(c, err, "Failed to get object", requestID)
+		}
+		return
+	}
+
+	if !h.checkOwnership(c, object, "objects:read:all") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You can only clone objects you can access",
+			"type":  "permission_denied",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	var req models.CloneObjectRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: failed to parse request body",
+				"type":  "validation_error",
+				"meta":  gin.H{"request_id": requestID},
+			})
+			return
+		}
+	}
+	req.CreatedBy = middleware.GetAuthenticatedUserID(c)
+
+	result, err := h.cloneService.Clone(c.Request.Context(), id, &req)
+	if err != nil {
+		if !writeMetadataSchemaError(c, err, requestID) && !writeCloneError(c, err, requestID) {
+			h.handleServiceError(c, err, "Failed to clone object", requestID)
+		}
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"object_id":     id,
+		"clone_id":      result.Object.ID,
+		"request_id":    requestID,
+		"objects":       len(result.Objects),
+		"relationships": len(result.Relationships),
+	}).Info("Object cloned successfully")
+
+	c.Header("ETag", result.Object.ETag())
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    result,
+		"message": "Object cloned successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+)
+
+// ConcreteTableHandler serves the typed attributes of object types and the DDL of their concrete
+// tables
+type ConcreteTableHandler struct {
+	service services.ConcreteTableService
+	logger  *logrus.Logger
+}
+
+func NewConcreteTableHandler(service services.ConcreteTableService, logger *logrus.Logger) *ConcreteTableHandler {
+	return &ConcreteTableHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ListAttributes handles GET /api/v1/object-types/:id/attributes. The response lists the concrete
+// tables of the type and its ancestors, root first, with the attributes stored in each.
+func (h *ConcreteTableHandler) ListAttributes(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, ok := h.parseID(c, requestID)
+	if !ok {
+		return
+	}
+
+	tables, err := h.service.GetTables(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, requestID, err, "list attributes")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{"object_type_id": id, "tables": tables},
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// AddAttributes handles POST /api/v1/object-types/:id/attributes?dry_run=. The response is the
+// plan of DDL statements, applied unless dry_run is set.
+func (h *ConcreteTableHandler) AddAttributes(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, ok := h.parseID(c, requestID)
+	if !ok {
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters: dry_run must be a boolean",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	var req models.AddObjectTypeAttributesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request body: " + err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+	req.CreatedBy = middleware.GetAuthenticatedUserID(c)
+
+	plan, err := h.service.AddAttributes(c.Request.Context(), id, &req, dryRun)
+	if err != nil {
+		h.handleError(c, requestID, err, "add attributes")
+		return
+	}
+
+	if plan.Applied {
+		h.logger.WithFields(logrus.Fields{
+			"request_id":     requestID,
+			"object_type_id": id,
+			"table":          plan.TableName,
+			"statements":     len(plan.Statements),
+		}).Info("Concrete table provisioned")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": plan,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// GetDDL handles GET /api/v1/object-types/:id/attributes/ddl, the statements creating the concrete
+// tables of the type and its ancestors as they are now
+func (h *ConcreteTableHandler) GetDDL(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, ok := h.parseID(c, requestID)
+	if !ok {
+		return
+	}
+
+	statements, err := h.service.GetDDL(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, requestID, err, "generate DDL")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{"object_type_id": id, "statements": statements},
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+func (h *ConcreteTableHandler) parseID(c *gin.Context, requestID string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid id format: id must be a positive integer",
+			"type":  "validation_error",
+			"field": "id",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *ConcreteTableHandler) handleError(c *gin.Context, requestID string, err error, operation string) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Object type not found",
+			"type":  "not_found",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, repository.ErrAlreadyExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"type":  "conflict",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	default:
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+		}).WithError(err).Error("Failed to " + operation)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to " + operation,
+			"type":  "internal_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+func versionedObject() *models.Object {
+	return &models.Object{
+		ID:           1,
+		ObjectTypeID: 1,
+		Name:         "ExistingObject",
+		Version:      3,
+		CreatedBy:    "user-123",
+		UpdatedAt:    time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestIfMatches(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"exact", `"v3-1"`, true},
+		{"wildcard", "*", true},
+		{"one of several", `"v2-1", "v3-1"`, true},
+		{"stale", `"v2-1"`, false},
+		{"weak tag", `W/"v3-1"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ifMatches(tt.header, `"v3-1"`))
+		})
+	}
+}
+
+func TestObjectHandler_GetByID_SetsETag(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	object := versionedObject()
+	mockService.On("GetByID", mock.Anything, int64(1)).Return(object, nil)
+
+	c, w := createTestGinContext("GET", "/api/v1/objects/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Set("user_id", "user-123")
+	handler.GetByID(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, object.ETag(), w.Header().Get("ETag"))
+}
+
+func TestObjectHandler_Update_IfMatch(t *testing.T) {
+	t.Run("stale tag is rejected", func(t *testing.T) {
+		mockService := &MockObjectService{}
+		handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+		object := versionedObject()
+		mockService.On("GetByID", mock.Anything, int64(1)).Return(object, nil)
+
+		c, w := createTestGinContext("PUT", "/api/v1/objects/1", models.UpdateObjectRequest{Name: stringPtr("Renamed")})
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		c.Request.Header.Set("If-Match", `"v2-0"`)
+		c.Set("user_id", "user-123")
+		handler.Update(c)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Equal(t, object.ETag(), w.Header().Get("ETag"))
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "precondition_failed", response["type"])
+		mockService.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("current tag pins the version", func(t *testing.T) {
+		mockService := &MockObjectService{}
+		handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+		object := versionedObject()
+		updated := versionedObject()
+		updated.Version = 4
+		mockService.On("GetByID", mock.Anything, int64(1)).Return(object, nil)
+		mockService.On("Update", mock.Anything, int64(1), mock.MatchedBy(func(req *models.UpdateObjectRequest) bool {
+			return req.Version != nil && *req.Version == 3
+		})).Return(updated, nil)
+
+		c, w := createTestGinContext("PUT", "/api/v1/objects/1", models.UpdateObjectRequest{Name: stringPtr("Renamed")})
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		c.Request.Header.Set("If-Match", object.ETag())
+		c.Set("user_id", "user-123")
+		handler.Update(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, updated.ETag(), w.Header().Get("ETag"))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("concurrent write after the check", func(t *testing.T) {
+		mockService := &MockObjectService{}
+		handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+		object := versionedObject()
+		mockService.On("GetByID", mock.Anything, int64(1)).Return(object, nil)
+		mockService.On("Update", mock.Anything, int64(1), mock.Anything).Return(nil, repository.ErrOptimisticLock)
+
+		c, w := createTestGinContext("PUT", "/api/v1/objects/1", models.UpdateObjectRequest{Name: stringPtr("Renamed")})
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		c.Request.Header.Set("If-Match", object.ETag())
+		c.Set("user_id", "user-123")
+		handler.Update(c)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+}
+
+func TestObjectHandler_Delete_IfMatchMismatch(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	mockService.On("GetByID", mock.Anything, int64(1)).Return(versionedObject(), nil)
+
+	c, w := createTestGinContext("DELETE", "/api/v1/objects/1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+	c.Request.Header.Set("If-Match", `"v1-0"`)
+	c.Set("user_id", "user-123")
+	handler.Delete(c)
+
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockService.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+// concurrentWriteDB is a database whose rows were changed by another writer after they were read,
+// so statements pinned to the state read match nothing
+type concurrentWriteDB struct {
+	pinned []string
+	args   []any
+	execs  int
+}
+
+func (db *concurrentWriteDB) Query(ctx context.Context, query string, args ...any) (repository.Rows, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (db *concurrentWriteDB) QueryRow(ctx context.Context, query string, args ...any) repository.Row {
+	db.pinned = append(db.pinned, query)
+	db.args = args
+	return noRow{}
+}
+
+func (db *concurrentWriteDB) Exec(ctx context.Context, query string, args ...any) (repository.CommandTag, error) {
+	db.execs++
+	return nil, nil
+}
+
+type noRow struct{}
+
+func (noRow) Scan(dest ...any) error {
+	return sql.ErrNoRows
+}
+
+// metadataWriter updates metadata through a real repository, so a test sees the write the
+// handler's context leads to
+type metadataWriter struct {
+	*MockObjectService
+	repo repository.ObjectRepository
+}
+
+func (s *metadataWriter) UpdateMetadata(ctx context.Context, id int64, metadata map[string]interface{}, updatedBy string) error {
+	return s.repo.UpdateMetadata(ctx, id, metadata, updatedBy)
+}
+
+func TestObjectHandler_UpdateMetadata_IfMatchRace(t *testing.T) {
+	object := versionedObject()
+
+	t.Run("write after the check loses to a concurrent writer", func(t *testing.T) {
+		db := &concurrentWriteDB{}
+		mockService := &MockObjectService{}
+		mockService.On("GetByID", mock.Anything, int64(1)).Return(object, nil)
+		service := &metadataWriter{MockObjectService: mockService, repo: repository.NewObjectRepository(db, repository.DefaultRepositoryOptions())}
+		handler := NewObjectHandlerWithInterface(service, createTestLogger())
+
+		c, w := createTestGinContext("PUT", "/api/v1/objects/1/metadata", map[string]interface{}{"colour": "red"})
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		c.Request.Header.Set("If-Match", object.ETag())
+		c.Set("user_id", "user-123")
+		handler.UpdateMetadata(c)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		assert.Len(t, db.pinned, 1)
+		assert.Contains(t, db.pinned[0], "WHERE id = $2 AND deleted_at IS NULL AND updated_at = $4 AND version = $5 RETURNING 1")
+		assert.Equal(t, []any{int64(1), "user-123", object.UpdatedAt, int64(3)}, db.args[1:])
+		assert.Zero(t, db.execs)
+	})
+
+	t.Run("write without If-Match is not pinned", func(t *testing.T) {
+		db := &concurrentWriteDB{}
+		mockService := &MockObjectService{}
+		mockService.On("GetByID", mock.Anything, int64(1)).Return(object, nil)
+		service := &metadataWriter{MockObjectService: mockService, repo: repository.NewObjectRepository(db, repository.DefaultRepositoryOptions())}
+		handler := NewObjectHandlerWithInterface(service, createTestLogger())
+
+		c, w := createTestGinContext("PUT", "/api/v1/objects/1/metadata", map[string]interface{}{"colour": "red"})
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		c.Set("user_id", "user-123")
+		handler.UpdateMetadata(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, db.pinned)
+	})
+}
+
+func TestObjectHandler_BulkUpdate_ReportsConflicts(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	current := int64(5)
+	result := &models.BulkUpdateResult{
+		Updated: []*models.Object{{ID: 1, Version: 2}},
+		Conflicts: []models.BulkUpdateConflict{
+			{ID: 2, ExpectedVersion: 4, CurrentVersion: &current, Reason: models.BulkConflictVersionMismatch},
+		},
+	}
+	mockService.On("BulkUpdate", mock.Anything, []int64{1, 2}, mock.Anything, map[int64]int64{2: 4}).Return(result, nil)
+
+	body := map[string]interface{}{
+		"ids":               []int64{1, 2},
+		"updates":           map[string]interface{}{"status": "archived"},
+		"expected_versions": map[string]int64{"2": 4},
+	}
+	c, w := createTestGinContext("POST", "/api/v1/objects/bulk/update", body)
+	handler.BulkUpdate(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response["data"], 1)
+	conflicts := response["conflicts"].([]interface{})
+	assert.Len(t, conflicts, 1)
+	assert.Equal(t, "version_mismatch", conflicts[0].(map[string]interface{})["reason"])
+	mockService.AssertExpectations(t)
+}
+
+func TestObjectTypeHandler_IfMatch(t *testing.T) {
+	objectType := &models.ObjectType{ID: 1, Name: "Type", UpdatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	t.Run("get sets tag", func(t *testing.T) {
+		mockService := &MockObjectTypeService{}
+		handler := NewObjectTypeHandlerWithInterface(mockService, createTestLogger())
+		mockService.On("GetByID", mock.Anything, int64(1)).Return(objectType, nil)
+
+		c, w := createTestGinContext("GET", "/api/v1/object-types/1", nil)
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		handler.GetByID(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, objectType.ETag(), w.Header().Get("ETag"))
+	})
+
+	t.Run("stale delete is rejected", func(t *testing.T) {
+		mockService := &MockObjectTypeService{}
+		handler := NewObjectTypeHandlerWithInterface(mockService, createTestLogger())
+		mockService.On("GetByID", mock.Anything, int64(1)).Return(objectType, nil)
+
+		c, w := createTestGinContext("DELETE", "/api/v1/object-types/1", nil)
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		c.Request.Header.Set("If-Match", `"0"`)
+		handler.Delete(c)
+
+		assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+		mockService.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("matching delete proceeds", func(t *testing.T) {
+		mockService := &MockObjectTypeService{}
+		handler := NewObjectTypeHandlerWithInterface(mockService, createTestLogger())
+		mockService.On("GetByID", mock.Anything, int64(1)).Return(objectType, nil)
+		mockService.On("Delete", mock.Anything, int64(1)).Return(nil)
+
+		c, w := createTestGinContext("DELETE", "/api/v1/object-types/1", nil)
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		c.Request.Header.Set("If-Match", objectType.ETag())
+		handler.Delete(c)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
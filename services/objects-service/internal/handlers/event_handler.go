@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+)
+
+// EventHandler serves the log of domain events to consumers that poll it
+type EventHandler struct {
+	service services.EventService
+	logger  *logrus.Logger
+}
+
+func NewEventHandler(service services.EventService, logger *logrus.Logger) *EventHandler {
+	return &EventHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List returns committed domain events after the given offset, oldest first
+func (h *EventHandler) List(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	var filter models.OutboxEventFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters: after and limit must be integers",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	page, err := h.service.List(c.Request.Context(), &filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+				"type":  "validation_error",
+				"meta":  gin.H{"request_id": requestID},
+			})
+			return
+		}
+
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+		}).WithError(err).Error("Failed to list events")
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to list events",
+			"type":  "internal_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": page,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+)
+
+// writeMetadataSchemaError answers metadata schema failures with a 400 and reports whether it did
+func writeMetadataSchemaError(c *gin.Context, err error, requestID string) bool {
+	var validationErr *services.MetadataValidationError
+	if errors.As(err, &validationErr) {
+		response := gin.H{
+			"error":  "Metadata does not match the object type schema",
+			"type":   "validation_error",
+			"field":  "metadata",
+			"errors": validationErr.Errors,
+			"meta":   gin.H{"request_id": requestID},
+		}
+		if validationErr.Index != nil {
+			response["index"] = *validationErr.Index
+		}
+		c.JSON(http.StatusBadRequest, response)
+		return true
+	}
+
+	if errors.Is(err, services.ErrInvalidMetadataSchema) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"type":  "validation_error",
+			"field": "metadata_schema",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return true
+	}
+
+	return false
+}
+
+// GetEffectiveMetadataSchema returns the metadata schema objects of a type are validated
+// against: the type's own schema merged with those inherited from its ancestors
+func (h *ObjectTypeHandler) GetEffectiveMetadataSchema(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid id format: id must be a positive integer",
+			"type":  "validation_error",
+			"field": "id",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	schema, err := h.service.GetEffectiveMetadataSchema(c.Request.Context(), id)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to get metadata schema", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{"object_type_id": id, "metadata_schema": schema},
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// ValidateMetadataSchema checks the metadata of existing objects of a type and its subtypes.
+// The optional body carries a candidate metadata_schema to try before saving it.
+func (h *ObjectHandler) ValidateMetadataSchema(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid id format: id must be a positive integer",
+			"type":  "validation_error",
+			"field": "id",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	var req models.ValidateMetadataSchemaRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.WithFields(logrus.Fields{
+				"request_id": requestID,
+			}).WithError(err).Error("Invalid request body")
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: failed to parse request body",
+				"type":  "validation_error",
+				"meta":  gin.H{"request_id": requestID},
+			})
+			return
+		}
+	}
+
+	report, err := h.service.ValidateExistingMetadata(c.Request.Context(), id, req.MetadataSchema)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to validate object metadata", requestID)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id":     requestID,
+		"object_type_id": id,
+		"checked":        report.Checked,
+		"invalid":        report.Invalid,
+	}).Info("Object metadata validated against schema")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+)
+
+func TestObjectHandler_Create_MetadataSchemaViolation(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	mockService.On("Create", mock.Anything, mock.Anything).Return(nil, &services.MetadataValidationError{
+		ObjectTypeID: 2,
+		Errors: []models.MetadataFieldError{
+			{Path: "/amount", Message: "expected number, but got string"},
+		},
+	})
+
+	c, w := createTestGinContext("POST", "/api/v1/objects", models.CreateObjectRequest{ObjectTypeID: 2, Name: "INV-1"})
+	handler.Create(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "metadata", response["field"])
+	assert.NotContains(t, response, "index")
+	errs := response["errors"].([]interface{})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "/amount", errs[0].(map[string]interface{})["path"])
+}
+
+func TestObjectHandler_BulkCreate_MetadataSchemaViolation(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	index := 3
+	mockService.On("BulkCreate", mock.Anything, mock.Anything).Return(nil, &services.MetadataValidationError{
+		ObjectTypeID: 2,
+		Index:        &index,
+		Errors:       []models.MetadataFieldError{{Path: "", Message: "missing properties: 'currency'"}},
+	})
+
+	c, w := createTestGinContext("POST", "/api/v1/objects/bulk", []models.CreateObjectRequest{{ObjectTypeID: 2, Name: "INV-1"}})
+	handler.BulkCreate(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(3), response["index"])
+}
+
+func TestObjectHandler_ValidateMetadataSchema(t *testing.T) {
+	t.Run("with candidate schema", func(t *testing.T) {
+		mockService := &MockObjectService{}
+		handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+		candidate := json.RawMessage(`{"required":["number"]}`)
+		report := &models.MetadataValidationReport{ObjectTypeID: 1, Checked: 4, Invalid: 1}
+		mockService.On("ValidateExistingMetadata", mock.Anything, int64(1), candidate).Return(report, nil)
+
+		c, w := createTestGinContext("POST", "/api/v1/object-types/1/metadata-schema/validate",
+			models.ValidateMetadataSchemaRequest{MetadataSchema: candidate})
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		handler.ValidateMetadataSchema(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("without body uses the stored schema", func(t *testing.T) {
+		mockService := &MockObjectService{}
+		handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+		mockService.On("ValidateExistingMetadata", mock.Anything, int64(1), json.RawMessage(nil)).
+			Return(&models.MetadataValidationReport{ObjectTypeID: 1}, nil)
+
+		c, w := createTestGinContext("POST", "/api/v1/object-types/1/metadata-schema/validate", nil)
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		handler.ValidateMetadataSchema(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid candidate schema", func(t *testing.T) {
+		mockService := &MockObjectService{}
+		handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+		mockService.On("ValidateExistingMetadata", mock.Anything, int64(1), mock.Anything).
+			Return(nil, fmt.Errorf("object type 1: %w", services.ErrInvalidMetadataSchema))
+
+		c, w := createTestGinContext("POST", "/api/v1/object-types/1/metadata-schema/validate",
+			models.ValidateMetadataSchemaRequest{MetadataSchema: json.RawMessage(`{"type":"money"}`)})
+		c.Params = gin.Params{{Key: "id", Value: "1"}}
+		handler.ValidateMetadataSchema(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "metadata_schema", response["field"])
+	})
+}
+
+func TestObjectTypeHandler_GetEffectiveMetadataSchema(t *testing.T) {
+	mockService := &MockObjectTypeService{}
+	handler := NewObjectTypeHandlerWithInterface(mockService, createTestLogger())
+
+	mockService.On("GetEffectiveMetadataSchema", mock.Anything, int64(2)).
+		Return(json.RawMessage(`{"type":"object","required":["number"]}`), nil)
+
+	c, w := createTestGinContext("GET", "/api/v1/object-types/2/metadata-schema", nil)
+	c.Params = gin.Params{{Key: "id", Value: "2"}}
+	handler.GetEffectiveMetadataSchema(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	schema := response["data"].(map[string]interface{})["metadata_schema"].(map[string]interface{})
+	assert.Equal(t, "object", schema["type"])
+	mockService.AssertExpectations(t)
+}
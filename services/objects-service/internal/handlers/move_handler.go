@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+)
+
+// writeMoveError answers failed moves whose cause the generic error handlers do not map, and
+// reports whether it did
+func writeMoveError(c *gin.Context, err error, requestID string) bool {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"type":  "not_found",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, services.ErrObjectDeleted):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Deleted objects cannot be moved",
+			"type":  "conflict",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// Move handles POST /api/v1/objects/:id/move. The object moves under parent_object_id, or to the
+// root when it is null, together with its descendants unless mode is single.
+func (h *ObjectHandler) Move(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid id format: id must be a positive integer",
+			"type":  "validation_error",
+			"field": "id",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	existingObj, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if !writeMoveError(c, err, requestID) {
+			h.handleServiceError(c, err, "Failed to get object", requestID)
+		}
+		return
+	}
+
+	if !h.checkOwnership(c, existingObj, "objects:update:all") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You can only move your own objects",
+			"type":  "permission_denied",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	if !checkIfMatch(c, existingObj.ETag(), requestID) {
+		return
+	}
+	ctx := writeContext(c, repository.ObjectPrecondition(existingObj))
+
+	var req models.MoveObjectRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format: failed to parse request body",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+	req.UpdatedBy = middleware.GetAuthenticatedUserID(c)
+
+	// A matched If-Match pins the version the client read, so a concurrent write still fails
+	if req.Version == nil && c.GetHeader("If-Match") != "" {
+		req.Version = &existingObj.Version
+	}
+
+	result, err := h.service.Move(ctx, id, &req)
+	if err != nil {
+		if !writeMetadataSchemaError(c, err, requestID) && !writeMoveError(c, err, requestID) {
+			h.handleServiceError(c, err, "Failed to move object", requestID)
+		}
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"object_id":  id,
+		"request_id": requestID,
+		"retyped":    len(result.Retyped),
+		"reparented": len(result.Reparented),
+	}).Info("Object moved successfully")
+
+	c.Header("ETag", result.Object.ETag())
+	c.JSON(http.StatusOK, gin.H{
+		"data":    result,
+		"message": "Object moved successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+// Move handles POST /api/v1/object-types/:id/move. The type moves under parent_type_id, or to
+// the root when it is null, together with its subtypes.
+func (h *ObjectTypeHandler) Move(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid id format: id must be a positive integer",
+			"type":  "validation_error",
+			"field": "id",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	var req models.MoveObjectTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format: failed to parse request body",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+	req.UpdatedBy = middleware.GetAuthenticatedUserID(c)
+
+	objectType, err := h.service.Move(c.Request.Context(), id, &req)
+	if err != nil {
+		if !writeMetadataSchemaError(c, err, requestID) && !writeMoveError(c, err, requestID) {
+			h.handleServiceError(c, err, "Failed to move object type", requestID)
+		}
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"object_type_id": id,
+		"request_id":     requestID,
+	}).Info("Object type moved successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    objectType,
+		"message": "Object type moved successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/filterexpr"
+)
+
+// writeFilterError reports an invalid filter expression with the position it failed at, and
+// reports whether err was one
+func writeFilterError(c *gin.Context, err error, requestID string) bool {
+	var filterErr *filterexpr.Error
+	if !errors.As(err, &filterErr) {
+		return false
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":    "Invalid filter expression: " + filterErr.Message,
+		"type":     "validation_error",
+		"field":    "filter",
+		"position": filterErr.Position,
+		"meta":     gin.H{"request_id": requestID},
+	})
+	return true
+}
@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -26,7 +30,7 @@ type ObjectServiceInterface interface {
 	Update(ctx context.Context, id int64, req *models.UpdateObjectRequest) (*models.Object, error)
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, filter *models.ObjectFilter) ([]*models.Object, int64, error)
-	Search(ctx context.Context, query string, limit int) ([]*models.Object, error)
+	Search(ctx context.Context, filter *models.ObjectSearchFilter) (*models.ObjectSearchResults, error)
 	FindByMetadata(ctx context.Context, key, value string) ([]*models.Object, error)
 	FindByTags(ctx context.Context, tags []string, matchAll bool) ([]*models.Object, error)
 	UpdateMetadata(ctx context.Context, id int64, metadata map[string]interface{}, updatedBy string) error
@@ -37,10 +41,18 @@ type ObjectServiceInterface interface {
 	GetAncestors(ctx context.Context, id int64) ([]*models.Object, error)
 	GetPath(ctx context.Context, id int64) ([]*models.Object, error)
 	BulkCreate(ctx context.Context, objects []*models.CreateObjectRequest) ([]*models.Object, error)
-	BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest) ([]*models.Object, error)
+	BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest, expectedVersions map[int64]int64) (*models.BulkUpdateResult, error)
 	BulkDelete(ctx context.Context, ids []int64) error
 	ValidateParentChild(ctx context.Context, parentID, childID int64) error
 	GetObjectStats(ctx context.Context, filter *models.ObjectFilter) (*repository.ObjectStats, error)
+	Aggregate(ctx context.Context, req *models.ObjectAggregationRequest) (*models.ObjectAggregationResult, error)
+	ValidateExistingMetadata(ctx context.Context, objectTypeID int64, candidate json.RawMessage) (*models.MetadataValidationReport, error)
+	ListVersions(ctx context.Context, id int64, filter *models.ObjectVersionFilter) ([]*models.ObjectVersion, int64, error)
+	GetVersion(ctx context.Context, entityType string, id, revision int64) (*models.ObjectVersion, error)
+	DiffVersions(ctx context.Context, entityType string, id, fromRevision, toRevision int64) (*models.ObjectVersionDiff, error)
+	GetAsOf(ctx context.Context, id int64, at time.Time) (*models.Object, error)
+	RestoreVersion(ctx context.Context, id, revision int64, updatedBy string) (*models.Object, error)
+	Move(ctx context.Context, id int64, req *models.MoveObjectRequest) (*models.MoveObjectResult, error)
 }
 
 // ObjectHandler handles HTTP requests for objects
@@ -49,6 +61,8 @@ type ObjectHandler struct {
 	logger         *logrus.Logger
 	auditLogger    *logging.AuditLogger
 	standardLogger *logging.StandardLogger
+	cloneService   services.CloneService
+	trashService   services.TrashService
 }
 
 // NewObjectHandler creates a new ObjectHandler
@@ -71,15 +85,48 @@ func NewObjectHandlerWithInterface(service ObjectServiceInterface, logger *logru
 	}
 }
 
+// SetAuditSink persists the handler's audit events to sink
+func (h *ObjectHandler) SetAuditSink(sink logging.AuditSink) {
+	h.auditLogger.SetSink(sink)
+}
+
+// SetCloneService enables cloning of objects through service
+func (h *ObjectHandler) SetCloneService(service services.CloneService) {
+	h.cloneService = service
+}
+
+// SetTrashService enables listing, restoring and purging deleted objects through service
+func (h *ObjectHandler) SetTrashService(service services.TrashService) {
+	h.trashService = service
+}
+
 func (h *ObjectHandler) handleServiceError(c *gin.Context, err error, operation string, requestID string) {
 	h.logger.WithFields(logrus.Fields{
 		"request_id": requestID,
 	}).WithError(err).Error(operation)
 
-	switch err {
-	case nil:
+	if writeMetadataSchemaError(c, err, requestID) || writeHistoryError(c, err, requestID) ||
+		writeFilterError(c, err, requestID) {
+		return
+	}
+
+	if errors.Is(err, repository.ErrInvalidInput) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
 		return
-	case repository.ErrOptimisticLock:
+	}
+
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, repository.ErrOptimisticLock):
+		if c.GetHeader("If-Match") != "" {
+			writePreconditionFailed(c, requestID)
+			return
+		}
 		c.JSON(http.StatusConflict, gin.H{
 			"error": "Version conflict - the object has been modified by another request",
 			"type":  "conflict",
@@ -101,6 +148,16 @@ func (h *ObjectHandler) checkOwnership(c *gin.Context, object *models.Object, al
 		return false
 	}
 
+	if hasAllPermission(c, allPermission) {
+		return true
+	}
+
+	return object.CreatedBy == userID
+}
+
+// hasAllPermission reports whether the caller may act on objects of every user: an admin, or
+// a caller matched by allPermission rather than by its ":own" counterpart
+func hasAllPermission(c *gin.Context, allPermission string) bool {
 	userRoles := middleware.GetAuthenticatedUserRoles(c)
 	for _, role := range userRoles {
 		if role == "admin" || role == "object-type-admin" {
@@ -110,19 +167,15 @@ func (h *ObjectHandler) checkOwnership(c *gin.Context, object *models.Object, al
 
 	matchedPermissions, exists := c.Get("matched_permissions")
 	if !exists {
-		return object.CreatedBy == userID
+		return false
 	}
 
 	perms, ok := matchedPermissions.([]string)
 	if !ok {
-		return object.CreatedBy == userID
-	}
-
-	if slices.Contains(perms, allPermission) {
-		return true
+		return false
 	}
 
-	return object.CreatedBy == userID
+	return slices.Contains(perms, allPermission)
 }
 
 func (h *ObjectHandler) Create(c *gin.Context) {
@@ -179,6 +232,11 @@ func (h *ObjectHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	if asOf := c.Query("as_of"); asOf != "" {
+		h.getAsOf(c, id, asOf, requestID)
+		return
+	}
+
 	object, err := h.service.GetByID(c.Request.Context(), id)
 	if err != nil {
 		h.handleServiceError(c, err, "Failed to get object", requestID)
@@ -194,6 +252,7 @@ func (h *ObjectHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", object.ETag())
 	c.JSON(http.StatusOK, gin.H{
 		"data":    object,
 		"meta":    gin.H{"request_id": requestID},
@@ -236,6 +295,7 @@ func (h *ObjectHandler) GetByPublicID(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", object.ETag())
 	c.JSON(http.StatusOK, gin.H{
 		"data":    object,
 		"meta":    gin.H{"request_id": requestID},
@@ -271,6 +331,7 @@ func (h *ObjectHandler) GetByName(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", object.ETag())
 	c.JSON(http.StatusOK, gin.H{
 		"data":    object,
 		"meta":    gin.H{"request_id": requestID},
@@ -307,6 +368,11 @@ func (h *ObjectHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, existingObj.ETag(), requestID) {
+		return
+	}
+	ctx := writeContext(c, repository.ObjectPrecondition(existingObj))
+
 	var req models.UpdateObjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		h.logger.WithFields(logrus.Fields{
@@ -325,7 +391,12 @@ func (h *ObjectHandler) Update(c *gin.Context) {
 		req.UpdatedBy = userID
 	}
 
-	object, err := h.service.Update(c.Request.Context(), id, &req)
+	// A matched If-Match pins the version the client read, so a concurrent write still fails
+	if req.Version == nil && c.GetHeader("If-Match") != "" {
+		req.Version = &existingObj.Version
+	}
+
+	object, err := h.service.Update(ctx, id, &req)
 	if err != nil {
 		h.handleServiceError(c, err, "Failed to update object", requestID)
 		return
@@ -336,6 +407,7 @@ func (h *ObjectHandler) Update(c *gin.Context) {
 		"request_id": requestID,
 	}).Info("Object updated successfully")
 
+	c.Header("ETag", object.ETag())
 	c.JSON(http.StatusOK, gin.H{
 		"data":    object,
 		"message": "Object updated successfully",
@@ -373,7 +445,12 @@ func (h *ObjectHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err = h.service.Delete(c.Request.Context(), id)
+	if !checkIfMatch(c, existingObj.ETag(), requestID) {
+		return
+	}
+	ctx := writeContext(c, repository.ObjectPrecondition(existingObj))
+
+	err = h.service.Delete(ctx, id)
 	if err != nil {
 		h.handleServiceError(c, err, "Failed to delete object", requestID)
 		return
@@ -406,6 +483,8 @@ func (h *ObjectHandler) List(c *gin.Context) {
 		filter.Status = status
 	}
 
+	filter.Filter = c.Query("filter")
+
 	filter.Limit = 50
 	filter.Offset = 0
 
@@ -439,38 +518,50 @@ func (h *ObjectHandler) List(c *gin.Context) {
 	})
 }
 
+// Search ranks objects against a full-text query, with highlighted snippets. When nothing matches
+// the query, names are matched by similarity instead and meta.match_type is "fuzzy".
 func (h *ObjectHandler) Search(c *gin.Context) {
 	requestID := c.GetHeader("X-Request-ID")
 
-	query := c.Query("q")
-	if query == "" {
+	var filter models.ObjectSearchFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Missing search query: query parameter 'q' is required",
+			"error": "Invalid query parameters",
 			"type":  "validation_error",
-			"field": "q",
 			"meta":  gin.H{"request_id": requestID},
 		})
 		return
 	}
 
-	limit := 50
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
-		}
+	if strings.TrimSpace(filter.Query) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Missing search query: query parameter 'q' is required",
+			"type":  "validation_error",
+			"field": "q",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
 	}
 
-	results, err := h.service.Search(c.Request.Context(), query, limit)
+	results, err := h.service.Search(c.Request.Context(), &filter)
 	if err != nil {
 		h.handleServiceError(c, err, "Failed to search objects", requestID)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"data":  results,
-		"query": query,
-		"meta":  gin.H{"request_id": requestID},
-	})
+	response := gin.H{
+		"data":  results.Results,
+		"query": filter.Query,
+		"meta": gin.H{
+			"request_id": requestID,
+			"total":      results.Total,
+			"match_type": results.MatchType,
+		},
+	}
+	if results.Facets != nil {
+		response["facets"] = results.Facets
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *ObjectHandler) UpdateMetadata(c *gin.Context) {
@@ -516,8 +607,13 @@ func (h *ObjectHandler) UpdateMetadata(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, existingObj.ETag(), requestID) {
+		return
+	}
+	ctx := writeContext(c, repository.ObjectPrecondition(existingObj))
+
 	userID := middleware.GetAuthenticatedUserID(c)
-	err = h.service.UpdateMetadata(c.Request.Context(), id, metadata, userID)
+	err = h.service.UpdateMetadata(ctx, id, metadata, userID)
 	if err != nil {
 		h.handleServiceError(c, err, "Failed to update metadata", requestID)
 		return
@@ -571,8 +667,13 @@ func (h *ObjectHandler) AddTags(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, existingObj.ETag(), requestID) {
+		return
+	}
+	ctx := writeContext(c, repository.ObjectPrecondition(existingObj))
+
 	userID := middleware.GetAuthenticatedUserID(c)
-	err = h.service.AddTags(c.Request.Context(), id, req.Tags, userID)
+	err = h.service.AddTags(ctx, id, req.Tags, userID)
 	if err != nil {
 		h.handleServiceError(c, err, "Failed to add tags", requestID)
 		return
@@ -651,8 +752,13 @@ func (h *ObjectHandler) RemoveTags(c *gin.Context) {
 		return
 	}
 
+	if !checkIfMatch(c, existingObj.ETag(), requestID) {
+		return
+	}
+	ctx := writeContext(c, repository.ObjectPrecondition(existingObj))
+
 	userID := middleware.GetAuthenticatedUserID(c)
-	err = h.service.RemoveTags(c.Request.Context(), id, req.Tags, userID)
+	err = h.service.RemoveTags(ctx, id, req.Tags, userID)
 	if err != nil {
 		h.logger.WithFields(logrus.Fields{
 			"request_id":  requestID,
@@ -832,8 +938,9 @@ func (h *ObjectHandler) BulkUpdate(c *gin.Context) {
 	requestID := c.GetHeader("X-Request-ID")
 
 	var req struct {
-		IDs     []int64                     `json:"ids"`
-		Updates *models.UpdateObjectRequest `json:"updates"`
+		IDs              []int64                     `json:"ids"`
+		Updates          *models.UpdateObjectRequest `json:"updates"`
+		ExpectedVersions map[int64]int64             `json:"expected_versions"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -854,16 +961,17 @@ func (h *ObjectHandler) BulkUpdate(c *gin.Context) {
 		return
 	}
 
-	results, err := h.service.BulkUpdate(c.Request.Context(), req.IDs, req.Updates)
+	result, err := h.service.BulkUpdate(c.Request.Context(), req.IDs, req.Updates, req.ExpectedVersions)
 	if err != nil {
 		h.handleServiceError(c, err, "Failed to bulk update objects", requestID)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"data":    results,
-		"message": "Objects updated successfully",
-		"meta":    gin.H{"request_id": requestID},
+		"data":      result.Updated,
+		"conflicts": result.Conflicts,
+		"message":   "Objects updated successfully",
+		"meta":      gin.H{"request_id": requestID},
 	})
 }
 
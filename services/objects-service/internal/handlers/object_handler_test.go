@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"testing"
 	"time"
@@ -10,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/filterexpr"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
 )
@@ -75,12 +78,12 @@ func (m *MockObjectService) List(ctx context.Context, filter *models.ObjectFilte
 	return args.Get(0).([]*models.Object), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockObjectService) Search(ctx context.Context, query string, limit int) ([]*models.Object, error) {
-	args := m.Called(ctx, query, limit)
+func (m *MockObjectService) Search(ctx context.Context, filter *models.ObjectSearchFilter) (*models.ObjectSearchResults, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*models.Object), args.Error(1)
+	return args.Get(0).(*models.ObjectSearchResults), args.Error(1)
 }
 
 func (m *MockObjectService) FindByMetadata(ctx context.Context, key, value string) ([]*models.Object, error) {
@@ -154,12 +157,12 @@ func (m *MockObjectService) BulkCreate(ctx context.Context, objects []*models.Cr
 	return args.Get(0).([]*models.Object), args.Error(1)
 }
 
-func (m *MockObjectService) BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest) ([]*models.Object, error) {
-	args := m.Called(ctx, ids, updates)
+func (m *MockObjectService) BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest, expectedVersions map[int64]int64) (*models.BulkUpdateResult, error) {
+	args := m.Called(ctx, ids, updates, expectedVersions)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*models.Object), args.Error(1)
+	return args.Get(0).(*models.BulkUpdateResult), args.Error(1)
 }
 
 func (m *MockObjectService) BulkDelete(ctx context.Context, ids []int64) error {
@@ -180,6 +183,70 @@ func (m *MockObjectService) GetObjectStats(ctx context.Context, filter *models.O
 	return args.Get(0).(*repository.ObjectStats), args.Error(1)
 }
 
+func (m *MockObjectService) Aggregate(ctx context.Context, req *models.ObjectAggregationRequest) (*models.ObjectAggregationResult, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ObjectAggregationResult), args.Error(1)
+}
+
+func (m *MockObjectService) ValidateExistingMetadata(ctx context.Context, objectTypeID int64, candidate json.RawMessage) (*models.MetadataValidationReport, error) {
+	args := m.Called(ctx, objectTypeID, candidate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MetadataValidationReport), args.Error(1)
+}
+
+func (m *MockObjectService) ListVersions(ctx context.Context, id int64, filter *models.ObjectVersionFilter) ([]*models.ObjectVersion, int64, error) {
+	args := m.Called(ctx, id, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*models.ObjectVersion), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockObjectService) GetVersion(ctx context.Context, entityType string, id, revision int64) (*models.ObjectVersion, error) {
+	args := m.Called(ctx, entityType, id, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ObjectVersion), args.Error(1)
+}
+
+func (m *MockObjectService) DiffVersions(ctx context.Context, entityType string, id, fromRevision, toRevision int64) (*models.ObjectVersionDiff, error) {
+	args := m.Called(ctx, entityType, id, fromRevision, toRevision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ObjectVersionDiff), args.Error(1)
+}
+
+func (m *MockObjectService) GetAsOf(ctx context.Context, id int64, at time.Time) (*models.Object, error) {
+	args := m.Called(ctx, id, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Object), args.Error(1)
+}
+
+func (m *MockObjectService) RestoreVersion(ctx context.Context, id, revision int64, updatedBy string) (*models.Object, error) {
+	args := m.Called(ctx, id, revision, updatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Object), args.Error(1)
+}
+
+func (m *MockObjectService) Move(ctx context.Context, id int64, req *models.MoveObjectRequest) (*models.MoveObjectResult, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MoveObjectResult), args.Error(1)
+}
+
 func TestObjectHandler_Create(t *testing.T) {
 	logger := createTestLogger()
 	mockService := &MockObjectService{}
@@ -537,11 +604,15 @@ func TestObjectHandler_Search(t *testing.T) {
 
 	handler := NewObjectHandlerWithInterface(mockService, logger)
 
-	objects := []*models.Object{
-		{ID: 1, Name: "SearchResult"},
+	results := &models.ObjectSearchResults{
+		Results: []*models.ObjectSearchResult{
+			{Object: &models.Object{ID: 1, Name: "SearchResult"}, Rank: 0.5, MatchType: models.SearchMatchFullText},
+		},
+		Total:     1,
+		MatchType: models.SearchMatchFullText,
 	}
 
-	mockService.On("Search", mock.Anything, "query", 50).Return(objects, nil)
+	mockService.On("Search", mock.Anything, &models.ObjectSearchFilter{Query: "query"}).Return(results, nil)
 
 	c, w := createTestGinContext("GET", "/api/v1/objects/search?q=query", nil)
 
@@ -552,6 +623,137 @@ func TestObjectHandler_Search(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestObjectHandler_Search_Filters(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	typeID := int64(4)
+	expected := &models.ObjectSearchFilter{Query: "widgte", ObjectTypeID: &typeID, Status: "active", Limit: 5, Offset: 10}
+	mockService.On("Search", mock.Anything, expected).Return(&models.ObjectSearchResults{
+		Results:   []*models.ObjectSearchResult{},
+		MatchType: models.SearchMatchFuzzy,
+	}, nil)
+
+	c, w := createTestGinContext("GET", "/api/v1/objects/search?q=widgte&object_type_id=4&status=active&limit=5&offset=10", nil)
+	handler.Search(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	meta := response["meta"].(map[string]interface{})
+	assert.Equal(t, "fuzzy", meta["match_type"])
+	assert.Equal(t, float64(0), meta["total"])
+	mockService.AssertExpectations(t)
+}
+
+func TestObjectHandler_Search_MissingQuery(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	c, w := createTestGinContext("GET", "/api/v1/objects/search?q=%20", nil)
+	handler.Search(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "Search", mock.Anything, mock.Anything)
+}
+
+func TestObjectHandler_Search_Facets(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	expected := &models.ObjectSearchFilter{Query: "widget", Facets: []string{"status"}}
+	mockService.On("Search", mock.Anything, expected).Return(&models.ObjectSearchResults{
+		Results:   []*models.ObjectSearchResult{},
+		MatchType: models.SearchMatchFullText,
+		Facets: map[string][]*models.FacetCount{
+			"status": {{Value: "active", Count: 3}},
+		},
+	}, nil)
+
+	c, w := createTestGinContext("GET", "/api/v1/objects/search?q=widget&facets=status", nil)
+	handler.Search(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	facets := response["facets"].(map[string]interface{})
+	assert.Len(t, facets["status"], 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestObjectHandler_Aggregate(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	mockService.On("Aggregate", mock.Anything, mock.MatchedBy(func(req *models.ObjectAggregationRequest) bool {
+		return len(req.GroupBy) == 1 && req.GroupBy[0] == "status" && req.Filter.Filter == "metadata.amount gt 10"
+	})).Return(&models.ObjectAggregationResult{
+		Buckets: []*models.AggregationBucket{{Key: map[string]interface{}{"status": "active"}, Count: 2}},
+		Total:   2,
+	}, nil)
+
+	body := map[string]interface{}{
+		"filter":   map[string]interface{}{"filter": "metadata.amount gt 10"},
+		"group_by": []string{"status"},
+	}
+	c, w := createTestGinContext("POST", "/api/v1/objects/aggregate", body)
+	handler.Aggregate(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, float64(2), data["total"])
+	mockService.AssertExpectations(t)
+}
+
+func TestObjectHandler_Aggregate_InvalidRequest(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	mockService.On("Aggregate", mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("unknown dimension %q: %w", "name", repository.ErrInvalidInput))
+
+	c, w := createTestGinContext("POST", "/api/v1/objects/aggregate", map[string]interface{}{"group_by": []string{"name"}})
+	handler.Aggregate(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestObjectHandler_List_Filter(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	mockService.On("List", mock.Anything, mock.MatchedBy(func(f *models.ObjectFilter) bool {
+		return f.Filter == `metadata.amount gt 1000` && f.Limit == 50
+	})).Return([]*models.Object{}, int64(0), nil)
+
+	c, w := createTestGinContext("GET", "/api/v1/objects?filter=metadata.amount+gt+1000", nil)
+	handler.List(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestObjectHandler_List_InvalidFilter(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	_, parseErr := filterexpr.Parse(`status = active`)
+	mockService.On("List", mock.Anything, mock.Anything).
+		Return(nil, int64(0), fmt.Errorf("invalid filter: %w: %w", repository.ErrInvalidInput, parseErr))
+
+	c, w := createTestGinContext("GET", "/api/v1/objects?filter=status+%3D+active", nil)
+	handler.List(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "filter", response["field"])
+	assert.Equal(t, float64(10), response["position"])
+	assert.Contains(t, response["error"], "Invalid filter expression: ")
+}
+
 func TestObjectHandler_AddTags(t *testing.T) {
 	logger := createTestLogger()
 	mockService := &MockObjectService{}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+)
+
+// writeHistoryError answers version history failures and reports whether it did
+func writeHistoryError(c *gin.Context, err error, requestID string) bool {
+	switch {
+	case errors.Is(err, services.ErrObjectVersionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"type":  "not_found",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, services.ErrObjectDeleted):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Deleted objects cannot be restored to an earlier version",
+			"type":  "conflict",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, services.ErrVersionNotRestorable):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"type":  "validation_error",
+			"field": "revision",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// parseHistoryParam reads a positive integer path or query value, answering 400 when it is not one
+func parseHistoryParam(c *gin.Context, field, value, requestID string) (int64, bool) {
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || parsed <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid " + field + " format: " + field + " must be a positive integer",
+			"type":  "validation_error",
+			"field": field,
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return 0, false
+	}
+	return parsed, true
+}
+
+// authorizeHistory applies the ownership rules of the object to its history. History of hard-deleted
+// relationships outlives their base object and is only shown to holders of the "all" permission.
+func (h *ObjectHandler) authorizeHistory(c *gin.Context, id int64, allPermission, requestID string) bool {
+	object, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			h.handleServiceError(c, err, "Failed to get object", requestID)
+			return false
+		}
+		object = &models.Object{}
+	}
+
+	if !h.checkOwnership(c, object, allPermission) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You can only access the history of your own objects",
+			"type":  "permission_denied",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return false
+	}
+	return true
+}
+
+// getAsOf answers GET /objects/:id?as_of=<RFC 3339 time> with the object as it was at that time
+func (h *ObjectHandler) getAsOf(c *gin.Context, id int64, asOf, requestID string) {
+	at, err := time.Parse(time.RFC3339, asOf)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid as_of format: as_of must be an RFC 3339 timestamp",
+			"type":  "validation_error",
+			"field": "as_of",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	if !h.authorizeHistory(c, id, "objects:read:all", requestID) {
+		return
+	}
+
+	object, err := h.service.GetAsOf(c.Request.Context(), id, at)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to get object version", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": object,
+		"meta": gin.H{"request_id": requestID, "as_of": at},
+	})
+}
+
+// ListVersions lists the recorded versions of an object, newest first. With
+// entity_type=relationship it lists the history of the relationship stored under that object ID.
+func (h *ObjectHandler) ListVersions(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, ok := parseHistoryParam(c, "id", c.Param("id"), requestID)
+	if !ok {
+		return
+	}
+
+	var filter models.ObjectVersionFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	if !h.authorizeHistory(c, id, "objects:read:all", requestID) {
+		return
+	}
+
+	versions, total, err := h.service.ListVersions(c.Request.Context(), id, &filter)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to list object versions", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": versions,
+		"meta": gin.H{"request_id": requestID, "total": total},
+	})
+}
+
+// GetVersion returns one recorded version of an object, including its full snapshot
+func (h *ObjectHandler) GetVersion(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, ok := parseHistoryParam(c, "id", c.Param("id"), requestID)
+	if !ok {
+		return
+	}
+	revision, ok := parseHistoryParam(c, "revision", c.Param("revision"), requestID)
+	if !ok {
+		return
+	}
+
+	if !h.authorizeHistory(c, id, "objects:read:all", requestID) {
+		return
+	}
+
+	version, err := h.service.GetVersion(c.Request.Context(), c.Query("entity_type"), id, revision)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to get object version", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": version,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// DiffVersions compares two recorded versions of an object field by field
+func (h *ObjectHandler) DiffVersions(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, ok := parseHistoryParam(c, "id", c.Param("id"), requestID)
+	if !ok {
+		return
+	}
+	from, ok := parseHistoryParam(c, "from", c.Query("from"), requestID)
+	if !ok {
+		return
+	}
+	to, ok := parseHistoryParam(c, "to", c.Query("to"), requestID)
+	if !ok {
+		return
+	}
+
+	if !h.authorizeHistory(c, id, "objects:read:all", requestID) {
+		return
+	}
+
+	diff, err := h.service.DiffVersions(c.Request.Context(), c.Query("entity_type"), id, from, to)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to diff object versions", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": diff,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// RestoreVersion writes an earlier version of an object back as its newest version
+func (h *ObjectHandler) RestoreVersion(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, ok := parseHistoryParam(c, "id", c.Param("id"), requestID)
+	if !ok {
+		return
+	}
+	revision, ok := parseHistoryParam(c, "revision", c.Param("revision"), requestID)
+	if !ok {
+		return
+	}
+
+	existingObj, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to get object", requestID)
+		return
+	}
+
+	if !h.checkOwnership(c, existingObj, "objects:update:all") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You can only update your own objects",
+			"type":  "permission_denied",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	if !checkIfMatch(c, existingObj.ETag(), requestID) {
+		return
+	}
+	ctx := writeContext(c, repository.ObjectPrecondition(existingObj))
+
+	object, err := h.service.RestoreVersion(ctx, id, revision, middleware.GetAuthenticatedUserID(c))
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to restore object version", requestID)
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"object_id":  id,
+		"revision":   revision,
+		"request_id": requestID,
+	}).Info("Object restored to earlier version")
+
+	c.Header("ETag", object.ETag())
+	c.JSON(http.StatusOK, gin.H{
+		"data":    object,
+		"message": "Object restored successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
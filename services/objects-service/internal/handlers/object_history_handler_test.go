@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+)
+
+func ownedObject() *models.Object {
+	return &models.Object{ID: 7, ObjectTypeID: 1, Name: "Widget", CreatedBy: "user-123"}
+}
+
+func TestObjectHandler_ListVersions(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	mockService.On("GetByID", mock.Anything, int64(7)).Return(ownedObject(), nil)
+	mockService.On("ListVersions", mock.Anything, int64(7), &models.ObjectVersionFilter{EntityType: "relationship", Limit: 5}).
+		Return([]*models.ObjectVersion{{ObjectID: 7, Revision: 2}, {ObjectID: 7, Revision: 1}}, int64(2), nil)
+
+	c, w := createTestGinContext("GET", "/api/v1/objects/7/versions?entity_type=relationship&limit=5", nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("user_id", "user-123")
+	handler.ListVersions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response["data"], 2)
+	assert.Equal(t, float64(2), response["meta"].(map[string]interface{})["total"])
+	mockService.AssertExpectations(t)
+}
+
+func TestObjectHandler_ListVersions_NotOwner(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	mockService.On("GetByID", mock.Anything, int64(7)).Return(ownedObject(), nil)
+
+	c, w := createTestGinContext("GET", "/api/v1/objects/7/versions", nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("user_id", "someone-else")
+	handler.ListVersions(c)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockService.AssertNotCalled(t, "ListVersions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestObjectHandler_GetVersion(t *testing.T) {
+	t.Run("found", func(t *testing.T) {
+		mockService := &MockObjectService{}
+		handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+		mockService.On("GetByID", mock.Anything, int64(7)).Return(ownedObject(), nil)
+		mockService.On("GetVersion", mock.Anything, "", int64(7), int64(2)).
+			Return(&models.ObjectVersion{ObjectID: 7, Revision: 2, Snapshot: json.RawMessage(`{"name":"Widget"}`)}, nil)
+
+		c, w := createTestGinContext("GET", "/api/v1/objects/7/versions/2", nil)
+		c.Params = gin.Params{{Key: "id", Value: "7"}, {Key: "revision", Value: "2"}}
+		c.Set("user_id", "user-123")
+		handler.GetVersion(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"snapshot":{"name":"Widget"}`)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := &MockObjectService{}
+		handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+		mockService.On("GetByID", mock.Anything, int64(7)).Return(ownedObject(), nil)
+		mockService.On("GetVersion", mock.Anything, "", int64(7), int64(9)).
+			Return(nil, fmt.Errorf("object 7 revision 9: %w", services.ErrObjectVersionNotFound))
+
+		c, w := createTestGinContext("GET", "/api/v1/objects/7/versions/9", nil)
+		c.Params = gin.Params{{Key: "id", Value: "7"}, {Key: "revision", Value: "9"}}
+		c.Set("user_id", "user-123")
+		handler.GetVersion(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid revision", func(t *testing.T) {
+		handler := NewObjectHandlerWithInterface(&MockObjectService{}, createTestLogger())
+
+		c, w := createTestGinContext("GET", "/api/v1/objects/7/versions/latest", nil)
+		c.Params = gin.Params{{Key: "id", Value: "7"}, {Key: "revision", Value: "latest"}}
+		handler.GetVersion(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestObjectHandler_GetVersion_DeletedRelationshipRequiresReadAll(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	mockService.On("GetByID", mock.Anything, int64(9)).Return(nil, repository.ErrNotFound)
+	mockService.On("GetVersion", mock.Anything, "relationship", int64(9), int64(1)).
+		Return(&models.ObjectVersion{ObjectID: 9, Revision: 1}, nil)
+
+	c, w := createTestGinContext("GET", "/api/v1/objects/9/versions/1?entity_type=relationship", nil)
+	c.Params = gin.Params{{Key: "id", Value: "9"}, {Key: "revision", Value: "1"}}
+	c.Set("user_id", "user-123")
+	handler.GetVersion(c)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	c, w = createTestGinContext("GET", "/api/v1/objects/9/versions/1?entity_type=relationship", nil)
+	c.Params = gin.Params{{Key: "id", Value: "9"}, {Key: "revision", Value: "1"}}
+	c.Set("user_id", "user-123")
+	c.Set("matched_permissions", []string{"objects:read:all"})
+	handler.GetVersion(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestObjectHandler_DiffVersions(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	mockService.On("GetByID", mock.Anything, int64(7)).Return(ownedObject(), nil)
+	mockService.On("DiffVersions", mock.Anything, "", int64(7), int64(1), int64(3)).Return(&models.ObjectVersionDiff{
+		ObjectID: 7, FromRevision: 1, ToRevision: 3,
+		Changes: map[string]models.FieldChange{"name": {Old: json.RawMessage(`"A"`), New: json.RawMessage(`"B"`)}},
+	}, nil)
+
+	c, w := createTestGinContext("GET", "/api/v1/objects/7/versions/diff?from=1&to=3", nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("user_id", "user-123")
+	handler.DiffVersions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":{"old":"A","new":"B"}`)
+
+	c, w = createTestGinContext("GET", "/api/v1/objects/7/versions/diff?from=1", nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	handler.DiffVersions(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestObjectHandler_GetByID_AsOf(t *testing.T) {
+	mockService := &MockObjectService{}
+	handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+	at := time.Date(2024, time.January, 2, 9, 0, 0, 0, time.UTC)
+	mockService.On("GetByID", mock.Anything, int64(7)).Return(ownedObject(), nil)
+	mockService.On("GetAsOf", mock.Anything, int64(7), at).Return(&models.Object{ID: 7, Name: "Old name"}, nil)
+
+	c, w := createTestGinContext("GET", "/api/v1/objects/7?as_of=2024-01-02T09:00:00Z", nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	c.Set("user_id", "user-123")
+	handler.GetByID(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Old name"`)
+
+	c, w = createTestGinContext("GET", "/api/v1/objects/7?as_of=yesterday", nil)
+	c.Params = gin.Params{{Key: "id", Value: "7"}}
+	handler.GetByID(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestObjectHandler_RestoreVersion(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"restored", nil, http.StatusOK},
+		{"deleted object", fmt.Errorf("object 7: %w", services.ErrObjectDeleted), http.StatusConflict},
+		{"delete revision", fmt.Errorf("revision 3 records a delete: %w", services.ErrVersionNotRestorable), http.StatusBadRequest},
+		{"concurrent update", repository.ErrOptimisticLock, http.StatusConflict},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockService := &MockObjectService{}
+			handler := NewObjectHandlerWithInterface(mockService, createTestLogger())
+
+			mockService.On("GetByID", mock.Anything, int64(7)).Return(ownedObject(), nil)
+			if tc.err != nil {
+				mockService.On("RestoreVersion", mock.Anything, int64(7), int64(1), "user-123").Return(nil, tc.err)
+			} else {
+				mockService.On("RestoreVersion", mock.Anything, int64(7), int64(1), "user-123").
+					Return(&models.Object{ID: 7, Name: "Widget", Version: 6}, nil)
+			}
+
+			c, w := createTestGinContext("POST", "/api/v1/objects/7/versions/1/restore", nil)
+			c.Params = gin.Params{{Key: "id", Value: "7"}, {Key: "revision", Value: "1"}}
+			c.Set("user_id", "user-123")
+			handler.RestoreVersion(c)
+
+			assert.Equal(t, tc.status, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
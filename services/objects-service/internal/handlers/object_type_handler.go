@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/v-egorov/service-boilerplate/common/logging"
 	"github.com/v-egorov/service-boilerplate/common/middleware"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
 )
 
@@ -28,7 +31,9 @@ type ObjectTypeServiceInterface interface {
 	List(ctx context.Context, filter *models.ObjectTypeFilter) ([]*models.ObjectType, error)
 	Search(ctx context.Context, query string, limit int) ([]*models.ObjectType, error)
 	ValidateMove(ctx context.Context, id int64, newParentID *int64) error
+	Move(ctx context.Context, id int64, req *models.MoveObjectTypeRequest) (*models.ObjectType, error)
 	GetSubtreeObjectCount(ctx context.Context, id int64) (int64, error)
+	GetEffectiveMetadataSchema(ctx context.Context, id int64) (json.RawMessage, error)
 }
 
 // ObjectTypeHandler handles HTTP requests for object types
@@ -59,11 +64,25 @@ func NewObjectTypeHandlerWithInterface(service ObjectTypeServiceInterface, logge
 	}
 }
 
+// SetAuditSink persists the handler's audit events to sink
+func (h *ObjectTypeHandler) SetAuditSink(sink logging.AuditSink) {
+	h.auditLogger.SetSink(sink)
+}
+
 func (h *ObjectTypeHandler) handleServiceError(c *gin.Context, err error, operation string, requestID string) {
 	h.logger.WithFields(logrus.Fields{
 		"request_id": requestID,
 	}).WithError(err).Error(operation)
 
+	if writeMetadataSchemaError(c, err, requestID) {
+		return
+	}
+
+	if errors.Is(err, repository.ErrOptimisticLock) {
+		writePreconditionFailed(c, requestID)
+		return
+	}
+
 	switch err {
 	case nil:
 		return
@@ -144,6 +163,7 @@ func (h *ObjectTypeHandler) GetByID(c *gin.Context) {
 		"object_type_id": objectType.ID,
 	}).Debug("Object type retrieved successfully")
 
+	c.Header("ETag", objectType.ETag())
 	c.JSON(http.StatusOK, gin.H{
 		"data":    objectType,
 		"meta":    gin.H{"request_id": requestID},
@@ -179,6 +199,7 @@ func (h *ObjectTypeHandler) GetByName(c *gin.Context) {
 		"object_type_name": objectType.Name,
 	}).Debug("Object type retrieved by name successfully")
 
+	c.Header("ETag", objectType.ETag())
 	c.JSON(http.StatusOK, gin.H{
 		"data":    objectType,
 		"meta":    gin.H{"request_id": requestID},
@@ -217,11 +238,16 @@ func (h *ObjectTypeHandler) Update(c *gin.Context) {
 		return
 	}
 
+	ctx, ok := h.checkIfMatch(c, id, requestID)
+	if !ok {
+		return
+	}
+
 	// Set updated_by from authenticated user
 	userID := middleware.GetAuthenticatedUserID(c)
 	req.UpdatedBy = userID
 
-	objectType, err := h.service.Update(c.Request.Context(), id, &req)
+	objectType, err := h.service.Update(ctx, id, &req)
 	if err != nil {
 		h.handleServiceError(c, err, "Failed to update object type", requestID)
 		return
@@ -232,6 +258,7 @@ func (h *ObjectTypeHandler) Update(c *gin.Context) {
 		"object_type_id": objectType.ID,
 	}).Info("Object type updated successfully")
 
+	c.Header("ETag", objectType.ETag())
 	c.JSON(http.StatusOK, gin.H{
 		"data":    objectType,
 		"message": "Object type updated successfully",
@@ -258,7 +285,12 @@ func (h *ObjectTypeHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err = h.service.Delete(c.Request.Context(), id)
+	ctx, ok := h.checkIfMatch(c, id, requestID)
+	if !ok {
+		return
+	}
+
+	err = h.service.Delete(ctx, id)
 	if err != nil {
 		h.handleServiceError(c, err, "Failed to delete object type", requestID)
 		return
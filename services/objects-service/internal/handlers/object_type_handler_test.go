@@ -118,11 +118,27 @@ func (m *MockObjectTypeService) ValidateMove(ctx context.Context, typeID int64,
 	return args.Error(0)
 }
 
+func (m *MockObjectTypeService) Move(ctx context.Context, id int64, req *models.MoveObjectTypeRequest) (*models.ObjectType, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ObjectType), args.Error(1)
+}
+
 func (m *MockObjectTypeService) GetSubtreeObjectCount(ctx context.Context, typeID int64) (int64, error) {
 	args := m.Called(ctx, typeID)
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockObjectTypeService) GetEffectiveMetadataSchema(ctx context.Context, id int64) (json.RawMessage, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(json.RawMessage), args.Error(1)
+}
+
 func createTestLogger() *logrus.Logger {
 	logger := logrus.New()
 	logger.SetLevel(logrus.FatalLevel)
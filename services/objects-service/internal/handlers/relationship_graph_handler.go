@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+)
+
+// Traverse follows relationships from an object and returns the objects reached with the
+// relationships between them
+func (h *RelationshipHandler) Traverse(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	objectPublicID, ok := h.parseGraphPublicID(c, "public_id", requestID)
+	if !ok {
+		return
+	}
+	query, ok := h.bindGraphQuery(c, requestID)
+	if !ok {
+		return
+	}
+
+	graph, err := h.service.Traverse(c.Request.Context(), objectPublicID, query)
+	if err != nil {
+		h.handleError(c, requestID, err, "traverse relationships")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"object_id":  objectPublicID.String(),
+		"nodes":      len(graph.Nodes),
+		"edges":      len(graph.Edges),
+	}).Info("Relationships traversed successfully")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": graph,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// ShortestPath returns a path with the fewest hops from one object to another
+func (h *RelationshipHandler) ShortestPath(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	sourcePublicID, ok := h.parseGraphPublicID(c, "public_id", requestID)
+	if !ok {
+		return
+	}
+	targetPublicID, ok := h.parseGraphPublicID(c, "target_public_id", requestID)
+	if !ok {
+		return
+	}
+	query, ok := h.bindGraphQuery(c, requestID)
+	if !ok {
+		return
+	}
+
+	path, err := h.service.ShortestPath(c.Request.Context(), sourcePublicID, targetPublicID, query)
+	if err != nil {
+		h.handleError(c, requestID, err, "find shortest path")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": path,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// Reachable returns every object reachable from an object through relationships of one type
+func (h *RelationshipHandler) Reachable(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	objectPublicID, ok := h.parseGraphPublicID(c, "public_id", requestID)
+	if !ok {
+		return
+	}
+	query, ok := h.bindGraphQuery(c, requestID)
+	if !ok {
+		return
+	}
+
+	nodes, truncated, err := h.service.Reachable(c.Request.Context(), objectPublicID, c.Param("type_key"), query)
+	if err != nil {
+		h.handleError(c, requestID, err, "get reachable objects")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": nodes,
+		"meta": gin.H{
+			"request_id": requestID,
+			"count":      len(nodes),
+			"truncated":  truncated,
+		},
+	})
+}
+
+func (h *RelationshipHandler) parseGraphPublicID(c *gin.Context, param, requestID string) (uuid.UUID, bool) {
+	publicID, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid public ID format",
+			"type":  "validation_error",
+			"field": param,
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return uuid.Nil, false
+	}
+	return publicID, true
+}
+
+func (h *RelationshipHandler) bindGraphQuery(c *gin.Context, requestID string) (*models.GraphQuery, bool) {
+	var query models.GraphQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+		}).WithError(err).Error("Invalid query parameters")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return nil, false
+	}
+	return &query, true
+}
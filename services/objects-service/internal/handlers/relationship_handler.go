@@ -9,6 +9,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/v-egorov/service-boilerplate/common/logging"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
 )
 
@@ -85,6 +86,7 @@ func (h *RelationshipHandler) GetByPublicID(c *gin.Context) {
 		"relationship_id": rel.ObjectID,
 	}).Info("Relationship retrieved successfully")
 
+	c.Header("ETag", rel.ETag())
 	c.JSON(http.StatusOK, gin.H{
 		"data":    rel.ToResponse(),
 		"meta":    gin.H{"request_id": requestID},
@@ -119,7 +121,12 @@ func (h *RelationshipHandler) Update(c *gin.Context) {
 		return
 	}
 
-	rel, err := h.service.Update(c.Request.Context(), publicID, &req)
+	ctx, ok := h.checkIfMatch(c, publicID, requestID)
+	if !ok {
+		return
+	}
+
+	rel, err := h.service.Update(ctx, publicID, &req)
 	if err != nil {
 		h.handleError(c, requestID, err, "update relationship")
 		return
@@ -130,6 +137,7 @@ func (h *RelationshipHandler) Update(c *gin.Context) {
 		"relationship_id": rel.ObjectID,
 	}).Info("Relationship updated successfully")
 
+	c.Header("ETag", rel.ETag())
 	c.JSON(http.StatusOK, gin.H{
 		"data":    rel.ToResponse(),
 		"meta":    gin.H{"request_id": requestID},
@@ -151,7 +159,12 @@ func (h *RelationshipHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	err = h.service.Delete(c.Request.Context(), publicID)
+	ctx, ok := h.checkIfMatch(c, publicID, requestID)
+	if !ok {
+		return
+	}
+
+	err = h.service.Delete(ctx, publicID)
 	if err != nil {
 		h.handleError(c, requestID, err, "delete relationship")
 		return
@@ -319,6 +332,10 @@ func (h *RelationshipHandler) handleError(c *gin.Context, requestID string, err
 		"operation":  operation,
 	}).WithError(err).Error("Relationship operation failed")
 
+	if writeRelationshipRulesError(c, err, requestID) {
+		return
+	}
+
 	statusCode := http.StatusInternalServerError
 	errorMessage := "Internal server error"
 	errorType := "internal_error"
@@ -356,6 +373,18 @@ func (h *RelationshipHandler) handleError(c *gin.Context, requestID string, err
 		statusCode = http.StatusBadRequest
 		errorMessage = "Source and target cannot be the same"
 		errorType = "validation_error"
+	case errors.Is(err, services.ErrNoPath):
+		statusCode = http.StatusNotFound
+		errorMessage = "No path found between the objects"
+		errorType = "not_found"
+	case errors.Is(err, repository.ErrInvalidInput):
+		statusCode = http.StatusBadRequest
+		errorMessage = err.Error()
+		errorType = "validation_error"
+	case errors.Is(err, repository.ErrOptimisticLock):
+		statusCode = http.StatusPreconditionFailed
+		errorMessage = "Precondition failed - the resource has been modified since it was read"
+		errorType = "precondition_failed"
 	}
 
 	c.JSON(statusCode, gin.H{
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+)
+
+// writeRelationshipRulesError answers validation rule failures and reports whether it did: a
+// relationship breaking its type's rules gets a 422, malformed rules a 400
+func writeRelationshipRulesError(c *gin.Context, err error, requestID string) bool {
+	var rulesErr *services.RelationshipRulesError
+	if errors.As(err, &rulesErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Relationship does not satisfy the validation rules of its type",
+			"type":   "validation_error",
+			"errors": rulesErr.Errors,
+			"meta":   gin.H{"request_id": requestID},
+		})
+		return true
+	}
+
+	if errors.Is(err, services.ErrInvalidValidationRules) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"type":  "validation_error",
+			"field": "validation_rules",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return true
+	}
+
+	return false
+}
+
+// ValidateRules checks existing relationships of a type against its validation rules. The
+// optional body carries candidate validation_rules to try before saving them.
+func (h *RelationshipHandler) ValidateRules(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+	typeKey := c.Param("type_key")
+
+	var req models.ValidateRelationshipRulesRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.WithFields(logrus.Fields{
+				"request_id": requestID,
+			}).WithError(err).Error("Invalid request body")
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: failed to parse request body",
+				"type":  "validation_error",
+				"meta":  gin.H{"request_id": requestID},
+			})
+			return
+		}
+	}
+
+	report, err := h.service.ValidateExistingRules(c.Request.Context(), typeKey, req.ValidationRules)
+	if err != nil {
+		h.handleError(c, requestID, err, "validate relationship rules")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"type_key":   typeKey,
+		"checked":    report.Checked,
+		"invalid":    report.Invalid,
+	}).Info("Relationships validated against type rules")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": report,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
@@ -251,6 +251,10 @@ func (h *RelationshipTypeHandler) handleError(c *gin.Context, requestID string,
 		"request_id": requestID,
 	}).WithError(err).Error(operation)
 
+	if writeRelationshipRulesError(c, err, requestID) {
+		return
+	}
+
 	// Check for specific error types
 	switch {
 	case errors.Is(err, services.ErrRelationshipTypeNotFound):
@@ -273,7 +277,7 @@ func (h *RelationshipTypeHandler) handleError(c *gin.Context, requestID string,
 		})
 	case errors.Is(err, services.ErrInvalidReverseType):
 		c.JSON(http.StatusUnprocessableEntity, gin.H{
-			"error": "Invalid reverse type key",
+			"error": err.Error(),
 			"type":  "validation_error",
 			"meta":  gin.H{"request_id": requestID},
 		})
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// maxTaxonomyBundleSize limits the size of an imported bundle
+const maxTaxonomyBundleSize = 8 << 20
+
+// TaxonomyHandler exports and imports taxonomy bundles
+type TaxonomyHandler struct {
+	service services.TaxonomyService
+	logger  *logrus.Logger
+}
+
+func NewTaxonomyHandler(service services.TaxonomyService, logger *logrus.Logger) *TaxonomyHandler {
+	return &TaxonomyHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Export handles GET /api/v1/taxonomy/export?format=json|yaml. The bundle is the response body
+// itself, so it can be imported as it is.
+func (h *TaxonomyHandler) Export(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "yaml" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid format: must be json or yaml",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	bundle, err := h.service.Export(c.Request.Context())
+	if err != nil {
+		h.handleError(c, requestID, err, "export taxonomy")
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=taxonomy."+format)
+	if format == "json" {
+		c.JSON(http.StatusOK, bundle)
+		return
+	}
+
+	body, err := yaml.Marshal(bundle)
+	if err != nil {
+		h.handleError(c, requestID, err, "export taxonomy")
+		return
+	}
+	c.Data(http.StatusOK, "application/yaml", body)
+}
+
+// Import handles POST /api/v1/taxonomy/import?dry_run=&prune=. The body is a bundle, read as YAML
+// when the content type says so and as JSON otherwise. The response is the plan, applied unless
+// dry_run is set.
+func (h *TaxonomyHandler) Import(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	var opts models.TaxonomyImportOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters: dry_run and prune must be booleans",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+	opts.Actor = middleware.GetAuthenticatedUserID(c)
+
+	bundle, err := decodeTaxonomyBundle(c)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+		}).WithError(err).Error("Invalid taxonomy bundle")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid taxonomy bundle: " + err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	plan, err := h.service.Import(c.Request.Context(), bundle, opts)
+	if err != nil {
+		h.handleError(c, requestID, err, "import taxonomy")
+		return
+	}
+
+	if plan.Applied {
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"summary":    plan.Summary,
+			"prune":      opts.Prune,
+		}).Info("Taxonomy bundle imported")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": plan,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// decodeTaxonomyBundle reads the request body as a bundle, rejecting unknown fields
+func decodeTaxonomyBundle(c *gin.Context) (*models.TaxonomyBundle, error) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxTaxonomyBundleSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxTaxonomyBundleSize {
+		return nil, errors.New("bundle is too large")
+	}
+
+	var bundle models.TaxonomyBundle
+	if strings.Contains(c.ContentType(), "yaml") {
+		decoder := yaml.NewDecoder(bytes.NewReader(body))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&bundle); err != nil {
+			return nil, err
+		}
+		return &bundle, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&bundle); err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+func (h *TaxonomyHandler) handleError(c *gin.Context, requestID string, err error, operation string) {
+	if writeMetadataSchemaError(c, err, requestID) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrInvalidCardinality),
+		errors.Is(err, services.ErrInvalidReverseType),
+		errors.Is(err, services.ErrInvalidCountConstraint),
+		errors.Is(err, services.ErrInvalidValidationRules):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, services.ErrRelationshipTypeInUse):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"type":  "conflict",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	default:
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+		}).WithError(err).Error("Failed to " + operation)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to " + operation,
+			"type":  "internal_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/transfer"
+)
+
+// TransferHandler streams objects and relationships out of the service and back in
+type TransferHandler struct {
+	service services.TransferService
+	logger  *logrus.Logger
+}
+
+func NewTransferHandler(service services.TransferService, logger *logrus.Logger) *TransferHandler {
+	return &TransferHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// ExportObjects handles GET /api/v1/objects/export?format=ndjson|csv with the filters of List.
+// Objects are streamed in ID order, however many there are.
+func (h *TransferHandler) ExportObjects(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	var filter models.ObjectFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters: " + err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", transfer.FormatNDJSON)
+	out := &exportResponse{c: c, format: format, filename: "objects"}
+	err := h.service.ExportObjects(c.Request.Context(), &filter, out, format)
+	h.finishExport(c, out, err, requestID, "export objects")
+}
+
+// ExportRelationships handles GET /api/v1/relationships/export?format=ndjson|csv&type_key=&status=
+func (h *TransferHandler) ExportRelationships(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	var filter models.RelationshipExportFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters: " + err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", transfer.FormatNDJSON)
+	out := &exportResponse{c: c, format: format, filename: "relationships"}
+	err := h.service.ExportRelationships(c.Request.Context(), &filter, out, format)
+	h.finishExport(c, out, err, requestID, "export relationships")
+}
+
+// finishExport answers an export that failed before anything was streamed with an error. Once
+// records have been sent the status can no longer change, so the failure is only logged and the
+// stream ends early.
+func (h *TransferHandler) finishExport(c *gin.Context, out *exportResponse, err error, requestID, operation string) {
+	if err == nil {
+		out.start()
+		return
+	}
+	if !out.started {
+		h.handleError(c, requestID, err, operation)
+		return
+	}
+	h.logger.WithFields(logrus.Fields{
+		"request_id": requestID,
+	}).WithError(err).Error("Failed to " + operation + " after streaming began")
+}
+
+// exportResponse writes an export to the response, sending the headers with the first bytes
+type exportResponse struct {
+	c        *gin.Context
+	format   string
+	filename string
+	started  bool
+}
+
+func (w *exportResponse) start() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.c.Header("Content-Type", transfer.ContentType(w.format))
+	w.c.Header("Content-Disposition", "attachment; filename="+w.filename+"."+w.format)
+	w.c.Status(http.StatusOK)
+	w.c.Writer.WriteHeaderNow()
+}
+
+func (w *exportResponse) Write(p []byte) (int, error) {
+	w.start()
+	n, err := w.c.Writer.Write(p)
+	w.c.Writer.Flush()
+	return n, err
+}
+
+// ImportObjects handles POST /api/v1/objects/import?format=ndjson|csv&upsert=. The body is the
+// stream of records; the response counts what was loaded and lists the records that failed.
+func (h *TransferHandler) ImportObjects(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	opts, ok := h.importOptions(c, requestID)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ImportObjects(c.Request.Context(), c.Request.Body, opts)
+	if err != nil {
+		h.handleError(c, requestID, err, "import objects")
+		return
+	}
+	h.logImport(requestID, "Objects imported", result)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// ImportRelationships handles POST /api/v1/relationships/import?format=ndjson|csv&upsert=
+func (h *TransferHandler) ImportRelationships(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	opts, ok := h.importOptions(c, requestID)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ImportRelationships(c.Request.Context(), c.Request.Body, opts)
+	if err != nil {
+		h.handleError(c, requestID, err, "import relationships")
+		return
+	}
+	h.logImport(requestID, "Relationships imported", result)
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": result,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// importOptions reads the options of an import. Without a format parameter a text/csv body is
+// read as CSV and anything else as NDJSON.
+func (h *TransferHandler) importOptions(c *gin.Context, requestID string) (*models.TransferImportOptions, bool) {
+	var opts models.TransferImportOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters: upsert must be a boolean",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return nil, false
+	}
+	if opts.Format == "" {
+		opts.Format = transfer.FormatNDJSON
+		if strings.Contains(c.ContentType(), "csv") {
+			opts.Format = transfer.FormatCSV
+		}
+	}
+	opts.Actor = middleware.GetAuthenticatedUserID(c)
+	return &opts, true
+}
+
+func (h *TransferHandler) logImport(requestID, message string, result *models.TransferImportResult) {
+	h.logger.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"records":    result.Records,
+		"created":    result.Created,
+		"updated":    result.Updated,
+		"failed":     result.Failed,
+		"aborted":    result.Aborted,
+	}).Info(message)
+}
+
+func (h *TransferHandler) handleError(c *gin.Context, requestID string, err error, operation string) {
+	if writeFilterError(c, err, requestID) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, repository.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	default:
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+		}).WithError(err).Error("Failed to " + operation)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to " + operation,
+			"type":  "internal_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	}
+}
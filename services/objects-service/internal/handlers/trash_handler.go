@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+)
+
+// writeTrashError answers failed trash operations whose cause the generic error handlers do not
+// map, and reports whether it did
+func writeTrashError(c *gin.Context, err error, requestID string) bool {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"type":  "not_found",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, services.ErrObjectNotDeleted):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Object is not in the trash",
+			"type":  "conflict",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, services.ErrObjectDeleted):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "The parent object is deleted; restore it first",
+			"type":  "conflict",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, services.ErrObjectHasLiveChild):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"type":  "conflict",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	default:
+		return false
+	}
+	return true
+}
+
+// trashAvailable answers 503 when the handler has no trash service, and reports whether it has
+func (h *ObjectHandler) trashAvailable(c *gin.Context, requestID string) bool {
+	if h.trashService != nil {
+		return true
+	}
+	c.JSON(http.StatusServiceUnavailable, gin.H{
+		"error": "The trash bin is not available",
+		"type":  "internal_error",
+		"meta":  gin.H{"request_id": requestID},
+	})
+	return false
+}
+
+// getTrashedObject reads the object in the trash named by the id path parameter and checks that
+// the caller may act on it, answering the request itself when it fails
+func (h *ObjectHandler) getTrashedObject(c *gin.Context, requestID string) (*models.Object, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid id format: id must be a positive integer",
+			"type":  "validation_error",
+			"field": "id",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return nil, false
+	}
+
+	object, err := h.trashService.Get(c.Request.Context(), id)
+	if err != nil {
+		if !writeTrashError(c, err, requestID) {
+			h.handleServiceError(c, err, "Failed to get deleted object", requestID)
+		}
+		return nil, false
+	}
+
+	if !h.checkOwnership(c, object, "objects:delete:all") {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "You can only restore or purge objects you own",
+			"type":  "permission_denied",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return nil, false
+	}
+	return object, true
+}
+
+// ListTrash handles GET /api/v1/objects/trash, listing deleted objects most recently deleted
+// first. Callers without objects:delete:all see the objects they created only.
+func (h *ObjectHandler) ListTrash(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	if !h.trashAvailable(c, requestID) {
+		return
+	}
+
+	var filter models.TrashFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters: " + err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if !hasAllPermission(c, "objects:delete:all") {
+		filter.CreatedBy = middleware.GetAuthenticatedUserID(c)
+		if filter.CreatedBy == "" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Authentication is required to list deleted objects",
+				"type":  "permission_denied",
+				"meta":  gin.H{"request_id": requestID},
+			})
+			return
+		}
+	}
+
+	objects, total, err := h.trashService.List(c.Request.Context(), &filter)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to list deleted objects", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": objects,
+		"pagination": gin.H{
+			"limit":  filter.Limit,
+			"offset": filter.Offset,
+			"total":  total,
+			"count":  len(objects),
+		},
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// RestoreFromTrash handles POST /api/v1/objects/:id/restore. include_descendants also restores
+// the descendants deleted below the object, include_relationships the relationships deleted
+// with the restored objects.
+func (h *ObjectHandler) RestoreFromTrash(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	if !h.trashAvailable(c, requestID) {
+		return
+	}
+
+	object, ok := h.getTrashedObject(c, requestID)
+	if !ok {
+		return
+	}
+
+	var req models.RestoreObjectRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format: failed to parse request body",
+				"type":  "validation_error",
+				"meta":  gin.H{"request_id": requestID},
+			})
+			return
+		}
+	}
+	req.UpdatedBy = middleware.GetAuthenticatedUserID(c)
+
+	result, err := h.trashService.Restore(c.Request.Context(), object.ID, &req)
+	if err != nil {
+		if !writeTrashError(c, err, requestID) {
+			h.handleServiceError(c, err, "Failed to restore object", requestID)
+		}
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"object_id":     object.ID,
+		"request_id":    requestID,
+		"descendants":   len(result.Descendants),
+		"relationships": len(result.Relationships),
+	}).Info("Object restored from trash")
+
+	c.Header("ETag", result.Object.ETag())
+	c.JSON(http.StatusOK, gin.H{
+		"data":    result,
+		"message": "Object restored successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+// Purge handles DELETE /api/v1/objects/:id/purge, removing a deleted object, its deleted
+// descendants and their relationships for good
+func (h *ObjectHandler) Purge(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	if !h.trashAvailable(c, requestID) {
+		return
+	}
+
+	object, ok := h.getTrashedObject(c, requestID)
+	if !ok {
+		return
+	}
+
+	result, err := h.trashService.Purge(c.Request.Context(), object.ID)
+	if err != nil {
+		if !writeTrashError(c, err, requestID) {
+			h.handleServiceError(c, err, "Failed to purge object", requestID)
+		}
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"object_id":  object.ID,
+		"request_id": requestID,
+		"purged":     len(result.Purged),
+	}).Info("Object purged from trash")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    result,
+		"message": "Object purged successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
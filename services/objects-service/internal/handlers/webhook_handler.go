@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/services"
+)
+
+// WebhookHandler manages webhook subscriptions and their delivery logs
+type WebhookHandler struct {
+	service services.WebhookService
+	logger  *logrus.Logger
+}
+
+func NewWebhookHandler(service services.WebhookService, logger *logrus.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Create handles POST /api/v1/webhooks. The response is the only one to carry the secret.
+func (h *WebhookHandler) Create(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	var req models.CreateWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.invalidBody(c, requestID, err)
+		return
+	}
+	req.CreatedBy = middleware.GetAuthenticatedUserID(c)
+
+	sub, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, requestID, err, "create webhook subscription")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id":      requestID,
+		"subscription_id": sub.PublicID,
+		"target_url":      sub.TargetURL,
+	}).Info("Webhook subscription created")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data": sub,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// List handles GET /api/v1/webhooks
+func (h *WebhookHandler) List(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	subs, err := h.service.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, requestID, err, "list webhook subscriptions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": subs,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// Get handles GET /api/v1/webhooks/:id
+func (h *WebhookHandler) Get(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	publicID, ok := h.uuidParam(c, requestID, "id")
+	if !ok {
+		return
+	}
+
+	sub, err := h.service.Get(c.Request.Context(), publicID)
+	if err != nil {
+		h.handleError(c, requestID, err, "get webhook subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": sub,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// Update handles PUT /api/v1/webhooks/:id
+func (h *WebhookHandler) Update(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	publicID, ok := h.uuidParam(c, requestID, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateWebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.invalidBody(c, requestID, err)
+		return
+	}
+	req.UpdatedBy = middleware.GetAuthenticatedUserID(c)
+
+	sub, err := h.service.Update(c.Request.Context(), publicID, &req)
+	if err != nil {
+		h.handleError(c, requestID, err, "update webhook subscription")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id":      requestID,
+		"subscription_id": sub.PublicID,
+	}).Info("Webhook subscription updated")
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": sub,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// Delete handles DELETE /api/v1/webhooks/:id
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	publicID, ok := h.uuidParam(c, requestID, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), publicID); err != nil {
+		h.handleError(c, requestID, err, "delete webhook subscription")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id":      requestID,
+		"subscription_id": publicID,
+	}).Info("Webhook subscription deleted")
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries handles GET /api/v1/webhooks/:id/deliveries?status=&limit=&offset=
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	publicID, ok := h.uuidParam(c, requestID, "id")
+	if !ok {
+		return
+	}
+
+	var filter models.WebhookDeliveryFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid query parameters: limit and offset must be integers",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	deliveries, total, err := h.service.ListDeliveries(c.Request.Context(), publicID, &filter)
+	if err != nil {
+		h.handleError(c, requestID, err, "list webhook deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": deliveries,
+		"pagination": gin.H{
+			"limit":  filter.Limit,
+			"offset": filter.Offset,
+			"total":  total,
+			"count":  len(deliveries),
+		},
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// ReplayDelivery handles POST /api/v1/webhooks/:id/deliveries/:delivery_id/replay
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	publicID, ok := h.uuidParam(c, requestID, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := h.uuidParam(c, requestID, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.ReplayDelivery(c.Request.Context(), publicID, deliveryID)
+	if err != nil {
+		h.handleError(c, requestID, err, "replay webhook delivery")
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id":      requestID,
+		"subscription_id": publicID,
+		"delivery_id":     deliveryID,
+	}).Info("Webhook delivery scheduled for replay")
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"data": delivery,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+func (h *WebhookHandler) uuidParam(c *gin.Context, requestID, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid " + name + " format",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *WebhookHandler) invalidBody(c *gin.Context, requestID string, err error) {
+	h.logger.WithFields(logrus.Fields{
+		"request_id": requestID,
+	}).WithError(err).Error("Invalid request body")
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": "Invalid request format",
+		"type":  "validation_error",
+		"meta":  gin.H{"request_id": requestID},
+	})
+}
+
+func (h *WebhookHandler) handleError(c *gin.Context, requestID string, err error, operation string) {
+	switch {
+	case errors.Is(err, services.ErrWebhookNotFound), errors.Is(err, services.ErrWebhookDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+			"type":  "not_found",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, services.ErrWebhookDeliveryNotReplayable):
+		c.JSON(http.StatusConflict, gin.H{
+			"error": err.Error(),
+			"type":  "conflict",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	case errors.Is(err, repository.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	default:
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+		}).WithError(err).Error("Failed to " + operation)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to " + operation,
+			"type":  "internal_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package models
+
+// Dimensions objects can be grouped by. Metadata values are grouped with "metadata.<path>", as in
+// metadata.region.
+const (
+	DimensionObjectType = "object_type"
+	DimensionStatus     = "status"
+	DimensionTag        = "tag"
+)
+
+// Metrics computed per bucket over a numeric metadata value
+const (
+	MetricSum = "sum"
+	MetricAvg = "avg"
+	MetricMin = "min"
+	MetricMax = "max"
+)
+
+// Intervals of a date histogram
+const (
+	IntervalHour  = "hour"
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+	IntervalYear  = "year"
+)
+
+// ObjectAggregationRequest groups the objects selected by Filter into buckets and counts them.
+// Objects with several tags count once per tag when grouped by tag.
+type ObjectAggregationRequest struct {
+	Filter        ObjectFilter        `json:"filter"`
+	GroupBy       []string            `json:"group_by,omitempty"`       // object_type, status, tag or metadata.<path>
+	Metrics       []AggregationMetric `json:"metrics,omitempty"`        // computed per bucket besides the count
+	DateHistogram *DateHistogram      `json:"date_histogram,omitempty"` // also buckets by a timestamp
+	Size          int                 `json:"size,omitempty"`           // most buckets returned
+}
+
+// AggregationMetric is a numeric aggregate over a metadata path; values that are not numbers
+// are ignored
+type AggregationMetric struct {
+	Op    string `json:"op"`    // sum, avg, min or max
+	Field string `json:"field"` // metadata.<path>
+}
+
+// Name is the key of the metric in the metrics of a bucket, as in "sum(metadata.amount)"
+func (m AggregationMetric) Name() string {
+	return m.Op + "(" + m.Field + ")"
+}
+
+// DateHistogram buckets objects by a timestamp truncated to an interval
+type DateHistogram struct {
+	Field    string `json:"field"`    // created_at or updated_at
+	Interval string `json:"interval"` // hour, day, week, month or year
+}
+
+// AggregationBucket is one combination of dimension values. Key maps each dimension, and
+// the histogram field, to its value; objects without a value are grouped under null.
+type AggregationBucket struct {
+	Key     map[string]interface{} `json:"key"`
+	Count   int64                  `json:"count"`
+	Metrics map[string]*float64    `json:"metrics,omitempty"`
+}
+
+// ObjectAggregationResult holds the buckets of an aggregation, largest first, or in time order
+// for a date histogram. Total counts the objects the filter selected.
+type ObjectAggregationResult struct {
+	Buckets   []*AggregationBucket `json:"buckets"`
+	Total     int64                `json:"total"`
+	Truncated bool                 `json:"truncated"` // more buckets than the size requested
+}
+
+// FacetCount is the number of search hits sharing a value of a facet
+type FacetCount struct {
+	Value interface{} `json:"value"`
+	Count int64       `json:"count"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "github.com/google/uuid"
+
+// CloneObjectRequest copies an object, and optionally its descendants, as new objects
+type CloneObjectRequest struct {
+	// ParentObjectID is the parent of the copy; without it the copy is placed next to the original
+	ParentObjectID *int64 `json:"parent_object_id,omitempty" validate:"omitempty,gt=0"`
+	// Name renames the copy of the object; its descendants keep their names
+	Name                      *string `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	IncludeDescendants        bool    `json:"include_descendants,omitempty"`
+	KeepExternalRelationships bool    `json:"keep_external_relationships,omitempty"` // also copy links to objects outside the clone
+	CreatedBy                 string  `json:"-"`
+}
+
+// CloneMapping pairs an original entity with its copy
+type CloneMapping struct {
+	OldID       int64     `json:"old_id"`
+	OldPublicID uuid.UUID `json:"old_public_id"`
+	NewID       int64     `json:"new_id"`
+	NewPublicID uuid.UUID `json:"new_public_id"`
+}
+
+// CloneObjectResult is the copy of the cloned object with the mapping of every copied object and
+// relationship to its original
+type CloneObjectResult struct {
+	Object        *Object        `json:"object"`
+	Objects       []CloneMapping `json:"objects"`
+	Relationships []CloneMapping `json:"relationships"`
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attribute data types and the column types they are stored as
+var attributeColumnTypes = map[string]string{
+	"text":      "TEXT",
+	"integer":   "INTEGER",
+	"bigint":    "BIGINT",
+	"numeric":   "NUMERIC",
+	"double":    "DOUBLE PRECISION",
+	"boolean":   "BOOLEAN",
+	"date":      "DATE",
+	"timestamp": "TIMESTAMPTZ",
+	"uuid":      "UUID",
+	"jsonb":     "JSONB",
+}
+
+// sqlIdentifierPattern matches the lowercase identifiers allowed for concrete tables and columns.
+// They never need quoting and fit PostgreSQL's 63 byte limit.
+var sqlIdentifierPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// ObjectTypeAttribute is a typed attribute declared by an object type. Its values are stored in a
+// column of the type's concrete table.
+type ObjectTypeAttribute struct {
+	ID           int64     `json:"id" db:"id"`
+	ObjectTypeID int64     `json:"object_type_id" db:"object_type_id"`
+	Name         string    `json:"name" db:"name"`
+	DataType     string    `json:"data_type" db:"data_type"`
+	Nullable     bool      `json:"nullable" db:"nullable"`
+	Indexed      bool      `json:"indexed" db:"indexed"`
+	Position     int       `json:"position" db:"position"`
+	CreatedBy    string    `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// ColumnType returns the SQL type of the attribute's column
+func (a *ObjectTypeAttribute) ColumnType() string {
+	return attributeColumnTypes[a.DataType]
+}
+
+// ConcreteTable is the concrete table of one object type together with the attributes stored in it
+type ConcreteTable struct {
+	ObjectTypeID int64                  `json:"object_type_id"`
+	TableName    string                 `json:"table_name"`
+	Attributes   []*ObjectTypeAttribute `json:"attributes"`
+}
+
+// AttributeDeclaration declares a new attribute; attributes are nullable unless stated otherwise
+type AttributeDeclaration struct {
+	Name     string `json:"name" binding:"required"`
+	DataType string `json:"data_type" binding:"required"`
+	Nullable *bool  `json:"nullable,omitempty"`
+	Indexed  bool   `json:"indexed"`
+}
+
+// IsNullable reports whether the declared attribute accepts null
+func (d *AttributeDeclaration) IsNullable() bool {
+	return d.Nullable == nil || *d.Nullable
+}
+
+// AddObjectTypeAttributesRequest adds attributes to an object type
+type AddObjectTypeAttributesRequest struct {
+	Attributes []AttributeDeclaration `json:"attributes" binding:"required"`
+	CreatedBy  string                 `json:"-"`
+}
+
+// ConcreteTablePlan lists the DDL statements provisioning an object type's concrete table
+type ConcreteTablePlan struct {
+	ObjectTypeID int64                  `json:"object_type_id"`
+	TableName    string                 `json:"table_name"`
+	Attributes   []*ObjectTypeAttribute `json:"attributes"`
+	Statements   []string               `json:"statements"`
+	Applied      bool                   `json:"applied"`
+}
+
+// IsValidAttributeDataType reports whether dataType is a supported attribute data type
+func IsValidAttributeDataType(dataType string) bool {
+	_, ok := attributeColumnTypes[dataType]
+	return ok
+}
+
+// IsValidSQLIdentifier reports whether name can be used as a concrete table or column name
+func IsValidSQLIdentifier(name string) bool {
+	return sqlIdentifierPattern.MatchString(name)
+}
+
+// DefaultConcreteTableName derives a concrete table name from an object type name, "" when the
+// name does not yield a valid identifier
+func DefaultConcreteTableName(typeName string) string {
+	var b strings.Builder
+	b.WriteString("objects_")
+	underscore := true
+	for _, r := range strings.ToLower(typeName) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	name := strings.TrimSuffix(b.String(), "_")
+	if name == "objects" || !IsValidSQLIdentifier(name) {
+		return ""
+	}
+	return name
+}
+
+// CheckAttributeValue checks a decoded JSON value against an attribute's data type. Null is
+// accepted for nullable attributes; formats are checked the way PostgreSQL parses them.
+func CheckAttributeValue(attribute *ObjectTypeAttribute, value interface{}) error {
+	if value == nil {
+		if !attribute.Nullable {
+			return fmt.Errorf("attribute %q cannot be null", attribute.Name)
+		}
+		return nil
+	}
+
+	invalid := fmt.Errorf("attribute %q must be a %s value", attribute.Name, attribute.DataType)
+	switch attribute.DataType {
+	case "text":
+		if _, ok := value.(string); !ok {
+			return invalid
+		}
+	case "integer", "bigint":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return invalid
+		}
+		if attribute.DataType == "integer" && (number < math.MinInt32 || number > math.MaxInt32) {
+			return fmt.Errorf("attribute %q is out of range for integer", attribute.Name)
+		}
+	case "numeric", "double":
+		if _, ok := value.(float64); !ok {
+			return invalid
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return invalid
+		}
+	case "date":
+		text, ok := value.(string)
+		if !ok {
+			return invalid
+		}
+		if _, err := time.Parse(time.DateOnly, text); err != nil {
+			return fmt.Errorf("attribute %q must be a date in YYYY-MM-DD format", attribute.Name)
+		}
+	case "timestamp":
+		text, ok := value.(string)
+		if !ok {
+			return invalid
+		}
+		if _, err := time.Parse(time.RFC3339Nano, text); err != nil {
+			return fmt.Errorf("attribute %q must be an RFC 3339 timestamp", attribute.Name)
+		}
+	case "uuid":
+		text, ok := value.(string)
+		if !ok {
+			return invalid
+		}
+		if _, err := uuid.Parse(text); err != nil {
+			return invalid
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ETag returns the entity tag of the object's current state. Version alone is not enough:
+// metadata and tag updates change updated_at without bumping it.
+func (o *Object) ETag() string {
+	return fmt.Sprintf(`"v%d-%s"`, o.Version, timestampTag(o.UpdatedAt))
+}
+
+// ETag returns the entity tag of the object type's current state
+func (ot *ObjectType) ETag() string {
+	return fmt.Sprintf(`"%s"`, timestampTag(ot.UpdatedAt))
+}
+
+// ETag returns the entity tag of the relationship's current state
+func (r *Relationship) ETag() string {
+	return fmt.Sprintf(`"%s"`, timestampTag(r.UpdatedAt))
+}
+
+// timestampTag encodes t at the microsecond precision PostgreSQL stores
+func timestampTag(t time.Time) string {
+	return fmt.Sprintf("%x", t.UnixMicro())
+}
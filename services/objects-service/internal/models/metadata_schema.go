@@ -0,0 +1,50 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// MetadataFieldError is one violation of an object type's metadata schema.
+// Path is a JSON Pointer into the object's metadata; "" is the metadata object itself.
+type MetadataFieldError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ObjectMetadataViolation lists the schema violations of one existing object
+type ObjectMetadataViolation struct {
+	ObjectID     int64                `json:"object_id"`
+	Name         string               `json:"name"`
+	ObjectTypeID int64                `json:"object_type_id"`
+	Errors       []MetadataFieldError `json:"errors"`
+}
+
+// MetadataValidationReport is the result of checking existing objects against a metadata schema
+type MetadataValidationReport struct {
+	ObjectTypeID int64                     `json:"object_type_id"`
+	Checked      int                       `json:"checked"`
+	Invalid      int                       `json:"invalid"`
+	Violations   []ObjectMetadataViolation `json:"violations"`
+	Truncated    bool                      `json:"truncated"`
+}
+
+// ValidateMetadataSchemaRequest optionally carries a candidate schema to check existing objects
+// against before it is saved; without one the type's stored schema is used
+type ValidateMetadataSchemaRequest struct {
+	MetadataSchema json.RawMessage `json:"metadata_schema,omitempty"`
+}
+
+// IsEmptyMetadataSchema reports whether raw declares no constraints: absent, null or {}
+func IsEmptyMetadataSchema(raw json.RawMessage) bool {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return true
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, trimmed); err != nil {
+		return false
+	}
+	return compact.String() == "{}"
+}
@@ -0,0 +1,32 @@
+package models
+
+// Object move modes
+const (
+	MoveModeSubtree = "subtree" // the object moves together with its descendants
+	MoveModeSingle  = "single"  // the object moves alone; its children go to its former parent
+)
+
+// MoveObjectRequest moves an object under another parent, or to the root when ParentObjectID is
+// null, optionally giving it another type
+type MoveObjectRequest struct {
+	ParentObjectID    *int64 `json:"parent_object_id" validate:"omitempty,gt=0"`
+	ObjectTypeID      *int64 `json:"object_type_id,omitempty" validate:"omitempty,gt=0"`
+	Mode              string `json:"mode,omitempty" validate:"omitempty,oneof=subtree single"`
+	RetypeDescendants bool   `json:"retype_descendants,omitempty"` // give moved descendants the new type as well
+	Version           *int64 `json:"version,omitempty" validate:"omitempty,gt=0"`
+	UpdatedBy         string `json:"-"`
+}
+
+// MoveObjectResult is the moved object with the other objects the move changed
+type MoveObjectResult struct {
+	Object     *Object `json:"object"`
+	Retyped    []int64 `json:"retyped"`    // descendants given the object's new type
+	Reparented []int64 `json:"reparented"` // children handed to the object's former parent
+}
+
+// MoveObjectTypeRequest moves an object type under another parent, or to the root when
+// ParentTypeID is null
+type MoveObjectTypeRequest struct {
+	ParentTypeID *int64 `json:"parent_type_id" validate:"omitempty,gt=0"`
+	UpdatedBy    string `json:"-"`
+}
@@ -43,6 +43,9 @@ type Object struct {
 	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
 	DeletedAt      *time.Time      `json:"deleted_at,omitempty" db:"deleted_at"`
 
+	// Attributes holds the values stored in the concrete tables of the object's type
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+
 	// Eager loading relationship (only ObjectType, as planned)
 	ObjectType *ObjectType `json:"object_type,omitempty"`
 }
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// History entity types: relationships are recorded under their base object's ID
+const (
+	HistoryEntityObject       = "object"
+	HistoryEntityRelationship = "relationship"
+)
+
+// History actions
+const (
+	HistoryActionBaseline = "baseline" // state when history recording was introduced
+	HistoryActionCreate   = "create"
+	HistoryActionUpdate   = "update"
+	HistoryActionDelete   = "delete"
+	HistoryActionRestore  = "restore"
+	HistoryActionMove     = "move"
+	HistoryActionPurge    = "purge" // final state of an object removed from the trash for good
+)
+
+// historyIgnoredFields change on every write and are left out of diffs
+var historyIgnoredFields = map[string]bool{
+	"version":    true,
+	"updated_at": true,
+}
+
+// FieldChange is the old and new value of one field between two versions
+type FieldChange struct {
+	Old json.RawMessage `json:"old"`
+	New json.RawMessage `json:"new"`
+}
+
+// ObjectVersion is one recorded state of an object or relationship
+type ObjectVersion struct {
+	ID            int64                  `json:"id" db:"id"`
+	EntityType    string                 `json:"entity_type" db:"entity_type"`
+	ObjectID      int64                  `json:"object_id" db:"object_id"`
+	Revision      int64                  `json:"revision" db:"revision"`
+	ObjectVersion *int64                 `json:"object_version,omitempty" db:"object_version"`
+	Action        string                 `json:"action" db:"action"`
+	Snapshot      json.RawMessage        `json:"snapshot,omitempty" db:"snapshot"`
+	Changes       map[string]FieldChange `json:"changes" db:"changes"`
+	UpdatedBy     string                 `json:"updated_by" db:"updated_by"`
+	RequestID     *string                `json:"request_id,omitempty" db:"request_id"`
+	RecordedAt    time.Time              `json:"recorded_at" db:"recorded_at"`
+}
+
+// ToObject decodes an object snapshot
+func (v *ObjectVersion) ToObject() (*Object, error) {
+	if v.EntityType != HistoryEntityObject {
+		return nil, fmt.Errorf("version %d is a %s snapshot", v.Revision, v.EntityType)
+	}
+
+	var object Object
+	if err := json.Unmarshal(v.Snapshot, &object); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	return &object, nil
+}
+
+// ObjectVersionDiff lists the fields that differ between two versions of an object
+type ObjectVersionDiff struct {
+	ObjectID     int64                  `json:"object_id"`
+	FromRevision int64                  `json:"from_revision"`
+	ToRevision   int64                  `json:"to_revision"`
+	Changes      map[string]FieldChange `json:"changes"`
+}
+
+// ObjectVersionFilter selects and pages an object's history
+type ObjectVersionFilter struct {
+	EntityType string `form:"entity_type"`
+	Limit      int    `form:"limit"`
+	Offset     int    `form:"offset"`
+}
+
+// DiffSnapshots compares two snapshots field by field, skipping bookkeeping fields.
+// A field missing from one side is reported with a null value on that side.
+func DiffSnapshots(from, to json.RawMessage) (map[string]FieldChange, error) {
+	var before, after map[string]json.RawMessage
+	if err := json.Unmarshal(from, &before); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if err := json.Unmarshal(to, &after); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := make(map[string]FieldChange)
+	for _, key := range keys {
+		if historyIgnoredFields[key] {
+			continue
+		}
+		oldValue, newValue := normalizeJSON(before[key]), normalizeJSON(after[key])
+		if !bytes.Equal(oldValue, newValue) {
+			changes[key] = FieldChange{Old: oldValue, New: newValue}
+		}
+	}
+	return changes, nil
+}
+
+// normalizeJSON compacts a value so formatting differences are not reported as changes
+func normalizeJSON(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return raw
+	}
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return raw
+	}
+	return normalized
+}
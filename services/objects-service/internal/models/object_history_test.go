@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	from := json.RawMessage(`{"name":"Draft","tags":["a"],"metadata":{"x":1,"y":2},"version":1,"updated_at":"2024-01-01T00:00:00+00:00"}`)
+	to := json.RawMessage(`{"name":"Final","tags":["a"],"metadata":{"y":2, "x":1},"version":2,"updated_at":"2024-01-02T00:00:00+00:00","description":"new"}`)
+
+	changes, err := DiffSnapshots(from, to)
+
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+	assert.JSONEq(t, `"Draft"`, string(changes["name"].Old))
+	assert.JSONEq(t, `"Final"`, string(changes["name"].New))
+	assert.JSONEq(t, `null`, string(changes["description"].Old))
+	assert.JSONEq(t, `"new"`, string(changes["description"].New))
+}
+
+func TestDiffSnapshots_InvalidSnapshot(t *testing.T) {
+	_, err := DiffSnapshots(json.RawMessage(`[]`), json.RawMessage(`{}`))
+	assert.Error(t, err)
+}
+
+func TestObjectVersion_ToObject(t *testing.T) {
+	version := &ObjectVersion{
+		EntityType: HistoryEntityObject,
+		Revision:   2,
+		Snapshot: json.RawMessage(`{"id":7,"name":"Widget","object_type_id":3,"metadata":{"k":"v"},"tags":["t"],
+			"status":"active","version":4,"created_at":"2024-01-01T10:00:00.123456+00:00","updated_at":"2024-01-02T10:00:00+00:00"}`),
+	}
+
+	object, err := version.ToObject()
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), object.ID)
+	assert.Equal(t, "Widget", object.Name)
+	assert.Equal(t, int64(4), object.Version)
+	assert.Equal(t, map[string]interface{}{"k": "v"}, object.GetMetadataMap())
+	assert.Equal(t, 2024, object.CreatedAt.Year())
+
+	version.EntityType = HistoryEntityRelationship
+	_, err = version.ToObject()
+	assert.Error(t, err)
+}
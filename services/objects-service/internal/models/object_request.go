@@ -15,6 +15,7 @@ type CreateObjectRequest struct {
 	Description    *string                `json:"description,omitempty" validate:"max=1000"`
 	Metadata       map[string]interface{} `json:"metadata,omitempty"`
 	Tags           []string               `json:"tags,omitempty"`
+	Attributes     map[string]interface{} `json:"attributes,omitempty"`
 	CreatedBy      string                 `json:"-" db:"created_by"`
 }
 
@@ -28,6 +29,7 @@ type UpdateObjectRequest struct {
 	Tags           *[]string               `json:"tags,omitempty"`
 	Status         *string                 `json:"status,omitempty" validate:"omitempty,oneof=active inactive archived deleted pending"`
 	Version        *int64                  `json:"version,omitempty" validate:"omitempty,gt=0"`
+	Attributes     map[string]interface{}  `json:"attributes,omitempty"` // Only the given attributes are changed
 	UpdatedBy      string                  `json:"-" db:"updated_by"`
 }
 
@@ -56,6 +58,7 @@ type ObjectFilter struct {
 	HasMetadata    bool       `json:"has_metadata,omitempty" form:"has_metadata"`
 	MetadataKey    string     `json:"metadata_key,omitempty" form:"metadata_key"`
 	MetadataValue  string     `json:"metadata_value,omitempty" form:"metadata_value"`
+	Filter         string     `json:"filter,omitempty" form:"filter"`
 	Limit          int        `json:"limit,omitempty" form:"limit"`
 	Offset         int        `json:"offset,omitempty" form:"offset"`
 	SortBy         string     `json:"sort_by,omitempty" form:"sort_by"`
@@ -97,6 +100,27 @@ type PaginationResponse struct {
 	Total  int64 `json:"total,omitempty"` // Repository will populate this
 }
 
+// Reasons a bulk update skipped an object
+const (
+	BulkConflictVersionMismatch = "version_mismatch"
+	BulkConflictNotFound        = "not_found"
+	BulkConflictDeleted         = "deleted"
+)
+
+// BulkUpdateConflict describes an object a bulk update skipped because its expected version did not hold
+type BulkUpdateConflict struct {
+	ID              int64  `json:"id"`
+	ExpectedVersion int64  `json:"expected_version"`
+	CurrentVersion  *int64 `json:"current_version,omitempty"`
+	Reason          string `json:"reason"`
+}
+
+// BulkUpdateResult is the outcome of a bulk update: the objects written and the ones skipped on conflict
+type BulkUpdateResult struct {
+	Updated   []*Object            `json:"updated"`
+	Conflicts []BulkUpdateConflict `json:"conflicts"`
+}
+
 // stringPtr returns a pointer to a string
 func stringPtr(s string) *string {
 	return &s
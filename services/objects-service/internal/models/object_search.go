@@ -0,0 +1,36 @@
+package models
+
+// How a search result was matched
+const (
+	SearchMatchFullText = "fulltext"
+	SearchMatchFuzzy    = "fuzzy"
+)
+
+// ObjectSearchFilter holds the query and filters of a full-text object search
+type ObjectSearchFilter struct {
+	Query        string   `form:"q"`
+	ObjectTypeID *int64   `form:"object_type_id"` // matches the type and all of its subtypes
+	Status       string   `form:"status"`
+	Facets       []string `form:"facets"`     // dimensions to count the hits by, as for aggregations
+	FacetSize    int      `form:"facet_size"` // most values returned per facet
+	Limit        int      `form:"limit"`
+	Offset       int      `form:"offset"`
+}
+
+// ObjectSearchResult is one ranked search hit. Snippet holds the matching text with the
+// matched words wrapped in <mark> tags.
+type ObjectSearchResult struct {
+	Object    *Object `json:"object"`
+	Rank      float64 `json:"rank"`
+	Snippet   string  `json:"snippet,omitempty"`
+	MatchType string  `json:"match_type"`
+}
+
+// ObjectSearchResults is a page of search hits. Fuzzy results are only returned when the
+// full-text query matched nothing, so all hits of a page share one match type.
+type ObjectSearchResults struct {
+	Results   []*ObjectSearchResult    `json:"results"`
+	Total     int64                    `json:"total"`
+	MatchType string                   `json:"match_type"`
+	Facets    map[string][]*FacetCount `json:"facets,omitempty"` // counts over all hits, not just the page
+}
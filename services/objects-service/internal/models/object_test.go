@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -247,3 +248,19 @@ func TestObject_IsPending(t *testing.T) {
 	obj.Status = StatusActive
 	assert.False(t, obj.IsPending())
 }
+
+func TestObject_ETag(t *testing.T) {
+	updatedAt := time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC)
+	obj := &Object{Version: 3, UpdatedAt: updatedAt}
+	etag := obj.ETag()
+
+	assert.Equal(t, fmt.Sprintf(`"v3-%x"`, updatedAt.UnixMicro()), etag)
+
+	// Metadata and tag writes touch updated_at without bumping the version
+	obj.UpdatedAt = updatedAt.Add(time.Microsecond)
+	assert.NotEqual(t, etag, obj.ETag())
+
+	obj.UpdatedAt = updatedAt
+	obj.Version = 4
+	assert.NotEqual(t, etag, obj.ETag())
+}
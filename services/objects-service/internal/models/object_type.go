@@ -14,10 +14,13 @@ type ObjectType struct {
 	Description       string          `json:"description,omitempty" db:"description"`
 	IsSealed          bool            `json:"is_sealed" db:"is_sealed"`
 	Metadata          json.RawMessage `json:"metadata,omitempty" db:"metadata"`
-	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time       `json:"updated_at" db:"updated_at"`
-	CreatedBy         string          `json:"created_by" db:"created_by"`
-	UpdatedBy         string          `json:"updated_by" db:"updated_by"`
+	MetadataSchema    json.RawMessage `json:"metadata_schema,omitempty" db:"metadata_schema"`
+	// SearchMetadataKeys lists the metadata keys whose values are indexed for full-text search
+	SearchMetadataKeys []string  `json:"search_metadata_keys,omitempty" db:"search_metadata_keys"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+	CreatedBy          string    `json:"created_by" db:"created_by"`
+	UpdatedBy          string    `json:"updated_by" db:"updated_by"`
 
 	// Eager loading relationships
 	ParentType *ObjectType  `json:"parent_type,omitempty"`
@@ -50,6 +53,11 @@ func (ot *ObjectType) HasConcreteTable() bool {
 	return ot.ConcreteTableName != nil && *ot.ConcreteTableName != ""
 }
 
+// HasMetadataSchema returns true if this type declares its own metadata JSON Schema
+func (ot *ObjectType) HasMetadataSchema() bool {
+	return !IsEmptyMetadataSchema(ot.MetadataSchema)
+}
+
 // GetMetadataMap converts JSONB metadata to map[string]interface{}
 func (ot *ObjectType) GetMetadataMap() map[string]interface{} {
 	if len(ot.Metadata) == 0 {
@@ -7,35 +7,41 @@ import (
 
 // CreateObjectTypeRequest represents the request payload for creating an object type
 type CreateObjectTypeRequest struct {
-	Name              string                 `json:"name" binding:"required" validate:"required,min=1,max=255"`
-	ParentTypeID      *int64                 `json:"parent_type_id,omitempty" validate:"omitempty,gt=0"`
-	ConcreteTableName *string                `json:"concrete_table_name,omitempty" validate:"omitempty,min=1,max=255"`
-	Description       string                 `json:"description,omitempty" validate:"max=1000"`
-	IsSealed          *bool                  `json:"is_sealed,omitempty"`
-	Metadata          map[string]interface{} `json:"metadata,omitempty"`
-	CreatedBy         string                 `json:"-"`
-	UpdatedBy         string                 `json:"-"`
+	Name               string                 `json:"name" binding:"required" validate:"required,min=1,max=255"`
+	ParentTypeID       *int64                 `json:"parent_type_id,omitempty" validate:"omitempty,gt=0"`
+	ConcreteTableName  *string                `json:"concrete_table_name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description        string                 `json:"description,omitempty" validate:"max=1000"`
+	IsSealed           *bool                  `json:"is_sealed,omitempty"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty"`
+	MetadataSchema     json.RawMessage        `json:"metadata_schema,omitempty"`
+	SearchMetadataKeys []string               `json:"search_metadata_keys,omitempty"`
+	CreatedBy          string                 `json:"-"`
+	UpdatedBy          string                 `json:"-"`
 }
 
 // UpdateObjectTypeRequest represents the request payload for updating an object type
 type UpdateObjectTypeRequest struct {
-	Name              *string                 `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
-	ParentTypeID      *int64                  `json:"parent_type_id,omitempty" validate:"omitempty,gt=0"`
-	ConcreteTableName *string                 `json:"concrete_table_name,omitempty" validate:"omitempty,min=1,max=255"`
-	Description       *string                 `json:"description,omitempty" validate:"omitempty,max=1000"`
-	IsSealed          *bool                   `json:"is_sealed,omitempty"`
-	Metadata          *map[string]interface{} `json:"metadata,omitempty"`
-	UpdatedBy         string                  `json:"-"`
+	Name               *string                 `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	ParentTypeID       *int64                  `json:"parent_type_id,omitempty" validate:"omitempty,gt=0"`
+	ConcreteTableName  *string                 `json:"concrete_table_name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description        *string                 `json:"description,omitempty" validate:"omitempty,max=1000"`
+	IsSealed           *bool                   `json:"is_sealed,omitempty"`
+	Metadata           *map[string]interface{} `json:"metadata,omitempty"`
+	MetadataSchema     *json.RawMessage        `json:"metadata_schema,omitempty"`      // {} removes the schema
+	SearchMetadataKeys *[]string               `json:"search_metadata_keys,omitempty"` // [] stops indexing metadata
+	UpdatedBy          string                  `json:"-"`
 }
 
 // ReplaceObjectTypeRequest represents the request payload for replacing an object type
 type ReplaceObjectTypeRequest struct {
-	Name              string                 `json:"name" binding:"required" validate:"required,min=1,max=255"`
-	ParentTypeID      *int64                 `json:"parent_type_id,omitempty" validate:"omitempty,gt=0"`
-	ConcreteTableName *string                `json:"concrete_table_name,omitempty" validate:"omitempty,min=1,max=255"`
-	Description       string                 `json:"description,omitempty" validate:"max=1000"`
-	IsSealed          *bool                  `json:"is_sealed,omitempty"`
-	Metadata          map[string]interface{} `json:"metadata,omitempty"`
+	Name               string                 `json:"name" binding:"required" validate:"required,min=1,max=255"`
+	ParentTypeID       *int64                 `json:"parent_type_id,omitempty" validate:"omitempty,gt=0"`
+	ConcreteTableName  *string                `json:"concrete_table_name,omitempty" validate:"omitempty,min=1,max=255"`
+	Description        string                 `json:"description,omitempty" validate:"max=1000"`
+	IsSealed           *bool                  `json:"is_sealed,omitempty"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty"`
+	MetadataSchema     json.RawMessage        `json:"metadata_schema,omitempty"`
+	SearchMetadataKeys []string               `json:"search_metadata_keys,omitempty"`
 }
 
 // ObjectTypeFilter represents query parameters for listing object types
@@ -52,20 +58,22 @@ type ObjectTypeFilter struct {
 
 // ObjectTypeResponse represents the response payload for object type operations
 type ObjectTypeResponse struct {
-	ID                int64                  `json:"id"`
-	Name              string                 `json:"name"`
-	ParentTypeID      *int64                 `json:"parent_type_id,omitempty"`
-	ParentName        *string                `json:"parent_name,omitempty"`
-	ConcreteTableName *string                `json:"concrete_table_name,omitempty"`
-	Description       string                 `json:"description,omitempty"`
-	IsSealed          bool                   `json:"is_sealed"`
-	Metadata          map[string]interface{} `json:"metadata,omitempty"`
-	CreatedAt         string                 `json:"created_at"`
-	UpdatedAt         string                 `json:"updated_at"`
-	CreatedBy         string                 `json:"created_by"`
-	UpdatedBy         string                 `json:"updated_by"`
-	Children          []ObjectTypeResponse   `json:"children,omitempty"`
-	ObjectCount       *int64                 `json:"object_count,omitempty"`
+	ID                 int64                  `json:"id"`
+	Name               string                 `json:"name"`
+	ParentTypeID       *int64                 `json:"parent_type_id,omitempty"`
+	ParentName         *string                `json:"parent_name,omitempty"`
+	ConcreteTableName  *string                `json:"concrete_table_name,omitempty"`
+	Description        string                 `json:"description,omitempty"`
+	IsSealed           bool                   `json:"is_sealed"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty"`
+	MetadataSchema     json.RawMessage        `json:"metadata_schema,omitempty"`
+	SearchMetadataKeys []string               `json:"search_metadata_keys,omitempty"`
+	CreatedAt          string                 `json:"created_at"`
+	UpdatedAt          string                 `json:"updated_at"`
+	CreatedBy          string                 `json:"created_by"`
+	UpdatedBy          string                 `json:"updated_by"`
+	Children           []ObjectTypeResponse   `json:"children,omitempty"`
+	ObjectCount        *int64                 `json:"object_count,omitempty"`
 }
 
 // ObjectTypeListResponse represents the paginated response for object types list
@@ -107,20 +115,22 @@ func (ot *ObjectType) ToResponse() *ObjectTypeResponse {
 	}
 
 	return &ObjectTypeResponse{
-		ID:                ot.ID,
-		Name:              ot.Name,
-		ParentTypeID:      ot.ParentTypeID,
-		ParentName:        parentName,
-		ConcreteTableName: ot.ConcreteTableName,
-		Description:       ot.Description,
-		IsSealed:          ot.IsSealed,
-		Metadata:          metadata,
-		CreatedAt:         ot.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:         ot.UpdatedAt.Format(time.RFC3339),
-		CreatedBy:         ot.CreatedBy,
-		UpdatedBy:         ot.UpdatedBy,
-		Children:          children,
-		ObjectCount:       objectCount,
+		ID:                 ot.ID,
+		Name:               ot.Name,
+		ParentTypeID:       ot.ParentTypeID,
+		ParentName:         parentName,
+		ConcreteTableName:  ot.ConcreteTableName,
+		Description:        ot.Description,
+		IsSealed:           ot.IsSealed,
+		Metadata:           metadata,
+		MetadataSchema:     ot.MetadataSchema,
+		SearchMetadataKeys: ot.SearchMetadataKeys,
+		CreatedAt:          ot.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          ot.UpdatedAt.Format(time.RFC3339),
+		CreatedBy:          ot.CreatedBy,
+		UpdatedBy:          ot.UpdatedBy,
+		Children:           children,
+		ObjectCount:        objectCount,
 	}
 }
 
@@ -143,17 +153,19 @@ func (ot *ObjectType) ToMinimalResponse() *ObjectTypeResponse {
 	}
 
 	return &ObjectTypeResponse{
-		ID:                ot.ID,
-		Name:              ot.Name,
-		ParentTypeID:      ot.ParentTypeID,
-		ParentName:        parentName,
-		ConcreteTableName: ot.ConcreteTableName,
-		Description:       ot.Description,
-		IsSealed:          ot.IsSealed,
-		Metadata:          metadata,
-		CreatedAt:         ot.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:         ot.UpdatedAt.Format(time.RFC3339),
-		CreatedBy:         ot.CreatedBy,
-		UpdatedBy:         ot.UpdatedBy,
+		ID:                 ot.ID,
+		Name:               ot.Name,
+		ParentTypeID:       ot.ParentTypeID,
+		ParentName:         parentName,
+		ConcreteTableName:  ot.ConcreteTableName,
+		Description:        ot.Description,
+		IsSealed:           ot.IsSealed,
+		Metadata:           metadata,
+		MetadataSchema:     ot.MetadataSchema,
+		SearchMetadataKeys: ot.SearchMetadataKeys,
+		CreatedAt:          ot.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          ot.UpdatedAt.Format(time.RFC3339),
+		CreatedBy:          ot.CreatedBy,
+		UpdatedBy:          ot.UpdatedBy,
 	}
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -155,3 +156,65 @@ func TestObjectType_Sealed(t *testing.T) {
 	assert.True(t, ot.IsSealed)
 	assert.False(t, ot.CanHaveChildren())
 }
+
+func TestIsEmptyMetadataSchema(t *testing.T) {
+	assert.True(t, IsEmptyMetadataSchema(nil))
+	assert.True(t, IsEmptyMetadataSchema(json.RawMessage("null")))
+	assert.True(t, IsEmptyMetadataSchema(json.RawMessage(" { } ")))
+	assert.False(t, IsEmptyMetadataSchema(json.RawMessage(`{"type":"object"}`)))
+
+	ot := ObjectType{MetadataSchema: json.RawMessage(`{"required":["number"]}`)}
+	assert.True(t, ot.HasMetadataSchema())
+	assert.False(t, (&ObjectType{}).HasMetadataSchema())
+}
+
+func TestDefaultConcreteTableName(t *testing.T) {
+	assert.Equal(t, "objects_book", DefaultConcreteTableName("Book"))
+	assert.Equal(t, "objects_e_book_v2", DefaultConcreteTableName("E-Book  v2"))
+	assert.Equal(t, "", DefaultConcreteTableName("!!!"))
+	assert.Equal(t, "", DefaultConcreteTableName(strings.Repeat("x", 60)))
+}
+
+func TestCheckAttributeValue(t *testing.T) {
+	attribute := func(dataType string, nullable bool) *ObjectTypeAttribute {
+		return &ObjectTypeAttribute{Name: "a", DataType: dataType, Nullable: nullable}
+	}
+
+	valid := []struct {
+		dataType string
+		value    interface{}
+	}{
+		{"text", "hello"},
+		{"integer", float64(42)},
+		{"bigint", float64(1 << 40)},
+		{"numeric", 1.5},
+		{"double", 2.25},
+		{"boolean", true},
+		{"date", "2024-02-29"},
+		{"timestamp", "2024-02-29T10:00:00Z"},
+		{"uuid", "6f1c2a5e-8d3b-4c7a-9e0f-1a2b3c4d5e6f"},
+		{"jsonb", map[string]interface{}{"k": []interface{}{1.0}}},
+	}
+	for _, tc := range valid {
+		assert.NoError(t, CheckAttributeValue(attribute(tc.dataType, false), tc.value), tc.dataType)
+	}
+
+	invalid := []struct {
+		dataType string
+		value    interface{}
+	}{
+		{"text", 1.0},
+		{"integer", 1.5},
+		{"integer", float64(1 << 40)},
+		{"boolean", "true"},
+		{"date", "29/02/2024"},
+		{"timestamp", "2024-02-29"},
+		{"uuid", "not-a-uuid"},
+	}
+	for _, tc := range invalid {
+		assert.Error(t, CheckAttributeValue(attribute(tc.dataType, true), tc.value), tc.dataType)
+	}
+
+	assert.NoError(t, CheckAttributeValue(attribute("text", true), nil))
+	assert.Error(t, CheckAttributeValue(attribute("text", false), nil))
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event entity types
+const (
+	EventEntityObject       = "object"
+	EventEntityObjectType   = "object_type"
+	EventEntityRelationship = "relationship"
+)
+
+// Event actions; an event type is "<entity type>.<action>", e.g. object.created
+const (
+	EventActionCreated  = "created"
+	EventActionUpdated  = "updated"
+	EventActionDeleted  = "deleted"
+	EventActionRestored = "restored"
+	EventActionMoved    = "moved"
+	EventActionPurged   = "purged"
+)
+
+// EventType returns the event type of an action on an entity type
+func EventType(entityType, action string) string {
+	return entityType + "." + action
+}
+
+// OutboxEvent is a domain event written to the outbox in the transaction of the change it
+// describes. Offset orders events in the log; Before is absent for creations and After for
+// deletions.
+type OutboxEvent struct {
+	Offset         int64           `json:"offset" db:"id"`
+	EventID        uuid.UUID       `json:"event_id" db:"event_id"`
+	EventType      string          `json:"event_type" db:"event_type"`
+	EntityType     string          `json:"entity_type" db:"entity_type"`
+	EntityID       int64           `json:"entity_id" db:"entity_id"`
+	EntityPublicID *uuid.UUID      `json:"entity_public_id,omitempty" db:"entity_public_id"`
+	Before         json.RawMessage `json:"before,omitempty" db:"before"`
+	After          json.RawMessage `json:"after,omitempty" db:"after"`
+	Actor          string          `json:"actor" db:"actor"`
+	TraceID        string          `json:"trace_id,omitempty" db:"trace_id"`
+	RequestID      string          `json:"request_id,omitempty" db:"request_id"`
+	OccurredAt     time.Time       `json:"occurred_at" db:"occurred_at"`
+}
+
+// OutboxEventFilter selects events from the log, oldest first, after an offset
+type OutboxEventFilter struct {
+	After      int64  `form:"after"`
+	Limit      int    `form:"limit"`
+	EventType  string `form:"event_type"`
+	EntityType string `form:"entity_type"`
+}
+
+// OutboxEventPage is one page of the event log. NextOffset is the offset to continue after.
+type OutboxEventPage struct {
+	Events     []*OutboxEvent `json:"events"`
+	NextOffset int64          `json:"next_offset"`
+	HasMore    bool           `json:"has_more"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import "github.com/google/uuid"
+
+// Traversal directions, relative to the source and target of each relationship followed
+const (
+	DirectionOutgoing = "outgoing"
+	DirectionIncoming = "incoming"
+	DirectionBoth     = "both"
+)
+
+// GraphQuery bounds a traversal over object relationships. Object type and status restrict
+// the objects the traversal passes through, not only the ones it returns.
+type GraphQuery struct {
+	TypeKeys     []string `json:"type_keys,omitempty" form:"type_keys"`
+	Direction    string   `json:"direction,omitempty" form:"direction"`
+	MaxDepth     int      `json:"max_depth,omitempty" form:"max_depth"`
+	ObjectTypeID *int64   `json:"object_type_id,omitempty" form:"object_type_id"`
+	Status       string   `json:"status,omitempty" form:"status"`
+	Limit        int      `json:"limit,omitempty" form:"limit"`
+}
+
+// GraphNode is an object reached by a traversal, at the fewest hops it was reached in
+type GraphNode struct {
+	ID           int64     `json:"id"`
+	PublicID     uuid.UUID `json:"public_id"`
+	ObjectTypeID int64     `json:"object_type_id"`
+	Name         string    `json:"name"`
+	Status       string    `json:"status"`
+	Depth        int       `json:"depth"`
+}
+
+// GraphEdge is a relationship between two objects of a traversal result
+type GraphEdge struct {
+	ID                   int64     `json:"id"`
+	PublicID             uuid.UUID `json:"public_id"`
+	SourceObjectID       int64     `json:"source_object_id"`
+	SourceObjectPublicID uuid.UUID `json:"source_object_public_id"`
+	TargetObjectID       int64     `json:"target_object_id"`
+	TargetObjectPublicID uuid.UUID `json:"target_object_public_id"`
+	TypeKey              string    `json:"type_key"`
+	Status               string    `json:"status"`
+}
+
+// Graph is the result of a traversal. Truncated is set when the node or edge limit cut it short.
+type Graph struct {
+	Nodes     []*GraphNode `json:"nodes"`
+	Edges     []*GraphEdge `json:"edges"`
+	Truncated bool         `json:"truncated"`
+}
+
+// GraphPath is a shortest path between two objects, with nodes and edges in walking order
+type GraphPath struct {
+	Nodes  []*GraphNode `json:"nodes"`
+	Edges  []*GraphEdge `json:"edges"`
+	Length int          `json:"length"`
+}
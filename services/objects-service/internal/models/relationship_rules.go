@@ -0,0 +1,87 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Rule names reported in RelationshipRuleError
+const (
+	RuleAllowedSourceTypes = "allowed_source_types"
+	RuleAllowedTargetTypes = "allowed_target_types"
+	RuleRequiredMetadata   = "required_metadata"
+	RuleMetadataSchema     = "metadata_schema"
+	RuleAllowedStatuses    = "allowed_statuses"
+)
+
+// ValidRelationshipStatuses contains the statuses a relationship can have
+var ValidRelationshipStatuses = map[string]bool{
+	"active":     true,
+	"inactive":   true,
+	"deprecated": true,
+}
+
+// RelationshipValidationRules is the structure of a relationship type's validation_rules. Every
+// rule is optional. Source and target types are object type names and also admit their
+// subtypes; metadata_schema is a JSON Schema for relationship_metadata.
+type RelationshipValidationRules struct {
+	AllowedSourceTypes []string        `json:"allowed_source_types,omitempty"`
+	AllowedTargetTypes []string        `json:"allowed_target_types,omitempty"`
+	RequiredMetadata   []string        `json:"required_metadata,omitempty"`
+	MetadataSchema     json.RawMessage `json:"metadata_schema,omitempty"`
+	AllowedStatuses    []string        `json:"allowed_statuses,omitempty"`
+}
+
+// ParseRelationshipValidationRules decodes stored or submitted validation rules. Absent, null
+// and {} documents decode to empty rules; unknown keys are refused so typos do not silently
+// disable a rule.
+func ParseRelationshipValidationRules(raw json.RawMessage) (*RelationshipValidationRules, error) {
+	rules := &RelationshipValidationRules{}
+	if IsEmptyMetadataSchema(raw) {
+		return rules, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(rules); err != nil {
+		return nil, fmt.Errorf("validation_rules: %w", err)
+	}
+	return rules, nil
+}
+
+// RelationshipRuleError is one violation of a relationship type's validation rules. Path is a
+// JSON Pointer into relationship_metadata for metadata rules.
+type RelationshipRuleError struct {
+	Rule    string `json:"rule"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// RelationshipRuleViolation lists the rule violations of one existing relationship
+type RelationshipRuleViolation struct {
+	ObjectID             int64                   `json:"object_id"`
+	PublicID             uuid.UUID               `json:"public_id"`
+	SourceObjectPublicID uuid.UUID               `json:"source_object_public_id"`
+	TargetObjectPublicID uuid.UUID               `json:"target_object_public_id"`
+	Errors               []RelationshipRuleError `json:"errors"`
+}
+
+// RelationshipValidationReport is the result of checking existing relationships of a type
+// against its validation rules
+type RelationshipValidationReport struct {
+	TypeKey    string                      `json:"type_key"`
+	Checked    int                         `json:"checked"`
+	Invalid    int                         `json:"invalid"`
+	Violations []RelationshipRuleViolation `json:"violations"`
+	Truncated  bool                        `json:"truncated"`
+}
+
+// ValidateRelationshipRulesRequest optionally carries candidate validation rules to check
+// existing relationships against before they are saved; without them the type's stored rules
+// are used
+type ValidateRelationshipRulesRequest struct {
+	ValidationRules json.RawMessage `json:"validation_rules,omitempty"`
+}
@@ -55,6 +55,19 @@ func IsValidCardinality(cardinality string) bool {
 	return false
 }
 
+// ReverseCardinality returns the cardinality of a relationship type seen from its reverse type:
+// one_to_many and many_to_one swap, the symmetric cardinalities stay as they are
+func ReverseCardinality(cardinality string) string {
+	switch cardinality {
+	case CardinalityOneToMany:
+		return CardinalityManyToOne
+	case CardinalityManyToOne:
+		return CardinalityOneToMany
+	default:
+		return cardinality
+	}
+}
+
 // GetValidationRulesMap converts JSONB validation_rules to map[string]interface{}
 func (rt *RelationshipType) GetValidationRulesMap() map[string]interface{} {
 	if len(rt.ValidationRules) == 0 {
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// TaxonomyBundleVersion is the format version of taxonomy bundles written by this service
+const TaxonomyBundleVersion = 1
+
+// TaxonomyBundle is a portable description of the object type tree and the relationship types.
+// Object types refer to their parent by name and are listed parents first.
+type TaxonomyBundle struct {
+	FormatVersion     int                      `json:"format_version" yaml:"format_version"`
+	ExportedAt        *time.Time               `json:"exported_at,omitempty" yaml:"exported_at,omitempty"`
+	ObjectTypes       []BundleObjectType       `json:"object_types" yaml:"object_types"`
+	RelationshipTypes []BundleRelationshipType `json:"relationship_types" yaml:"relationship_types"`
+}
+
+// BundleObjectType is an object type in a taxonomy bundle, identified by its name
+type BundleObjectType struct {
+	Name               string                 `json:"name" yaml:"name"`
+	Parent             string                 `json:"parent,omitempty" yaml:"parent,omitempty"`
+	ConcreteTableName  string                 `json:"concrete_table_name,omitempty" yaml:"concrete_table_name,omitempty"`
+	Description        string                 `json:"description,omitempty" yaml:"description,omitempty"`
+	IsSealed           bool                   `json:"is_sealed,omitempty" yaml:"is_sealed,omitempty"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	MetadataSchema     map[string]interface{} `json:"metadata_schema,omitempty" yaml:"metadata_schema,omitempty"`
+	SearchMetadataKeys []string               `json:"search_metadata_keys,omitempty" yaml:"search_metadata_keys,omitempty"`
+}
+
+// BundleRelationshipType is a relationship type in a taxonomy bundle, identified by its type key.
+// Both types of a reverse pair are listed and name each other.
+type BundleRelationshipType struct {
+	TypeKey          string                 `json:"type_key" yaml:"type_key"`
+	RelationshipName string                 `json:"relationship_name,omitempty" yaml:"relationship_name,omitempty"`
+	ReverseTypeKey   string                 `json:"reverse_type_key,omitempty" yaml:"reverse_type_key,omitempty"`
+	Cardinality      string                 `json:"cardinality" yaml:"cardinality"`
+	Required         bool                   `json:"required,omitempty" yaml:"required,omitempty"`
+	MinCount         int                    `json:"min_count" yaml:"min_count"`
+	MaxCount         int                    `json:"max_count" yaml:"max_count"`
+	ValidationRules  map[string]interface{} `json:"validation_rules,omitempty" yaml:"validation_rules,omitempty"`
+}
+
+// Taxonomy plan actions
+const (
+	TaxonomyActionCreate = "create"
+	TaxonomyActionUpdate = "update"
+	TaxonomyActionMove   = "move"
+	TaxonomyActionDelete = "delete"
+)
+
+// Kinds of taxonomy entries
+const (
+	TaxonomyKindObjectType       = "object_type"
+	TaxonomyKindRelationshipType = "relationship_type"
+)
+
+// TaxonomyChange is one step of a taxonomy import plan. Updates list the changed fields; moves
+// name the old and new parent, empty for the root.
+type TaxonomyChange struct {
+	Action     string   `json:"action"`
+	Kind       string   `json:"kind"`
+	Key        string   `json:"key"`
+	Fields     []string `json:"fields,omitempty"`
+	FromParent *string  `json:"from_parent,omitempty"`
+	ToParent   *string  `json:"to_parent,omitempty"`
+}
+
+// TaxonomyPlan is the difference between a bundle and the live taxonomy, in the order it is applied
+type TaxonomyPlan struct {
+	Applied bool             `json:"applied"`
+	Changes []TaxonomyChange `json:"changes"`
+	Summary map[string]int   `json:"summary"`
+}
+
+// TaxonomyImportOptions controls a taxonomy import. Without Prune, types missing from the bundle
+// are kept; with DryRun, the plan is returned without applying it.
+type TaxonomyImportOptions struct {
+	DryRun bool   `form:"dry_run"`
+	Prune  bool   `form:"prune"`
+	Actor  string `form:"-"`
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ObjectRecord is an object as streamed by export and import, one NDJSON line or CSV row. The
+// type is named and the parent referred to by public ID or external key, so records can be
+// loaded into another environment.
+type ObjectRecord struct {
+	PublicID          string                 `json:"public_id,omitempty"`
+	ExternalKey       string                 `json:"external_key,omitempty"`
+	ObjectType        string                 `json:"object_type"`
+	ParentPublicID    string                 `json:"parent_public_id,omitempty"`
+	ParentExternalKey string                 `json:"parent_external_key,omitempty"`
+	Name              string                 `json:"name"`
+	Description       *string                `json:"description,omitempty"`
+	Status            string                 `json:"status,omitempty"`
+	Tags              []string               `json:"tags,omitempty"`
+	Metadata          json.RawMessage        `json:"metadata,omitempty"`
+	Attributes        map[string]interface{} `json:"attributes,omitempty"`
+	CreatedBy         string                 `json:"created_by,omitempty"`
+	CreatedAt         *time.Time             `json:"created_at,omitempty"` // ignored by import
+	UpdatedAt         *time.Time             `json:"updated_at,omitempty"` // ignored by import
+
+	// Set by export to load the attributes of the object
+	ID           int64 `json:"-"`
+	ObjectTypeID int64 `json:"-"`
+}
+
+// RelationshipRecord is a relationship as streamed by export and import. Its ends are referred
+// to by public ID or external key.
+type RelationshipRecord struct {
+	PublicID          string          `json:"public_id,omitempty"` // ignored by import
+	RelationshipType  string          `json:"relationship_type"`
+	SourcePublicID    string          `json:"source_public_id,omitempty"`
+	SourceExternalKey string          `json:"source_external_key,omitempty"`
+	TargetPublicID    string          `json:"target_public_id,omitempty"`
+	TargetExternalKey string          `json:"target_external_key,omitempty"`
+	Status            string          `json:"status,omitempty"`
+	Metadata          json.RawMessage `json:"metadata,omitempty"`
+	CreatedBy         string          `json:"created_by,omitempty"`
+	CreatedAt         *time.Time      `json:"created_at,omitempty"` // ignored by import
+	UpdatedAt         *time.Time      `json:"updated_at,omitempty"` // ignored by import
+}
+
+// RelationshipExportFilter selects the relationships to export
+type RelationshipExportFilter struct {
+	TypeKey string `form:"type_key"`
+	Status  string `form:"status"`
+}
+
+// ImportedObject is an object record resolved for writing. ID is set when the record replaces
+// an existing object.
+type ImportedObject struct {
+	ID             int64
+	PublicID       uuid.UUID
+	ExternalKey    *string
+	ObjectTypeID   int64
+	ParentObjectID *int64
+	Name           string
+	Description    *string
+	Metadata       json.RawMessage
+	Tags           []string
+	Status         string
+	CreatedBy      string
+	UpdatedBy      string
+	Attributes     map[string]interface{}
+}
+
+// TransferImportOptions control a streaming import
+type TransferImportOptions struct {
+	Format string `form:"format"` // ndjson or csv
+	Upsert bool   `form:"upsert"` // update records that match an existing entity instead of failing them
+	Actor  string `form:"-"`
+}
+
+// TransferImportResult summarizes a streaming import. Records that fail are reported and
+// skipped; the others are loaded.
+type TransferImportResult struct {
+	Records         int                   `json:"records"`
+	Created         int                   `json:"created"`
+	Updated         int                   `json:"updated"`
+	Failed          int                   `json:"failed"`
+	Errors          []TransferRecordError `json:"errors"`
+	ErrorsTruncated bool                  `json:"errors_truncated"`  // more records failed than are listed
+	Aborted         string                `json:"aborted,omitempty"` // why the stream could not be read to its end
+}
+
+// TransferRecordError is a record that could not be imported, by the line it starts on
+type TransferRecordError struct {
+	Line  int    `json:"line"`
+	Key   string `json:"key,omitempty"` // public ID, external key or name of the record
+	Error string `json:"error"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// TrashFilter selects soft-deleted objects, most recently deleted first
+type TrashFilter struct {
+	Name           string     `form:"name"`
+	ObjectTypeID   *int64     `form:"object_type_id"`
+	ParentObjectID *int64     `form:"parent_object_id"`
+	DeletedAfter   *time.Time `form:"deleted_after"`
+	DeletedBefore  *time.Time `form:"deleted_before"`
+	Limit          int        `form:"limit"`
+	Offset         int        `form:"offset"`
+	CreatedBy      string     `form:"-"` // set to restrict the list to objects of one creator
+}
+
+// RestoreObjectRequest takes a soft-deleted object out of the trash
+type RestoreObjectRequest struct {
+	IncludeDescendants   bool   `json:"include_descendants,omitempty"`   // also restore descendants deleted below it
+	IncludeRelationships bool   `json:"include_relationships,omitempty"` // also restore relationships deleted with the restored objects
+	UpdatedBy            string `json:"-"`
+}
+
+// RestoreObjectResult is the restored object with the other entities restored along with it
+type RestoreObjectResult struct {
+	Object        *Object `json:"object"`
+	Descendants   []int64 `json:"descendants"`
+	Relationships []int64 `json:"relationships"`
+}
+
+// PurgeObjectResult lists the objects removed for good: the purged object and its deleted
+// descendants
+type PurgeObjectResult struct {
+	Purged []int64 `json:"purged"`
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookSubscription is an endpoint that receives domain events as signed HTTP POSTs. Empty
+// filters match every event; the secret signs payloads and is only returned when it is set.
+type WebhookSubscription struct {
+	ID                   int64     `json:"-" db:"id"`
+	PublicID             uuid.UUID `json:"id" db:"public_id"`
+	Name                 string    `json:"name" db:"name"`
+	TargetURL            string    `json:"target_url" db:"target_url"`
+	Secret               string    `json:"secret,omitempty" db:"secret"`
+	EventTypes           []string  `json:"event_types" db:"event_types"`
+	ObjectTypeIDs        []int64   `json:"object_type_ids" db:"object_type_ids"`
+	RelationshipTypeKeys []string  `json:"relationship_type_keys" db:"relationship_type_keys"`
+	IsActive             bool      `json:"is_active" db:"is_active"`
+	CreatedBy            string    `json:"created_by" db:"created_by"`
+	UpdatedBy            string    `json:"updated_by" db:"updated_by"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CreateWebhookSubscriptionRequest represents creation input. A secret is generated when none
+// is given.
+type CreateWebhookSubscriptionRequest struct {
+	Name                 string   `json:"name" binding:"required"`
+	TargetURL            string   `json:"target_url" binding:"required"`
+	Secret               string   `json:"secret,omitempty"`
+	EventTypes           []string `json:"event_types,omitempty"`
+	ObjectTypeIDs        []int64  `json:"object_type_ids,omitempty"`
+	RelationshipTypeKeys []string `json:"relationship_type_keys,omitempty"`
+	IsActive             *bool    `json:"is_active,omitempty"`
+	CreatedBy            string   `json:"-"`
+}
+
+// UpdateWebhookSubscriptionRequest represents update input; a new secret replaces the old one
+type UpdateWebhookSubscriptionRequest struct {
+	Name                 *string   `json:"name,omitempty"`
+	TargetURL            *string   `json:"target_url,omitempty"`
+	Secret               *string   `json:"secret,omitempty"`
+	EventTypes           *[]string `json:"event_types,omitempty"`
+	ObjectTypeIDs        *[]int64  `json:"object_type_ids,omitempty"`
+	RelationshipTypeKeys *[]string `json:"relationship_type_keys,omitempty"`
+	IsActive             *bool     `json:"is_active,omitempty"`
+	UpdatedBy            string    `json:"-"`
+}
+
+// Webhook delivery statuses. A pending delivery that failed is retried until it runs out of
+// attempts and becomes dead; dead deliveries form the dead-letter list.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookDelivery is the delivery of one event to one subscription and the log of its attempts
+type WebhookDelivery struct {
+	ID             int64           `json:"-" db:"id"`
+	PublicID       uuid.UUID       `json:"id" db:"public_id"`
+	SubscriptionID int64           `json:"-" db:"subscription_id"`
+	EventID        uuid.UUID       `json:"event_id" db:"event_id"`
+	EventType      string          `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" db:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty" db:"last_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      *string         `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+}
+
+// WebhookDeliveryFilter selects deliveries of a subscription, newest first
+type WebhookDeliveryFilter struct {
+	Status string `form:"status"`
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+}
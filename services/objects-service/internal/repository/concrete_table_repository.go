@@ -0,0 +1,603 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+)
+
+// concreteTableSchema is the schema concrete tables are provisioned in
+const concreteTableSchema = "objects_service"
+
+// concreteTableComment marks a table as provisioned for an object type; the table comment is
+// how ownership of an existing table is recognised
+const concreteTableComment = "Concrete table of object type %d"
+
+// ConcreteTableRepository defines operations on typed attribute declarations, the concrete tables
+// they are stored in and the attribute values of objects
+type ConcreteTableRepository interface {
+	Repository
+
+	// Attribute declarations
+	ListAttributes(ctx context.Context, objectTypeID int64) ([]*models.ObjectTypeAttribute, error)
+	CreateAttributes(ctx context.Context, objectTypeID int64, declarations []models.AttributeDeclaration, createdBy string) ([]*models.ObjectTypeAttribute, error)
+	GetConcreteTables(ctx context.Context, objectTypeID int64) ([]*models.ConcreteTable, error)
+	ListSubtreeAttributes(ctx context.Context, objectTypeID int64) ([]*models.ObjectTypeAttribute, error)
+
+	// Concrete tables
+	TableOwner(ctx context.Context, tableName string) (exists bool, objectTypeID int64, err error)
+	TableHasRows(ctx context.Context, tableName string) (bool, error)
+	ApplyDDL(ctx context.Context, statements []string) error
+
+	// Attribute values
+	LoadAttributes(ctx context.Context, objects []*models.Object) error
+	WriteAttributes(ctx context.Context, objectID, objectTypeID int64, previousTypeID *int64, values map[string]interface{}) error
+}
+
+// concreteTableRepository implements ConcreteTableRepository
+type concreteTableRepository struct {
+	db      DBInterface
+	options *RepositoryOptions
+	metrics *RepositoryMetrics
+}
+
+// NewConcreteTableRepository creates a new ConcreteTableRepository instance
+func NewConcreteTableRepository(db DBInterface, options *RepositoryOptions) ConcreteTableRepository {
+	if options == nil {
+		options = DefaultRepositoryOptions()
+	}
+
+	return &concreteTableRepository{
+		db:      db,
+		options: options,
+		metrics: &RepositoryMetrics{LastResetAt: time.Now()},
+	}
+}
+
+// DB implements Repository interface
+func (r *concreteTableRepository) DB() DBInterface {
+	return r.db
+}
+
+// Options implements Repository interface
+func (r *concreteTableRepository) Options() *RepositoryOptions {
+	return r.options
+}
+
+// Metrics implements Repository interface
+func (r *concreteTableRepository) Metrics() *RepositoryMetrics {
+	return r.metrics
+}
+
+// ResetMetrics implements Repository interface
+func (r *concreteTableRepository) ResetMetrics() {
+	r.metrics.Reset()
+}
+
+// Healthy implements Repository interface
+func (r *concreteTableRepository) Healthy(ctx context.Context) error {
+	var result int
+	return r.db.QueryRow(ctx, "SELECT 1").Scan(&result)
+}
+
+// ListAttributes returns the attributes an object type declares itself, in declaration order
+func (r *concreteTableRepository) ListAttributes(ctx context.Context, objectTypeID int64) ([]*models.ObjectTypeAttribute, error) {
+	r.metrics.QueryCount++
+
+	query := `
+		SELECT COALESCE(jsonb_agg(to_jsonb(a) ORDER BY a.position, a.id), '[]')
+		FROM objects_service.object_type_attributes a
+		WHERE a.object_type_id = $1`
+
+	var raw []byte
+	if err := r.db.QueryRow(ctx, query, objectTypeID).Scan(&raw); err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to list attributes: %w", err)
+	}
+	return decodeAttributes(raw)
+}
+
+// CreateAttributes stores new attribute declarations after the type's existing ones
+func (r *concreteTableRepository) CreateAttributes(ctx context.Context, objectTypeID int64, declarations []models.AttributeDeclaration, createdBy string) ([]*models.ObjectTypeAttribute, error) {
+	r.metrics.QueryCount++
+
+	if createdBy == "" {
+		createdBy = "system"
+	}
+
+	query := `
+		INSERT INTO objects_service.object_type_attributes (
+			object_type_id, name, data_type, nullable, indexed, position, created_by
+		) VALUES (
+			$1, $2, $3, $4, $5,
+			COALESCE((SELECT MAX(position) FROM objects_service.object_type_attributes WHERE object_type_id = $1), 0) + 1,
+			$6
+		) RETURNING id, position, created_at`
+
+	attributes := make([]*models.ObjectTypeAttribute, 0, len(declarations))
+	for _, declaration := range declarations {
+		attribute := &models.ObjectTypeAttribute{
+			ObjectTypeID: objectTypeID,
+			Name:         declaration.Name,
+			DataType:     declaration.DataType,
+			Nullable:     declaration.IsNullable(),
+			Indexed:      declaration.Indexed,
+			CreatedBy:    createdBy,
+		}
+		err := r.db.QueryRow(ctx, query,
+			objectTypeID, attribute.Name, attribute.DataType, attribute.Nullable, attribute.Indexed, createdBy,
+		).Scan(&attribute.ID, &attribute.Position, &attribute.CreatedAt)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to create attribute %q: %w", attribute.Name, err)
+		}
+		attributes = append(attributes, attribute)
+	}
+	return attributes, nil
+}
+
+// GetConcreteTables returns the concrete tables of an object type and its ancestors that declare
+// attributes, from the root down. An object of the type has a row in each of them.
+func (r *concreteTableRepository) GetConcreteTables(ctx context.Context, objectTypeID int64) ([]*models.ConcreteTable, error) {
+	r.metrics.QueryCount++
+
+	query := `
+		WITH RECURSIVE path AS (
+			SELECT id, parent_type_id, concrete_table_name, 0 AS depth
+			FROM objects_service.object_types
+			WHERE id = $1
+			UNION ALL
+			SELECT ot.id, ot.parent_type_id, ot.concrete_table_name, p.depth + 1
+			FROM objects_service.object_types ot
+			INNER JOIN path p ON ot.id = p.parent_type_id
+		)
+		SELECT COALESCE(jsonb_agg(jsonb_build_object(
+			'object_type_id', p.id,
+			'table_name', p.concrete_table_name,
+			'attributes', (
+				SELECT jsonb_agg(to_jsonb(a) ORDER BY a.position, a.id)
+				FROM objects_service.object_type_attributes a
+				WHERE a.object_type_id = p.id
+			)
+		) ORDER BY p.depth DESC), '[]')
+		FROM path p
+		WHERE EXISTS (SELECT 1 FROM objects_service.object_type_attributes a WHERE a.object_type_id = p.id)`
+
+	var raw []byte
+	if err := r.db.QueryRow(ctx, query, objectTypeID).Scan(&raw); err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to get concrete tables: %w", err)
+	}
+
+	tables := []*models.ConcreteTable{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &tables); err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to decode concrete tables: %w", err)
+		}
+	}
+	for _, table := range tables {
+		if !models.IsValidSQLIdentifier(table.TableName) {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("object type %d has attributes but no valid concrete table name", table.ObjectTypeID)
+		}
+	}
+	return tables, nil
+}
+
+// ListSubtreeAttributes returns the attributes declared by an object type and its descendants
+func (r *concreteTableRepository) ListSubtreeAttributes(ctx context.Context, objectTypeID int64) ([]*models.ObjectTypeAttribute, error) {
+	r.metrics.QueryCount++
+
+	query := `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM objects_service.object_types WHERE id = $1
+			UNION ALL
+			SELECT ot.id FROM objects_service.object_types ot
+			INNER JOIN subtree s ON ot.parent_type_id = s.id
+		)
+		SELECT COALESCE(jsonb_agg(to_jsonb(a) ORDER BY a.object_type_id, a.position, a.id), '[]')
+		FROM objects_service.object_type_attributes a
+		WHERE a.object_type_id IN (SELECT id FROM subtree)`
+
+	var raw []byte
+	if err := r.db.QueryRow(ctx, query, objectTypeID).Scan(&raw); err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to list subtree attributes: %w", err)
+	}
+	return decodeAttributes(raw)
+}
+
+// TableOwner reports whether a table exists in the objects_service schema and, when it is a
+// provisioned concrete table, the object type it belongs to; other tables have owner 0
+func (r *concreteTableRepository) TableOwner(ctx context.Context, tableName string) (bool, int64, error) {
+	r.metrics.QueryCount++
+
+	query := `
+		SELECT to_regclass($1) IS NOT NULL, COALESCE(obj_description(to_regclass($1), 'pg_class'), '')`
+
+	var exists bool
+	var comment string
+	if err := r.db.QueryRow(ctx, query, qualifiedTableName(tableName)).Scan(&exists, &comment); err != nil {
+		r.metrics.ErrorCount++
+		return false, 0, fmt.Errorf("failed to look up table %s: %w", tableName, err)
+	}
+
+	var owner int64
+	if _, err := fmt.Sscanf(comment, concreteTableComment, &owner); err != nil {
+		owner = 0
+	}
+	return exists, owner, nil
+}
+
+// TableHasRows reports whether a concrete table holds any row
+func (r *concreteTableRepository) TableHasRows(ctx context.Context, tableName string) (bool, error) {
+	r.metrics.QueryCount++
+
+	var hasRows bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s)`, qualifiedTableName(tableName))
+	if err := r.db.QueryRow(ctx, query).Scan(&hasRows); err != nil {
+		r.metrics.ErrorCount++
+		return false, fmt.Errorf("failed to check rows of %s: %w", tableName, err)
+	}
+	return hasRows, nil
+}
+
+// ApplyDDL executes DDL statements in order. Run inside a transaction, a failing statement
+// undoes the ones before it.
+func (r *concreteTableRepository) ApplyDDL(ctx context.Context, statements []string) error {
+	for _, statement := range statements {
+		r.metrics.QueryCount++
+		if _, err := r.db.Exec(ctx, statement); err != nil {
+			r.metrics.ErrorCount++
+			return fmt.Errorf("failed to apply %q: %w", statement, err)
+		}
+	}
+	return nil
+}
+
+// LoadAttributes sets the attributes of objects whose type has concrete tables. Every declared
+// attribute is present; attributes without a stored value are null.
+func (r *concreteTableRepository) LoadAttributes(ctx context.Context, objects []*models.Object) error {
+	byType := map[int64][]*models.Object{}
+	for _, object := range objects {
+		byType[object.ObjectTypeID] = append(byType[object.ObjectTypeID], object)
+	}
+
+	for objectTypeID, typed := range byType {
+		tables, err := r.GetConcreteTables(ctx, objectTypeID)
+		if err != nil {
+			return err
+		}
+		if len(tables) == 0 {
+			continue
+		}
+
+		byID := make(map[int64]*models.Object, len(typed))
+		ids := make([]int64, 0, len(typed))
+		for _, object := range typed {
+			object.Attributes = map[string]interface{}{}
+			for _, table := range tables {
+				for _, attribute := range table.Attributes {
+					object.Attributes[attribute.Name] = nil
+				}
+			}
+			byID[object.ID] = object
+			ids = append(ids, object.ID)
+		}
+
+		for _, table := range tables {
+			if err := r.loadTableRows(ctx, table, ids, byID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadTableRows copies the values of one concrete table into the attributes of the objects
+func (r *concreteTableRepository) loadTableRows(ctx context.Context, table *models.ConcreteTable, ids []int64, byID map[int64]*models.Object) error {
+	r.metrics.QueryCount++
+
+	query := fmt.Sprintf(`
+		SELECT t.object_id, to_jsonb(t) - 'object_id'
+		FROM %s t
+		WHERE t.object_id = ANY($1)`, qualifiedTableName(table.TableName))
+
+	rows, err := r.db.Query(ctx, query, ids)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return fmt.Errorf("failed to load attributes from %s: %w", table.TableName, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var objectID int64
+		var raw []byte
+		if err := rows.Scan(&objectID, &raw); err != nil {
+			r.metrics.ErrorCount++
+			return fmt.Errorf("failed to scan attributes from %s: %w", table.TableName, err)
+		}
+		var values map[string]interface{}
+		if err := json.Unmarshal(raw, &values); err != nil {
+			r.metrics.ErrorCount++
+			return fmt.Errorf("failed to decode attributes from %s: %w", table.TableName, err)
+		}
+		object := byID[objectID]
+		if object == nil {
+			continue
+		}
+		for _, attribute := range table.Attributes {
+			object.Attributes[attribute.Name] = values[attribute.Name]
+		}
+	}
+	return rows.Err()
+}
+
+// WriteAttributes stores attribute values of an object in the concrete tables of its type. Only
+// the given attributes change. previousTypeID is nil for a new object, which gets a row in every
+// table; when it names another type, rows of tables the object leaves are deleted and tables it
+// joins get a row.
+func (r *concreteTableRepository) WriteAttributes(ctx context.Context, objectID, objectTypeID int64, previousTypeID *int64, values map[string]interface{}) error {
+	tables, err := r.GetConcreteTables(ctx, objectTypeID)
+	if err != nil {
+		return err
+	}
+
+	previous := map[string]bool{}
+	if previousTypeID != nil {
+		previousTables := tables
+		if *previousTypeID != objectTypeID {
+			if previousTables, err = r.GetConcreteTables(ctx, *previousTypeID); err != nil {
+				return err
+			}
+		}
+		for _, table := range previousTables {
+			previous[table.TableName] = true
+		}
+	}
+
+	// Every value must belong to a declared attribute and match its type
+	declared := map[string]*models.ObjectTypeAttribute{}
+	for _, table := range tables {
+		for _, attribute := range table.Attributes {
+			declared[attribute.Name] = attribute
+		}
+	}
+	for name, value := range values {
+		attribute := declared[name]
+		if attribute == nil {
+			return fmt.Errorf("object type %d has no attribute %q: %w", objectTypeID, name, ErrInvalidInput)
+		}
+		if err := models.CheckAttributeValue(attribute, value); err != nil {
+			return fmt.Errorf("%v: %w", err, ErrInvalidInput)
+		}
+	}
+
+	current := map[string]bool{}
+	for _, table := range tables {
+		current[table.TableName] = true
+		tableValues := map[string]interface{}{}
+		for _, attribute := range table.Attributes {
+			if value, ok := values[attribute.Name]; ok {
+				tableValues[attribute.Name] = value
+			}
+		}
+
+		if len(tableValues) > 0 {
+			updated, err := r.updateRow(ctx, table, objectID, tableValues)
+			if err != nil || updated {
+				return errOrNil(err, table)
+			}
+		} else if previous[table.TableName] {
+			continue
+		} else if exists, err := r.rowExists(ctx, table, objectID); err != nil || exists {
+			return errOrNil(err, table)
+		}
+
+		if err := r.insertRow(ctx, table, objectID, tableValues); err != nil {
+			return err
+		}
+	}
+
+	for tableName := range previous {
+		if current[tableName] {
+			continue
+		}
+		r.metrics.QueryCount++
+		query := fmt.Sprintf(`DELETE FROM %s WHERE object_id = $1`, qualifiedTableName(tableName))
+		if _, err := r.db.Exec(ctx, query, objectID); err != nil {
+			r.metrics.ErrorCount++
+			return fmt.Errorf("failed to delete attributes from %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
+
+func errOrNil(err error, table *models.ConcreteTable) error {
+	if err != nil {
+		return fmt.Errorf("failed to write attributes to %s: %w", table.TableName, err)
+	}
+	return nil
+}
+
+// updateRow sets the given columns of an object's row, reporting whether the row exists
+func (r *concreteTableRepository) updateRow(ctx context.Context, table *models.ConcreteTable, objectID int64, values map[string]interface{}) (bool, error) {
+	r.metrics.QueryCount++
+
+	payload, err := json.Marshal(values)
+	if err != nil {
+		return false, err
+	}
+
+	var setClauses []string
+	for _, attribute := range table.Attributes {
+		if _, ok := values[attribute.Name]; ok {
+			column := quoteIdentifier(attribute.Name)
+			setClauses = append(setClauses, fmt.Sprintf("%s = v.%s", column, column))
+		}
+	}
+
+	tableName := qualifiedTableName(table.TableName)
+	query := fmt.Sprintf(`
+		UPDATE %s t SET %s
+		FROM jsonb_populate_record(NULL::%s, $2) v
+		WHERE t.object_id = $1
+		RETURNING t.object_id`, tableName, strings.Join(setClauses, ", "), tableName)
+
+	var id int64
+	err = r.db.QueryRow(ctx, query, objectID, payload).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		r.metrics.ErrorCount++
+		return false, attributeWriteError(err)
+	}
+	return true, nil
+}
+
+// insertRow adds an object's row; attributes without a value are null, so every non-nullable
+// attribute must be given
+func (r *concreteTableRepository) insertRow(ctx context.Context, table *models.ConcreteTable, objectID int64, values map[string]interface{}) error {
+	r.metrics.QueryCount++
+
+	for _, attribute := range table.Attributes {
+		if _, ok := values[attribute.Name]; !ok && !attribute.Nullable {
+			return fmt.Errorf("attribute %q is required: %w", attribute.Name, ErrInvalidInput)
+		}
+	}
+
+	row := map[string]interface{}{"object_id": objectID}
+	for name, value := range values {
+		row[name] = value
+	}
+	payload, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	tableName := qualifiedTableName(table.TableName)
+	query := fmt.Sprintf(`INSERT INTO %s SELECT * FROM jsonb_populate_record(NULL::%s, $1)`, tableName, tableName)
+	if _, err := r.db.Exec(ctx, query, payload); err != nil {
+		r.metrics.ErrorCount++
+		return fmt.Errorf("failed to write attributes to %s: %w", table.TableName, attributeWriteError(err))
+	}
+	return nil
+}
+
+func (r *concreteTableRepository) rowExists(ctx context.Context, table *models.ConcreteTable, objectID int64) (bool, error) {
+	r.metrics.QueryCount++
+
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE object_id = $1)`, qualifiedTableName(table.TableName))
+	if err := r.db.QueryRow(ctx, query, objectID).Scan(&exists); err != nil {
+		r.metrics.ErrorCount++
+		return false, err
+	}
+	return exists, nil
+}
+
+// attributeWriteError marks values PostgreSQL rejects (data exceptions and not-null violations)
+// as invalid input
+func attributeWriteError(err error) error {
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		state := pgErr.SQLState()
+		if strings.HasPrefix(state, "22") || state == "23502" {
+			return fmt.Errorf("%v: %w", err, ErrInvalidInput)
+		}
+	}
+	return err
+}
+
+func decodeAttributes(raw []byte) ([]*models.ObjectTypeAttribute, error) {
+	attributes := []*models.ObjectTypeAttribute{}
+	if len(raw) == 0 {
+		return attributes, nil
+	}
+	if err := json.Unmarshal(raw, &attributes); err != nil {
+		return nil, fmt.Errorf("failed to decode attributes: %w", err)
+	}
+	return attributes, nil
+}
+
+// qualifiedTableName returns the quoted, schema-qualified name of a concrete table
+func qualifiedTableName(tableName string) string {
+	return pgx.Identifier{concreteTableSchema, tableName}.Sanitize()
+}
+
+// quoteIdentifier quotes a column or index name. Valid identifiers may still be reserved words
+// such as order or user.
+func quoteIdentifier(name string) string {
+	return pgx.Identifier{name}.Sanitize()
+}
+
+// CreateConcreteTableDDL returns the statements creating a concrete table with the given
+// attributes, marked as belonging to its object type
+func CreateConcreteTableDDL(table *models.ConcreteTable) []string {
+	tableName := qualifiedTableName(table.TableName)
+
+	columns := []string{
+		"    object_id BIGINT PRIMARY KEY REFERENCES objects_service.objects(id) ON DELETE CASCADE",
+	}
+	for _, attribute := range table.Attributes {
+		columns = append(columns, "    "+columnDefinition(attribute))
+	}
+
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE %s (\n%s\n)", tableName, strings.Join(columns, ",\n")),
+		fmt.Sprintf("COMMENT ON TABLE %s IS '%s'", tableName, fmt.Sprintf(concreteTableComment, table.ObjectTypeID)),
+	}
+	return append(statements, indexDDL(table.TableName, table.Attributes)...)
+}
+
+// AddConcreteColumnsDDL returns the statements adding attributes to an existing concrete table.
+// Columns are only ever added; a non-nullable column can only be added to an empty table.
+func AddConcreteColumnsDDL(tableName string, attributes []*models.ObjectTypeAttribute) []string {
+	var statements []string
+	for _, attribute := range attributes {
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", qualifiedTableName(tableName), columnDefinition(attribute)))
+	}
+	return append(statements, indexDDL(tableName, attributes)...)
+}
+
+func columnDefinition(attribute *models.ObjectTypeAttribute) string {
+	definition := quoteIdentifier(attribute.Name) + " " + attribute.ColumnType()
+	if !attribute.Nullable {
+		definition += " NOT NULL"
+	}
+	return definition
+}
+
+func indexDDL(tableName string, attributes []*models.ObjectTypeAttribute) []string {
+	var statements []string
+	for _, attribute := range attributes {
+		if !attribute.Indexed {
+			continue
+		}
+		statements = append(statements, fmt.Sprintf("CREATE INDEX %s ON %s (%s)",
+			quoteIdentifier(concreteIndexName(tableName, attribute.Name)), qualifiedTableName(tableName), quoteIdentifier(attribute.Name)))
+	}
+	return statements
+}
+
+// concreteIndexName names the index of a column, shortened with a hash suffix when the name would
+// exceed PostgreSQL's identifier limit
+func concreteIndexName(tableName, column string) string {
+	name := "idx_" + tableName + "_" + column
+	if len(name) <= 63 {
+		return name
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	return fmt.Sprintf("%s_%08x", name[:54], hash.Sum32())
+}
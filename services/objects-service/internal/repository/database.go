@@ -33,6 +33,11 @@ func (db *PGDatabase) Exec(ctx context.Context, sql string, args ...any) (Comman
 	return db.pool.Exec(ctx, sql, args...)
 }
 
+// CopyFrom implements Copier
+func (db *PGDatabase) CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, rows pgx.CopyFromSource) (int64, error) {
+	return db.pool.CopyFrom(ctx, table, columns, rows)
+}
+
 // BeginTx starts a new transaction (not part of DBInterface, but available for advanced use)
 func (db *PGDatabase) BeginTx(ctx context.Context) (pgx.Tx, error) {
 	return db.pool.Begin(ctx)
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
 // DBInterface defines the minimal database operations needed for testing (user-service pattern)
@@ -13,6 +15,11 @@ type DBInterface interface {
 	Exec(ctx context.Context, sql string, args ...any) (CommandTag, error)
 }
 
+// Copier is implemented by databases that can bulk-load rows with COPY
+type Copier interface {
+	CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, rows pgx.CopyFromSource) (int64, error)
+}
+
 // CommandTag represents the result of an Exec operation
 // TODO: - this is a different pattern compared with users-service: CommandTag in user-service
 // is a 'real' pgconn.CommandTag. There were issues when pgconn was imported here - need to resolve
@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/filterexpr"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+)
+
+// tagJoin lists an object once per tag, and once with a null tag when it has none
+const tagJoin = " LEFT JOIN LATERAL unnest(o.tags) AS tag(value) ON true"
+
+// dimensionKind is the SQL type of a dimension's values, which decides how they are scanned
+type dimensionKind int
+
+const (
+	dimensionInt dimensionKind = iota
+	dimensionText
+	dimensionJSON
+	dimensionTime
+)
+
+// dimension is a resolved grouping: the SQL expression of its values over objects aliased o.
+// Names are looked up in fixed tables and metadata paths are passed as arguments, so no part of
+// a request is spliced into SQL.
+type dimension struct {
+	name string
+	expr string
+	kind dimensionKind
+	tag  bool
+}
+
+var histogramColumns = map[string]string{
+	"created_at": "o.created_at",
+	"updated_at": "o.updated_at",
+}
+
+var histogramIntervals = map[string]bool{
+	models.IntervalHour:  true,
+	models.IntervalDay:   true,
+	models.IntervalWeek:  true,
+	models.IntervalMonth: true,
+	models.IntervalYear:  true,
+}
+
+var metricFunctions = map[string]string{
+	models.MetricSum: "SUM",
+	models.MetricAvg: "AVG",
+	models.MetricMin: "MIN",
+	models.MetricMax: "MAX",
+}
+
+// metadataPath splits a metadata.<path> field into the keys of the path
+func metadataPath(field string) ([]string, error) {
+	rest, ok := strings.CutPrefix(field, "metadata.")
+	if !ok {
+		return nil, fmt.Errorf("unknown field %q: %w", field, ErrInvalidInput)
+	}
+	path := strings.Split(rest, ".")
+	if len(path) > filterexpr.MaxPathDepth {
+		return nil, fmt.Errorf("metadata path %q is more than %d keys deep: %w", field, filterexpr.MaxPathDepth, ErrInvalidInput)
+	}
+	for _, key := range path {
+		if key == "" {
+			return nil, fmt.Errorf("metadata path %q has an empty key: %w", field, ErrInvalidInput)
+		}
+	}
+	return path, nil
+}
+
+// resolveDimension resolves a dimension name, adding the arguments its expression refers to
+func resolveDimension(name string, args []interface{}) (dimension, []interface{}, error) {
+	switch name {
+	case models.DimensionObjectType:
+		return dimension{name: name, expr: "o.object_type_id", kind: dimensionInt}, args, nil
+	case models.DimensionStatus:
+		return dimension{name: name, expr: "o.status", kind: dimensionText}, args, nil
+	case models.DimensionTag:
+		return dimension{name: name, expr: "tag.value", kind: dimensionText, tag: true}, args, nil
+	}
+
+	path, err := metadataPath(name)
+	if err != nil {
+		return dimension{}, nil, fmt.Errorf("unknown dimension %q: %w", name, ErrInvalidInput)
+	}
+	args = append(args, path)
+	return dimension{name: name, expr: fmt.Sprintf("o.metadata #> $%d::text[]", len(args)), kind: dimensionJSON}, args, nil
+}
+
+// resolveHistogram resolves a date histogram into a dimension named after its field
+func resolveHistogram(histogram *models.DateHistogram, args []interface{}) (dimension, []interface{}, error) {
+	column, ok := histogramColumns[histogram.Field]
+	if !ok {
+		return dimension{}, nil, fmt.Errorf("date histogram field must be created_at or updated_at: %w", ErrInvalidInput)
+	}
+	if !histogramIntervals[histogram.Interval] {
+		return dimension{}, nil, fmt.Errorf("unknown date histogram interval %q: %w", histogram.Interval, ErrInvalidInput)
+	}
+	args = append(args, histogram.Interval)
+	return dimension{name: histogram.Field, expr: fmt.Sprintf("date_trunc($%d, %s)", len(args), column), kind: dimensionTime}, args, nil
+}
+
+// resolveMetric resolves a metric into an aggregate over the numbers found at its path
+func resolveMetric(metric models.AggregationMetric, args []interface{}) (string, []interface{}, error) {
+	function, ok := metricFunctions[metric.Op]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown metric %q: %w", metric.Op, ErrInvalidInput)
+	}
+	path, err := metadataPath(metric.Field)
+	if err != nil {
+		return "", nil, err
+	}
+	args = append(args, path)
+	value := fmt.Sprintf("o.metadata #> $%d::text[]", len(args))
+	return fmt.Sprintf("%s(CASE WHEN jsonb_typeof(%s) = 'number' THEN (%s)::numeric END)::float8", function, value, value), args, nil
+}
+
+// holder returns a scan destination for a value of the dimension
+func (d dimension) holder() interface{} {
+	switch d.kind {
+	case dimensionInt:
+		return &sql.NullInt64{}
+	case dimensionJSON:
+		return &[]byte{}
+	case dimensionTime:
+		return &sql.NullTime{}
+	default:
+		return &sql.NullString{}
+	}
+}
+
+// value converts a scanned holder into the value reported for the dimension, nil for null
+func (d dimension) value(holder interface{}) (interface{}, error) {
+	switch h := holder.(type) {
+	case *sql.NullInt64:
+		if h.Valid {
+			return h.Int64, nil
+		}
+	case *sql.NullString:
+		if h.Valid {
+			return h.String, nil
+		}
+	case *sql.NullTime:
+		if h.Valid {
+			return h.Time, nil
+		}
+	case *[]byte:
+		if len(*h) > 0 {
+			var v interface{}
+			if err := json.Unmarshal(*h, &v); err != nil {
+				return nil, fmt.Errorf("failed to decode %s value: %w", d.name, err)
+			}
+			return v, nil
+		}
+	}
+	return nil, nil
+}
+
+// Aggregate counts the objects selected by the request's filter in buckets of its dimensions
+// and date histogram, with its metrics per bucket. It returns at most req.Size buckets.
+func (r *objectRepository) Aggregate(ctx context.Context, req *models.ObjectAggregationRequest) (*models.ObjectAggregationResult, error) {
+	r.metrics.QueryCount++
+
+	conditions, args, err := objectListConditions(&req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+	filterArgs := args[:len(args):len(args)]
+
+	var dimensions []dimension
+	seen := map[string]bool{}
+	join := ""
+	for _, name := range req.GroupBy {
+		if seen[name] {
+			return nil, fmt.Errorf("dimension %q is grouped by twice: %w", name, ErrInvalidInput)
+		}
+		seen[name] = true
+
+		var dim dimension
+		dim, args, err = resolveDimension(name, args)
+		if err != nil {
+			return nil, err
+		}
+		if dim.tag {
+			join = tagJoin
+		}
+		dimensions = append(dimensions, dim)
+	}
+	histogram := -1
+	if req.DateHistogram != nil {
+		var dim dimension
+		dim, args, err = resolveHistogram(req.DateHistogram, args)
+		if err != nil {
+			return nil, err
+		}
+		histogram = len(dimensions)
+		dimensions = append(dimensions, dim)
+	}
+	var metrics []string
+	for _, metric := range req.Metrics {
+		var expr string
+		expr, args, err = resolveMetric(metric, args)
+		if err != nil {
+			return nil, err
+		}
+		metrics = append(metrics, expr)
+	}
+
+	// The total counts objects, which the tag join would count once per tag
+	var total int64
+	err = r.db.QueryRow(ctx, "SELECT COUNT(*) FROM objects_service.objects o"+where, filterArgs...).Scan(&total)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to count aggregated objects: %w", err)
+	}
+
+	columns := make([]string, 0, len(dimensions)+1+len(metrics))
+	ordinals := make([]string, 0, len(dimensions))
+	for i, dim := range dimensions {
+		columns = append(columns, dim.expr)
+		ordinals = append(ordinals, fmt.Sprint(i+1))
+	}
+	columns = append(columns, "COUNT(*)")
+	columns = append(columns, metrics...)
+	countOrdinal := fmt.Sprint(len(dimensions) + 1)
+
+	query := "SELECT " + strings.Join(columns, ", ") + " FROM objects_service.objects o" + join + where
+	if len(ordinals) > 0 {
+		query += " GROUP BY " + strings.Join(ordinals, ", ")
+	}
+	if histogram >= 0 {
+		query += fmt.Sprintf(" ORDER BY %d, %s DESC", histogram+1, countOrdinal)
+	} else {
+		query += " ORDER BY " + strings.Join(append([]string{countOrdinal + " DESC"}, ordinals...), ", ")
+	}
+	args = append(args, req.Size+1)
+	query += fmt.Sprintf(" LIMIT $%d", len(args))
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to aggregate objects: %w", err)
+	}
+	defer rows.Close()
+
+	result := &models.ObjectAggregationResult{Buckets: []*models.AggregationBucket{}, Total: total}
+	for rows.Next() {
+		if len(result.Buckets) == req.Size {
+			result.Truncated = true
+			break
+		}
+
+		bucket := &models.AggregationBucket{Key: make(map[string]interface{}, len(dimensions))}
+		holders := make([]interface{}, 0, len(columns))
+		for _, dim := range dimensions {
+			holders = append(holders, dim.holder())
+		}
+		holders = append(holders, &bucket.Count)
+		values := make([]sql.NullFloat64, len(metrics))
+		for i := range values {
+			holders = append(holders, &values[i])
+		}
+
+		if err := rows.Scan(holders...); err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to scan aggregation bucket: %w", err)
+		}
+		for i, dim := range dimensions {
+			if bucket.Key[dim.name], err = dim.value(holders[i]); err != nil {
+				return nil, err
+			}
+		}
+		if len(metrics) > 0 {
+			bucket.Metrics = make(map[string]*float64, len(metrics))
+			for i, metric := range req.Metrics {
+				if values[i].Valid {
+					bucket.Metrics[metric.Name()] = &values[i].Float64
+				} else {
+					bucket.Metrics[metric.Name()] = nil
+				}
+			}
+		}
+		result.Buckets = append(result.Buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to read aggregation buckets: %w", err)
+	}
+
+	return result, nil
+}
+
+// searchFacets counts the hits of a search by each of the filter's facets, most frequent values
+// first
+func (r *objectRepository) searchFacets(ctx context.Context, mode searchMode, filter *models.ObjectSearchFilter) (map[string][]*models.FacetCount, error) {
+	facets := make(map[string][]*models.FacetCount, len(filter.Facets))
+	for _, name := range filter.Facets {
+		conditions, args := searchConditions(mode, filter)
+		dim, args, err := resolveDimension(name, args)
+		if err != nil {
+			return nil, err
+		}
+		join := ""
+		if dim.tag {
+			join = tagJoin
+		}
+		args = append(args, filter.FacetSize)
+
+		query := fmt.Sprintf("SELECT %s, COUNT(*) FROM objects_service.objects o%s WHERE %s GROUP BY 1 ORDER BY 2 DESC, 1 LIMIT $%d",
+			dim.expr, join, conditions, len(args))
+		counts, err := r.queryFacet(ctx, dim, query, args)
+		if err != nil {
+			return nil, err
+		}
+		facets[name] = counts
+	}
+	return facets, nil
+}
+
+func (r *objectRepository) queryFacet(ctx context.Context, dim dimension, query string, args []interface{}) ([]*models.FacetCount, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to count %s facet: %w", dim.name, err)
+	}
+	defer rows.Close()
+
+	counts := []*models.FacetCount{}
+	for rows.Next() {
+		holder := dim.holder()
+		count := &models.FacetCount{}
+		if err := rows.Scan(holder, &count.Count); err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to scan %s facet: %w", dim.name, err)
+		}
+		if count.Value, err = dim.value(holder); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to read %s facet: %w", dim.name, err)
+	}
+	return counts, nil
+}
+
+// ValidateDimension checks that objects can be grouped by a dimension
+func ValidateDimension(name string) error {
+	_, _, err := resolveDimension(name, nil)
+	return err
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+)
+
+type changeContextKey struct{}
+
+// ChangeContext describes who made a change and in which request; it is stored with each history entry
+type ChangeContext struct {
+	RequestID string
+	Actor     string
+	Action    string // overrides the recorded action of updates, e.g. models.HistoryActionRestore
+}
+
+// WithChangeContext attaches change details to ctx for the history recorded by repository writes
+func WithChangeContext(ctx context.Context, change ChangeContext) context.Context {
+	return context.WithValue(ctx, changeContextKey{}, change)
+}
+
+// ChangeContextFrom returns the change details attached to ctx, if any
+func ChangeContextFrom(ctx context.Context) ChangeContext {
+	change, _ := ctx.Value(changeContextKey{}).(ChangeContext)
+	return change
+}
+
+// historySources maps a history entity type to the table and key column its snapshots are taken from
+var historySources = map[string]struct{ table, key string }{
+	models.HistoryEntityObject:       {table: "objects_service.objects", key: "id"},
+	models.HistoryEntityRelationship: {table: "objects_service.objects_relationships", key: "object_id"},
+}
+
+// recordHistory snapshots the current rows of ids into objects_service.object_history, together with
+// the fields changed since each row's previous entry. Rows identical to their last snapshot are
+// skipped, so writes that matched nothing leave no entry. A delete is recorded even when the row
+// itself did not change, as hard-deleted relationships are snapshotted just before removal.
+func recordHistory(ctx context.Context, db DBInterface, entityType, action string, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	source, ok := historySources[entityType]
+	if !ok {
+		return fmt.Errorf("unknown history entity type %q", entityType)
+	}
+
+	change := ChangeContextFrom(ctx)
+	if change.Action != "" && action == models.HistoryActionUpdate {
+		action = change.Action
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO objects_service.object_history (
+			entity_type, object_id, revision, object_version, action,
+			snapshot, changes, updated_by, request_id
+		)
+		SELECT $1, s.id, COALESCE(prev.revision, 0) + 1, (s.snapshot ->> 'version')::bigint, $2,
+			s.snapshot,
+			COALESCE((
+				SELECT jsonb_object_agg(d.key, jsonb_build_object('old', prev.snapshot -> d.key, 'new', d.value))
+				FROM jsonb_each(s.snapshot) d
+				WHERE d.key NOT IN ('version', 'updated_at')
+					AND (prev.snapshot -> d.key) IS DISTINCT FROM d.value
+			), '{}'::jsonb),
+			COALESCE(NULLIF($4, ''), s.snapshot ->> 'updated_by', 'system'), NULLIF($5, '')
+		FROM (
+			SELECT t.%[2]s AS id, to_jsonb(t) - 'search_vector' AS snapshot
+			FROM %[1]s t
+			WHERE t.%[2]s = ANY($3::bigint[])
+		) s
+		LEFT JOIN LATERAL (
+			SELECT h.revision, h.action, h.snapshot
+			FROM objects_service.object_history h
+			WHERE h.entity_type = $1 AND h.object_id = s.id
+			ORDER BY h.revision DESC
+			LIMIT 1
+		) prev ON true
+		WHERE prev.snapshot IS DISTINCT FROM s.snapshot
+			OR ($2 = 'delete' AND prev.action IS DISTINCT FROM 'delete')`,
+		source.table, source.key)
+
+	if _, err := db.Exec(ctx, query, entityType, action, ids, change.Actor, change.RequestID); err != nil {
+		return fmt.Errorf("failed to record %s history: %w", entityType, err)
+	}
+	return nil
+}
+
+func objectIDs(objects []*models.Object) []int64 {
+	ids := make([]int64, 0, len(objects))
+	for _, object := range objects {
+		ids = append(ids, object.ID)
+	}
+	return ids
+}
+
+const historyColumns = `id, entity_type, object_id, revision, object_version, action,
+			snapshot, changes, updated_by, request_id, recorded_at`
+
+func scanObjectVersion(row Row, withSnapshot bool) (*models.ObjectVersion, error) {
+	var version models.ObjectVersion
+	var snapshot, changes []byte
+
+	err := row.Scan(
+		&version.ID, &version.EntityType, &version.ObjectID, &version.Revision, &version.ObjectVersion, &version.Action,
+		&snapshot, &changes, &version.UpdatedBy, &version.RequestID, &version.RecordedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if withSnapshot {
+		version.Snapshot = snapshot
+	}
+	version.Changes = map[string]models.FieldChange{}
+	if len(changes) > 0 {
+		if err := json.Unmarshal(changes, &version.Changes); err != nil {
+			return nil, fmt.Errorf("failed to decode history changes: %w", err)
+		}
+	}
+	return &version, nil
+}
+
+// ListVersions returns an object's history, newest first, without snapshots
+func (r *objectRepository) ListVersions(ctx context.Context, objectID int64, filter *models.ObjectVersionFilter) ([]*models.ObjectVersion, int64, error) {
+	r.metrics.QueryCount++
+
+	var total int64
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM objects_service.object_history
+		WHERE entity_type = $1 AND object_id = $2`,
+		filter.EntityType, objectID,
+	).Scan(&total)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, 0, fmt.Errorf("failed to count object versions: %w", err)
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+historyColumns+`
+		FROM objects_service.object_history
+		WHERE entity_type = $1 AND object_id = $2
+		ORDER BY revision DESC
+		LIMIT $3 OFFSET $4`,
+		filter.EntityType, objectID, filter.Limit, filter.Offset,
+	)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, 0, fmt.Errorf("failed to list object versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []*models.ObjectVersion{}
+	for rows.Next() {
+		version, err := scanObjectVersion(rows, false)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return nil, 0, fmt.Errorf("failed to scan object version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		r.metrics.ErrorCount++
+		return nil, 0, fmt.Errorf("failed to list object versions: %w", err)
+	}
+
+	return versions, total, nil
+}
+
+// GetVersion returns one revision of an object's history, including its snapshot
+func (r *objectRepository) GetVersion(ctx context.Context, entityType string, objectID, revision int64) (*models.ObjectVersion, error) {
+	r.metrics.QueryCount++
+
+	version, err := scanObjectVersion(r.db.QueryRow(ctx, `
+		SELECT `+historyColumns+`
+		FROM objects_service.object_history
+		WHERE entity_type = $1 AND object_id = $2 AND revision = $3`,
+		entityType, objectID, revision,
+	), true)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to get object version: %w", err)
+	}
+	return version, nil
+}
+
+// GetVersionAsOf returns the last history entry of an object recorded at or before at
+func (r *objectRepository) GetVersionAsOf(ctx context.Context, entityType string, objectID int64, at time.Time) (*models.ObjectVersion, error) {
+	r.metrics.QueryCount++
+
+	version, err := scanObjectVersion(r.db.QueryRow(ctx, `
+		SELECT `+historyColumns+`
+		FROM objects_service.object_history
+		WHERE entity_type = $1 AND object_id = $2 AND recorded_at <= $3
+		ORDER BY revision DESC
+		LIMIT 1`,
+		entityType, objectID, at,
+	), true)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to get object version: %w", err)
+	}
+	return version, nil
+}
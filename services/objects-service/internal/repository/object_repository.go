@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/filterexpr"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
 )
 
@@ -28,7 +29,7 @@ type ObjectRepository interface {
 
 	// Advanced querying operations
 	List(ctx context.Context, filter *models.ObjectFilter) ([]*models.Object, int64, error)
-	Search(ctx context.Context, query string, limit int) ([]*models.Object, error)
+	Search(ctx context.Context, filter *models.ObjectSearchFilter) (*models.ObjectSearchResults, error)
 
 	// Metadata and tag operations
 	FindByMetadata(ctx context.Context, key, value string) ([]*models.Object, error)
@@ -42,10 +43,11 @@ type ObjectRepository interface {
 	GetDescendants(ctx context.Context, rootID int64, maxDepth *int) ([]*models.Object, error)
 	GetAncestors(ctx context.Context, id int64) ([]*models.Object, error)
 	GetPath(ctx context.Context, id int64) ([]*models.Object, error)
+	Move(ctx context.Context, id int64, parentID *int64, objectTypeID int64, updatedBy string) (*models.Object, error)
 
 	// Bulk operations
 	BulkCreate(ctx context.Context, objects []*models.CreateObjectRequest) ([]*models.Object, error)
-	BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest) ([]*models.Object, error)
+	BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest, expectedVersions map[int64]int64) ([]*models.Object, error)
 	BulkDelete(ctx context.Context, ids []int64) error
 
 	// Business logic and validation
@@ -53,6 +55,28 @@ type ObjectRepository interface {
 	ValidateParentChild(ctx context.Context, parentID, childID int64) error
 	CanDelete(ctx context.Context, id int64) (bool, error)
 	GetObjectStats(ctx context.Context, filter *models.ObjectFilter) (*ObjectStats, error)
+
+	// Aggregations over the objects a filter selects
+	Aggregate(ctx context.Context, req *models.ObjectAggregationRequest) (*models.ObjectAggregationResult, error)
+
+	// Trash bin: soft-deleted objects, which every other read leaves out
+	GetByIDWithDeleted(ctx context.Context, id int64) (*models.Object, error)
+	ListDeleted(ctx context.Context, filter *models.TrashFilter) ([]*models.Object, int64, error)
+	GetDeletedDescendants(ctx context.Context, rootID int64) ([]*models.Object, error)
+	ListExpired(ctx context.Context, deletedBefore time.Time, limit int) ([]*models.Object, error)
+	Restore(ctx context.Context, id int64, updatedBy string) (*models.Object, error)
+	Purge(ctx context.Context, ids []int64) error
+
+	// Streaming export and import. ExportObjects reads through a cursor and needs a transaction.
+	ExportObjects(ctx context.Context, filter *models.ObjectFilter, batchSize int, fn func([]*models.ObjectRecord) error) error
+	FindByKeys(ctx context.Context, publicIDs []uuid.UUID, externalKeys []string) (map[uuid.UUID]*models.Object, map[string]*models.Object, error)
+	CopyObjects(ctx context.Context, inputs []*models.ImportedObject) ([]*models.Object, error)
+	UpdateImportedObjects(ctx context.Context, inputs []*models.ImportedObject) ([]*models.Object, error)
+
+	// Version history
+	ListVersions(ctx context.Context, objectID int64, filter *models.ObjectVersionFilter) ([]*models.ObjectVersion, int64, error)
+	GetVersion(ctx context.Context, entityType string, objectID, revision int64) (*models.ObjectVersion, error)
+	GetVersionAsOf(ctx context.Context, entityType string, objectID int64, at time.Time) (*models.ObjectVersion, error)
 }
 
 // ObjectStats contains statistics about objects
@@ -66,9 +90,10 @@ type ObjectStats struct {
 
 // objectRepository implements ObjectRepository with performance focus (no eager loading)
 type objectRepository struct {
-	db      DBInterface
-	options *RepositoryOptions
-	metrics *RepositoryMetrics
+	db       DBInterface
+	options  *RepositoryOptions
+	metrics  *RepositoryMetrics
+	concrete ConcreteTableRepository
 }
 
 // NewObjectRepository creates a new ObjectRepository instance
@@ -78,9 +103,10 @@ func NewObjectRepository(db DBInterface, options *RepositoryOptions) ObjectRepos
 	}
 
 	return &objectRepository{
-		db:      db,
-		options: options,
-		metrics: &RepositoryMetrics{LastResetAt: time.Now()},
+		db:       db,
+		options:  options,
+		metrics:  &RepositoryMetrics{LastResetAt: time.Now()},
+		concrete: NewConcreteTableRepository(db, options),
 	}
 }
 
@@ -173,6 +199,20 @@ func (r *objectRepository) Create(ctx context.Context, input *models.CreateObjec
 		return nil, fmt.Errorf("failed to create object: %w", err)
 	}
 
+	if err := r.concrete.WriteAttributes(ctx, object.ID, object.ObjectTypeID, nil, input.Attributes); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+	if err := r.concrete.LoadAttributes(ctx, []*models.Object{&object}); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionCreate, []int64{object.ID}); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
 	// Load ObjectType for eager loading (as planned)
 	if objectType, err := r.getObjectTypeByID(ctx, object.ObjectTypeID); err == nil {
 		object.ObjectType = objectType
@@ -181,8 +221,17 @@ func (r *objectRepository) Create(ctx context.Context, input *models.CreateObjec
 	return &object, nil
 }
 
-// GetByID retrieves an object by ID with minimal eager loading
+// GetByID retrieves a live object by ID with minimal eager loading
 func (r *objectRepository) GetByID(ctx context.Context, id int64) (*models.Object, error) {
+	return r.getByID(ctx, id, false)
+}
+
+// GetByIDWithDeleted retrieves an object by ID whether or not it is in the trash
+func (r *objectRepository) GetByIDWithDeleted(ctx context.Context, id int64) (*models.Object, error) {
+	return r.getByID(ctx, id, true)
+}
+
+func (r *objectRepository) getByID(ctx context.Context, id int64, withDeleted bool) (*models.Object, error) {
 
 	r.metrics.QueryCount++
 
@@ -192,6 +241,9 @@ func (r *objectRepository) GetByID(ctx context.Context, id int64) (*models.Objec
 			   created_at, updated_at, deleted_at
 		FROM objects_service.objects
 		WHERE id = $1`
+	if !withDeleted {
+		query += " AND deleted_at IS NULL"
+	}
 
 	var object models.Object
 	var parentObjectID sql.NullInt64
@@ -207,6 +259,9 @@ func (r *objectRepository) GetByID(ctx context.Context, id int64) (*models.Objec
 
 	if err != nil {
 		r.metrics.ErrorCount++
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
 		return nil, fmt.Errorf("failed to get object: %w", err)
 	}
 
@@ -221,6 +276,11 @@ func (r *objectRepository) GetByID(ctx context.Context, id int64) (*models.Objec
 		object.DeletedAt = &deletedAt.Time
 	}
 
+	if err := r.concrete.LoadAttributes(ctx, []*models.Object{&object}); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
 	// Load ObjectType for eager loading (as planned)
 	if objectType, err := r.getObjectTypeByID(ctx, object.ObjectTypeID); err == nil {
 		object.ObjectType = objectType
@@ -234,7 +294,7 @@ func (r *objectRepository) GetByPublicID(ctx context.Context, publicID uuid.UUID
 
 	r.metrics.QueryCount++
 
-	query := `SELECT id FROM objects_service.objects WHERE public_id = $1`
+	query := `SELECT id FROM objects_service.objects WHERE public_id = $1 AND deleted_at IS NULL`
 	var id int64
 	err := r.db.QueryRow(ctx, query, publicID).Scan(&id)
 	if err != nil {
@@ -353,7 +413,7 @@ func (r *objectRepository) Update(ctx context.Context, id int64, input *models.U
 		argIndex++
 	}
 
-	if len(setClauses) == 0 {
+	if len(setClauses) == 0 && input.Attributes == nil {
 		return current, nil // No changes
 	}
 
@@ -368,30 +428,100 @@ func (r *objectRepository) Update(ctx context.Context, id int64, input *models.U
 	}
 	argIndex++
 
+	pinned, pinnedArgs := pinnedCondition(ctx, preconditionObject, id, argIndex+2)
 	query := fmt.Sprintf(`
 		UPDATE objects_service.objects 
 		SET %s 
-		WHERE id = $%d AND deleted_at IS NULL AND version = $%d
+		WHERE id = $%d AND deleted_at IS NULL AND version = $%d%s
 		RETURNING updated_at, version`,
 		strings.Join(setClauses, ", "),
 		argIndex,
 		argIndex+1,
+		pinned,
 	)
 
 	args = append(args, id, current.Version)
+	args = append(args, pinnedArgs...)
 
 	var updatedAt sql.NullTime
 	var newVersion int64
 	err = r.db.QueryRow(ctx, query, args...).Scan(&updatedAt, &newVersion)
 	if err != nil {
 		r.metrics.ErrorCount++
+		if errors.Is(err, sql.ErrNoRows) {
+			// Another writer bumped the version between the read above and this update
+			return nil, ErrOptimisticLock
+		}
 		return nil, fmt.Errorf("failed to update object: %w", err)
 	}
 
+	objectTypeID := current.ObjectTypeID
+	if input.ObjectTypeID != nil {
+		objectTypeID = *input.ObjectTypeID
+	}
+	if err := r.concrete.WriteAttributes(ctx, id, objectTypeID, &current.ObjectTypeID, input.Attributes); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionUpdate, []int64{id}); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
 	// Return updated object
 	return r.GetByID(ctx, id)
 }
 
+// Move sets the parent and type of an object; a nil parent makes it a root. Unlike Update it can
+// detach an object from its parent. Attribute rows follow the concrete tables of the new type.
+func (r *objectRepository) Move(ctx context.Context, id int64, parentID *int64, objectTypeID int64, updatedBy string) (*models.Object, error) {
+	r.metrics.QueryCount++
+
+	current, err := r.GetByID(ctx, id)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
+	if updatedBy == "" {
+		updatedBy = "system"
+	}
+
+	pinned, pinnedArgs := pinnedCondition(ctx, preconditionObject, id, 5)
+	query := `
+		UPDATE objects_service.objects
+		SET parent_object_id = $1, object_type_id = $2, version = version + 1,
+			updated_at = CURRENT_TIMESTAMP, updated_by = $3
+		WHERE id = $4 AND deleted_at IS NULL` + pinned + `
+		RETURNING version`
+
+	var version int64
+	args := append([]interface{}{parentID, objectTypeID, updatedBy, id}, pinnedArgs...)
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&version); err != nil {
+		r.metrics.ErrorCount++
+		if errors.Is(err, sql.ErrNoRows) {
+			if pinned != "" {
+				return nil, ErrOptimisticLock
+			}
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to move object: %w", err)
+	}
+
+	if err := r.concrete.WriteAttributes(ctx, id, objectTypeID, &current.ObjectTypeID, nil); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionUpdate, []int64{id}); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
+	return r.GetByID(ctx, id)
+}
+
 // Delete soft-deletes an object
 func (r *objectRepository) Delete(ctx context.Context, id int64) error {
 
@@ -412,12 +542,25 @@ func (r *objectRepository) Delete(ctx context.Context, id int64) error {
 		SET deleted_at = CURRENT_TIMESTAMP, status = 'deleted', updated_at = CURRENT_TIMESTAMP
 		WHERE id = $1 AND deleted_at IS NULL`
 
-	_, err = r.db.Exec(ctx, query, id)
+	err = execPinned(ctx, r.db, preconditionObject, id, query, id)
+	if errors.Is(err, ErrOptimisticLock) {
+		return err
+	}
 	if err != nil {
 		r.metrics.ErrorCount++
 		return fmt.Errorf("failed to delete object: %w", err)
 	}
 
+	if err := r.trashRelationships(ctx, []int64{id}); err != nil {
+		r.metrics.ErrorCount++
+		return err
+	}
+
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionDelete, []int64{id}); err != nil {
+		r.metrics.ErrorCount++
+		return err
+	}
+
 	return nil
 }
 
@@ -426,82 +569,53 @@ func (r *objectRepository) List(ctx context.Context, filter *models.ObjectFilter
 
 	r.metrics.QueryCount++
 
-	qb := NewQueryBuilder()
-	qb.Select(
-		"id", "public_id", "object_type_id", "parent_object_id", "name", "description",
-		"metadata", "tags", "status", "version", "created_by", "updated_by",
-		"created_at", "updated_at", "deleted_at",
-	).From("objects_service.objects")
-
-	// Apply filters
-	if filter.Name != "" {
-		qb.Where("name ILIKE $1", fmt.Sprintf("%%%s%%", filter.Name))
-	}
-
-	if filter.ObjectTypeID != nil {
-		qb.Where("object_type_id = $1", *filter.ObjectTypeID)
-	}
-
-	if filter.ParentObjectID != nil {
-		qb.Where("parent_object_id = $1", *filter.ParentObjectID)
-	}
-
-	if filter.Status != "" {
-		qb.Where("status = $1", filter.Status)
-	}
-
-	if len(filter.Tags) > 0 {
-		qb.WhereTagsContain(filter.Tags)
-	}
-
-	if filter.CreatedAfter != nil {
-		qb.WhereDateRange("created_at", *filter.CreatedAfter, time.Time{})
-	}
-
-	if filter.CreatedBefore != nil {
-		qb.WhereDateRange("created_at", time.Time{}, *filter.CreatedBefore)
+	conditions, args, err := objectListConditions(filter)
+	if err != nil {
+		return nil, 0, err
 	}
-
-	if filter.MetadataKey != "" && filter.MetadataValue != "" {
-		qb.WhereJsonContains("metadata", map[string]interface{}{
-			filter.MetadataKey: filter.MetadataValue,
-		})
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	// Always exclude deleted objects unless explicitly requested
-	if filter.Status != models.StatusDeleted {
-		qb.Where("deleted_at IS NULL")
+	// Column names cannot be bound as arguments, so sort_by must name a whitelisted column
+	orderBy := "created_at DESC"
+	if filter.SortBy != "" {
+		if !objectSortColumns[filter.SortBy] {
+			return nil, 0, fmt.Errorf("cannot sort by %q: %w", filter.SortBy, ErrInvalidInput)
+		}
+		orderDir := "ASC"
+		if filter.SortOrder == "desc" {
+			orderDir = "DESC"
+		}
+		orderBy = filter.SortBy + " " + orderDir
 	}
 
 	// Get total count first
-	countQuery, countArgs := qb.BuildCount()
 	var total int64
-	err := r.db.QueryRow(ctx, countQuery, countArgs...).Scan(&total)
+	err = r.db.QueryRow(ctx, "SELECT COUNT(*) FROM objects_service.objects"+where, args...).Scan(&total)
 	if err != nil {
 		r.metrics.ErrorCount++
 		return nil, 0, fmt.Errorf("failed to count objects: %w", err)
 	}
 
-	// Apply sorting
-	if filter.SortBy != "" {
-		orderDir := "ASC"
-		if filter.SortOrder == "desc" {
-			orderDir = "DESC"
-		}
-		qb.OrderBy(fmt.Sprintf("%s %s", filter.SortBy, orderDir))
-	} else {
-		qb.OrderByDesc("created_at")
-	}
+	query := `
+		SELECT id, public_id, object_type_id, parent_object_id, name, description,
+		       metadata, tags, status, version, created_by, updated_by,
+		       created_at, updated_at, deleted_at
+		FROM objects_service.objects` + where + `
+		ORDER BY ` + orderBy
 
 	// Apply pagination
 	if filter.Limit > 0 {
-		qb.Limit(filter.Limit)
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
 	}
 	if filter.Offset > 0 {
-		qb.Offset(filter.Offset)
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
 	}
 
-	query, args := qb.Build()
 	rows, err := r.db.Query(ctx, query, args...)
 	if err != nil {
 		r.metrics.ErrorCount++
@@ -539,11 +653,86 @@ func (r *objectRepository) List(ctx context.Context, filter *models.ObjectFilter
 	// Load ObjectType for all objects if needed
 	if len(objects) > 0 {
 		r.loadObjectTypesForObjects(ctx, objects)
+		if err := r.concrete.LoadAttributes(ctx, objects); err != nil {
+			r.metrics.ErrorCount++
+			return nil, 0, err
+		}
 	}
 
 	return objects, total, nil
 }
 
+// objectSortColumns lists the columns List accepts in sort_by
+var objectSortColumns = map[string]bool{
+	"id": true, "name": true, "status": true, "version": true,
+	"created_at": true, "updated_at": true,
+}
+
+// objectListConditions builds the WHERE conditions for List, numbering placeholders in the
+// order the arguments are returned
+func objectListConditions(filter *models.ObjectFilter) ([]string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Name != "" {
+		args = append(args, "%"+filter.Name+"%")
+		conditions = append(conditions, fmt.Sprintf("name ILIKE $%d", len(args)))
+	}
+
+	if filter.ObjectTypeID != nil {
+		args = append(args, *filter.ObjectTypeID)
+		conditions = append(conditions, fmt.Sprintf("object_type_id = $%d", len(args)))
+	}
+
+	if filter.ParentObjectID != nil {
+		args = append(args, *filter.ParentObjectID)
+		conditions = append(conditions, fmt.Sprintf("parent_object_id = $%d", len(args)))
+	}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	if len(filter.Tags) > 0 {
+		args = append(args, filter.Tags)
+		conditions = append(conditions, fmt.Sprintf("tags @> $%d", len(args)))
+	}
+
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		conditions = append(conditions, fmt.Sprintf("created_at <= $%d", len(args)))
+	}
+
+	if filter.MetadataKey != "" && filter.MetadataValue != "" {
+		contains, err := json.Marshal(map[string]interface{}{filter.MetadataKey: filter.MetadataValue})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to encode metadata filter: %w", err)
+		}
+		args = append(args, string(contains))
+		conditions = append(conditions, fmt.Sprintf("metadata @> $%d::jsonb", len(args)))
+	}
+
+	if filter.Filter != "" {
+		condition, filterArgs, err := filterexpr.Compile(filter.Filter, args)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid filter: %w: %w", ErrInvalidInput, err)
+		}
+		args = filterArgs
+		conditions = append(conditions, condition)
+	}
+
+	// Deleted objects are listed by ListDeleted only
+	conditions = append(conditions, "deleted_at IS NULL")
+
+	return conditions, args, nil
+}
+
 // Helper methods
 
 func (r *objectRepository) validateParent(ctx context.Context, parentID int64) error {
@@ -653,67 +842,6 @@ func (r *objectRepository) loadObjectTypesForObjects(ctx context.Context, object
 	}
 }
 
-func (r *objectRepository) Search(ctx context.Context, searchQuery string, limit int) ([]*models.Object, error) {
-	r.metrics.QueryCount++
-
-	if limit <= 0 {
-		limit = 50
-	}
-
-	query := `
-		SELECT id, public_id, object_type_id, parent_object_id, name, description,
-			   metadata, tags, status, version, created_by, updated_by,
-			   created_at, updated_at, deleted_at
-		FROM objects_service.objects
-		WHERE deleted_at IS NULL
-		  AND (name ILIKE $1 OR description ILIKE $1 OR tags @> $2::text[])
-		ORDER BY
-			CASE WHEN name ILIKE $1 THEN 0 ELSE 1 END,
-			created_at DESC
-		LIMIT $3`
-
-	pattern := fmt.Sprintf("%%%s%%", searchQuery)
-	rows, err := r.db.Query(ctx, query, pattern, []string{searchQuery}, limit)
-	if err != nil {
-		r.metrics.ErrorCount++
-		return nil, fmt.Errorf("failed to search objects: %w", err)
-	}
-	defer rows.Close()
-
-	var objects []*models.Object
-	for rows.Next() {
-		var object models.Object
-		var parentObjectID sql.NullInt64
-		var deletedAt sql.NullTime
-
-		err := rows.Scan(
-			&object.ID, &object.PublicID, &object.ObjectTypeID, &parentObjectID,
-			&object.Name, &object.Description, &object.Metadata, &object.Tags,
-			&object.Status, &object.Version, &object.CreatedBy, &object.UpdatedBy,
-			&object.CreatedAt, &object.UpdatedAt, &deletedAt,
-		)
-		if err != nil {
-			r.metrics.ErrorCount++
-			return nil, fmt.Errorf("failed to scan object row: %w", err)
-		}
-
-		if parentObjectID.Valid {
-			object.ParentObjectID = &parentObjectID.Int64
-		}
-		if deletedAt.Valid {
-			object.DeletedAt = &deletedAt.Time
-		}
-
-		objects = append(objects, &object)
-	}
-
-	if len(objects) > 0 {
-		r.loadObjectTypesForObjects(ctx, objects)
-	}
-
-	return objects, nil
-}
-
 func (r *objectRepository) FindByMetadata(ctx context.Context, key, value string) ([]*models.Object, error) {
 	r.metrics.QueryCount++
 
@@ -860,12 +988,20 @@ func (r *objectRepository) UpdateMetadata(ctx context.Context, id int64, metadat
 		SET metadata = $1, updated_at = CURRENT_TIMESTAMP, updated_by = $3
 		WHERE id = $2 AND deleted_at IS NULL`
 
-	_, err = r.db.Exec(ctx, query, metadataJSON, id, userToSet)
+	err = execPinned(ctx, r.db, preconditionObject, id, query, metadataJSON, id, userToSet)
+	if errors.Is(err, ErrOptimisticLock) {
+		return err
+	}
 	if err != nil {
 		r.metrics.ErrorCount++
 		return fmt.Errorf("failed to update metadata: %w", err)
 	}
 
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionUpdate, []int64{id}); err != nil {
+		r.metrics.ErrorCount++
+		return err
+	}
+
 	return nil
 }
 
@@ -886,12 +1022,20 @@ func (r *objectRepository) AddTags(ctx context.Context, id int64, tags []string,
 		SET tags = tags || $1::text[], updated_at = CURRENT_TIMESTAMP, updated_by = $3
 		WHERE id = $2 AND deleted_at IS NULL`
 
-	_, err := r.db.Exec(ctx, query, tags, id, userToSet)
+	err := execPinned(ctx, r.db, preconditionObject, id, query, tags, id, userToSet)
+	if errors.Is(err, ErrOptimisticLock) {
+		return err
+	}
 	if err != nil {
 		r.metrics.ErrorCount++
 		return fmt.Errorf("failed to add tags: %w", err)
 	}
 
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionUpdate, []int64{id}); err != nil {
+		r.metrics.ErrorCount++
+		return err
+	}
+
 	return nil
 }
 
@@ -912,12 +1056,20 @@ func (r *objectRepository) RemoveTags(ctx context.Context, id int64, tags []stri
 		SET tags = array_remove(tags, $1), updated_at = CURRENT_TIMESTAMP, updated_by = $3
 		WHERE id = $2 AND deleted_at IS NULL`
 
-	_, err := r.db.Exec(ctx, query, tags[0], id, userToSet)
+	err := execPinned(ctx, r.db, preconditionObject, id, query, tags[0], id, userToSet)
+	if errors.Is(err, ErrOptimisticLock) {
+		return err
+	}
 	if err != nil {
 		r.metrics.ErrorCount++
 		return fmt.Errorf("failed to remove tags: %w", err)
 	}
 
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionUpdate, []int64{id}); err != nil {
+		r.metrics.ErrorCount++
+		return err
+	}
+
 	return nil
 }
 
@@ -1245,14 +1397,34 @@ func (r *objectRepository) BulkCreate(ctx context.Context, inputs []*models.Crea
 		objects = append(objects, &object)
 	}
 
+	// Rows are returned in insertion order, so each object pairs with its input
+	for i, object := range objects {
+		if err := r.concrete.WriteAttributes(ctx, object.ID, object.ObjectTypeID, nil, inputs[i].Attributes); err != nil {
+			r.metrics.ErrorCount++
+			return nil, err
+		}
+	}
+
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionCreate, objectIDs(objects)); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
 	if len(objects) > 0 {
 		r.loadObjectTypesForObjects(ctx, objects)
+		if err := r.concrete.LoadAttributes(ctx, objects); err != nil {
+			r.metrics.ErrorCount++
+			return nil, err
+		}
 	}
 
 	return objects, nil
 }
 
-func (r *objectRepository) BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest) ([]*models.Object, error) {
+// BulkUpdate applies the same changes to all listed objects. Objects with an entry in
+// expectedVersions are only updated while their version still matches it; the others are
+// left out of the result.
+func (r *objectRepository) BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest, expectedVersions map[int64]int64) ([]*models.Object, error) {
 	r.metrics.QueryCount++
 
 	if len(ids) == 0 {
@@ -1302,7 +1474,7 @@ func (r *objectRepository) BulkUpdate(ctx context.Context, ids []int64, updates
 		argIndex++
 	}
 
-	if len(setClauses) == 0 {
+	if len(setClauses) == 0 && updates.Attributes == nil {
 		return []*models.Object{}, nil
 	}
 
@@ -1314,16 +1486,34 @@ func (r *objectRepository) BulkUpdate(ctx context.Context, ids []int64, updates
 
 	idArray := fmt.Sprintf("$%d", argIndex)
 	args = append(args, ids)
+	argIndex++
+
+	versionGuard := ""
+	if len(expectedVersions) > 0 {
+		guardIDs := make([]int64, 0, len(expectedVersions))
+		guardVersions := make([]int64, 0, len(expectedVersions))
+		for id, version := range expectedVersions {
+			guardIDs = append(guardIDs, id)
+			guardVersions = append(guardVersions, version)
+		}
+		versionGuard = fmt.Sprintf(`
+		  AND NOT EXISTS (
+			SELECT 1 FROM unnest($%d::bigint[], $%d::bigint[]) AS ev(id, version)
+			WHERE ev.id = objects.id AND ev.version <> objects.version
+		  )`, argIndex, argIndex+1)
+		args = append(args, guardIDs, guardVersions)
+	}
 
 	query := fmt.Sprintf(`
 		UPDATE objects_service.objects
 		SET %s
-		WHERE id = ANY(%s::bigint[]) AND deleted_at IS NULL
+		WHERE id = ANY(%s::bigint[]) AND deleted_at IS NULL%s
 		RETURNING id, public_id, object_type_id, parent_object_id, name, description,
 				  metadata, tags, status, version, created_by, updated_by,
 				  created_at, updated_at, deleted_at`,
 		strings.Join(setClauses, ", "),
 		idArray,
+		versionGuard,
 	)
 
 	rows, err := r.db.Query(ctx, query, args...)
@@ -1360,8 +1550,24 @@ func (r *objectRepository) BulkUpdate(ctx context.Context, ids []int64, updates
 		objects = append(objects, &object)
 	}
 
+	for _, object := range objects {
+		if err := r.concrete.WriteAttributes(ctx, object.ID, object.ObjectTypeID, &object.ObjectTypeID, updates.Attributes); err != nil {
+			r.metrics.ErrorCount++
+			return nil, err
+		}
+	}
+
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionUpdate, objectIDs(objects)); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
 	if len(objects) > 0 {
 		r.loadObjectTypesForObjects(ctx, objects)
+		if err := r.concrete.LoadAttributes(ctx, objects); err != nil {
+			r.metrics.ErrorCount++
+			return nil, err
+		}
 	}
 
 	return objects, nil
@@ -1386,6 +1592,16 @@ func (r *objectRepository) BulkDelete(ctx context.Context, ids []int64) error {
 		return fmt.Errorf("failed to bulk delete objects: %w", err)
 	}
 
+	if err := r.trashRelationships(ctx, ids); err != nil {
+		r.metrics.ErrorCount++
+		return err
+	}
+
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionDelete, ids); err != nil {
+		r.metrics.ErrorCount++
+		return err
+	}
+
 	return nil
 }
 
@@ -1446,10 +1662,7 @@ func (r *objectRepository) GetObjectStats(ctx context.Context, filter *models.Ob
 	}
 
 	baseQuery := "FROM objects_service.objects"
-	whereClauses := []string{}
-	if filter == nil || filter.Status != models.StatusDeleted {
-		whereClauses = append(whereClauses, "deleted_at IS NULL")
-	}
+	whereClauses := []string{"deleted_at IS NULL"}
 
 	if filter != nil && filter.ObjectTypeID != nil {
 		whereClauses = append(whereClauses, "object_type_id = $1")
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+)
+
+// searchHeadlineOptions configures the highlighted snippets of full-text results
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=10, MaxFragments=2"
+
+// searchMode holds the SQL that differs between full-text and fuzzy matching; $1 is always the query
+type searchMode struct {
+	matchType string
+	condition string
+	rank      string
+	snippet   string
+}
+
+var (
+	fullTextSearch = searchMode{
+		matchType: models.SearchMatchFullText,
+		condition: "o.search_vector @@ websearch_to_tsquery('english', $1)",
+		rank:      "ts_rank_cd(o.search_vector, websearch_to_tsquery('english', $1))::float8",
+		snippet: `ts_headline('english',
+				concat_ws(' ', h.name, h.description, array_to_string(h.tags, ' '),
+					objects_service.object_search_metadata_text(h.metadata, h.object_type_id)),
+				websearch_to_tsquery('english', $1), '` + searchHeadlineOptions + `')`,
+	}
+	fuzzySearch = searchMode{
+		matchType: models.SearchMatchFuzzy,
+		condition: "(o.name % $1 OR $1 <% o.name)",
+		rank:      "GREATEST(similarity(o.name, $1), word_similarity($1, o.name))::float8",
+		snippet:   "''",
+	}
+)
+
+// Search ranks objects against a full-text query over name, tags, description and the metadata
+// keys configured on their type. When the query matches nothing, names are matched by trigram
+// similarity instead so that misspelled queries still find something. Facets count the hits of
+// whichever matching was used.
+func (r *objectRepository) Search(ctx context.Context, filter *models.ObjectSearchFilter) (*models.ObjectSearchResults, error) {
+	r.metrics.QueryCount++
+
+	mode := fullTextSearch
+	total, err := r.countSearchMatches(ctx, mode, filter)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		mode = fuzzySearch
+		if total, err = r.countSearchMatches(ctx, mode, filter); err != nil {
+			return nil, err
+		}
+	}
+
+	results := &models.ObjectSearchResults{
+		Results:   []*models.ObjectSearchResult{},
+		Total:     total,
+		MatchType: mode.matchType,
+	}
+	if len(filter.Facets) > 0 {
+		if results.Facets, err = r.searchFacets(ctx, mode, filter); err != nil {
+			return nil, err
+		}
+	}
+	if total == 0 {
+		return results, nil
+	}
+
+	conditions, args := searchConditions(mode, filter)
+	args = append(args, filter.Limit, filter.Offset)
+
+	query := fmt.Sprintf(`
+		WITH hits AS (
+			SELECT o.id, o.public_id, o.object_type_id, o.parent_object_id, o.name, o.description,
+				   o.metadata, o.tags, o.status, o.version, o.created_by, o.updated_by,
+				   o.created_at, o.updated_at, %s AS rank
+			FROM objects_service.objects o
+			WHERE %s
+			ORDER BY rank DESC, o.id
+			LIMIT $%d OFFSET $%d
+		)
+		SELECT h.id, h.public_id, h.object_type_id, h.parent_object_id, h.name, h.description,
+			   h.metadata, h.tags, h.status, h.version, h.created_by, h.updated_by,
+			   h.created_at, h.updated_at, h.rank, %s AS snippet
+		FROM hits h
+		ORDER BY h.rank DESC, h.id`,
+		mode.rank, conditions, len(args)-1, len(args), mode.snippet)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to search objects: %w", err)
+	}
+	defer rows.Close()
+
+	var objects []*models.Object
+	for rows.Next() {
+		var object models.Object
+		var parentObjectID sql.NullInt64
+		result := &models.ObjectSearchResult{Object: &object, MatchType: mode.matchType}
+
+		err := rows.Scan(
+			&object.ID, &object.PublicID, &object.ObjectTypeID, &parentObjectID,
+			&object.Name, &object.Description, &object.Metadata, &object.Tags,
+			&object.Status, &object.Version, &object.CreatedBy, &object.UpdatedBy,
+			&object.CreatedAt, &object.UpdatedAt, &result.Rank, &result.Snippet,
+		)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to scan search result: %w", err)
+		}
+
+		if parentObjectID.Valid {
+			object.ParentObjectID = &parentObjectID.Int64
+		}
+
+		objects = append(objects, &object)
+		results.Results = append(results.Results, result)
+	}
+	if err := rows.Err(); err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to read search results: %w", err)
+	}
+
+	if len(objects) > 0 {
+		r.loadObjectTypesForObjects(ctx, objects)
+	}
+
+	return results, nil
+}
+
+func (r *objectRepository) countSearchMatches(ctx context.Context, mode searchMode, filter *models.ObjectSearchFilter) (int64, error) {
+	conditions, args := searchConditions(mode, filter)
+
+	var total int64
+	query := "SELECT COUNT(*) FROM objects_service.objects o WHERE " + conditions
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		r.metrics.ErrorCount++
+		return 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+	return total, nil
+}
+
+// searchConditions builds the WHERE clause shared by the count and the page query
+func searchConditions(mode searchMode, filter *models.ObjectSearchFilter) (string, []interface{}) {
+	conditions := "o.deleted_at IS NULL AND " + mode.condition
+	args := []interface{}{filter.Query}
+
+	if filter.ObjectTypeID != nil {
+		args = append(args, *filter.ObjectTypeID)
+		conditions += fmt.Sprintf(`
+			  AND o.object_type_id IN (
+				WITH RECURSIVE subtree AS (
+					SELECT id FROM objects_service.object_types WHERE id = $%d
+					UNION
+					SELECT t.id FROM objects_service.object_types t
+					INNER JOIN subtree s ON t.parent_type_id = s.id
+				)
+				SELECT id FROM subtree
+			  )`, len(args))
+	}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions += fmt.Sprintf(" AND o.status = $%d", len(args))
+	}
+
+	return conditions, args
+}
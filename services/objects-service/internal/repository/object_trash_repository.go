@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+)
+
+// trashColumns are the object columns the trash bin reads, in the order queryObjects scans them
+const trashColumns = `id, public_id, object_type_id, parent_object_id, name, description,
+		       metadata, tags, status, version, created_by, updated_by,
+		       created_at, updated_at, deleted_at`
+
+// notRelationship leaves out the base objects of relationships, which go to the trash and come
+// back with their ends rather than on their own
+const notRelationship = `NOT EXISTS (
+	SELECT 1 FROM objects_service.objects_relationships r WHERE r.object_id = objects.id
+)`
+
+// ListDeleted lists objects in the trash, most recently deleted first
+func (r *objectRepository) ListDeleted(ctx context.Context, filter *models.TrashFilter) ([]*models.Object, int64, error) {
+	r.metrics.QueryCount++
+
+	conditions := []string{"deleted_at IS NOT NULL", notRelationship}
+	var args []interface{}
+
+	if filter.Name != "" {
+		args = append(args, "%"+filter.Name+"%")
+		conditions = append(conditions, fmt.Sprintf("name ILIKE $%d", len(args)))
+	}
+	if filter.ObjectTypeID != nil {
+		args = append(args, *filter.ObjectTypeID)
+		conditions = append(conditions, fmt.Sprintf("object_type_id = $%d", len(args)))
+	}
+	if filter.ParentObjectID != nil {
+		args = append(args, *filter.ParentObjectID)
+		conditions = append(conditions, fmt.Sprintf("parent_object_id = $%d", len(args)))
+	}
+	if filter.CreatedBy != "" {
+		args = append(args, filter.CreatedBy)
+		conditions = append(conditions, fmt.Sprintf("created_by = $%d", len(args)))
+	}
+	if filter.DeletedAfter != nil {
+		args = append(args, *filter.DeletedAfter)
+		conditions = append(conditions, fmt.Sprintf("deleted_at >= $%d", len(args)))
+	}
+	if filter.DeletedBefore != nil {
+		args = append(args, *filter.DeletedBefore)
+		conditions = append(conditions, fmt.Sprintf("deleted_at <= $%d", len(args)))
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int64
+	err := r.db.QueryRow(ctx, "SELECT COUNT(*) FROM objects_service.objects"+where, args...).Scan(&total)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, 0, fmt.Errorf("failed to count deleted objects: %w", err)
+	}
+
+	query := "SELECT " + trashColumns + " FROM objects_service.objects" + where + " ORDER BY deleted_at DESC, id"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	objects, err := r.queryObjects(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list deleted objects: %w", err)
+	}
+	return objects, total, nil
+}
+
+// GetDeletedDescendants returns the deleted descendants of an object that are reached through
+// deleted objects only, parents before their children
+func (r *objectRepository) GetDeletedDescendants(ctx context.Context, rootID int64) ([]*models.Object, error) {
+	r.metrics.QueryCount++
+
+	query := `
+		WITH RECURSIVE trashed AS (
+			SELECT id, 1 AS depth
+			FROM objects_service.objects
+			WHERE parent_object_id = $1 AND deleted_at IS NOT NULL
+
+			UNION ALL
+
+			SELECT o.id, t.depth + 1
+			FROM objects_service.objects o
+			INNER JOIN trashed t ON o.parent_object_id = t.id
+			WHERE o.deleted_at IS NOT NULL AND t.depth < 100
+		)
+		SELECT ` + trashColumns + `
+		FROM objects_service.objects
+		JOIN trashed USING (id)
+		ORDER BY trashed.depth, id`
+
+	objects, err := r.queryObjects(ctx, query, rootID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deleted descendants: %w", err)
+	}
+	return objects, nil
+}
+
+// ListExpired returns up to limit objects deleted before deletedBefore that have no children
+// left, deleted or not, so that purging them leaves no object without its parent. Purging them
+// turns their parents into candidates of the next call.
+func (r *objectRepository) ListExpired(ctx context.Context, deletedBefore time.Time, limit int) ([]*models.Object, error) {
+	r.metrics.QueryCount++
+
+	query := `
+		SELECT ` + trashColumns + `
+		FROM objects_service.objects
+		WHERE deleted_at < $1 AND ` + notRelationship + `
+			AND NOT EXISTS (
+				SELECT 1 FROM objects_service.objects c WHERE c.parent_object_id = objects.id
+			)
+		ORDER BY deleted_at, id
+		LIMIT $2`
+
+	objects, err := r.queryObjects(ctx, query, deletedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired objects: %w", err)
+	}
+	return objects, nil
+}
+
+// Restore takes an object out of the trash as a new, active version
+func (r *objectRepository) Restore(ctx context.Context, id int64, updatedBy string) (*models.Object, error) {
+	r.metrics.QueryCount++
+
+	query := `
+		UPDATE objects_service.objects
+		SET deleted_at = NULL, status = $2, version = version + 1,
+			updated_by = COALESCE(NULLIF($3, ''), updated_by), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		RETURNING id`
+
+	var restoredID int64
+	err := r.db.QueryRow(ctx, query, id, models.StatusActive, updatedBy).Scan(&restoredID)
+	if err != nil {
+		r.metrics.ErrorCount++
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to restore object: %w", err)
+	}
+
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionRestore, []int64{id}); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+// Purge removes objects in the trash for good, together with the relationships they take part
+// in. The final state of everything removed is recorded in the history first, which outlives it.
+// It fails with ErrNotFound, removing nothing, unless every object is in the trash.
+func (r *objectRepository) Purge(ctx context.Context, ids []int64) error {
+	r.metrics.QueryCount++
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	// The rows are locked so that none is restored while it is being purged
+	trashed, err := r.queryIDs(ctx, `
+		SELECT id FROM objects_service.objects
+		WHERE id = ANY($1::bigint[]) AND deleted_at IS NOT NULL
+		FOR UPDATE`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to lock deleted objects: %w", err)
+	}
+	if len(trashed) != len(ids) {
+		return fmt.Errorf("%d of %d objects are not in the trash: %w", len(ids)-len(trashed), len(ids), ErrNotFound)
+	}
+
+	relationshipIDs, err := r.queryIDs(ctx, `
+		SELECT object_id FROM objects_service.objects_relationships
+		WHERE source_object_id = ANY($1::bigint[]) OR target_object_id = ANY($1::bigint[])`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to find relationships of deleted objects: %w", err)
+	}
+
+	purged := append(relationshipIDs, ids...)
+	if err := recordHistory(ctx, r.db, models.HistoryEntityRelationship, models.HistoryActionPurge, relationshipIDs); err != nil {
+		r.metrics.ErrorCount++
+		return err
+	}
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionPurge, purged); err != nil {
+		r.metrics.ErrorCount++
+		return err
+	}
+
+	// Removing a base object removes its relationship row with it
+	_, err = r.db.Exec(ctx, `DELETE FROM objects_service.objects WHERE id = ANY($1::bigint[])`, purged)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return fmt.Errorf("failed to purge objects: %w", err)
+	}
+
+	return nil
+}
+
+// trashRelationships soft-deletes the relationships the objects take part in, so that they go
+// to the trash and can be restored together with the objects
+func (r *objectRepository) trashRelationships(ctx context.Context, ids []int64) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE objects_service.objects o
+		SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		FROM objects_service.objects_relationships r
+		WHERE o.id = r.object_id AND o.deleted_at IS NULL
+			AND (r.source_object_id = ANY($1::bigint[]) OR r.target_object_id = ANY($1::bigint[]))`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to delete relationships of deleted objects: %w", err)
+	}
+	return nil
+}
+
+// queryObjects runs a query selecting trashColumns and returns its objects with their types
+func (r *objectRepository) queryObjects(ctx context.Context, query string, args ...interface{}) ([]*models.Object, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+	defer rows.Close()
+
+	objects := []*models.Object{}
+	for rows.Next() {
+		var object models.Object
+		var parentObjectID sql.NullInt64
+		var deletedAt sql.NullTime
+
+		err := rows.Scan(
+			&object.ID, &object.PublicID, &object.ObjectTypeID, &parentObjectID,
+			&object.Name, &object.Description, &object.Metadata, &object.Tags,
+			&object.Status, &object.Version, &object.CreatedBy, &object.UpdatedBy,
+			&object.CreatedAt, &object.UpdatedAt, &deletedAt,
+		)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return nil, err
+		}
+
+		if parentObjectID.Valid {
+			object.ParentObjectID = &parentObjectID.Int64
+		}
+		if deletedAt.Valid {
+			object.DeletedAt = &deletedAt.Time
+		}
+		objects = append(objects, &object)
+	}
+	if err := rows.Err(); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
+	if len(objects) > 0 {
+		r.loadObjectTypesForObjects(ctx, objects)
+	}
+	return objects, nil
+}
+
+// queryIDs runs a query selecting a single ID column
+func (r *objectRepository) queryIDs(ctx context.Context, query string, args ...interface{}) ([]int64, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			r.metrics.ErrorCount++
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+	return ids, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -40,13 +41,15 @@ type ObjectTypeRepository interface {
 	ValidateMove(ctx context.Context, id int64, newParentID *int64) error
 	CanDelete(ctx context.Context, id int64) (bool, error)
 	GetSubtreeObjectCount(ctx context.Context, id int64) (int64, error)
+	Move(ctx context.Context, id int64, newParentID *int64, updatedBy string) (*models.ObjectType, error)
 }
 
 // objectTypeRepository implements ObjectTypeRepository
 type objectTypeRepository struct {
-	db      DBInterface
-	options *RepositoryOptions
-	metrics *RepositoryMetrics
+	db       DBInterface
+	options  *RepositoryOptions
+	metrics  *RepositoryMetrics
+	concrete ConcreteTableRepository
 }
 
 // NewObjectTypeRepository creates a new ObjectTypeRepository instance
@@ -56,9 +59,10 @@ func NewObjectTypeRepository(db DBInterface, options *RepositoryOptions) ObjectT
 	}
 
 	return &objectTypeRepository{
-		db:      db,
-		options: options,
-		metrics: &RepositoryMetrics{LastResetAt: time.Now()},
+		db:       db,
+		options:  options,
+		metrics:  &RepositoryMetrics{LastResetAt: time.Now()},
+		concrete: NewConcreteTableRepository(db, options),
 	}
 }
 
@@ -109,9 +113,10 @@ func (r *objectTypeRepository) Create(ctx context.Context, input *models.CreateO
 
 	query := `
 		INSERT INTO objects_service.object_types (
-			name, parent_type_id, concrete_table_name, description, is_sealed, metadata, created_by, updated_by
+			name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema,
+			search_metadata_keys, created_by, updated_by
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
 		) RETURNING id, created_at, updated_at, created_by, updated_by`
 
 	var objectType models.ObjectType
@@ -137,10 +142,19 @@ func (r *objectTypeRepository) Create(ctx context.Context, input *models.CreateO
 		}
 	}
 
+	if !models.IsEmptyMetadataSchema(input.MetadataSchema) {
+		objectType.MetadataSchema = input.MetadataSchema
+	}
+
+	objectType.SearchMetadataKeys = input.SearchMetadataKeys
+	if objectType.SearchMetadataKeys == nil {
+		objectType.SearchMetadataKeys = []string{}
+	}
+
 	err := r.db.QueryRow(ctx, query,
 		objectType.Name, objectType.ParentTypeID, objectType.ConcreteTableName,
-		objectType.Description, objectType.IsSealed, objectType.Metadata,
-		objectType.CreatedBy, objectType.UpdatedBy,
+		objectType.Description, objectType.IsSealed, objectType.Metadata, objectType.MetadataSchema,
+		objectType.SearchMetadataKeys, objectType.CreatedBy, objectType.UpdatedBy,
 	).Scan(&objectType.ID, &objectType.CreatedAt, &objectType.UpdatedAt, &objectType.CreatedBy, &objectType.UpdatedBy)
 	if err != nil {
 		r.metrics.ErrorCount++
@@ -163,7 +177,7 @@ func (r *objectTypeRepository) GetByID(ctx context.Context, id int64) (*models.O
 	r.metrics.QueryCount++
 
 	query := `
-		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, created_at, updated_at
+		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema, search_metadata_keys, created_at, updated_at
 		FROM objects_service.object_types
 		WHERE id = $1`
 
@@ -172,11 +186,14 @@ func (r *objectTypeRepository) GetByID(ctx context.Context, id int64) (*models.O
 
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&objectType.ID, &objectType.Name, &parentID, &objectType.ConcreteTableName,
-		&objectType.Description, &objectType.IsSealed, &objectType.Metadata,
+		&objectType.Description, &objectType.IsSealed, &objectType.Metadata, &objectType.MetadataSchema, &objectType.SearchMetadataKeys,
 		&objectType.CreatedAt, &objectType.UpdatedAt,
 	)
 	if err != nil {
 		r.metrics.ErrorCount++
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
 		return nil, fmt.Errorf("failed to get object type: %w", err)
 	}
 
@@ -211,7 +228,7 @@ func (r *objectTypeRepository) GetByName(ctx context.Context, name string) (*mod
 	r.metrics.QueryCount++
 
 	query := `
-		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, created_at, updated_at
+		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema, search_metadata_keys, created_at, updated_at
 		FROM objects_service.object_types 
 		WHERE name = $1`
 
@@ -220,7 +237,7 @@ func (r *objectTypeRepository) GetByName(ctx context.Context, name string) (*mod
 
 	err := r.db.QueryRow(ctx, query, name).Scan(
 		&objectType.ID, &objectType.Name, &parentID, &objectType.ConcreteTableName,
-		&objectType.Description, &objectType.IsSealed, &objectType.Metadata,
+		&objectType.Description, &objectType.IsSealed, &objectType.Metadata, &objectType.MetadataSchema, &objectType.SearchMetadataKeys,
 		&objectType.CreatedAt, &objectType.UpdatedAt,
 	)
 	if err != nil {
@@ -261,6 +278,21 @@ func (r *objectTypeRepository) Update(ctx context.Context, id int64, input *mode
 		if err := r.ValidateParentChild(ctx, *input.ParentTypeID, id); err != nil {
 			return nil, fmt.Errorf("invalid parent relationship: %w", err)
 		}
+		if err := r.validateAttributeParentChange(ctx, current, input.ParentTypeID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Attribute values live in the concrete table, so it cannot be swapped out from under them
+	if input.ConcreteTableName != nil && (current.ConcreteTableName == nil || *input.ConcreteTableName != *current.ConcreteTableName) {
+		attributes, err := r.concrete.ListAttributes(ctx, id)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return nil, err
+		}
+		if len(attributes) > 0 {
+			return nil, fmt.Errorf("cannot change the concrete table of an object type with attributes: %w", ErrInvalidInput)
+		}
 	}
 
 	// Build dynamic update query
@@ -310,6 +342,27 @@ func (r *objectTypeRepository) Update(ctx context.Context, id int64, input *mode
 		argIndex++
 	}
 
+	if input.MetadataSchema != nil {
+		// An empty schema ({}) removes it
+		setClauses = append(setClauses, fmt.Sprintf("metadata_schema = $%d", argIndex))
+		if models.IsEmptyMetadataSchema(*input.MetadataSchema) {
+			args = append(args, nil)
+		} else {
+			args = append(args, []byte(*input.MetadataSchema))
+		}
+		argIndex++
+	}
+
+	if input.SearchMetadataKeys != nil {
+		setClauses = append(setClauses, fmt.Sprintf("search_metadata_keys = $%d", argIndex))
+		keys := *input.SearchMetadataKeys
+		if keys == nil {
+			keys = []string{}
+		}
+		args = append(args, keys)
+		argIndex++
+	}
+
 	if len(setClauses) == 0 {
 		return current, nil // No changes
 	}
@@ -323,22 +376,28 @@ func (r *objectTypeRepository) Update(ctx context.Context, id int64, input *mode
 	args = append(args, updatedBy)
 	argIndex++
 
+	pinned, pinnedArgs := pinnedCondition(ctx, preconditionObjectType, id, argIndex+1)
 	query := fmt.Sprintf(`
 		UPDATE objects_service.object_types 
 		SET %s 
-		WHERE id = $%d
+		WHERE id = $%d%s
 		RETURNING updated_at, updated_by`,
 		strings.Join(setClauses, ", "),
 		argIndex,
+		pinned,
 	)
 
 	args = append(args, id)
+	args = append(args, pinnedArgs...)
 
 	var updatedAt sql.NullTime
 	var returnedUpdatedBy string
 	err = r.db.QueryRow(ctx, query, args...).Scan(&updatedAt, &returnedUpdatedBy)
 	if err != nil {
 		r.metrics.ErrorCount++
+		if pinned != "" && errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOptimisticLock
+		}
 		return nil, fmt.Errorf("failed to update object type: %w", err)
 	}
 
@@ -346,6 +405,119 @@ func (r *objectTypeRepository) Update(ctx context.Context, id int64, input *mode
 	return r.GetByID(ctx, id)
 }
 
+// validateAttributeParentChange checks that moving an object type under a new parent, nil for the
+// root, keeps the attributes of its subtree unique and, when the subtree has objects, keeps the
+// concrete tables inherited from its ancestors
+func (r *objectTypeRepository) validateAttributeParentChange(ctx context.Context, current *models.ObjectType, newParentID *int64) error {
+	if sameParent(current.ParentTypeID, newParentID) {
+		return nil
+	}
+
+	var newTables []*models.ConcreteTable
+	var err error
+	if newParentID != nil {
+		if newTables, err = r.concrete.GetConcreteTables(ctx, *newParentID); err != nil {
+			return err
+		}
+	}
+	subtree, err := r.concrete.ListSubtreeAttributes(ctx, current.ID)
+	if err != nil {
+		return err
+	}
+
+	inherited := map[string]bool{}
+	for _, table := range newTables {
+		for _, attribute := range table.Attributes {
+			inherited[attribute.Name] = true
+		}
+	}
+	for _, attribute := range subtree {
+		if inherited[attribute.Name] {
+			return fmt.Errorf("attribute %q is already declared by the new parent's type chain: %w", attribute.Name, ErrInvalidInput)
+		}
+	}
+
+	var oldTables []*models.ConcreteTable
+	if current.ParentTypeID != nil {
+		if oldTables, err = r.concrete.GetConcreteTables(ctx, *current.ParentTypeID); err != nil {
+			return err
+		}
+	}
+	if sameConcreteTables(oldTables, newTables) {
+		return nil
+	}
+
+	count, err := r.GetSubtreeObjectCount(ctx, current.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("cannot move an object type with objects to a parent with different concrete tables: %w", ErrInvalidInput)
+	}
+	return nil
+}
+
+// Move sets the parent of an object type; a nil parent makes it a root. Unlike Update it can
+// detach a type from its parent.
+func (r *objectTypeRepository) Move(ctx context.Context, id int64, newParentID *int64, updatedBy string) (*models.ObjectType, error) {
+	r.metrics.QueryCount++
+
+	current, err := r.GetByID(ctx, id)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
+	if newParentID != nil {
+		if err := r.ValidateParentChild(ctx, *newParentID, id); err != nil {
+			return nil, fmt.Errorf("invalid parent relationship: %v: %w", err, ErrInvalidInput)
+		}
+	}
+	if err := r.validateAttributeParentChange(ctx, current, newParentID); err != nil {
+		return nil, err
+	}
+
+	if updatedBy == "" {
+		updatedBy = "system"
+	}
+
+	query := `
+		UPDATE objects_service.object_types
+		SET parent_type_id = $1, updated_at = CURRENT_TIMESTAMP, updated_by = $2
+		WHERE id = $3
+		RETURNING id`
+
+	var movedID int64
+	if err := r.db.QueryRow(ctx, query, newParentID, updatedBy, id).Scan(&movedID); err != nil {
+		r.metrics.ErrorCount++
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to move object type: %w", err)
+	}
+
+	return r.GetByID(ctx, id)
+}
+
+func sameParent(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func sameConcreteTables(a, b []*models.ConcreteTable) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].TableName != b[i].TableName {
+			return false
+		}
+	}
+	return true
+}
+
 // Delete soft-deletes an object type
 func (r *objectTypeRepository) Delete(ctx context.Context, id int64) error {
 	r.metrics.QueryCount++
@@ -362,7 +534,10 @@ func (r *objectTypeRepository) Delete(ctx context.Context, id int64) error {
 
 	// For simplicity, we'll do a hard delete since the current schema doesn't have soft delete
 	query := `DELETE FROM objects_service.object_types WHERE id = $1`
-	_, err = r.db.Exec(ctx, query, id)
+	err = execPinned(ctx, r.db, preconditionObjectType, id, query, id)
+	if errors.Is(err, ErrOptimisticLock) {
+		return err
+	}
 	if err != nil {
 		r.metrics.ErrorCount++
 		return fmt.Errorf("failed to delete object type: %w", err)
@@ -380,7 +555,7 @@ func (r *objectTypeRepository) GetTree(ctx context.Context, rootID *int64) ([]*m
 		WITH RECURSIVE object_tree AS (
 			-- Base case: root nodes
 			SELECT 
-				id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata,
+				id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema, search_metadata_keys,
 				created_at, updated_at
 			FROM objects_service.object_types 
 			WHERE ($1::bigint IS NULL AND parent_type_id IS NULL) OR id = $1::bigint
@@ -389,7 +564,7 @@ func (r *objectTypeRepository) GetTree(ctx context.Context, rootID *int64) ([]*m
 			
 			-- Recursive case: children
 			SELECT 
-				ot.id, ot.name, ot.parent_type_id, ot.concrete_table_name, ot.description, ot.is_sealed, ot.metadata,
+				ot.id, ot.name, ot.parent_type_id, ot.concrete_table_name, ot.description, ot.is_sealed, ot.metadata, ot.metadata_schema, ot.search_metadata_keys,
 				ot.created_at, ot.updated_at
 			FROM objects_service.object_types ot
 			INNER JOIN object_tree t ON ot.parent_type_id = t.id
@@ -412,7 +587,7 @@ func (r *objectTypeRepository) GetTree(ctx context.Context, rootID *int64) ([]*m
 
 		err := rows.Scan(
 			&objectType.ID, &objectType.Name, &parentID, &objectType.ConcreteTableName,
-			&objectType.Description, &objectType.IsSealed, &objectType.Metadata,
+			&objectType.Description, &objectType.IsSealed, &objectType.Metadata, &objectType.MetadataSchema, &objectType.SearchMetadataKeys,
 			&objectType.CreatedAt, &objectType.UpdatedAt,
 		)
 		if err != nil {
@@ -450,7 +625,7 @@ func (r *objectTypeRepository) GetChildren(ctx context.Context, parentID int64)
 	r.metrics.QueryCount++
 
 	query := `
-		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, created_at, updated_at
+		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema, search_metadata_keys, created_at, updated_at
 		FROM objects_service.object_types
 		WHERE parent_type_id = $1
 		ORDER BY name ASC`
@@ -469,7 +644,7 @@ func (r *objectTypeRepository) GetChildren(ctx context.Context, parentID int64)
 
 		err := rows.Scan(
 			&objectType.ID, &objectType.Name, &parentID, &objectType.ConcreteTableName,
-			&objectType.Description, &objectType.IsSealed, &objectType.Metadata,
+			&objectType.Description, &objectType.IsSealed, &objectType.Metadata, &objectType.MetadataSchema, &objectType.SearchMetadataKeys,
 			&objectType.CreatedAt, &objectType.UpdatedAt,
 		)
 		if err != nil {
@@ -562,17 +737,17 @@ func (r *objectTypeRepository) GetDescendants(ctx context.Context, rootID int64,
 	query := `
 		WITH RECURSIVE descendants AS (
 			-- Base case: the root node itself
-			SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, created_at, updated_at, 1 as depth
+			SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema, search_metadata_keys, created_at, updated_at, 1 as depth
 			FROM objects_service.object_types WHERE id = $1
 
 			UNION ALL
 
 			-- Recursive case: children of current nodes
-			SELECT ot.id, ot.name, ot.parent_type_id, ot.concrete_table_name, ot.description, ot.is_sealed, ot.metadata, ot.created_at, ot.updated_at, d.depth + 1
+			SELECT ot.id, ot.name, ot.parent_type_id, ot.concrete_table_name, ot.description, ot.is_sealed, ot.metadata, ot.metadata_schema, ot.search_metadata_keys, ot.created_at, ot.updated_at, d.depth + 1
 			FROM objects_service.object_types ot
 			INNER JOIN descendants d ON ot.parent_type_id = d.id
 		)
-		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, created_at, updated_at
+		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema, search_metadata_keys, created_at, updated_at
 		FROM descendants`
 
 	args := []interface{}{rootID}
@@ -601,7 +776,7 @@ func (r *objectTypeRepository) GetDescendants(ctx context.Context, rootID int64,
 
 		err := rows.Scan(
 			&objectType.ID, &objectType.Name, &parentID, &objectType.ConcreteTableName,
-			&objectType.Description, &objectType.IsSealed, &objectType.Metadata,
+			&objectType.Description, &objectType.IsSealed, &objectType.Metadata, &objectType.MetadataSchema, &objectType.SearchMetadataKeys,
 			&objectType.CreatedAt, &objectType.UpdatedAt,
 		)
 		if err != nil {
@@ -628,17 +803,17 @@ func (r *objectTypeRepository) GetAncestors(ctx context.Context, id int64) ([]*m
 	query := `
 		WITH RECURSIVE ancestors AS (
 			-- Base case: the node itself
-			SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, created_at, updated_at, 1 as level
+			SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema, search_metadata_keys, created_at, updated_at, 1 as level
 			FROM objects_service.object_types WHERE id = $1
 
 			UNION ALL
 
 			-- Recursive case: parent of current node
-			SELECT ot.id, ot.name, ot.parent_type_id, ot.concrete_table_name, ot.description, ot.is_sealed, ot.metadata, ot.created_at, ot.updated_at, a.level + 1
+			SELECT ot.id, ot.name, ot.parent_type_id, ot.concrete_table_name, ot.description, ot.is_sealed, ot.metadata, ot.metadata_schema, ot.search_metadata_keys, ot.created_at, ot.updated_at, a.level + 1
 			FROM objects_service.object_types ot
 			INNER JOIN ancestors a ON ot.id = a.parent_type_id
 		)
-		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, created_at, updated_at
+		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema, search_metadata_keys, created_at, updated_at
 		FROM ancestors
 		WHERE id != $1
 		ORDER BY level DESC`
@@ -657,7 +832,7 @@ func (r *objectTypeRepository) GetAncestors(ctx context.Context, id int64) ([]*m
 
 		err := rows.Scan(
 			&objectType.ID, &objectType.Name, &parentID, &objectType.ConcreteTableName,
-			&objectType.Description, &objectType.IsSealed, &objectType.Metadata,
+			&objectType.Description, &objectType.IsSealed, &objectType.Metadata, &objectType.MetadataSchema, &objectType.SearchMetadataKeys,
 			&objectType.CreatedAt, &objectType.UpdatedAt,
 		)
 		if err != nil {
@@ -683,17 +858,17 @@ func (r *objectTypeRepository) GetPath(ctx context.Context, id int64) ([]*models
 	query := `
 		WITH RECURSIVE path AS (
 			-- Base case: the target node
-			SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, created_at, updated_at, 1 as level
+			SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema, search_metadata_keys, created_at, updated_at, 1 as level
 			FROM objects_service.object_types WHERE id = $1
 
 			UNION ALL
 
 			-- Recursive case: parent of current node
-			SELECT ot.id, ot.name, ot.parent_type_id, ot.concrete_table_name, ot.description, ot.is_sealed, ot.metadata, ot.created_at, ot.updated_at, p.level + 1
+			SELECT ot.id, ot.name, ot.parent_type_id, ot.concrete_table_name, ot.description, ot.is_sealed, ot.metadata, ot.metadata_schema, ot.search_metadata_keys, ot.created_at, ot.updated_at, p.level + 1
 			FROM objects_service.object_types ot
 			INNER JOIN path p ON ot.id = p.parent_type_id
 		)
-		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, created_at, updated_at
+		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema, search_metadata_keys, created_at, updated_at
 		FROM path
 		ORDER BY level DESC`
 
@@ -711,7 +886,7 @@ func (r *objectTypeRepository) GetPath(ctx context.Context, id int64) ([]*models
 
 		err := rows.Scan(
 			&objectType.ID, &objectType.Name, &parentID, &objectType.ConcreteTableName,
-			&objectType.Description, &objectType.IsSealed, &objectType.Metadata,
+			&objectType.Description, &objectType.IsSealed, &objectType.Metadata, &objectType.MetadataSchema, &objectType.SearchMetadataKeys,
 			&objectType.CreatedAt, &objectType.UpdatedAt,
 		)
 		if err != nil {
@@ -739,7 +914,7 @@ func (r *objectTypeRepository) List(ctx context.Context, filter *models.ObjectTy
 	}
 
 	query := `
-		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, created_at, updated_at
+		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema, search_metadata_keys, created_at, updated_at
 		FROM objects_service.object_types`
 	whereClauses := []string{}
 	args := []interface{}{}
@@ -794,7 +969,7 @@ func (r *objectTypeRepository) List(ctx context.Context, filter *models.ObjectTy
 
 		err := rows.Scan(
 			&objectType.ID, &objectType.Name, &parentID, &objectType.ConcreteTableName,
-			&objectType.Description, &objectType.IsSealed, &objectType.Metadata,
+			&objectType.Description, &objectType.IsSealed, &objectType.Metadata, &objectType.MetadataSchema, &objectType.SearchMetadataKeys,
 			&objectType.CreatedAt, &objectType.UpdatedAt,
 		)
 		if err != nil {
@@ -822,7 +997,7 @@ func (r *objectTypeRepository) Search(ctx context.Context, query string, limit i
 	}
 
 	searchQuery := `
-		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, created_at, updated_at
+		SELECT id, name, parent_type_id, concrete_table_name, description, is_sealed, metadata, metadata_schema, search_metadata_keys, created_at, updated_at
 		FROM objects_service.object_types
 		WHERE name ILIKE $1 OR description ILIKE $1
 		ORDER BY
@@ -845,7 +1020,7 @@ func (r *objectTypeRepository) Search(ctx context.Context, query string, limit i
 
 		err := rows.Scan(
 			&objectType.ID, &objectType.Name, &parentID, &objectType.ConcreteTableName,
-			&objectType.Description, &objectType.IsSealed, &objectType.Metadata,
+			&objectType.Description, &objectType.IsSealed, &objectType.Metadata, &objectType.MetadataSchema, &objectType.SearchMetadataKeys,
 			&objectType.CreatedAt, &objectType.UpdatedAt,
 		)
 		if err != nil {
@@ -876,14 +1051,15 @@ func (r *objectTypeRepository) ValidateMove(ctx context.Context, id int64, newPa
 		return fmt.Errorf("object type cannot be moved to itself")
 	}
 
-	ancestors, err := r.GetAncestors(ctx, id)
+	// The new parent must not be a descendant, so the type must not be among its ancestors
+	ancestors, err := r.GetAncestors(ctx, *newParentID)
 	if err != nil {
 		r.metrics.ErrorCount++
 		return fmt.Errorf("failed to get ancestors: %w", err)
 	}
 
 	for _, ancestor := range ancestors {
-		if ancestor.ID == *newParentID {
+		if ancestor.ID == id {
 			return fmt.Errorf("circular dependency: cannot move object type under its own descendant")
 		}
 	}
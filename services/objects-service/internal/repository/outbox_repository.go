@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+)
+
+// OutboxRepository defines operations on the outbox of domain events. Events are appended in the
+// transaction of the change they describe and read back in offset order once that transaction
+// has committed.
+type OutboxRepository interface {
+	Repository
+
+	Append(ctx context.Context, events ...*models.OutboxEvent) error
+	List(ctx context.Context, filter *models.OutboxEventFilter) ([]*models.OutboxEvent, error)
+	TryLockDispatch(ctx context.Context) (bool, error)
+	Pending(ctx context.Context, limit int) ([]*models.OutboxEvent, error)
+	MarkDispatched(ctx context.Context, offsets []int64) error
+	MarkFailed(ctx context.Context, offset int64, cause string) error
+}
+
+// outboxRepository implements OutboxRepository
+type outboxRepository struct {
+	db      DBInterface
+	options *RepositoryOptions
+	metrics *RepositoryMetrics
+}
+
+// NewOutboxRepository creates a new OutboxRepository instance
+func NewOutboxRepository(db DBInterface, options *RepositoryOptions) OutboxRepository {
+	if options == nil {
+		options = DefaultRepositoryOptions()
+	}
+
+	return &outboxRepository{
+		db:      db,
+		options: options,
+		metrics: &RepositoryMetrics{LastResetAt: time.Now()},
+	}
+}
+
+// DB implements Repository interface
+func (r *outboxRepository) DB() DBInterface {
+	return r.db
+}
+
+// Options implements Repository interface
+func (r *outboxRepository) Options() *RepositoryOptions {
+	return r.options
+}
+
+// Metrics implements Repository interface
+func (r *outboxRepository) Metrics() *RepositoryMetrics {
+	return r.metrics
+}
+
+// ResetMetrics implements Repository interface
+func (r *outboxRepository) ResetMetrics() {
+	r.metrics.Reset()
+}
+
+// Healthy implements Repository interface
+func (r *outboxRepository) Healthy(ctx context.Context) error {
+	var result int
+	return r.db.QueryRow(ctx, "SELECT 1").Scan(&result)
+}
+
+const outboxColumns = `id, event_id, event_type, entity_type, entity_id, entity_public_id,
+			before, after, actor, trace_id, request_id, occurred_at`
+
+// outboxVisible admits the events of transactions that ended before every transaction still in
+// progress. Offsets are assigned when events are written, not when they commit, so a reader
+// that went past the events of a transaction still in progress would never see them.
+const outboxVisible = `transaction_id < pg_snapshot_xmin(pg_current_snapshot())`
+
+// Append writes events to the outbox, filling in their offsets, IDs and times
+func (r *outboxRepository) Append(ctx context.Context, events ...*models.OutboxEvent) error {
+	for _, event := range events {
+		r.metrics.QueryCount++
+
+		err := r.db.QueryRow(ctx, `
+			INSERT INTO objects_service.outbox_events (
+				event_type, entity_type, entity_id, entity_public_id,
+				before, after, actor, trace_id, request_id
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''))
+			RETURNING id, event_id, occurred_at`,
+			event.EventType, event.EntityType, event.EntityID, event.EntityPublicID,
+			nullableJSON(event.Before), nullableJSON(event.After), event.Actor, event.TraceID, event.RequestID,
+		).Scan(&event.Offset, &event.EventID, &event.OccurredAt)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return fmt.Errorf("failed to append %s event: %w", event.EventType, err)
+		}
+	}
+	return nil
+}
+
+// List returns committed events after filter.After, oldest first
+func (r *outboxRepository) List(ctx context.Context, filter *models.OutboxEventFilter) ([]*models.OutboxEvent, error) {
+	r.metrics.QueryCount++
+
+	where := []string{"id > $1", outboxVisible}
+	args := []interface{}{filter.After}
+	if filter.EventType != "" {
+		args = append(args, filter.EventType)
+		where = append(where, fmt.Sprintf("event_type = $%d", len(args)))
+	}
+	if filter.EntityType != "" {
+		args = append(args, filter.EntityType)
+		where = append(where, fmt.Sprintf("entity_type = $%d", len(args)))
+	}
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM objects_service.outbox_events
+		WHERE %s
+		ORDER BY id
+		LIMIT $%d`, outboxColumns, strings.Join(where, " AND "), len(args))
+
+	events, err := r.queryEvents(ctx, query, args...)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to list outbox events: %w", err)
+	}
+	return events, nil
+}
+
+// TryLockDispatch takes the dispatcher lock for the rest of the transaction, so a single
+// dispatcher delivers events at a time and in order. It reports false if another holds it.
+func (r *outboxRepository) TryLockDispatch(ctx context.Context) (bool, error) {
+	r.metrics.QueryCount++
+
+	var locked bool
+	err := r.db.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock(hashtextextended('objects_service.outbox_events', 0))`).Scan(&locked)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return false, fmt.Errorf("failed to lock outbox dispatch: %w", err)
+	}
+	return locked, nil
+}
+
+// Pending returns committed events that have not been dispatched yet, oldest first
+func (r *outboxRepository) Pending(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	r.metrics.QueryCount++
+
+	events, err := r.queryEvents(ctx, `
+		SELECT `+outboxColumns+`
+		FROM objects_service.outbox_events
+		WHERE dispatched_at IS NULL AND `+outboxVisible+`
+		ORDER BY id
+		LIMIT $1`, limit)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to get pending outbox events: %w", err)
+	}
+	return events, nil
+}
+
+// MarkDispatched records that events were delivered to every sink
+func (r *outboxRepository) MarkDispatched(ctx context.Context, offsets []int64) error {
+	r.metrics.QueryCount++
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE objects_service.outbox_events
+		SET dispatched_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = NULL
+		WHERE id = ANY($1::bigint[])`, offsets)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return fmt.Errorf("failed to mark outbox events dispatched: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed delivery attempt of an event
+func (r *outboxRepository) MarkFailed(ctx context.Context, offset int64, cause string) error {
+	r.metrics.QueryCount++
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE objects_service.outbox_events
+		SET attempts = attempts + 1, last_error = $2
+		WHERE id = $1`, offset, cause)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) queryEvents(ctx context.Context, query string, args ...interface{}) ([]*models.OutboxEvent, error) {
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*models.OutboxEvent{}
+	for rows.Next() {
+		var event models.OutboxEvent
+		var before, after []byte
+		var traceID, requestID *string
+		err := rows.Scan(
+			&event.Offset, &event.EventID, &event.EventType, &event.EntityType, &event.EntityID, &event.EntityPublicID,
+			&before, &after, &event.Actor, &traceID, &requestID, &event.OccurredAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.Before = before
+		event.After = after
+		if traceID != nil {
+			event.TraceID = *traceID
+		}
+		if requestID != nil {
+			event.RequestID = *requestID
+		}
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// nullableJSON stores an absent document as NULL rather than an empty value
+func nullableJSON(doc []byte) interface{} {
+	if len(doc) == 0 {
+		return nil
+	}
+	return doc
+}
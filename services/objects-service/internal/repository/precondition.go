@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+)
+
+type preconditionKey struct{}
+
+// Entities a precondition can pin
+const (
+	preconditionObject       = "object"
+	preconditionObjectType   = "object_type"
+	preconditionRelationship = "relationship"
+)
+
+// Precondition is the state a client read an entity in, from the entity tag its If-Match header
+// matched. A write to that entity made with the precondition on its context only applies while
+// the row is still in that state, checked by the write statement itself; otherwise it fails
+// with ErrOptimisticLock.
+type Precondition struct {
+	entity    string
+	id        int64
+	updatedAt time.Time
+	version   int64 // objects only
+}
+
+// ObjectPrecondition pins writes to an object to the version and update time it was read with
+func ObjectPrecondition(object *models.Object) Precondition {
+	return Precondition{entity: preconditionObject, id: object.ID, updatedAt: object.UpdatedAt, version: object.Version}
+}
+
+// ObjectTypePrecondition pins writes to an object type to the update time it was read with
+func ObjectTypePrecondition(objectType *models.ObjectType) Precondition {
+	return Precondition{entity: preconditionObjectType, id: objectType.ID, updatedAt: objectType.UpdatedAt}
+}
+
+// RelationshipPrecondition pins writes to a relationship to the update time it was read with
+func RelationshipPrecondition(relationship *models.Relationship) Precondition {
+	return Precondition{entity: preconditionRelationship, id: relationship.ObjectID, updatedAt: relationship.UpdatedAt}
+}
+
+// WithPrecondition attaches a precondition to ctx for the repository writes made with it
+func WithPrecondition(ctx context.Context, precondition Precondition) context.Context {
+	return context.WithValue(ctx, preconditionKey{}, precondition)
+}
+
+// pinnedCondition returns the condition keeping a write to row id of entity to the state pinned
+// on ctx, with its arguments numbered from argIndex. It is "" when no precondition applies to
+// the row, so writes to other rows made with the same context are left alone.
+func pinnedCondition(ctx context.Context, entity string, id int64, argIndex int) (string, []interface{}) {
+	precondition, ok := ctx.Value(preconditionKey{}).(Precondition)
+	if !ok || precondition.entity != entity || precondition.id != id {
+		return "", nil
+	}
+	if precondition.version == 0 {
+		return fmt.Sprintf(" AND updated_at = $%d", argIndex), []interface{}{precondition.updatedAt}
+	}
+	return fmt.Sprintf(" AND updated_at = $%d AND version = $%d", argIndex, argIndex+1),
+		[]interface{}{precondition.updatedAt, precondition.version}
+}
+
+// execPinned runs a write to row id of entity whose query ends in its WHERE clause. With a
+// precondition on ctx for the row, the write is pinned to it and fails with ErrOptimisticLock
+// when it matches nothing.
+func execPinned(ctx context.Context, db DBInterface, entity string, id int64, query string, args ...interface{}) error {
+	condition, pinnedArgs := pinnedCondition(ctx, entity, id, len(args)+1)
+	if condition == "" {
+		_, err := db.Exec(ctx, query, args...)
+		return err
+	}
+
+	var matched int
+	err := db.QueryRow(ctx, query+condition+" RETURNING 1", append(args, pinnedArgs...)...).Scan(&matched)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrOptimisticLock
+	}
+	return err
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+)
+
+// graphStep returns the join condition that moves one hop from walk.object_id in the given
+// direction, and the expression for the object the hop reaches
+func graphStep(direction string) (join, next string) {
+	switch direction {
+	case models.DirectionIncoming:
+		return "r.target_object_id = w.object_id", "r.source_object_id"
+	case models.DirectionBoth:
+		return "(r.source_object_id = w.object_id OR r.target_object_id = w.object_id)",
+			"CASE WHEN r.source_object_id = w.object_id THEN r.target_object_id ELSE r.source_object_id END"
+	default:
+		return "r.source_object_id = w.object_id", "r.target_object_id"
+	}
+}
+
+// graphHopConditions restricts the relationships a hop may follow to the query's type keys and
+// the objects it may reach to live objects matching the query's filters. The type keys are
+// bound as $typeKeysArg; further arguments are appended to args.
+func graphHopConditions(query *models.GraphQuery, typeKeysArg int, args []interface{}) (string, []interface{}) {
+	conditions := fmt.Sprintf(`
+		  AND (COALESCE(cardinality($%[1]d::text[]), 0) = 0 OR rt.type_key = ANY($%[1]d::text[]))
+		  AND o.deleted_at IS NULL AND %[2]s`, typeKeysArg, liveRelationship)
+
+	if query.ObjectTypeID != nil {
+		args = append(args, *query.ObjectTypeID)
+		conditions += fmt.Sprintf(" AND o.object_type_id = $%d", len(args))
+	}
+	if query.Status != "" {
+		args = append(args, query.Status)
+		conditions += fmt.Sprintf(" AND o.status = $%d", len(args))
+	}
+	return conditions, args
+}
+
+// TraverseNodes walks relationships breadth-first from startObjectID up to query.MaxDepth hops
+// and returns up to query.Limit objects ordered by the fewest hops they were reached in. The
+// walk is deduplicated per object and depth, so its cost grows with the depth times the number
+// of relationships followed rather than with the number of paths. truncated reports whether
+// more objects were reachable than the limit allowed.
+func (r *relationshipRepository) TraverseNodes(ctx context.Context, startObjectID int64, query *models.GraphQuery) ([]*models.GraphNode, bool, error) {
+	r.metrics.QueryCount++
+
+	join, next := graphStep(query.Direction)
+	args := []interface{}{startObjectID, query.MaxDepth, query.TypeKeys}
+	conditions, args := graphHopConditions(query, 3, args)
+	args = append(args, query.Limit+1)
+
+	sqlQuery := fmt.Sprintf(`
+		WITH RECURSIVE walk(object_id, depth) AS (
+			SELECT $1::bigint, 0
+			UNION
+			SELECT %[2]s, w.depth + 1
+			FROM walk w
+			JOIN objects_service.objects_relationships r ON %[1]s
+			JOIN objects_service.objects_relationship_types rt ON rt.object_id = r.relationship_type_id
+			JOIN objects_service.objects o ON o.id = %[2]s
+			WHERE w.depth < $2%[3]s
+		)
+		SELECT o.id, o.public_id, o.object_type_id, o.name, o.status, MIN(w.depth) AS depth
+		FROM walk w
+		JOIN objects_service.objects o ON o.id = w.object_id
+		GROUP BY o.id
+		ORDER BY depth, o.id
+		LIMIT $%[4]d`, join, next, conditions, len(args))
+
+	rows, err := r.db.Query(ctx, sqlQuery, args...)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, false, fmt.Errorf("failed to traverse relationships: %w", err)
+	}
+	defer rows.Close()
+
+	var nodes []*models.GraphNode
+	for rows.Next() {
+		var node models.GraphNode
+		if err := rows.Scan(&node.ID, &node.PublicID, &node.ObjectTypeID, &node.Name, &node.Status, &node.Depth); err != nil {
+			r.metrics.ErrorCount++
+			return nil, false, fmt.Errorf("failed to scan graph node: %w", err)
+		}
+		nodes = append(nodes, &node)
+	}
+
+	if len(nodes) > query.Limit {
+		return nodes[:query.Limit], true, nil
+	}
+	return nodes, false, nil
+}
+
+// EdgesBetween returns up to limit relationships whose source and target are both among
+// objectIDs, restricted to typeKeys when it is not empty. truncated reports whether more
+// relationships matched than the limit allowed.
+func (r *relationshipRepository) EdgesBetween(ctx context.Context, objectIDs []int64, typeKeys []string, limit int) ([]*models.GraphEdge, bool, error) {
+	r.metrics.QueryCount++
+
+	edges, err := r.queryGraphEdges(ctx, `
+		WHERE r.source_object_id = ANY($1) AND r.target_object_id = ANY($1) AND ro.deleted_at IS NULL
+		  AND (COALESCE(cardinality($2::text[]), 0) = 0 OR rt.type_key = ANY($2::text[]))
+		ORDER BY r.object_id
+		LIMIT $3`, objectIDs, typeKeys, limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(edges) > limit {
+		return edges[:limit], true, nil
+	}
+	return edges, false, nil
+}
+
+// ShortestPath finds a path with the fewest hops from sourceObjectID to targetObjectID, no
+// longer than query.MaxDepth, and returns ErrNotFound when there is none. The walk is
+// breadth-first and stops at the first path that reaches the target.
+func (r *relationshipRepository) ShortestPath(ctx context.Context, sourceObjectID, targetObjectID int64, query *models.GraphQuery) (*models.GraphPath, error) {
+	r.metrics.QueryCount++
+
+	join, next := graphStep(query.Direction)
+	args := []interface{}{sourceObjectID, targetObjectID, query.MaxDepth, query.TypeKeys}
+	conditions, args := graphHopConditions(query, 4, args)
+
+	// Without an ORDER BY, LIMIT 1 lets PostgreSQL stop the recursion as soon as a level
+	// produces the target, so deeper levels are never expanded
+	sqlQuery := fmt.Sprintf(`
+		WITH RECURSIVE walk(object_id, depth, node_ids, edge_ids) AS (
+			SELECT $1::bigint, 0, ARRAY[$1::bigint], ARRAY[]::bigint[]
+			UNION ALL
+			SELECT %[2]s, w.depth + 1, w.node_ids || %[2]s, w.edge_ids || r.object_id
+			FROM walk w
+			JOIN objects_service.objects_relationships r ON %[1]s
+			JOIN objects_service.objects_relationship_types rt ON rt.object_id = r.relationship_type_id
+			JOIN objects_service.objects o ON o.id = %[2]s
+			WHERE w.depth < $3
+			  AND w.object_id <> $2
+			  AND NOT (%[2]s = ANY(w.node_ids))%[3]s
+		)
+		SELECT node_ids, edge_ids FROM walk WHERE object_id = $2 LIMIT 1`, join, next, conditions)
+
+	var nodeIDs, edgeIDs []int64
+	err := r.db.QueryRow(ctx, sqlQuery, args...).Scan(&nodeIDs, &edgeIDs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to find shortest path: %w", err)
+	}
+
+	path := &models.GraphPath{Length: len(edgeIDs)}
+	if path.Nodes, err = r.graphNodesByID(ctx, nodeIDs); err != nil {
+		return nil, err
+	}
+	if path.Edges, err = r.graphEdgesByID(ctx, edgeIDs); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+// graphNodesByID loads the given objects as nodes, in the order of ids and with their index
+// as the depth
+func (r *relationshipRepository) graphNodesByID(ctx context.Context, ids []int64) ([]*models.GraphNode, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, public_id, object_type_id, name, status
+		FROM objects_service.objects
+		WHERE id = ANY($1)`, ids)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to load path objects: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[int64]*models.GraphNode, len(ids))
+	for rows.Next() {
+		var node models.GraphNode
+		if err := rows.Scan(&node.ID, &node.PublicID, &node.ObjectTypeID, &node.Name, &node.Status); err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to scan graph node: %w", err)
+		}
+		byID[node.ID] = &node
+	}
+
+	nodes := make([]*models.GraphNode, 0, len(ids))
+	for depth, id := range ids {
+		if node, ok := byID[id]; ok {
+			node.Depth = depth
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
+// graphEdgesByID loads the given relationships as edges, in the order of ids
+func (r *relationshipRepository) graphEdgesByID(ctx context.Context, ids []int64) ([]*models.GraphEdge, error) {
+	edges, err := r.queryGraphEdges(ctx, "WHERE r.object_id = ANY($1)", ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*models.GraphEdge, len(edges))
+	for _, edge := range edges {
+		byID[edge.ID] = edge
+	}
+
+	ordered := make([]*models.GraphEdge, 0, len(ids))
+	for _, id := range ids {
+		if edge, ok := byID[id]; ok {
+			ordered = append(ordered, edge)
+		}
+	}
+	return ordered, nil
+}
+
+func (r *relationshipRepository) queryGraphEdges(ctx context.Context, where string, args ...interface{}) ([]*models.GraphEdge, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT r.object_id, ro.public_id, r.source_object_id, s.public_id,
+		       r.target_object_id, t.public_id, rt.type_key, r.status
+		FROM objects_service.objects_relationships r
+		JOIN objects_service.objects ro ON ro.id = r.object_id
+		JOIN objects_service.objects s ON s.id = r.source_object_id
+		JOIN objects_service.objects t ON t.id = r.target_object_id
+		JOIN objects_service.objects_relationship_types rt ON rt.object_id = r.relationship_type_id
+		`+where, args...)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to query graph edges: %w", err)
+	}
+	defer rows.Close()
+
+	var edges []*models.GraphEdge
+	for rows.Next() {
+		var edge models.GraphEdge
+		err := rows.Scan(
+			&edge.ID, &edge.PublicID, &edge.SourceObjectID, &edge.SourceObjectPublicID,
+			&edge.TargetObjectID, &edge.TargetObjectPublicID, &edge.TypeKey, &edge.Status,
+		)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to scan graph edge: %w", err)
+		}
+		edges = append(edges, &edge)
+	}
+	return edges, nil
+}
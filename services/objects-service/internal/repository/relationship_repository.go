@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -13,6 +14,12 @@ import (
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
 )
 
+// liveRelationship leaves out relationships in the trash, whose base object was soft-deleted
+// together with one of their ends; r is the objects_relationships row
+const liveRelationship = `EXISTS (
+	SELECT 1 FROM objects_service.objects ro WHERE ro.id = r.object_id AND ro.deleted_at IS NULL
+)`
+
 type RelationshipRepository interface {
 	Repository
 
@@ -26,6 +33,8 @@ type RelationshipRepository interface {
 	List(ctx context.Context, filter *models.RelationshipFilter) ([]*models.Relationship, error)
 	GetForObject(ctx context.Context, objectPublicID uuid.UUID, filter *models.RelationshipFilterForType) ([]*models.Relationship, error)
 	GetForObjectByType(ctx context.Context, objectPublicID uuid.UUID, typeKey string) ([]*models.Relationship, error)
+	ListForObjects(ctx context.Context, objectIDs []int64) ([]*models.Relationship, error)
+	ListDeletedForObjects(ctx context.Context, objectIDs []int64) ([]*models.Relationship, error)
 	GetRelatedObjects(ctx context.Context, objectPublicID uuid.UUID, typeKey *string) ([]*models.Object, error)
 
 	Exists(ctx context.Context, sourceObjectID, targetObjectID int64, typeObjectID int64) (bool, error)
@@ -33,6 +42,22 @@ type RelationshipRepository interface {
 	GetByTypeKey(ctx context.Context, typeKey string) ([]*models.Relationship, error)
 
 	CheckCircular(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error)
+
+	CountInDirection(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error)
+	ObjectTypeNames(ctx context.Context, objectIDs []int64) (map[int64][]string, error)
+	LockType(ctx context.Context, typeObjectID int64) error
+	LockObjects(ctx context.Context, typeObjectID int64, objectIDs ...int64) error
+
+	TraverseNodes(ctx context.Context, startObjectID int64, query *models.GraphQuery) ([]*models.GraphNode, bool, error)
+	EdgesBetween(ctx context.Context, objectIDs []int64, typeKeys []string, limit int) ([]*models.GraphEdge, bool, error)
+	ShortestPath(ctx context.Context, sourceObjectID, targetObjectID int64, query *models.GraphQuery) (*models.GraphPath, error)
+
+	// ExportRelationships reads through a cursor and needs a transaction
+	ExportRelationships(ctx context.Context, filter *models.RelationshipExportFilter, batchSize int, fn func([]*models.RelationshipRecord) error) error
+
+	// Relationships go to the trash with their ends; these take them out again or for good
+	Restore(ctx context.Context, objectIDs []int64) error
+	PurgeDeletedBefore(ctx context.Context, deletedBefore time.Time) (int, error)
 }
 
 type relationshipRepository struct {
@@ -183,6 +208,11 @@ func (r *relationshipRepository) Create(ctx context.Context, input *models.Creat
 		return nil, fmt.Errorf("failed to create relationship: %w", err)
 	}
 
+	if err := recordHistory(ctx, r.db, models.HistoryEntityRelationship, models.HistoryActionCreate, []int64{objectID}); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
 	rel.PublicID = baseObject.PublicID
 
 	rel.RelationshipMetadata = relMeta
@@ -207,7 +237,7 @@ func (r *relationshipRepository) GetByObjectID(ctx context.Context, objectID int
 		JOIN objects_service.objects s ON r.source_object_id = s.id
 		JOIN objects_service.objects t ON r.target_object_id = t.id
 		JOIN objects_service.objects_relationship_types rt ON r.relationship_type_id = rt.object_id
-		WHERE r.object_id = $1`
+		WHERE r.object_id = $1 AND o.deleted_at IS NULL`
 
 	var rel models.Relationship
 	var metadata []byte
@@ -235,7 +265,7 @@ func (r *relationshipRepository) GetByPublicID(ctx context.Context, publicID uui
 
 	var objectID int64
 	err := r.db.QueryRow(ctx, `
-		SELECT id FROM objects_service.objects WHERE public_id = $1 AND object_type_id = (
+		SELECT id FROM objects_service.objects WHERE public_id = $1 AND deleted_at IS NULL AND object_type_id = (
 			SELECT id FROM objects_service.object_types WHERE name = 'Relationship'
 		)
 	`, publicID).Scan(&objectID)
@@ -288,13 +318,15 @@ func (r *relationshipRepository) Update(ctx context.Context, objectID int64, inp
 	}
 
 	args = append(args, objectID)
+	pinned, pinnedArgs := pinnedCondition(ctx, preconditionRelationship, objectID, argNum+1)
+	args = append(args, pinnedArgs...)
 	query := fmt.Sprintf(`
 		UPDATE objects_service.objects_relationships
 		SET %s
-		WHERE object_id = $%d
+		WHERE object_id = $%d%s
 		RETURNING object_id, source_object_id, target_object_id, relationship_type_id,
 			status, relationship_metadata, created_by, updated_by, created_at, updated_at`,
-		strings.Join(updates, ", "), argNum)
+		strings.Join(updates, ", "), argNum, pinned)
 
 	var rel models.Relationship
 	var metadata []byte
@@ -306,9 +338,17 @@ func (r *relationshipRepository) Update(ctx context.Context, objectID int64, inp
 	)
 	if err != nil {
 		r.metrics.ErrorCount++
+		if pinned != "" && errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrOptimisticLock
+		}
 		return nil, fmt.Errorf("failed to update relationship: %w", err)
 	}
 
+	if err := recordHistory(ctx, r.db, models.HistoryEntityRelationship, models.HistoryActionUpdate, []int64{objectID}); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+
 	rel.RelationshipMetadata = metadata
 	rel.SourceObjectPublicID = current.SourceObjectPublicID
 	rel.TargetObjectPublicID = current.TargetObjectPublicID
@@ -320,7 +360,20 @@ func (r *relationshipRepository) Update(ctx context.Context, objectID int64, inp
 func (r *relationshipRepository) Delete(ctx context.Context, objectID int64) error {
 	r.metrics.QueryCount++
 
-	_, err := r.db.Exec(ctx, `DELETE FROM objects_service.objects_relationships WHERE object_id = $1`, objectID)
+	// Both rows are removed for good, so their final state is recorded first
+	if err := recordHistory(ctx, r.db, models.HistoryEntityRelationship, models.HistoryActionDelete, []int64{objectID}); err != nil {
+		r.metrics.ErrorCount++
+		return err
+	}
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionDelete, []int64{objectID}); err != nil {
+		r.metrics.ErrorCount++
+		return err
+	}
+
+	err := execPinned(ctx, r.db, preconditionRelationship, objectID, `DELETE FROM objects_service.objects_relationships WHERE object_id = $1`, objectID)
+	if errors.Is(err, ErrOptimisticLock) {
+		return err
+	}
 	if err != nil {
 		r.metrics.ErrorCount++
 		return fmt.Errorf("failed to delete relationship: %w", err)
@@ -356,7 +409,7 @@ func (r *relationshipRepository) List(ctx context.Context, filter *models.Relati
 		filter.PageSize = r.options.MaxPageSize
 	}
 
-	whereClauses := []string{"1=1"}
+	whereClauses := []string{"o.deleted_at IS NULL"}
 	args := []interface{}{}
 	argNum := 1
 
@@ -466,7 +519,7 @@ func (r *relationshipRepository) GetForObject(ctx context.Context, objectPublicI
 		filter.PageSize = r.options.MaxPageSize
 	}
 
-	whereClauses := []string{"(r.source_object_id = $1 OR r.target_object_id = $1)"}
+	whereClauses := []string{"(r.source_object_id = $1 OR r.target_object_id = $1)", "o.deleted_at IS NULL"}
 	args := []interface{}{object.ID}
 	argNum := 2
 
@@ -527,6 +580,69 @@ func (r *relationshipRepository) GetForObject(ctx context.Context, objectPublicI
 	return rels, nil
 }
 
+// ListForObjects returns every relationship with its source or target among the objects, oldest
+// first. Unlike GetForObject it is not paginated; relationships to deleted objects are left out.
+func (r *relationshipRepository) ListForObjects(ctx context.Context, objectIDs []int64) ([]*models.Relationship, error) {
+	return r.listForObjects(ctx, objectIDs, "o.deleted_at IS NULL")
+}
+
+// ListDeletedForObjects returns the relationships in the trash with their source or target among
+// the objects and both ends live again, oldest first: those that can be restored
+func (r *relationshipRepository) ListDeletedForObjects(ctx context.Context, objectIDs []int64) ([]*models.Relationship, error) {
+	return r.listForObjects(ctx, objectIDs, "o.deleted_at IS NOT NULL")
+}
+
+func (r *relationshipRepository) listForObjects(ctx context.Context, objectIDs []int64, trashCondition string) ([]*models.Relationship, error) {
+	r.metrics.QueryCount++
+
+	if len(objectIDs) == 0 {
+		return []*models.Relationship{}, nil
+	}
+
+	query := `
+		SELECT
+			r.object_id, o.public_id, r.source_object_id, r.target_object_id, r.relationship_type_id,
+			r.status, r.relationship_metadata, r.created_by, r.updated_by,
+			r.created_at, r.updated_at,
+			s.public_id, t.public_id, rt.type_key
+		FROM objects_service.objects_relationships r
+		JOIN objects_service.objects o ON r.object_id = o.id
+		JOIN objects_service.objects s ON r.source_object_id = s.id
+		JOIN objects_service.objects t ON r.target_object_id = t.id
+		JOIN objects_service.objects_relationship_types rt ON r.relationship_type_id = rt.object_id
+		WHERE (r.source_object_id = ANY($1) OR r.target_object_id = ANY($1))
+			AND ` + trashCondition + ` AND s.deleted_at IS NULL AND t.deleted_at IS NULL
+		ORDER BY r.object_id`
+
+	rows, err := r.db.Query(ctx, query, objectIDs)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to list relationships for objects: %w", err)
+	}
+	defer rows.Close()
+
+	rels := []*models.Relationship{}
+	for rows.Next() {
+		var rel models.Relationship
+		var metadata []byte
+
+		err := rows.Scan(
+			&rel.ObjectID, &rel.PublicID, &rel.SourceObjectID, &rel.TargetObjectID, &rel.RelationshipTypeID,
+			&rel.Status, &metadata, &rel.CreatedBy, &rel.UpdatedBy,
+			&rel.CreatedAt, &rel.UpdatedAt,
+			&rel.SourceObjectPublicID, &rel.TargetObjectPublicID, &rel.RelationshipTypeKey,
+		)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to scan relationship: %w", err)
+		}
+		rel.RelationshipMetadata = metadata
+		rels = append(rels, &rel)
+	}
+
+	return rels, nil
+}
+
 func (r *relationshipRepository) GetForObjectByType(ctx context.Context, objectPublicID uuid.UUID, typeKey string) ([]*models.Relationship, error) {
 	r.metrics.QueryCount++
 
@@ -548,7 +664,7 @@ func (r *relationshipRepository) GetForObjectByType(ctx context.Context, objectP
 		JOIN objects_service.objects t ON r.target_object_id = t.id
 		JOIN objects_service.objects_relationship_types rt ON r.relationship_type_id = rt.object_id
 		WHERE (r.source_object_id = $1 OR r.target_object_id = $1)
-			AND rt.type_key = $2
+			AND rt.type_key = $2 AND o.deleted_at IS NULL
 		ORDER BY r.created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, object.ID, typeKey)
@@ -594,29 +710,37 @@ func (r *relationshipRepository) GetRelatedObjects(ctx context.Context, objectPu
 
 	if typeKey != nil && *typeKey != "" {
 		query = `
-			SELECT DISTINCT o.*
+			SELECT DISTINCT o.id, o.public_id, o.object_type_id, o.parent_object_id, o.name, o.description,
+				o.metadata, o.tags, o.status, o.version, o.created_by, o.updated_by,
+				o.created_at, o.updated_at, o.deleted_at
 			FROM objects_service.objects_relationships r
 			JOIN objects_service.objects o ON r.target_object_id = o.id
 			JOIN objects_service.objects_relationship_types rt ON r.relationship_type_id = rt.object_id
-			WHERE r.source_object_id = $1 AND rt.type_key = $2
+			WHERE r.source_object_id = $1 AND rt.type_key = $2 AND o.deleted_at IS NULL AND ` + liveRelationship + `
 			UNION
-			SELECT DISTINCT o.*
+			SELECT DISTINCT o.id, o.public_id, o.object_type_id, o.parent_object_id, o.name, o.description,
+				o.metadata, o.tags, o.status, o.version, o.created_by, o.updated_by,
+				o.created_at, o.updated_at, o.deleted_at
 			FROM objects_service.objects_relationships r
 			JOIN objects_service.objects o ON r.source_object_id = o.id
 			JOIN objects_service.objects_relationship_types rt ON r.relationship_type_id = rt.object_id
-			WHERE r.target_object_id = $1 AND rt.type_key = $2`
+			WHERE r.target_object_id = $1 AND rt.type_key = $2 AND o.deleted_at IS NULL AND ` + liveRelationship
 		rows, err = r.db.Query(ctx, query, object.ID, *typeKey)
 	} else {
 		query = `
-			SELECT DISTINCT o.*
+			SELECT DISTINCT o.id, o.public_id, o.object_type_id, o.parent_object_id, o.name, o.description,
+				o.metadata, o.tags, o.status, o.version, o.created_by, o.updated_by,
+				o.created_at, o.updated_at, o.deleted_at
 			FROM objects_service.objects_relationships r
 			JOIN objects_service.objects o ON r.target_object_id = o.id
-			WHERE r.source_object_id = $1
+			WHERE r.source_object_id = $1 AND o.deleted_at IS NULL AND ` + liveRelationship + `
 			UNION
-			SELECT DISTINCT o.*
+			SELECT DISTINCT o.id, o.public_id, o.object_type_id, o.parent_object_id, o.name, o.description,
+				o.metadata, o.tags, o.status, o.version, o.created_by, o.updated_by,
+				o.created_at, o.updated_at, o.deleted_at
 			FROM objects_service.objects_relationships r
 			JOIN objects_service.objects o ON r.source_object_id = o.id
-			WHERE r.target_object_id = $1`
+			WHERE r.target_object_id = $1 AND o.deleted_at IS NULL AND ` + liveRelationship
 		rows, err = r.db.Query(ctx, query, object.ID)
 	}
 
@@ -648,6 +772,7 @@ func (r *relationshipRepository) GetRelatedObjects(ctx context.Context, objectPu
 func (r *relationshipRepository) Exists(ctx context.Context, sourceObjectID, targetObjectID int64, typeObjectID int64) (bool, error) {
 	r.metrics.QueryCount++
 
+	// Relationships in the trash count too, as they still hold their place in unique_relationship
 	var exists bool
 	err := r.db.QueryRow(ctx, `
 		SELECT EXISTS(
@@ -675,13 +800,12 @@ func (r *relationshipRepository) CountForObject(ctx context.Context, objectID in
 			FROM objects_service.objects_relationships r
 			JOIN objects_service.objects_relationship_types rt ON r.relationship_type_id = rt.object_id
 			WHERE (r.source_object_id = $1 OR r.target_object_id = $1) AND rt.type_key = $2
-		`, objectID, *typeKey).Scan(&count)
+				AND `+liveRelationship, objectID, *typeKey).Scan(&count)
 	} else {
 		err = r.db.QueryRow(ctx, `
 			SELECT COUNT(*)
-			FROM objects_service.objects_relationships
-			WHERE source_object_id = $1 OR target_object_id = $1
-		`, objectID).Scan(&count)
+			FROM objects_service.objects_relationships r
+			WHERE (r.source_object_id = $1 OR r.target_object_id = $1) AND `+liveRelationship, objectID).Scan(&count)
 	}
 
 	if err != nil {
@@ -706,7 +830,7 @@ func (r *relationshipRepository) GetByTypeKey(ctx context.Context, typeKey strin
 		JOIN objects_service.objects s ON r.source_object_id = s.id
 		JOIN objects_service.objects t ON r.target_object_id = t.id
 		JOIN objects_service.objects_relationship_types rt ON r.relationship_type_id = rt.object_id
-		WHERE rt.type_key = $1
+		WHERE rt.type_key = $1 AND o.deleted_at IS NULL
 		ORDER BY r.created_at DESC`
 
 	rows, err := r.db.Query(ctx, query, typeKey)
@@ -764,21 +888,21 @@ func (r *relationshipRepository) CheckCircular(ctx context.Context, sourceObject
 	}
 
 	var isCircular bool
-	err = r.db.QueryRow(ctx, `
+	err = r.db.QueryRow(ctx, fmt.Sprintf(`
 		WITH RECURSIVE relationship_path AS (
-			SELECT target_object_id, 1 as depth
-			FROM objects_service.objects_relationships
-			WHERE source_object_id = $1 AND relationship_type_id = $2
+			SELECT r.target_object_id, 1 as depth
+			FROM objects_service.objects_relationships r
+			WHERE r.source_object_id = $1 AND r.relationship_type_id = $2 AND %[1]s
 
 			UNION
 
 			SELECT r.target_object_id, rp.depth + 1
 			FROM objects_service.objects_relationships r
 			INNER JOIN relationship_path rp ON r.source_object_id = rp.target_object_id
-			WHERE r.relationship_type_id = $2 AND rp.depth < 100
+			WHERE r.relationship_type_id = $2 AND rp.depth < 100 AND %[1]s
 		)
 		SELECT EXISTS(SELECT 1 FROM relationship_path WHERE target_object_id = $1)
-	`, targetObjectID, typeObjectID).Scan(&isCircular)
+	`, liveRelationship), targetObjectID, typeObjectID).Scan(&isCircular)
 	if err != nil {
 		r.metrics.ErrorCount++
 		return false, fmt.Errorf("failed to check circular relationship: %w", err)
@@ -786,3 +910,173 @@ func (r *relationshipRepository) CheckCircular(ctx context.Context, sourceObject
 
 	return isCircular, nil
 }
+
+// CountInDirection counts the relationships of one type an object takes part in, as the source
+// for DirectionOutgoing and as the target for DirectionIncoming
+func (r *relationshipRepository) CountInDirection(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
+	r.metrics.QueryCount++
+
+	column := "source_object_id"
+	if direction == models.DirectionIncoming {
+		column = "target_object_id"
+	}
+
+	var count int
+	err := r.db.QueryRow(ctx, fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM objects_service.objects_relationships r
+		WHERE %s = $1 AND relationship_type_id = $2 AND %s
+	`, column, liveRelationship), objectID, typeObjectID).Scan(&count)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return 0, fmt.Errorf("failed to count relationships: %w", err)
+	}
+
+	return count, nil
+}
+
+// ObjectTypeNames returns, for each object, the name of its object type followed by the names
+// of the type's ancestors up to the root
+func (r *relationshipRepository) ObjectTypeNames(ctx context.Context, objectIDs []int64) (map[int64][]string, error) {
+	r.metrics.QueryCount++
+
+	rows, err := r.db.Query(ctx, `
+		WITH RECURSIVE lineage(object_id, type_id, depth) AS (
+			SELECT id, object_type_id, 0
+			FROM objects_service.objects
+			WHERE id = ANY($1)
+			UNION ALL
+			SELECT l.object_id, t.parent_type_id, l.depth + 1
+			FROM lineage l
+			JOIN objects_service.object_types t ON t.id = l.type_id
+			WHERE t.parent_type_id IS NOT NULL AND l.depth < 100
+		)
+		SELECT l.object_id, t.name
+		FROM lineage l
+		JOIN objects_service.object_types t ON t.id = l.type_id
+		ORDER BY l.object_id, l.depth`, objectIDs)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to get object type names: %w", err)
+	}
+	defer rows.Close()
+
+	names := make(map[int64][]string, len(objectIDs))
+	for rows.Next() {
+		var objectID int64
+		var name string
+		if err := rows.Scan(&objectID, &name); err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to scan object type name: %w", err)
+		}
+		names[objectID] = append(names[objectID], name)
+	}
+	return names, nil
+}
+
+// LockType takes a transaction-scoped advisory lock on a relationship type, serializing
+// changes that have to see every relationship of the type, such as cycle checks. It must be
+// taken before any LockObjects lock in the same transaction.
+func (r *relationshipRepository) LockType(ctx context.Context, typeObjectID int64) error {
+	return r.advisoryLock(ctx, fmt.Sprintf("objects_relationships:%d", typeObjectID))
+}
+
+// LockObjects takes transaction-scoped advisory locks on the relationships of one type held by
+// each object. The locks are taken in ascending object order so that concurrent callers cannot
+// deadlock. Outside a transaction the locks are released as soon as they are taken.
+func (r *relationshipRepository) LockObjects(ctx context.Context, typeObjectID int64, objectIDs ...int64) error {
+	ids := append([]int64(nil), objectIDs...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
+		}
+		if err := r.advisoryLock(ctx, fmt.Sprintf("objects_relationships:%d:%d", typeObjectID, id)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *relationshipRepository) advisoryLock(ctx context.Context, key string) error {
+	r.metrics.QueryCount++
+
+	if _, err := r.db.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, key); err != nil {
+		r.metrics.ErrorCount++
+		return fmt.Errorf("failed to lock relationships: %w", err)
+	}
+	return nil
+}
+
+// Restore takes relationships out of the trash. Their history is left as it is: it records the
+// relationship rows, which the trash does not change.
+func (r *relationshipRepository) Restore(ctx context.Context, objectIDs []int64) error {
+	r.metrics.QueryCount++
+
+	if len(objectIDs) == 0 {
+		return nil
+	}
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE objects_service.objects
+		SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ANY($1::bigint[]) AND deleted_at IS NOT NULL`, objectIDs)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return fmt.Errorf("failed to restore relationships: %w", err)
+	}
+	return nil
+}
+
+// PurgeDeletedBefore removes relationships that went to the trash before deletedBefore for good,
+// recording their final state first, and returns how many it removed
+func (r *relationshipRepository) PurgeDeletedBefore(ctx context.Context, deletedBefore time.Time) (int, error) {
+	r.metrics.QueryCount++
+
+	rows, err := r.db.Query(ctx, `
+		SELECT o.id
+		FROM objects_service.objects o
+		JOIN objects_service.objects_relationships r ON r.object_id = o.id
+		WHERE o.deleted_at < $1
+		FOR UPDATE OF o`, deletedBefore)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return 0, fmt.Errorf("failed to list expired relationships: %w", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			r.metrics.ErrorCount++
+			return 0, fmt.Errorf("failed to scan relationship id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.metrics.ErrorCount++
+		return 0, fmt.Errorf("failed to list expired relationships: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := recordHistory(ctx, r.db, models.HistoryEntityRelationship, models.HistoryActionPurge, ids); err != nil {
+		r.metrics.ErrorCount++
+		return 0, err
+	}
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionPurge, ids); err != nil {
+		r.metrics.ErrorCount++
+		return 0, err
+	}
+
+	// Removing the base objects removes their relationship rows with them
+	_, err = r.db.Exec(ctx, `DELETE FROM objects_service.objects WHERE id = ANY($1::bigint[])`, ids)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return 0, fmt.Errorf("failed to purge relationships: %w", err)
+	}
+	return len(ids), nil
+}
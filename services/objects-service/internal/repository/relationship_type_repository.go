@@ -24,6 +24,7 @@ type RelationshipTypeRepository interface {
 	List(ctx context.Context, filter *models.RelationshipTypeFilter) ([]*models.RelationshipType, error)
 	Exists(ctx context.Context, typeKey string) (bool, error)
 	GetByReverseTypeKey(ctx context.Context, reverseKey string) (*models.RelationshipType, error)
+	HasRelationships(ctx context.Context, id int64) (bool, error)
 }
 
 // relationshipTypeRepository implements RelationshipTypeRepository
@@ -259,7 +260,8 @@ func (r *relationshipTypeRepository) Update(ctx context.Context, id int64, input
 	}
 
 	if input.ReverseTypeKey != nil {
-		updates = append(updates, fmt.Sprintf("reverse_type_key = $%d", argNum))
+		// An empty reverse_type_key clears the pairing
+		updates = append(updates, fmt.Sprintf("reverse_type_key = NULLIF($%d, '')", argNum))
 		args = append(args, *input.ReverseTypeKey)
 		argNum++
 	}
@@ -515,3 +517,19 @@ func (r *relationshipTypeRepository) GetByReverseTypeKey(ctx context.Context, re
 	rt.ReverseTypeKey = reverseTypeKey
 	return &rt, nil
 }
+
+// HasRelationships checks if any relationship is stored under a relationship type
+func (r *relationshipTypeRepository) HasRelationships(ctx context.Context, id int64) (bool, error) {
+	r.metrics.QueryCount++
+
+	var exists bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM objects_service.objects_relationships WHERE relationship_type_id = $1)
+	`, id).Scan(&exists)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return false, fmt.Errorf("failed to check relationships of relationship type: %w", err)
+	}
+
+	return exists, nil
+}
@@ -2,9 +2,13 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
@@ -45,6 +49,13 @@ func (m *MockRow) Scan(dest ...any) error {
 	return nil
 }
 
+// errRow is a Row whose Scan fails with err
+type errRow struct{ err error }
+
+func (r errRow) Scan(dest ...any) error {
+	return r.err
+}
+
 // MockRows implements Rows for testing
 type MockRows struct {
 	CloseFunc func()
@@ -300,6 +311,24 @@ func TestObjectTypeRepository_ValidateMove(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// TestObjectTypeRepository_ValidateMove_ChecksNewParentAncestors tests that cycles are found by
+// walking up from the new parent
+func TestObjectTypeRepository_ValidateMove_ChecksNewParentAncestors(t *testing.T) {
+	var ancestorsOf any
+	mockDB := &MockDBPool{
+		QueryFunc: func(ctx context.Context, sql string, args ...any) (Rows, error) {
+			ancestorsOf = args[0]
+			return &MockRows{NextFunc: func() bool { return false }}, nil
+		},
+	}
+
+	repo := NewObjectTypeRepository(mockDB, DefaultRepositoryOptions())
+
+	newParentID := int64(2)
+	assert.NoError(t, repo.ValidateMove(context.Background(), 1, &newParentID))
+	assert.Equal(t, int64(2), ancestorsOf)
+}
+
 // TestObjectTypeRepository_ValidateMove_ToSelf tests moving to self
 func TestObjectTypeRepository_ValidateMove_ToSelf(t *testing.T) {
 	mockDB := &MockDBPool{}
@@ -414,7 +443,7 @@ func TestObjectRepository_BulkUpdate(t *testing.T) {
 		Name: &name,
 	}
 
-	result, err := repo.BulkUpdate(context.Background(), ids, updates)
+	result, err := repo.BulkUpdate(context.Background(), ids, updates, nil)
 	assert.NoError(t, err)
 	assert.Nil(t, result)
 }
@@ -426,7 +455,7 @@ func TestObjectRepository_BulkUpdate_EmptyIds(t *testing.T) {
 	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
 	updates := &models.UpdateObjectRequest{}
 
-	result, err := repo.BulkUpdate(context.Background(), []int64{}, updates)
+	result, err := repo.BulkUpdate(context.Background(), []int64{}, updates, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Len(t, result, 0)
@@ -507,3 +536,808 @@ func TestPGDatabaseCreation(t *testing.T) {
 	var db *PGDatabase
 	assert.Nil(t, db)
 }
+
+// TestObjectRepository_UpdateMetadata_RecordsHistory tests that writes snapshot the object into its history
+func TestObjectRepository_UpdateMetadata_RecordsHistory(t *testing.T) {
+	var statements []string
+	var historyArgs []any
+	mockDB := &MockDBPool{
+		ExecFunc: func(ctx context.Context, sql string, args ...any) (CommandTag, error) {
+			statements = append(statements, sql)
+			if strings.Contains(sql, "object_history") {
+				historyArgs = args
+			}
+			return nil, nil
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+	ctx := WithChangeContext(context.Background(), ChangeContext{RequestID: "req-1", Actor: "user-1"})
+
+	err := repo.UpdateMetadata(ctx, 7, map[string]interface{}{"key": "value"}, "user-1")
+
+	assert.NoError(t, err)
+	assert.Len(t, statements, 2)
+	assert.Contains(t, statements[1], "INSERT INTO objects_service.object_history")
+	assert.Contains(t, statements[1], "FROM objects_service.objects t")
+	assert.Equal(t, []any{models.HistoryEntityObject, models.HistoryActionUpdate, []int64{7}, "user-1", "req-1"}, historyArgs)
+}
+
+// TestRecordHistory_ActionOverride tests that a restore context relabels updates but not deletes
+func TestRecordHistory_ActionOverride(t *testing.T) {
+	var actions []any
+	mockDB := &MockDBPool{
+		ExecFunc: func(ctx context.Context, sql string, args ...any) (CommandTag, error) {
+			actions = append(actions, args[1])
+			return nil, nil
+		},
+	}
+	ctx := WithChangeContext(context.Background(), ChangeContext{Action: models.HistoryActionRestore})
+
+	assert.NoError(t, recordHistory(ctx, mockDB, models.HistoryEntityRelationship, models.HistoryActionUpdate, []int64{1}))
+	assert.NoError(t, recordHistory(ctx, mockDB, models.HistoryEntityRelationship, models.HistoryActionDelete, []int64{1}))
+	assert.NoError(t, recordHistory(ctx, mockDB, models.HistoryEntityObject, models.HistoryActionUpdate, nil))
+	assert.Equal(t, []any{models.HistoryActionRestore, models.HistoryActionDelete}, actions)
+
+	assert.Error(t, recordHistory(ctx, mockDB, "object_type", models.HistoryActionUpdate, []int64{1}))
+}
+
+// TestObjectRepository_Update_ConcurrentWrite tests that losing the version race reports an optimistic lock failure
+func TestObjectRepository_Update_ConcurrentWrite(t *testing.T) {
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			if strings.Contains(query, "UPDATE objects_service.objects") {
+				return errRow{err: sql.ErrNoRows}
+			}
+			return &MockRow{}
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+	name := "renamed"
+
+	_, err := repo.Update(context.Background(), 1, &models.UpdateObjectRequest{Name: &name})
+	assert.True(t, errors.Is(err, ErrOptimisticLock))
+}
+
+// TestObjectRepository_BulkUpdate_ExpectedVersions tests that expected versions guard the update
+func TestObjectRepository_BulkUpdate_ExpectedVersions(t *testing.T) {
+	var query string
+	var queryArgs []any
+	mockDB := &MockDBPool{
+		QueryFunc: func(ctx context.Context, sql string, args ...any) (Rows, error) {
+			query, queryArgs = sql, args
+			return &MockRows{NextFunc: func() bool { return false }}, nil
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+	status := "archived"
+
+	_, err := repo.BulkUpdate(context.Background(), []int64{1, 2}, &models.UpdateObjectRequest{Status: &status}, map[int64]int64{2: 4})
+	assert.NoError(t, err)
+	assert.Contains(t, query, "unnest(")
+	assert.Equal(t, []int64{2}, queryArgs[len(queryArgs)-2])
+	assert.Equal(t, []int64{4}, queryArgs[len(queryArgs)-1])
+}
+
+// countRow is a Row that scans a single count
+type countRow struct{ n int64 }
+
+func (r countRow) Scan(dest ...any) error {
+	*dest[0].(*int64) = r.n
+	return nil
+}
+
+// TestObjectRepository_Search_FullText tests that full-text matches are ranked with snippets
+func TestObjectRepository_Search_FullText(t *testing.T) {
+	var pageQuery string
+	var pageArgs []any
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			return countRow{n: 3}
+		},
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			pageQuery, pageArgs = query, args
+			return &MockRows{}, nil
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+	typeID := int64(4)
+
+	results, err := repo.Search(context.Background(), &models.ObjectSearchFilter{
+		Query: "red widget", ObjectTypeID: &typeID, Status: "active", Limit: 20, Offset: 40,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), results.Total)
+	assert.Equal(t, models.SearchMatchFullText, results.MatchType)
+	assert.Contains(t, pageQuery, "websearch_to_tsquery")
+	assert.Contains(t, pageQuery, "ts_headline")
+	assert.Contains(t, pageQuery, "WITH RECURSIVE subtree")
+	assert.Equal(t, []any{"red widget", int64(4), "active", 20, 40}, pageArgs)
+}
+
+// TestObjectRepository_Search_FuzzyFallback tests that trigram matching is used when full-text finds nothing
+func TestObjectRepository_Search_FuzzyFallback(t *testing.T) {
+	var pageQuery string
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			if strings.Contains(query, "@@") {
+				return countRow{n: 0}
+			}
+			return countRow{n: 1}
+		},
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			pageQuery = query
+			return &MockRows{}, nil
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+
+	results, err := repo.Search(context.Background(), &models.ObjectSearchFilter{Query: "widgte", Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), results.Total)
+	assert.Equal(t, models.SearchMatchFuzzy, results.MatchType)
+	assert.Contains(t, pageQuery, "similarity(o.name, $1)")
+	assert.NotContains(t, pageQuery, "@@")
+}
+
+// TestObjectRepository_List_Filter tests that a filter expression is numbered after the other filters
+// and that the count and page queries share the same conditions
+func TestObjectRepository_List_Filter(t *testing.T) {
+	var countQuery, pageQuery string
+	var countArgs, pageArgs []any
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			countQuery, countArgs = query, args
+			return countRow{n: 1}
+		},
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			pageQuery, pageArgs = query, args
+			return &MockRows{}, nil
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+	typeID := int64(7)
+
+	_, total, err := repo.List(context.Background(), &models.ObjectFilter{
+		ObjectTypeID: &typeID,
+		Status:       "active",
+		Filter:       `metadata.amount gt 1000 and tags has "urgent"`,
+		Limit:        20,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Contains(t, countQuery, "object_type_id = $1 AND status = $2 AND ((CASE WHEN jsonb_typeof(metadata #> $3::text[])")
+	assert.Contains(t, countQuery, "$5 = ANY(tags)) AND deleted_at IS NULL")
+	assert.Equal(t, []any{int64(7), "active", []string{"amount"}, "1000", "urgent"}, countArgs)
+	assert.Contains(t, pageQuery, "ORDER BY created_at DESC LIMIT $6")
+	assert.Equal(t, append(countArgs, 20), pageArgs)
+}
+
+// TestObjectRepository_List_InvalidInput tests that bad filters and sort columns never reach the database
+func TestObjectRepository_List_InvalidInput(t *testing.T) {
+	repo := NewObjectRepository(&MockDBPool{}, DefaultRepositoryOptions())
+
+	_, _, err := repo.List(context.Background(), &models.ObjectFilter{Filter: `colour = "red"`})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, _, err = repo.List(context.Background(), &models.ObjectFilter{SortBy: "name; DROP TABLE objects"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+// TestObjectRepository_Aggregate tests that dimensions, histograms and metrics are numbered after
+// the filter and that the total is counted without the tag join
+func TestObjectRepository_Aggregate(t *testing.T) {
+	var countQuery, aggregateQuery string
+	var countArgs, aggregateArgs []any
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			countQuery, countArgs = query, args
+			return countRow{n: 5}
+		},
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			aggregateQuery, aggregateArgs = query, args
+			return &MockRows{NextFunc: rowCount(3)}, nil
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+
+	result, err := repo.Aggregate(context.Background(), &models.ObjectAggregationRequest{
+		Filter:        models.ObjectFilter{Status: "active"},
+		GroupBy:       []string{"tag", "metadata.region"},
+		Metrics:       []models.AggregationMetric{{Op: "sum", Field: "metadata.amount"}},
+		DateHistogram: &models.DateHistogram{Field: "created_at", Interval: "month"},
+		Size:          2,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), result.Total)
+	assert.Len(t, result.Buckets, 2)
+	assert.True(t, result.Truncated)
+	assert.Equal(t, "SELECT COUNT(*) FROM objects_service.objects o WHERE status = $1 AND deleted_at IS NULL", countQuery)
+	assert.Equal(t, []any{"active"}, countArgs)
+	assert.Contains(t, aggregateQuery, "SELECT tag.value, o.metadata #> $2::text[], date_trunc($3, o.created_at), COUNT(*), SUM(")
+	assert.Contains(t, aggregateQuery, "LEFT JOIN LATERAL unnest(o.tags)")
+	assert.Contains(t, aggregateQuery, "GROUP BY 1, 2, 3 ORDER BY 3, 4 DESC LIMIT $5")
+	assert.Equal(t, []any{"active", []string{"region"}, "month", []string{"amount"}, 3}, aggregateArgs)
+}
+
+// TestObjectRepository_Aggregate_InvalidInput tests that unknown names never reach the database
+func TestObjectRepository_Aggregate_InvalidInput(t *testing.T) {
+	repo := NewObjectRepository(&MockDBPool{}, DefaultRepositoryOptions())
+	ctx := context.Background()
+
+	for _, req := range []*models.ObjectAggregationRequest{
+		{GroupBy: []string{"name; DROP TABLE objects"}},
+		{GroupBy: []string{"status", "status"}},
+		{GroupBy: []string{"metadata."}},
+		{Metrics: []models.AggregationMetric{{Op: "median", Field: "metadata.amount"}}},
+		{Metrics: []models.AggregationMetric{{Op: "sum", Field: "version"}}},
+		{DateHistogram: &models.DateHistogram{Field: "deleted_at", Interval: "day"}},
+		{DateHistogram: &models.DateHistogram{Field: "created_at", Interval: "fortnight"}},
+	} {
+		_, err := repo.Aggregate(ctx, req)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	}
+}
+
+// TestObjectRepository_Search_Facets tests that each facet counts the hits of the search
+func TestObjectRepository_Search_Facets(t *testing.T) {
+	var facetQueries []string
+	var facetArgs [][]any
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			return countRow{n: 0}
+		},
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			facetQueries = append(facetQueries, query)
+			facetArgs = append(facetArgs, args)
+			return &MockRows{}, nil
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+
+	results, err := repo.Search(context.Background(), &models.ObjectSearchFilter{
+		Query: "widget", Facets: []string{"status", "tag"}, FacetSize: 10, Limit: 20,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), results.Total)
+	assert.Len(t, results.Facets, 2)
+	assert.Len(t, facetQueries, 2)
+	assert.Contains(t, facetQueries[0], "SELECT o.status, COUNT(*)")
+	assert.Contains(t, facetQueries[0], "GROUP BY 1 ORDER BY 2 DESC, 1 LIMIT $2")
+	assert.Contains(t, facetQueries[1], "LEFT JOIN LATERAL unnest(o.tags)")
+	assert.Equal(t, []any{"widget", 10}, facetArgs[0])
+}
+
+// TestObjectRepository_ListDeleted_Filter tests that the trash lists deleted objects only, without
+// relationships
+func TestObjectRepository_ListDeleted_Filter(t *testing.T) {
+	var countQuery, pageQuery string
+	var pageArgs []any
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			countQuery = query
+			return countRow{n: 2}
+		},
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			pageQuery, pageArgs = query, args
+			return &MockRows{}, nil
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+	typeID := int64(7)
+
+	_, total, err := repo.ListDeleted(context.Background(), &models.TrashFilter{ObjectTypeID: &typeID, CreatedBy: "alice", Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Contains(t, countQuery, "WHERE deleted_at IS NOT NULL AND NOT EXISTS")
+	assert.Contains(t, countQuery, "object_type_id = $1 AND created_by = $2")
+	assert.Contains(t, pageQuery, "ORDER BY deleted_at DESC, id LIMIT $3")
+	assert.Equal(t, []any{int64(7), "alice", 10}, pageArgs)
+}
+
+// TestObjectRepository_Purge_LiveObject tests that nothing is purged unless every object is deleted
+func TestObjectRepository_Purge_LiveObject(t *testing.T) {
+	mockDB := &MockDBPool{
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			assert.Contains(t, query, "deleted_at IS NOT NULL")
+			return &MockRows{NextFunc: rowCount(0)}, nil
+		},
+		ExecFunc: func(ctx context.Context, query string, args ...any) (CommandTag, error) {
+			t.Fatalf("unexpected statement: %s", query)
+			return nil, nil
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+
+	err := repo.Purge(context.Background(), []int64{42})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// rowCount returns a NextFunc that yields n rows
+func rowCount(n int) func() bool {
+	return func() bool {
+		n--
+		return n >= 0
+	}
+}
+
+// TestRelationshipRepository_TraverseNodes tests the traversal query and truncation
+func TestRelationshipRepository_TraverseNodes(t *testing.T) {
+	var traverseQuery string
+	var traverseArgs []any
+	mockDB := &MockDBPool{
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			traverseQuery, traverseArgs = query, args
+			return &MockRows{NextFunc: rowCount(3)}, nil
+		},
+	}
+
+	repo := NewRelationshipRepository(mockDB, DefaultRepositoryOptions(), nil)
+	typeID := int64(4)
+
+	nodes, truncated, err := repo.TraverseNodes(context.Background(), 10, &models.GraphQuery{
+		TypeKeys:     []string{"contains"},
+		Direction:    models.DirectionIncoming,
+		MaxDepth:     2,
+		ObjectTypeID: &typeID,
+		Limit:        2,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 2)
+	assert.True(t, truncated)
+	assert.Contains(t, traverseQuery, "WITH RECURSIVE walk")
+	assert.Contains(t, traverseQuery, "ON r.target_object_id = w.object_id")
+	assert.Contains(t, traverseQuery, "AND o.object_type_id = $4")
+	assert.Contains(t, traverseQuery, "LIMIT $5")
+	assert.Equal(t, []any{int64(10), 2, []string{"contains"}, int64(4), 3}, traverseArgs)
+}
+
+// TestRelationshipRepository_ShortestPath_NoPath tests that a missing path is reported as not found
+func TestRelationshipRepository_ShortestPath_NoPath(t *testing.T) {
+	var pathQuery string
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			pathQuery = query
+			return errRow{err: sql.ErrNoRows}
+		},
+	}
+
+	repo := NewRelationshipRepository(mockDB, DefaultRepositoryOptions(), nil)
+
+	path, err := repo.ShortestPath(context.Background(), 1, 2, &models.GraphQuery{Direction: models.DirectionBoth, MaxDepth: 4})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Nil(t, path)
+	assert.Contains(t, pathQuery, "CASE WHEN r.source_object_id = w.object_id THEN r.target_object_id ELSE r.source_object_id END")
+	assert.Contains(t, pathQuery, "LIMIT 1")
+}
+
+// TestRelationshipRepository_LockObjects tests that object locks are taken once each, in ascending order
+func TestRelationshipRepository_LockObjects(t *testing.T) {
+	var keys []any
+	mockDB := &MockDBPool{
+		ExecFunc: func(ctx context.Context, query string, args ...any) (CommandTag, error) {
+			assert.Contains(t, query, "pg_advisory_xact_lock")
+			keys = append(keys, args...)
+			return nil, nil
+		},
+	}
+
+	repo := NewRelationshipRepository(mockDB, DefaultRepositoryOptions(), nil)
+
+	assert.NoError(t, repo.LockType(context.Background(), 4))
+	assert.NoError(t, repo.LockObjects(context.Background(), 4, 9, 2, 9))
+	assert.Equal(t, []any{"objects_relationships:4", "objects_relationships:4:2", "objects_relationships:4:9"}, keys)
+}
+
+// TestRelationshipRepository_CountInDirection tests that counts are taken on the side matching the direction
+func TestRelationshipRepository_CountInDirection(t *testing.T) {
+	var countQuery string
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			countQuery = query
+			return errRow{}
+		},
+	}
+
+	repo := NewRelationshipRepository(mockDB, DefaultRepositoryOptions(), nil)
+
+	_, err := repo.CountInDirection(context.Background(), 1, 4, models.DirectionIncoming)
+	assert.NoError(t, err)
+	assert.Contains(t, countQuery, "WHERE target_object_id = $1 AND relationship_type_id = $2")
+
+	_, err = repo.CountInDirection(context.Background(), 1, 4, models.DirectionOutgoing)
+	assert.NoError(t, err)
+	assert.Contains(t, countQuery, "WHERE source_object_id = $1 AND relationship_type_id = $2")
+}
+
+// TestRelationshipRepository_ObjectTypeNames tests that type names are collected per object in hierarchy order
+func TestRelationshipRepository_ObjectTypeNames(t *testing.T) {
+	var namesQuery string
+	mockDB := &MockDBPool{
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			namesQuery = query
+			assert.Equal(t, []int64{1, 2}, args[0])
+			return &MockRows{NextFunc: rowCount(0)}, nil
+		},
+	}
+
+	repo := NewRelationshipRepository(mockDB, DefaultRepositoryOptions(), nil)
+
+	names, err := repo.ObjectTypeNames(context.Background(), []int64{1, 2})
+	assert.NoError(t, err)
+	assert.Empty(t, names)
+	assert.Contains(t, namesQuery, "WITH RECURSIVE lineage")
+	assert.Contains(t, namesQuery, "ORDER BY l.object_id, l.depth")
+}
+
+// TestRelationshipTypeRepository_ReverseType tests that an empty reverse_type_key clears the pairing
+func TestRelationshipTypeRepository_ReverseType(t *testing.T) {
+	var queries []string
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			queries = append(queries, query)
+			return errRow{}
+		},
+	}
+
+	repo := NewRelationshipTypeRepository(mockDB, DefaultRepositoryOptions())
+
+	unpaired := ""
+	_, err := repo.Update(context.Background(), 5, &models.UpdateRelationshipTypeRequest{ReverseTypeKey: &unpaired})
+	assert.NoError(t, err)
+	assert.Contains(t, queries[len(queries)-1], "reverse_type_key = NULLIF($1, '')")
+
+	_, err = repo.HasRelationships(context.Background(), 5)
+	assert.NoError(t, err)
+	assert.Contains(t, queries[len(queries)-1], "FROM objects_service.objects_relationships WHERE relationship_type_id = $1")
+}
+
+func TestOutboxRepository_Queries(t *testing.T) {
+	var queries []string
+	var lastArgs []any
+	mockDB := &MockDBPool{
+		QueryFunc: func(ctx context.Context, sql string, args ...any) (Rows, error) {
+			queries = append(queries, sql)
+			lastArgs = args
+			return &MockRows{NextFunc: func() bool { return false }}, nil
+		},
+		QueryRowFunc: func(ctx context.Context, sql string, args ...any) Row {
+			queries = append(queries, sql)
+			return errRow{}
+		},
+	}
+
+	repo := NewOutboxRepository(mockDB, DefaultRepositoryOptions())
+
+	events, err := repo.List(context.Background(), &models.OutboxEventFilter{After: 7, Limit: 10, EntityType: models.EventEntityObject})
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+	assert.Contains(t, queries[len(queries)-1], "id > $1 AND transaction_id < pg_snapshot_xmin(pg_current_snapshot()) AND entity_type = $2")
+	assert.Contains(t, queries[len(queries)-1], "LIMIT $3")
+	assert.Equal(t, []any{int64(7), models.EventEntityObject, 10}, lastArgs)
+
+	_, err = repo.Pending(context.Background(), 50)
+	assert.NoError(t, err)
+	assert.Contains(t, queries[len(queries)-1], "dispatched_at IS NULL AND transaction_id < pg_snapshot_xmin")
+
+	assert.NoError(t, repo.Append(context.Background(), &models.OutboxEvent{EventType: "object.created"}))
+	assert.Contains(t, queries[len(queries)-1], "INSERT INTO objects_service.outbox_events")
+
+	_, err = repo.TryLockDispatch(context.Background())
+	assert.NoError(t, err)
+	assert.Contains(t, queries[len(queries)-1], "pg_try_advisory_xact_lock")
+}
+
+func TestWebhookRepository_Queries(t *testing.T) {
+	var queries []string
+	var lastArgs []any
+	mockDB := &MockDBPool{
+		QueryFunc: func(ctx context.Context, sql string, args ...any) (Rows, error) {
+			queries = append(queries, sql)
+			lastArgs = args
+			return &MockRows{NextFunc: func() bool { return false }}, nil
+		},
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			queries = append(queries, query)
+			lastArgs = args
+			if strings.Contains(query, "COUNT(*)") {
+				return countRow{n: 3}
+			}
+			return errRow{err: sql.ErrNoRows}
+		},
+		ExecFunc: func(ctx context.Context, sql string, args ...any) (CommandTag, error) {
+			queries = append(queries, sql)
+			lastArgs = args
+			return nil, nil
+		},
+	}
+
+	repo := NewWebhookRepository(mockDB, DefaultRepositoryOptions())
+	ctx := context.Background()
+
+	_, err := repo.ClaimDueDeliveries(ctx, 20, 20*time.Second)
+	assert.NoError(t, err)
+	assert.Contains(t, queries[len(queries)-1], "status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP")
+	assert.Contains(t, queries[len(queries)-1], "FOR UPDATE SKIP LOCKED")
+	assert.Equal(t, []any{20, int64(20000)}, lastArgs)
+
+	deliveries, total, err := repo.ListDeliveries(ctx, 5, &models.WebhookDeliveryFilter{Status: models.WebhookDeliveryDead, Limit: 50, Offset: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, deliveries)
+	assert.Equal(t, int64(3), total)
+	assert.Contains(t, queries[len(queries)-1], "subscription_id = $1 AND status = $2")
+	assert.Contains(t, queries[len(queries)-1], "LIMIT $3 OFFSET $4")
+	assert.Equal(t, []any{int64(5), models.WebhookDeliveryDead, 50, 10}, lastArgs)
+
+	assert.NoError(t, repo.EnqueueDeliveries(ctx, &models.WebhookDelivery{SubscriptionID: 5, EventID: uuid.New()}))
+	assert.Contains(t, queries[len(queries)-1], "ON CONFLICT (subscription_id, event_id) DO NOTHING")
+
+	_, err = repo.GetDelivery(ctx, 5, uuid.New())
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = repo.ReplayDelivery(ctx, 9)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteSubscription(ctx, 5), ErrNotFound)
+}
+
+// jsonRow is a Row returning a single JSON document
+type jsonRow struct{ raw string }
+
+func (r jsonRow) Scan(dest ...any) error {
+	*dest[0].(*[]byte) = []byte(r.raw)
+	return nil
+}
+
+func TestCreateConcreteTableDDL(t *testing.T) {
+	statements := CreateConcreteTableDDL(&models.ConcreteTable{
+		ObjectTypeID: 7,
+		TableName:    "objects_book",
+		Attributes: []*models.ObjectTypeAttribute{
+			{Name: "isbn", DataType: "text", Nullable: false, Indexed: true},
+			{Name: "published_on", DataType: "date", Nullable: true},
+		},
+	})
+
+	assert.Len(t, statements, 3)
+	assert.Equal(t, `CREATE TABLE "objects_service"."objects_book" (`+"\n"+
+		"    object_id BIGINT PRIMARY KEY REFERENCES objects_service.objects(id) ON DELETE CASCADE,\n"+
+		`    "isbn" TEXT NOT NULL,`+"\n"+
+		`    "published_on" DATE`+"\n)", statements[0])
+	assert.Equal(t, `COMMENT ON TABLE "objects_service"."objects_book" IS 'Concrete table of object type 7'`, statements[1])
+	assert.Equal(t, `CREATE INDEX "idx_objects_book_isbn" ON "objects_service"."objects_book" ("isbn")`, statements[2])
+}
+
+// TestConcreteTableDDL_ReservedWords tests that attribute names which are reserved words are quoted
+func TestConcreteTableDDL_ReservedWords(t *testing.T) {
+	statements := CreateConcreteTableDDL(&models.ConcreteTable{
+		ObjectTypeID: 7,
+		TableName:    "objects_user",
+		Attributes: []*models.ObjectTypeAttribute{
+			{Name: "order", DataType: "integer", Nullable: true, Indexed: true},
+			{Name: "default", DataType: "text", Nullable: true},
+		},
+	})
+
+	assert.Contains(t, statements[0], `    "order" INTEGER,`)
+	assert.Contains(t, statements[0], `    "default" TEXT`)
+	assert.Equal(t, `CREATE INDEX "idx_objects_user_order" ON "objects_service"."objects_user" ("order")`, statements[2])
+}
+
+func TestAddConcreteColumnsDDL(t *testing.T) {
+	statements := AddConcreteColumnsDDL("objects_book", []*models.ObjectTypeAttribute{
+		{Name: "pages", DataType: "integer", Nullable: true, Indexed: true},
+	})
+
+	assert.Equal(t, []string{
+		`ALTER TABLE "objects_service"."objects_book" ADD COLUMN "pages" INTEGER`,
+		`CREATE INDEX "idx_objects_book_pages" ON "objects_service"."objects_book" ("pages")`,
+	}, statements)
+}
+
+func TestConcreteIndexName_Truncated(t *testing.T) {
+	name := concreteIndexName(strings.Repeat("t", 60), "column")
+
+	assert.Len(t, name, 63)
+	assert.Equal(t, name, concreteIndexName(strings.Repeat("t", 60), "column"))
+	assert.NotEqual(t, name, concreteIndexName(strings.Repeat("t", 60), "column2"))
+}
+
+func TestConcreteTableRepository_WriteAttributes(t *testing.T) {
+	tables := `[{"object_type_id": 7, "table_name": "objects_book", "attributes": [
+		{"name": "isbn", "data_type": "text", "nullable": false},
+		{"name": "pages", "data_type": "integer", "nullable": true}
+	]}]`
+
+	var queries []string
+	var inserted []byte
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			queries = append(queries, query)
+			if strings.Contains(query, "WITH RECURSIVE path") {
+				return jsonRow{raw: tables}
+			}
+			// No row to update yet
+			return errRow{err: sql.ErrNoRows}
+		},
+		ExecFunc: func(ctx context.Context, query string, args ...any) (CommandTag, error) {
+			queries = append(queries, query)
+			inserted = args[0].([]byte)
+			return nil, nil
+		},
+	}
+	repo := NewConcreteTableRepository(mockDB, DefaultRepositoryOptions())
+	ctx := context.Background()
+
+	err := repo.WriteAttributes(ctx, 42, 7, nil, map[string]interface{}{"isbn": "978-0", "pages": float64(320)})
+	assert.NoError(t, err)
+	assert.Contains(t, queries[1], `UPDATE "objects_service"."objects_book" t SET "isbn" = v."isbn", "pages" = v."pages"`)
+	assert.Contains(t, queries[2], `INSERT INTO "objects_service"."objects_book" SELECT * FROM jsonb_populate_record`)
+	assert.JSONEq(t, `{"object_id": 42, "isbn": "978-0", "pages": 320}`, string(inserted))
+
+	err = repo.WriteAttributes(ctx, 42, 7, nil, map[string]interface{}{"color": "red"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	err = repo.WriteAttributes(ctx, 42, 7, nil, map[string]interface{}{"pages": "many"})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	// A new object must be given every non-nullable attribute
+	err = repo.WriteAttributes(ctx, 42, 7, nil, map[string]interface{}{"pages": float64(10)})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+// TestObjectRepository_ExportObjects tests that the export reads through a cursor in batches
+// and leaves relationship rows out
+func TestObjectRepository_ExportObjects(t *testing.T) {
+	var statements []string
+	var declareArgs []any
+	fetches := 0
+	mockDB := &MockDBPool{
+		ExecFunc: func(ctx context.Context, query string, args ...any) (CommandTag, error) {
+			statements = append(statements, query)
+			if strings.HasPrefix(query, "DECLARE") {
+				declareArgs = args
+			}
+			return nil, nil
+		},
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			statements = append(statements, query)
+			fetches++
+			if fetches == 1 {
+				return &MockRows{NextFunc: rowCount(2)}, nil
+			}
+			return &MockRows{}, nil
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+
+	var batches []int
+	err := repo.ExportObjects(context.Background(), &models.ObjectFilter{Status: "active"}, 2, func(records []*models.ObjectRecord) error {
+		batches = append(batches, len(records))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, batches)
+	assert.Len(t, statements, 4)
+	assert.Contains(t, statements[0], "DECLARE objects_export NO SCROLL CURSOR FOR")
+	assert.Contains(t, statements[0], "status = $1 AND deleted_at IS NULL AND NOT EXISTS")
+	assert.Contains(t, statements[0], "ORDER BY o.id")
+	assert.Equal(t, []any{"active"}, declareArgs)
+	assert.Equal(t, "FETCH FORWARD 2 FROM objects_export", statements[1])
+	assert.Equal(t, "CLOSE objects_export", statements[3])
+}
+
+// TestObjectRepository_ExportObjects_StopsOnCallbackError tests that a failing consumer ends the export
+func TestObjectRepository_ExportObjects_StopsOnCallbackError(t *testing.T) {
+	mockDB := &MockDBPool{
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			return &MockRows{NextFunc: rowCount(1)}, nil
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+	errWrite := errors.New("client went away")
+
+	err := repo.ExportObjects(context.Background(), &models.ObjectFilter{}, 10, func(records []*models.ObjectRecord) error {
+		return errWrite
+	})
+	assert.ErrorIs(t, err, errWrite)
+}
+
+// TestObjectRepository_FindByKeys_Empty tests that no keys means no query
+func TestObjectRepository_FindByKeys_Empty(t *testing.T) {
+	mockDB := &MockDBPool{
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			t.Fatalf("unexpected query: %s", query)
+			return nil, nil
+		},
+	}
+
+	repo := NewObjectRepository(mockDB, DefaultRepositoryOptions())
+
+	byPublicID, byExternalKey, err := repo.FindByKeys(context.Background(), nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, byPublicID)
+	assert.Empty(t, byExternalKey)
+}
+
+// TestObjectRepository_CopyObjects_RequiresCopier tests that COPY is refused on a database without it
+func TestObjectRepository_CopyObjects_RequiresCopier(t *testing.T) {
+	repo := NewObjectRepository(&MockDBPool{}, DefaultRepositoryOptions())
+
+	_, err := repo.CopyObjects(context.Background(), []*models.ImportedObject{{PublicID: uuid.New(), Name: "a"}})
+	assert.Error(t, err)
+
+	objects, err := repo.CopyObjects(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, objects)
+}
+
+// TestRelationshipRepository_ExportRelationships tests the filters of the relationship export cursor
+func TestRelationshipRepository_ExportRelationships(t *testing.T) {
+	var declare string
+	var declareArgs []any
+	mockDB := &MockDBPool{
+		ExecFunc: func(ctx context.Context, query string, args ...any) (CommandTag, error) {
+			if strings.HasPrefix(query, "DECLARE") {
+				declare, declareArgs = query, args
+			}
+			return nil, nil
+		},
+		QueryFunc: func(ctx context.Context, query string, args ...any) (Rows, error) {
+			return &MockRows{}, nil
+		},
+	}
+
+	repo := NewRelationshipRepository(mockDB, DefaultRepositoryOptions(), nil)
+
+	err := repo.ExportRelationships(context.Background(), &models.RelationshipExportFilter{TypeKey: "contains", Status: "active"}, 100, func(records []*models.RelationshipRecord) error {
+		t.Fatal("no records expected")
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, declare, "DECLARE relationships_export NO SCROLL CURSOR FOR")
+	assert.Contains(t, declare, "WHERE o.deleted_at IS NULL AND rt.type_key = $1 AND r.status = $2")
+	assert.Equal(t, []any{"contains", "active"}, declareArgs)
+}
+
+// TestPrecondition_PinsOnlyItsRow tests that writes made with an If-Match precondition fail once
+// the row has changed, and that other rows written with the same context are left alone
+func TestPrecondition_PinsOnlyItsRow(t *testing.T) {
+	updatedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var pinned []string
+	var pinnedArgs []any
+	var execs []string
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, query string, args ...any) Row {
+			pinned, pinnedArgs = append(pinned, query), args
+			return errRow{err: sql.ErrNoRows}
+		},
+		ExecFunc: func(ctx context.Context, query string, args ...any) (CommandTag, error) {
+			execs = append(execs, query)
+			return nil, nil
+		},
+	}
+	relationships := NewRelationshipRepository(mockDB, DefaultRepositoryOptions(), nil)
+
+	ctx := WithPrecondition(context.Background(), RelationshipPrecondition(&models.Relationship{ObjectID: 9, UpdatedAt: updatedAt}))
+
+	err := relationships.Delete(ctx, 9)
+	assert.ErrorIs(t, err, ErrOptimisticLock)
+	assert.Equal(t, "DELETE FROM objects_service.objects_relationships WHERE object_id = $1 AND updated_at = $2 RETURNING 1", pinned[0])
+	assert.Equal(t, []any{int64(9), updatedAt}, pinnedArgs)
+
+	// An object type is not the relationship the precondition pins, even with the same ID
+	execs = nil
+	err = execPinned(ctx, mockDB, preconditionObjectType, 9, `DELETE FROM objects_service.object_types WHERE id = $1`, int64(9))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"DELETE FROM objects_service.object_types WHERE id = $1"}, execs)
+}
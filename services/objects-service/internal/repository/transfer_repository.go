@@ -0,0 +1,407 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+)
+
+// notRelationshipType leaves out the base objects of relationship types, which are part of the
+// taxonomy rather than of the data
+const notRelationshipType = `NOT EXISTS (
+	SELECT 1 FROM objects_service.objects_relationship_types rt WHERE rt.object_id = objects.id
+)`
+
+// importColumns are the object columns CopyObjects loads, in the order of its rows
+var importColumns = []string{
+	"public_id", "external_key", "object_type_id", "parent_object_id", "name", "description",
+	"metadata", "tags", "status", "version", "created_by", "updated_by",
+}
+
+// ExportObjects reads the live objects a filter selects in ID order, handing them to fn
+// batchSize at a time. The rows are read through a cursor, so the repository must be bound to
+// a transaction. Relationships and relationship types are not exported as objects.
+func (r *objectRepository) ExportObjects(ctx context.Context, filter *models.ObjectFilter, batchSize int, fn func([]*models.ObjectRecord) error) error {
+	r.metrics.QueryCount++
+
+	conditions, args, err := objectListConditions(filter)
+	if err != nil {
+		return err
+	}
+	conditions = append(conditions, notRelationship, notRelationshipType)
+
+	query := `DECLARE objects_export NO SCROLL CURSOR FOR
+		SELECT o.id, o.object_type_id, o.public_id::text, o.external_key, ot.name,
+		       p.public_id::text, p.external_key, o.name, o.description, o.status,
+		       o.tags, o.metadata, o.created_by, o.created_at, o.updated_at
+		FROM (
+			SELECT * FROM objects_service.objects WHERE ` + strings.Join(conditions, " AND ") + `
+		) o
+		JOIN objects_service.object_types ot ON ot.id = o.object_type_id
+		LEFT JOIN objects_service.objects p ON p.id = o.parent_object_id
+		ORDER BY o.id`
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		r.metrics.ErrorCount++
+		return fmt.Errorf("failed to open export cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM objects_export", batchSize)
+	for {
+		records, err := r.fetchObjectRecords(ctx, fetch)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			break
+		}
+		if err := fn(records); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.db.Exec(ctx, "CLOSE objects_export"); err != nil {
+		r.metrics.ErrorCount++
+		return fmt.Errorf("failed to close export cursor: %w", err)
+	}
+	return nil
+}
+
+func (r *objectRepository) fetchObjectRecords(ctx context.Context, fetch string) ([]*models.ObjectRecord, error) {
+	rows, err := r.db.Query(ctx, fetch)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to fetch exported objects: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*models.ObjectRecord
+	for rows.Next() {
+		var record models.ObjectRecord
+		var externalKey, parentPublicID, parentExternalKey, status *string
+		err := rows.Scan(
+			&record.ID, &record.ObjectTypeID, &record.PublicID, &externalKey, &record.ObjectType,
+			&parentPublicID, &parentExternalKey, &record.Name, &record.Description, &status,
+			&record.Tags, &record.Metadata, &record.CreatedBy, &record.CreatedAt, &record.UpdatedAt,
+		)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to scan exported object: %w", err)
+		}
+		record.ExternalKey = stringValue(externalKey)
+		record.ParentPublicID = stringValue(parentPublicID)
+		record.ParentExternalKey = stringValue(parentExternalKey)
+		record.Status = stringValue(status)
+		if string(record.Metadata) == "{}" || string(record.Metadata) == "null" {
+			record.Metadata = nil
+		}
+		records = append(records, &record)
+	}
+	if err := rows.Err(); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+	return records, nil
+}
+
+// FindByKeys returns the live objects with the given public IDs and external keys, by key.
+// Keys without an object are missing from the maps.
+func (r *objectRepository) FindByKeys(ctx context.Context, publicIDs []uuid.UUID, externalKeys []string) (map[uuid.UUID]*models.Object, map[string]*models.Object, error) {
+	r.metrics.QueryCount++
+
+	byPublicID := map[uuid.UUID]*models.Object{}
+	byExternalKey := map[string]*models.Object{}
+	if len(publicIDs) == 0 && len(externalKeys) == 0 {
+		return byPublicID, byExternalKey, nil
+	}
+
+	keyedIDs := map[int64]string{}
+	if len(externalKeys) > 0 {
+		rows, err := r.db.Query(ctx, `
+			SELECT id, external_key FROM objects_service.objects
+			WHERE external_key = ANY($1) AND deleted_at IS NULL AND `+notRelationship, externalKeys)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return nil, nil, fmt.Errorf("failed to find objects by external key: %w", err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id int64
+			var key string
+			if err := rows.Scan(&id, &key); err != nil {
+				r.metrics.ErrorCount++
+				return nil, nil, err
+			}
+			keyedIDs[id] = key
+		}
+		if err := rows.Err(); err != nil {
+			r.metrics.ErrorCount++
+			return nil, nil, err
+		}
+		rows.Close()
+	}
+
+	ids := make([]int64, 0, len(keyedIDs))
+	for id := range keyedIDs {
+		ids = append(ids, id)
+	}
+	if publicIDs == nil {
+		publicIDs = []uuid.UUID{}
+	}
+	query := `SELECT ` + trashColumns + ` FROM objects_service.objects
+		WHERE (public_id = ANY($1) OR id = ANY($2)) AND deleted_at IS NULL AND ` + notRelationship
+	objects, err := r.queryObjects(ctx, query, publicIDs, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find objects by key: %w", err)
+	}
+
+	for _, object := range objects {
+		byPublicID[object.PublicID] = object
+		if key, ok := keyedIDs[object.ID]; ok {
+			byExternalKey[key] = object
+		}
+	}
+	return byPublicID, byExternalKey, nil
+}
+
+// CopyObjects bulk-loads new objects with COPY and returns them in input order, with their
+// attributes written to the concrete tables of their types
+func (r *objectRepository) CopyObjects(ctx context.Context, inputs []*models.ImportedObject) ([]*models.Object, error) {
+	r.metrics.QueryCount++
+
+	if len(inputs) == 0 {
+		return []*models.Object{}, nil
+	}
+	copier, ok := r.db.(Copier)
+	if !ok {
+		return nil, fmt.Errorf("database does not support COPY")
+	}
+
+	rows := make([][]any, len(inputs))
+	publicIDs := make([]uuid.UUID, len(inputs))
+	for i, input := range inputs {
+		publicIDs[i] = input.PublicID
+		rows[i] = []any{
+			input.PublicID, input.ExternalKey, input.ObjectTypeID, input.ParentObjectID,
+			input.Name, input.Description, importedMetadata(input.Metadata), importedTags(input.Tags),
+			importedStatus(input.Status), int64(1), input.CreatedBy, input.CreatedBy,
+		}
+	}
+
+	if _, err := copier.CopyFrom(ctx, pgx.Identifier{"objects_service", "objects"}, importColumns, pgx.CopyFromRows(rows)); err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to copy objects: %w", err)
+	}
+
+	query := `SELECT ` + trashColumns + ` FROM objects_service.objects WHERE public_id = ANY($1)`
+	loaded, err := r.queryObjects(ctx, query, publicIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read copied objects: %w", err)
+	}
+	byPublicID := make(map[uuid.UUID]*models.Object, len(loaded))
+	for _, object := range loaded {
+		byPublicID[object.PublicID] = object
+	}
+
+	objects := make([]*models.Object, len(inputs))
+	for i, input := range inputs {
+		object := byPublicID[input.PublicID]
+		if object == nil {
+			return nil, fmt.Errorf("copied object %s not found", input.PublicID)
+		}
+		if err := r.concrete.WriteAttributes(ctx, object.ID, object.ObjectTypeID, nil, input.Attributes); err != nil {
+			r.metrics.ErrorCount++
+			return nil, err
+		}
+		objects[i] = object
+	}
+
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionCreate, objectIDs(objects)); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+	if err := r.concrete.LoadAttributes(ctx, objects); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+	return objects, nil
+}
+
+// UpdateImportedObjects replaces the fields of existing objects with imported ones, keeping
+// their type, and returns them in input order
+func (r *objectRepository) UpdateImportedObjects(ctx context.Context, inputs []*models.ImportedObject) ([]*models.Object, error) {
+	r.metrics.QueryCount++
+
+	if len(inputs) == 0 {
+		return []*models.Object{}, nil
+	}
+
+	ids := make([]int64, len(inputs))
+	for i, input := range inputs {
+		ids[i] = input.ID
+		_, err := r.db.Exec(ctx, `
+			UPDATE objects_service.objects
+			SET external_key = $2, parent_object_id = $3, name = $4, description = $5,
+			    metadata = $6, tags = $7, status = $8, updated_by = $9,
+			    version = version + 1, updated_at = NOW()
+			WHERE id = $1 AND deleted_at IS NULL`,
+			input.ID, input.ExternalKey, input.ParentObjectID, input.Name, input.Description,
+			importedMetadata(input.Metadata), importedTags(input.Tags), importedStatus(input.Status),
+			input.UpdatedBy)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to update imported object %d: %w", input.ID, err)
+		}
+		if err := r.concrete.WriteAttributes(ctx, input.ID, input.ObjectTypeID, &input.ObjectTypeID, input.Attributes); err != nil {
+			r.metrics.ErrorCount++
+			return nil, err
+		}
+	}
+
+	query := `SELECT ` + trashColumns + ` FROM objects_service.objects WHERE id = ANY($1) AND deleted_at IS NULL`
+	loaded, err := r.queryObjects(ctx, query, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read updated objects: %w", err)
+	}
+	byID := make(map[int64]*models.Object, len(loaded))
+	for _, object := range loaded {
+		byID[object.ID] = object
+	}
+	objects := make([]*models.Object, len(inputs))
+	for i, input := range inputs {
+		if objects[i] = byID[input.ID]; objects[i] == nil {
+			return nil, fmt.Errorf("imported object %d: %w", input.ID, ErrNotFound)
+		}
+	}
+
+	if err := recordHistory(ctx, r.db, models.HistoryEntityObject, models.HistoryActionUpdate, ids); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+	if err := r.concrete.LoadAttributes(ctx, objects); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+	return objects, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func importedMetadata(metadata []byte) []byte {
+	if len(metadata) == 0 || string(metadata) == "null" {
+		return []byte("{}")
+	}
+	return metadata
+}
+
+func importedTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func importedStatus(status string) string {
+	if status == "" {
+		return models.StatusActive
+	}
+	return status
+}
+
+// ExportRelationships reads the live relationships a filter selects in ID order, handing them to
+// fn batchSize at a time, through a cursor like ExportObjects
+func (r *relationshipRepository) ExportRelationships(ctx context.Context, filter *models.RelationshipExportFilter, batchSize int, fn func([]*models.RelationshipRecord) error) error {
+	r.metrics.QueryCount++
+
+	conditions := []string{"o.deleted_at IS NULL"}
+	var args []interface{}
+	if filter.TypeKey != "" {
+		args = append(args, filter.TypeKey)
+		conditions = append(conditions, fmt.Sprintf("rt.type_key = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("r.status = $%d", len(args)))
+	}
+
+	query := `DECLARE relationships_export NO SCROLL CURSOR FOR
+		SELECT o.public_id::text, rt.type_key, s.public_id::text, s.external_key,
+		       t.public_id::text, t.external_key, r.status, r.relationship_metadata,
+		       r.created_by, r.created_at, r.updated_at
+		FROM objects_service.objects_relationships r
+		JOIN objects_service.objects o ON r.object_id = o.id
+		JOIN objects_service.objects s ON r.source_object_id = s.id
+		JOIN objects_service.objects t ON r.target_object_id = t.id
+		JOIN objects_service.objects_relationship_types rt ON r.relationship_type_id = rt.object_id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY r.object_id`
+	if _, err := r.db.Exec(ctx, query, args...); err != nil {
+		r.metrics.ErrorCount++
+		return fmt.Errorf("failed to open export cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM relationships_export", batchSize)
+	for {
+		records, err := r.fetchRelationshipRecords(ctx, fetch)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			break
+		}
+		if err := fn(records); err != nil {
+			return err
+		}
+	}
+
+	if _, err := r.db.Exec(ctx, "CLOSE relationships_export"); err != nil {
+		r.metrics.ErrorCount++
+		return fmt.Errorf("failed to close export cursor: %w", err)
+	}
+	return nil
+}
+
+func (r *relationshipRepository) fetchRelationshipRecords(ctx context.Context, fetch string) ([]*models.RelationshipRecord, error) {
+	rows, err := r.db.Query(ctx, fetch)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to fetch exported relationships: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*models.RelationshipRecord
+	for rows.Next() {
+		var record models.RelationshipRecord
+		var sourceExternalKey, targetExternalKey, status *string
+		var metadata []byte
+		err := rows.Scan(
+			&record.PublicID, &record.RelationshipType, &record.SourcePublicID, &sourceExternalKey,
+			&record.TargetPublicID, &targetExternalKey, &status, &metadata,
+			&record.CreatedBy, &record.CreatedAt, &record.UpdatedAt,
+		)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to scan exported relationship: %w", err)
+		}
+		record.SourceExternalKey = stringValue(sourceExternalKey)
+		record.TargetExternalKey = stringValue(targetExternalKey)
+		record.Status = stringValue(status)
+		if len(metadata) > 0 && string(metadata) != "{}" && string(metadata) != "null" {
+			record.Metadata = metadata
+		}
+		records = append(records, &record)
+	}
+	if err := rows.Err(); err != nil {
+		r.metrics.ErrorCount++
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,452 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+)
+
+// WebhookRepository defines operations on webhook subscriptions and their deliveries
+type WebhookRepository interface {
+	Repository
+
+	CreateSubscription(ctx context.Context, input *models.CreateWebhookSubscriptionRequest) (*models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, publicID uuid.UUID) (*models.WebhookSubscription, error)
+	GetSubscriptionByID(ctx context.Context, id int64) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error)
+	ActiveSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, id int64, input *models.UpdateWebhookSubscriptionRequest) (*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+
+	EnqueueDeliveries(ctx context.Context, deliveries ...*models.WebhookDelivery) error
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, subscriptionID int64, filter *models.WebhookDeliveryFilter) ([]*models.WebhookDelivery, int64, error)
+	GetDelivery(ctx context.Context, subscriptionID int64, publicID uuid.UUID) (*models.WebhookDelivery, error)
+	ReplayDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error)
+}
+
+// webhookRepository implements WebhookRepository
+type webhookRepository struct {
+	db      DBInterface
+	options *RepositoryOptions
+	metrics *RepositoryMetrics
+}
+
+// NewWebhookRepository creates a new WebhookRepository instance
+func NewWebhookRepository(db DBInterface, options *RepositoryOptions) WebhookRepository {
+	if options == nil {
+		options = DefaultRepositoryOptions()
+	}
+
+	return &webhookRepository{
+		db:      db,
+		options: options,
+		metrics: &RepositoryMetrics{LastResetAt: time.Now()},
+	}
+}
+
+// DB implements Repository interface
+func (r *webhookRepository) DB() DBInterface {
+	return r.db
+}
+
+// Options implements Repository interface
+func (r *webhookRepository) Options() *RepositoryOptions {
+	return r.options
+}
+
+// Metrics implements Repository interface
+func (r *webhookRepository) Metrics() *RepositoryMetrics {
+	return r.metrics
+}
+
+// ResetMetrics implements Repository interface
+func (r *webhookRepository) ResetMetrics() {
+	r.metrics.Reset()
+}
+
+// Healthy implements Repository interface
+func (r *webhookRepository) Healthy(ctx context.Context) error {
+	var result int
+	return r.db.QueryRow(ctx, "SELECT 1").Scan(&result)
+}
+
+const webhookSubscriptionColumns = `id, public_id, name, target_url, secret, event_types, object_type_ids,
+			relationship_type_keys, is_active, created_by, updated_by, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, public_id, subscription_id, event_id, event_type, payload, status, attempts,
+			next_attempt_at, last_attempt_at, last_status_code, last_error, delivered_at, created_at`
+
+func scanWebhookSubscription(row Row) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	err := row.Scan(
+		&sub.ID, &sub.PublicID, &sub.Name, &sub.TargetURL, &sub.Secret, &sub.EventTypes, &sub.ObjectTypeIDs,
+		&sub.RelationshipTypeKeys, &sub.IsActive, &sub.CreatedBy, &sub.UpdatedBy, &sub.CreatedAt, &sub.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func scanWebhookDelivery(row Row) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var payload []byte
+	err := row.Scan(
+		&delivery.ID, &delivery.PublicID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &payload,
+		&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastAttemptAt, &delivery.LastStatusCode,
+		&delivery.LastError, &delivery.DeliveredAt, &delivery.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	return &delivery, nil
+}
+
+// CreateSubscription creates a webhook subscription
+func (r *webhookRepository) CreateSubscription(ctx context.Context, input *models.CreateWebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	r.metrics.QueryCount++
+
+	isActive := true
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+	createdBy := input.CreatedBy
+	if createdBy == "" {
+		createdBy = "system"
+	}
+
+	sub, err := scanWebhookSubscription(r.db.QueryRow(ctx, `
+		INSERT INTO objects_service.webhook_subscriptions (
+			name, target_url, secret, event_types, object_type_ids, relationship_type_keys,
+			is_active, created_by, updated_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		RETURNING `+webhookSubscriptionColumns,
+		input.Name, input.TargetURL, input.Secret, nonNilStrings(input.EventTypes), nonNilInt64s(input.ObjectTypeIDs),
+		nonNilStrings(input.RelationshipTypeKeys), isActive, createdBy,
+	))
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// GetSubscription returns a subscription by its public ID
+func (r *webhookRepository) GetSubscription(ctx context.Context, publicID uuid.UUID) (*models.WebhookSubscription, error) {
+	return r.getSubscription(ctx, "public_id = $1", publicID)
+}
+
+// GetSubscriptionByID returns a subscription by its internal ID
+func (r *webhookRepository) GetSubscriptionByID(ctx context.Context, id int64) (*models.WebhookSubscription, error) {
+	return r.getSubscription(ctx, "id = $1", id)
+}
+
+func (r *webhookRepository) getSubscription(ctx context.Context, where string, arg interface{}) (*models.WebhookSubscription, error) {
+	r.metrics.QueryCount++
+
+	sub, err := scanWebhookSubscription(r.db.QueryRow(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM objects_service.webhook_subscriptions
+		WHERE `+where, arg))
+	if err != nil {
+		r.metrics.ErrorCount++
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// ListSubscriptions returns every subscription, oldest first
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	return r.listSubscriptions(ctx, "")
+}
+
+// ActiveSubscriptions returns the subscriptions events are delivered to
+func (r *webhookRepository) ActiveSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	return r.listSubscriptions(ctx, "WHERE is_active")
+}
+
+func (r *webhookRepository) listSubscriptions(ctx context.Context, where string) ([]*models.WebhookSubscription, error) {
+	r.metrics.QueryCount++
+
+	rows, err := r.db.Query(ctx, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM objects_service.webhook_subscriptions
+		`+where+`
+		ORDER BY id`)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []*models.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			r.metrics.ErrorCount++
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// UpdateSubscription changes the given fields of a subscription
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, id int64, input *models.UpdateWebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	r.metrics.QueryCount++
+
+	sets := []string{}
+	args := []interface{}{}
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if input.Name != nil {
+		set("name", *input.Name)
+	}
+	if input.TargetURL != nil {
+		set("target_url", *input.TargetURL)
+	}
+	if input.Secret != nil {
+		set("secret", *input.Secret)
+	}
+	if input.EventTypes != nil {
+		set("event_types", nonNilStrings(*input.EventTypes))
+	}
+	if input.ObjectTypeIDs != nil {
+		set("object_type_ids", nonNilInt64s(*input.ObjectTypeIDs))
+	}
+	if input.RelationshipTypeKeys != nil {
+		set("relationship_type_keys", nonNilStrings(*input.RelationshipTypeKeys))
+	}
+	if input.IsActive != nil {
+		set("is_active", *input.IsActive)
+	}
+	if input.UpdatedBy != "" {
+		set("updated_by", input.UpdatedBy)
+	}
+	sets = append(sets, "updated_at = CURRENT_TIMESTAMP")
+	args = append(args, id)
+
+	sub, err := scanWebhookSubscription(r.db.QueryRow(ctx, fmt.Sprintf(`
+		UPDATE objects_service.webhook_subscriptions
+		SET %s
+		WHERE id = $%d
+		RETURNING %s`, strings.Join(sets, ", "), len(args), webhookSubscriptionColumns), args...))
+	if err != nil {
+		r.metrics.ErrorCount++
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+	return sub, nil
+}
+
+// DeleteSubscription deletes a subscription together with its deliveries
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	r.metrics.QueryCount++
+
+	var deleted int64
+	err := r.db.QueryRow(ctx, `
+		DELETE FROM objects_service.webhook_subscriptions WHERE id = $1 RETURNING id`, id).Scan(&deleted)
+	if err != nil {
+		r.metrics.ErrorCount++
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	return nil
+}
+
+// EnqueueDeliveries schedules deliveries for their first attempt. An event is delivered to a
+// subscription once: deliveries of events already enqueued for it are skipped.
+func (r *webhookRepository) EnqueueDeliveries(ctx context.Context, deliveries ...*models.WebhookDelivery) error {
+	for _, delivery := range deliveries {
+		r.metrics.QueryCount++
+
+		_, err := r.db.Exec(ctx, `
+			INSERT INTO objects_service.webhook_deliveries (subscription_id, event_id, event_type, payload)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (subscription_id, event_id) DO NOTHING`,
+			delivery.SubscriptionID, delivery.EventID, delivery.EventType, []byte(delivery.Payload))
+		if err != nil {
+			r.metrics.ErrorCount++
+			return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClaimDueDeliveries returns pending deliveries whose next attempt is due, oldest first, and
+// pushes their next attempt back by lease, so no other worker picks them up meanwhile
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	r.metrics.QueryCount++
+
+	rows, err := r.db.Query(ctx, `
+		UPDATE objects_service.webhook_deliveries
+		SET next_attempt_at = CURRENT_TIMESTAMP + $2 * INTERVAL '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM objects_service.webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+webhookDeliveryColumns, limit, lease.Milliseconds())
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries, err := scanWebhookDeliveries(rows)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RecordAttempt stores the outcome of a delivery attempt
+func (r *webhookRepository) RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	r.metrics.QueryCount++
+
+	_, err := r.db.Exec(ctx, `
+		UPDATE objects_service.webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_attempt_at = $5,
+			last_status_code = $6, last_error = $7, delivered_at = $8
+		WHERE id = $1`,
+		delivery.ID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastAttemptAt,
+		delivery.LastStatusCode, delivery.LastError, delivery.DeliveredAt)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return fmt.Errorf("failed to record webhook delivery attempt: %w", err)
+	}
+	return nil
+}
+
+// ListDeliveries returns the deliveries of a subscription, newest first
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, filter *models.WebhookDeliveryFilter) ([]*models.WebhookDelivery, int64, error) {
+	r.metrics.QueryCount++
+
+	where := "subscription_id = $1"
+	args := []interface{}{subscriptionID}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		where += " AND status = $2"
+	}
+
+	var total int64
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM objects_service.webhook_deliveries WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	rows, err := r.db.Query(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM objects_service.webhook_deliveries
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT $%d OFFSET $%d`, webhookDeliveryColumns, where, len(args)-1, len(args)), args...)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries, err := scanWebhookDeliveries(rows)
+	if err != nil {
+		r.metrics.ErrorCount++
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, total, nil
+}
+
+// GetDelivery returns a delivery of a subscription by its public ID
+func (r *webhookRepository) GetDelivery(ctx context.Context, subscriptionID int64, publicID uuid.UUID) (*models.WebhookDelivery, error) {
+	r.metrics.QueryCount++
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRow(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM objects_service.webhook_deliveries
+		WHERE subscription_id = $1 AND public_id = $2`, subscriptionID, publicID))
+	if err != nil {
+		r.metrics.ErrorCount++
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// ReplayDelivery schedules a delivery for immediate redelivery with a fresh set of attempts
+func (r *webhookRepository) ReplayDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	r.metrics.QueryCount++
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRow(ctx, `
+		UPDATE objects_service.webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, delivered_at = NULL
+		WHERE id = $1
+		RETURNING `+webhookDeliveryColumns, id))
+	if err != nil {
+		r.metrics.ErrorCount++
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+func scanWebhookDeliveries(rows Rows) ([]*models.WebhookDelivery, error) {
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// nonNilStrings stores an absent list as an empty array, as the columns are NOT NULL
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// nonNilInt64s stores an absent list as an empty array, as the columns are NOT NULL
+func nonNilInt64s(values []int64) []int64 {
+	if values == nil {
+		return []int64{}
+	}
+	return values
+}
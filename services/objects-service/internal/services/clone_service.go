@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// CloneService copies objects, or whole subtrees of them, together with their relationships
+type CloneService interface {
+	Clone(ctx context.Context, id int64, req *models.CloneObjectRequest) (*models.CloneObjectResult, error)
+}
+
+type cloneService struct {
+	objectRepo     repository.ObjectRepository
+	objectTypeRepo repository.ObjectTypeRepository
+	relRepo        repository.RelationshipRepository
+	relTypeRepo    repository.RelationshipTypeRepository
+	txDB           TxBeginner
+}
+
+// NewCloneService creates a clone service that makes each copy inside a single transaction
+// started by txDB. Without txDB the copies are made directly against the repositories.
+func NewCloneService(objectRepo repository.ObjectRepository, objectTypeRepo repository.ObjectTypeRepository, relRepo repository.RelationshipRepository, relTypeRepo repository.RelationshipTypeRepository, txDB TxBeginner) CloneService {
+	return &cloneService{
+		objectRepo:     objectRepo,
+		objectTypeRepo: objectTypeRepo,
+		relRepo:        relRepo,
+		relTypeRepo:    relTypeRepo,
+		txDB:           txDB,
+	}
+}
+
+// withinTx runs fn with an object service and a relationship service bound to a single
+// transaction, so copies are checked and recorded exactly as objects and relationships created
+// one by one
+func (s *cloneService) withinTx(ctx context.Context, fn func(objects *objectService, relationships *relationshipService) error) error {
+	if s.txDB == nil {
+		return fn(
+			&objectService{repo: s.objectRepo, objectTypeRepo: s.objectTypeRepo},
+			&relationshipService{repo: s.relRepo, relationshipTypeRepo: s.relTypeRepo, objectRepo: s.objectRepo},
+		)
+	}
+	return WithinTxOptions(ctx, s.txDB, relationshipTxOptions, func(tx Transaction) error {
+		return fn(
+			&objectService{repo: tx.ObjectRepository(), objectTypeRepo: tx.ObjectTypeRepository(), outbox: tx.OutboxRepository()},
+			&relationshipService{
+				repo:                 tx.RelationshipRepository(),
+				relationshipTypeRepo: tx.RelationshipTypeRepository(),
+				objectRepo:           tx.ObjectRepository(),
+				outbox:               tx.OutboxRepository(),
+			},
+		)
+	})
+}
+
+// Clone copies an object, and its descendants when req.IncludeDescendants is set, as new objects
+// with new public IDs, the same metadata, tags and attributes, and the same tree shape.
+// Relationships between copied objects are copied between the copies; relationships to objects
+// outside the clone are copied only when req.KeepExternalRelationships is set. Either everything
+// is copied or nothing is.
+func (s *cloneService) Clone(ctx context.Context, id int64, req *models.CloneObjectRequest) (*models.CloneObjectResult, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid id: %w", repository.ErrInvalidInput)
+	}
+	if req.ParentObjectID != nil && *req.ParentObjectID <= 0 {
+		return nil, fmt.Errorf("invalid parent object id: %w", repository.ErrInvalidInput)
+	}
+	if req.Name != nil && *req.Name == "" {
+		return nil, fmt.Errorf("name must not be empty: %w", repository.ErrInvalidInput)
+	}
+
+	var result *models.CloneObjectResult
+	err := s.withinTx(ctx, func(objects *objectService, relationships *relationshipService) error {
+		var err error
+		result, err = s.clone(ctx, objects, relationships, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *cloneService) clone(ctx context.Context, objects *objectService, relationships *relationshipService, id int64, req *models.CloneObjectRequest) (*models.CloneObjectResult, error) {
+	source, err := objects.repo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("object not found: %w", err)
+	}
+	if source.DeletedAt != nil {
+		return nil, fmt.Errorf("object %d: %w", id, ErrObjectDeleted)
+	}
+
+	// GetDescendants lists parents before their children, starting with the object itself
+	originals := []*models.Object{source}
+	if req.IncludeDescendants {
+		subtree, err := objects.repo.GetDescendants(ctx, id, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get descendants: %w", err)
+		}
+		for _, descendant := range subtree {
+			if descendant.ID == id {
+				continue
+			}
+			// Descendants are listed without their attributes
+			original, err := objects.repo.GetByID(ctx, descendant.ID)
+			if err != nil {
+				return nil, err
+			}
+			originals = append(originals, original)
+		}
+	}
+
+	result := &models.CloneObjectResult{Objects: []models.CloneMapping{}, Relationships: []models.CloneMapping{}}
+	copies := make(map[int64]*models.Object, len(originals))
+	for _, original := range originals {
+		create := &models.CreateObjectRequest{
+			ObjectTypeID: original.ObjectTypeID,
+			Name:         original.Name,
+			Description:  original.Description,
+			Metadata:     original.GetMetadataMap(),
+			Tags:         original.Tags,
+			Attributes:   original.Attributes,
+			CreatedBy:    req.CreatedBy,
+		}
+		if original.ID == id {
+			create.ParentObjectID = source.ParentObjectID
+			if req.ParentObjectID != nil {
+				create.ParentObjectID = req.ParentObjectID
+			}
+			if req.Name != nil {
+				create.Name = *req.Name
+			}
+		} else {
+			create.ParentObjectID = &copies[*original.ParentObjectID].ID
+		}
+
+		copied, err := objects.create(ctx, create)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy object %d: %w", original.ID, err)
+		}
+		copies[original.ID] = copied
+		result.Objects = append(result.Objects, models.CloneMapping{
+			OldID: original.ID, OldPublicID: original.PublicID, NewID: copied.ID, NewPublicID: copied.PublicID,
+		})
+	}
+	result.Object = copies[id]
+
+	originalIDs := make([]int64, len(originals))
+	for i, original := range originals {
+		originalIDs[i] = original.ID
+	}
+	rels, err := relationships.repo.ListForObjects(ctx, originalIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range rels {
+		source, sourceCopied := copies[rel.SourceObjectID]
+		target, targetCopied := copies[rel.TargetObjectID]
+		if (!sourceCopied || !targetCopied) && !req.KeepExternalRelationships {
+			continue
+		}
+
+		sourcePublicID, targetPublicID := rel.SourceObjectPublicID, rel.TargetObjectPublicID
+		if sourceCopied {
+			sourcePublicID = source.PublicID
+		}
+		if targetCopied {
+			targetPublicID = target.PublicID
+		}
+
+		create := &models.CreateRelationshipRequest{
+			SourceObjectPublicID: sourcePublicID.String(),
+			TargetObjectPublicID: targetPublicID.String(),
+			RelationshipTypeKey:  rel.RelationshipTypeKey,
+			Status:               rel.Status,
+			RelationshipMetadata: rel.RelationshipMetadata,
+			CreatedBy:            req.CreatedBy,
+		}
+		create.SetDefaults()
+
+		copied, err := relationships.create(ctx, create, sourcePublicID, targetPublicID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy relationship %s: %w", rel.PublicID, err)
+		}
+		result.Relationships = append(result.Relationships, models.CloneMapping{
+			OldID: rel.ObjectID, OldPublicID: rel.PublicID, NewID: copied.ObjectID, NewPublicID: copied.PublicID,
+		})
+	}
+
+	return result, nil
+}
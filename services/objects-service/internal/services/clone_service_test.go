@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+func (r *memoryObjects) Create(ctx context.Context, input *models.CreateObjectRequest) (*models.Object, error) {
+	r.nextID++
+	metadata, _ := json.Marshal(input.Metadata)
+	object := &models.Object{
+		ID:             r.nextID,
+		PublicID:       uuid.New(),
+		ObjectTypeID:   input.ObjectTypeID,
+		ParentObjectID: input.ParentObjectID,
+		Name:           input.Name,
+		Description:    input.Description,
+		Metadata:       metadata,
+		Tags:           input.Tags,
+		Attributes:     input.Attributes,
+		Status:         models.StatusActive,
+		Version:        1,
+		CreatedBy:      input.CreatedBy,
+	}
+	r.objects[object.ID] = object
+	return r.GetByID(ctx, object.ID)
+}
+
+func (r *memoryObjects) GetByPublicID(ctx context.Context, publicID uuid.UUID) (*models.Object, error) {
+	for _, object := range r.objects {
+		if object.PublicID == publicID {
+			return r.GetByID(ctx, object.ID)
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryObjects) ValidateParentChild(ctx context.Context, parentID, childID int64) error {
+	return nil
+}
+
+// memoryRelationships is an in-memory RelationshipRepository over the objects of memoryObjects
+type memoryRelationships struct {
+	repository.RelationshipRepository
+	objects *memoryObjects
+	types   *memoryRelationshipTypes
+	rels    []*models.Relationship
+	trashed map[int64]bool
+	nextID  int64
+}
+
+func newMemoryRelationships(objects *memoryObjects, types *memoryRelationshipTypes) *memoryRelationships {
+	return &memoryRelationships{objects: objects, types: types, trashed: map[int64]bool{}, nextID: 5000}
+}
+
+// add stores a relationship of a type from source to target
+func (r *memoryRelationships) add(typeKey string, source, target *models.Object) *models.Relationship {
+	r.nextID++
+	rel := &models.Relationship{
+		ObjectID:             r.nextID,
+		PublicID:             uuid.New(),
+		SourceObjectID:       source.ID,
+		SourceObjectPublicID: source.PublicID,
+		TargetObjectID:       target.ID,
+		TargetObjectPublicID: target.PublicID,
+		RelationshipTypeID:   r.types.byKey(typeKey).ObjectID,
+		RelationshipTypeKey:  typeKey,
+		Status:               models.StatusActive,
+		RelationshipMetadata: json.RawMessage(`{}`),
+	}
+	r.rels = append(r.rels, rel)
+	return rel
+}
+
+func (r *memoryRelationships) Create(ctx context.Context, input *models.CreateRelationshipRequest) (*models.Relationship, error) {
+	source, err := r.objects.GetByPublicID(ctx, uuid.MustParse(input.SourceObjectPublicID))
+	if err != nil {
+		return nil, err
+	}
+	target, err := r.objects.GetByPublicID(ctx, uuid.MustParse(input.TargetObjectPublicID))
+	if err != nil {
+		return nil, err
+	}
+	rel := r.add(input.RelationshipTypeKey, source, target)
+	rel.Status = input.Status
+	rel.RelationshipMetadata = input.RelationshipMetadata
+	copied := *rel
+	return &copied, nil
+}
+
+func (r *memoryRelationships) ListForObjects(ctx context.Context, objectIDs []int64) ([]*models.Relationship, error) {
+	listed := map[int64]bool{}
+	for _, id := range objectIDs {
+		listed[id] = true
+	}
+	rels := []*models.Relationship{}
+	for _, rel := range r.rels {
+		if !r.trashed[rel.ObjectID] && (listed[rel.SourceObjectID] || listed[rel.TargetObjectID]) {
+			copied := *rel
+			rels = append(rels, &copied)
+		}
+	}
+	return rels, nil
+}
+
+func (r *memoryRelationships) Exists(ctx context.Context, sourceObjectID, targetObjectID int64, typeObjectID int64) (bool, error) {
+	for _, rel := range r.rels {
+		if rel.SourceObjectID == sourceObjectID && rel.TargetObjectID == targetObjectID && rel.RelationshipTypeID == typeObjectID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryRelationships) CountInDirection(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
+	count := 0
+	for _, rel := range r.rels {
+		end := rel.SourceObjectID
+		if direction == models.DirectionIncoming {
+			end = rel.TargetObjectID
+		}
+		if end == objectID && rel.RelationshipTypeID == typeObjectID && !r.trashed[rel.ObjectID] {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryRelationships) CheckCircular(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error) {
+	return false, nil
+}
+
+func (r *memoryRelationships) LockType(ctx context.Context, typeObjectID int64) error { return nil }
+
+func (r *memoryRelationships) LockObjects(ctx context.Context, typeObjectID int64, objectIDs ...int64) error {
+	return nil
+}
+
+// cloneFixture is a template tree project > {phase > task} with a task linked to an outside
+// object, and the outside object owning the phase
+type cloneFixture struct {
+	objects *memoryObjects
+	rels    *memoryRelationships
+	db      *memoryTxDB
+	service CloneService
+	project *models.Object
+	phase   *models.Object
+	task    *models.Object
+	outside *models.Object
+}
+
+func newCloneFixture() *cloneFixture {
+	types := newMemoryObjectTypes()
+	template := types.add("Template", "", false)
+	relTypes := newMemoryRelationshipTypes()
+	relTypes.add("links_to", "", models.CardinalityManyToMany)
+	relTypes.add("owns", "", models.CardinalityOneToMany)
+
+	f := &cloneFixture{objects: newMemoryObjects()}
+	f.rels = newMemoryRelationships(f.objects, relTypes)
+	f.project = f.objects.add("project", template.ID, nil)
+	f.phase = f.objects.add("phase", template.ID, f.project)
+	f.task = f.objects.add("task", template.ID, f.phase)
+	f.outside = f.objects.add("outside", template.ID, nil)
+	f.objects.objects[f.phase.ID].Metadata = json.RawMessage(`{"duration":5}`)
+	f.objects.objects[f.phase.ID].Tags = []string{"template"}
+	f.rels.add("links_to", f.phase, f.task)
+	f.rels.add("links_to", f.task, f.outside)
+	f.rels.add("owns", f.outside, f.phase)
+
+	f.db = newMemoryTxDB(f.objects, types)
+	f.db.relRepo = f.rels
+	f.db.relTypeRepo = relTypes
+	f.service = NewCloneService(f.objects, types, f.rels, relTypes, f.db)
+	return f
+}
+
+func (f *cloneFixture) copyOf(result *models.CloneObjectResult, original *models.Object) *models.Object {
+	for _, mapping := range result.Objects {
+		if mapping.OldID == original.ID {
+			return f.objects.objects[mapping.NewID]
+		}
+	}
+	return nil
+}
+
+func TestCloneService_CloneSubtree(t *testing.T) {
+	f := newCloneFixture()
+
+	result, err := f.service.Clone(context.Background(), f.phase.ID, &models.CloneObjectRequest{IncludeDescendants: true, CreatedBy: "alice"})
+
+	require.NoError(t, err)
+	require.Len(t, result.Objects, 2)
+	phase, task := f.copyOf(result, f.phase), f.copyOf(result, f.task)
+	assert.Equal(t, phase.ID, result.Object.ID)
+	assert.NotEqual(t, f.phase.PublicID, phase.PublicID)
+	assert.Equal(t, f.project.ID, *phase.ParentObjectID)
+	assert.Equal(t, phase.ID, *task.ParentObjectID)
+	assert.JSONEq(t, `{"duration":5}`, string(phase.Metadata))
+	assert.Equal(t, []string{"template"}, phase.Tags)
+	assert.Equal(t, "alice", task.CreatedBy)
+
+	require.Len(t, result.Relationships, 1)
+	copied := f.rels.rels[len(f.rels.rels)-1]
+	assert.Equal(t, copied.ObjectID, result.Relationships[0].NewID)
+	assert.Equal(t, phase.ID, copied.SourceObjectID)
+	assert.Equal(t, task.ID, copied.TargetObjectID)
+
+	assert.Equal(t, []string{"object.created", "object.created", "relationship.created"}, f.db.outbox.eventTypes())
+	assert.Equal(t, 1, f.db.commits)
+}
+
+func TestCloneService_KeepExternalRelationships(t *testing.T) {
+	f := newCloneFixture()
+
+	result, err := f.service.Clone(context.Background(), f.task.ID, &models.CloneObjectRequest{KeepExternalRelationships: true})
+
+	require.NoError(t, err)
+	require.Len(t, result.Relationships, 2)
+	task := f.copyOf(result, f.task)
+	incoming, outgoing := f.rels.rels[len(f.rels.rels)-2], f.rels.rels[len(f.rels.rels)-1]
+	assert.Equal(t, f.phase.ID, incoming.SourceObjectID)
+	assert.Equal(t, task.ID, incoming.TargetObjectID)
+	assert.Equal(t, task.ID, outgoing.SourceObjectID)
+	assert.Equal(t, f.outside.ID, outgoing.TargetObjectID)
+}
+
+func TestCloneService_CloneObjectOnly(t *testing.T) {
+	f := newCloneFixture()
+	name := "project copy"
+
+	result, err := f.service.Clone(context.Background(), f.project.ID, &models.CloneObjectRequest{Name: &name, ParentObjectID: &f.outside.ID})
+
+	require.NoError(t, err)
+	assert.Len(t, result.Objects, 1)
+	assert.Empty(t, result.Relationships)
+	assert.Equal(t, "project copy", result.Object.Name)
+	assert.Equal(t, f.outside.ID, *result.Object.ParentObjectID)
+}
+
+func TestCloneService_FailedRelationshipCopyRollsBack(t *testing.T) {
+	f := newCloneFixture()
+
+	// The outside object can have one owner only, which the copy of the phase would add to
+	f.rels.add("owns", f.phase, f.outside)
+
+	_, err := f.service.Clone(context.Background(), f.phase.ID, &models.CloneObjectRequest{KeepExternalRelationships: true})
+
+	assert.ErrorIs(t, err, ErrCardinalityViolation)
+	assert.Equal(t, 1, f.db.rollbacks)
+	assert.Empty(t, f.db.outbox.committed)
+}
+
+func TestCloneService_RejectsInvalidClones(t *testing.T) {
+	f := newCloneFixture()
+	deleted := f.objects.add("deleted", f.project.ObjectTypeID, nil)
+	deletedAt := time.Now()
+	deleted.DeletedAt = &deletedAt
+	empty := ""
+	invalidParent := int64(-1)
+
+	tests := []struct {
+		name string
+		id   int64
+		req  *models.CloneObjectRequest
+		err  error
+	}{
+		{"missing object", 999, &models.CloneObjectRequest{}, repository.ErrNotFound},
+		{"deleted object", deleted.ID, &models.CloneObjectRequest{}, ErrObjectDeleted},
+		{"empty name", f.phase.ID, &models.CloneObjectRequest{Name: &empty}, repository.ErrInvalidInput},
+		{"invalid parent", f.phase.ID, &models.CloneObjectRequest{ParentObjectID: &invalidParent}, repository.ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.Clone(context.Background(), tt.id, tt.req)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+	assert.Empty(t, f.db.outbox.committed)
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// ConcreteTableService manages the typed attributes of object types and provisions the concrete
+// tables their values are stored in
+type ConcreteTableService interface {
+	GetTables(ctx context.Context, objectTypeID int64) ([]*models.ConcreteTable, error)
+	AddAttributes(ctx context.Context, objectTypeID int64, req *models.AddObjectTypeAttributesRequest, dryRun bool) (*models.ConcreteTablePlan, error)
+	GetDDL(ctx context.Context, objectTypeID int64) ([]string, error)
+}
+
+type concreteTableService struct {
+	objectTypeRepo repository.ObjectTypeRepository
+	concreteRepo   repository.ConcreteTableRepository
+	txDB           TxBeginner
+}
+
+// NewConcreteTableService creates a concrete table service that records attributes and applies
+// their DDL inside a single transaction started by txDB. Without txDB the changes run directly
+// against the repositories.
+func NewConcreteTableService(objectTypeRepo repository.ObjectTypeRepository, concreteRepo repository.ConcreteTableRepository, txDB TxBeginner) ConcreteTableService {
+	return &concreteTableService{objectTypeRepo: objectTypeRepo, concreteRepo: concreteRepo, txDB: txDB}
+}
+
+// withinTx runs fn with an object type service and a concrete table repository bound to a single
+// transaction
+func (s *concreteTableService) withinTx(ctx context.Context, fn func(objectTypes *objectTypeService, concrete repository.ConcreteTableRepository) error) error {
+	if s.txDB == nil {
+		return fn(&objectTypeService{repo: s.objectTypeRepo}, s.concreteRepo)
+	}
+	return WithinTxOptions(ctx, s.txDB, outboxTxOptions, func(tx Transaction) error {
+		return fn(&objectTypeService{repo: tx.ObjectTypeRepository(), outbox: tx.OutboxRepository()}, tx.ConcreteTableRepository())
+	})
+}
+
+// GetTables returns the concrete tables an object of the type is stored in, from the root type
+// down, with the attributes each of them holds
+func (s *concreteTableService) GetTables(ctx context.Context, objectTypeID int64) ([]*models.ConcreteTable, error) {
+	if _, err := s.objectTypeRepo.GetByID(ctx, objectTypeID); err != nil {
+		return nil, err
+	}
+	return s.concreteRepo.GetConcreteTables(ctx, objectTypeID)
+}
+
+// GetDDL returns the statements creating the concrete tables of an object type as they are now
+func (s *concreteTableService) GetDDL(ctx context.Context, objectTypeID int64) ([]string, error) {
+	tables, err := s.GetTables(ctx, objectTypeID)
+	if err != nil {
+		return nil, err
+	}
+	statements := []string{}
+	for _, table := range tables {
+		statements = append(statements, repository.CreateConcreteTableDDL(table)...)
+	}
+	return statements, nil
+}
+
+// AddAttributes declares new attributes on an object type and plans the DDL storing them: the
+// type's concrete table is created when it does not exist yet, otherwise columns are added to it.
+// Unless dryRun is set the attributes are recorded and the DDL applied in one transaction.
+func (s *concreteTableService) AddAttributes(ctx context.Context, objectTypeID int64, req *models.AddObjectTypeAttributesRequest, dryRun bool) (*models.ConcreteTablePlan, error) {
+	if len(req.Attributes) == 0 {
+		return nil, fmt.Errorf("at least one attribute is required: %w", repository.ErrInvalidInput)
+	}
+
+	if dryRun {
+		plan, _, err := planAttributes(ctx, s.objectTypeRepo, s.concreteRepo, objectTypeID, req)
+		return plan, err
+	}
+
+	var plan *models.ConcreteTablePlan
+	err := s.withinTx(ctx, func(objectTypes *objectTypeService, concrete repository.ConcreteTableRepository) error {
+		var objectType *models.ObjectType
+		var err error
+		plan, objectType, err = planAttributes(ctx, objectTypes.repo, concrete, objectTypeID, req)
+		if err != nil {
+			return err
+		}
+
+		if objectType.ConcreteTableName == nil || *objectType.ConcreteTableName != plan.TableName {
+			_, err := objectTypes.update(ctx, objectTypeID, &models.UpdateObjectTypeRequest{
+				ConcreteTableName: &plan.TableName,
+				UpdatedBy:         req.CreatedBy,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		attributes, err := concrete.CreateAttributes(ctx, objectTypeID, req.Attributes, req.CreatedBy)
+		if err != nil {
+			return err
+		}
+		if err := concrete.ApplyDDL(ctx, plan.Statements); err != nil {
+			return err
+		}
+		plan.Attributes = attributes
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	plan.Applied = true
+	return plan, nil
+}
+
+// planAttributes validates new attribute declarations against the type chain and subtree of an
+// object type and returns the DDL adding them, together with the object type
+func planAttributes(ctx context.Context, objectTypes repository.ObjectTypeRepository, concrete repository.ConcreteTableRepository, objectTypeID int64, req *models.AddObjectTypeAttributesRequest) (*models.ConcreteTablePlan, *models.ObjectType, error) {
+	objectType, err := objectTypes.GetByID(ctx, objectTypeID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Attribute names are unique along every path through the type tree, so the chain above the
+	// type and the subtree below it are both checked
+	declared := map[string]bool{"object_id": true}
+	chain, err := concrete.GetConcreteTables(ctx, objectTypeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, table := range chain {
+		for _, attribute := range table.Attributes {
+			declared[attribute.Name] = true
+		}
+	}
+	subtree, err := concrete.ListSubtreeAttributes(ctx, objectTypeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, attribute := range subtree {
+		declared[attribute.Name] = true
+	}
+
+	own, err := concrete.ListAttributes(ctx, objectTypeID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attributes := make([]*models.ObjectTypeAttribute, 0, len(req.Attributes))
+	required := false
+	for i, declaration := range req.Attributes {
+		if !models.IsValidSQLIdentifier(declaration.Name) {
+			return nil, nil, fmt.Errorf("attribute name %q must be a lowercase identifier of at most 63 characters: %w", declaration.Name, repository.ErrInvalidInput)
+		}
+		if declared[declaration.Name] {
+			return nil, nil, fmt.Errorf("attribute %q is already declared: %w", declaration.Name, repository.ErrInvalidInput)
+		}
+		if !models.IsValidAttributeDataType(declaration.DataType) {
+			return nil, nil, fmt.Errorf("attribute %q has unsupported data type %q: %w", declaration.Name, declaration.DataType, repository.ErrInvalidInput)
+		}
+		declared[declaration.Name] = true
+		required = required || !declaration.IsNullable()
+
+		attributes = append(attributes, &models.ObjectTypeAttribute{
+			ObjectTypeID: objectTypeID,
+			Name:         declaration.Name,
+			DataType:     declaration.DataType,
+			Nullable:     declaration.IsNullable(),
+			Indexed:      declaration.Indexed,
+			Position:     len(own) + i + 1,
+			CreatedBy:    req.CreatedBy,
+		})
+	}
+
+	tableName := models.DefaultConcreteTableName(objectType.Name)
+	if objectType.ConcreteTableName != nil && *objectType.ConcreteTableName != "" {
+		tableName = *objectType.ConcreteTableName
+	}
+	if !models.IsValidSQLIdentifier(tableName) {
+		return nil, nil, fmt.Errorf("object type %q needs a concrete table name that is a lowercase identifier: %w", objectType.Name, repository.ErrInvalidInput)
+	}
+
+	exists, owner, err := concrete.TableOwner(ctx, tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exists && owner != objectTypeID {
+		return nil, nil, fmt.Errorf("table %s already exists and is not the concrete table of object type %q: %w", tableName, objectType.Name, repository.ErrInvalidInput)
+	}
+
+	// A non-nullable column has no value for objects stored before it was added
+	if required {
+		count, err := objectTypes.GetSubtreeObjectCount(ctx, objectTypeID)
+		if err != nil {
+			return nil, nil, err
+		}
+		hasRows := false
+		if exists {
+			if hasRows, err = concrete.TableHasRows(ctx, tableName); err != nil {
+				return nil, nil, err
+			}
+		}
+		if count > 0 || hasRows {
+			return nil, nil, fmt.Errorf("non-nullable attributes can only be added while object type %q has no objects: %w", objectType.Name, repository.ErrInvalidInput)
+		}
+	}
+
+	plan := &models.ConcreteTablePlan{
+		ObjectTypeID: objectTypeID,
+		TableName:    tableName,
+		Attributes:   attributes,
+	}
+	if exists {
+		plan.Statements = repository.AddConcreteColumnsDDL(tableName, attributes)
+	} else {
+		plan.Statements = repository.CreateConcreteTableDDL(&models.ConcreteTable{
+			ObjectTypeID: objectTypeID,
+			TableName:    tableName,
+			Attributes:   append(own, attributes...),
+		})
+	}
+	return plan, objectType, nil
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// memoryConcreteTables is an in-memory ConcreteTableRepository over the type tree of
+// memoryObjectTypes. Tables map to the type owning them; applied collects executed DDL.
+type memoryConcreteTables struct {
+	repository.ConcreteTableRepository
+	types      *memoryObjectTypes
+	attributes map[int64][]*models.ObjectTypeAttribute
+	tables     map[string]int64
+	rows       map[string]bool
+	applied    []string
+}
+
+func newMemoryConcreteTables(types *memoryObjectTypes) *memoryConcreteTables {
+	return &memoryConcreteTables{
+		types:      types,
+		attributes: map[int64][]*models.ObjectTypeAttribute{},
+		tables:     map[string]int64{},
+		rows:       map[string]bool{},
+	}
+}
+
+func (r *memoryConcreteTables) ListAttributes(ctx context.Context, objectTypeID int64) ([]*models.ObjectTypeAttribute, error) {
+	return append([]*models.ObjectTypeAttribute{}, r.attributes[objectTypeID]...), nil
+}
+
+func (r *memoryConcreteTables) CreateAttributes(ctx context.Context, objectTypeID int64, declarations []models.AttributeDeclaration, createdBy string) ([]*models.ObjectTypeAttribute, error) {
+	var created []*models.ObjectTypeAttribute
+	for _, declaration := range declarations {
+		attribute := &models.ObjectTypeAttribute{
+			ObjectTypeID: objectTypeID,
+			Name:         declaration.Name,
+			DataType:     declaration.DataType,
+			Nullable:     declaration.IsNullable(),
+			Indexed:      declaration.Indexed,
+			Position:     len(r.attributes[objectTypeID]) + 1,
+			CreatedBy:    createdBy,
+		}
+		r.attributes[objectTypeID] = append(r.attributes[objectTypeID], attribute)
+		created = append(created, attribute)
+	}
+	return created, nil
+}
+
+func (r *memoryConcreteTables) GetConcreteTables(ctx context.Context, objectTypeID int64) ([]*models.ConcreteTable, error) {
+	var tables []*models.ConcreteTable
+	for id := &objectTypeID; id != nil; id = r.types.types[*id].ParentTypeID {
+		objectType := r.types.types[*id]
+		if len(r.attributes[objectType.ID]) == 0 {
+			continue
+		}
+		table := &models.ConcreteTable{ObjectTypeID: objectType.ID, TableName: *objectType.ConcreteTableName, Attributes: r.attributes[objectType.ID]}
+		tables = append([]*models.ConcreteTable{table}, tables...)
+	}
+	return tables, nil
+}
+
+func (r *memoryConcreteTables) ListSubtreeAttributes(ctx context.Context, objectTypeID int64) ([]*models.ObjectTypeAttribute, error) {
+	attributes := append([]*models.ObjectTypeAttribute{}, r.attributes[objectTypeID]...)
+	for _, objectType := range r.types.types {
+		if objectType.ParentTypeID != nil && *objectType.ParentTypeID == objectTypeID {
+			below, _ := r.ListSubtreeAttributes(ctx, objectType.ID)
+			attributes = append(attributes, below...)
+		}
+	}
+	return attributes, nil
+}
+
+func (r *memoryConcreteTables) TableOwner(ctx context.Context, tableName string) (bool, int64, error) {
+	owner, ok := r.tables[tableName]
+	return ok, owner, nil
+}
+
+func (r *memoryConcreteTables) TableHasRows(ctx context.Context, tableName string) (bool, error) {
+	return r.rows[tableName], nil
+}
+
+func (r *memoryConcreteTables) ApplyDDL(ctx context.Context, statements []string) error {
+	r.applied = append(r.applied, statements...)
+	return nil
+}
+
+func newConcreteTableFixture() (*memoryObjectTypes, *memoryConcreteTables, ConcreteTableService) {
+	types, _ := newTaxonomyFixture()
+	concrete := newMemoryConcreteTables(types)
+	return types, concrete, NewConcreteTableService(types, concrete, nil)
+}
+
+func attributesRequest(declarations ...models.AttributeDeclaration) *models.AddObjectTypeAttributesRequest {
+	return &models.AddObjectTypeAttributesRequest{Attributes: declarations, CreatedBy: "admin"}
+}
+
+func TestConcreteTableService_AddAttributesCreatesTable(t *testing.T) {
+	types, concrete, service := newConcreteTableFixture()
+	book := types.byName("Book")
+	required := false
+
+	plan, err := service.AddAttributes(context.Background(), book.ID, attributesRequest(
+		models.AttributeDeclaration{Name: "isbn", DataType: "text", Indexed: true},
+		models.AttributeDeclaration{Name: "pages", DataType: "integer", Nullable: &required},
+	), false)
+
+	require.NoError(t, err)
+	assert.True(t, plan.Applied)
+	assert.Equal(t, "objects_book", plan.TableName)
+	assert.Equal(t, "objects_book", *types.byName("Book").ConcreteTableName)
+	require.Len(t, plan.Statements, 3)
+	assert.Contains(t, plan.Statements[0], `CREATE TABLE "objects_service"."objects_book"`)
+	assert.Contains(t, plan.Statements[0], `"pages" INTEGER NOT NULL`)
+	assert.Equal(t, plan.Statements, concrete.applied)
+	assert.Len(t, concrete.attributes[book.ID], 2)
+}
+
+func TestConcreteTableService_AddAttributesAltersExistingTable(t *testing.T) {
+	types, concrete, service := newConcreteTableFixture()
+	book := types.byName("Book")
+	ctx := context.Background()
+	_, err := service.AddAttributes(ctx, book.ID, attributesRequest(models.AttributeDeclaration{Name: "isbn", DataType: "text"}), false)
+	require.NoError(t, err)
+	concrete.tables["objects_book"] = book.ID
+	concrete.applied = nil
+
+	plan, err := service.AddAttributes(ctx, book.ID, attributesRequest(models.AttributeDeclaration{Name: "pages", DataType: "integer"}), false)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{`ALTER TABLE "objects_service"."objects_book" ADD COLUMN "pages" INTEGER`}, concrete.applied)
+	assert.Equal(t, 2, plan.Attributes[0].Position)
+}
+
+func TestConcreteTableService_DryRunChangesNothing(t *testing.T) {
+	types, concrete, service := newConcreteTableFixture()
+	book := types.byName("Book")
+
+	plan, err := service.AddAttributes(context.Background(), book.ID, attributesRequest(models.AttributeDeclaration{Name: "isbn", DataType: "text"}), true)
+
+	require.NoError(t, err)
+	assert.False(t, plan.Applied)
+	assert.NotEmpty(t, plan.Statements)
+	assert.Empty(t, concrete.applied)
+	assert.Empty(t, concrete.attributes)
+	assert.Nil(t, types.byName("Book").ConcreteTableName)
+}
+
+func TestConcreteTableService_RejectsInvalidAttributes(t *testing.T) {
+	types, _, service := newConcreteTableFixture()
+	ctx := context.Background()
+	book := types.byName("Book")
+	_, err := service.AddAttributes(ctx, book.ID, attributesRequest(models.AttributeDeclaration{Name: "isbn", DataType: "text"}), false)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		objectType  string
+		declaration models.AttributeDeclaration
+	}{
+		{"invalid name", "Book", models.AttributeDeclaration{Name: "Page Count", DataType: "integer"}},
+		{"reserved name", "Book", models.AttributeDeclaration{Name: "object_id", DataType: "bigint"}},
+		{"unsupported type", "Book", models.AttributeDeclaration{Name: "cover", DataType: "blob"}},
+		{"declared by ancestor", "EBook", models.AttributeDeclaration{Name: "isbn", DataType: "text"}},
+		{"declared by descendant", "Product", models.AttributeDeclaration{Name: "isbn", DataType: "text"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.AddAttributes(ctx, types.byName(tt.objectType).ID, attributesRequest(tt.declaration), true)
+			assert.ErrorIs(t, err, repository.ErrInvalidInput)
+		})
+	}
+}
+
+func TestConcreteTableService_RejectsRequiredAttributeWithObjects(t *testing.T) {
+	types, _, service := newConcreteTableFixture()
+	types.objects[types.byName("EBook").ID] = 1
+	required := false
+
+	_, err := service.AddAttributes(context.Background(), types.byName("Book").ID, attributesRequest(
+		models.AttributeDeclaration{Name: "isbn", DataType: "text", Nullable: &required},
+	), true)
+
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+}
+
+func TestConcreteTableService_RejectsForeignTable(t *testing.T) {
+	types, concrete, service := newConcreteTableFixture()
+	concrete.tables["objects_book"] = 0
+
+	_, err := service.AddAttributes(context.Background(), types.byName("Book").ID, attributesRequest(
+		models.AttributeDeclaration{Name: "isbn", DataType: "text"},
+	), true)
+
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+}
+
+func TestConcreteTableService_GetDDL(t *testing.T) {
+	types, _, service := newConcreteTableFixture()
+	ctx := context.Background()
+	_, err := service.AddAttributes(ctx, types.byName("Product").ID, attributesRequest(models.AttributeDeclaration{Name: "sku", DataType: "text"}), false)
+	require.NoError(t, err)
+	_, err = service.AddAttributes(ctx, types.byName("Book").ID, attributesRequest(models.AttributeDeclaration{Name: "isbn", DataType: "text"}), false)
+	require.NoError(t, err)
+
+	statements, err := service.GetDDL(ctx, types.byName("EBook").ID)
+
+	require.NoError(t, err)
+	require.Len(t, statements, 4)
+	assert.Contains(t, statements[0], `CREATE TABLE "objects_service"."objects_product"`)
+	assert.Contains(t, statements[2], `CREATE TABLE "objects_service"."objects_book"`)
+}
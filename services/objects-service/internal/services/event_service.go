@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+const (
+	defaultEventPageSize = 100
+	maxEventPageSize     = 1000
+)
+
+// EventService reads the log of domain events written to the outbox
+type EventService interface {
+	List(ctx context.Context, filter *models.OutboxEventFilter) (*models.OutboxEventPage, error)
+}
+
+type eventService struct {
+	repo repository.OutboxRepository
+}
+
+func NewEventService(repo repository.OutboxRepository) EventService {
+	return &eventService{repo: repo}
+}
+
+// List returns the committed events after filter.After. Consumers continue from NextOffset; the
+// log only grows at its end, so a page once read stays the same.
+func (s *eventService) List(ctx context.Context, filter *models.OutboxEventFilter) (*models.OutboxEventPage, error) {
+	if filter.After < 0 {
+		return nil, fmt.Errorf("after must not be negative: %w", repository.ErrInvalidInput)
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultEventPageSize
+	}
+	if filter.Limit < 0 || filter.Limit > maxEventPageSize {
+		return nil, fmt.Errorf("limit must be between 1 and %d: %w", maxEventPageSize, repository.ErrInvalidInput)
+	}
+	switch filter.EntityType {
+	case "", models.EventEntityObject, models.EventEntityObjectType, models.EventEntityRelationship:
+	default:
+		return nil, fmt.Errorf("unknown entity type %q: %w", filter.EntityType, repository.ErrInvalidInput)
+	}
+
+	// One event more than asked tells whether another page follows
+	query := *filter
+	query.Limit++
+	events, err := s.repo.List(ctx, &query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.OutboxEventPage{Events: events, NextOffset: filter.After}
+	if len(events) > filter.Limit {
+		page.Events = events[:filter.Limit]
+		page.HasMore = true
+	}
+	if len(page.Events) > 0 {
+		page.NextOffset = page.Events[len(page.Events)-1].Offset
+	}
+	return page, nil
+}
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+var ErrInvalidMetadataSchema = errors.New("invalid metadata schema")
+
+const (
+	// metadataSchemaURL is the in-memory location schemas are compiled under
+	metadataSchemaURL = "mem://objects-service/metadata-schema.json"
+
+	// metadataValidationPageSize is how many objects are loaded at a time when validating existing objects
+	metadataValidationPageSize = 500
+
+	// maxReportedViolations caps the violations listed in a MetadataValidationReport
+	maxReportedViolations = 100
+)
+
+// MetadataValidationError is returned when object metadata does not satisfy the effective
+// schema of its object type. It wraps repository.ErrInvalidInput.
+type MetadataValidationError struct {
+	ObjectTypeID int64
+	Index        *int // position in a bulk request
+	Errors       []models.MetadataFieldError
+}
+
+func (e *MetadataValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		path := fieldErr.Path
+		if path == "" {
+			path = "/"
+		}
+		messages = append(messages, path+": "+fieldErr.Message)
+	}
+
+	prefix := ""
+	if e.Index != nil {
+		prefix = fmt.Sprintf("object[%d]: ", *e.Index)
+	}
+	return fmt.Sprintf("%smetadata does not match schema of object type %d: %s", prefix, e.ObjectTypeID, strings.Join(messages, "; "))
+}
+
+func (e *MetadataValidationError) Unwrap() error {
+	return repository.ErrInvalidInput
+}
+
+// mergeMetadataSchemas layers a child type's schema over its parent's: properties are merged
+// with the child's definition of a property replacing the parent's, required lists are unioned
+// and any other keyword set by the child wins.
+func mergeMetadataSchemas(parent, child map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(parent)+len(child))
+	for key, value := range parent {
+		merged[key] = value
+	}
+
+	for key, value := range child {
+		switch key {
+		case "properties":
+			childProps, ok := value.(map[string]interface{})
+			parentProps, parentOK := merged[key].(map[string]interface{})
+			if !ok || !parentOK {
+				merged[key] = value
+				continue
+			}
+			props := make(map[string]interface{}, len(parentProps)+len(childProps))
+			for name, prop := range parentProps {
+				props[name] = prop
+			}
+			for name, prop := range childProps {
+				props[name] = prop
+			}
+			merged[key] = props
+		case "required":
+			childRequired, ok := value.([]interface{})
+			parentRequired, parentOK := merged[key].([]interface{})
+			if !ok || !parentOK {
+				merged[key] = value
+				continue
+			}
+			seen := make(map[interface{}]bool, len(parentRequired)+len(childRequired))
+			required := make([]interface{}, 0, len(parentRequired)+len(childRequired))
+			for _, name := range append(append([]interface{}{}, parentRequired...), childRequired...) {
+				if !seen[name] {
+					seen[name] = true
+					required = append(required, name)
+				}
+			}
+			merged[key] = required
+		default:
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
+// decodeMetadataSchema parses a stored or submitted schema; empty schemas decode to nil
+func decodeMetadataSchema(raw json.RawMessage) (map[string]interface{}, error) {
+	if models.IsEmptyMetadataSchema(raw) {
+		return nil, nil
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("metadata schema must be a JSON object: %w", ErrInvalidMetadataSchema)
+	}
+	return schema, nil
+}
+
+// compileMetadataSchema compiles a JSON Schema document. Remote and file $refs are refused.
+func compileMetadataSchema(schema map[string]interface{}) (*jsonschema.Schema, error) {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidMetadataSchema)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("external schema reference %q is not allowed", url)
+	}
+	if err := compiler.AddResource(metadataSchemaURL, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidMetadataSchema)
+	}
+
+	compiled, err := compiler.Compile(metadataSchemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidMetadataSchema)
+	}
+	return compiled, nil
+}
+
+// effectiveMetadataSchema merges the schemas along an object type's parent chain.
+// It returns nil when neither the type nor any ancestor declares a schema.
+func effectiveMetadataSchema(ctx context.Context, objectTypeRepo repository.ObjectTypeRepository, objectTypeID int64) (map[string]interface{}, error) {
+	path, err := objectTypeRepo.GetPath(ctx, objectTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve object type hierarchy: %w", err)
+	}
+
+	var effective map[string]interface{}
+	for _, objectType := range path {
+		schema, err := decodeMetadataSchema(objectType.MetadataSchema)
+		if err != nil {
+			return nil, fmt.Errorf("object type %d: %w", objectType.ID, err)
+		}
+		if schema != nil {
+			effective = mergeMetadataSchemas(effective, schema)
+		}
+	}
+	return effective, nil
+}
+
+// validateMetadataAgainst checks metadata with a compiled schema and returns the leaf violations
+func validateMetadataAgainst(schema *jsonschema.Schema, metadata map[string]interface{}) ([]models.MetadataFieldError, error) {
+	if metadata == nil {
+		// Objects without metadata are checked as an empty object
+		metadata = map[string]interface{}{}
+	}
+
+	// Round-trip through JSON so numbers and nested values have the types the validator expects
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("metadata is not valid JSON: %w", repository.ErrInvalidInput)
+	}
+	var instance interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&instance); err != nil {
+		return nil, fmt.Errorf("metadata is not valid JSON: %w", repository.ErrInvalidInput)
+	}
+
+	err = schema.Validate(instance)
+	if err == nil {
+		return nil, nil
+	}
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return nil, fmt.Errorf("failed to validate metadata: %w", err)
+	}
+
+	var fieldErrors []models.MetadataFieldError
+	var collect func(*jsonschema.ValidationError)
+	collect = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			fieldErrors = append(fieldErrors, models.MetadataFieldError{Path: e.InstanceLocation, Message: e.Message})
+			return
+		}
+		for _, cause := range e.Causes {
+			collect(cause)
+		}
+	}
+	collect(validationErr)
+
+	sort.SliceStable(fieldErrors, func(i, j int) bool { return fieldErrors[i].Path < fieldErrors[j].Path })
+	return fieldErrors, nil
+}
+
+// metadataSchemaCache compiles each object type's effective schema once per operation
+type metadataSchemaCache struct {
+	objectTypeRepo repository.ObjectTypeRepository
+	schemas        map[int64]*jsonschema.Schema
+}
+
+func newMetadataSchemaCache(objectTypeRepo repository.ObjectTypeRepository) *metadataSchemaCache {
+	return &metadataSchemaCache{objectTypeRepo: objectTypeRepo, schemas: make(map[int64]*jsonschema.Schema)}
+}
+
+// validate returns a *MetadataValidationError when metadata violates the type's effective schema
+func (c *metadataSchemaCache) validate(ctx context.Context, objectTypeID int64, metadata map[string]interface{}) error {
+	compiled, ok := c.schemas[objectTypeID]
+	if !ok {
+		schema, err := effectiveMetadataSchema(ctx, c.objectTypeRepo, objectTypeID)
+		if err != nil {
+			return err
+		}
+		if schema != nil {
+			if compiled, err = compileMetadataSchema(schema); err != nil {
+				return fmt.Errorf("object type %d: %w", objectTypeID, err)
+			}
+		}
+		c.schemas[objectTypeID] = compiled
+	}
+	if compiled == nil {
+		return nil
+	}
+
+	fieldErrors, err := validateMetadataAgainst(compiled, metadata)
+	if err != nil {
+		return err
+	}
+	if len(fieldErrors) > 0 {
+		return &MetadataValidationError{ObjectTypeID: objectTypeID, Errors: fieldErrors}
+	}
+	return nil
+}
+
+// ValidateExistingMetadata checks the metadata of every object of an object type and its
+// subtypes against the effective schema. A non-empty candidate replaces the type's own schema,
+// so a schema change can be tried out before it is saved.
+func (s *objectService) ValidateExistingMetadata(ctx context.Context, objectTypeID int64, candidate json.RawMessage) (*models.MetadataValidationReport, error) {
+	if objectTypeID <= 0 {
+		return nil, fmt.Errorf("invalid object type id: %w", repository.ErrInvalidInput)
+	}
+
+	ancestors, err := s.objectTypeRepo.GetPath(ctx, objectTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object type: %w", err)
+	}
+	subtree, err := s.objectTypeRepo.GetDescendants(ctx, objectTypeID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object subtypes: %w", err)
+	}
+
+	ownSchema := func(objectType *models.ObjectType) (map[string]interface{}, error) {
+		raw := objectType.MetadataSchema
+		if objectType.ID == objectTypeID && len(bytes.TrimSpace(candidate)) > 0 {
+			raw = candidate
+		}
+		schema, err := decodeMetadataSchema(raw)
+		if err != nil {
+			return nil, fmt.Errorf("object type %d: %w", objectType.ID, err)
+		}
+		return schema, nil
+	}
+
+	// Effective schemas of the root type (from its ancestors) and then of each subtype
+	effective := make(map[int64]map[string]interface{}, len(subtree))
+	var inherited map[string]interface{}
+	for _, objectType := range ancestors {
+		schema, err := ownSchema(objectType)
+		if err != nil {
+			return nil, err
+		}
+		if schema != nil {
+			inherited = mergeMetadataSchemas(inherited, schema)
+		}
+	}
+	effective[objectTypeID] = inherited
+
+	parents := make(map[int64]*models.ObjectType, len(subtree))
+	for _, objectType := range subtree {
+		parents[objectType.ID] = objectType
+	}
+	var resolve func(objectType *models.ObjectType) (map[string]interface{}, error)
+	resolve = func(objectType *models.ObjectType) (map[string]interface{}, error) {
+		if schema, ok := effective[objectType.ID]; ok {
+			return schema, nil
+		}
+		var parentSchema map[string]interface{}
+		if objectType.ParentTypeID != nil {
+			if parent, ok := parents[*objectType.ParentTypeID]; ok {
+				var err error
+				if parentSchema, err = resolve(parent); err != nil {
+					return nil, err
+				}
+			}
+		}
+		schema, err := ownSchema(objectType)
+		if err != nil {
+			return nil, err
+		}
+		if schema != nil {
+			parentSchema = mergeMetadataSchemas(parentSchema, schema)
+		}
+		effective[objectType.ID] = parentSchema
+		return parentSchema, nil
+	}
+
+	report := &models.MetadataValidationReport{ObjectTypeID: objectTypeID, Violations: []models.ObjectMetadataViolation{}}
+	for _, objectType := range subtree {
+		schema, err := resolve(objectType)
+		if err != nil {
+			return nil, err
+		}
+		if schema == nil {
+			continue
+		}
+		compiled, err := compileMetadataSchema(schema)
+		if err != nil {
+			return nil, fmt.Errorf("object type %d: %w", objectType.ID, err)
+		}
+
+		typeID := objectType.ID
+		for offset := 0; ; offset += metadataValidationPageSize {
+			objects, _, err := s.repo.List(ctx, &models.ObjectFilter{
+				ObjectTypeID: &typeID,
+				SortBy:       "id",
+				Limit:        metadataValidationPageSize,
+				Offset:       offset,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list objects: %w", err)
+			}
+
+			for _, obj := range objects {
+				report.Checked++
+				fieldErrors, err := validateMetadataAgainst(compiled, obj.GetMetadataMap())
+				if err != nil {
+					return nil, err
+				}
+				if len(fieldErrors) == 0 {
+					continue
+				}
+				report.Invalid++
+				if len(report.Violations) >= maxReportedViolations {
+					report.Truncated = true
+					continue
+				}
+				report.Violations = append(report.Violations, models.ObjectMetadataViolation{
+					ObjectID:     obj.ID,
+					Name:         obj.Name,
+					ObjectTypeID: obj.ObjectTypeID,
+					Errors:       fieldErrors,
+				})
+			}
+
+			if len(objects) < metadataValidationPageSize {
+				break
+			}
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+// invoiceTypeRepo models "document" (id 1) with a required number field and its subtype
+// "invoice" (id 2) that adds a currency enum
+func invoiceTypeRepo() *mockObjectTypeRepositoryForObjectService {
+	document := &models.ObjectType{ID: 1, Name: "document", MetadataSchema: json.RawMessage(
+		`{"type":"object","properties":{"number":{"type":"string"}},"required":["number"]}`)}
+	invoice := &models.ObjectType{ID: 2, Name: "invoice", ParentTypeID: int64Ptr(1), MetadataSchema: json.RawMessage(
+		`{"properties":{"currency":{"enum":["EUR","USD"]},"amount":{"type":"number"}},"required":["currency"],"additionalProperties":false}`)}
+
+	return &mockObjectTypeRepositoryForObjectService{
+		getPathFunc: func(ctx context.Context, id int64) ([]*models.ObjectType, error) {
+			if id == 1 {
+				return []*models.ObjectType{document}, nil
+			}
+			return []*models.ObjectType{document, invoice}, nil
+		},
+		getDescendantsFunc: func(ctx context.Context, rootID int64, maxDepth *int) ([]*models.ObjectType, error) {
+			if rootID == 1 {
+				return []*models.ObjectType{document, invoice}, nil
+			}
+			return []*models.ObjectType{invoice}, nil
+		},
+	}
+}
+
+func TestMergeMetadataSchemas(t *testing.T) {
+	parent := map[string]interface{}{
+		"type":                 "object",
+		"properties":           map[string]interface{}{"a": "parent", "b": "parent"},
+		"required":             []interface{}{"a"},
+		"additionalProperties": true,
+	}
+	child := map[string]interface{}{
+		"properties":           map[string]interface{}{"b": "child", "c": "child"},
+		"required":             []interface{}{"a", "c"},
+		"additionalProperties": false,
+	}
+
+	merged := mergeMetadataSchemas(parent, child)
+
+	assert.Equal(t, "object", merged["type"])
+	assert.Equal(t, map[string]interface{}{"a": "parent", "b": "child", "c": "child"}, merged["properties"])
+	assert.Equal(t, []interface{}{"a", "c"}, merged["required"])
+	assert.Equal(t, false, merged["additionalProperties"])
+	assert.Equal(t, map[string]interface{}{"a": "parent", "b": "parent"}, parent["properties"], "parent must not be modified")
+}
+
+func TestCompileMetadataSchema_RejectsExternalRefs(t *testing.T) {
+	_, err := compileMetadataSchema(map[string]interface{}{"$ref": "https://example.com/schema.json"})
+	assert.True(t, errors.Is(err, ErrInvalidMetadataSchema))
+
+	_, err = compileMetadataSchema(map[string]interface{}{"type": "no-such-type"})
+	assert.True(t, errors.Is(err, ErrInvalidMetadataSchema))
+}
+
+func TestObjectService_Create_MetadataSchemaViolation(t *testing.T) {
+	service := NewObjectService(&mockObjectRepository{}, invoiceTypeRepo())
+
+	_, err := service.Create(context.Background(), &models.CreateObjectRequest{
+		Name:         "INV-1",
+		ObjectTypeID: 2,
+		Metadata:     map[string]interface{}{"currency": "GBP", "amount": "12.50", "note": "x"},
+	})
+
+	var metadataErr *MetadataValidationError
+	require.True(t, errors.As(err, &metadataErr))
+	assert.True(t, errors.Is(err, repository.ErrInvalidInput))
+	assert.Equal(t, int64(2), metadataErr.ObjectTypeID)
+	assert.Nil(t, metadataErr.Index)
+
+	paths := make([]string, 0, len(metadataErr.Errors))
+	for _, fieldErr := range metadataErr.Errors {
+		paths = append(paths, fieldErr.Path)
+	}
+	// number is inherited from "document"; the rest come from "invoice"
+	assert.ElementsMatch(t, []string{"", "", "/amount", "/currency"}, paths)
+}
+
+func TestObjectService_Create_MetadataMatchesInheritedSchema(t *testing.T) {
+	service := NewObjectService(&mockObjectRepository{}, invoiceTypeRepo())
+
+	result, err := service.Create(context.Background(), &models.CreateObjectRequest{
+		Name:         "INV-2",
+		ObjectTypeID: 2,
+		Metadata:     map[string]interface{}{"number": "2024-001", "currency": "EUR", "amount": 12.5},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "INV-2", result.Name)
+}
+
+func TestObjectService_UpdateMetadata_SchemaViolation(t *testing.T) {
+	updated := false
+	mockRepo := &mockObjectRepository{
+		getByIDFunc: func(ctx context.Context, id int64) (*models.Object, error) {
+			return &models.Object{ID: id, ObjectTypeID: 1}, nil
+		},
+		updateMetadataFunc: func(ctx context.Context, id int64, metadata map[string]interface{}, updatedBy string) error {
+			updated = true
+			return nil
+		},
+	}
+	service := NewObjectService(mockRepo, invoiceTypeRepo())
+
+	err := service.UpdateMetadata(context.Background(), 7, map[string]interface{}{"number": 42}, "user-1")
+
+	var metadataErr *MetadataValidationError
+	require.True(t, errors.As(err, &metadataErr))
+	assert.Equal(t, "/number", metadataErr.Errors[0].Path)
+	assert.False(t, updated)
+}
+
+func TestObjectService_Update_TypeChangeRevalidatesMetadata(t *testing.T) {
+	mockRepo := &mockObjectRepository{
+		getByIDFunc: func(ctx context.Context, id int64) (*models.Object, error) {
+			return &models.Object{ID: id, ObjectTypeID: 1, Metadata: json.RawMessage(`{"number":"A-1"}`)}, nil
+		},
+	}
+	service := NewObjectService(mockRepo, invoiceTypeRepo())
+
+	_, err := service.Update(context.Background(), 7, &models.UpdateObjectRequest{ObjectTypeID: int64Ptr(2)})
+
+	var metadataErr *MetadataValidationError
+	require.True(t, errors.As(err, &metadataErr))
+	assert.Equal(t, int64(2), metadataErr.ObjectTypeID)
+}
+
+func TestObjectService_BulkCreate_MetadataSchemaViolationIndex(t *testing.T) {
+	service := NewObjectService(&mockObjectRepository{}, invoiceTypeRepo())
+
+	_, err := service.BulkCreate(context.Background(), []*models.CreateObjectRequest{
+		{Name: "doc", ObjectTypeID: 1, Metadata: map[string]interface{}{"number": "D-1"}},
+		{Name: "inv", ObjectTypeID: 2, Metadata: map[string]interface{}{"number": "I-1"}},
+	})
+
+	var metadataErr *MetadataValidationError
+	require.True(t, errors.As(err, &metadataErr))
+	require.NotNil(t, metadataErr.Index)
+	assert.Equal(t, 1, *metadataErr.Index)
+	assert.Contains(t, err.Error(), "object[1]")
+}
+
+func TestObjectService_ValidateExistingMetadata(t *testing.T) {
+	objectsByType := map[int64][]*models.Object{
+		1: {{ID: 10, Name: "doc", ObjectTypeID: 1, Metadata: json.RawMessage(`{"number":"D-1"}`)}},
+		2: {
+			{ID: 20, Name: "inv-ok", ObjectTypeID: 2, Metadata: json.RawMessage(`{"number":"I-1","currency":"EUR","vat":"19"}`)},
+			{ID: 21, Name: "inv-bad", ObjectTypeID: 2, Metadata: json.RawMessage(`{"number":"I-2","currency":"EUR","vat":19}`)},
+		},
+	}
+	mockRepo := &mockObjectRepository{
+		listFunc: func(ctx context.Context, filter *models.ObjectFilter) ([]*models.Object, int64, error) {
+			objects := objectsByType[*filter.ObjectTypeID]
+			return objects, int64(len(objects)), nil
+		},
+	}
+	service := NewObjectService(mockRepo, invoiceTypeRepo())
+
+	t.Run("candidate schema for the root type applies to subtypes", func(t *testing.T) {
+		candidate := json.RawMessage(`{"type":"object","properties":{"number":{"type":"string"},"vat":{"type":"string"}},"required":["number"]}`)
+
+		report, err := service.ValidateExistingMetadata(context.Background(), 1, candidate)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, report.Checked)
+		assert.Equal(t, 1, report.Invalid)
+		require.Len(t, report.Violations, 1)
+		assert.Equal(t, int64(21), report.Violations[0].ObjectID)
+		assert.Equal(t, "/vat", report.Violations[0].Errors[0].Path)
+	})
+
+	t.Run("stored schema", func(t *testing.T) {
+		report, err := service.ValidateExistingMetadata(context.Background(), 2, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Checked)
+		// additionalProperties:false on invoice rejects the vat key
+		assert.Equal(t, 2, report.Invalid)
+		assert.False(t, report.Truncated)
+	})
+
+	t.Run("invalid candidate", func(t *testing.T) {
+		_, err := service.ValidateExistingMetadata(context.Background(), 1, json.RawMessage(`[1,2]`))
+		assert.True(t, errors.Is(err, ErrInvalidMetadataSchema))
+	})
+}
+
+func TestObjectTypeService_Create_InvalidMetadataSchema(t *testing.T) {
+	created := false
+	mockRepo := &mockObjectTypeRepository{
+		createFunc: func(ctx context.Context, input *models.CreateObjectTypeRequest) (*models.ObjectType, error) {
+			created = true
+			return &models.ObjectType{ID: 1, Name: input.Name}, nil
+		},
+	}
+	service := NewObjectTypeService(mockRepo)
+
+	_, err := service.Create(context.Background(), &models.CreateObjectTypeRequest{
+		Name:           "invoice",
+		MetadataSchema: json.RawMessage(`{"type":"object","properties":{"amount":{"type":"money"}}}`),
+	})
+
+	assert.True(t, errors.Is(err, ErrInvalidMetadataSchema))
+	assert.False(t, created)
+}
+
+func TestObjectTypeService_GetEffectiveMetadataSchema(t *testing.T) {
+	mockRepo := &mockObjectTypeRepository{
+		getPathFunc: invoiceTypeRepo().getPathFunc,
+	}
+	service := NewObjectTypeService(mockRepo)
+
+	schema, err := service.GetEffectiveMetadataSchema(context.Background(), 2)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(schema, &decoded))
+	assert.Equal(t, "object", decoded["type"])
+	assert.ElementsMatch(t, []interface{}{"number", "currency"}, decoded["required"])
+	assert.Len(t, decoded["properties"], 3)
+}
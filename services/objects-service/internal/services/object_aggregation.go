@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+const (
+	maxAggregationDimensions = 3
+	maxAggregationMetrics    = 10
+	defaultAggregationSize   = 100
+	maxAggregationSize       = 1000
+
+	maxSearchFacets        = 5
+	defaultSearchFacetSize = 10
+	maxSearchFacetSize     = 100
+)
+
+// Aggregate counts the objects selected by req.Filter in buckets of its dimensions and date
+// histogram, computing its metrics per bucket
+func (s *objectService) Aggregate(ctx context.Context, req *models.ObjectAggregationRequest) (*models.ObjectAggregationResult, error) {
+	if req == nil {
+		return nil, fmt.Errorf("aggregation request is required: %w", repository.ErrInvalidInput)
+	}
+	if len(req.GroupBy) > maxAggregationDimensions {
+		return nil, fmt.Errorf("at most %d group_by dimensions are allowed: %w", maxAggregationDimensions, repository.ErrInvalidInput)
+	}
+	if len(req.Metrics) > maxAggregationMetrics {
+		return nil, fmt.Errorf("at most %d metrics are allowed: %w", maxAggregationMetrics, repository.ErrInvalidInput)
+	}
+	if req.Size < 0 {
+		return nil, fmt.Errorf("size cannot be negative: %w", repository.ErrInvalidInput)
+	}
+	if req.Filter.Status != "" && !models.ValidStatuses[req.Filter.Status] {
+		return nil, fmt.Errorf("invalid status %q: %w", req.Filter.Status, repository.ErrInvalidInput)
+	}
+
+	normalized := *req
+	if normalized.Size == 0 {
+		normalized.Size = defaultAggregationSize
+	}
+	if normalized.Size > maxAggregationSize {
+		normalized.Size = maxAggregationSize
+	}
+
+	return s.repo.Aggregate(ctx, &normalized)
+}
+
+// normalizeFacets splits comma-separated facets, drops repeats and checks that every facet is
+// a dimension objects can be grouped by
+func normalizeFacets(facets []string) ([]string, error) {
+	var normalized []string
+	seen := map[string]bool{}
+	for _, entry := range facets {
+		for _, facet := range strings.Split(entry, ",") {
+			facet = strings.TrimSpace(facet)
+			if facet == "" || seen[facet] {
+				continue
+			}
+			if err := repository.ValidateDimension(facet); err != nil {
+				return nil, err
+			}
+			seen[facet] = true
+			normalized = append(normalized, facet)
+		}
+	}
+	if len(normalized) > maxSearchFacets {
+		return nil, fmt.Errorf("at most %d facets are allowed: %w", maxSearchFacets, repository.ErrInvalidInput)
+	}
+	return normalized, nil
+}
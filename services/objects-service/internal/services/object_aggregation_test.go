@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+func TestObjectService_Aggregate_NormalizesSize(t *testing.T) {
+	var got *models.ObjectAggregationRequest
+	mockRepo := &mockObjectRepository{
+		aggregateFunc: func(ctx context.Context, req *models.ObjectAggregationRequest) (*models.ObjectAggregationResult, error) {
+			got = req
+			return &models.ObjectAggregationResult{}, nil
+		},
+	}
+	service := NewObjectService(mockRepo, &mockObjectTypeRepositoryForObjectService{})
+
+	_, err := service.Aggregate(context.Background(), &models.ObjectAggregationRequest{GroupBy: []string{"status"}})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultAggregationSize, got.Size)
+
+	_, err = service.Aggregate(context.Background(), &models.ObjectAggregationRequest{Size: 100000})
+	assert.NoError(t, err)
+	assert.Equal(t, maxAggregationSize, got.Size)
+}
+
+func TestObjectService_Aggregate_InvalidRequests(t *testing.T) {
+	service := NewObjectService(&mockObjectRepository{}, &mockObjectTypeRepositoryForObjectService{})
+
+	tests := []struct {
+		name string
+		req  *models.ObjectAggregationRequest
+	}{
+		{"nil request", nil},
+		{"negative size", &models.ObjectAggregationRequest{Size: -1}},
+		{"unknown status", &models.ObjectAggregationRequest{Filter: models.ObjectFilter{Status: "gone"}}},
+		{"too many dimensions", &models.ObjectAggregationRequest{GroupBy: []string{"status", "tag", "object_type", "metadata.region"}}},
+		{"too many metrics", &models.ObjectAggregationRequest{Metrics: make([]models.AggregationMetric, maxAggregationMetrics+1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Aggregate(context.Background(), tt.req)
+			assert.ErrorIs(t, err, repository.ErrInvalidInput)
+		})
+	}
+}
+
+func TestObjectService_Search_NormalizesFacets(t *testing.T) {
+	var got *models.ObjectSearchFilter
+	mockRepo := &mockObjectRepository{
+		searchFunc: func(ctx context.Context, filter *models.ObjectSearchFilter) (*models.ObjectSearchResults, error) {
+			got = filter
+			return &models.ObjectSearchResults{}, nil
+		},
+	}
+	service := NewObjectService(mockRepo, &mockObjectTypeRepositoryForObjectService{})
+
+	_, err := service.Search(context.Background(), &models.ObjectSearchFilter{
+		Query: "widget", Facets: []string{"status, tag", "status", "metadata.brand"}, FacetSize: 1000,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"status", "tag", "metadata.brand"}, got.Facets)
+	assert.Equal(t, maxSearchFacetSize, got.FacetSize)
+
+	_, err = service.Search(context.Background(), &models.ObjectSearchFilter{Query: "widget", Facets: []string{"name"}})
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+
+	_, err = service.Search(context.Background(), &models.ObjectSearchFilter{
+		Query: "widget", Facets: []string{"status,tag,object_type,metadata.a,metadata.b,metadata.c"},
+	})
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+}
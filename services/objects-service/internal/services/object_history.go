@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+var (
+	ErrObjectVersionNotFound = errors.New("object version not found")
+	ErrObjectDeleted         = errors.New("object is deleted")
+	ErrVersionNotRestorable  = errors.New("version cannot be restored")
+)
+
+const (
+	defaultVersionPageSize = 50
+	maxVersionPageSize     = 500
+)
+
+func normalizeHistoryEntity(entityType string) (string, error) {
+	switch entityType {
+	case "":
+		return models.HistoryEntityObject, nil
+	case models.HistoryEntityObject, models.HistoryEntityRelationship:
+		return entityType, nil
+	default:
+		return "", fmt.Errorf("entity_type must be %q or %q: %w",
+			models.HistoryEntityObject, models.HistoryEntityRelationship, repository.ErrInvalidInput)
+	}
+}
+
+// ListVersions returns the recorded history of an object or relationship, newest first
+func (s *objectService) ListVersions(ctx context.Context, id int64, filter *models.ObjectVersionFilter) ([]*models.ObjectVersion, int64, error) {
+	if id <= 0 {
+		return nil, 0, fmt.Errorf("invalid id: %w", repository.ErrInvalidInput)
+	}
+	if filter == nil {
+		filter = &models.ObjectVersionFilter{}
+	}
+
+	entityType, err := normalizeHistoryEntity(filter.EntityType)
+	if err != nil {
+		return nil, 0, err
+	}
+	if filter.Offset < 0 {
+		return nil, 0, fmt.Errorf("offset cannot be negative: %w", repository.ErrInvalidInput)
+	}
+
+	normalized := *filter
+	normalized.EntityType = entityType
+	if normalized.Limit <= 0 {
+		normalized.Limit = defaultVersionPageSize
+	}
+	if normalized.Limit > maxVersionPageSize {
+		normalized.Limit = maxVersionPageSize
+	}
+
+	return s.repo.ListVersions(ctx, id, &normalized)
+}
+
+// GetVersion returns one recorded revision, including its full snapshot
+func (s *objectService) GetVersion(ctx context.Context, entityType string, id, revision int64) (*models.ObjectVersion, error) {
+	if id <= 0 || revision <= 0 {
+		return nil, fmt.Errorf("id and revision must be positive: %w", repository.ErrInvalidInput)
+	}
+
+	entityType, err := normalizeHistoryEntity(entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.repo.GetVersion(ctx, entityType, id, revision)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%s %d revision %d: %w", entityType, id, revision, ErrObjectVersionNotFound)
+		}
+		return nil, err
+	}
+	return version, nil
+}
+
+// DiffVersions lists the fields that differ between two revisions
+func (s *objectService) DiffVersions(ctx context.Context, entityType string, id, fromRevision, toRevision int64) (*models.ObjectVersionDiff, error) {
+	from, err := s.GetVersion(ctx, entityType, id, fromRevision)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.GetVersion(ctx, entityType, id, toRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := models.DiffSnapshots(from.Snapshot, to.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ObjectVersionDiff{
+		ObjectID:     id,
+		FromRevision: fromRevision,
+		ToRevision:   toRevision,
+		Changes:      changes,
+	}, nil
+}
+
+// GetAsOf returns the object as it was at the given time
+func (s *objectService) GetAsOf(ctx context.Context, id int64, at time.Time) (*models.Object, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid id: %w", repository.ErrInvalidInput)
+	}
+
+	version, err := s.repo.GetVersionAsOf(ctx, models.HistoryEntityObject, id, at)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("object %d has no version at %s: %w", id, at.Format(time.RFC3339), ErrObjectVersionNotFound)
+		}
+		return nil, err
+	}
+	if version.Action == models.HistoryActionDelete || version.Action == models.HistoryActionPurge {
+		return nil, fmt.Errorf("object %d was deleted at %s: %w", id, at.Format(time.RFC3339), ErrObjectVersionNotFound)
+	}
+
+	return version.ToObject()
+}
+
+// RestoreVersion writes the fields of an earlier revision back as a new version of the object.
+// The restore goes through Update, so metadata schemas and parent rules apply as for any edit.
+// Fields that the update API cannot clear, such as a parent the object did not have at that
+// revision, are left as they are.
+func (s *objectService) RestoreVersion(ctx context.Context, id, revision int64, updatedBy string) (*models.Object, error) {
+	version, err := s.GetVersion(ctx, models.HistoryEntityObject, id, revision)
+	if err != nil {
+		return nil, err
+	}
+	if version.Action == models.HistoryActionDelete || version.Action == models.HistoryActionPurge {
+		return nil, fmt.Errorf("revision %d records a delete: %w", revision, ErrVersionNotRestorable)
+	}
+
+	snapshot, err := version.ToObject()
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.DeletedAt != nil {
+		return nil, fmt.Errorf("revision %d is a deleted state: %w", revision, ErrVersionNotRestorable)
+	}
+
+	current, err := s.repo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("object not found: %w", err)
+	}
+	if current.DeletedAt != nil {
+		return nil, fmt.Errorf("object %d: %w", id, ErrObjectDeleted)
+	}
+
+	metadata := snapshot.GetMetadataMap()
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	tags := snapshot.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	description := ""
+	if snapshot.Description != nil {
+		description = *snapshot.Description
+	}
+
+	req := &models.UpdateObjectRequest{
+		ObjectTypeID: &snapshot.ObjectTypeID,
+		Name:         &snapshot.Name,
+		Metadata:     &metadata,
+		Tags:         &tags,
+		Status:       &snapshot.Status,
+		Version:      &current.Version,
+		UpdatedBy:    updatedBy,
+	}
+	if snapshot.Description != nil || current.Description != nil {
+		req.Description = &description
+	}
+	if snapshot.ParentObjectID != nil {
+		req.ParentObjectID = snapshot.ParentObjectID
+	}
+
+	change := repository.ChangeContextFrom(ctx)
+	change.Action = models.HistoryActionRestore
+	return s.Update(repository.WithChangeContext(ctx, change), id, req)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// widgetHistory holds three revisions of object 7: created, renamed and retagged, then deleted
+func widgetHistory() map[int64]*models.ObjectVersion {
+	return map[int64]*models.ObjectVersion{
+		1: {EntityType: models.HistoryEntityObject, ObjectID: 7, Revision: 1, Action: models.HistoryActionCreate,
+			Snapshot: json.RawMessage(`{"id":7,"name":"Widget","object_type_id":1,"parent_object_id":3,"metadata":{"number":"W-1"},"tags":["a"],"status":"active","version":1}`)},
+		2: {EntityType: models.HistoryEntityObject, ObjectID: 7, Revision: 2, Action: models.HistoryActionUpdate,
+			Snapshot: json.RawMessage(`{"id":7,"name":"Gadget","object_type_id":1,"parent_object_id":3,"metadata":{"number":"W-1"},"tags":["a","b"],"status":"active","version":2}`)},
+		3: {EntityType: models.HistoryEntityObject, ObjectID: 7, Revision: 3, Action: models.HistoryActionDelete,
+			Snapshot: json.RawMessage(`{"id":7,"name":"Gadget","object_type_id":1,"status":"deleted","version":2,"deleted_at":"2024-01-03T00:00:00Z"}`)},
+	}
+}
+
+func historyRepo(current *models.Object, history map[int64]*models.ObjectVersion) *mockObjectRepository {
+	return &mockObjectRepository{
+		getByIDFunc: func(ctx context.Context, id int64) (*models.Object, error) {
+			if id == current.ID {
+				return current, nil
+			}
+			return &models.Object{ID: id, ObjectTypeID: 1}, nil
+		},
+		getVersionFunc: func(ctx context.Context, entityType string, objectID, revision int64) (*models.ObjectVersion, error) {
+			if version, ok := history[revision]; ok && entityType == version.EntityType {
+				return version, nil
+			}
+			return nil, repository.ErrNotFound
+		},
+	}
+}
+
+func TestObjectService_ListVersions_Defaults(t *testing.T) {
+	var received *models.ObjectVersionFilter
+	mockRepo := &mockObjectRepository{
+		listVersionsFunc: func(ctx context.Context, objectID int64, filter *models.ObjectVersionFilter) ([]*models.ObjectVersion, int64, error) {
+			received = filter
+			return []*models.ObjectVersion{}, 0, nil
+		},
+	}
+	service := NewObjectService(mockRepo, &mockObjectTypeRepositoryForObjectService{})
+
+	_, _, err := service.ListVersions(context.Background(), 7, &models.ObjectVersionFilter{Limit: 10000})
+	require.NoError(t, err)
+	assert.Equal(t, models.HistoryEntityObject, received.EntityType)
+	assert.Equal(t, maxVersionPageSize, received.Limit)
+
+	_, _, err = service.ListVersions(context.Background(), 7, &models.ObjectVersionFilter{EntityType: "object_type"})
+	assert.True(t, errors.Is(err, repository.ErrInvalidInput))
+}
+
+func TestObjectService_GetVersion_NotFound(t *testing.T) {
+	service := NewObjectService(historyRepo(&models.Object{ID: 7}, widgetHistory()), &mockObjectTypeRepositoryForObjectService{})
+
+	_, err := service.GetVersion(context.Background(), "", 7, 9)
+	assert.True(t, errors.Is(err, ErrObjectVersionNotFound))
+
+	_, err = service.GetVersion(context.Background(), models.HistoryEntityRelationship, 7, 1)
+	assert.True(t, errors.Is(err, ErrObjectVersionNotFound))
+}
+
+func TestObjectService_DiffVersions(t *testing.T) {
+	service := NewObjectService(historyRepo(&models.Object{ID: 7}, widgetHistory()), &mockObjectTypeRepositoryForObjectService{})
+
+	diff, err := service.DiffVersions(context.Background(), models.HistoryEntityObject, 7, 1, 2)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), diff.FromRevision)
+	assert.Equal(t, int64(2), diff.ToRevision)
+	assert.Len(t, diff.Changes, 2)
+	assert.JSONEq(t, `"Widget"`, string(diff.Changes["name"].Old))
+	assert.JSONEq(t, `["a","b"]`, string(diff.Changes["tags"].New))
+}
+
+func TestObjectService_GetAsOf(t *testing.T) {
+	history := widgetHistory()
+	var asOf time.Time
+	mockRepo := &mockObjectRepository{
+		getVersionAsOfFunc: func(ctx context.Context, entityType string, objectID int64, at time.Time) (*models.ObjectVersion, error) {
+			asOf = at
+			if at.Year() < 2024 {
+				return nil, repository.ErrNotFound
+			}
+			if at.Month() == time.March {
+				return history[3], nil
+			}
+			return history[2], nil
+		},
+	}
+	service := NewObjectService(mockRepo, &mockObjectTypeRepositoryForObjectService{})
+
+	at := time.Date(2024, time.January, 2, 12, 0, 0, 0, time.UTC)
+	object, err := service.GetAsOf(context.Background(), 7, at)
+	require.NoError(t, err)
+	assert.Equal(t, "Gadget", object.Name)
+	assert.Equal(t, at, asOf)
+
+	_, err = service.GetAsOf(context.Background(), 7, time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, errors.Is(err, ErrObjectVersionNotFound))
+
+	_, err = service.GetAsOf(context.Background(), 7, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC))
+	assert.True(t, errors.Is(err, ErrObjectVersionNotFound), "deleted state is not returned")
+}
+
+func TestObjectService_RestoreVersion(t *testing.T) {
+	description := "later"
+	current := &models.Object{ID: 7, ObjectTypeID: 1, Name: "Gadget", Version: 5, Description: &description}
+	mockRepo := historyRepo(current, widgetHistory())
+
+	var restoreReq *models.UpdateObjectRequest
+	var change repository.ChangeContext
+	mockRepo.updateFunc = func(ctx context.Context, id int64, input *models.UpdateObjectRequest) (*models.Object, error) {
+		restoreReq = input
+		change = repository.ChangeContextFrom(ctx)
+		return &models.Object{ID: id, Name: *input.Name, Version: *input.Version + 1}, nil
+	}
+	service := NewObjectService(mockRepo, &mockObjectTypeRepositoryForObjectService{})
+
+	ctx := repository.WithChangeContext(context.Background(), repository.ChangeContext{RequestID: "req-9", Actor: "user-1"})
+	object, err := service.RestoreVersion(ctx, 7, 1, "user-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Widget", object.Name)
+	assert.Equal(t, int64(5), *restoreReq.Version, "restore is checked against the current version")
+	assert.Equal(t, []string{"a"}, *restoreReq.Tags)
+	assert.Equal(t, "", *restoreReq.Description, "description the revision did not have is cleared")
+	assert.Equal(t, int64(3), *restoreReq.ParentObjectID)
+	assert.Equal(t, "user-1", restoreReq.UpdatedBy)
+	assert.Equal(t, repository.ChangeContext{RequestID: "req-9", Actor: "user-1", Action: models.HistoryActionRestore}, change)
+}
+
+func TestObjectService_RestoreVersion_Rejected(t *testing.T) {
+	deletedAt := time.Now()
+
+	t.Run("delete revision", func(t *testing.T) {
+		service := NewObjectService(historyRepo(&models.Object{ID: 7, Version: 5}, widgetHistory()), &mockObjectTypeRepositoryForObjectService{})
+		_, err := service.RestoreVersion(context.Background(), 7, 3, "user-1")
+		assert.True(t, errors.Is(err, ErrVersionNotRestorable))
+	})
+
+	t.Run("deleted object", func(t *testing.T) {
+		service := NewObjectService(historyRepo(&models.Object{ID: 7, Version: 5, DeletedAt: &deletedAt}, widgetHistory()), &mockObjectTypeRepositoryForObjectService{})
+		_, err := service.RestoreVersion(context.Background(), 7, 1, "user-1")
+		assert.True(t, errors.Is(err, ErrObjectDeleted))
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		service := NewObjectService(historyRepo(&models.Object{ID: 7, Version: 5}, widgetHistory()), &mockObjectTypeRepositoryForObjectService{})
+		_, err := service.RestoreVersion(context.Background(), 7, 42, "user-1")
+		assert.True(t, errors.Is(err, ErrObjectVersionNotFound))
+	})
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// withMoveContext marks the writes made through ctx as a move, so history and events record them
+// as one
+func withMoveContext(ctx context.Context) context.Context {
+	change := repository.ChangeContextFrom(ctx)
+	change.Action = models.HistoryActionMove
+	return repository.WithChangeContext(ctx, change)
+}
+
+// Move moves an object under another parent, or to the root, in one transaction. In subtree mode
+// (the default) its descendants move along; in single mode its children stay in place, under
+// the object's former parent.
+func (s *objectService) Move(ctx context.Context, id int64, req *models.MoveObjectRequest) (*models.MoveObjectResult, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid id: %w", repository.ErrInvalidInput)
+	}
+	if req.ParentObjectID != nil && *req.ParentObjectID <= 0 {
+		return nil, fmt.Errorf("invalid parent object id: %w", repository.ErrInvalidInput)
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = models.MoveModeSubtree
+	}
+	if mode != models.MoveModeSubtree && mode != models.MoveModeSingle {
+		return nil, fmt.Errorf("mode must be %s or %s: %w", models.MoveModeSubtree, models.MoveModeSingle, repository.ErrInvalidInput)
+	}
+	if req.RetypeDescendants && mode != models.MoveModeSubtree {
+		return nil, fmt.Errorf("retype_descendants requires subtree mode: %w", repository.ErrInvalidInput)
+	}
+
+	ctx = withMoveContext(ctx)
+	var result *models.MoveObjectResult
+	err := s.withinTx(ctx, func(tx *objectService) error {
+		var err error
+		result, err = tx.move(ctx, id, req, mode)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *objectService) move(ctx context.Context, id int64, req *models.MoveObjectRequest, mode string) (*models.MoveObjectResult, error) {
+	existing, err := s.repo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("object not found: %w", err)
+	}
+	if existing.DeletedAt != nil {
+		return nil, fmt.Errorf("object %d: %w", id, ErrObjectDeleted)
+	}
+	if req.Version != nil && *req.Version != existing.Version {
+		return nil, repository.ErrOptimisticLock
+	}
+
+	typeID := existing.ObjectTypeID
+	if req.ObjectTypeID != nil {
+		typeID = *req.ObjectTypeID
+	}
+	objectType, err := s.objectTypeRepo.GetByID(ctx, typeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object type: %w", err)
+	}
+	if objectType.IsSealed {
+		return nil, fmt.Errorf("cannot move objects of sealed type: %w", repository.ErrInvalidInput)
+	}
+
+	// Descendants moving along; GetDescendants lists the object itself first
+	var descendants []*models.Object
+	if mode == models.MoveModeSubtree {
+		subtree, err := s.repo.GetDescendants(ctx, id, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get descendants: %w", err)
+		}
+		for _, object := range subtree {
+			if object.ID != id {
+				descendants = append(descendants, object)
+			}
+		}
+	}
+
+	if req.ParentObjectID != nil {
+		parentID := *req.ParentObjectID
+		if parentID == id {
+			return nil, fmt.Errorf("object cannot be its own parent: %w", repository.ErrInvalidInput)
+		}
+		for _, object := range descendants {
+			if object.ID == parentID {
+				return nil, fmt.Errorf("circular dependency: cannot move an object under its own descendant: %w", repository.ErrInvalidInput)
+			}
+		}
+
+		parent, err := s.repo.GetByIDWithDeleted(ctx, parentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent object: %w", err)
+		}
+		if parent.DeletedAt != nil {
+			return nil, fmt.Errorf("parent object is deleted: %w", repository.ErrInvalidInput)
+		}
+		if parent.ObjectTypeID != typeID {
+			return nil, fmt.Errorf("parent object must be of same type: %w", repository.ErrInvalidInput)
+		}
+	}
+
+	// Descendants keep their parents, so they must take the object's new type as well
+	var retyped []*models.Object
+	if typeID != existing.ObjectTypeID {
+		for _, object := range descendants {
+			if object.ObjectTypeID != typeID {
+				retyped = append(retyped, object)
+			}
+		}
+		if len(retyped) > 0 && !req.RetypeDescendants {
+			return nil, fmt.Errorf("descendants of the object are of another type; set retype_descendants to give them the new type: %w", repository.ErrInvalidInput)
+		}
+
+		schemas := newMetadataSchemaCache(s.objectTypeRepo)
+		for _, object := range append([]*models.Object{existing}, retyped...) {
+			if err := schemas.validate(ctx, typeID, object.GetMetadataMap()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := &models.MoveObjectResult{Retyped: []int64{}, Reparented: []int64{}}
+
+	// In single mode the children stay where they are, under the object's former parent
+	if mode == models.MoveModeSingle {
+		children, err := s.repo.GetChildren(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get children: %w", err)
+		}
+		for _, child := range children {
+			if _, err := s.moveOne(ctx, child, existing.ParentObjectID, child.ObjectTypeID, req.UpdatedBy); err != nil {
+				return nil, err
+			}
+			result.Reparented = append(result.Reparented, child.ID)
+		}
+	}
+
+	if result.Object, err = s.moveOne(ctx, existing, req.ParentObjectID, typeID, req.UpdatedBy); err != nil {
+		return nil, err
+	}
+
+	for _, object := range retyped {
+		if _, err := s.moveOne(ctx, object, object.ParentObjectID, typeID, req.UpdatedBy); err != nil {
+			return nil, err
+		}
+		result.Retyped = append(result.Retyped, object.ID)
+	}
+	return result, nil
+}
+
+// moveOne sets the parent and type of one object and records the event of the change
+func (s *objectService) moveOne(ctx context.Context, before *models.Object, parentID *int64, typeID int64, updatedBy string) (*models.Object, error) {
+	after, err := s.repo.Move(ctx, before.ID, parentID, typeID, updatedBy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordEvent(ctx, models.EventActionUpdated, before, after); err != nil {
+		return nil, err
+	}
+	return after, nil
+}
+
+// Move moves an object type under another parent, or to the root, in one transaction. The
+// parent must not be sealed or in the type's subtree, and the metadata schemas of the subtree
+// must still merge with the ones inherited from the new ancestors.
+func (s *objectTypeService) Move(ctx context.Context, id int64, req *models.MoveObjectTypeRequest) (*models.ObjectType, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid id: %w", repository.ErrInvalidInput)
+	}
+	if req.ParentTypeID != nil && *req.ParentTypeID <= 0 {
+		return nil, fmt.Errorf("invalid new parent id: %w", repository.ErrInvalidInput)
+	}
+
+	ctx = withMoveContext(ctx)
+	var moved *models.ObjectType
+	err := s.withinTx(ctx, func(tx *objectTypeService) error {
+		var err error
+		moved, err = tx.move(ctx, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
+
+func (s *objectTypeService) move(ctx context.Context, id int64, req *models.MoveObjectTypeRequest) (*models.ObjectType, error) {
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("object type not found: %w", err)
+	}
+
+	if req.ParentTypeID != nil {
+		parent, err := s.repo.GetByID(ctx, *req.ParentTypeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent type: %w", err)
+		}
+		if parent.IsSealed {
+			return nil, fmt.Errorf("cannot move an object type under sealed type %q: %w", parent.Name, repository.ErrInvalidInput)
+		}
+		if err := s.repo.ValidateMove(ctx, id, req.ParentTypeID); err != nil {
+			return nil, fmt.Errorf("%v: %w", err, repository.ErrInvalidInput)
+		}
+	}
+
+	moved, err := s.repo.Move(ctx, id, req.ParentTypeID, req.UpdatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Schemas of the subtree now merge with those of the new ancestors; within the transaction
+	// the move is undone when one of them no longer compiles
+	subtree, err := s.repo.GetDescendants(ctx, id, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get descendants: %w", err)
+	}
+	for _, objectType := range subtree {
+		if models.IsEmptyMetadataSchema(objectType.MetadataSchema) {
+			continue
+		}
+		schema, err := effectiveMetadataSchema(ctx, s.repo, objectType.ID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := compileMetadataSchema(schema); err != nil {
+			return nil, fmt.Errorf("object type %q: %w", objectType.Name, err)
+		}
+	}
+
+	if err := s.recordEvent(ctx, models.EventActionUpdated, existing, moved); err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// memoryObjects is an in-memory ObjectRepository holding an object tree for move tests
+type memoryObjects struct {
+	repository.ObjectRepository
+	objects map[int64]*models.Object
+	nextID  int64
+}
+
+func newMemoryObjects() *memoryObjects {
+	return &memoryObjects{objects: map[int64]*models.Object{}}
+}
+
+// add stores an object of a type under a parent object, nil for a root
+func (r *memoryObjects) add(name string, typeID int64, parent *models.Object) *models.Object {
+	r.nextID++
+	object := &models.Object{ID: r.nextID, PublicID: uuid.New(), Name: name, ObjectTypeID: typeID, Version: 1}
+	if parent != nil {
+		object.ParentObjectID = &parent.ID
+	}
+	r.objects[object.ID] = object
+	return object
+}
+
+func (r *memoryObjects) GetByID(ctx context.Context, id int64) (*models.Object, error) {
+	object, err := r.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if object.DeletedAt != nil {
+		return nil, repository.ErrNotFound
+	}
+	return object, nil
+}
+
+func (r *memoryObjects) GetByIDWithDeleted(ctx context.Context, id int64) (*models.Object, error) {
+	object, ok := r.objects[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *object
+	return &copied, nil
+}
+
+func (r *memoryObjects) GetChildren(ctx context.Context, parentID int64) ([]*models.Object, error) {
+	children := []*models.Object{}
+	for _, object := range r.objects {
+		if object.ParentObjectID != nil && *object.ParentObjectID == parentID {
+			copied := *object
+			children = append(children, &copied)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].ID < children[j].ID })
+	return children, nil
+}
+
+func (r *memoryObjects) GetDescendants(ctx context.Context, rootID int64, maxDepth *int) ([]*models.Object, error) {
+	root, err := r.GetByID(ctx, rootID)
+	if err != nil {
+		return nil, err
+	}
+	descendants := []*models.Object{root}
+	for i := 0; i < len(descendants); i++ {
+		children, _ := r.GetChildren(ctx, descendants[i].ID)
+		descendants = append(descendants, children...)
+	}
+	return descendants, nil
+}
+
+func (r *memoryObjects) Move(ctx context.Context, id int64, parentID *int64, objectTypeID int64, updatedBy string) (*models.Object, error) {
+	object, ok := r.objects[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	object.ParentObjectID = parentID
+	object.ObjectTypeID = objectTypeID
+	object.UpdatedBy = updatedBy
+	object.Version++
+	return r.GetByID(ctx, id)
+}
+
+func (r *memoryObjectTypes) GetDescendants(ctx context.Context, rootID int64, maxDepth *int) ([]*models.ObjectType, error) {
+	descendants := []*models.ObjectType{r.types[rootID]}
+	for i := 0; i < len(descendants); i++ {
+		for _, objectType := range r.types {
+			if objectType.ParentTypeID != nil && *objectType.ParentTypeID == descendants[i].ID {
+				descendants = append(descendants, objectType)
+			}
+		}
+	}
+	return descendants, nil
+}
+
+func (r *memoryObjectTypes) Move(ctx context.Context, id int64, newParentID *int64, updatedBy string) (*models.ObjectType, error) {
+	objectType, ok := r.types[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	objectType.ParentTypeID = newParentID
+	return r.GetByID(ctx, id)
+}
+
+// moveFixture is a folder tree root > {a > a1, b} of type Folder, and a Binder type
+type moveFixture struct {
+	types   *memoryObjectTypes
+	objects *memoryObjects
+	db      *memoryTxDB
+	service ObjectService
+	folder  *models.ObjectType
+	binder  *models.ObjectType
+	root    *models.Object
+	a       *models.Object
+	a1      *models.Object
+	b       *models.Object
+}
+
+func newMoveFixture() *moveFixture {
+	f := &moveFixture{types: newMemoryObjectTypes(), objects: newMemoryObjects()}
+	f.folder = f.types.add("Folder", "", false)
+	f.binder = f.types.add("Binder", "", false)
+	f.root = f.objects.add("root", f.folder.ID, nil)
+	f.a = f.objects.add("a", f.folder.ID, f.root)
+	f.a1 = f.objects.add("a1", f.folder.ID, f.a)
+	f.b = f.objects.add("b", f.folder.ID, f.root)
+	f.db = newMemoryTxDB(f.objects, f.types)
+	f.service = NewObjectServiceWithTx(f.objects, f.types, f.db)
+	return f
+}
+
+func (f *moveFixture) parentOf(object *models.Object) *int64 {
+	return f.objects.objects[object.ID].ParentObjectID
+}
+
+func TestObjectService_MoveSubtree(t *testing.T) {
+	f := newMoveFixture()
+
+	result, err := f.service.Move(context.Background(), f.a.ID, &models.MoveObjectRequest{ParentObjectID: &f.b.ID})
+
+	require.NoError(t, err)
+	assert.Equal(t, f.b.ID, *result.Object.ParentObjectID)
+	assert.Equal(t, int64(2), result.Object.Version)
+	assert.Equal(t, f.a.ID, *f.parentOf(f.a1))
+	assert.Empty(t, result.Reparented)
+	assert.Equal(t, []string{"object.moved"}, f.db.outbox.eventTypes())
+}
+
+func TestObjectService_MoveToRoot(t *testing.T) {
+	f := newMoveFixture()
+
+	result, err := f.service.Move(context.Background(), f.a.ID, &models.MoveObjectRequest{})
+
+	require.NoError(t, err)
+	assert.Nil(t, result.Object.ParentObjectID)
+}
+
+func TestObjectService_MoveSingleHandsChildrenToFormerParent(t *testing.T) {
+	f := newMoveFixture()
+
+	result, err := f.service.Move(context.Background(), f.a.ID, &models.MoveObjectRequest{ParentObjectID: &f.b.ID, Mode: models.MoveModeSingle})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{f.a1.ID}, result.Reparented)
+	assert.Equal(t, f.root.ID, *f.parentOf(f.a1))
+	assert.Equal(t, f.b.ID, *f.parentOf(f.a))
+	assert.Equal(t, []string{"object.moved", "object.moved"}, f.db.outbox.eventTypes())
+}
+
+func TestObjectService_MoveSingleAllowsMoveUnderFormerChild(t *testing.T) {
+	f := newMoveFixture()
+
+	_, err := f.service.Move(context.Background(), f.a.ID, &models.MoveObjectRequest{ParentObjectID: &f.a1.ID, Mode: models.MoveModeSingle})
+
+	require.NoError(t, err)
+	assert.Equal(t, f.root.ID, *f.parentOf(f.a1))
+	assert.Equal(t, f.a1.ID, *f.parentOf(f.a))
+}
+
+func TestObjectService_MoveRetypesDescendants(t *testing.T) {
+	f := newMoveFixture()
+	ctx := context.Background()
+	req := &models.MoveObjectRequest{ObjectTypeID: &f.binder.ID}
+
+	_, err := f.service.Move(ctx, f.a.ID, req)
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+	assert.Equal(t, f.folder.ID, f.objects.objects[f.a.ID].ObjectTypeID)
+
+	req.RetypeDescendants = true
+	result, err := f.service.Move(ctx, f.a.ID, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{f.a1.ID}, result.Retyped)
+	assert.Equal(t, f.binder.ID, f.objects.objects[f.a.ID].ObjectTypeID)
+	assert.Equal(t, f.binder.ID, f.objects.objects[f.a1.ID].ObjectTypeID)
+	assert.Equal(t, f.a.ID, *f.parentOf(f.a1))
+}
+
+func TestObjectService_MoveRejectsInvalidMoves(t *testing.T) {
+	f := newMoveFixture()
+	other := f.objects.add("other", f.binder.ID, nil)
+	deleted := f.objects.add("deleted", f.folder.ID, nil)
+	deletedAt := time.Now()
+	deleted.DeletedAt = &deletedAt
+	sealed := f.types.add("Sealed", "", true)
+	missing := int64(999)
+	stale := int64(7)
+
+	tests := []struct {
+		name string
+		id   int64
+		req  *models.MoveObjectRequest
+		err  error
+	}{
+		{"own parent", f.a.ID, &models.MoveObjectRequest{ParentObjectID: &f.a.ID}, repository.ErrInvalidInput},
+		{"under descendant", f.a.ID, &models.MoveObjectRequest{ParentObjectID: &f.a1.ID}, repository.ErrInvalidInput},
+		{"parent of another type", f.a.ID, &models.MoveObjectRequest{ParentObjectID: &other.ID}, repository.ErrInvalidInput},
+		{"deleted parent", f.a.ID, &models.MoveObjectRequest{ParentObjectID: &deleted.ID}, repository.ErrInvalidInput},
+		{"missing parent", f.a.ID, &models.MoveObjectRequest{ParentObjectID: &missing}, repository.ErrNotFound},
+		{"sealed type", f.a.ID, &models.MoveObjectRequest{ObjectTypeID: &sealed.ID, RetypeDescendants: true}, repository.ErrInvalidInput},
+		{"unknown mode", f.a.ID, &models.MoveObjectRequest{Mode: "sideways"}, repository.ErrInvalidInput},
+		{"retype in single mode", f.a.ID, &models.MoveObjectRequest{Mode: models.MoveModeSingle, RetypeDescendants: true}, repository.ErrInvalidInput},
+		{"stale version", f.a.ID, &models.MoveObjectRequest{Version: &stale}, repository.ErrOptimisticLock},
+		{"deleted object", deleted.ID, &models.MoveObjectRequest{}, ErrObjectDeleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.Move(context.Background(), tt.id, tt.req)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	assert.Equal(t, f.root.ID, *f.parentOf(f.a))
+	assert.Empty(t, f.db.outbox.committed)
+}
+
+func newTypeMoveFixture() (*memoryObjectTypes, *memoryTxDB, ObjectTypeService) {
+	types, _ := newTaxonomyFixture()
+	db := newMemoryTxDB(nil, types)
+	return types, db, NewObjectTypeServiceWithTx(types, db)
+}
+
+func TestObjectTypeService_Move(t *testing.T) {
+	types, db, service := newTypeMoveFixture()
+	ebook, tag := types.byName("EBook"), types.byName("Tag")
+
+	moved, err := service.Move(context.Background(), ebook.ID, &models.MoveObjectTypeRequest{ParentTypeID: &tag.ID})
+
+	require.NoError(t, err)
+	assert.Equal(t, tag.ID, *moved.ParentTypeID)
+	assert.Equal(t, "Tag", types.parentName("EBook"))
+	assert.Equal(t, []string{"object_type.moved"}, db.outbox.eventTypes())
+}
+
+func TestObjectTypeService_MoveRejectsInvalidMoves(t *testing.T) {
+	types, db, service := newTypeMoveFixture()
+	product, ebook := types.byName("Product"), types.byName("EBook")
+	sealed := types.add("Sealed", "", true)
+	missing := int64(999)
+
+	tests := []struct {
+		name   string
+		id     int64
+		parent *int64
+		err    error
+	}{
+		{"under descendant", product.ID, &ebook.ID, repository.ErrInvalidInput},
+		{"under itself", product.ID, &product.ID, repository.ErrInvalidInput},
+		{"sealed parent", ebook.ID, &sealed.ID, repository.ErrInvalidInput},
+		{"missing parent", ebook.ID, &missing, repository.ErrNotFound},
+		{"missing type", missing, nil, repository.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Move(context.Background(), tt.id, &models.MoveObjectTypeRequest{ParentTypeID: tt.parent})
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	assert.Equal(t, "Book", types.parentName("EBook"))
+	assert.Empty(t, db.outbox.committed)
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+const (
+	defaultSearchPageSize = 50
+	maxSearchPageSize     = 200
+
+	maxSearchMetadataKeys = 32
+)
+
+// Search ranks objects against a full-text query, falling back to fuzzy name matching when
+// the query matches nothing, and counts all hits by the requested facets
+func (s *objectService) Search(ctx context.Context, filter *models.ObjectSearchFilter) (*models.ObjectSearchResults, error) {
+	if filter == nil || strings.TrimSpace(filter.Query) == "" {
+		return nil, fmt.Errorf("search query is required: %w", repository.ErrInvalidInput)
+	}
+	if filter.Offset < 0 {
+		return nil, fmt.Errorf("offset cannot be negative: %w", repository.ErrInvalidInput)
+	}
+	if filter.Status != "" && !models.ValidStatuses[filter.Status] {
+		return nil, fmt.Errorf("invalid status %q: %w", filter.Status, repository.ErrInvalidInput)
+	}
+	if filter.ObjectTypeID != nil {
+		if _, err := s.objectTypeRepo.GetByID(ctx, *filter.ObjectTypeID); err != nil {
+			return nil, fmt.Errorf("invalid object type %d: %w", *filter.ObjectTypeID, repository.ErrInvalidInput)
+		}
+	}
+
+	normalized := *filter
+	normalized.Query = strings.TrimSpace(filter.Query)
+	if normalized.Limit <= 0 {
+		normalized.Limit = defaultSearchPageSize
+	}
+	if normalized.Limit > maxSearchPageSize {
+		normalized.Limit = maxSearchPageSize
+	}
+
+	facets, err := normalizeFacets(filter.Facets)
+	if err != nil {
+		return nil, err
+	}
+	normalized.Facets = facets
+	if normalized.FacetSize <= 0 {
+		normalized.FacetSize = defaultSearchFacetSize
+	}
+	if normalized.FacetSize > maxSearchFacetSize {
+		normalized.FacetSize = maxSearchFacetSize
+	}
+
+	return s.repo.Search(ctx, &normalized)
+}
+
+// checkSearchMetadataKeys validates the metadata keys an object type indexes for search
+func checkSearchMetadataKeys(keys []string) error {
+	if len(keys) > maxSearchMetadataKeys {
+		return fmt.Errorf("at most %d search metadata keys are allowed: %w", maxSearchMetadataKeys, repository.ErrInvalidInput)
+	}
+
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("search metadata keys cannot be empty: %w", repository.ErrInvalidInput)
+		}
+		if seen[key] {
+			return fmt.Errorf("duplicate search metadata key %q: %w", key, repository.ErrInvalidInput)
+		}
+		seen[key] = true
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+func TestObjectService_Search_Normalizes(t *testing.T) {
+	var got *models.ObjectSearchFilter
+	mockRepo := &mockObjectRepository{
+		searchFunc: func(ctx context.Context, filter *models.ObjectSearchFilter) (*models.ObjectSearchResults, error) {
+			got = filter
+			return &models.ObjectSearchResults{MatchType: models.SearchMatchFullText}, nil
+		},
+	}
+	service := NewObjectService(mockRepo, &mockObjectTypeRepositoryForObjectService{})
+
+	_, err := service.Search(context.Background(), &models.ObjectSearchFilter{Query: "  red widget ", Limit: 10000})
+	assert.NoError(t, err)
+	assert.Equal(t, "red widget", got.Query)
+	assert.Equal(t, maxSearchPageSize, got.Limit)
+
+	_, err = service.Search(context.Background(), &models.ObjectSearchFilter{Query: "widget"})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultSearchPageSize, got.Limit)
+}
+
+func TestObjectService_Search_InvalidFilters(t *testing.T) {
+	mockTypeRepo := &mockObjectTypeRepositoryForObjectService{
+		getByIDFunc: func(ctx context.Context, id int64) (*models.ObjectType, error) {
+			return nil, repository.ErrNotFound
+		},
+	}
+	service := NewObjectService(&mockObjectRepository{}, mockTypeRepo)
+	unknownType := int64(99)
+
+	tests := []struct {
+		name   string
+		filter *models.ObjectSearchFilter
+	}{
+		{"nil filter", nil},
+		{"negative offset", &models.ObjectSearchFilter{Query: "widget", Offset: -1}},
+		{"unknown status", &models.ObjectSearchFilter{Query: "widget", Status: "gone"}},
+		{"unknown type", &models.ObjectSearchFilter{Query: "widget", ObjectTypeID: &unknownType}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Search(context.Background(), tt.filter)
+			assert.ErrorIs(t, err, repository.ErrInvalidInput)
+		})
+	}
+}
+
+func TestCheckSearchMetadataKeys(t *testing.T) {
+	assert.NoError(t, checkSearchMetadataKeys(nil))
+	assert.NoError(t, checkSearchMetadataKeys([]string{"sku", "brand"}))
+	assert.ErrorIs(t, checkSearchMetadataKeys([]string{"sku", "sku"}), repository.ErrInvalidInput)
+	assert.ErrorIs(t, checkSearchMetadataKeys([]string{" "}), repository.ErrInvalidInput)
+	assert.ErrorIs(t, checkSearchMetadataKeys(make([]string, maxSearchMetadataKeys+1)), repository.ErrInvalidInput)
+}
+
+func TestObjectTypeService_Update_RejectsDuplicateSearchKeys(t *testing.T) {
+	mockRepo := &mockObjectTypeRepository{
+		getByIDFunc: func(ctx context.Context, id int64) (*models.ObjectType, error) {
+			return &models.ObjectType{ID: id, Name: "product"}, nil
+		},
+	}
+	service := NewObjectTypeService(mockRepo)
+
+	keys := []string{"sku", "sku"}
+	_, err := service.Update(context.Background(), 1, &models.UpdateObjectTypeRequest{SearchMetadataKeys: &keys})
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+}
@@ -2,10 +2,14 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/filterexpr"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
 )
@@ -18,7 +22,7 @@ type ObjectService interface {
 	Update(ctx context.Context, id int64, req *models.UpdateObjectRequest) (*models.Object, error)
 	Delete(ctx context.Context, id int64) error
 	List(ctx context.Context, filter *models.ObjectFilter) ([]*models.Object, int64, error)
-	Search(ctx context.Context, query string, limit int) ([]*models.Object, error)
+	Search(ctx context.Context, filter *models.ObjectSearchFilter) (*models.ObjectSearchResults, error)
 	FindByMetadata(ctx context.Context, key, value string) ([]*models.Object, error)
 	FindByTags(ctx context.Context, tags []string, matchAll bool) ([]*models.Object, error)
 	UpdateMetadata(ctx context.Context, id int64, metadata map[string]interface{}, updatedBy string) error
@@ -28,26 +32,83 @@ type ObjectService interface {
 	GetDescendants(ctx context.Context, rootID int64, maxDepth *int) ([]*models.Object, error)
 	GetAncestors(ctx context.Context, id int64) ([]*models.Object, error)
 	GetPath(ctx context.Context, id int64) ([]*models.Object, error)
+	Move(ctx context.Context, id int64, req *models.MoveObjectRequest) (*models.MoveObjectResult, error)
 	BulkCreate(ctx context.Context, objects []*models.CreateObjectRequest) ([]*models.Object, error)
-	BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest) ([]*models.Object, error)
+	BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest, expectedVersions map[int64]int64) (*models.BulkUpdateResult, error)
 	BulkDelete(ctx context.Context, ids []int64) error
 	ValidateParentChild(ctx context.Context, parentID, childID int64) error
 	GetObjectStats(ctx context.Context, filter *models.ObjectFilter) (*repository.ObjectStats, error)
+	Aggregate(ctx context.Context, req *models.ObjectAggregationRequest) (*models.ObjectAggregationResult, error)
+	ValidateExistingMetadata(ctx context.Context, objectTypeID int64, candidate json.RawMessage) (*models.MetadataValidationReport, error)
+	ListVersions(ctx context.Context, id int64, filter *models.ObjectVersionFilter) ([]*models.ObjectVersion, int64, error)
+	GetVersion(ctx context.Context, entityType string, id, revision int64) (*models.ObjectVersion, error)
+	DiffVersions(ctx context.Context, entityType string, id, fromRevision, toRevision int64) (*models.ObjectVersionDiff, error)
+	GetAsOf(ctx context.Context, id int64, at time.Time) (*models.Object, error)
+	RestoreVersion(ctx context.Context, id, revision int64, updatedBy string) (*models.Object, error)
 }
 
 type objectService struct {
 	repo           repository.ObjectRepository
 	objectTypeRepo repository.ObjectTypeRepository
+	txDB           TxBeginner
+	outbox         repository.OutboxRepository
 }
 
 func NewObjectService(repo repository.ObjectRepository, objectTypeRepo repository.ObjectTypeRepository) ObjectService {
+	return NewObjectServiceWithTx(repo, objectTypeRepo, nil)
+}
+
+// NewObjectServiceWithTx creates an object service that makes every change inside a transaction
+// started by txDB, together with the outbox event describing it. Without txDB changes run
+// directly against the given repositories and record no events.
+func NewObjectServiceWithTx(repo repository.ObjectRepository, objectTypeRepo repository.ObjectTypeRepository, txDB TxBeginner) ObjectService {
 	return &objectService{
 		repo:           repo,
 		objectTypeRepo: objectTypeRepo,
+		txDB:           txDB,
 	}
 }
 
+// withinTx runs fn with a service whose repositories are bound to a single transaction
+func (s *objectService) withinTx(ctx context.Context, fn func(tx *objectService) error) error {
+	if s.txDB == nil {
+		return fn(s)
+	}
+	return WithinTxOptions(ctx, s.txDB, outboxTxOptions, func(tx Transaction) error {
+		return fn(&objectService{
+			repo:           tx.ObjectRepository(),
+			objectTypeRepo: tx.ObjectTypeRepository(),
+			outbox:         tx.OutboxRepository(),
+		})
+	})
+}
+
+// recordEvent appends the event of an action on an object to the outbox of the transaction
+func (s *objectService) recordEvent(ctx context.Context, action string, before, after *models.Object) error {
+	if s.outbox == nil {
+		return nil
+	}
+	event, err := objectEvent(ctx, action, before, after)
+	if err != nil {
+		return err
+	}
+	return s.outbox.Append(ctx, event)
+}
+
 func (s *objectService) Create(ctx context.Context, req *models.CreateObjectRequest) (*models.Object, error) {
+	var created *models.Object
+	err := s.withinTx(ctx, func(tx *objectService) error {
+		var err error
+		created, err = tx.create(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *objectService) create(ctx context.Context, req *models.CreateObjectRequest) (*models.Object, error) {
 	if req.Name == "" {
 		return nil, fmt.Errorf("name is required: %w", repository.ErrInvalidInput)
 	}
@@ -80,7 +141,18 @@ func (s *objectService) Create(ctx context.Context, req *models.CreateObjectRequ
 		}
 	}
 
-	return s.repo.Create(ctx, req)
+	if err := newMetadataSchemaCache(s.objectTypeRepo).validate(ctx, req.ObjectTypeID, req.Metadata); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordEvent(ctx, models.EventActionCreated, nil, created); err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
 func (s *objectService) GetByID(ctx context.Context, id int64) (*models.Object, error) {
@@ -136,6 +208,19 @@ func (s *objectService) Update(ctx context.Context, id int64, req *models.Update
 		return nil, fmt.Errorf("invalid id: %w", repository.ErrInvalidInput)
 	}
 
+	var updated *models.Object
+	err := s.withinTx(ctx, func(tx *objectService) error {
+		var err error
+		updated, err = tx.update(ctx, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *objectService) update(ctx context.Context, id int64, req *models.UpdateObjectRequest) (*models.Object, error) {
 	existing, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("object not found: %w", err)
@@ -172,7 +257,29 @@ func (s *objectService) Update(ctx context.Context, id int64, req *models.Update
 		}
 	}
 
-	return s.repo.Update(ctx, id, req)
+	// Metadata is checked when it is replaced or when the object moves to a type with another schema
+	if req.Metadata != nil || req.ObjectTypeID != nil {
+		typeID := existing.ObjectTypeID
+		if req.ObjectTypeID != nil {
+			typeID = *req.ObjectTypeID
+		}
+		metadata := existing.GetMetadataMap()
+		if req.Metadata != nil {
+			metadata = *req.Metadata
+		}
+		if err := newMetadataSchemaCache(s.objectTypeRepo).validate(ctx, typeID, metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.repo.Update(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordEvent(ctx, models.EventActionUpdated, existing, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
 }
 
 func (s *objectService) Delete(ctx context.Context, id int64) error {
@@ -180,6 +287,12 @@ func (s *objectService) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("invalid id: %w", repository.ErrInvalidInput)
 	}
 
+	return s.withinTx(ctx, func(tx *objectService) error {
+		return tx.delete(ctx, id)
+	})
+}
+
+func (s *objectService) delete(ctx context.Context, id int64) error {
 	existing, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("object not found: %w", err)
@@ -190,7 +303,10 @@ func (s *objectService) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("cannot delete objects of sealed type: %w", repository.ErrInvalidInput)
 	}
 
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.recordEvent(ctx, models.EventActionDeleted, existing, nil)
 }
 
 func (s *objectService) List(ctx context.Context, filter *models.ObjectFilter) ([]*models.Object, int64, error) {
@@ -201,15 +317,13 @@ func (s *objectService) List(ctx context.Context, filter *models.ObjectFilter) (
 		}
 	}
 
-	return s.repo.List(ctx, filter)
-}
-
-func (s *objectService) Search(ctx context.Context, query string, limit int) ([]*models.Object, error) {
-	if query == "" {
-		return nil, fmt.Errorf("search query is required: %w", repository.ErrInvalidInput)
+	if filter != nil && filter.Filter != "" {
+		if _, err := filterexpr.Parse(filter.Filter); err != nil {
+			return nil, 0, fmt.Errorf("invalid filter: %w: %w", repository.ErrInvalidInput, err)
+		}
 	}
 
-	return s.repo.Search(ctx, query, limit)
+	return s.repo.List(ctx, filter)
 }
 
 func (s *objectService) FindByMetadata(ctx context.Context, key, value string) ([]*models.Object, error) {
@@ -237,7 +351,21 @@ func (s *objectService) UpdateMetadata(ctx context.Context, id int64, metadata m
 		return fmt.Errorf("metadata cannot be nil: %w", repository.ErrInvalidInput)
 	}
 
-	return s.repo.UpdateMetadata(ctx, id, metadata, updatedBy)
+	return s.withinTx(ctx, func(tx *objectService) error {
+		existing, err := tx.repo.GetByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("object not found: %w", err)
+		}
+
+		if err := newMetadataSchemaCache(tx.objectTypeRepo).validate(ctx, existing.ObjectTypeID, metadata); err != nil {
+			return err
+		}
+
+		if err := tx.repo.UpdateMetadata(ctx, id, metadata, updatedBy); err != nil {
+			return err
+		}
+		return tx.recordUpdate(ctx, existing)
+	})
 }
 
 func (s *objectService) AddTags(ctx context.Context, id int64, tags []string, updatedBy string) error {
@@ -249,7 +377,16 @@ func (s *objectService) AddTags(ctx context.Context, id int64, tags []string, up
 		return nil
 	}
 
-	return s.repo.AddTags(ctx, id, tags, updatedBy)
+	return s.withinTx(ctx, func(tx *objectService) error {
+		existing, err := tx.beforeUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.repo.AddTags(ctx, id, tags, updatedBy); err != nil {
+			return err
+		}
+		return tx.recordUpdate(ctx, existing)
+	})
 }
 
 func (s *objectService) RemoveTags(ctx context.Context, id int64, tags []string, updatedBy string) error {
@@ -261,7 +398,38 @@ func (s *objectService) RemoveTags(ctx context.Context, id int64, tags []string,
 		return nil
 	}
 
-	return s.repo.RemoveTags(ctx, id, tags, updatedBy)
+	return s.withinTx(ctx, func(tx *objectService) error {
+		existing, err := tx.beforeUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.repo.RemoveTags(ctx, id, tags, updatedBy); err != nil {
+			return err
+		}
+		return tx.recordUpdate(ctx, existing)
+	})
+}
+
+// beforeUpdate returns the object an update recording an event starts from; without an outbox
+// there is nothing to record and it is not read
+func (s *objectService) beforeUpdate(ctx context.Context, id int64) (*models.Object, error) {
+	if s.outbox == nil {
+		return nil, nil
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// recordUpdate records the update of an object from before to its current state, for the
+// repository writes that do not return the object they change
+func (s *objectService) recordUpdate(ctx context.Context, before *models.Object) error {
+	if s.outbox == nil || before == nil {
+		return nil
+	}
+	after, err := s.repo.GetByID(ctx, before.ID)
+	if err != nil {
+		return err
+	}
+	return s.recordEvent(ctx, models.EventActionUpdated, before, after)
 }
 
 func (s *objectService) GetChildren(ctx context.Context, parentID int64) ([]*models.Object, error) {
@@ -301,6 +469,7 @@ func (s *objectService) BulkCreate(ctx context.Context, objects []*models.Create
 		return []*models.Object{}, nil
 	}
 
+	schemas := newMetadataSchemaCache(s.objectTypeRepo)
 	for i, obj := range objects {
 		if obj.ObjectTypeID <= 0 {
 			return nil, fmt.Errorf("object[%d]: object_type_id is required: %w", i, repository.ErrInvalidInput)
@@ -314,21 +483,117 @@ func (s *objectService) BulkCreate(ctx context.Context, objects []*models.Create
 		if objectType.IsSealed {
 			return nil, fmt.Errorf("object[%d]: cannot create objects of sealed type: %w", i, repository.ErrInvalidInput)
 		}
+
+		if err := schemas.validate(ctx, obj.ObjectTypeID, obj.Metadata); err != nil {
+			var metadataErr *MetadataValidationError
+			if errors.As(err, &metadataErr) {
+				index := i
+				metadataErr.Index = &index
+				return nil, metadataErr
+			}
+			return nil, fmt.Errorf("object[%d]: %w", i, err)
+		}
 	}
 
-	return s.repo.BulkCreate(ctx, objects)
+	var created []*models.Object
+	err := s.withinTx(ctx, func(tx *objectService) error {
+		var err error
+		created, err = tx.repo.BulkCreate(ctx, objects)
+		if err != nil {
+			return err
+		}
+		for _, object := range created {
+			if err := tx.recordEvent(ctx, models.EventActionCreated, nil, object); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
-func (s *objectService) BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest) ([]*models.Object, error) {
+// BulkUpdate applies the same changes to all listed objects. Objects listed in expectedVersions
+// are only written while they are still at that version; the ones that are not are reported as
+// conflicts instead of failing the whole batch.
+func (s *objectService) BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest, expectedVersions map[int64]int64) (*models.BulkUpdateResult, error) {
+	result := &models.BulkUpdateResult{
+		Updated:   []*models.Object{},
+		Conflicts: []models.BulkUpdateConflict{},
+	}
 	if len(ids) == 0 {
-		return []*models.Object{}, nil
+		return result, nil
 	}
 
 	if updates == nil {
 		return nil, fmt.Errorf("updates cannot be nil: %w", repository.ErrInvalidInput)
 	}
 
-	return s.repo.BulkUpdate(ctx, ids, updates)
+	requested := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		requested[id] = true
+	}
+	for id := range expectedVersions {
+		if !requested[id] {
+			return nil, fmt.Errorf("expected version given for object %d which is not in ids: %w", id, repository.ErrInvalidInput)
+		}
+	}
+
+	var updated []*models.Object
+	err := s.withinTx(ctx, func(tx *objectService) error {
+		before, err := tx.beforeBulkChange(ctx, ids)
+		if err != nil {
+			return err
+		}
+		updated, err = tx.repo.BulkUpdate(ctx, ids, updates, expectedVersions)
+		if err != nil {
+			return err
+		}
+		for _, object := range updated {
+			if err := tx.recordEvent(ctx, models.EventActionUpdated, before[object.ID], object); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if updated != nil {
+		result.Updated = updated
+	}
+
+	settled := make(map[int64]bool, len(ids))
+	for _, object := range updated {
+		settled[object.ID] = true
+	}
+
+	for _, id := range ids {
+		expected, guarded := expectedVersions[id]
+		if !guarded || settled[id] {
+			continue
+		}
+		settled[id] = true
+
+		conflict := models.BulkUpdateConflict{ID: id, ExpectedVersion: expected}
+		current, err := s.repo.GetByIDWithDeleted(ctx, id)
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			conflict.Reason = models.BulkConflictNotFound
+		case err != nil:
+			return nil, err
+		case current.DeletedAt != nil:
+			conflict.Reason = models.BulkConflictDeleted
+		default:
+			conflict.Reason = models.BulkConflictVersionMismatch
+			conflict.CurrentVersion = &current.Version
+		}
+		result.Conflicts = append(result.Conflicts, conflict)
+	}
+
+	return result, nil
 }
 
 func (s *objectService) BulkDelete(ctx context.Context, ids []int64) error {
@@ -336,7 +601,46 @@ func (s *objectService) BulkDelete(ctx context.Context, ids []int64) error {
 		return nil
 	}
 
-	return s.repo.BulkDelete(ctx, ids)
+	return s.withinTx(ctx, func(tx *objectService) error {
+		before, err := tx.beforeBulkChange(ctx, ids)
+		if err != nil {
+			return err
+		}
+		if err := tx.repo.BulkDelete(ctx, ids); err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if object, ok := before[id]; ok {
+				if err := tx.recordEvent(ctx, models.EventActionDeleted, object, nil); err != nil {
+					return err
+				}
+				delete(before, id)
+			}
+		}
+		return nil
+	})
+}
+
+// beforeBulkChange returns the live objects among ids, by ID, as a bulk change recording events
+// starts from them; without an outbox they are not read
+func (s *objectService) beforeBulkChange(ctx context.Context, ids []int64) (map[int64]*models.Object, error) {
+	before := make(map[int64]*models.Object, len(ids))
+	if s.outbox == nil {
+		return before, nil
+	}
+	for _, id := range ids {
+		object, err := s.repo.GetByID(ctx, id)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if object.DeletedAt == nil {
+			before[id] = object
+		}
+	}
+	return before, nil
 }
 
 func (s *objectService) ValidateParentChild(ctx context.Context, parentID, childID int64) error {
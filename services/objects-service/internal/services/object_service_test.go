@@ -14,10 +14,12 @@ package services
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/filterexpr"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
 )
@@ -30,7 +32,8 @@ type mockObjectRepository struct {
 	updateFunc              func(ctx context.Context, id int64, input *models.UpdateObjectRequest) (*models.Object, error)
 	deleteFunc              func(ctx context.Context, id int64) error
 	listFunc                func(ctx context.Context, filter *models.ObjectFilter) ([]*models.Object, int64, error)
-	searchFunc              func(ctx context.Context, query string, limit int) ([]*models.Object, error)
+	searchFunc              func(ctx context.Context, filter *models.ObjectSearchFilter) (*models.ObjectSearchResults, error)
+	aggregateFunc           func(ctx context.Context, req *models.ObjectAggregationRequest) (*models.ObjectAggregationResult, error)
 	findByMetadataFunc      func(ctx context.Context, key, value string) ([]*models.Object, error)
 	findByTagsFunc          func(ctx context.Context, tags []string, matchAll bool) ([]*models.Object, error)
 	updateMetadataFunc      func(ctx context.Context, id int64, metadata map[string]interface{}, updatedBy string) error
@@ -40,11 +43,15 @@ type mockObjectRepository struct {
 	getDescendantsFunc      func(ctx context.Context, rootID int64, maxDepth *int) ([]*models.Object, error)
 	getAncestorsFunc        func(ctx context.Context, id int64) ([]*models.Object, error)
 	getPathFunc             func(ctx context.Context, id int64) ([]*models.Object, error)
+	moveFunc                func(ctx context.Context, id int64, parentID *int64, objectTypeID int64, updatedBy string) (*models.Object, error)
 	bulkCreateFunc          func(ctx context.Context, objects []*models.CreateObjectRequest) ([]*models.Object, error)
-	bulkUpdateFunc          func(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest) ([]*models.Object, error)
+	bulkUpdateFunc          func(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest, expectedVersions map[int64]int64) ([]*models.Object, error)
 	bulkDeleteFunc          func(ctx context.Context, ids []int64) error
 	validateParentChildFunc func(ctx context.Context, parentID, childID int64) error
 	getObjectStatsFunc      func(ctx context.Context, filter *models.ObjectFilter) (*repository.ObjectStats, error)
+	listVersionsFunc        func(ctx context.Context, objectID int64, filter *models.ObjectVersionFilter) ([]*models.ObjectVersion, int64, error)
+	getVersionFunc          func(ctx context.Context, entityType string, objectID, revision int64) (*models.ObjectVersion, error)
+	getVersionAsOfFunc      func(ctx context.Context, entityType string, objectID int64, at time.Time) (*models.ObjectVersion, error)
 }
 
 func (m *mockObjectRepository) Create(ctx context.Context, input *models.CreateObjectRequest) (*models.Object, error) {
@@ -96,9 +103,9 @@ func (m *mockObjectRepository) List(ctx context.Context, filter *models.ObjectFi
 	return nil, 0, nil
 }
 
-func (m *mockObjectRepository) Search(ctx context.Context, query string, limit int) ([]*models.Object, error) {
+func (m *mockObjectRepository) Search(ctx context.Context, filter *models.ObjectSearchFilter) (*models.ObjectSearchResults, error) {
 	if m.searchFunc != nil {
-		return m.searchFunc(ctx, query, limit)
+		return m.searchFunc(ctx, filter)
 	}
 	return nil, nil
 }
@@ -166,6 +173,13 @@ func (m *mockObjectRepository) GetPath(ctx context.Context, id int64) ([]*models
 	return nil, nil
 }
 
+func (m *mockObjectRepository) Move(ctx context.Context, id int64, parentID *int64, objectTypeID int64, updatedBy string) (*models.Object, error) {
+	if m.moveFunc != nil {
+		return m.moveFunc(ctx, id, parentID, objectTypeID, updatedBy)
+	}
+	return &models.Object{ID: id, ParentObjectID: parentID, ObjectTypeID: objectTypeID}, nil
+}
+
 func (m *mockObjectRepository) BulkCreate(ctx context.Context, objects []*models.CreateObjectRequest) ([]*models.Object, error) {
 	if m.bulkCreateFunc != nil {
 		return m.bulkCreateFunc(ctx, objects)
@@ -173,9 +187,9 @@ func (m *mockObjectRepository) BulkCreate(ctx context.Context, objects []*models
 	return nil, nil
 }
 
-func (m *mockObjectRepository) BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest) ([]*models.Object, error) {
+func (m *mockObjectRepository) BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest, expectedVersions map[int64]int64) ([]*models.Object, error) {
 	if m.bulkUpdateFunc != nil {
-		return m.bulkUpdateFunc(ctx, ids, updates)
+		return m.bulkUpdateFunc(ctx, ids, updates, expectedVersions)
 	}
 	return nil, nil
 }
@@ -209,6 +223,75 @@ func (m *mockObjectRepository) GetObjectStats(ctx context.Context, filter *model
 	return &repository.ObjectStats{}, nil
 }
 
+func (m *mockObjectRepository) ListVersions(ctx context.Context, objectID int64, filter *models.ObjectVersionFilter) ([]*models.ObjectVersion, int64, error) {
+	if m.listVersionsFunc != nil {
+		return m.listVersionsFunc(ctx, objectID, filter)
+	}
+	return []*models.ObjectVersion{}, 0, nil
+}
+
+func (m *mockObjectRepository) GetVersion(ctx context.Context, entityType string, objectID, revision int64) (*models.ObjectVersion, error) {
+	if m.getVersionFunc != nil {
+		return m.getVersionFunc(ctx, entityType, objectID, revision)
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *mockObjectRepository) GetVersionAsOf(ctx context.Context, entityType string, objectID int64, at time.Time) (*models.ObjectVersion, error) {
+	if m.getVersionAsOfFunc != nil {
+		return m.getVersionAsOfFunc(ctx, entityType, objectID, at)
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *mockObjectRepository) Aggregate(ctx context.Context, req *models.ObjectAggregationRequest) (*models.ObjectAggregationResult, error) {
+	if m.aggregateFunc != nil {
+		return m.aggregateFunc(ctx, req)
+	}
+	return &models.ObjectAggregationResult{Buckets: []*models.AggregationBucket{}}, nil
+}
+
+func (m *mockObjectRepository) ExportObjects(ctx context.Context, filter *models.ObjectFilter, batchSize int, fn func([]*models.ObjectRecord) error) error {
+	return nil
+}
+
+func (m *mockObjectRepository) FindByKeys(ctx context.Context, publicIDs []uuid.UUID, externalKeys []string) (map[uuid.UUID]*models.Object, map[string]*models.Object, error) {
+	return map[uuid.UUID]*models.Object{}, map[string]*models.Object{}, nil
+}
+
+func (m *mockObjectRepository) CopyObjects(ctx context.Context, inputs []*models.ImportedObject) ([]*models.Object, error) {
+	return []*models.Object{}, nil
+}
+
+func (m *mockObjectRepository) UpdateImportedObjects(ctx context.Context, inputs []*models.ImportedObject) ([]*models.Object, error) {
+	return []*models.Object{}, nil
+}
+
+// GetByIDWithDeleted answers from getByIDFunc as well, whose objects may be deleted
+func (m *mockObjectRepository) GetByIDWithDeleted(ctx context.Context, id int64) (*models.Object, error) {
+	return m.GetByID(ctx, id)
+}
+
+func (m *mockObjectRepository) ListDeleted(ctx context.Context, filter *models.TrashFilter) ([]*models.Object, int64, error) {
+	return []*models.Object{}, 0, nil
+}
+
+func (m *mockObjectRepository) GetDeletedDescendants(ctx context.Context, rootID int64) ([]*models.Object, error) {
+	return []*models.Object{}, nil
+}
+
+func (m *mockObjectRepository) ListExpired(ctx context.Context, deletedBefore time.Time, limit int) ([]*models.Object, error) {
+	return []*models.Object{}, nil
+}
+
+func (m *mockObjectRepository) Restore(ctx context.Context, id int64, updatedBy string) (*models.Object, error) {
+	return &models.Object{ID: id}, nil
+}
+
+func (m *mockObjectRepository) Purge(ctx context.Context, ids []int64) error {
+	return nil
+}
+
 func (m *mockObjectRepository) DB() repository.DBInterface             { return nil }
 func (m *mockObjectRepository) Options() *repository.RepositoryOptions { return nil }
 func (m *mockObjectRepository) Metrics() *repository.RepositoryMetrics { return nil }
@@ -216,7 +299,9 @@ func (m *mockObjectRepository) ResetMetrics()                          {}
 func (m *mockObjectRepository) Healthy(ctx context.Context) error      { return nil }
 
 type mockObjectTypeRepositoryForObjectService struct {
-	getByIDFunc func(ctx context.Context, id int64) (*models.ObjectType, error)
+	getByIDFunc        func(ctx context.Context, id int64) (*models.ObjectType, error)
+	getDescendantsFunc func(ctx context.Context, rootID int64, maxDepth *int) ([]*models.ObjectType, error)
+	getPathFunc        func(ctx context.Context, id int64) ([]*models.ObjectType, error)
 }
 
 func (m *mockObjectTypeRepositoryForObjectService) Create(ctx context.Context, input *models.CreateObjectTypeRequest) (*models.ObjectType, error) {
@@ -251,6 +336,9 @@ func (m *mockObjectTypeRepositoryForObjectService) GetChildren(ctx context.Conte
 }
 
 func (m *mockObjectTypeRepositoryForObjectService) GetDescendants(ctx context.Context, rootID int64, maxDepth *int) ([]*models.ObjectType, error) {
+	if m.getDescendantsFunc != nil {
+		return m.getDescendantsFunc(ctx, rootID, maxDepth)
+	}
 	return nil, nil
 }
 
@@ -259,6 +347,9 @@ func (m *mockObjectTypeRepositoryForObjectService) GetAncestors(ctx context.Cont
 }
 
 func (m *mockObjectTypeRepositoryForObjectService) GetPath(ctx context.Context, id int64) ([]*models.ObjectType, error) {
+	if m.getPathFunc != nil {
+		return m.getPathFunc(ctx, id)
+	}
 	return nil, nil
 }
 
@@ -278,6 +369,10 @@ func (m *mockObjectTypeRepositoryForObjectService) GetSubtreeObjectCount(ctx con
 	return 0, nil
 }
 
+func (m *mockObjectTypeRepositoryForObjectService) Move(ctx context.Context, id int64, newParentID *int64, updatedBy string) (*models.ObjectType, error) {
+	return nil, nil
+}
+
 func (m *mockObjectTypeRepositoryForObjectService) CanDelete(ctx context.Context, id int64) (bool, error) {
 	return true, nil
 }
@@ -426,7 +521,7 @@ func TestObjectService_Search_EmptyQuery(t *testing.T) {
 	mockTypeRepo := &mockObjectTypeRepositoryForObjectService{}
 	service := NewObjectService(mockRepo, mockTypeRepo)
 
-	_, err := service.Search(context.Background(), "", 10)
+	_, err := service.Search(context.Background(), &models.ObjectSearchFilter{Query: "  ", Limit: 10})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "query is required")
 }
@@ -585,10 +680,11 @@ func TestObjectService_BulkUpdate_EmptyIDs(t *testing.T) {
 	mockTypeRepo := &mockObjectTypeRepositoryForObjectService{}
 	service := NewObjectService(mockRepo, mockTypeRepo)
 
-	result, err := service.BulkUpdate(context.Background(), []int64{}, &models.UpdateObjectRequest{})
+	result, err := service.BulkUpdate(context.Background(), []int64{}, &models.UpdateObjectRequest{}, nil)
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Len(t, result, 0)
+	assert.Len(t, result.Updated, 0)
+	assert.Len(t, result.Conflicts, 0)
 }
 
 func TestObjectService_BulkUpdate_NilUpdates(t *testing.T) {
@@ -596,11 +692,52 @@ func TestObjectService_BulkUpdate_NilUpdates(t *testing.T) {
 	mockTypeRepo := &mockObjectTypeRepositoryForObjectService{}
 	service := NewObjectService(mockRepo, mockTypeRepo)
 
-	_, err := service.BulkUpdate(context.Background(), []int64{1, 2}, nil)
+	_, err := service.BulkUpdate(context.Background(), []int64{1, 2}, nil, nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "updates cannot be nil")
 }
 
+func TestObjectService_BulkUpdate_ExpectedVersions(t *testing.T) {
+	deletedAt := time.Now()
+	mockRepo := &mockObjectRepository{
+		bulkUpdateFunc: func(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest, expectedVersions map[int64]int64) ([]*models.Object, error) {
+			return []*models.Object{{ID: 1, Version: 3}, {ID: 4, Version: 2}}, nil
+		},
+		getByIDFunc: func(ctx context.Context, id int64) (*models.Object, error) {
+			switch id {
+			case 2:
+				return &models.Object{ID: 2, Version: 6}, nil
+			case 3:
+				return &models.Object{ID: 3, Version: 1, DeletedAt: &deletedAt}, nil
+			default:
+				return nil, repository.ErrNotFound
+			}
+		},
+	}
+	service := NewObjectService(mockRepo, &mockObjectTypeRepositoryForObjectService{})
+
+	status := "archived"
+	result, err := service.BulkUpdate(context.Background(), []int64{1, 2, 3, 4, 5}, &models.UpdateObjectRequest{Status: &status},
+		map[int64]int64{1: 2, 2: 5, 3: 1, 5: 1})
+	assert.NoError(t, err)
+	assert.Len(t, result.Updated, 2)
+	if assert.Len(t, result.Conflicts, 3) {
+		assert.Equal(t, models.BulkConflictVersionMismatch, result.Conflicts[0].Reason)
+		assert.Equal(t, int64(6), *result.Conflicts[0].CurrentVersion)
+		assert.Equal(t, models.BulkConflictDeleted, result.Conflicts[1].Reason)
+		assert.Equal(t, models.BulkConflictNotFound, result.Conflicts[2].Reason)
+		assert.Nil(t, result.Conflicts[2].CurrentVersion)
+	}
+}
+
+func TestObjectService_BulkUpdate_ExpectedVersionOutsideIDs(t *testing.T) {
+	service := NewObjectService(&mockObjectRepository{}, &mockObjectTypeRepositoryForObjectService{})
+
+	status := "archived"
+	_, err := service.BulkUpdate(context.Background(), []int64{1}, &models.UpdateObjectRequest{Status: &status}, map[int64]int64{9: 1})
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+}
+
 func TestObjectService_BulkDelete_EmptyIDs(t *testing.T) {
 	mockRepo := &mockObjectRepository{}
 	mockTypeRepo := &mockObjectTypeRepositoryForObjectService{}
@@ -609,3 +746,24 @@ func TestObjectService_BulkDelete_EmptyIDs(t *testing.T) {
 	err := service.BulkDelete(context.Background(), []int64{})
 	assert.NoError(t, err)
 }
+
+func TestObjectService_List_InvalidFilter(t *testing.T) {
+	listed := false
+	mockRepo := &mockObjectRepository{
+		listFunc: func(ctx context.Context, filter *models.ObjectFilter) ([]*models.Object, int64, error) {
+			listed = true
+			return nil, 0, nil
+		},
+	}
+	service := NewObjectService(mockRepo, &mockObjectTypeRepositoryForObjectService{})
+
+	_, _, err := service.List(context.Background(), &models.ObjectFilter{Filter: `metadata.amount gt 1000 and`})
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+	var filterErr *filterexpr.Error
+	assert.ErrorAs(t, err, &filterErr)
+	assert.False(t, listed)
+
+	_, _, err = service.List(context.Background(), &models.ObjectFilter{Filter: `metadata.amount gt 1000`})
+	assert.NoError(t, err)
+	assert.True(t, listed)
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
@@ -22,15 +23,47 @@ type ObjectTypeService interface {
 	List(ctx context.Context, filter *models.ObjectTypeFilter) ([]*models.ObjectType, error)
 	Search(ctx context.Context, query string, limit int) ([]*models.ObjectType, error)
 	ValidateMove(ctx context.Context, id int64, newParentID *int64) error
+	Move(ctx context.Context, id int64, req *models.MoveObjectTypeRequest) (*models.ObjectType, error)
 	GetSubtreeObjectCount(ctx context.Context, id int64) (int64, error)
+	GetEffectiveMetadataSchema(ctx context.Context, id int64) (json.RawMessage, error)
 }
 
 type objectTypeService struct {
-	repo repository.ObjectTypeRepository
+	repo   repository.ObjectTypeRepository
+	txDB   TxBeginner
+	outbox repository.OutboxRepository
 }
 
 func NewObjectTypeService(repo repository.ObjectTypeRepository) ObjectTypeService {
-	return &objectTypeService{repo: repo}
+	return NewObjectTypeServiceWithTx(repo, nil)
+}
+
+// NewObjectTypeServiceWithTx creates an object type service that makes every change inside a
+// transaction started by txDB, together with the outbox event describing it
+func NewObjectTypeServiceWithTx(repo repository.ObjectTypeRepository, txDB TxBeginner) ObjectTypeService {
+	return &objectTypeService{repo: repo, txDB: txDB}
+}
+
+// withinTx runs fn with a service whose repository is bound to a single transaction
+func (s *objectTypeService) withinTx(ctx context.Context, fn func(tx *objectTypeService) error) error {
+	if s.txDB == nil {
+		return fn(s)
+	}
+	return WithinTxOptions(ctx, s.txDB, outboxTxOptions, func(tx Transaction) error {
+		return fn(&objectTypeService{repo: tx.ObjectTypeRepository(), outbox: tx.OutboxRepository()})
+	})
+}
+
+// recordEvent appends the event of an action on an object type to the outbox of the transaction
+func (s *objectTypeService) recordEvent(ctx context.Context, action string, before, after *models.ObjectType) error {
+	if s.outbox == nil {
+		return nil
+	}
+	event, err := objectTypeEvent(ctx, action, before, after)
+	if err != nil {
+		return err
+	}
+	return s.outbox.Append(ctx, event)
 }
 
 func (s *objectTypeService) Create(ctx context.Context, req *models.CreateObjectTypeRequest) (*models.ObjectType, error) {
@@ -38,7 +71,36 @@ func (s *objectTypeService) Create(ctx context.Context, req *models.CreateObject
 		return nil, fmt.Errorf("name is required: %w", repository.ErrInvalidInput)
 	}
 
-	return s.repo.Create(ctx, req)
+	var created *models.ObjectType
+	err := s.withinTx(ctx, func(tx *objectTypeService) error {
+		var err error
+		created, err = tx.create(ctx, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *objectTypeService) create(ctx context.Context, req *models.CreateObjectTypeRequest) (*models.ObjectType, error) {
+
+	if err := s.checkMetadataSchema(ctx, req.ParentTypeID, req.MetadataSchema); err != nil {
+		return nil, err
+	}
+
+	if err := checkSearchMetadataKeys(req.SearchMetadataKeys); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.Create(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordEvent(ctx, models.EventActionCreated, nil, created); err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
 func (s *objectTypeService) GetByID(ctx context.Context, id int64) (*models.ObjectType, error) {
@@ -78,6 +140,19 @@ func (s *objectTypeService) Update(ctx context.Context, id int64, req *models.Up
 		return nil, fmt.Errorf("invalid id: %w", repository.ErrInvalidInput)
 	}
 
+	var updated *models.ObjectType
+	err := s.withinTx(ctx, func(tx *objectTypeService) error {
+		var err error
+		updated, err = tx.update(ctx, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *objectTypeService) update(ctx context.Context, id int64, req *models.UpdateObjectTypeRequest) (*models.ObjectType, error) {
 	existing, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("object type not found: %w", err)
@@ -91,7 +166,30 @@ func (s *objectTypeService) Update(ctx context.Context, id int64, req *models.Up
 		return nil, fmt.Errorf("cannot modify sealed object type: %w", repository.ErrInvalidInput)
 	}
 
-	return s.repo.Update(ctx, id, req)
+	if req.MetadataSchema != nil {
+		parentTypeID := existing.ParentTypeID
+		if req.ParentTypeID != nil {
+			parentTypeID = req.ParentTypeID
+		}
+		if err := s.checkMetadataSchema(ctx, parentTypeID, *req.MetadataSchema); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.SearchMetadataKeys != nil {
+		if err := checkSearchMetadataKeys(*req.SearchMetadataKeys); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.repo.Update(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordEvent(ctx, models.EventActionUpdated, existing, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
 }
 
 func (s *objectTypeService) Delete(ctx context.Context, id int64) error {
@@ -99,6 +197,12 @@ func (s *objectTypeService) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("invalid id: %w", repository.ErrInvalidInput)
 	}
 
+	return s.withinTx(ctx, func(tx *objectTypeService) error {
+		return tx.delete(ctx, id)
+	})
+}
+
+func (s *objectTypeService) delete(ctx context.Context, id int64) error {
 	existing, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("object type not found: %w", err)
@@ -117,7 +221,10 @@ func (s *objectTypeService) Delete(ctx context.Context, id int64) error {
 		return fmt.Errorf("cannot delete object type with existing objects: %w", repository.ErrInvalidInput)
 	}
 
-	return s.repo.Delete(ctx, id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.recordEvent(ctx, models.EventActionDeleted, existing, nil)
 }
 
 func (s *objectTypeService) GetTree(ctx context.Context, rootID *int64) ([]*models.ObjectType, error) {
@@ -171,3 +278,40 @@ func (s *objectTypeService) GetSubtreeObjectCount(ctx context.Context, id int64)
 
 	return s.repo.GetSubtreeObjectCount(ctx, id)
 }
+
+// GetEffectiveMetadataSchema returns the type's metadata schema merged with those of its ancestors,
+// or an empty object when no schema applies
+func (s *objectTypeService) GetEffectiveMetadataSchema(ctx context.Context, id int64) (json.RawMessage, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid id: %w", repository.ErrInvalidInput)
+	}
+
+	schema, err := effectiveMetadataSchema(ctx, s.repo, id)
+	if err != nil {
+		return nil, err
+	}
+	if schema == nil {
+		return json.RawMessage("{}"), nil
+	}
+
+	return json.Marshal(schema)
+}
+
+// checkMetadataSchema verifies that a type's own schema, merged with the inherited one, compiles
+func (s *objectTypeService) checkMetadataSchema(ctx context.Context, parentTypeID *int64, raw json.RawMessage) error {
+	schema, err := decodeMetadataSchema(raw)
+	if err != nil || schema == nil {
+		return err
+	}
+
+	if parentTypeID != nil {
+		inherited, err := effectiveMetadataSchema(ctx, s.repo, *parentTypeID)
+		if err != nil {
+			return err
+		}
+		schema = mergeMetadataSchemas(inherited, schema)
+	}
+
+	_, err = compileMetadataSchema(schema)
+	return err
+}
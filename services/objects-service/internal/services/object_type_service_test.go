@@ -32,6 +32,7 @@ type mockObjectTypeRepository struct {
 	getSubtreeObjectCountFunc func(ctx context.Context, id int64) (int64, error)
 	canDeleteFunc             func(ctx context.Context, id int64) (bool, error)
 	validateParentChildFunc   func(ctx context.Context, parentID, childID int64) error
+	moveFunc                  func(ctx context.Context, id int64, newParentID *int64, updatedBy string) (*models.ObjectType, error)
 }
 
 func (m *mockObjectTypeRepository) Create(ctx context.Context, input *models.CreateObjectTypeRequest) (*models.ObjectType, error) {
@@ -132,6 +133,13 @@ func (m *mockObjectTypeRepository) GetSubtreeObjectCount(ctx context.Context, id
 	return 0, nil
 }
 
+func (m *mockObjectTypeRepository) Move(ctx context.Context, id int64, newParentID *int64, updatedBy string) (*models.ObjectType, error) {
+	if m.moveFunc != nil {
+		return m.moveFunc(ctx, id, newParentID, updatedBy)
+	}
+	return &models.ObjectType{ID: id, ParentTypeID: newParentID}, nil
+}
+
 func (m *mockObjectTypeRepository) CanDelete(ctx context.Context, id int64) (bool, error) {
 	if m.canDeleteFunc != nil {
 		return m.canDeleteFunc(ctx, id)
@@ -0,0 +1,290 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// Changes to objects, object types and relationships are described by domain events appended
+// to the outbox in the same transaction as the change, so an event exists exactly when its change
+// was committed. The OutboxDispatcher delivers them to sinks afterwards, at least once and in
+// offset order; consumers deduplicate by event ID.
+
+// outboxTxOptions runs changes that record events at READ COMMITTED, the isolation the
+// repositories assume outside of transactions
+var outboxTxOptions = pgx.TxOptions{
+	IsoLevel:   pgx.ReadCommitted,
+	AccessMode: pgx.ReadWrite,
+}
+
+// newOutboxEvent describes an action on an entity. before and after are the entity as it was and
+// as it is; nil stands for absent. The actor is the one of the request, else fallbackActor.
+func newOutboxEvent(ctx context.Context, entityType, action string, entityID int64, publicID *uuid.UUID, before, after interface{}, fallbackActor string) (*models.OutboxEvent, error) {
+	event := &models.OutboxEvent{
+		EventType:      models.EventType(entityType, action),
+		EntityType:     entityType,
+		EntityID:       entityID,
+		EntityPublicID: publicID,
+	}
+
+	var err error
+	if event.Before, err = eventDocument(before); err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", event.EventType, err)
+	}
+	if event.After, err = eventDocument(after); err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", event.EventType, err)
+	}
+
+	change := repository.ChangeContextFrom(ctx)
+	event.RequestID = change.RequestID
+	switch {
+	case change.Actor != "":
+		event.Actor = change.Actor
+	case fallbackActor != "":
+		event.Actor = fallbackActor
+	default:
+		event.Actor = "system"
+	}
+
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		event.TraceID = span.TraceID().String()
+	}
+	return event, nil
+}
+
+// eventDocument encodes an entity, leaving nil and typed nil pointers absent
+func eventDocument(entity interface{}) (json.RawMessage, error) {
+	if entity == nil {
+		return nil, nil
+	}
+	doc, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+	if string(doc) == "null" {
+		return nil, nil
+	}
+	return doc, nil
+}
+
+// objectEvent describes an action on an object; an update made by a restore is a restore and
+// one made by a move is a move
+func objectEvent(ctx context.Context, action string, before, after *models.Object) (*models.OutboxEvent, error) {
+	if action == models.EventActionUpdated {
+		switch repository.ChangeContextFrom(ctx).Action {
+		case models.HistoryActionRestore:
+			action = models.EventActionRestored
+		case models.HistoryActionMove:
+			action = models.EventActionMoved
+		}
+	}
+	object := after
+	if object == nil {
+		object = before
+	}
+	publicID := object.PublicID
+	return newOutboxEvent(ctx, models.EventEntityObject, action, object.ID, &publicID, before, after, object.UpdatedBy)
+}
+
+// objectTypeEvent describes an action on an object type; an update made by a move is a move
+func objectTypeEvent(ctx context.Context, action string, before, after *models.ObjectType) (*models.OutboxEvent, error) {
+	if action == models.EventActionUpdated && repository.ChangeContextFrom(ctx).Action == models.HistoryActionMove {
+		action = models.EventActionMoved
+	}
+	objectType := after
+	if objectType == nil {
+		objectType = before
+	}
+	return newOutboxEvent(ctx, models.EventEntityObjectType, action, objectType.ID, nil, before, after, objectType.UpdatedBy)
+}
+
+// relationshipEvent describes an action on a relationship, as stored under its primary type
+func relationshipEvent(ctx context.Context, action string, before, after *models.Relationship) (*models.OutboxEvent, error) {
+	rel := after
+	if rel == nil {
+		rel = before
+	}
+	actor := ""
+	if rel.UpdatedBy != nil {
+		actor = *rel.UpdatedBy
+	} else if rel.CreatedBy != nil {
+		actor = *rel.CreatedBy
+	}
+	publicID := rel.PublicID
+	return newOutboxEvent(ctx, models.EventEntityRelationship, action, rel.ObjectID, &publicID, before, after, actor)
+}
+
+// EventSink receives the events delivered by the OutboxDispatcher. Publish gets events in offset
+// order and must return an error unless all of them were accepted; the batch is then retried.
+type EventSink interface {
+	Name() string
+	Publish(ctx context.Context, events []*models.OutboxEvent) error
+}
+
+// LogSink writes events to the service log
+type LogSink struct {
+	logger *logrus.Logger
+}
+
+// NewLogSink creates a sink that logs every event it receives
+func NewLogSink(logger *logrus.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+// Name implements EventSink
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+// Publish implements EventSink
+func (s *LogSink) Publish(ctx context.Context, events []*models.OutboxEvent) error {
+	for _, event := range events {
+		s.logger.WithFields(logrus.Fields{
+			"offset":      event.Offset,
+			"event_id":    event.EventID,
+			"event_type":  event.EventType,
+			"entity_id":   event.EntityID,
+			"actor":       event.Actor,
+			"request_id":  event.RequestID,
+			"trace_id":    event.TraceID,
+			"occurred_at": event.OccurredAt,
+		}).Info("Domain event")
+	}
+	return nil
+}
+
+// OutboxDispatcherConfig configures the delivery of outbox events
+type OutboxDispatcherConfig struct {
+	BatchSize    int           // Events delivered per transaction
+	PollInterval time.Duration // Wait between polls once the outbox is drained
+	MaxBackoff   time.Duration // Longest wait between retries of a failing delivery
+}
+
+// OutboxDispatcher delivers committed outbox events to its sinks. Every delivery runs in a
+// transaction holding the dispatch lock, so of several service instances one delivers at a time
+// and events stay in order. A batch is marked dispatched only once every sink accepted it.
+type OutboxDispatcher struct {
+	db     TxBeginner
+	sinks  []EventSink
+	config OutboxDispatcherConfig
+	logger *logrus.Logger
+}
+
+// NewOutboxDispatcher creates a dispatcher delivering events to sinks
+func NewOutboxDispatcher(db TxBeginner, config OutboxDispatcherConfig, logger *logrus.Logger, sinks ...EventSink) *OutboxDispatcher {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.MaxBackoff < config.PollInterval {
+		config.MaxBackoff = config.PollInterval
+	}
+
+	return &OutboxDispatcher{
+		db:     db,
+		sinks:  sinks,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run delivers events until ctx is cancelled. A full batch is followed by the next one at once;
+// failures are retried with exponential backoff.
+func (d *OutboxDispatcher) Run(ctx context.Context) {
+	failures := 0
+	for {
+		dispatched, err := d.DispatchOnce(ctx)
+
+		wait := d.config.PollInterval
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			wait = d.backoff(failures)
+			d.logger.WithError(err).WithField("retry_in", wait).Warn("Failed to dispatch outbox events")
+		case dispatched == d.config.BatchSize:
+			failures = 0
+			wait = 0
+		default:
+			failures = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff doubles the poll interval for every consecutive failure, up to MaxBackoff
+func (d *OutboxDispatcher) backoff(failures int) time.Duration {
+	wait := d.config.PollInterval
+	for i := 1; i < failures && wait < d.config.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > d.config.MaxBackoff {
+		wait = d.config.MaxBackoff
+	}
+	return wait
+}
+
+// DispatchOnce delivers one batch of pending events and returns how many were delivered. It
+// delivers nothing while another dispatcher holds the dispatch lock.
+func (d *OutboxDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	var dispatched int
+	var failure error
+
+	err := WithinTxOptions(ctx, d.db, outboxTxOptions, func(tx Transaction) error {
+		outbox := tx.OutboxRepository()
+
+		locked, err := outbox.TryLockDispatch(ctx)
+		if err != nil || !locked {
+			return err
+		}
+
+		events, err := outbox.Pending(ctx, d.config.BatchSize)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		for _, sink := range d.sinks {
+			if err := sink.Publish(ctx, events); err != nil {
+				// The attempt is recorded on the oldest event, which blocks the rest
+				failure = fmt.Errorf("sink %s: %w", sink.Name(), err)
+				return outbox.MarkFailed(ctx, events[0].Offset, failure.Error())
+			}
+		}
+
+		offsets := make([]int64, len(events))
+		for i, event := range events {
+			offsets[i] = event.Offset
+		}
+		if err := outbox.MarkDispatched(ctx, offsets); err != nil {
+			return err
+		}
+		dispatched = len(events)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if failure != nil {
+		return 0, failure
+	}
+	return dispatched, nil
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// memoryOutbox is an in-memory OutboxRepository. Events appended in a transaction become visible
+// when it commits and are dropped when it rolls back.
+type memoryOutbox struct {
+	committed  []*models.OutboxEvent
+	dispatched map[int64]bool
+	failures   map[int64]string
+	locked     bool
+	nextOffset int64
+}
+
+func newMemoryOutbox() *memoryOutbox {
+	return &memoryOutbox{dispatched: map[int64]bool{}, failures: map[int64]string{}}
+}
+
+func (o *memoryOutbox) eventTypes() []string {
+	types := make([]string, len(o.committed))
+	for i, event := range o.committed {
+		types[i] = event.EventType
+	}
+	return types
+}
+
+// outboxTx is a transaction of memoryTxDB; its outbox stages appended events
+type outboxTx struct {
+	db     *memoryTxDB
+	staged []*models.OutboxEvent
+}
+
+func (tx *outboxTx) ObjectTypeRepository() repository.ObjectTypeRepository {
+	return tx.db.objectTypeRepo
+}
+func (tx *outboxTx) ObjectRepository() repository.ObjectRepository { return tx.db.objectRepo }
+func (tx *outboxTx) RelationshipTypeRepository() repository.RelationshipTypeRepository {
+	return tx.db.relTypeRepo
+}
+func (tx *outboxTx) RelationshipRepository() repository.RelationshipRepository { return tx.db.relRepo }
+func (tx *outboxTx) OutboxRepository() repository.OutboxRepository             { return &txOutbox{tx: tx} }
+func (tx *outboxTx) ConcreteTableRepository() repository.ConcreteTableRepository {
+	return tx.db.concreteRepo
+}
+
+func (tx *outboxTx) Commit(ctx context.Context) error {
+	tx.db.outbox.committed = append(tx.db.outbox.committed, tx.staged...)
+	tx.db.commits++
+	return nil
+}
+
+func (tx *outboxTx) Rollback(ctx context.Context) error {
+	tx.db.rollbacks++
+	return nil
+}
+
+// txOutbox is the outbox repository seen inside an outboxTx
+type txOutbox struct {
+	repository.OutboxRepository
+	tx *outboxTx
+}
+
+func (o *txOutbox) Append(ctx context.Context, events ...*models.OutboxEvent) error {
+	outbox := o.tx.db.outbox
+	for _, event := range events {
+		outbox.nextOffset++
+		event.Offset = outbox.nextOffset
+		event.EventID = uuid.New()
+		event.OccurredAt = time.Now()
+		o.tx.staged = append(o.tx.staged, event)
+	}
+	return nil
+}
+
+func (o *txOutbox) TryLockDispatch(ctx context.Context) (bool, error) {
+	return !o.tx.db.outbox.locked, nil
+}
+
+func (o *txOutbox) Pending(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	outbox := o.tx.db.outbox
+	pending := []*models.OutboxEvent{}
+	for _, event := range outbox.committed {
+		if !outbox.dispatched[event.Offset] && len(pending) < limit {
+			pending = append(pending, event)
+		}
+	}
+	return pending, nil
+}
+
+func (o *txOutbox) MarkDispatched(ctx context.Context, offsets []int64) error {
+	for _, offset := range offsets {
+		o.tx.db.outbox.dispatched[offset] = true
+	}
+	return nil
+}
+
+func (o *txOutbox) MarkFailed(ctx context.Context, offset int64, cause string) error {
+	o.tx.db.outbox.failures[offset] = cause
+	return nil
+}
+
+// memoryTxDB is a TxBeginner sharing fixed repositories and a memoryOutbox between transactions
+type memoryTxDB struct {
+	objectRepo     repository.ObjectRepository
+	objectTypeRepo repository.ObjectTypeRepository
+	outbox         *memoryOutbox
+	concreteRepo   repository.ConcreteTableRepository
+	relRepo        repository.RelationshipRepository
+	relTypeRepo    repository.RelationshipTypeRepository
+	commits        int
+	rollbacks      int
+}
+
+func newMemoryTxDB(objectRepo repository.ObjectRepository, objectTypeRepo repository.ObjectTypeRepository) *memoryTxDB {
+	return &memoryTxDB{objectRepo: objectRepo, objectTypeRepo: objectTypeRepo, outbox: newMemoryOutbox()}
+}
+
+func (db *memoryTxDB) BeginTx(ctx context.Context, opts pgx.TxOptions) (Transaction, error) {
+	return &outboxTx{db: db}, nil
+}
+
+func TestObjectService_RecordsEventsInTransaction(t *testing.T) {
+	objects := map[int64]*models.Object{}
+	objectRepo := &mockObjectRepository{
+		createFunc: func(ctx context.Context, input *models.CreateObjectRequest) (*models.Object, error) {
+			object := &models.Object{ID: 10, PublicID: uuid.New(), ObjectTypeID: input.ObjectTypeID, Name: input.Name, Version: 1, UpdatedBy: input.CreatedBy}
+			objects[object.ID] = object
+			return object, nil
+		},
+		getByIDFunc: func(ctx context.Context, id int64) (*models.Object, error) {
+			if object, ok := objects[id]; ok {
+				copied := *object
+				return &copied, nil
+			}
+			return nil, repository.ErrNotFound
+		},
+		updateFunc: func(ctx context.Context, id int64, input *models.UpdateObjectRequest) (*models.Object, error) {
+			object := objects[id]
+			object.Name = *input.Name
+			object.Version++
+			return object, nil
+		},
+		deleteFunc: func(ctx context.Context, id int64) error {
+			delete(objects, id)
+			return nil
+		},
+	}
+	objectTypeRepo := &mockObjectTypeRepository{
+		getByIDFunc: func(ctx context.Context, id int64) (*models.ObjectType, error) {
+			return &models.ObjectType{ID: id, Name: "Document"}, nil
+		},
+	}
+	db := newMemoryTxDB(objectRepo, objectTypeRepo)
+	service := NewObjectServiceWithTx(objectRepo, objectTypeRepo, db)
+
+	ctx := repository.WithChangeContext(context.Background(), repository.ChangeContext{RequestID: "req-1", Actor: "alice"})
+	created, err := service.Create(ctx, &models.CreateObjectRequest{ObjectTypeID: 2, Name: "Spec"})
+	require.NoError(t, err)
+
+	name := "Spec v2"
+	_, err = service.Update(ctx, created.ID, &models.UpdateObjectRequest{Name: &name})
+	require.NoError(t, err)
+	require.NoError(t, service.Delete(ctx, created.ID))
+
+	assert.Equal(t, []string{"object.created", "object.updated", "object.deleted"}, db.outbox.eventTypes())
+	assert.Equal(t, 3, db.commits)
+
+	createdEvent, updatedEvent, deletedEvent := db.outbox.committed[0], db.outbox.committed[1], db.outbox.committed[2]
+	assert.Empty(t, createdEvent.Before)
+	assert.Contains(t, string(createdEvent.After), `"name":"Spec"`)
+	assert.Contains(t, string(updatedEvent.Before), `"name":"Spec"`)
+	assert.Contains(t, string(updatedEvent.After), `"name":"Spec v2"`)
+	assert.Contains(t, string(deletedEvent.Before), `"name":"Spec v2"`)
+	assert.Empty(t, deletedEvent.After)
+	for _, event := range db.outbox.committed {
+		assert.Equal(t, created.ID, event.EntityID)
+		assert.Equal(t, created.PublicID, *event.EntityPublicID)
+		assert.Equal(t, "alice", event.Actor)
+		assert.Equal(t, "req-1", event.RequestID)
+	}
+}
+
+func TestObjectService_FailedChangeRecordsNoEvent(t *testing.T) {
+	objectRepo := &mockObjectRepository{
+		createFunc: func(ctx context.Context, input *models.CreateObjectRequest) (*models.Object, error) {
+			return nil, errors.New("insert failed")
+		},
+	}
+	objectTypeRepo := &mockObjectTypeRepository{
+		getByIDFunc: func(ctx context.Context, id int64) (*models.ObjectType, error) {
+			return &models.ObjectType{ID: id, Name: "Document"}, nil
+		},
+	}
+	db := newMemoryTxDB(objectRepo, objectTypeRepo)
+	service := NewObjectServiceWithTx(objectRepo, objectTypeRepo, db)
+
+	_, err := service.Create(context.Background(), &models.CreateObjectRequest{ObjectTypeID: 2, Name: "Spec"})
+	require.Error(t, err)
+
+	assert.Empty(t, db.outbox.committed)
+	assert.Equal(t, 0, db.commits)
+	assert.Equal(t, 1, db.rollbacks)
+}
+
+func TestObjectService_BulkDeleteRecordsLiveObjectsOnly(t *testing.T) {
+	deletedAt := time.Now()
+	objects := map[int64]*models.Object{
+		1: {ID: 1, PublicID: uuid.New(), Name: "a"},
+		2: {ID: 2, PublicID: uuid.New(), Name: "b", DeletedAt: &deletedAt},
+	}
+	objectRepo := &mockObjectRepository{
+		getByIDFunc: func(ctx context.Context, id int64) (*models.Object, error) {
+			if object, ok := objects[id]; ok {
+				return object, nil
+			}
+			return nil, repository.ErrNotFound
+		},
+		bulkDeleteFunc: func(ctx context.Context, ids []int64) error { return nil },
+	}
+	db := newMemoryTxDB(objectRepo, &mockObjectTypeRepository{})
+	service := NewObjectServiceWithTx(objectRepo, &mockObjectTypeRepository{}, db)
+
+	require.NoError(t, service.BulkDelete(context.Background(), []int64{1, 2, 3, 1}))
+
+	require.Len(t, db.outbox.committed, 1)
+	assert.Equal(t, "object.deleted", db.outbox.committed[0].EventType)
+	assert.Equal(t, int64(1), db.outbox.committed[0].EntityID)
+}
+
+func TestObjectEvent_RestoreAndActorFallback(t *testing.T) {
+	object := &models.Object{ID: 4, PublicID: uuid.New(), UpdatedBy: "bob"}
+
+	event, err := objectEvent(context.Background(), models.EventActionUpdated, object, object)
+	require.NoError(t, err)
+	assert.Equal(t, "object.updated", event.EventType)
+	assert.Equal(t, "bob", event.Actor)
+
+	ctx := repository.WithChangeContext(context.Background(), repository.ChangeContext{Action: models.HistoryActionRestore})
+	event, err = objectEvent(ctx, models.EventActionUpdated, object, object)
+	require.NoError(t, err)
+	assert.Equal(t, "object.restored", event.EventType)
+
+	event, err = objectTypeEvent(context.Background(), models.EventActionDeleted, &models.ObjectType{ID: 3}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "object_type.deleted", event.EventType)
+	assert.Equal(t, "system", event.Actor)
+	assert.Nil(t, event.EntityPublicID)
+	assert.Empty(t, event.After)
+}
+
+// recordingSink collects published events and fails while err is set
+type recordingSink struct {
+	events []*models.OutboxEvent
+	err    error
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Publish(ctx context.Context, events []*models.OutboxEvent) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func seedOutbox(t *testing.T, db *memoryTxDB, count int) {
+	t.Helper()
+	tx, _ := db.BeginTx(context.Background(), pgx.TxOptions{})
+	for i := 0; i < count; i++ {
+		require.NoError(t, tx.OutboxRepository().Append(context.Background(), &models.OutboxEvent{EventType: "object.created"}))
+	}
+	require.NoError(t, tx.Commit(context.Background()))
+}
+
+func TestOutboxDispatcher_DispatchOnce(t *testing.T) {
+	db := newMemoryTxDB(nil, nil)
+	seedOutbox(t, db, 3)
+	sink := &recordingSink{}
+	dispatcher := NewOutboxDispatcher(db, OutboxDispatcherConfig{BatchSize: 2}, logrus.New(), sink)
+
+	dispatched, err := dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, dispatched)
+
+	dispatched, err = dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, dispatched)
+
+	dispatched, err = dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, dispatched)
+
+	require.Len(t, sink.events, 3)
+	for i, event := range sink.events {
+		assert.Equal(t, int64(i+1), event.Offset)
+	}
+}
+
+func TestOutboxDispatcher_SinkFailureKeepsEventsPending(t *testing.T) {
+	db := newMemoryTxDB(nil, nil)
+	seedOutbox(t, db, 2)
+	sink := &recordingSink{err: errors.New("connection refused")}
+	dispatcher := NewOutboxDispatcher(db, OutboxDispatcherConfig{}, logrus.New(), sink)
+
+	_, err := dispatcher.DispatchOnce(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+	assert.Empty(t, db.outbox.dispatched)
+	assert.Contains(t, db.outbox.failures[1], "sink recording: connection refused")
+
+	sink.err = nil
+	dispatched, err := dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, dispatched)
+}
+
+func TestOutboxDispatcher_SkipsWhileLocked(t *testing.T) {
+	db := newMemoryTxDB(nil, nil)
+	seedOutbox(t, db, 1)
+	db.outbox.locked = true
+	sink := &recordingSink{}
+	dispatcher := NewOutboxDispatcher(db, OutboxDispatcherConfig{}, logrus.New(), sink)
+
+	dispatched, err := dispatcher.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, dispatched)
+	assert.Empty(t, sink.events)
+}
+
+func TestOutboxDispatcher_Backoff(t *testing.T) {
+	dispatcher := NewOutboxDispatcher(nil, OutboxDispatcherConfig{PollInterval: time.Second, MaxBackoff: 5 * time.Second}, logrus.New())
+
+	assert.Equal(t, time.Second, dispatcher.backoff(1))
+	assert.Equal(t, 2*time.Second, dispatcher.backoff(2))
+	assert.Equal(t, 4*time.Second, dispatcher.backoff(3))
+	assert.Equal(t, 5*time.Second, dispatcher.backoff(10))
+}
+
+// listingOutbox serves List from a fixed log
+type listingOutbox struct {
+	repository.OutboxRepository
+	events []*models.OutboxEvent
+}
+
+func (o *listingOutbox) List(ctx context.Context, filter *models.OutboxEventFilter) ([]*models.OutboxEvent, error) {
+	listed := []*models.OutboxEvent{}
+	for _, event := range o.events {
+		if event.Offset > filter.After && len(listed) < filter.Limit {
+			listed = append(listed, event)
+		}
+	}
+	return listed, nil
+}
+
+func TestEventService_List(t *testing.T) {
+	repo := &listingOutbox{}
+	for offset := int64(1); offset <= 5; offset++ {
+		repo.events = append(repo.events, &models.OutboxEvent{Offset: offset})
+	}
+	service := NewEventService(repo)
+
+	page, err := service.List(context.Background(), &models.OutboxEventFilter{After: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Events, 2)
+	assert.Equal(t, int64(3), page.NextOffset)
+	assert.True(t, page.HasMore)
+
+	page, err = service.List(context.Background(), &models.OutboxEventFilter{After: 3})
+	require.NoError(t, err)
+	assert.Len(t, page.Events, 2)
+	assert.Equal(t, int64(5), page.NextOffset)
+	assert.False(t, page.HasMore)
+
+	page, err = service.List(context.Background(), &models.OutboxEventFilter{After: 5})
+	require.NoError(t, err)
+	assert.Empty(t, page.Events)
+	assert.Equal(t, int64(5), page.NextOffset)
+
+	_, err = service.List(context.Background(), &models.OutboxEventFilter{Limit: 5000})
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+	_, err = service.List(context.Background(), &models.OutboxEventFilter{EntityType: "user"})
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+}
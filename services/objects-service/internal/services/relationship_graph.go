@@ -0,0 +1,226 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+var ErrNoPath = errors.New("no path between the objects")
+
+const (
+	defaultGraphDepth = 3
+	defaultPathDepth  = 6
+	maxGraphDepth     = 10
+
+	defaultGraphNodes = 100
+	maxGraphNodes     = 1000
+	maxGraphEdges     = 5000
+)
+
+// Traverse returns the objects reachable from an object within the query's bounds, and the
+// relationships between them
+func (s *relationshipService) Traverse(ctx context.Context, objectPublicID uuid.UUID, query *models.GraphQuery) (*models.Graph, error) {
+	query, err := s.normalizeGraphQuery(ctx, query, defaultGraphDepth)
+	if err != nil {
+		return nil, err
+	}
+	start, err := s.graphObject(ctx, objectPublicID, ErrSourceObjectNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, nodesTruncated, err := s.repo.TraverseNodes(ctx, start.ID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ID
+	}
+	edges, edgesTruncated, err := s.repo.EdgesBetween(ctx, ids, query.TypeKeys, maxGraphEdges)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &models.Graph{
+		Nodes:     nodes,
+		Edges:     edges,
+		Truncated: nodesTruncated || edgesTruncated,
+	}
+	if graph.Nodes == nil {
+		graph.Nodes = []*models.GraphNode{}
+	}
+	if graph.Edges == nil {
+		graph.Edges = []*models.GraphEdge{}
+	}
+	return graph, nil
+}
+
+// ShortestPath finds a path with the fewest hops between two objects
+func (s *relationshipService) ShortestPath(ctx context.Context, sourcePublicID, targetPublicID uuid.UUID, query *models.GraphQuery) (*models.GraphPath, error) {
+	if sourcePublicID == targetPublicID {
+		return nil, fmt.Errorf("%w: %s", ErrSourceTargetSame, repository.ErrInvalidInput)
+	}
+
+	query, err := s.normalizeGraphQuery(ctx, query, defaultPathDepth)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.graphObject(ctx, sourcePublicID, ErrSourceObjectNotFound)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.graphObject(ctx, targetPublicID, ErrTargetObjectNotFound)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := s.repo.ShortestPath(ctx, source.ID, target.ID, query)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w within %d hops", ErrNoPath, query.MaxDepth)
+		}
+		return nil, err
+	}
+	return path, nil
+}
+
+// Reachable returns the objects reachable from an object through relationships of one type,
+// excluding the object itself. It looks as deep as allowed unless the query says otherwise.
+func (s *relationshipService) Reachable(ctx context.Context, objectPublicID uuid.UUID, typeKey string, query *models.GraphQuery) ([]*models.GraphNode, bool, error) {
+	reachable := models.GraphQuery{}
+	if query != nil {
+		reachable = *query
+	}
+	reachable.TypeKeys = []string{typeKey}
+
+	normalized, err := s.normalizeGraphQuery(ctx, &reachable, maxGraphDepth)
+	if err != nil {
+		return nil, false, err
+	}
+	start, err := s.graphObject(ctx, objectPublicID, ErrSourceObjectNotFound)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Ask for one more node to make up for the start object, which is dropped
+	normalized.Limit++
+	nodes, truncated, err := s.repo.TraverseNodes(ctx, start.ID, normalized)
+	if err != nil {
+		return nil, false, err
+	}
+
+	reached := make([]*models.GraphNode, 0, len(nodes))
+	for _, node := range nodes {
+		if node.ID != start.ID {
+			reached = append(reached, node)
+		}
+	}
+	return reached, truncated, nil
+}
+
+// normalizeGraphQuery validates a graph query and fills in its defaults. Type keys may also be
+// given comma-separated.
+func (s *relationshipService) normalizeGraphQuery(ctx context.Context, query *models.GraphQuery, defaultDepth int) (*models.GraphQuery, error) {
+	normalized := models.GraphQuery{}
+	if query != nil {
+		normalized = *query
+	}
+
+	switch normalized.Direction {
+	case "":
+		normalized.Direction = models.DirectionOutgoing
+	case models.DirectionOutgoing, models.DirectionIncoming, models.DirectionBoth:
+	default:
+		return nil, fmt.Errorf("invalid direction %q, expected outgoing, incoming or both: %w", normalized.Direction, repository.ErrInvalidInput)
+	}
+
+	if normalized.MaxDepth == 0 {
+		normalized.MaxDepth = defaultDepth
+	}
+	if normalized.MaxDepth < 1 || normalized.MaxDepth > maxGraphDepth {
+		return nil, fmt.Errorf("max_depth must be between 1 and %d: %w", maxGraphDepth, repository.ErrInvalidInput)
+	}
+
+	if normalized.Limit <= 0 {
+		normalized.Limit = defaultGraphNodes
+	}
+	if normalized.Limit > maxGraphNodes {
+		normalized.Limit = maxGraphNodes
+	}
+
+	if normalized.Status != "" && !models.ValidStatuses[normalized.Status] {
+		return nil, fmt.Errorf("invalid status %q: %w", normalized.Status, repository.ErrInvalidInput)
+	}
+
+	// Relationships of alias types are stored under their primary types, so aliases are walked
+	// as their primary types in the opposite direction
+	typeKeys := []string{}
+	seen := make(map[string]bool)
+	var primaries, aliases []string
+	for _, value := range normalized.TypeKeys {
+		for _, key := range strings.Split(value, ",") {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			relType, err := s.relationshipTypeRepo.GetByTypeKey(ctx, key)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return nil, fmt.Errorf("%w: type_key '%s' not found", ErrRelationshipTypeNotFound, key)
+				}
+				return nil, err
+			}
+			stored, inverted, err := s.storedType(ctx, relType)
+			if err != nil {
+				return nil, err
+			}
+			if inverted {
+				aliases = append(aliases, key)
+			} else {
+				primaries = append(primaries, key)
+			}
+			if !seen[stored.TypeKey] {
+				seen[stored.TypeKey] = true
+				typeKeys = append(typeKeys, stored.TypeKey)
+			}
+		}
+	}
+	normalized.TypeKeys = typeKeys
+
+	if len(aliases) > 0 && normalized.Direction != models.DirectionBoth {
+		if len(primaries) > 0 {
+			return nil, fmt.Errorf("type keys %s are reverse types and cannot be followed %s together with %s: %w",
+				strings.Join(aliases, ", "), normalized.Direction, strings.Join(primaries, ", "), repository.ErrInvalidInput)
+		}
+		if normalized.Direction == models.DirectionOutgoing {
+			normalized.Direction = models.DirectionIncoming
+		} else {
+			normalized.Direction = models.DirectionOutgoing
+		}
+	}
+
+	return &normalized, nil
+}
+
+// graphObject resolves an endpoint of a traversal, reporting a missing object as notFound
+func (s *relationshipService) graphObject(ctx context.Context, publicID uuid.UUID, notFound error) (*models.Object, error) {
+	object, err := s.objectRepo.GetByPublicID(ctx, publicID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %w", notFound, err)
+		}
+		return nil, err
+	}
+	if object.DeletedAt != nil {
+		return nil, fmt.Errorf("%w: object is deleted", notFound)
+	}
+	return object, nil
+}
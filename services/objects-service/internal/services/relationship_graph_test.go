@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+func graphTestObjects() *mockObjectRepository {
+	return &mockObjectRepository{
+		getByPublicIDFunc: func(ctx context.Context, publicID uuid.UUID) (*models.Object, error) {
+			switch publicID {
+			case testSourcePublicID:
+				return &models.Object{ID: testSourceObjectID, PublicID: publicID}, nil
+			case testTargetPublicID:
+				return &models.Object{ID: testTargetObjectID, PublicID: publicID}, nil
+			}
+			return nil, repository.ErrNotFound
+		},
+	}
+}
+
+func TestRelationshipService_Traverse(t *testing.T) {
+	var traversed *models.GraphQuery
+	var edgeIDs []int64
+	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{
+		traverseNodesFunc: func(ctx context.Context, startObjectID int64, query *models.GraphQuery) ([]*models.GraphNode, bool, error) {
+			traversed = query
+			return []*models.GraphNode{{ID: startObjectID}, {ID: 7, Depth: 1}}, false, nil
+		},
+		edgesBetweenFunc: func(ctx context.Context, objectIDs []int64, typeKeys []string, limit int) ([]*models.GraphEdge, bool, error) {
+			edgeIDs = objectIDs
+			return []*models.GraphEdge{{ID: 20, SourceObjectID: testSourceObjectID, TargetObjectID: 7}}, true, nil
+		},
+	}
+	service := NewRelationshipService(mockRelRepo, &mockRelationshipTypeRepository{}, graphTestObjects())
+
+	graph, err := service.Traverse(context.Background(), testSourcePublicID, &models.GraphQuery{
+		TypeKeys: []string{"contains, owns", "contains"},
+		Limit:    5000,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"contains", "owns"}, traversed.TypeKeys)
+	assert.Equal(t, models.DirectionOutgoing, traversed.Direction)
+	assert.Equal(t, defaultGraphDepth, traversed.MaxDepth)
+	assert.Equal(t, maxGraphNodes, traversed.Limit)
+	assert.Equal(t, []int64{testSourceObjectID, 7}, edgeIDs)
+	assert.Len(t, graph.Nodes, 2)
+	assert.Len(t, graph.Edges, 1)
+	assert.True(t, graph.Truncated)
+}
+
+func TestRelationshipService_Traverse_InvalidQuery(t *testing.T) {
+	mockRelTypeRepo := &mockRelationshipTypeRepository{
+		getByTypeKeyFunc: func(ctx context.Context, typeKey string) (*models.RelationshipType, error) {
+			return nil, repository.ErrNotFound
+		},
+	}
+	service := NewRelationshipService(&mockRelationshipRepositoryForRelationshipService{}, mockRelTypeRepo, graphTestObjects())
+
+	tests := []struct {
+		name  string
+		query *models.GraphQuery
+		want  error
+	}{
+		{"unknown direction", &models.GraphQuery{Direction: "sideways"}, repository.ErrInvalidInput},
+		{"depth too large", &models.GraphQuery{MaxDepth: maxGraphDepth + 1}, repository.ErrInvalidInput},
+		{"negative depth", &models.GraphQuery{MaxDepth: -1}, repository.ErrInvalidInput},
+		{"unknown status", &models.GraphQuery{Status: "gone"}, repository.ErrInvalidInput},
+		{"unknown type key", &models.GraphQuery{TypeKeys: []string{"nope"}}, ErrRelationshipTypeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Traverse(context.Background(), testSourcePublicID, tt.query)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+
+	_, err := service.Traverse(context.Background(), uuid.New(), nil)
+	assert.ErrorIs(t, err, ErrSourceObjectNotFound)
+}
+
+func TestRelationshipService_ShortestPath(t *testing.T) {
+	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{}
+	service := NewRelationshipService(mockRelRepo, &mockRelationshipTypeRepository{}, graphTestObjects())
+
+	_, err := service.ShortestPath(context.Background(), testSourcePublicID, testSourcePublicID, nil)
+	assert.ErrorIs(t, err, ErrSourceTargetSame)
+
+	_, err = service.ShortestPath(context.Background(), testSourcePublicID, uuid.New(), nil)
+	assert.ErrorIs(t, err, ErrTargetObjectNotFound)
+
+	_, err = service.ShortestPath(context.Background(), testSourcePublicID, testTargetPublicID, nil)
+	assert.ErrorIs(t, err, ErrNoPath)
+
+	var searched *models.GraphQuery
+	mockRelRepo.shortestPathFunc = func(ctx context.Context, sourceObjectID, targetObjectID int64, query *models.GraphQuery) (*models.GraphPath, error) {
+		searched = query
+		return &models.GraphPath{Length: 1}, nil
+	}
+	path, err := service.ShortestPath(context.Background(), testSourcePublicID, testTargetPublicID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, path.Length)
+	assert.Equal(t, defaultPathDepth, searched.MaxDepth)
+}
+
+func TestRelationshipService_Reachable(t *testing.T) {
+	var traversed *models.GraphQuery
+	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{
+		traverseNodesFunc: func(ctx context.Context, startObjectID int64, query *models.GraphQuery) ([]*models.GraphNode, bool, error) {
+			traversed = query
+			return []*models.GraphNode{{ID: startObjectID}, {ID: 7, Depth: 1}, {ID: 8, Depth: 2}}, true, nil
+		},
+	}
+	service := NewRelationshipService(mockRelRepo, &mockRelationshipTypeRepository{}, graphTestObjects())
+
+	nodes, truncated, err := service.Reachable(context.Background(), testSourcePublicID, "contains", &models.GraphQuery{
+		TypeKeys: []string{"ignored"},
+		Limit:    2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"contains"}, traversed.TypeKeys)
+	assert.Equal(t, maxGraphDepth, traversed.MaxDepth)
+	assert.Equal(t, 3, traversed.Limit)
+	assert.Len(t, nodes, 2)
+	assert.True(t, truncated)
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// A relationship type and the type named by its reverse_type_key form a pair when each names
+// the other. The two types describe one logical edge, which is stored once, under the primary
+// type of the pair: the one created first. The other type is an alias whose relationships are
+// the primary's with source and target swapped.
+
+// pairedRelationshipType returns the type paired with relType, or nil if relType has no reverse
+// type or the reverse type does not name relType back
+func pairedRelationshipType(ctx context.Context, repo repository.RelationshipTypeRepository, relType *models.RelationshipType) (*models.RelationshipType, error) {
+	if relType.ReverseTypeKey == nil || *relType.ReverseTypeKey == "" || *relType.ReverseTypeKey == relType.TypeKey {
+		return nil, nil
+	}
+
+	reverse, err := repo.GetByTypeKey(ctx, *relType.ReverseTypeKey)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if reverse.ReverseTypeKey == nil || *reverse.ReverseTypeKey != relType.TypeKey {
+		return nil, nil
+	}
+	return reverse, nil
+}
+
+// isPrimaryOf reports whether relType is the primary type of its pair with reverse
+func isPrimaryOf(relType, reverse *models.RelationshipType) bool {
+	return reverse == nil || relType.ObjectID < reverse.ObjectID
+}
+
+// storedType returns the type the relationships of relType are stored under, and whether they
+// are stored inverted, that is relType is the alias of a pair
+func (s *relationshipService) storedType(ctx context.Context, relType *models.RelationshipType) (*models.RelationshipType, bool, error) {
+	reverse, err := pairedRelationshipType(ctx, s.relationshipTypeRepo, relType)
+	if err != nil {
+		return nil, false, err
+	}
+	if isPrimaryOf(relType, reverse) {
+		return relType, false, nil
+	}
+	return reverse, true, nil
+}
+
+// storedTypeKey resolves a type key used to read relationships. It returns the key to query and,
+// when typeKey is an alias, the alias type to present the results as. Unknown keys are returned
+// as they are, so reads of them find nothing.
+func (s *relationshipService) storedTypeKey(ctx context.Context, typeKey string) (string, *models.RelationshipType, error) {
+	relType, err := s.relationshipTypeRepo.GetByTypeKey(ctx, typeKey)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return typeKey, nil, nil
+		}
+		return "", nil, err
+	}
+
+	stored, inverted, err := s.storedType(ctx, relType)
+	if err != nil {
+		return "", nil, err
+	}
+	if !inverted {
+		return typeKey, nil, nil
+	}
+	return stored.TypeKey, relType, nil
+}
+
+// invertRelationships presents relationships stored under the primary type of a pair as
+// relationships of its alias
+func invertRelationships(rels []*models.Relationship, alias *models.RelationshipType) []*models.Relationship {
+	inverted := make([]*models.Relationship, len(rels))
+	for i, rel := range rels {
+		inverted[i] = invertRelationship(rel, alias)
+	}
+	return inverted
+}
+
+func invertRelationship(rel *models.Relationship, alias *models.RelationshipType) *models.Relationship {
+	inverted := *rel
+	inverted.SourceObjectID, inverted.TargetObjectID = rel.TargetObjectID, rel.SourceObjectID
+	inverted.SourceObjectPublicID, inverted.TargetObjectPublicID = rel.TargetObjectPublicID, rel.SourceObjectPublicID
+	inverted.RelationshipTypeID = alias.ObjectID
+	inverted.RelationshipTypeKey = alias.TypeKey
+	return &inverted
+}
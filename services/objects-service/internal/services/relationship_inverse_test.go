@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// pairedTypes returns a relationship type repository holding the pair parent_of / child_of, in
+// which parent_of was created first and is the primary type, and the standalone type references
+func pairedTypes() *mockRelationshipTypeRepository {
+	types := map[string]*models.RelationshipType{
+		"parent_of":  {ObjectID: 5, TypeKey: "parent_of", ReverseTypeKey: stringPtr("child_of"), Cardinality: models.CardinalityOneToMany},
+		"child_of":   {ObjectID: 6, TypeKey: "child_of", ReverseTypeKey: stringPtr("parent_of"), Cardinality: models.CardinalityManyToOne},
+		"references": {ObjectID: 7, TypeKey: "references", Cardinality: models.CardinalityManyToMany},
+	}
+	return &mockRelationshipTypeRepository{
+		getByTypeKeyFunc: func(ctx context.Context, typeKey string) (*models.RelationshipType, error) {
+			if relType, ok := types[typeKey]; ok {
+				return relType, nil
+			}
+			return nil, repository.ErrNotFound
+		},
+	}
+}
+
+func TestRelationshipTypeService_Create_CreatesReverseType(t *testing.T) {
+	var created []*models.CreateRelationshipTypeRequest
+	mockRepo := &mockRelationshipTypeRepository{
+		getByTypeKeyFunc: func(ctx context.Context, typeKey string) (*models.RelationshipType, error) {
+			return nil, repository.ErrNotFound
+		},
+		createFunc: func(ctx context.Context, input *models.CreateRelationshipTypeRequest) (*models.RelationshipType, error) {
+			created = append(created, input)
+			return &models.RelationshipType{
+				ObjectID:       int64(len(created)),
+				TypeKey:        input.TypeKey,
+				ReverseTypeKey: input.ReverseTypeKey,
+				Cardinality:    input.Cardinality,
+			}, nil
+		},
+	}
+
+	service := NewRelationshipTypeService(mockRepo)
+	result, err := service.Create(context.Background(), &models.CreateRelationshipTypeRequest{
+		TypeKey:        "contains",
+		ReverseTypeKey: stringPtr("contained_by"),
+		Cardinality:    models.CardinalityOneToMany,
+		CreatedBy:      "alice",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "contains", result.TypeKey)
+
+	require.Len(t, created, 2)
+	reverse := created[1]
+	assert.Equal(t, "contained_by", reverse.TypeKey)
+	assert.Equal(t, "contains", *reverse.ReverseTypeKey)
+	assert.Equal(t, models.CardinalityManyToOne, reverse.Cardinality)
+	assert.Equal(t, -1, reverse.MaxCount)
+	assert.Equal(t, "alice", reverse.CreatedBy)
+}
+
+func TestRelationshipTypeService_Create_PairsExistingReverseType(t *testing.T) {
+	var pairedID int64
+	var pairedWith *string
+	mockRepo := &mockRelationshipTypeRepository{
+		getByTypeKeyFunc: func(ctx context.Context, typeKey string) (*models.RelationshipType, error) {
+			return &models.RelationshipType{ObjectID: 3, TypeKey: typeKey, Cardinality: models.CardinalityManyToOne}, nil
+		},
+		createFunc: func(ctx context.Context, input *models.CreateRelationshipTypeRequest) (*models.RelationshipType, error) {
+			assert.Equal(t, "contains", input.TypeKey, "the existing reverse type is not created again")
+			return &models.RelationshipType{ObjectID: 4, TypeKey: input.TypeKey, ReverseTypeKey: input.ReverseTypeKey, Cardinality: input.Cardinality}, nil
+		},
+		updateFunc: func(ctx context.Context, id int64, input *models.UpdateRelationshipTypeRequest) (*models.RelationshipType, error) {
+			pairedID, pairedWith = id, input.ReverseTypeKey
+			return &models.RelationshipType{ObjectID: id}, nil
+		},
+	}
+
+	service := NewRelationshipTypeService(mockRepo)
+	_, err := service.Create(context.Background(), &models.CreateRelationshipTypeRequest{
+		TypeKey:        "contains",
+		ReverseTypeKey: stringPtr("contained_by"),
+		Cardinality:    models.CardinalityOneToMany,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), pairedID)
+	require.NotNil(t, pairedWith)
+	assert.Equal(t, "contains", *pairedWith)
+}
+
+func TestRelationshipTypeService_Update_ReverseType(t *testing.T) {
+	mockRepo := pairedTypes()
+	updates := map[int64]*models.UpdateRelationshipTypeRequest{}
+	mockRepo.updateFunc = func(ctx context.Context, id int64, input *models.UpdateRelationshipTypeRequest) (*models.RelationshipType, error) {
+		updates[id] = input
+		return &models.RelationshipType{ObjectID: id}, nil
+	}
+	var created *models.CreateRelationshipTypeRequest
+	mockRepo.createFunc = func(ctx context.Context, input *models.CreateRelationshipTypeRequest) (*models.RelationshipType, error) {
+		created = input
+		return &models.RelationshipType{ObjectID: 8, TypeKey: input.TypeKey}, nil
+	}
+
+	service := NewRelationshipTypeService(mockRepo)
+	_, err := service.Update(context.Background(), "parent_of", &models.UpdateRelationshipTypeRequest{
+		ReverseTypeKey: stringPtr("offspring_of"),
+	})
+	require.NoError(t, err)
+
+	// The old reverse type is unpaired and the new one created
+	require.Contains(t, updates, int64(6))
+	assert.Equal(t, "", *updates[6].ReverseTypeKey)
+	require.NotNil(t, created)
+	assert.Equal(t, "offspring_of", created.TypeKey)
+	assert.Equal(t, "parent_of", *created.ReverseTypeKey)
+	assert.Equal(t, models.CardinalityManyToOne, created.Cardinality)
+}
+
+func TestRelationshipTypeService_Update_ReverseTypeWithRelationships(t *testing.T) {
+	mockRepo := pairedTypes()
+	mockRepo.hasRelationshipsFunc = func(ctx context.Context, id int64) (bool, error) {
+		assert.Equal(t, int64(7), id, "the newer type of the pair would become the alias")
+		return true, nil
+	}
+	mockRepo.updateFunc = func(ctx context.Context, id int64, input *models.UpdateRelationshipTypeRequest) (*models.RelationshipType, error) {
+		return &models.RelationshipType{ObjectID: id}, nil
+	}
+
+	service := NewRelationshipTypeService(mockRepo)
+	manyToMany := models.CardinalityManyToMany
+	_, err := service.Update(context.Background(), "parent_of", &models.UpdateRelationshipTypeRequest{
+		ReverseTypeKey: stringPtr("references"),
+		Cardinality:    &manyToMany,
+	})
+	assert.ErrorIs(t, err, ErrInvalidReverseType)
+	assert.Contains(t, err.Error(), "'references' already has relationships")
+}
+
+func TestRelationshipTypeService_Update_MirrorsCardinality(t *testing.T) {
+	mockRepo := pairedTypes()
+	updates := map[int64]*models.UpdateRelationshipTypeRequest{}
+	mockRepo.updateFunc = func(ctx context.Context, id int64, input *models.UpdateRelationshipTypeRequest) (*models.RelationshipType, error) {
+		updates[id] = input
+		return &models.RelationshipType{ObjectID: id}, nil
+	}
+
+	service := NewRelationshipTypeService(mockRepo)
+	oneToOne := models.CardinalityOneToOne
+	_, err := service.Update(context.Background(), "parent_of", &models.UpdateRelationshipTypeRequest{Cardinality: &oneToOne})
+	require.NoError(t, err)
+
+	require.Contains(t, updates, int64(6))
+	assert.Equal(t, models.CardinalityOneToOne, *updates[6].Cardinality)
+	assert.Nil(t, updates[6].ReverseTypeKey)
+}
+
+func TestRelationshipTypeService_Delete_UnpairsReverseType(t *testing.T) {
+	mockRepo := pairedTypes()
+	var unpaired int64
+	mockRepo.updateFunc = func(ctx context.Context, id int64, input *models.UpdateRelationshipTypeRequest) (*models.RelationshipType, error) {
+		assert.Equal(t, "", *input.ReverseTypeKey)
+		unpaired = id
+		return &models.RelationshipType{ObjectID: id}, nil
+	}
+	var deleted int64
+	mockRepo.deleteFunc = func(ctx context.Context, id int64) error {
+		deleted = id
+		return nil
+	}
+
+	service := NewRelationshipTypeService(mockRepo)
+	require.NoError(t, service.Delete(context.Background(), "child_of"))
+	assert.Equal(t, int64(5), unpaired)
+	assert.Equal(t, int64(6), deleted)
+}
+
+func TestRelationshipService_Create_ReverseType(t *testing.T) {
+	var stored *models.CreateRelationshipRequest
+	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{
+		existsFunc: func(ctx context.Context, sourceObjectID, targetObjectID int64, typeObjectID int64) (bool, error) {
+			assert.Equal(t, []int64{testTargetObjectID, testSourceObjectID, 5}, []int64{sourceObjectID, targetObjectID, typeObjectID})
+			return false, nil
+		},
+		createFunc: func(ctx context.Context, input *models.CreateRelationshipRequest) (*models.Relationship, error) {
+			stored = input
+			return &models.Relationship{
+				ObjectID:             10,
+				SourceObjectID:       testTargetObjectID,
+				SourceObjectPublicID: testTargetPublicID,
+				TargetObjectID:       testSourceObjectID,
+				TargetObjectPublicID: testSourcePublicID,
+				RelationshipTypeID:   5,
+				RelationshipTypeKey:  "parent_of",
+			}, nil
+		},
+	}
+
+	service := NewRelationshipService(mockRelRepo, pairedTypes(), graphTestObjects())
+	input := &models.CreateRelationshipRequest{
+		SourceObjectPublicID: testSourcePublicID.String(),
+		TargetObjectPublicID: testTargetPublicID.String(),
+		RelationshipTypeKey:  "child_of",
+	}
+	rel, err := service.Create(context.Background(), input)
+	require.NoError(t, err)
+
+	// Stored once, as the primary type from the other end
+	require.NotNil(t, stored)
+	assert.Equal(t, "parent_of", stored.RelationshipTypeKey)
+	assert.Equal(t, testTargetPublicID.String(), stored.SourceObjectPublicID)
+	assert.Equal(t, testSourcePublicID.String(), stored.TargetObjectPublicID)
+	assert.Equal(t, "child_of", input.RelationshipTypeKey, "the caller's input is left as it was")
+
+	// Returned as asked for
+	assert.Equal(t, "child_of", rel.RelationshipTypeKey)
+	assert.Equal(t, int64(6), rel.RelationshipTypeID)
+	assert.Equal(t, testSourcePublicID, rel.SourceObjectPublicID)
+	assert.Equal(t, testTargetPublicID, rel.TargetObjectPublicID)
+}
+
+func TestRelationshipService_GetForObjectByType_ReverseType(t *testing.T) {
+	stored := &models.Relationship{
+		ObjectID:             10,
+		PublicID:             uuid.New(),
+		SourceObjectID:       testSourceObjectID,
+		SourceObjectPublicID: testSourcePublicID,
+		TargetObjectID:       testTargetObjectID,
+		TargetObjectPublicID: testTargetPublicID,
+		RelationshipTypeID:   5,
+		RelationshipTypeKey:  "parent_of",
+	}
+	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{
+		getForObjectByTypeFunc: func(ctx context.Context, objectPublicID uuid.UUID, typeKey string) ([]*models.Relationship, error) {
+			assert.Equal(t, "parent_of", typeKey)
+			return []*models.Relationship{stored}, nil
+		},
+	}
+	service := NewRelationshipService(mockRelRepo, pairedTypes(), graphTestObjects())
+
+	rels, err := service.GetForObjectByType(context.Background(), testTargetPublicID, "child_of")
+	require.NoError(t, err)
+	require.Len(t, rels, 1)
+	assert.Equal(t, stored.PublicID, rels[0].PublicID)
+	assert.Equal(t, "child_of", rels[0].RelationshipTypeKey)
+	assert.Equal(t, testTargetPublicID, rels[0].SourceObjectPublicID)
+	assert.Equal(t, testSourcePublicID, rels[0].TargetObjectPublicID)
+	assert.Equal(t, testSourcePublicID, stored.SourceObjectPublicID, "the stored relationship is not modified")
+
+	rels, err = service.GetForObjectByType(context.Background(), testTargetPublicID, "parent_of")
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Relationship{stored}, rels)
+}
+
+func TestRelationshipService_List_ReverseType(t *testing.T) {
+	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{
+		listFunc: func(ctx context.Context, filter *models.RelationshipFilter) ([]*models.Relationship, error) {
+			assert.Equal(t, "parent_of", *filter.RelationshipTypeKey)
+			assert.Nil(t, filter.SourceObjectPublicID)
+			require.NotNil(t, filter.TargetObjectPublicID)
+			assert.Equal(t, testSourcePublicID.String(), *filter.TargetObjectPublicID)
+			return []*models.Relationship{{
+				SourceObjectPublicID: testTargetPublicID,
+				TargetObjectPublicID: testSourcePublicID,
+				RelationshipTypeKey:  "parent_of",
+			}}, nil
+		},
+	}
+	service := NewRelationshipService(mockRelRepo, pairedTypes(), graphTestObjects())
+
+	rels, err := service.List(context.Background(), &models.RelationshipFilter{
+		RelationshipTypeKey:  stringPtr("child_of"),
+		SourceObjectPublicID: stringPtr(testSourcePublicID.String()),
+	})
+	require.NoError(t, err)
+	require.Len(t, rels, 1)
+	assert.Equal(t, testSourcePublicID, rels[0].SourceObjectPublicID)
+	assert.Equal(t, "child_of", rels[0].RelationshipTypeKey)
+}
+
+func TestRelationshipService_Traverse_ReverseType(t *testing.T) {
+	var query *models.GraphQuery
+	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{
+		traverseNodesFunc: func(ctx context.Context, startObjectID int64, q *models.GraphQuery) ([]*models.GraphNode, bool, error) {
+			query = q
+			return nil, false, nil
+		},
+	}
+	service := NewRelationshipService(mockRelRepo, pairedTypes(), graphTestObjects())
+
+	_, err := service.Traverse(context.Background(), testSourcePublicID, &models.GraphQuery{TypeKeys: []string{"child_of"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"parent_of"}, query.TypeKeys)
+	assert.Equal(t, models.DirectionIncoming, query.Direction)
+
+	_, err = service.Traverse(context.Background(), testSourcePublicID, &models.GraphQuery{TypeKeys: []string{"child_of,parent_of"}, Direction: models.DirectionBoth})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"parent_of"}, query.TypeKeys)
+	assert.Equal(t, models.DirectionBoth, query.Direction)
+
+	_, err = service.Traverse(context.Background(), testSourcePublicID, &models.GraphQuery{TypeKeys: []string{"child_of,references"}})
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+}
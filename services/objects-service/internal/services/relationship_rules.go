@@ -0,0 +1,358 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+var ErrInvalidValidationRules = errors.New("invalid validation rules")
+
+// RelationshipRulesError is returned when a relationship does not satisfy the validation rules
+// of its type. It wraps repository.ErrInvalidInput.
+type RelationshipRulesError struct {
+	TypeKey string
+	Errors  []models.RelationshipRuleError
+}
+
+func (e *RelationshipRulesError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, ruleErr := range e.Errors {
+		message := ruleErr.Rule + ": " + ruleErr.Message
+		if ruleErr.Path != "" {
+			message = ruleErr.Rule + ": " + ruleErr.Path + ": " + ruleErr.Message
+		}
+		messages = append(messages, message)
+	}
+	return fmt.Sprintf("relationship does not satisfy the validation rules of type '%s': %s", e.TypeKey, strings.Join(messages, "; "))
+}
+
+func (e *RelationshipRulesError) Unwrap() error {
+	return repository.ErrInvalidInput
+}
+
+// relationshipRules are a relationship type's validation rules, checked and ready to apply
+type relationshipRules struct {
+	*models.RelationshipValidationRules
+	schema *jsonschema.Schema
+}
+
+// compileRelationshipRules parses validation rules and checks that they can be applied: statuses
+// must exist, names must not be blank and the metadata schema must compile
+func compileRelationshipRules(raw json.RawMessage) (*relationshipRules, error) {
+	parsed, err := models.ParseRelationshipValidationRules(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, ErrInvalidValidationRules)
+	}
+	rules := &relationshipRules{RelationshipValidationRules: parsed}
+
+	lists := map[string][]string{
+		models.RuleAllowedSourceTypes: parsed.AllowedSourceTypes,
+		models.RuleAllowedTargetTypes: parsed.AllowedTargetTypes,
+		models.RuleRequiredMetadata:   parsed.RequiredMetadata,
+		models.RuleAllowedStatuses:    parsed.AllowedStatuses,
+	}
+	for rule, values := range lists {
+		for _, value := range values {
+			if strings.TrimSpace(value) == "" {
+				return nil, fmt.Errorf("%s: values must not be blank: %w", rule, ErrInvalidValidationRules)
+			}
+		}
+	}
+	for _, status := range parsed.AllowedStatuses {
+		if !models.ValidRelationshipStatuses[status] {
+			return nil, fmt.Errorf("%s: unknown status %q: %w", models.RuleAllowedStatuses, status, ErrInvalidValidationRules)
+		}
+	}
+
+	schema, err := decodeMetadataSchema(parsed.MetadataSchema)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v: %w", models.RuleMetadataSchema, err, ErrInvalidValidationRules)
+	}
+	if schema != nil {
+		if rules.schema, err = compileMetadataSchema(schema); err != nil {
+			return nil, fmt.Errorf("%s: %v: %w", models.RuleMetadataSchema, err, ErrInvalidValidationRules)
+		}
+	}
+	return rules, nil
+}
+
+// validateRulesDocument checks validation rules submitted for a relationship type
+func validateRulesDocument(rules map[string]interface{}) error {
+	if rules == nil {
+		return nil
+	}
+	raw, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("%v: %w", err, ErrInvalidValidationRules)
+	}
+	_, err = compileRelationshipRules(raw)
+	return err
+}
+
+// restrictsObjectTypes reports whether checking the rules needs the object types of the
+// relationship's source and target
+func (r *relationshipRules) restrictsObjectTypes() bool {
+	return len(r.AllowedSourceTypes) > 0 || len(r.AllowedTargetTypes) > 0
+}
+
+// checkEndpoints checks the object types of a relationship's source and target. Each is given
+// as its type name followed by the names of the type's ancestors.
+func (r *relationshipRules) checkEndpoints(sourceTypes, targetTypes []string) []models.RelationshipRuleError {
+	var ruleErrors []models.RelationshipRuleError
+	if !typeAllowed(sourceTypes, r.AllowedSourceTypes) {
+		ruleErrors = append(ruleErrors, models.RelationshipRuleError{
+			Rule:    models.RuleAllowedSourceTypes,
+			Message: disallowedTypeMessage("source", sourceTypes, r.AllowedSourceTypes),
+		})
+	}
+	if !typeAllowed(targetTypes, r.AllowedTargetTypes) {
+		ruleErrors = append(ruleErrors, models.RelationshipRuleError{
+			Rule:    models.RuleAllowedTargetTypes,
+			Message: disallowedTypeMessage("target", targetTypes, r.AllowedTargetTypes),
+		})
+	}
+	return ruleErrors
+}
+
+func typeAllowed(lineage, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, name := range lineage {
+		for _, allowedName := range allowed {
+			if name == allowedName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func disallowedTypeMessage(end string, lineage, allowed []string) string {
+	typeName := "unknown"
+	if len(lineage) > 0 {
+		typeName = lineage[0]
+	}
+	return fmt.Sprintf("%s object type '%s' is not %s or a subtype of one", end, typeName, strings.Join(allowed, ", "))
+}
+
+// checkStatus checks a relationship status against allowed_statuses
+func (r *relationshipRules) checkStatus(status string) []models.RelationshipRuleError {
+	if len(r.AllowedStatuses) == 0 {
+		return nil
+	}
+	for _, allowed := range r.AllowedStatuses {
+		if status == allowed {
+			return nil
+		}
+	}
+	return []models.RelationshipRuleError{{
+		Rule:    models.RuleAllowedStatuses,
+		Message: fmt.Sprintf("status '%s' is not one of %s", status, strings.Join(r.AllowedStatuses, ", ")),
+	}}
+}
+
+// checkMetadata checks relationship_metadata against required_metadata and metadata_schema
+func (r *relationshipRules) checkMetadata(raw json.RawMessage) ([]models.RelationshipRuleError, error) {
+	if len(r.RequiredMetadata) == 0 && r.schema == nil {
+		return nil, nil
+	}
+
+	metadata := map[string]interface{}{}
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) {
+		if err := json.Unmarshal(trimmed, &metadata); err != nil {
+			return nil, fmt.Errorf("metadata must be a JSON object: %w", repository.ErrInvalidInput)
+		}
+	}
+
+	var ruleErrors []models.RelationshipRuleError
+	for _, key := range r.RequiredMetadata {
+		if _, ok := metadata[key]; !ok {
+			ruleErrors = append(ruleErrors, models.RelationshipRuleError{
+				Rule:    models.RuleRequiredMetadata,
+				Path:    "/" + key,
+				Message: "missing required metadata key",
+			})
+		}
+	}
+
+	if r.schema != nil {
+		fieldErrors, err := validateMetadataAgainst(r.schema, metadata)
+		if err != nil {
+			return nil, err
+		}
+		for _, fieldErr := range fieldErrors {
+			ruleErrors = append(ruleErrors, models.RelationshipRuleError{
+				Rule:    models.RuleMetadataSchema,
+				Path:    fieldErr.Path,
+				Message: fieldErr.Message,
+			})
+		}
+	}
+	return ruleErrors, nil
+}
+
+// typeRules compiles the stored validation rules of a relationship type
+func typeRules(relType *models.RelationshipType) (*relationshipRules, error) {
+	rules, err := compileRelationshipRules(relType.ValidationRules)
+	if err != nil {
+		return nil, fmt.Errorf("relationship type '%s': %w", relType.TypeKey, err)
+	}
+	return rules, nil
+}
+
+// validateNewRelationship checks a relationship about to be created against its type's rules
+func (s *relationshipService) validateNewRelationship(ctx context.Context, relType *models.RelationshipType, source, target *models.Object, input *models.CreateRelationshipRequest) error {
+	rules, err := typeRules(relType)
+	if err != nil {
+		return err
+	}
+
+	var ruleErrors []models.RelationshipRuleError
+	if rules.restrictsObjectTypes() {
+		names, err := s.repo.ObjectTypeNames(ctx, []int64{source.ID, target.ID})
+		if err != nil {
+			return err
+		}
+		ruleErrors = append(ruleErrors, rules.checkEndpoints(names[source.ID], names[target.ID])...)
+	}
+	ruleErrors = append(ruleErrors, rules.checkStatus(input.Status)...)
+	metadataErrors, err := rules.checkMetadata(input.RelationshipMetadata)
+	if err != nil {
+		return err
+	}
+	ruleErrors = append(ruleErrors, metadataErrors...)
+
+	if len(ruleErrors) > 0 {
+		return &RelationshipRulesError{TypeKey: relType.TypeKey, Errors: ruleErrors}
+	}
+	return nil
+}
+
+// validateRelationshipUpdate checks the status and metadata an update sets against the rules of
+// the relationship's type. Source and target cannot change, so they are not checked again.
+func (s *relationshipService) validateRelationshipUpdate(ctx context.Context, rel *models.Relationship, input *models.UpdateRelationshipRequest) error {
+	if input.Status == nil && input.RelationshipMetadata == nil {
+		return nil
+	}
+
+	relType, err := s.relationshipTypeRepo.GetByID(ctx, rel.RelationshipTypeID)
+	if err != nil {
+		return fmt.Errorf("failed to get relationship type: %w", err)
+	}
+	rules, err := typeRules(relType)
+	if err != nil {
+		return err
+	}
+
+	var ruleErrors []models.RelationshipRuleError
+	if input.Status != nil {
+		ruleErrors = append(ruleErrors, rules.checkStatus(*input.Status)...)
+	}
+	if input.RelationshipMetadata != nil {
+		metadataErrors, err := rules.checkMetadata(input.RelationshipMetadata)
+		if err != nil {
+			return err
+		}
+		ruleErrors = append(ruleErrors, metadataErrors...)
+	}
+
+	if len(ruleErrors) > 0 {
+		return &RelationshipRulesError{TypeKey: relType.TypeKey, Errors: ruleErrors}
+	}
+	return nil
+}
+
+// ValidateExistingRules checks every relationship of a type against the type's validation
+// rules. Non-empty candidate rules replace the stored ones, so a change can be tried out
+// before it is saved.
+func (s *relationshipService) ValidateExistingRules(ctx context.Context, typeKey string, candidate json.RawMessage) (*models.RelationshipValidationReport, error) {
+	relType, err := s.relationshipTypeRepo.GetByTypeKey(ctx, typeKey)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: type_key '%s' not found", ErrRelationshipTypeNotFound, typeKey)
+		}
+		return nil, err
+	}
+
+	raw := relType.ValidationRules
+	if len(bytes.TrimSpace(candidate)) > 0 {
+		raw = candidate
+	}
+	rules, err := compileRelationshipRules(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.RelationshipValidationReport{TypeKey: typeKey, Violations: []models.RelationshipRuleViolation{}}
+	filter := &models.RelationshipFilter{
+		RelationshipTypeKey: &typeKey,
+		PageSize:            metadataValidationPageSize,
+		SortBy:              "r.object_id",
+		SortOrder:           "asc",
+	}
+	for page := 1; ; page++ {
+		filter.Page = page
+		rels, err := s.repo.List(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list relationships: %w", err)
+		}
+
+		var names map[int64][]string
+		if rules.restrictsObjectTypes() && len(rels) > 0 {
+			ids := make([]int64, 0, 2*len(rels))
+			for _, rel := range rels {
+				ids = append(ids, rel.SourceObjectID, rel.TargetObjectID)
+			}
+			if names, err = s.repo.ObjectTypeNames(ctx, ids); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, rel := range rels {
+			report.Checked++
+			var ruleErrors []models.RelationshipRuleError
+			if names != nil {
+				ruleErrors = append(ruleErrors, rules.checkEndpoints(names[rel.SourceObjectID], names[rel.TargetObjectID])...)
+			}
+			ruleErrors = append(ruleErrors, rules.checkStatus(rel.Status)...)
+			metadataErrors, err := rules.checkMetadata(rel.RelationshipMetadata)
+			if err != nil {
+				// Stored metadata that is not an object is reported rather than failing the run
+				metadataErrors = []models.RelationshipRuleError{{Rule: models.RuleMetadataSchema, Message: err.Error()}}
+			}
+			ruleErrors = append(ruleErrors, metadataErrors...)
+
+			if len(ruleErrors) == 0 {
+				continue
+			}
+			report.Invalid++
+			if len(report.Violations) >= maxReportedViolations {
+				report.Truncated = true
+				continue
+			}
+			report.Violations = append(report.Violations, models.RelationshipRuleViolation{
+				ObjectID:             rel.ObjectID,
+				PublicID:             rel.PublicID,
+				SourceObjectPublicID: rel.SourceObjectPublicID,
+				TargetObjectPublicID: rel.TargetObjectPublicID,
+				Errors:               ruleErrors,
+			})
+		}
+
+		if len(rels) < filter.PageSize {
+			break
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+const testRules = `{
+	"allowed_source_types": ["Warehouse"],
+	"allowed_target_types": ["Product"],
+	"required_metadata": ["quantity"],
+	"metadata_schema": {"properties": {"quantity": {"type": "integer", "minimum": 1}}},
+	"allowed_statuses": ["active", "inactive"]
+}`
+
+func rulesTestService(rules string, rels ...*models.Relationship) (RelationshipService, *mockRelationshipRepositoryForRelationshipService) {
+	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{
+		objectTypeNamesFunc: func(ctx context.Context, objectIDs []int64) (map[int64][]string, error) {
+			return map[int64][]string{
+				testSourceObjectID: {"Cold Storage", "Warehouse", "Location"},
+				testTargetObjectID: {"Service", "Offering"},
+			}, nil
+		},
+		listFunc: func(ctx context.Context, filter *models.RelationshipFilter) ([]*models.Relationship, error) {
+			if filter.Page > 1 {
+				return nil, nil
+			}
+			return rels, nil
+		},
+	}
+	relType := &models.RelationshipType{
+		ObjectID:        testRelTypeObjectID,
+		TypeKey:         "stocks",
+		Cardinality:     models.CardinalityManyToMany,
+		ValidationRules: json.RawMessage(rules),
+	}
+	mockRelTypeRepo := &mockRelationshipTypeRepository{
+		getByTypeKeyFunc: func(ctx context.Context, typeKey string) (*models.RelationshipType, error) {
+			return relType, nil
+		},
+		getByIDFunc: func(ctx context.Context, id int64) (*models.RelationshipType, error) {
+			return relType, nil
+		},
+	}
+	return NewRelationshipService(mockRelRepo, mockRelTypeRepo, graphTestObjects()), mockRelRepo
+}
+
+func TestCompileRelationshipRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{"unknown key", `{"allowed_source_type": ["Warehouse"]}`},
+		{"unknown status", `{"allowed_statuses": ["archived"]}`},
+		{"blank type name", `{"allowed_target_types": [" "]}`},
+		{"schema not an object", `{"metadata_schema": []}`},
+		{"schema does not compile", `{"metadata_schema": {"type": 5}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := compileRelationshipRules(json.RawMessage(tt.rules))
+			assert.ErrorIs(t, err, ErrInvalidValidationRules)
+		})
+	}
+
+	for _, empty := range []string{"", "null", "{}"} {
+		rules, err := compileRelationshipRules(json.RawMessage(empty))
+		require.NoError(t, err)
+		assert.False(t, rules.restrictsObjectTypes())
+	}
+}
+
+func TestRelationshipService_Create_ValidationRules(t *testing.T) {
+	service, mockRelRepo := rulesTestService(testRules)
+	created := false
+	mockRelRepo.createFunc = func(ctx context.Context, input *models.CreateRelationshipRequest) (*models.Relationship, error) {
+		created = true
+		return &models.Relationship{}, nil
+	}
+
+	_, err := service.Create(context.Background(), &models.CreateRelationshipRequest{
+		SourceObjectPublicID: testSourcePublicID.String(),
+		TargetObjectPublicID: testTargetPublicID.String(),
+		RelationshipTypeKey:  "stocks",
+		Status:               "deprecated",
+		RelationshipMetadata: json.RawMessage(`{"quantity": 0}`),
+	})
+
+	var rulesErr *RelationshipRulesError
+	require.ErrorAs(t, err, &rulesErr)
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+	assert.False(t, created)
+
+	rules := make([]string, len(rulesErr.Errors))
+	for i, ruleErr := range rulesErr.Errors {
+		rules[i] = ruleErr.Rule
+	}
+	// The source is a subtype of Warehouse and passes; the target is not a Product
+	assert.Equal(t, []string{models.RuleAllowedTargetTypes, models.RuleAllowedStatuses, models.RuleMetadataSchema}, rules)
+	assert.Equal(t, "/quantity", rulesErr.Errors[2].Path)
+	assert.Contains(t, rulesErr.Errors[0].Message, "'Service'")
+}
+
+func TestRelationshipService_Create_ValidationRulesPass(t *testing.T) {
+	service, mockRelRepo := rulesTestService(`{"allowed_source_types": ["Location"], "required_metadata": ["quantity"]}`)
+	mockRelRepo.objectTypeNamesFunc = func(ctx context.Context, objectIDs []int64) (map[int64][]string, error) {
+		assert.Equal(t, []int64{testSourceObjectID, testTargetObjectID}, objectIDs)
+		return map[int64][]string{testSourceObjectID: {"Warehouse", "Location"}}, nil
+	}
+
+	_, err := service.Create(context.Background(), &models.CreateRelationshipRequest{
+		SourceObjectPublicID: testSourcePublicID.String(),
+		TargetObjectPublicID: testTargetPublicID.String(),
+		RelationshipTypeKey:  "stocks",
+		RelationshipMetadata: json.RawMessage(`{"quantity": 3}`),
+	})
+	assert.NoError(t, err)
+
+	_, err = service.Create(context.Background(), &models.CreateRelationshipRequest{
+		SourceObjectPublicID: testSourcePublicID.String(),
+		TargetObjectPublicID: testTargetPublicID.String(),
+		RelationshipTypeKey:  "stocks",
+	})
+	var rulesErr *RelationshipRulesError
+	require.ErrorAs(t, err, &rulesErr)
+	assert.Equal(t, []models.RelationshipRuleError{{
+		Rule:    models.RuleRequiredMetadata,
+		Path:    "/quantity",
+		Message: "missing required metadata key",
+	}}, rulesErr.Errors)
+}
+
+func TestRelationshipService_Update_ValidationRules(t *testing.T) {
+	service, mockRelRepo := rulesTestService(testRules)
+	mockRelRepo.getByObjectIDFunc = func(ctx context.Context, objectID int64) (*models.Relationship, error) {
+		return &models.Relationship{ObjectID: objectID, RelationshipTypeID: testRelTypeObjectID}, nil
+	}
+	mockRelRepo.objectTypeNamesFunc = func(ctx context.Context, objectIDs []int64) (map[int64][]string, error) {
+		t.Fatal("source and target types are not checked on update")
+		return nil, nil
+	}
+
+	deprecated := "deprecated"
+	_, err := service.Update(context.Background(), testSourcePublicID, &models.UpdateRelationshipRequest{Status: &deprecated})
+	var rulesErr *RelationshipRulesError
+	require.ErrorAs(t, err, &rulesErr)
+	assert.Equal(t, models.RuleAllowedStatuses, rulesErr.Errors[0].Rule)
+
+	_, err = service.Update(context.Background(), testSourcePublicID, &models.UpdateRelationshipRequest{
+		RelationshipMetadata: json.RawMessage(`{"quantity": 2}`),
+	})
+	assert.NoError(t, err)
+
+	_, err = service.Update(context.Background(), testSourcePublicID, &models.UpdateRelationshipRequest{
+		RelationshipMetadata: json.RawMessage(`[1]`),
+	})
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+}
+
+func TestRelationshipService_ValidateExistingRules(t *testing.T) {
+	valid := &models.Relationship{
+		ObjectID:             10,
+		PublicID:             uuid.New(),
+		SourceObjectID:       testSourceObjectID,
+		TargetObjectID:       testSourceObjectID,
+		Status:               "active",
+		RelationshipMetadata: json.RawMessage(`{"quantity": 4}`),
+	}
+	invalid := &models.Relationship{
+		ObjectID:             11,
+		PublicID:             uuid.New(),
+		SourceObjectID:       testTargetObjectID,
+		TargetObjectID:       testSourceObjectID,
+		Status:               "active",
+		RelationshipMetadata: json.RawMessage(`{}`),
+	}
+	service, _ := rulesTestService(`{"allowed_source_types": ["Warehouse"], "required_metadata": ["quantity"]}`, valid, invalid)
+
+	report, err := service.ValidateExistingRules(context.Background(), "stocks", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "stocks", report.TypeKey)
+	assert.Equal(t, 2, report.Checked)
+	assert.Equal(t, 1, report.Invalid)
+	require.Len(t, report.Violations, 1)
+	assert.Equal(t, invalid.PublicID, report.Violations[0].PublicID)
+	assert.Len(t, report.Violations[0].Errors, 2)
+
+	// Candidate rules replace the stored ones
+	report, err = service.ValidateExistingRules(context.Background(), "stocks", json.RawMessage(`{"allowed_statuses": ["active"]}`))
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Invalid)
+
+	_, err = service.ValidateExistingRules(context.Background(), "stocks", json.RawMessage(`{"bogus": true}`))
+	assert.ErrorIs(t, err, ErrInvalidValidationRules)
+}
+
+func TestRelationshipTypeService_ValidationRules(t *testing.T) {
+	service := NewRelationshipTypeService(&mockRelationshipTypeRepository{})
+
+	_, err := service.Create(context.Background(), &models.CreateRelationshipTypeRequest{
+		TypeKey:         "stocks",
+		Cardinality:     models.CardinalityManyToMany,
+		ValidationRules: map[string]interface{}{"allowed_statuses": []interface{}{"gone"}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidValidationRules)
+
+	_, err = service.Create(context.Background(), &models.CreateRelationshipTypeRequest{
+		TypeKey:         "stocks",
+		Cardinality:     models.CardinalityManyToMany,
+		ValidationRules: map[string]interface{}{"allowed_source_types": []interface{}{"Warehouse"}},
+	})
+	assert.NoError(t, err)
+
+	rules := map[string]interface{}{"metadata_schema": "not a schema"}
+	_, err = service.Update(context.Background(), "stocks", &models.UpdateRelationshipTypeRequest{ValidationRules: &rules})
+	assert.ErrorIs(t, err, ErrInvalidValidationRules)
+}
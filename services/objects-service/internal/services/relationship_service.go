@@ -2,10 +2,12 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
 	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
 )
@@ -35,20 +37,71 @@ type RelationshipService interface {
 	GetForObject(ctx context.Context, objectPublicID uuid.UUID, filter *models.RelationshipFilterForType) ([]*models.Relationship, error)
 	GetForObjectByType(ctx context.Context, objectPublicID uuid.UUID, typeKey string) ([]*models.Relationship, error)
 	GetRelatedObjects(ctx context.Context, objectPublicID uuid.UUID, typeKey *string) ([]*models.Object, error)
+
+	Traverse(ctx context.Context, objectPublicID uuid.UUID, query *models.GraphQuery) (*models.Graph, error)
+	ShortestPath(ctx context.Context, sourcePublicID, targetPublicID uuid.UUID, query *models.GraphQuery) (*models.GraphPath, error)
+	Reachable(ctx context.Context, objectPublicID uuid.UUID, typeKey string, query *models.GraphQuery) ([]*models.GraphNode, bool, error)
+
+	ValidateExistingRules(ctx context.Context, typeKey string, candidate json.RawMessage) (*models.RelationshipValidationReport, error)
 }
 
 type relationshipService struct {
 	repo                 repository.RelationshipRepository
 	relationshipTypeRepo repository.RelationshipTypeRepository
 	objectRepo           repository.ObjectRepository
+	txDB                 TxBeginner
+	outbox               repository.OutboxRepository
+}
+
+// relationshipTxOptions runs relationship changes at READ COMMITTED: every statement issued
+// after the advisory locks are taken sees what the previous lock holders committed, which a
+// serializable snapshot taken before waiting for the locks would not
+var relationshipTxOptions = pgx.TxOptions{
+	IsoLevel:   pgx.ReadCommitted,
+	AccessMode: pgx.ReadWrite,
 }
 
 func NewRelationshipService(repo repository.RelationshipRepository, relTypeRepo repository.RelationshipTypeRepository, objectRepo repository.ObjectRepository) RelationshipService {
+	return NewRelationshipServiceWithTx(repo, relTypeRepo, objectRepo, nil)
+}
+
+// NewRelationshipServiceWithTx creates a relationship service that creates, updates and deletes
+// relationships inside transactions started by txDB, so cardinality checks and the changes they
+// guard are atomic. Without txDB the same steps run directly against the given repositories.
+func NewRelationshipServiceWithTx(repo repository.RelationshipRepository, relTypeRepo repository.RelationshipTypeRepository, objectRepo repository.ObjectRepository, txDB TxBeginner) RelationshipService {
 	return &relationshipService{
 		repo:                 repo,
 		relationshipTypeRepo: relTypeRepo,
 		objectRepo:           objectRepo,
+		txDB:                 txDB,
+	}
+}
+
+// withinTx runs fn with a service whose repositories are bound to a single transaction
+func (s *relationshipService) withinTx(ctx context.Context, fn func(tx *relationshipService) error) error {
+	if s.txDB == nil {
+		return fn(s)
 	}
+	return WithinTxOptions(ctx, s.txDB, relationshipTxOptions, func(tx Transaction) error {
+		return fn(&relationshipService{
+			repo:                 tx.RelationshipRepository(),
+			relationshipTypeRepo: tx.RelationshipTypeRepository(),
+			objectRepo:           tx.ObjectRepository(),
+			outbox:               tx.OutboxRepository(),
+		})
+	})
+}
+
+// recordEvent appends the event of an action on a relationship to the outbox of the transaction
+func (s *relationshipService) recordEvent(ctx context.Context, action string, before, after *models.Relationship) error {
+	if s.outbox == nil {
+		return nil
+	}
+	event, err := relationshipEvent(ctx, action, before, after)
+	if err != nil {
+		return err
+	}
+	return s.outbox.Append(ctx, event)
 }
 
 func (s *relationshipService) Create(ctx context.Context, input *models.CreateRelationshipRequest) (*models.Relationship, error) {
@@ -72,6 +125,21 @@ func (s *relationshipService) Create(ctx context.Context, input *models.CreateRe
 		return nil, fmt.Errorf("%w: %s", ErrSourceTargetSame, repository.ErrInvalidInput)
 	}
 
+	var created *models.Relationship
+	err = s.withinTx(ctx, func(tx *relationshipService) error {
+		var err error
+		created, err = tx.create(ctx, input, sourcePublicID, targetPublicID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// create checks and inserts a relationship while holding the locks of both its objects, so no
+// concurrent change can invalidate the checks before the insert is committed
+func (s *relationshipService) create(ctx context.Context, input *models.CreateRelationshipRequest, sourcePublicID, targetPublicID uuid.UUID) (*models.Relationship, error) {
 	sourceObject, err := s.objectRepo.GetByPublicID(ctx, sourcePublicID)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
@@ -93,6 +161,36 @@ func (s *relationshipService) Create(ctx context.Context, input *models.CreateRe
 		return nil, fmt.Errorf("%w: type_key '%s' not found", ErrRelationshipTypeNotFound, input.RelationshipTypeKey)
 	}
 
+	// A relationship of an alias type is stored inverted under the primary type, whose
+	// cardinality and validation rules it is checked against
+	alias := relType
+	relType, inverted, err := s.storedType(ctx, relType)
+	if err != nil {
+		return nil, err
+	}
+	if inverted {
+		stored := *input
+		stored.SourceObjectPublicID, stored.TargetObjectPublicID = input.TargetObjectPublicID, input.SourceObjectPublicID
+		stored.RelationshipTypeKey = relType.TypeKey
+		input = &stored
+		sourceObject, targetObject = targetObject, sourceObject
+	}
+
+	if err := s.validateNewRelationship(ctx, relType, sourceObject, targetObject, input); err != nil {
+		return nil, err
+	}
+
+	// A cycle can be closed through objects other than these two, so types that forbid cycles
+	// are locked as a whole
+	if relType.Cardinality == models.CardinalityOneToMany || relType.Cardinality == models.CardinalityManyToOne {
+		if err := s.repo.LockType(ctx, relType.ObjectID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.repo.LockObjects(ctx, relType.ObjectID, sourceObject.ID, targetObject.ID); err != nil {
+		return nil, err
+	}
+
 	exists, err := s.repo.Exists(ctx, sourceObject.ID, targetObject.ID, relType.ObjectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check relationship existence: %w", err)
@@ -113,37 +211,69 @@ func (s *relationshipService) Create(ctx context.Context, input *models.CreateRe
 		return nil, err
 	}
 
-	return s.repo.Create(ctx, input)
+	created, err := s.repo.Create(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.recordEvent(ctx, models.EventActionCreated, nil, created); err != nil {
+		return nil, err
+	}
+	if !inverted {
+		return created, nil
+	}
+	return invertRelationship(created, alias), nil
 }
 
+// validateCardinality checks that one more relationship between the objects stays within the
+// type's cardinality and max_count. The "one" side of a cardinality may take part in a single
+// relationship of the type; max_count bounds the "many" side, which is the source for
+// many_to_many.
 func (s *relationshipService) validateCardinality(ctx context.Context, sourceObjectID, targetObjectID int64, relType *models.RelationshipType) error {
 	switch relType.Cardinality {
 	case models.CardinalityOneToOne:
-		srcCount, _ := s.repo.CountForObject(ctx, sourceObjectID, &relType.TypeKey)
-		tgtCount, _ := s.repo.CountForObject(ctx, targetObjectID, &relType.TypeKey)
+		srcCount, err := s.repo.CountInDirection(ctx, sourceObjectID, relType.ObjectID, models.DirectionOutgoing)
+		if err != nil {
+			return err
+		}
+		tgtCount, err := s.repo.CountInDirection(ctx, targetObjectID, relType.ObjectID, models.DirectionIncoming)
+		if err != nil {
+			return err
+		}
 		if srcCount >= 1 || tgtCount >= 1 {
 			return fmt.Errorf("%w: one_to_one cardinality violated", ErrCardinalityViolation)
 		}
 
 	case models.CardinalityOneToMany:
-		tgtCount, _ := s.repo.CountForObject(ctx, targetObjectID, &relType.TypeKey)
+		tgtCount, err := s.repo.CountInDirection(ctx, targetObjectID, relType.ObjectID, models.DirectionIncoming)
+		if err != nil {
+			return err
+		}
 		if tgtCount >= 1 {
 			return fmt.Errorf("%w: one_to_many target already has source", ErrCardinalityViolation)
 		}
 		if relType.MaxCount > 0 {
-			srcCount, _ := s.repo.CountForObject(ctx, sourceObjectID, &relType.TypeKey)
+			srcCount, err := s.repo.CountInDirection(ctx, sourceObjectID, relType.ObjectID, models.DirectionOutgoing)
+			if err != nil {
+				return err
+			}
 			if srcCount >= relType.MaxCount {
 				return fmt.Errorf("%w: source reached max_count %d", ErrCardinalityViolation, relType.MaxCount)
 			}
 		}
 
 	case models.CardinalityManyToOne:
-		srcCount, _ := s.repo.CountForObject(ctx, sourceObjectID, &relType.TypeKey)
+		srcCount, err := s.repo.CountInDirection(ctx, sourceObjectID, relType.ObjectID, models.DirectionOutgoing)
+		if err != nil {
+			return err
+		}
 		if srcCount >= 1 {
 			return fmt.Errorf("%w: many_to_one source already has target", ErrCardinalityViolation)
 		}
 		if relType.MaxCount > 0 {
-			tgtCount, _ := s.repo.CountForObject(ctx, targetObjectID, &relType.TypeKey)
+			tgtCount, err := s.repo.CountInDirection(ctx, targetObjectID, relType.ObjectID, models.DirectionIncoming)
+			if err != nil {
+				return err
+			}
 			if tgtCount >= relType.MaxCount {
 				return fmt.Errorf("%w: target reached max_count %d", ErrCardinalityViolation, relType.MaxCount)
 			}
@@ -151,7 +281,10 @@ func (s *relationshipService) validateCardinality(ctx context.Context, sourceObj
 
 	case models.CardinalityManyToMany:
 		if relType.MaxCount > 0 {
-			srcCount, _ := s.repo.CountForObject(ctx, sourceObjectID, &relType.TypeKey)
+			srcCount, err := s.repo.CountInDirection(ctx, sourceObjectID, relType.ObjectID, models.DirectionOutgoing)
+			if err != nil {
+				return err
+			}
 			if srcCount >= relType.MaxCount {
 				return fmt.Errorf("%w: source reached max_count %d", ErrCardinalityViolation, relType.MaxCount)
 			}
@@ -161,6 +294,27 @@ func (s *relationshipService) validateCardinality(ctx context.Context, sourceObj
 	return nil
 }
 
+// validateRemoval checks that the source of a relationship keeps the type's minimum number of
+// relationships once it is removed. A required type has a minimum of at least one.
+func (s *relationshipService) validateRemoval(ctx context.Context, rel *models.Relationship, relType *models.RelationshipType) error {
+	minCount := relType.MinCount
+	if relType.Required && minCount < 1 {
+		minCount = 1
+	}
+	if minCount == 0 {
+		return nil
+	}
+
+	srcCount, err := s.repo.CountInDirection(ctx, rel.SourceObjectID, relType.ObjectID, models.DirectionOutgoing)
+	if err != nil {
+		return err
+	}
+	if srcCount <= minCount {
+		return fmt.Errorf("%w: source must keep at least %d '%s' relationships", ErrCardinalityViolation, minCount, relType.TypeKey)
+	}
+	return nil
+}
+
 func (s *relationshipService) GetByPublicID(ctx context.Context, publicID uuid.UUID) (*models.Relationship, error) {
 	rel, err := s.repo.GetByPublicID(ctx, publicID)
 	if err != nil {
@@ -173,6 +327,53 @@ func (s *relationshipService) GetByPublicID(ctx context.Context, publicID uuid.U
 }
 
 func (s *relationshipService) Update(ctx context.Context, publicID uuid.UUID, input *models.UpdateRelationshipRequest) (*models.Relationship, error) {
+	var updated *models.Relationship
+	err := s.withinTx(ctx, func(tx *relationshipService) error {
+		rel, err := tx.lockRelationship(ctx, publicID)
+		if err != nil {
+			return err
+		}
+		if err := tx.validateRelationshipUpdate(ctx, rel, input); err != nil {
+			return err
+		}
+
+		updated, err = tx.repo.Update(ctx, rel.ObjectID, input)
+		if err != nil {
+			return err
+		}
+		return tx.recordEvent(ctx, models.EventActionUpdated, rel, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *relationshipService) Delete(ctx context.Context, publicID uuid.UUID) error {
+	return s.withinTx(ctx, func(tx *relationshipService) error {
+		rel, err := tx.lockRelationship(ctx, publicID)
+		if err != nil {
+			return err
+		}
+
+		relType, err := tx.relationshipTypeRepo.GetByID(ctx, rel.RelationshipTypeID)
+		if err != nil {
+			return fmt.Errorf("failed to get relationship type: %w", err)
+		}
+		if err := tx.validateRemoval(ctx, rel, relType); err != nil {
+			return err
+		}
+
+		if err := tx.repo.Delete(ctx, rel.ObjectID); err != nil {
+			return err
+		}
+		return tx.recordEvent(ctx, models.EventActionDeleted, rel, nil)
+	})
+}
+
+// lockRelationship takes the locks of a relationship's objects and returns the relationship as
+// read once they are held
+func (s *relationshipService) lockRelationship(ctx context.Context, publicID uuid.UUID) (*models.Relationship, error) {
 	rel, err := s.repo.GetByPublicID(ctx, publicID)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
@@ -181,27 +382,45 @@ func (s *relationshipService) Update(ctx context.Context, publicID uuid.UUID, in
 		return nil, err
 	}
 
-	objectID := rel.ObjectID
-	return s.repo.Update(ctx, objectID, input)
-}
+	if err := s.repo.LockObjects(ctx, rel.RelationshipTypeID, rel.SourceObjectID, rel.TargetObjectID); err != nil {
+		return nil, err
+	}
 
-func (s *relationshipService) Delete(ctx context.Context, publicID uuid.UUID) error {
-	rel, err := s.repo.GetByPublicID(ctx, publicID)
+	// Another transaction may have removed the relationship while the locks were awaited
+	rel, err = s.repo.GetByObjectID(ctx, rel.ObjectID)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
-			return fmt.Errorf("%w: relationship not found", ErrRelationshipNotFound)
+			return nil, fmt.Errorf("%w: relationship not found", ErrRelationshipNotFound)
 		}
-		return err
+		return nil, err
 	}
-
-	return s.repo.Delete(ctx, rel.ObjectID)
+	return rel, nil
 }
 
 func (s *relationshipService) List(ctx context.Context, filter *models.RelationshipFilter) ([]*models.Relationship, error) {
 	if filter == nil {
 		filter = &models.RelationshipFilter{}
 	}
-	return s.repo.List(ctx, filter)
+	if filter.RelationshipTypeKey == nil || *filter.RelationshipTypeKey == "" {
+		return s.repo.List(ctx, filter)
+	}
+
+	typeKey, alias, err := s.storedTypeKey(ctx, *filter.RelationshipTypeKey)
+	if err != nil {
+		return nil, err
+	}
+	if alias == nil {
+		return s.repo.List(ctx, filter)
+	}
+
+	stored := *filter
+	stored.RelationshipTypeKey = &typeKey
+	stored.SourceObjectPublicID, stored.TargetObjectPublicID = filter.TargetObjectPublicID, filter.SourceObjectPublicID
+	rels, err := s.repo.List(ctx, &stored)
+	if err != nil {
+		return nil, err
+	}
+	return invertRelationships(rels, alias), nil
 }
 
 func (s *relationshipService) GetForObject(ctx context.Context, objectPublicID uuid.UUID, filter *models.RelationshipFilterForType) ([]*models.Relationship, error) {
@@ -213,9 +432,28 @@ func (s *relationshipService) GetForObjectByType(ctx context.Context, objectPubl
 	if err != nil {
 		return nil, fmt.Errorf("%w: object not found", repository.ErrNotFound)
 	}
-	return s.repo.GetForObjectByType(ctx, objectPublicID, typeKey)
+
+	storedKey, alias, err := s.storedTypeKey(ctx, typeKey)
+	if err != nil {
+		return nil, err
+	}
+	rels, err := s.repo.GetForObjectByType(ctx, objectPublicID, storedKey)
+	if err != nil || alias == nil {
+		return rels, err
+	}
+	return invertRelationships(rels, alias), nil
 }
 
 func (s *relationshipService) GetRelatedObjects(ctx context.Context, objectPublicID uuid.UUID, typeKey *string) ([]*models.Object, error) {
-	return s.repo.GetRelatedObjects(ctx, objectPublicID, typeKey)
+	if typeKey == nil || *typeKey == "" {
+		return s.repo.GetRelatedObjects(ctx, objectPublicID, typeKey)
+	}
+
+	// Related objects are found in both directions, so an alias finds the same objects as its
+	// primary type
+	storedKey, _, err := s.storedTypeKey(ctx, *typeKey)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetRelatedObjects(ctx, objectPublicID, &storedKey)
 }
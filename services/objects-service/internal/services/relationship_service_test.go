@@ -20,7 +20,7 @@ func TestRelationshipService_Create_Success(t *testing.T) {
 		checkCircularFunc: func(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error) {
 			return false, nil
 		},
-		countForObjectFunc: func(ctx context.Context, objectID int64, typeKey *string) (int, error) {
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
 			return 0, nil
 		},
 		createFunc: func(ctx context.Context, input *models.CreateRelationshipRequest) (*models.Relationship, error) {
@@ -328,7 +328,7 @@ func TestRelationshipService_Create_WithMetadata(t *testing.T) {
 	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{
 		existsFunc:          func(ctx context.Context, sourceObjectID, targetObjectID int64, typeObjectID int64) (bool, error) { return false, nil },
 		checkCircularFunc:   func(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error) { return false, nil },
-		countForObjectFunc: func(ctx context.Context, objectID int64, typeKey *string) (int, error) { return 0, nil },
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) { return 0, nil },
 		createFunc: func(ctx context.Context, input *models.CreateRelationshipRequest) (*models.Relationship, error) {
 			return &models.Relationship{RelationshipTypeKey: input.RelationshipTypeKey}, nil
 		},
@@ -365,7 +365,7 @@ func TestRelationshipService_Create_WithCreatedBy(t *testing.T) {
 	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{
 		existsFunc:     func(ctx context.Context, sourceObjectID, targetObjectID int64, typeObjectID int64) (bool, error) { return false, nil },
 		checkCircularFunc: func(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error) { return false, nil },
-		countForObjectFunc: func(ctx context.Context, objectID int64, typeKey *string) (int, error) { return 0, nil },
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) { return 0, nil },
 		createFunc: func(ctx context.Context, input *models.CreateRelationshipRequest) (*models.Relationship, error) {
 			createdBy := input.CreatedBy
 			return &models.Relationship{RelationshipTypeKey: input.RelationshipTypeKey, CreatedBy: &createdBy}, nil
@@ -403,7 +403,7 @@ func TestRelationshipService_Create_WithDefaultStatus(t *testing.T) {
 	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{
 		existsFunc:     func(ctx context.Context, sourceObjectID, targetObjectID int64, typeObjectID int64) (bool, error) { return false, nil },
 		checkCircularFunc: func(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error) { return false, nil },
-		countForObjectFunc: func(ctx context.Context, objectID int64, typeKey *string) (int, error) { return 0, nil },
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) { return 0, nil },
 		createFunc: func(ctx context.Context, input *models.CreateRelationshipRequest) (*models.Relationship, error) {
 			return &models.Relationship{Status: input.Status}, nil
 		},
@@ -634,7 +634,7 @@ func TestRelationshipService_Create_ManyToMany_NoCircularCheck(t *testing.T) {
 		existsFunc: func(ctx context.Context, sourceObjectID, targetObjectID int64, typeObjectID int64) (bool, error) {
 			return false, nil
 		},
-		countForObjectFunc: func(ctx context.Context, objectID int64, typeKey *string) (int, error) {
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
 			return 0, nil
 		},
 		createFunc: func(ctx context.Context, input *models.CreateRelationshipRequest) (*models.Relationship, error) {
@@ -680,7 +680,7 @@ func TestRelationshipService_Create_OneToOne_SourceAlreadyHasRelationship(t *tes
 		checkCircularFunc: func(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error) {
 			return false, nil
 		},
-		countForObjectFunc: func(ctx context.Context, objectID int64, typeKey *string) (int, error) {
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
 			return 1, nil
 		},
 	}
@@ -724,7 +724,7 @@ func TestRelationshipService_Create_OneToOne_TargetAlreadyHasRelationship(t *tes
 		checkCircularFunc: func(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error) {
 			return false, nil
 		},
-		countForObjectFunc: func(ctx context.Context, objectID int64, typeKey *string) (int, error) {
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
 			if objectID == testTargetObjectID {
 				return 1, nil
 			}
@@ -774,7 +774,7 @@ func TestRelationshipService_Create_OneToMany_TargetAlreadyHasSource(t *testing.
 		checkCircularFunc: func(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error) {
 			return false, nil
 		},
-		countForObjectFunc: func(ctx context.Context, objectID int64, typeKey *string) (int, error) {
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
 			if objectID == testTargetObjectID {
 				return 1, nil
 			}
@@ -824,7 +824,7 @@ func TestRelationshipService_Create_ManyToOne_SourceAlreadyHasTarget(t *testing.
 		checkCircularFunc: func(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error) {
 			return false, nil
 		},
-		countForObjectFunc: func(ctx context.Context, objectID int64, typeKey *string) (int, error) {
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
 			if objectID == testSourceObjectID {
 				return 1, nil
 			}
@@ -874,7 +874,7 @@ func TestRelationshipService_Create_MaxCountViolation_OneToMany(t *testing.T) {
 		checkCircularFunc: func(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error) {
 			return false, nil
 		},
-		countForObjectFunc: func(ctx context.Context, objectID int64, typeKey *string) (int, error) {
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
 			if objectID == testSourceObjectID {
 				return 5, nil
 			}
@@ -925,7 +925,7 @@ func TestRelationshipService_Create_MaxCountViolation_ManyToMany(t *testing.T) {
 		checkCircularFunc: func(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error) {
 			return false, nil
 		},
-		countForObjectFunc: func(ctx context.Context, objectID int64, typeKey *string) (int, error) {
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
 			if objectID == testSourceObjectID {
 				return 10, nil
 			}
@@ -1067,7 +1067,7 @@ func TestRelationshipService_Create_MaxCountZero(t *testing.T) {
 		checkCircularFunc: func(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error) {
 			return false, nil
 		},
-		countForObjectFunc: func(ctx context.Context, objectID int64, typeKey *string) (int, error) {
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
 			return 0, nil
 		},
 		createFunc: func(ctx context.Context, input *models.CreateRelationshipRequest) (*models.Relationship, error) {
@@ -0,0 +1,429 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// lockingTxDB is an in-memory TxBeginner whose transactions hold real locks until they end, the
+// way advisory locks are held by a database transaction. Counting is slowed down so that
+// unguarded check-then-insert sequences would interleave.
+type lockingTxDB struct {
+	relType *models.RelationshipType
+	objects map[uuid.UUID]*models.Object
+
+	mu     sync.Mutex
+	rels   map[int64]*models.Relationship
+	nextID int64
+	locks  sync.Map
+}
+
+func newLockingTxDB(relType *models.RelationshipType, objectCount int) *lockingTxDB {
+	db := &lockingTxDB{
+		relType: relType,
+		objects: make(map[uuid.UUID]*models.Object),
+		rels:    make(map[int64]*models.Relationship),
+		nextID:  1000,
+	}
+	for id := int64(1); id <= int64(objectCount); id++ {
+		publicID := uuid.New()
+		db.objects[publicID] = &models.Object{ID: id, PublicID: publicID}
+	}
+	return db
+}
+
+func (db *lockingTxDB) object(id int64) *models.Object {
+	for _, object := range db.objects {
+		if object.ID == id {
+			return object
+		}
+	}
+	return nil
+}
+
+func (db *lockingTxDB) insert(sourceID, targetID int64) *models.Relationship {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.nextID++
+	rel := &models.Relationship{
+		ObjectID:             db.nextID,
+		PublicID:             uuid.New(),
+		SourceObjectID:       sourceID,
+		SourceObjectPublicID: db.object(sourceID).PublicID,
+		TargetObjectID:       targetID,
+		TargetObjectPublicID: db.object(targetID).PublicID,
+		RelationshipTypeID:   db.relType.ObjectID,
+		RelationshipTypeKey:  db.relType.TypeKey,
+	}
+	db.rels[rel.ObjectID] = rel
+	return rel
+}
+
+func (db *lockingTxDB) count() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return len(db.rels)
+}
+
+func (db *lockingTxDB) BeginTx(ctx context.Context, opts pgx.TxOptions) (Transaction, error) {
+	return &lockingTx{db: db}, nil
+}
+
+type lockingTx struct {
+	db   *lockingTxDB
+	held []*sync.Mutex
+}
+
+func (tx *lockingTx) hold(key string) {
+	lock, _ := tx.db.locks.LoadOrStore(key, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	tx.held = append(tx.held, lock.(*sync.Mutex))
+}
+
+func (tx *lockingTx) release() {
+	for i := len(tx.held) - 1; i >= 0; i-- {
+		tx.held[i].Unlock()
+	}
+	tx.held = nil
+}
+
+func (tx *lockingTx) Commit(ctx context.Context) error {
+	tx.release()
+	return nil
+}
+
+func (tx *lockingTx) Rollback(ctx context.Context) error {
+	tx.release()
+	return nil
+}
+
+func (tx *lockingTx) ObjectTypeRepository() repository.ObjectTypeRepository { return nil }
+
+func (tx *lockingTx) OutboxRepository() repository.OutboxRepository { return nil }
+
+func (tx *lockingTx) ConcreteTableRepository() repository.ConcreteTableRepository { return nil }
+
+func (tx *lockingTx) ObjectRepository() repository.ObjectRepository {
+	return &mockObjectRepository{
+		getByPublicIDFunc: func(ctx context.Context, publicID uuid.UUID) (*models.Object, error) {
+			if object, ok := tx.db.objects[publicID]; ok {
+				return object, nil
+			}
+			return nil, repository.ErrNotFound
+		},
+	}
+}
+
+func (tx *lockingTx) RelationshipTypeRepository() repository.RelationshipTypeRepository {
+	return &mockRelationshipTypeRepository{
+		getByTypeKeyFunc: func(ctx context.Context, typeKey string) (*models.RelationshipType, error) {
+			return tx.db.relType, nil
+		},
+		getByIDFunc: func(ctx context.Context, id int64) (*models.RelationshipType, error) {
+			return tx.db.relType, nil
+		},
+	}
+}
+
+func (tx *lockingTx) RelationshipRepository() repository.RelationshipRepository {
+	db := tx.db
+	find := func(match func(rel *models.Relationship) bool) (*models.Relationship, error) {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		for _, rel := range db.rels {
+			if match(rel) {
+				copied := *rel
+				return &copied, nil
+			}
+		}
+		return nil, repository.ErrNotFound
+	}
+
+	return &mockRelationshipRepositoryForRelationshipService{
+		lockTypeFunc: func(ctx context.Context, typeObjectID int64) error {
+			tx.hold(fmt.Sprintf("type:%d", typeObjectID))
+			return nil
+		},
+		lockObjectsFunc: func(ctx context.Context, typeObjectID int64, objectIDs ...int64) error {
+			ids := append([]int64(nil), objectIDs...)
+			sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+			for _, id := range ids {
+				tx.hold(fmt.Sprintf("object:%d:%d", typeObjectID, id))
+			}
+			return nil
+		},
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
+			db.mu.Lock()
+			count := 0
+			for _, rel := range db.rels {
+				if (direction == models.DirectionOutgoing && rel.SourceObjectID == objectID) ||
+					(direction == models.DirectionIncoming && rel.TargetObjectID == objectID) {
+					count++
+				}
+			}
+			db.mu.Unlock()
+			time.Sleep(time.Millisecond)
+			return count, nil
+		},
+		existsFunc: func(ctx context.Context, sourceObjectID, targetObjectID int64, typeObjectID int64) (bool, error) {
+			_, err := find(func(rel *models.Relationship) bool {
+				return rel.SourceObjectID == sourceObjectID && rel.TargetObjectID == targetObjectID
+			})
+			return err == nil, nil
+		},
+		createFunc: func(ctx context.Context, input *models.CreateRelationshipRequest) (*models.Relationship, error) {
+			source := db.objects[uuid.MustParse(input.SourceObjectPublicID)]
+			target := db.objects[uuid.MustParse(input.TargetObjectPublicID)]
+			return db.insert(source.ID, target.ID), nil
+		},
+		getByPublicIDFunc: func(ctx context.Context, publicID uuid.UUID) (*models.Relationship, error) {
+			return find(func(rel *models.Relationship) bool { return rel.PublicID == publicID })
+		},
+		getByObjectIDFunc: func(ctx context.Context, objectID int64) (*models.Relationship, error) {
+			return find(func(rel *models.Relationship) bool { return rel.ObjectID == objectID })
+		},
+		deleteFunc: func(ctx context.Context, objectID int64) error {
+			db.mu.Lock()
+			defer db.mu.Unlock()
+			delete(db.rels, objectID)
+			return nil
+		},
+	}
+}
+
+// runConcurrently calls fn n times at once and returns the errors it returned
+func runConcurrently(n int, fn func(i int) error) []error {
+	errs := make([]error, n)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = fn(i)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	return errs
+}
+
+func assertCardinalityOutcome(t *testing.T, errs []error, wantSucceeded int) {
+	t.Helper()
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, ErrCardinalityViolation)
+	}
+	assert.Equal(t, wantSucceeded, succeeded)
+}
+
+func TestRelationshipService_Create_ConcurrentOneToOne(t *testing.T) {
+	const attempts = 20
+	db := newLockingTxDB(&models.RelationshipType{
+		ObjectID:    100,
+		TypeKey:     "married_to",
+		Cardinality: models.CardinalityOneToOne,
+	}, attempts+1)
+	service := NewRelationshipServiceWithTx(nil, nil, nil, db)
+
+	source := db.object(1)
+	errs := runConcurrently(attempts, func(i int) error {
+		_, err := service.Create(context.Background(), &models.CreateRelationshipRequest{
+			SourceObjectPublicID: source.PublicID.String(),
+			TargetObjectPublicID: db.object(int64(i + 2)).PublicID.String(),
+			RelationshipTypeKey:  "married_to",
+		})
+		return err
+	})
+
+	assertCardinalityOutcome(t, errs, 1)
+	assert.Equal(t, 1, db.count())
+}
+
+func TestRelationshipService_Create_ConcurrentOneToManyTarget(t *testing.T) {
+	const attempts = 20
+	db := newLockingTxDB(&models.RelationshipType{
+		ObjectID:    100,
+		TypeKey:     "contains",
+		Cardinality: models.CardinalityOneToMany,
+	}, attempts+1)
+	service := NewRelationshipServiceWithTx(nil, nil, nil, db)
+
+	target := db.object(1)
+	errs := runConcurrently(attempts, func(i int) error {
+		_, err := service.Create(context.Background(), &models.CreateRelationshipRequest{
+			SourceObjectPublicID: db.object(int64(i + 2)).PublicID.String(),
+			TargetObjectPublicID: target.PublicID.String(),
+			RelationshipTypeKey:  "contains",
+		})
+		return err
+	})
+
+	assertCardinalityOutcome(t, errs, 1)
+	assert.Equal(t, 1, db.count())
+}
+
+func TestRelationshipService_Create_ConcurrentMaxCount(t *testing.T) {
+	const attempts = 20
+	db := newLockingTxDB(&models.RelationshipType{
+		ObjectID:    100,
+		TypeKey:     "related_to",
+		Cardinality: models.CardinalityManyToMany,
+		MaxCount:    3,
+	}, attempts+1)
+	service := NewRelationshipServiceWithTx(nil, nil, nil, db)
+
+	source := db.object(1)
+	errs := runConcurrently(attempts, func(i int) error {
+		_, err := service.Create(context.Background(), &models.CreateRelationshipRequest{
+			SourceObjectPublicID: source.PublicID.String(),
+			TargetObjectPublicID: db.object(int64(i + 2)).PublicID.String(),
+			RelationshipTypeKey:  "related_to",
+		})
+		return err
+	})
+
+	assertCardinalityOutcome(t, errs, 3)
+	assert.Equal(t, 3, db.count())
+}
+
+func TestRelationshipService_Delete_ConcurrentMinCount(t *testing.T) {
+	const existing = 6
+	db := newLockingTxDB(&models.RelationshipType{
+		ObjectID:    100,
+		TypeKey:     "has_owner",
+		Cardinality: models.CardinalityManyToMany,
+		Required:    true,
+		MinCount:    2,
+	}, existing+1)
+	service := NewRelationshipServiceWithTx(nil, nil, nil, db)
+
+	var publicIDs []uuid.UUID
+	for i := 0; i < existing; i++ {
+		publicIDs = append(publicIDs, db.insert(1, int64(i+2)).PublicID)
+	}
+
+	errs := runConcurrently(existing, func(i int) error {
+		return service.Delete(context.Background(), publicIDs[i])
+	})
+
+	assertCardinalityOutcome(t, errs, existing-2)
+	assert.Equal(t, 2, db.count())
+}
+
+func TestRelationshipService_Delete_Required(t *testing.T) {
+	db := newLockingTxDB(&models.RelationshipType{
+		ObjectID:    100,
+		TypeKey:     "belongs_to",
+		Cardinality: models.CardinalityManyToOne,
+		Required:    true,
+	}, 2)
+	service := NewRelationshipServiceWithTx(nil, nil, nil, db)
+
+	rel := db.insert(1, 2)
+	err := service.Delete(context.Background(), rel.PublicID)
+
+	assert.ErrorIs(t, err, ErrCardinalityViolation)
+	assert.Equal(t, 1, db.count())
+}
+
+func TestRelationshipService_Create_CountFails(t *testing.T) {
+	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{
+		countInDirectionFunc: func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
+			return 0, errors.New("database error")
+		},
+		createFunc: func(ctx context.Context, input *models.CreateRelationshipRequest) (*models.Relationship, error) {
+			t.Fatal("relationship created although the cardinality check failed")
+			return nil, nil
+		},
+	}
+	mockRelTypeRepo := &mockRelationshipTypeRepository{
+		getByTypeKeyFunc: func(ctx context.Context, typeKey string) (*models.RelationshipType, error) {
+			return &models.RelationshipType{ObjectID: 1, TypeKey: typeKey, Cardinality: models.CardinalityOneToOne}, nil
+		},
+	}
+	service := NewRelationshipService(mockRelRepo, mockRelTypeRepo, graphTestObjects())
+
+	_, err := service.Create(context.Background(), &models.CreateRelationshipRequest{
+		SourceObjectPublicID: testSourcePublicID.String(),
+		TargetObjectPublicID: testTargetPublicID.String(),
+		RelationshipTypeKey:  "married_to",
+	})
+
+	assert.EqualError(t, err, "database error")
+}
+
+func TestRelationshipService_Create_LocksBeforeChecking(t *testing.T) {
+	var calls []string
+	mockRelRepo := &mockRelationshipRepositoryForRelationshipService{
+		lockTypeFunc: func(ctx context.Context, typeObjectID int64) error {
+			calls = append(calls, "lock type")
+			return nil
+		},
+		lockObjectsFunc: func(ctx context.Context, typeObjectID int64, objectIDs ...int64) error {
+			assert.ElementsMatch(t, []int64{testSourceObjectID, testTargetObjectID}, objectIDs)
+			calls = append(calls, "lock objects")
+			return nil
+		},
+		existsFunc: func(ctx context.Context, sourceObjectID, targetObjectID int64, typeObjectID int64) (bool, error) {
+			calls = append(calls, "exists")
+			return false, nil
+		},
+	}
+	mockRelTypeRepo := &mockRelationshipTypeRepository{
+		getByTypeKeyFunc: func(ctx context.Context, typeKey string) (*models.RelationshipType, error) {
+			return &models.RelationshipType{ObjectID: 1, TypeKey: typeKey, Cardinality: models.CardinalityOneToMany}, nil
+		},
+	}
+	service := NewRelationshipService(mockRelRepo, mockRelTypeRepo, graphTestObjects())
+
+	_, err := service.Create(context.Background(), &models.CreateRelationshipRequest{
+		SourceObjectPublicID: testSourcePublicID.String(),
+		TargetObjectPublicID: testTargetPublicID.String(),
+		RelationshipTypeKey:  "contains",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lock type", "lock objects", "exists"}, calls)
+}
+
+func TestWithinTx(t *testing.T) {
+	db := newLockingTxDB(&models.RelationshipType{ObjectID: 100}, 0)
+
+	var seen Transaction
+	err := WithinTx(context.Background(), db, func(tx Transaction) error {
+		seen = tx
+		tx.(*lockingTx).hold("key")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NotNil(t, seen.RelationshipRepository())
+	assert.Empty(t, seen.(*lockingTx).held, "commit releases the locks")
+
+	failure := errors.New("failed")
+	err = WithinTx(context.Background(), db, func(tx Transaction) error {
+		seen = tx
+		tx.(*lockingTx).hold("key")
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+	assert.Empty(t, seen.(*lockingTx).held, "rollback releases the locks")
+}
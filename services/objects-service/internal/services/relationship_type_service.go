@@ -13,7 +13,7 @@ var (
 	ErrRelationshipTypeNotFound  = errors.New("relationship type not found")
 	ErrDuplicateRelationshipType = errors.New("relationship type already exists")
 	ErrInvalidCardinality        = errors.New("invalid cardinality value")
-	ErrInvalidReverseType        = errors.New("reverse type key does not exist")
+	ErrInvalidReverseType        = errors.New("invalid reverse type key")
 	ErrRelationshipTypeInUse     = errors.New("relationship type is in use and cannot be deleted")
 	ErrInvalidCountConstraint    = errors.New("min_count cannot exceed max_count")
 	ErrTypeKeyRequired           = errors.New("type_key is required")
@@ -32,11 +32,29 @@ type RelationshipTypeService interface {
 // relationshipTypeService implements RelationshipTypeService
 type relationshipTypeService struct {
 	repo repository.RelationshipTypeRepository
+	txDB TxBeginner
 }
 
 // NewRelationshipTypeService creates a new RelationshipTypeService instance
 func NewRelationshipTypeService(repo repository.RelationshipTypeRepository) RelationshipTypeService {
-	return &relationshipTypeService{repo: repo}
+	return NewRelationshipTypeServiceWithTx(repo, nil)
+}
+
+// NewRelationshipTypeServiceWithTx creates a relationship type service that changes a type and
+// its reverse type inside one transaction started by txDB. Without txDB the changes run directly
+// against repo.
+func NewRelationshipTypeServiceWithTx(repo repository.RelationshipTypeRepository, txDB TxBeginner) RelationshipTypeService {
+	return &relationshipTypeService{repo: repo, txDB: txDB}
+}
+
+// withinTx runs fn with a service whose repository is bound to a single transaction
+func (s *relationshipTypeService) withinTx(ctx context.Context, fn func(tx *relationshipTypeService) error) error {
+	if s.txDB == nil {
+		return fn(s)
+	}
+	return WithinTx(ctx, s.txDB, func(tx Transaction) error {
+		return fn(&relationshipTypeService{repo: tx.RelationshipTypeRepository()})
+	})
 }
 
 // Create creates a new relationship type
@@ -64,20 +82,9 @@ func (s *relationshipTypeService) Create(ctx context.Context, req *models.Create
 		return nil, fmt.Errorf("%w: type_key '%s' already exists", ErrDuplicateRelationshipType, req.TypeKey)
 	}
 
-	// Validate reverse_type_key if provided
-	if req.ReverseTypeKey != nil && *req.ReverseTypeKey != "" {
-		// Check that reverse type exists (but not self-reference)
-		if *req.ReverseTypeKey == req.TypeKey {
-			return nil, fmt.Errorf("%w: cannot reference itself", ErrInvalidReverseType)
-		}
-
-		reverseExists, err := s.repo.Exists(ctx, *req.ReverseTypeKey)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check reverse type existence: %w", err)
-		}
-		if !reverseExists {
-			return nil, fmt.Errorf("%w: type_key '%s' does not exist", ErrInvalidReverseType, *req.ReverseTypeKey)
-		}
+	// The reverse type is checked against the new type and created along with it if missing
+	if req.ReverseTypeKey != nil && *req.ReverseTypeKey == req.TypeKey {
+		return nil, fmt.Errorf("%w: cannot reference itself", ErrInvalidReverseType)
 	}
 
 	// Validate count constraints
@@ -85,6 +92,10 @@ func (s *relationshipTypeService) Create(ctx context.Context, req *models.Create
 		return nil, fmt.Errorf("%w: min_count cannot exceed max_count", ErrInvalidCountConstraint)
 	}
 
+	if err := validateRulesDocument(req.ValidationRules); err != nil {
+		return nil, err
+	}
+
 	// Set defaults
 	if req.MinCount < 0 {
 		req.MinCount = 0
@@ -93,7 +104,96 @@ func (s *relationshipTypeService) Create(ctx context.Context, req *models.Create
 		req.MaxCount = -1
 	}
 
-	return s.repo.Create(ctx, req)
+	if req.ReverseTypeKey == nil || *req.ReverseTypeKey == "" {
+		return s.repo.Create(ctx, req)
+	}
+
+	var created *models.RelationshipType
+	err = s.withinTx(ctx, func(tx *relationshipTypeService) error {
+		reverse, err := tx.reverseTypeFor(ctx, req.TypeKey, *req.ReverseTypeKey, req.Cardinality)
+		if err != nil {
+			return err
+		}
+
+		created, err = tx.repo.Create(ctx, req)
+		if err != nil {
+			return err
+		}
+		return tx.pair(ctx, created, reverse, req.CreatedBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// reverseTypeFor returns the type reverseKey names, checked to be usable as the reverse type of
+// typeKey, or nil if it does not exist yet. An existing reverse type must not be paired with
+// another type and must have the mirrored cardinality.
+func (s *relationshipTypeService) reverseTypeFor(ctx context.Context, typeKey, reverseKey, cardinality string) (*models.RelationshipType, error) {
+	reverse, err := s.repo.GetByTypeKey(ctx, reverseKey)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get reverse type: %w", err)
+	}
+
+	if reverse.ReverseTypeKey != nil && *reverse.ReverseTypeKey != "" && *reverse.ReverseTypeKey != typeKey {
+		return nil, fmt.Errorf("%w: type_key '%s' is already the reverse of '%s'", ErrInvalidReverseType, reverseKey, *reverse.ReverseTypeKey)
+	}
+	if expected := models.ReverseCardinality(cardinality); reverse.Cardinality != expected {
+		return nil, fmt.Errorf("%w: type_key '%s' has cardinality %s, expected %s", ErrInvalidReverseType, reverseKey, reverse.Cardinality, expected)
+	}
+	return reverse, nil
+}
+
+// pair makes reverse name relType back, or creates it as relType's reverse type when it is nil
+func (s *relationshipTypeService) pair(ctx context.Context, relType, reverse *models.RelationshipType, user string) error {
+	if reverse == nil {
+		_, err := s.repo.Create(ctx, &models.CreateRelationshipTypeRequest{
+			TypeKey:        *relType.ReverseTypeKey,
+			ReverseTypeKey: &relType.TypeKey,
+			Cardinality:    models.ReverseCardinality(relType.Cardinality),
+			MaxCount:       -1,
+			CreatedBy:      user,
+			UpdatedBy:      user,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create reverse type: %w", err)
+		}
+		return nil
+	}
+
+	if reverse.ReverseTypeKey != nil && *reverse.ReverseTypeKey == relType.TypeKey {
+		return nil
+	}
+	_, err := s.repo.Update(ctx, reverse.ObjectID, &models.UpdateRelationshipTypeRequest{
+		ReverseTypeKey: &relType.TypeKey,
+		UpdatedBy:      user,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to pair reverse type: %w", err)
+	}
+	return nil
+}
+
+// unpair clears the reverse type key of the type paired with relType
+func (s *relationshipTypeService) unpair(ctx context.Context, relType *models.RelationshipType, user string) error {
+	reverse, err := pairedRelationshipType(ctx, s.repo, relType)
+	if err != nil || reverse == nil {
+		return err
+	}
+
+	unpaired := ""
+	_, err = s.repo.Update(ctx, reverse.ObjectID, &models.UpdateRelationshipTypeRequest{
+		ReverseTypeKey: &unpaired,
+		UpdatedBy:      user,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to unpair reverse type: %w", err)
+	}
+	return nil
 }
 
 // GetByTypeKey retrieves a relationship type by type_key
@@ -133,18 +233,9 @@ func (s *relationshipTypeService) Update(ctx context.Context, typeKey string, re
 		return nil, fmt.Errorf("%w: %s", ErrInvalidCardinality, repository.ErrInvalidInput)
 	}
 
-	// Validate reverse_type_key if provided
-	if req.ReverseTypeKey != nil && *req.ReverseTypeKey != "" {
-		// Allow self-reference if it's being removed (set to empty)
-		if *req.ReverseTypeKey != typeKey {
-			reverseExists, err := s.repo.Exists(ctx, *req.ReverseTypeKey)
-			if err != nil {
-				return nil, fmt.Errorf("failed to check reverse type existence: %w", err)
-			}
-			if !reverseExists {
-				return nil, fmt.Errorf("%w: type_key '%s' does not exist", ErrInvalidReverseType, *req.ReverseTypeKey)
-			}
-		}
+	// An empty reverse_type_key unpairs the type
+	if req.ReverseTypeKey != nil && *req.ReverseTypeKey == typeKey {
+		return nil, fmt.Errorf("%w: cannot reference itself", ErrInvalidReverseType)
 	}
 
 	// Validate count constraints if provided
@@ -154,7 +245,88 @@ func (s *relationshipTypeService) Update(ctx context.Context, typeKey string, re
 		}
 	}
 
-	return s.repo.Update(ctx, existing.ObjectID, req)
+	if req.ValidationRules != nil {
+		if err := validateRulesDocument(*req.ValidationRules); err != nil {
+			return nil, err
+		}
+	}
+
+	var updated *models.RelationshipType
+	err = s.withinTx(ctx, func(tx *relationshipTypeService) error {
+		if err := tx.updateReverse(ctx, existing, req); err != nil {
+			return err
+		}
+		updated, err = tx.repo.Update(ctx, existing.ObjectID, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// updateReverse keeps the reverse type of relType in step with an update: a new reverse type is
+// paired or created in place of the old one, and a changed cardinality is mirrored onto it
+func (s *relationshipTypeService) updateReverse(ctx context.Context, relType *models.RelationshipType, req *models.UpdateRelationshipTypeRequest) error {
+	cardinality := relType.Cardinality
+	if req.Cardinality != nil {
+		cardinality = *req.Cardinality
+	}
+	currentKey := ""
+	if relType.ReverseTypeKey != nil {
+		currentKey = *relType.ReverseTypeKey
+	}
+
+	if req.ReverseTypeKey == nil || *req.ReverseTypeKey == currentKey {
+		if req.Cardinality == nil || cardinality == relType.Cardinality {
+			return nil
+		}
+		reverse, err := pairedRelationshipType(ctx, s.repo, relType)
+		if err != nil || reverse == nil {
+			return err
+		}
+		reverseCardinality := models.ReverseCardinality(cardinality)
+		_, err = s.repo.Update(ctx, reverse.ObjectID, &models.UpdateRelationshipTypeRequest{
+			Cardinality: &reverseCardinality,
+			UpdatedBy:   req.UpdatedBy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update reverse type: %w", err)
+		}
+		return nil
+	}
+
+	if err := s.unpair(ctx, relType, req.UpdatedBy); err != nil {
+		return err
+	}
+	if *req.ReverseTypeKey == "" {
+		return nil
+	}
+
+	reverse, err := s.reverseTypeFor(ctx, relType.TypeKey, *req.ReverseTypeKey, cardinality)
+	if err != nil {
+		return err
+	}
+	if reverse != nil {
+		// Relationships are stored under the older type of a pair, so the newer one must have
+		// none of its own
+		alias := relType
+		if reverse.ObjectID > relType.ObjectID {
+			alias = reverse
+		}
+		inUse, err := s.repo.HasRelationships(ctx, alias.ObjectID)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return fmt.Errorf("%w: type_key '%s' already has relationships of its own", ErrInvalidReverseType, alias.TypeKey)
+		}
+	}
+
+	paired := *relType
+	paired.ReverseTypeKey = req.ReverseTypeKey
+	paired.Cardinality = cardinality
+	return s.pair(ctx, &paired, reverse, req.UpdatedBy)
 }
 
 // Delete deletes a relationship type
@@ -175,7 +347,12 @@ func (s *relationshipTypeService) Delete(ctx context.Context, typeKey string) er
 	// TODO: Check if relationship type is in use before deletion
 	// This will be implemented in Phase R2 when we have relationship instances
 
-	return s.repo.Delete(ctx, existing.ObjectID)
+	return s.withinTx(ctx, func(tx *relationshipTypeService) error {
+		if err := tx.unpair(ctx, existing, ""); err != nil {
+			return err
+		}
+		return tx.repo.Delete(ctx, existing.ObjectID)
+	})
 }
 
 // List retrieves relationship types with filtering and pagination
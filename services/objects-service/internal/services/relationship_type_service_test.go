@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -19,6 +20,7 @@ type mockRelationshipTypeRepository struct {
 	listFunc                func(ctx context.Context, filter *models.RelationshipTypeFilter) ([]*models.RelationshipType, error)
 	existsFunc              func(ctx context.Context, typeKey string) (bool, error)
 	getByReverseTypeKeyFunc func(ctx context.Context, reverseKey string) (*models.RelationshipType, error)
+	hasRelationshipsFunc    func(ctx context.Context, id int64) (bool, error)
 }
 
 func (m *mockRelationshipTypeRepository) DB() repository.DBInterface             { return nil }
@@ -83,6 +85,13 @@ func (m *mockRelationshipTypeRepository) GetByReverseTypeKey(ctx context.Context
 	return nil, nil
 }
 
+func (m *mockRelationshipTypeRepository) HasRelationships(ctx context.Context, id int64) (bool, error) {
+	if m.hasRelationshipsFunc != nil {
+		return m.hasRelationshipsFunc(ctx, id)
+	}
+	return false, nil
+}
+
 func TestRelationshipTypeService_Create_Success(t *testing.T) {
 	mockRepo := &mockRelationshipTypeRepository{
 		existsFunc: func(ctx context.Context, typeKey string) (bool, error) {
@@ -156,6 +165,15 @@ func TestRelationshipTypeService_Create_InvalidReverseTypeKey(t *testing.T) {
 			}
 			return false, nil
 		},
+		getByTypeKeyFunc: func(ctx context.Context, typeKey string) (*models.RelationshipType, error) {
+			pairedWith := "holds"
+			return &models.RelationshipType{
+				ObjectID:       2,
+				TypeKey:        typeKey,
+				ReverseTypeKey: &pairedWith,
+				Cardinality:    models.CardinalityManyToOne,
+			}, nil
+		},
 		createFunc: func(ctx context.Context, input *models.CreateRelationshipTypeRequest) (*models.RelationshipType, error) {
 			return nil, repository.ErrNotFound
 		},
@@ -170,9 +188,9 @@ func TestRelationshipTypeService_Create_InvalidReverseTypeKey(t *testing.T) {
 
 	result, err := service.Create(context.Background(), req)
 
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidReverseType)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "does not exist")
+	assert.Contains(t, err.Error(), "already the reverse of 'holds'")
 }
 
 func TestRelationshipTypeService_GetByTypeKey_Success(t *testing.T) {
@@ -249,7 +267,7 @@ func TestRelationshipTypeService_Update_Success(t *testing.T) {
 }
 
 func TestRelationshipTypeService_Update_InvalidReverseTypeKey(t *testing.T) {
-	reverseKey := "nonexistent"
+	reverseKey := "part_of"
 	mockRepo := &mockRelationshipTypeRepository{
 		getByTypeKeyFunc: func(ctx context.Context, typeKey string) (*models.RelationshipType, error) {
 			return &models.RelationshipType{
@@ -270,9 +288,9 @@ func TestRelationshipTypeService_Update_InvalidReverseTypeKey(t *testing.T) {
 
 	result, err := service.Update(context.Background(), "contains", req)
 
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, ErrInvalidReverseType)
 	assert.Nil(t, result)
-	assert.Contains(t, err.Error(), "does not exist")
+	assert.Contains(t, err.Error(), "has cardinality one_to_many, expected many_to_one")
 }
 
 func TestRelationshipTypeService_Delete_Success(t *testing.T) {
@@ -374,11 +392,19 @@ type mockRelationshipRepositoryForRelationshipService struct {
 	listFunc            func(ctx context.Context, filter *models.RelationshipFilter) ([]*models.Relationship, error)
 	getForObjectFunc   func(ctx context.Context, objectPublicID uuid.UUID, filter *models.RelationshipFilterForType) ([]*models.Relationship, error)
 	getForObjectByTypeFunc func(ctx context.Context, objectPublicID uuid.UUID, typeKey string) ([]*models.Relationship, error)
+	listForObjectsFunc func(ctx context.Context, objectIDs []int64) ([]*models.Relationship, error)
 	getRelatedObjectsFunc func(ctx context.Context, objectPublicID uuid.UUID, typeKey *string) ([]*models.Object, error)
 	existsFunc          func(ctx context.Context, sourceObjectID, targetObjectID int64, typeObjectID int64) (bool, error)
 	countForObjectFunc func(ctx context.Context, objectID int64, typeKey *string) (int, error)
 	getByTypeKeyFunc  func(ctx context.Context, typeKey string) ([]*models.Relationship, error)
 	checkCircularFunc  func(ctx context.Context, sourceObjectID, targetObjectID, typeObjectID int64) (bool, error)
+	traverseNodesFunc  func(ctx context.Context, startObjectID int64, query *models.GraphQuery) ([]*models.GraphNode, bool, error)
+	edgesBetweenFunc   func(ctx context.Context, objectIDs []int64, typeKeys []string, limit int) ([]*models.GraphEdge, bool, error)
+	shortestPathFunc   func(ctx context.Context, sourceObjectID, targetObjectID int64, query *models.GraphQuery) (*models.GraphPath, error)
+	countInDirectionFunc func(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error)
+	lockTypeFunc         func(ctx context.Context, typeObjectID int64) error
+	lockObjectsFunc      func(ctx context.Context, typeObjectID int64, objectIDs ...int64) error
+	objectTypeNamesFunc  func(ctx context.Context, objectIDs []int64) (map[int64][]string, error)
 }
 
 func (m *mockRelationshipRepositoryForRelationshipService) DB() repository.DBInterface             { return nil }
@@ -459,6 +485,29 @@ func (m *mockRelationshipRepositoryForRelationshipService) GetForObjectByType(ct
 	return []*models.Relationship{}, nil
 }
 
+func (m *mockRelationshipRepositoryForRelationshipService) ListForObjects(ctx context.Context, objectIDs []int64) ([]*models.Relationship, error) {
+	if m.listForObjectsFunc != nil {
+		return m.listForObjectsFunc(ctx, objectIDs)
+	}
+	return []*models.Relationship{}, nil
+}
+
+func (m *mockRelationshipRepositoryForRelationshipService) ListDeletedForObjects(ctx context.Context, objectIDs []int64) ([]*models.Relationship, error) {
+	return []*models.Relationship{}, nil
+}
+
+func (m *mockRelationshipRepositoryForRelationshipService) Restore(ctx context.Context, objectIDs []int64) error {
+	return nil
+}
+
+func (m *mockRelationshipRepositoryForRelationshipService) PurgeDeletedBefore(ctx context.Context, deletedBefore time.Time) (int, error) {
+	return 0, nil
+}
+
+func (m *mockRelationshipRepositoryForRelationshipService) ExportRelationships(ctx context.Context, filter *models.RelationshipExportFilter, batchSize int, fn func([]*models.RelationshipRecord) error) error {
+	return nil
+}
+
 func (m *mockRelationshipRepositoryForRelationshipService) GetRelatedObjects(ctx context.Context, objectPublicID uuid.UUID, typeKey *string) ([]*models.Object, error) {
 	if m.getRelatedObjectsFunc != nil {
 		return m.getRelatedObjectsFunc(ctx, objectPublicID, typeKey)
@@ -494,6 +543,55 @@ func (m *mockRelationshipRepositoryForRelationshipService) CheckCircular(ctx con
 	return false, nil
 }
 
+func (m *mockRelationshipRepositoryForRelationshipService) TraverseNodes(ctx context.Context, startObjectID int64, query *models.GraphQuery) ([]*models.GraphNode, bool, error) {
+	if m.traverseNodesFunc != nil {
+		return m.traverseNodesFunc(ctx, startObjectID, query)
+	}
+	return []*models.GraphNode{}, false, nil
+}
+
+func (m *mockRelationshipRepositoryForRelationshipService) EdgesBetween(ctx context.Context, objectIDs []int64, typeKeys []string, limit int) ([]*models.GraphEdge, bool, error) {
+	if m.edgesBetweenFunc != nil {
+		return m.edgesBetweenFunc(ctx, objectIDs, typeKeys, limit)
+	}
+	return []*models.GraphEdge{}, false, nil
+}
+
+func (m *mockRelationshipRepositoryForRelationshipService) ShortestPath(ctx context.Context, sourceObjectID, targetObjectID int64, query *models.GraphQuery) (*models.GraphPath, error) {
+	if m.shortestPathFunc != nil {
+		return m.shortestPathFunc(ctx, sourceObjectID, targetObjectID, query)
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (m *mockRelationshipRepositoryForRelationshipService) CountInDirection(ctx context.Context, objectID, typeObjectID int64, direction string) (int, error) {
+	if m.countInDirectionFunc != nil {
+		return m.countInDirectionFunc(ctx, objectID, typeObjectID, direction)
+	}
+	return 0, nil
+}
+
+func (m *mockRelationshipRepositoryForRelationshipService) LockType(ctx context.Context, typeObjectID int64) error {
+	if m.lockTypeFunc != nil {
+		return m.lockTypeFunc(ctx, typeObjectID)
+	}
+	return nil
+}
+
+func (m *mockRelationshipRepositoryForRelationshipService) LockObjects(ctx context.Context, typeObjectID int64, objectIDs ...int64) error {
+	if m.lockObjectsFunc != nil {
+		return m.lockObjectsFunc(ctx, typeObjectID, objectIDs...)
+	}
+	return nil
+}
+
+func (m *mockRelationshipRepositoryForRelationshipService) ObjectTypeNames(ctx context.Context, objectIDs []int64) (map[int64][]string, error) {
+	if m.objectTypeNamesFunc != nil {
+		return m.objectTypeNamesFunc(ctx, objectIDs)
+	}
+	return map[int64][]string{}, nil
+}
+
 var (
 	testSourcePublicID    = uuid.MustParse("11111111-1111-1111-1111-111111111111")
 	testTargetPublicID    = uuid.MustParse("22222222-2222-2222-2222-222222222222")
@@ -0,0 +1,815 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// TaxonomyService exports the object type tree and relationship types as a bundle and imports
+// bundles by diffing them against the live taxonomy
+type TaxonomyService interface {
+	Export(ctx context.Context) (*models.TaxonomyBundle, error)
+	Import(ctx context.Context, bundle *models.TaxonomyBundle, opts models.TaxonomyImportOptions) (*models.TaxonomyPlan, error)
+}
+
+type taxonomyService struct {
+	objectTypeRepo repository.ObjectTypeRepository
+	relTypeRepo    repository.RelationshipTypeRepository
+	txDB           TxBeginner
+}
+
+// NewTaxonomyService creates a taxonomy service that applies imports inside a single transaction
+// started by txDB. Without txDB the changes run directly against the repositories.
+func NewTaxonomyService(objectTypeRepo repository.ObjectTypeRepository, relTypeRepo repository.RelationshipTypeRepository, txDB TxBeginner) TaxonomyService {
+	return &taxonomyService{objectTypeRepo: objectTypeRepo, relTypeRepo: relTypeRepo, txDB: txDB}
+}
+
+// withinTx runs fn with an object type service and a relationship type repository bound to a
+// single transaction
+func (s *taxonomyService) withinTx(ctx context.Context, fn func(objectTypes *objectTypeService, relTypes repository.RelationshipTypeRepository) error) error {
+	if s.txDB == nil {
+		return fn(&objectTypeService{repo: s.objectTypeRepo}, s.relTypeRepo)
+	}
+	return WithinTxOptions(ctx, s.txDB, outboxTxOptions, func(tx Transaction) error {
+		return fn(&objectTypeService{repo: tx.ObjectTypeRepository(), outbox: tx.OutboxRepository()}, tx.RelationshipTypeRepository())
+	})
+}
+
+// Export returns the live taxonomy as a bundle
+func (s *taxonomyService) Export(ctx context.Context) (*models.TaxonomyBundle, error) {
+	objectTypes, err := s.objectTypeRepo.List(ctx, &models.ObjectTypeFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list object types: %w", err)
+	}
+	relTypes, err := listAllRelationshipTypes(ctx, s.relTypeRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	exportedAt := time.Now().UTC()
+	bundle := &models.TaxonomyBundle{
+		FormatVersion:     models.TaxonomyBundleVersion,
+		ExportedAt:        &exportedAt,
+		ObjectTypes:       []models.BundleObjectType{},
+		RelationshipTypes: []models.BundleRelationshipType{},
+	}
+
+	names := make(map[int64]string, len(objectTypes))
+	for _, objectType := range objectTypes {
+		names[objectType.ID] = objectType.Name
+	}
+	for _, objectType := range parentsFirst(objectTypes) {
+		entry := models.BundleObjectType{
+			Name:               objectType.Name,
+			Description:        objectType.Description,
+			IsSealed:           objectType.IsSealed,
+			Metadata:           decodeJSONObject(objectType.Metadata),
+			MetadataSchema:     decodeJSONObject(objectType.MetadataSchema),
+			SearchMetadataKeys: objectType.SearchMetadataKeys,
+		}
+		if objectType.ParentTypeID != nil {
+			entry.Parent = names[*objectType.ParentTypeID]
+		}
+		if objectType.ConcreteTableName != nil {
+			entry.ConcreteTableName = *objectType.ConcreteTableName
+		}
+		bundle.ObjectTypes = append(bundle.ObjectTypes, entry)
+	}
+
+	for _, relType := range relTypes {
+		entry := models.BundleRelationshipType{
+			TypeKey:          relType.TypeKey,
+			RelationshipName: relType.RelationshipName,
+			Cardinality:      relType.Cardinality,
+			Required:         relType.Required,
+			MinCount:         relType.MinCount,
+			MaxCount:         relType.MaxCount,
+			ValidationRules:  decodeJSONObject(relType.ValidationRules),
+		}
+		if relType.ReverseTypeKey != nil {
+			entry.ReverseTypeKey = *relType.ReverseTypeKey
+		}
+		bundle.RelationshipTypes = append(bundle.RelationshipTypes, entry)
+	}
+
+	return bundle, nil
+}
+
+// Import diffs a bundle against the live taxonomy and, unless opts.DryRun is set, applies the
+// resulting plan in one transaction: either every change is made or none is
+func (s *taxonomyService) Import(ctx context.Context, bundle *models.TaxonomyBundle, opts models.TaxonomyImportOptions) (*models.TaxonomyPlan, error) {
+	if err := checkTaxonomyBundle(bundle); err != nil {
+		return nil, err
+	}
+
+	if opts.DryRun {
+		diff, err := diffTaxonomy(ctx, s.objectTypeRepo, s.relTypeRepo, bundle, opts.Prune)
+		if err != nil {
+			return nil, err
+		}
+		return diff.plan, nil
+	}
+
+	var plan *models.TaxonomyPlan
+	err := s.withinTx(ctx, func(objectTypes *objectTypeService, relTypes repository.RelationshipTypeRepository) error {
+		// The plan is made again inside the transaction, against what it is applied to
+		diff, err := diffTaxonomy(ctx, objectTypes.repo, relTypes, bundle, opts.Prune)
+		if err != nil {
+			return err
+		}
+		if err := diff.apply(ctx, objectTypes, relTypes, opts.Actor); err != nil {
+			return err
+		}
+		plan = diff.plan
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	plan.Applied = true
+	return plan, nil
+}
+
+// checkTaxonomyBundle validates a bundle on its own: unique keys, a parent tree without cycles or
+// sealed parents, valid relationship type settings and reverse types that name each other
+func checkTaxonomyBundle(bundle *models.TaxonomyBundle) error {
+	if bundle.FormatVersion != models.TaxonomyBundleVersion {
+		return fmt.Errorf("unsupported bundle format_version %d, expected %d: %w", bundle.FormatVersion, models.TaxonomyBundleVersion, repository.ErrInvalidInput)
+	}
+
+	types := make(map[string]*models.BundleObjectType, len(bundle.ObjectTypes))
+	for i := range bundle.ObjectTypes {
+		objectType := &bundle.ObjectTypes[i]
+		if objectType.Name == "" {
+			return fmt.Errorf("object_types[%d]: name is required: %w", i, repository.ErrInvalidInput)
+		}
+		if types[objectType.Name] != nil {
+			return fmt.Errorf("object type %q is listed twice: %w", objectType.Name, repository.ErrInvalidInput)
+		}
+		if err := checkSearchMetadataKeys(objectType.SearchMetadataKeys); err != nil {
+			return fmt.Errorf("object type %q: %w", objectType.Name, err)
+		}
+		types[objectType.Name] = objectType
+	}
+	for _, objectType := range bundle.ObjectTypes {
+		if parent := types[objectType.Parent]; parent != nil && parent.IsSealed {
+			return fmt.Errorf("object type %q cannot inherit from sealed type %q: %w", objectType.Name, parent.Name, repository.ErrInvalidInput)
+		}
+		seen := map[string]bool{objectType.Name: true}
+		for parent := types[objectType.Parent]; parent != nil; parent = types[parent.Parent] {
+			if seen[parent.Name] {
+				return fmt.Errorf("object type %q is part of a parent cycle: %w", objectType.Name, repository.ErrInvalidInput)
+			}
+			seen[parent.Name] = true
+		}
+	}
+
+	relTypes := make(map[string]*models.BundleRelationshipType, len(bundle.RelationshipTypes))
+	for i := range bundle.RelationshipTypes {
+		relType := &bundle.RelationshipTypes[i]
+		if relType.TypeKey == "" {
+			return fmt.Errorf("relationship_types[%d]: type_key is required: %w", i, repository.ErrInvalidInput)
+		}
+		if relTypes[relType.TypeKey] != nil {
+			return fmt.Errorf("relationship type %q is listed twice: %w", relType.TypeKey, repository.ErrInvalidInput)
+		}
+		if !models.IsValidCardinality(relType.Cardinality) {
+			return fmt.Errorf("relationship type %q: %w %q", relType.TypeKey, ErrInvalidCardinality, relType.Cardinality)
+		}
+		if relType.MinCount < 0 || relType.MaxCount < -1 || (relType.MaxCount >= 0 && relType.MinCount > relType.MaxCount) {
+			return fmt.Errorf("relationship type %q: %w", relType.TypeKey, ErrInvalidCountConstraint)
+		}
+		if err := validateRulesDocument(relType.ValidationRules); err != nil {
+			return fmt.Errorf("relationship type %q: %w", relType.TypeKey, err)
+		}
+		relTypes[relType.TypeKey] = relType
+	}
+	for _, relType := range bundle.RelationshipTypes {
+		if relType.ReverseTypeKey == "" {
+			continue
+		}
+		reverse := relTypes[relType.ReverseTypeKey]
+		switch {
+		case relType.ReverseTypeKey == relType.TypeKey:
+			return fmt.Errorf("%w: %q cannot reference itself", ErrInvalidReverseType, relType.TypeKey)
+		case reverse == nil:
+			return fmt.Errorf("%w: reverse type %q of %q is not in the bundle", ErrInvalidReverseType, relType.ReverseTypeKey, relType.TypeKey)
+		case reverse.ReverseTypeKey != relType.TypeKey:
+			return fmt.Errorf("%w: %q and %q do not name each other as reverse types", ErrInvalidReverseType, relType.TypeKey, reverse.TypeKey)
+		case reverse.Cardinality != models.ReverseCardinality(relType.Cardinality):
+			return fmt.Errorf("%w: %q has cardinality %s, expected %s", ErrInvalidReverseType, reverse.TypeKey, reverse.Cardinality, models.ReverseCardinality(relType.Cardinality))
+		}
+	}
+
+	return nil
+}
+
+// objectTypeChange is a planned change of an existing object type: a move, field updates or both
+type objectTypeChange struct {
+	objectType *models.ObjectType
+	newParent  string
+	req        *models.UpdateObjectTypeRequest
+}
+
+// relTypeChange is a planned update of an existing relationship type's fields
+type relTypeChange struct {
+	relType *models.RelationshipType
+	req     *models.UpdateRelationshipTypeRequest
+}
+
+// taxonomyDiff is a plan together with what applying it takes
+type taxonomyDiff struct {
+	plan *models.TaxonomyPlan
+
+	liveTypeIDs    map[string]int64
+	createTypes    []*models.BundleObjectType
+	changeTypes    []objectTypeChange
+	deleteTypes    []*models.ObjectType
+	createRelTypes []*models.BundleRelationshipType
+	changeRelTypes []relTypeChange
+	reverseKeys    map[string]string // Type key to its new reverse type key, "" to unpair
+	deleteRelTypes []*models.RelationshipType
+}
+
+func (d *taxonomyDiff) add(change models.TaxonomyChange) {
+	d.plan.Changes = append(d.plan.Changes, change)
+	d.plan.Summary[change.Action]++
+}
+
+// diffTaxonomy plans the changes that turn the live taxonomy into the bundle. Changes are listed in
+// the order they are applied: object types, then relationship types, then deletions.
+func diffTaxonomy(ctx context.Context, objectTypeRepo repository.ObjectTypeRepository, relTypeRepo repository.RelationshipTypeRepository, bundle *models.TaxonomyBundle, prune bool) (*taxonomyDiff, error) {
+	liveTypes, err := objectTypeRepo.List(ctx, &models.ObjectTypeFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list object types: %w", err)
+	}
+	liveRelTypes, err := listAllRelationshipTypes(ctx, relTypeRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &taxonomyDiff{
+		plan: &models.TaxonomyPlan{
+			Changes: []models.TaxonomyChange{},
+			Summary: map[string]int{},
+		},
+		liveTypeIDs: map[string]int64{},
+		reverseKeys: map[string]string{},
+	}
+	if err := diff.objectTypes(ctx, objectTypeRepo, bundle, liveTypes, prune); err != nil {
+		return nil, err
+	}
+	if err := diff.relationshipTypes(ctx, relTypeRepo, bundle, liveRelTypes, prune); err != nil {
+		return nil, err
+	}
+	diff.deletions()
+	return diff, nil
+}
+
+func (d *taxonomyDiff) objectTypes(ctx context.Context, repo repository.ObjectTypeRepository, bundle *models.TaxonomyBundle, liveTypes []*models.ObjectType, prune bool) error {
+	live := make(map[string]*models.ObjectType, len(liveTypes))
+	names := make(map[int64]string, len(liveTypes))
+	for _, objectType := range liveTypes {
+		live[objectType.Name] = objectType
+		names[objectType.ID] = objectType.Name
+		d.liveTypeIDs[objectType.Name] = objectType.ID
+	}
+	inBundle := make(map[string]bool, len(bundle.ObjectTypes))
+	for _, objectType := range bundle.ObjectTypes {
+		inBundle[objectType.Name] = true
+	}
+
+	for _, entry := range bundleParentsFirst(bundle.ObjectTypes) {
+		if entry.Parent != "" && !inBundle[entry.Parent] {
+			parent := live[entry.Parent]
+			if parent == nil || prune {
+				return fmt.Errorf("parent %q of object type %q is neither in the bundle nor kept: %w", entry.Parent, entry.Name, repository.ErrInvalidInput)
+			}
+			if parent.IsSealed {
+				return fmt.Errorf("object type %q cannot inherit from sealed type %q: %w", entry.Name, parent.Name, repository.ErrInvalidInput)
+			}
+		}
+
+		existing := live[entry.Name]
+		if existing == nil {
+			d.createTypes = append(d.createTypes, entry)
+			d.add(models.TaxonomyChange{Action: models.TaxonomyActionCreate, Kind: models.TaxonomyKindObjectType, Key: entry.Name})
+			continue
+		}
+
+		change := objectTypeChange{objectType: existing}
+		currentParent := ""
+		if existing.ParentTypeID != nil {
+			currentParent = names[*existing.ParentTypeID]
+		}
+		if entry.Parent != currentParent {
+			if entry.Parent == "" {
+				return fmt.Errorf("object type %q cannot be moved to the root: %w", entry.Name, repository.ErrInvalidInput)
+			}
+			change.newParent = entry.Parent
+			from, to := currentParent, entry.Parent
+			d.add(models.TaxonomyChange{Action: models.TaxonomyActionMove, Kind: models.TaxonomyKindObjectType, Key: entry.Name, FromParent: &from, ToParent: &to})
+		}
+
+		req, fields, err := objectTypeUpdate(existing, entry)
+		if err != nil {
+			return err
+		}
+		if len(fields) > 0 {
+			change.req = req
+			d.add(models.TaxonomyChange{Action: models.TaxonomyActionUpdate, Kind: models.TaxonomyKindObjectType, Key: entry.Name, Fields: fields})
+		}
+		if change.newParent != "" || change.req != nil {
+			d.changeTypes = append(d.changeTypes, change)
+		}
+	}
+
+	if !prune {
+		return nil
+	}
+	// Children are deleted before their parents
+	ordered := parentsFirst(liveTypes)
+	for i := len(ordered) - 1; i >= 0; i-- {
+		objectType := ordered[i]
+		if inBundle[objectType.Name] {
+			continue
+		}
+		if objectType.IsSealed {
+			return fmt.Errorf("cannot delete sealed object type %q: %w", objectType.Name, repository.ErrInvalidInput)
+		}
+		count, err := repo.GetSubtreeObjectCount(ctx, objectType.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check object count: %w", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("cannot delete object type %q with existing objects: %w", objectType.Name, repository.ErrInvalidInput)
+		}
+		d.deleteTypes = append(d.deleteTypes, objectType)
+	}
+	return nil
+}
+
+// objectTypeUpdate returns the update turning an object type into its bundle entry and the names
+// of the changed fields. A concrete table name missing from the bundle is kept.
+func objectTypeUpdate(existing *models.ObjectType, entry *models.BundleObjectType) (*models.UpdateObjectTypeRequest, []string, error) {
+	req := &models.UpdateObjectTypeRequest{}
+	var fields []string
+
+	if entry.ConcreteTableName != "" && (existing.ConcreteTableName == nil || *existing.ConcreteTableName != entry.ConcreteTableName) {
+		req.ConcreteTableName = &entry.ConcreteTableName
+		fields = append(fields, "concrete_table_name")
+	}
+	if entry.Description != existing.Description {
+		req.Description = &entry.Description
+		fields = append(fields, "description")
+	}
+	if entry.IsSealed != existing.IsSealed {
+		req.IsSealed = &entry.IsSealed
+		fields = append(fields, "is_sealed")
+	}
+	if !sameJSONObject(existing.Metadata, entry.Metadata) {
+		metadata := entry.Metadata
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+		req.Metadata = &metadata
+		fields = append(fields, "metadata")
+	}
+	if !sameJSONObject(existing.MetadataSchema, entry.MetadataSchema) {
+		schema := json.RawMessage("{}")
+		if len(entry.MetadataSchema) > 0 {
+			raw, err := json.Marshal(entry.MetadataSchema)
+			if err != nil {
+				return nil, nil, fmt.Errorf("object type %q: invalid metadata_schema: %w", entry.Name, repository.ErrInvalidInput)
+			}
+			schema = raw
+		}
+		req.MetadataSchema = &schema
+		fields = append(fields, "metadata_schema")
+	}
+	if !sameStrings(existing.SearchMetadataKeys, entry.SearchMetadataKeys) {
+		keys := entry.SearchMetadataKeys
+		req.SearchMetadataKeys = &keys
+		fields = append(fields, "search_metadata_keys")
+	}
+
+	return req, fields, nil
+}
+
+func (d *taxonomyDiff) relationshipTypes(ctx context.Context, repo repository.RelationshipTypeRepository, bundle *models.TaxonomyBundle, liveRelTypes []*models.RelationshipType, prune bool) error {
+	live := make(map[string]*models.RelationshipType, len(liveRelTypes))
+	for _, relType := range liveRelTypes {
+		live[relType.TypeKey] = relType
+	}
+	wanted := make(map[string]*models.BundleRelationshipType, len(bundle.RelationshipTypes))
+	for i := range bundle.RelationshipTypes {
+		wanted[bundle.RelationshipTypes[i].TypeKey] = &bundle.RelationshipTypes[i]
+	}
+
+	for i := range bundle.RelationshipTypes {
+		entry := &bundle.RelationshipTypes[i]
+		existing := live[entry.TypeKey]
+		if existing == nil {
+			d.createRelTypes = append(d.createRelTypes, entry)
+			if entry.ReverseTypeKey != "" {
+				d.reverseKeys[entry.TypeKey] = entry.ReverseTypeKey
+			}
+			d.add(models.TaxonomyChange{Action: models.TaxonomyActionCreate, Kind: models.TaxonomyKindRelationshipType, Key: entry.TypeKey})
+			continue
+		}
+
+		req, fields := relationshipTypeUpdate(existing, entry)
+		if currentReverse(existing) != entry.ReverseTypeKey {
+			if err := checkNewPair(ctx, repo, existing, live[entry.ReverseTypeKey]); err != nil {
+				return err
+			}
+			d.reverseKeys[entry.TypeKey] = entry.ReverseTypeKey
+			fields = append(fields, "reverse_type_key")
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		d.changeRelTypes = append(d.changeRelTypes, relTypeChange{relType: existing, req: req})
+		d.add(models.TaxonomyChange{Action: models.TaxonomyActionUpdate, Kind: models.TaxonomyKindRelationshipType, Key: entry.TypeKey, Fields: fields})
+	}
+
+	for _, relType := range liveRelTypes {
+		if wanted[relType.TypeKey] != nil {
+			continue
+		}
+		if prune {
+			inUse, err := repo.HasRelationships(ctx, relType.ObjectID)
+			if err != nil {
+				return fmt.Errorf("failed to check relationship type usage: %w", err)
+			}
+			if inUse {
+				return fmt.Errorf("%w: type_key '%s'", ErrRelationshipTypeInUse, relType.TypeKey)
+			}
+			d.deleteRelTypes = append(d.deleteRelTypes, relType)
+			continue
+		}
+		// A kept type whose reverse type is paired elsewhere by the bundle loses its pairing
+		if reverse := wanted[currentReverse(relType)]; reverse != nil && reverse.ReverseTypeKey != relType.TypeKey {
+			d.reverseKeys[relType.TypeKey] = ""
+			d.add(models.TaxonomyChange{Action: models.TaxonomyActionUpdate, Kind: models.TaxonomyKindRelationshipType, Key: relType.TypeKey, Fields: []string{"reverse_type_key"}})
+		}
+	}
+	return nil
+}
+
+// relationshipTypeUpdate returns the update of an existing relationship type's own fields and the
+// names of the changed fields; the reverse type key is handled separately
+func relationshipTypeUpdate(existing *models.RelationshipType, entry *models.BundleRelationshipType) (*models.UpdateRelationshipTypeRequest, []string) {
+	req := &models.UpdateRelationshipTypeRequest{}
+	var fields []string
+
+	name := entry.RelationshipName
+	if name == "" {
+		name = entry.TypeKey
+	}
+	if name != existing.RelationshipName {
+		req.RelationshipName = &name
+		fields = append(fields, "relationship_name")
+	}
+	if entry.Cardinality != existing.Cardinality {
+		req.Cardinality = &entry.Cardinality
+		fields = append(fields, "cardinality")
+	}
+	if entry.Required != existing.Required {
+		req.Required = &entry.Required
+		fields = append(fields, "required")
+	}
+	if entry.MinCount != existing.MinCount {
+		req.MinCount = &entry.MinCount
+		fields = append(fields, "min_count")
+	}
+	if entry.MaxCount != existing.MaxCount {
+		req.MaxCount = &entry.MaxCount
+		fields = append(fields, "max_count")
+	}
+	if !sameJSONObject(existing.ValidationRules, entry.ValidationRules) {
+		rules := entry.ValidationRules
+		if rules == nil {
+			rules = map[string]interface{}{}
+		}
+		req.ValidationRules = &rules
+		fields = append(fields, "validation_rules")
+	}
+
+	return req, fields
+}
+
+// checkNewPair checks that two existing types can become a pair. Relationships are stored under
+// the older type of a pair, so the newer one must have none of its own.
+func checkNewPair(ctx context.Context, repo repository.RelationshipTypeRepository, relType, reverse *models.RelationshipType) error {
+	if reverse == nil {
+		return nil
+	}
+	alias := relType
+	if reverse.ObjectID > relType.ObjectID {
+		alias = reverse
+	}
+	inUse, err := repo.HasRelationships(ctx, alias.ObjectID)
+	if err != nil {
+		return fmt.Errorf("failed to check relationship type usage: %w", err)
+	}
+	if inUse {
+		return fmt.Errorf("%w: type_key '%s' already has relationships of its own", ErrInvalidReverseType, alias.TypeKey)
+	}
+	return nil
+}
+
+// deletions appends the planned deletions, relationship types before object types
+func (d *taxonomyDiff) deletions() {
+	for _, relType := range d.deleteRelTypes {
+		d.add(models.TaxonomyChange{Action: models.TaxonomyActionDelete, Kind: models.TaxonomyKindRelationshipType, Key: relType.TypeKey})
+	}
+	for _, objectType := range d.deleteTypes {
+		d.add(models.TaxonomyChange{Action: models.TaxonomyActionDelete, Kind: models.TaxonomyKindObjectType, Key: objectType.Name})
+	}
+}
+
+// apply makes the planned changes. Object types go through the object type service, so metadata
+// schemas are checked and outbox events written as for single changes.
+func (d *taxonomyDiff) apply(ctx context.Context, objectTypes *objectTypeService, relTypes repository.RelationshipTypeRepository, actor string) error {
+	typeIDs := make(map[string]int64, len(d.liveTypeIDs))
+	for name, id := range d.liveTypeIDs {
+		typeIDs[name] = id
+	}
+
+	for _, entry := range d.createTypes {
+		req, err := createObjectTypeRequest(entry, typeIDs, actor)
+		if err != nil {
+			return err
+		}
+		created, err := objectTypes.create(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to create object type %q: %w", entry.Name, err)
+		}
+		typeIDs[created.Name] = created.ID
+	}
+
+	for _, change := range d.changeTypes {
+		req := change.req
+		if req == nil {
+			req = &models.UpdateObjectTypeRequest{}
+		}
+		req.UpdatedBy = actor
+		if change.newParent != "" {
+			parentID := typeIDs[change.newParent]
+			if err := objectTypes.repo.ValidateMove(ctx, change.objectType.ID, &parentID); err != nil {
+				return fmt.Errorf("cannot move object type %q under %q: %v: %w", change.objectType.Name, change.newParent, err, repository.ErrInvalidInput)
+			}
+			req.ParentTypeID = &parentID
+		}
+		if _, err := objectTypes.update(ctx, change.objectType.ID, req); err != nil {
+			return fmt.Errorf("failed to update object type %q: %w", change.objectType.Name, err)
+		}
+	}
+
+	relTypeIDs := map[string]int64{}
+	for _, entry := range d.createRelTypes {
+		created, err := relTypes.Create(ctx, &models.CreateRelationshipTypeRequest{
+			TypeKey:          entry.TypeKey,
+			RelationshipName: entry.RelationshipName,
+			Cardinality:      entry.Cardinality,
+			Required:         entry.Required,
+			MinCount:         entry.MinCount,
+			MaxCount:         entry.MaxCount,
+			ValidationRules:  entry.ValidationRules,
+			CreatedBy:        actor,
+			UpdatedBy:        actor,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create relationship type %q: %w", entry.TypeKey, err)
+		}
+		relTypeIDs[created.TypeKey] = created.ObjectID
+	}
+
+	for _, change := range d.changeRelTypes {
+		relTypeIDs[change.relType.TypeKey] = change.relType.ObjectID
+		if reflect.DeepEqual(change.req, &models.UpdateRelationshipTypeRequest{}) {
+			continue
+		}
+		change.req.UpdatedBy = actor
+		if _, err := relTypes.Update(ctx, change.relType.ObjectID, change.req); err != nil {
+			return fmt.Errorf("failed to update relationship type %q: %w", change.relType.TypeKey, err)
+		}
+	}
+
+	if err := d.pairRelationshipTypes(ctx, relTypes, relTypeIDs, actor); err != nil {
+		return err
+	}
+
+	for _, relType := range d.deleteRelTypes {
+		if err := relTypes.Delete(ctx, relType.ObjectID); err != nil {
+			return fmt.Errorf("failed to delete relationship type %q: %w", relType.TypeKey, err)
+		}
+	}
+	for _, objectType := range d.deleteTypes {
+		if err := objectTypes.delete(ctx, objectType.ID); err != nil {
+			return fmt.Errorf("failed to delete object type %q: %w", objectType.Name, err)
+		}
+	}
+	return nil
+}
+
+// pairRelationshipTypes sets the new reverse type keys. Every changed pairing is cleared first so
+// no type is ever the reverse of two others.
+func (d *taxonomyDiff) pairRelationshipTypes(ctx context.Context, relTypes repository.RelationshipTypeRepository, relTypeIDs map[string]int64, actor string) error {
+	keys := make([]string, 0, len(d.reverseKeys))
+	for key := range d.reverseKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	unpaired := ""
+	for _, key := range keys {
+		id, err := relationshipTypeID(ctx, relTypes, relTypeIDs, key)
+		if err != nil {
+			return err
+		}
+		_, err = relTypes.Update(ctx, id, &models.UpdateRelationshipTypeRequest{ReverseTypeKey: &unpaired, UpdatedBy: actor})
+		if err != nil {
+			return fmt.Errorf("failed to unpair relationship type %q: %w", key, err)
+		}
+	}
+	for _, key := range keys {
+		reverseKey := d.reverseKeys[key]
+		if reverseKey == "" {
+			continue
+		}
+		_, err := relTypes.Update(ctx, relTypeIDs[key], &models.UpdateRelationshipTypeRequest{ReverseTypeKey: &reverseKey, UpdatedBy: actor})
+		if err != nil {
+			return fmt.Errorf("failed to pair relationship type %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// relationshipTypeID returns the ID of a relationship type created or changed by the import, or
+// looks up a type that is only unpaired
+func relationshipTypeID(ctx context.Context, relTypes repository.RelationshipTypeRepository, relTypeIDs map[string]int64, key string) (int64, error) {
+	if id, ok := relTypeIDs[key]; ok {
+		return id, nil
+	}
+	relType, err := relTypes.GetByTypeKey(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get relationship type %q: %w", key, err)
+	}
+	relTypeIDs[key] = relType.ObjectID
+	return relType.ObjectID, nil
+}
+
+func createObjectTypeRequest(entry *models.BundleObjectType, typeIDs map[string]int64, actor string) (*models.CreateObjectTypeRequest, error) {
+	isSealed := entry.IsSealed
+	req := &models.CreateObjectTypeRequest{
+		Name:               entry.Name,
+		Description:        entry.Description,
+		IsSealed:           &isSealed,
+		Metadata:           entry.Metadata,
+		SearchMetadataKeys: entry.SearchMetadataKeys,
+		CreatedBy:          actor,
+		UpdatedBy:          actor,
+	}
+	if entry.Parent != "" {
+		parentID := typeIDs[entry.Parent]
+		req.ParentTypeID = &parentID
+	}
+	if entry.ConcreteTableName != "" {
+		req.ConcreteTableName = &entry.ConcreteTableName
+	}
+	if len(entry.MetadataSchema) > 0 {
+		schema, err := json.Marshal(entry.MetadataSchema)
+		if err != nil {
+			return nil, fmt.Errorf("object type %q: invalid metadata_schema: %w", entry.Name, repository.ErrInvalidInput)
+		}
+		req.MetadataSchema = schema
+	}
+	return req, nil
+}
+
+// listAllRelationshipTypes pages through every relationship type, ordered by type key
+func listAllRelationshipTypes(ctx context.Context, repo repository.RelationshipTypeRepository) ([]*models.RelationshipType, error) {
+	var all []*models.RelationshipType
+	for page := 1; ; page++ {
+		filter := &models.RelationshipTypeFilter{Page: page, PageSize: 100}
+		relTypes, err := repo.List(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list relationship types: %w", err)
+		}
+		all = append(all, relTypes...)
+		if len(relTypes) < filter.PageSize {
+			break
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].TypeKey < all[j].TypeKey })
+	return all, nil
+}
+
+// parentsFirst orders object types depth first from the roots, siblings by name
+func parentsFirst(objectTypes []*models.ObjectType) []*models.ObjectType {
+	byID := make(map[int64]bool, len(objectTypes))
+	for _, objectType := range objectTypes {
+		byID[objectType.ID] = true
+	}
+	children := map[int64][]*models.ObjectType{}
+	var roots []*models.ObjectType
+	for _, objectType := range objectTypes {
+		if objectType.ParentTypeID == nil || !byID[*objectType.ParentTypeID] {
+			roots = append(roots, objectType)
+			continue
+		}
+		children[*objectType.ParentTypeID] = append(children[*objectType.ParentTypeID], objectType)
+	}
+
+	ordered := make([]*models.ObjectType, 0, len(objectTypes))
+	var visit func(level []*models.ObjectType)
+	visit = func(level []*models.ObjectType) {
+		sort.Slice(level, func(i, j int) bool { return level[i].Name < level[j].Name })
+		for _, objectType := range level {
+			ordered = append(ordered, objectType)
+			visit(children[objectType.ID])
+		}
+	}
+	visit(roots)
+	return ordered
+}
+
+// bundleParentsFirst orders bundle entries so that every parent in the bundle precedes its
+// children, keeping the bundle order otherwise. The bundle is known to have no parent cycles.
+func bundleParentsFirst(entries []models.BundleObjectType) []*models.BundleObjectType {
+	index := make(map[string]int, len(entries))
+	for i, entry := range entries {
+		index[entry.Name] = i
+	}
+
+	ordered := make([]*models.BundleObjectType, 0, len(entries))
+	placed := make(map[string]bool, len(entries))
+	var place func(i int)
+	place = func(i int) {
+		entry := &entries[i]
+		if placed[entry.Name] {
+			return
+		}
+		if parent, ok := index[entry.Parent]; ok {
+			place(parent)
+		}
+		placed[entry.Name] = true
+		ordered = append(ordered, entry)
+	}
+	for i := range entries {
+		place(i)
+	}
+	return ordered
+}
+
+// decodeJSONObject decodes a stored JSON object, returning nil for an empty or missing one
+func decodeJSONObject(raw json.RawMessage) map[string]interface{} {
+	var object map[string]interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &object) != nil || len(object) == 0 {
+		return nil
+	}
+	return object
+}
+
+// sameJSONObject reports whether a stored JSON object equals a bundle object; a missing object
+// equals an empty one
+func sameJSONObject(raw json.RawMessage, object map[string]interface{}) bool {
+	stored := decodeJSONObject(raw)
+	if len(stored) == 0 || len(object) == 0 {
+		return len(stored) == len(object)
+	}
+	// Round trip the bundle object so that numbers compare as they are stored
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(stored, decodeJSONObject(encoded))
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func currentReverse(relType *models.RelationshipType) string {
+	if relType.ReverseTypeKey == nil {
+		return ""
+	}
+	return *relType.ReverseTypeKey
+}
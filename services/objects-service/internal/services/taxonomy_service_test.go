@@ -0,0 +1,618 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// memoryObjectTypes is an in-memory ObjectTypeRepository holding the object type tree and the
+// number of objects of each type
+type memoryObjectTypes struct {
+	repository.ObjectTypeRepository
+	types   map[int64]*models.ObjectType
+	objects map[int64]int64
+	nextID  int64
+}
+
+func newMemoryObjectTypes() *memoryObjectTypes {
+	return &memoryObjectTypes{types: map[int64]*models.ObjectType{}, objects: map[int64]int64{}}
+}
+
+// add stores an object type under a parent given by name, "" for a root
+func (r *memoryObjectTypes) add(name, parent string, sealed bool) *models.ObjectType {
+	r.nextID++
+	objectType := &models.ObjectType{ID: r.nextID, Name: name, IsSealed: sealed}
+	if parent != "" {
+		parentID := r.byName(parent).ID
+		objectType.ParentTypeID = &parentID
+	}
+	r.types[objectType.ID] = objectType
+	return objectType
+}
+
+func (r *memoryObjectTypes) byName(name string) *models.ObjectType {
+	for _, objectType := range r.types {
+		if objectType.Name == name {
+			return objectType
+		}
+	}
+	return nil
+}
+
+func (r *memoryObjectTypes) parentName(name string) string {
+	objectType := r.byName(name)
+	if objectType == nil || objectType.ParentTypeID == nil {
+		return ""
+	}
+	return r.types[*objectType.ParentTypeID].Name
+}
+
+func (r *memoryObjectTypes) List(ctx context.Context, filter *models.ObjectTypeFilter) ([]*models.ObjectType, error) {
+	list := make([]*models.ObjectType, 0, len(r.types))
+	for _, objectType := range r.types {
+		copied := *objectType
+		list = append(list, &copied)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (r *memoryObjectTypes) GetByID(ctx context.Context, id int64) (*models.ObjectType, error) {
+	objectType, ok := r.types[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *objectType
+	return &copied, nil
+}
+
+func (r *memoryObjectTypes) GetPath(ctx context.Context, id int64) ([]*models.ObjectType, error) {
+	var path []*models.ObjectType
+	for objectType := r.types[id]; objectType != nil; {
+		path = append([]*models.ObjectType{objectType}, path...)
+		if objectType.ParentTypeID == nil {
+			break
+		}
+		objectType = r.types[*objectType.ParentTypeID]
+	}
+	return path, nil
+}
+
+func (r *memoryObjectTypes) Create(ctx context.Context, input *models.CreateObjectTypeRequest) (*models.ObjectType, error) {
+	if r.byName(input.Name) != nil {
+		return nil, repository.ErrAlreadyExists
+	}
+	r.nextID++
+	metadata, _ := json.Marshal(input.Metadata)
+	objectType := &models.ObjectType{
+		ID:                 r.nextID,
+		Name:               input.Name,
+		ParentTypeID:       input.ParentTypeID,
+		ConcreteTableName:  input.ConcreteTableName,
+		Description:        input.Description,
+		IsSealed:           input.IsSealed != nil && *input.IsSealed,
+		Metadata:           metadata,
+		MetadataSchema:     input.MetadataSchema,
+		SearchMetadataKeys: input.SearchMetadataKeys,
+	}
+	r.types[objectType.ID] = objectType
+	copied := *objectType
+	return &copied, nil
+}
+
+func (r *memoryObjectTypes) Update(ctx context.Context, id int64, input *models.UpdateObjectTypeRequest) (*models.ObjectType, error) {
+	objectType, ok := r.types[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if input.ParentTypeID != nil {
+		objectType.ParentTypeID = input.ParentTypeID
+	}
+	if input.ConcreteTableName != nil {
+		objectType.ConcreteTableName = input.ConcreteTableName
+	}
+	if input.Description != nil {
+		objectType.Description = *input.Description
+	}
+	if input.IsSealed != nil {
+		objectType.IsSealed = *input.IsSealed
+	}
+	if input.Metadata != nil {
+		objectType.Metadata, _ = json.Marshal(*input.Metadata)
+	}
+	if input.MetadataSchema != nil {
+		objectType.MetadataSchema = *input.MetadataSchema
+	}
+	if input.SearchMetadataKeys != nil {
+		objectType.SearchMetadataKeys = *input.SearchMetadataKeys
+	}
+	copied := *objectType
+	return &copied, nil
+}
+
+func (r *memoryObjectTypes) Delete(ctx context.Context, id int64) error {
+	delete(r.types, id)
+	return nil
+}
+
+func (r *memoryObjectTypes) GetSubtreeObjectCount(ctx context.Context, id int64) (int64, error) {
+	count := r.objects[id]
+	for _, objectType := range r.types {
+		if objectType.ParentTypeID != nil && *objectType.ParentTypeID == id {
+			childCount, _ := r.GetSubtreeObjectCount(ctx, objectType.ID)
+			count += childCount
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryObjectTypes) ValidateMove(ctx context.Context, id int64, newParentID *int64) error {
+	for parentID := newParentID; parentID != nil; parentID = r.types[*parentID].ParentTypeID {
+		if *parentID == id {
+			return errors.New("circular dependency")
+		}
+	}
+	return nil
+}
+
+// memoryRelationshipTypes is an in-memory RelationshipTypeRepository; inUse marks the types that
+// have relationships of their own
+type memoryRelationshipTypes struct {
+	repository.RelationshipTypeRepository
+	types  map[int64]*models.RelationshipType
+	inUse  map[int64]bool
+	nextID int64
+}
+
+func newMemoryRelationshipTypes() *memoryRelationshipTypes {
+	return &memoryRelationshipTypes{types: map[int64]*models.RelationshipType{}, inUse: map[int64]bool{}, nextID: 1000}
+}
+
+func (r *memoryRelationshipTypes) add(typeKey, reverseKey, cardinality string) *models.RelationshipType {
+	r.nextID++
+	relType := &models.RelationshipType{ObjectID: r.nextID, TypeKey: typeKey, RelationshipName: typeKey, Cardinality: cardinality, MaxCount: -1}
+	if reverseKey != "" {
+		relType.ReverseTypeKey = &reverseKey
+	}
+	r.types[relType.ObjectID] = relType
+	return relType
+}
+
+func (r *memoryRelationshipTypes) byKey(typeKey string) *models.RelationshipType {
+	for _, relType := range r.types {
+		if relType.TypeKey == typeKey {
+			return relType
+		}
+	}
+	return nil
+}
+
+func (r *memoryRelationshipTypes) List(ctx context.Context, filter *models.RelationshipTypeFilter) ([]*models.RelationshipType, error) {
+	var all []*models.RelationshipType
+	for _, relType := range r.types {
+		copied := *relType
+		all = append(all, &copied)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].ObjectID < all[j].ObjectID })
+	start := (filter.Page - 1) * filter.PageSize
+	if start >= len(all) {
+		return nil, nil
+	}
+	end := start + filter.PageSize
+	if end > len(all) {
+		end = len(all)
+	}
+	return all[start:end], nil
+}
+
+func (r *memoryRelationshipTypes) GetByTypeKey(ctx context.Context, typeKey string) (*models.RelationshipType, error) {
+	relType := r.byKey(typeKey)
+	if relType == nil {
+		return nil, repository.ErrNotFound
+	}
+	copied := *relType
+	return &copied, nil
+}
+
+func (r *memoryRelationshipTypes) Create(ctx context.Context, input *models.CreateRelationshipTypeRequest) (*models.RelationshipType, error) {
+	relType := r.add(input.TypeKey, "", input.Cardinality)
+	if input.RelationshipName != "" {
+		relType.RelationshipName = input.RelationshipName
+	}
+	relType.Required = input.Required
+	relType.MinCount = input.MinCount
+	relType.MaxCount = input.MaxCount
+	relType.ValidationRules, _ = json.Marshal(input.ValidationRules)
+	copied := *relType
+	return &copied, nil
+}
+
+func (r *memoryRelationshipTypes) Update(ctx context.Context, id int64, input *models.UpdateRelationshipTypeRequest) (*models.RelationshipType, error) {
+	relType, ok := r.types[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	if input.RelationshipName != nil {
+		relType.RelationshipName = *input.RelationshipName
+	}
+	if input.ReverseTypeKey != nil {
+		if *input.ReverseTypeKey == "" {
+			relType.ReverseTypeKey = nil
+		} else {
+			for _, other := range r.types {
+				if other.ObjectID != id && other.ReverseTypeKey != nil && *other.ReverseTypeKey == *input.ReverseTypeKey {
+					return nil, repository.ErrAlreadyExists
+				}
+			}
+			reverseKey := *input.ReverseTypeKey
+			relType.ReverseTypeKey = &reverseKey
+		}
+	}
+	if input.Cardinality != nil {
+		relType.Cardinality = *input.Cardinality
+	}
+	if input.Required != nil {
+		relType.Required = *input.Required
+	}
+	if input.MinCount != nil {
+		relType.MinCount = *input.MinCount
+	}
+	if input.MaxCount != nil {
+		relType.MaxCount = *input.MaxCount
+	}
+	if input.ValidationRules != nil {
+		relType.ValidationRules, _ = json.Marshal(*input.ValidationRules)
+	}
+	copied := *relType
+	return &copied, nil
+}
+
+func (r *memoryRelationshipTypes) Delete(ctx context.Context, id int64) error {
+	delete(r.types, id)
+	return nil
+}
+
+func (r *memoryRelationshipTypes) HasRelationships(ctx context.Context, id int64) (bool, error) {
+	return r.inUse[id], nil
+}
+
+// newTaxonomyFixture returns a live taxonomy of Product > Book > EBook plus Tag, with the
+// contains/contained_in pair and a standalone links_to relationship type
+func newTaxonomyFixture() (*memoryObjectTypes, *memoryRelationshipTypes) {
+	objectTypes := newMemoryObjectTypes()
+	objectTypes.add("Product", "", false)
+	objectTypes.add("Book", "Product", false)
+	objectTypes.add("EBook", "Book", false)
+	objectTypes.add("Tag", "", false)
+
+	relTypes := newMemoryRelationshipTypes()
+	relTypes.add("contains", "contained_in", "one_to_many")
+	relTypes.add("contained_in", "contains", "many_to_one")
+	relTypes.add("links_to", "", "many_to_many")
+	return objectTypes, relTypes
+}
+
+func exportTaxonomy(t *testing.T, service TaxonomyService) *models.TaxonomyBundle {
+	t.Helper()
+	bundle, err := service.Export(context.Background())
+	require.NoError(t, err)
+	return bundle
+}
+
+func bundleObjectType(bundle *models.TaxonomyBundle, name string) *models.BundleObjectType {
+	for i := range bundle.ObjectTypes {
+		if bundle.ObjectTypes[i].Name == name {
+			return &bundle.ObjectTypes[i]
+		}
+	}
+	return nil
+}
+
+func planKeys(plan *models.TaxonomyPlan) []string {
+	keys := make([]string, 0, len(plan.Changes))
+	for _, change := range plan.Changes {
+		keys = append(keys, change.Action+" "+change.Kind+" "+change.Key)
+	}
+	return keys
+}
+
+func TestTaxonomyService_ExportListsParentsFirst(t *testing.T) {
+	objectTypes, relTypes := newTaxonomyFixture()
+	bundle := exportTaxonomy(t, NewTaxonomyService(objectTypes, relTypes, nil))
+
+	assert.Equal(t, models.TaxonomyBundleVersion, bundle.FormatVersion)
+	require.Len(t, bundle.ObjectTypes, 4)
+	names := []string{}
+	for _, entry := range bundle.ObjectTypes {
+		names = append(names, entry.Name)
+	}
+	assert.Equal(t, []string{"Product", "Book", "EBook", "Tag"}, names)
+	assert.Equal(t, "Book", bundleObjectType(bundle, "EBook").Parent)
+
+	require.Len(t, bundle.RelationshipTypes, 3)
+	assert.Equal(t, "contained_in", bundle.RelationshipTypes[0].TypeKey)
+	assert.Equal(t, "contains", bundle.RelationshipTypes[0].ReverseTypeKey)
+}
+
+func TestTaxonomyService_ImportOwnExportIsEmptyPlan(t *testing.T) {
+	objectTypes, relTypes := newTaxonomyFixture()
+	service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+	plan, err := service.Import(context.Background(), exportTaxonomy(t, service), models.TaxonomyImportOptions{Prune: true})
+	require.NoError(t, err)
+	assert.Empty(t, plan.Changes)
+	assert.True(t, plan.Applied)
+}
+
+func TestTaxonomyService_DryRunPlansWithoutChanges(t *testing.T) {
+	objectTypes, relTypes := newTaxonomyFixture()
+	service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+	bundle := exportTaxonomy(t, service)
+	bundle.ObjectTypes = append(bundle.ObjectTypes, models.BundleObjectType{Name: "AudioBook", Parent: "Book"})
+	bundleObjectType(bundle, "Tag").Description = "Free-form label"
+	bundleObjectType(bundle, "EBook").Parent = "Product"
+
+	plan, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.False(t, plan.Applied)
+	assert.Equal(t, []string{
+		"move object_type EBook",
+		"update object_type Tag",
+		"create object_type AudioBook",
+	}, planKeys(plan))
+	assert.Equal(t, map[string]int{"create": 1, "update": 1, "move": 1}, plan.Summary)
+	assert.Equal(t, "Book", *plan.Changes[0].FromParent)
+	assert.Equal(t, "Product", *plan.Changes[0].ToParent)
+	assert.Equal(t, []string{"description"}, plan.Changes[1].Fields)
+
+	assert.Nil(t, objectTypes.byName("AudioBook"))
+	assert.Equal(t, "Book", objectTypes.parentName("EBook"))
+	assert.Empty(t, objectTypes.byName("Tag").Description)
+}
+
+func TestTaxonomyService_ImportAppliesPlan(t *testing.T) {
+	objectTypes, relTypes := newTaxonomyFixture()
+	service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+	bundle := exportTaxonomy(t, service)
+	bundle.ObjectTypes = append(bundle.ObjectTypes,
+		models.BundleObjectType{Name: "Paperback", Parent: "Hardcopy"},
+		models.BundleObjectType{Name: "Hardcopy", Parent: "Book", Metadata: map[string]interface{}{"format": "print"}},
+	)
+	bundleObjectType(bundle, "EBook").Parent = "Product"
+	bundle.RelationshipTypes = append(bundle.RelationshipTypes, models.BundleRelationshipType{
+		TypeKey: "tagged", Cardinality: "many_to_many", MinCount: 0, MaxCount: -1,
+	})
+
+	plan, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{Actor: "admin"})
+	require.NoError(t, err)
+	assert.True(t, plan.Applied)
+	assert.Equal(t, map[string]int{"create": 3, "move": 1}, plan.Summary)
+
+	assert.Equal(t, "Book", objectTypes.parentName("Hardcopy"))
+	assert.Equal(t, "Hardcopy", objectTypes.parentName("Paperback"))
+	assert.Equal(t, "Product", objectTypes.parentName("EBook"))
+	assert.JSONEq(t, `{"format":"print"}`, string(objectTypes.byName("Hardcopy").Metadata))
+	assert.NotNil(t, relTypes.byKey("tagged"))
+
+	// Importing the same bundle again has nothing left to do
+	again, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, again.Changes)
+}
+
+func TestTaxonomyService_PruneDeletesMissingTypes(t *testing.T) {
+	objectTypes, relTypes := newTaxonomyFixture()
+	service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+	bundle := exportTaxonomy(t, service)
+	bundle.ObjectTypes = bundle.ObjectTypes[:1] // Only Product
+	bundle.RelationshipTypes = bundle.RelationshipTypes[:2]
+
+	// Without prune the missing types are kept
+	plan, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Empty(t, plan.Changes)
+
+	plan, err = service.Import(context.Background(), bundle, models.TaxonomyImportOptions{Prune: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"delete relationship_type links_to",
+		"delete object_type Tag",
+		"delete object_type EBook",
+		"delete object_type Book",
+	}, planKeys(plan))
+	assert.Len(t, objectTypes.types, 1)
+	assert.Nil(t, relTypes.byKey("links_to"))
+}
+
+func TestTaxonomyService_PruneRejectsTypesInUse(t *testing.T) {
+	t.Run("object type with objects", func(t *testing.T) {
+		objectTypes, relTypes := newTaxonomyFixture()
+		objectTypes.objects[objectTypes.byName("EBook").ID] = 3
+		service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+		bundle := exportTaxonomy(t, service)
+		bundle.ObjectTypes = bundle.ObjectTypes[:1]
+
+		_, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{Prune: true})
+		assert.ErrorIs(t, err, repository.ErrInvalidInput)
+		assert.Contains(t, err.Error(), "EBook")
+		assert.NotNil(t, objectTypes.byName("Tag"))
+	})
+
+	t.Run("sealed object type", func(t *testing.T) {
+		objectTypes, relTypes := newTaxonomyFixture()
+		objectTypes.byName("Tag").IsSealed = true
+		service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+		bundle := exportTaxonomy(t, service)
+		bundle.ObjectTypes = bundle.ObjectTypes[:3]
+
+		_, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{Prune: true})
+		assert.ErrorIs(t, err, repository.ErrInvalidInput)
+	})
+
+	t.Run("relationship type with relationships", func(t *testing.T) {
+		objectTypes, relTypes := newTaxonomyFixture()
+		relTypes.inUse[relTypes.byKey("links_to").ObjectID] = true
+		service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+		bundle := exportTaxonomy(t, service)
+		bundle.RelationshipTypes = bundle.RelationshipTypes[:2]
+
+		_, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{Prune: true})
+		assert.ErrorIs(t, err, ErrRelationshipTypeInUse)
+	})
+}
+
+func TestTaxonomyService_RejectsInvalidTrees(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(bundle *models.TaxonomyBundle)
+	}{
+		{
+			name: "child of a sealed type in the bundle",
+			modify: func(bundle *models.TaxonomyBundle) {
+				bundleObjectType(bundle, "Book").IsSealed = true
+			},
+		},
+		{
+			name: "parent cycle",
+			modify: func(bundle *models.TaxonomyBundle) {
+				bundleObjectType(bundle, "Product").Parent = "EBook"
+			},
+		},
+		{
+			name: "move to the root",
+			modify: func(bundle *models.TaxonomyBundle) {
+				bundleObjectType(bundle, "EBook").Parent = ""
+			},
+		},
+		{
+			name: "unknown parent",
+			modify: func(bundle *models.TaxonomyBundle) {
+				bundleObjectType(bundle, "Tag").Parent = "Label"
+			},
+		},
+		{
+			name: "duplicate name",
+			modify: func(bundle *models.TaxonomyBundle) {
+				bundle.ObjectTypes = append(bundle.ObjectTypes, models.BundleObjectType{Name: "Tag"})
+			},
+		},
+		{
+			name: "unsupported format version",
+			modify: func(bundle *models.TaxonomyBundle) {
+				bundle.FormatVersion = 2
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objectTypes, relTypes := newTaxonomyFixture()
+			service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+			bundle := exportTaxonomy(t, service)
+			tt.modify(bundle)
+
+			_, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{})
+			assert.ErrorIs(t, err, repository.ErrInvalidInput)
+			assert.Equal(t, "Book", objectTypes.parentName("EBook"))
+		})
+	}
+}
+
+func TestTaxonomyService_RejectsChildOfLiveSealedType(t *testing.T) {
+	objectTypes, relTypes := newTaxonomyFixture()
+	objectTypes.byName("Tag").IsSealed = true
+	service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+	bundle := &models.TaxonomyBundle{
+		FormatVersion: models.TaxonomyBundleVersion,
+		ObjectTypes:   []models.BundleObjectType{{Name: "Topic", Parent: "Tag"}},
+	}
+
+	_, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{})
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+	assert.Nil(t, objectTypes.byName("Topic"))
+}
+
+func TestTaxonomyService_ReverseTypes(t *testing.T) {
+	t.Run("must name each other", func(t *testing.T) {
+		objectTypes, relTypes := newTaxonomyFixture()
+		service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+		bundle := exportTaxonomy(t, service)
+		bundle.RelationshipTypes[2].ReverseTypeKey = "contains" // links_to
+
+		_, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{})
+		assert.ErrorIs(t, err, ErrInvalidReverseType)
+	})
+
+	t.Run("must mirror cardinality", func(t *testing.T) {
+		objectTypes, relTypes := newTaxonomyFixture()
+		service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+		bundle := exportTaxonomy(t, service)
+		bundle.RelationshipTypes[0].Cardinality = "one_to_one" // contained_in
+
+		_, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{})
+		assert.ErrorIs(t, err, ErrInvalidReverseType)
+	})
+
+	t.Run("re-pairing unpairs the old partner", func(t *testing.T) {
+		objectTypes, relTypes := newTaxonomyFixture()
+		service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+		bundle := exportTaxonomy(t, service)
+		bundle.RelationshipTypes = append(bundle.RelationshipTypes[1:2], // contains
+			models.BundleRelationshipType{TypeKey: "part_of", ReverseTypeKey: "contains", Cardinality: "many_to_one", MaxCount: -1},
+		)
+		bundle.RelationshipTypes[0].ReverseTypeKey = "part_of"
+
+		plan, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"update relationship_type contains",
+			"create relationship_type part_of",
+			"update relationship_type contained_in",
+		}, planKeys(plan))
+
+		assert.Equal(t, "part_of", *relTypes.byKey("contains").ReverseTypeKey)
+		assert.Equal(t, "contains", *relTypes.byKey("part_of").ReverseTypeKey)
+		assert.Nil(t, relTypes.byKey("contained_in").ReverseTypeKey)
+	})
+
+	t.Run("newer type of a new pair must be unused", func(t *testing.T) {
+		objectTypes, relTypes := newTaxonomyFixture()
+		relTypes.add("linked_from", "", "many_to_many")
+		relTypes.inUse[relTypes.byKey("linked_from").ObjectID] = true
+		service := NewTaxonomyService(objectTypes, relTypes, nil)
+
+		bundle := exportTaxonomy(t, service)
+		for i := range bundle.RelationshipTypes {
+			switch bundle.RelationshipTypes[i].TypeKey {
+			case "links_to":
+				bundle.RelationshipTypes[i].ReverseTypeKey = "linked_from"
+			case "linked_from":
+				bundle.RelationshipTypes[i].ReverseTypeKey = "links_to"
+			}
+		}
+
+		_, err := service.Import(context.Background(), bundle, models.TaxonomyImportOptions{})
+		assert.ErrorIs(t, err, ErrInvalidReverseType)
+		assert.Nil(t, relTypes.byKey("links_to").ReverseTypeKey)
+	})
+}
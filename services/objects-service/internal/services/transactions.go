@@ -14,23 +14,43 @@ import (
 type Transaction interface {
 	ObjectTypeRepository() repository.ObjectTypeRepository
 	ObjectRepository() repository.ObjectRepository
+	RelationshipTypeRepository() repository.RelationshipTypeRepository
+	RelationshipRepository() repository.RelationshipRepository
+	OutboxRepository() repository.OutboxRepository
+	ConcreteTableRepository() repository.ConcreteTableRepository
 	Commit(ctx context.Context) error
 	Rollback(ctx context.Context) error
 }
 
+// TxBeginner starts transactions; TransactionalDB is the production implementation
+type TxBeginner interface {
+	BeginTx(ctx context.Context, opts pgx.TxOptions) (Transaction, error)
+}
+
 // txDB implements Transaction using pgx.Tx
 type txDB struct {
-	tx             pgx.Tx
-	objectTypeRepo repository.ObjectTypeRepository
-	objectRepo     repository.ObjectRepository
+	tx                   pgx.Tx
+	objectTypeRepo       repository.ObjectTypeRepository
+	objectRepo           repository.ObjectRepository
+	relationshipTypeRepo repository.RelationshipTypeRepository
+	relationshipRepo     repository.RelationshipRepository
+	outboxRepo           repository.OutboxRepository
+	concreteTableRepo    repository.ConcreteTableRepository
 }
 
-// NewTransaction creates a new transaction wrapper
-func NewTransaction(tx pgx.Tx, objectTypeRepo repository.ObjectTypeRepository, objectRepo repository.ObjectRepository) Transaction {
+// NewTransaction creates a new transaction wrapper with every repository bound to tx
+func NewTransaction(tx pgx.Tx, options *repository.RepositoryOptions) Transaction {
+	wrappedTx := &txWrapper{tx: tx}
+
+	objectRepo := repository.NewObjectRepository(wrappedTx, options)
 	return &txDB{
-		tx:             tx,
-		objectTypeRepo: objectTypeRepo,
-		objectRepo:     objectRepo,
+		tx:                   tx,
+		objectTypeRepo:       repository.NewObjectTypeRepository(wrappedTx, options),
+		objectRepo:           objectRepo,
+		relationshipTypeRepo: repository.NewRelationshipTypeRepository(wrappedTx, options),
+		relationshipRepo:     repository.NewRelationshipRepository(wrappedTx, options, objectRepo),
+		outboxRepo:           repository.NewOutboxRepository(wrappedTx, options),
+		concreteTableRepo:    repository.NewConcreteTableRepository(wrappedTx, options),
 	}
 }
 
@@ -44,6 +64,26 @@ func (t *txDB) ObjectRepository() repository.ObjectRepository {
 	return t.objectRepo
 }
 
+// RelationshipTypeRepository returns the wrapped relationship type repository
+func (t *txDB) RelationshipTypeRepository() repository.RelationshipTypeRepository {
+	return t.relationshipTypeRepo
+}
+
+// RelationshipRepository returns the wrapped relationship repository
+func (t *txDB) RelationshipRepository() repository.RelationshipRepository {
+	return t.relationshipRepo
+}
+
+// OutboxRepository returns the wrapped outbox repository
+func (t *txDB) OutboxRepository() repository.OutboxRepository {
+	return t.outboxRepo
+}
+
+// ConcreteTableRepository returns the wrapped concrete table repository
+func (t *txDB) ConcreteTableRepository() repository.ConcreteTableRepository {
+	return t.concreteTableRepo
+}
+
 // Commit commits the transaction
 func (t *txDB) Commit(ctx context.Context) error {
 	return t.tx.Commit(ctx)
@@ -80,16 +120,7 @@ func (db *TransactionalDB) BeginTx(ctx context.Context, opts pgx.TxOptions) (Tra
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	wrappedTx := &txWrapper{tx: tx}
-
-	objectTypeRepo := repository.NewObjectTypeRepository(wrappedTx, nil)
-	objectRepo := repository.NewObjectRepository(wrappedTx, nil)
-
-	return &txDB{
-		tx:             tx,
-		objectTypeRepo: objectTypeRepo,
-		objectRepo:     objectRepo,
-	}, nil
+	return NewTransaction(tx, nil), nil
 }
 
 // txWrapper wraps pgx.Tx to implement repository.DBInterface
@@ -109,27 +140,37 @@ func (w *txWrapper) Exec(ctx context.Context, sql string, args ...any) (reposito
 	return w.tx.Exec(ctx, sql, args...)
 }
 
-// WithinTx executes a function within a transaction
+func (w *txWrapper) CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, rows pgx.CopyFromSource) (int64, error) {
+	return w.tx.CopyFrom(ctx, table, columns, rows)
+}
+
+// WithinTx executes a function within a serializable transaction
 // The function receives the transaction and should return an error
 // The transaction is automatically committed on success or rolled back on error
-func WithinTx(ctx context.Context, db *pgxpool.Pool, fn func(tx Transaction) error) error {
-	tx, err := db.BeginTx(ctx, pgx.TxOptions{
+func WithinTx(ctx context.Context, db TxBeginner, fn func(tx Transaction) error) error {
+	return WithinTxOptions(ctx, db, pgx.TxOptions{
 		IsoLevel:       pgx.Serializable,
 		AccessMode:     pgx.ReadWrite,
 		DeferrableMode: pgx.NotDeferrable,
-	})
+	}, fn)
+}
+
+// WithinTxOptions is WithinTx with custom transaction options
+func WithinTxOptions(ctx context.Context, db TxBeginner, opts pgx.TxOptions, fn func(tx Transaction) error) error {
+	tx, err := db.BeginTx(ctx, opts)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return err
 	}
 
-	txWrapper := NewTransaction(tx, nil, nil)
-
-	if err := fn(txWrapper); err != nil {
+	if err := fn(tx); err != nil {
 		if rbErr := tx.Rollback(ctx); rbErr != nil {
 			return fmt.Errorf("error: %v, rollback error: %w", err, rbErr)
 		}
 		return err
 	}
 
-	return tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
 }
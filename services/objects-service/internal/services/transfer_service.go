@@ -0,0 +1,822 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/transfer"
+)
+
+const (
+	// transferBatchSize is the number of records exported per fetch and imported per transaction
+	transferBatchSize = 1000
+	// maxTransferErrors bounds the record errors listed in an import result
+	maxTransferErrors = 1000
+	// maxExternalKeyLength is the size of the external_key column
+	maxExternalKeyLength = 255
+)
+
+// exportTxOptions reads a whole export from a single snapshot
+var exportTxOptions = pgx.TxOptions{
+	IsoLevel:   pgx.RepeatableRead,
+	AccessMode: pgx.ReadOnly,
+}
+
+// TransferService streams objects and relationships out of the service and back in, as NDJSON
+// or CSV, to move them between environments. Imports refer to types by name and to parents and
+// relationship ends by public ID or external key.
+type TransferService interface {
+	ExportObjects(ctx context.Context, filter *models.ObjectFilter, w io.Writer, format string) error
+	ImportObjects(ctx context.Context, r io.Reader, opts *models.TransferImportOptions) (*models.TransferImportResult, error)
+	ExportRelationships(ctx context.Context, filter *models.RelationshipExportFilter, w io.Writer, format string) error
+	ImportRelationships(ctx context.Context, r io.Reader, opts *models.TransferImportOptions) (*models.TransferImportResult, error)
+}
+
+type transferService struct {
+	objects       *objectService
+	relationships *relationshipService
+	txDB          TxBeginner
+}
+
+// NewTransferService creates a transfer service. Exports read through cursors and need txDB;
+// imports write each batch of records in a transaction started by txDB, with the outbox events
+// of the objects and relationships they create or update.
+func NewTransferService(objectRepo repository.ObjectRepository, objectTypeRepo repository.ObjectTypeRepository, relRepo repository.RelationshipRepository, relTypeRepo repository.RelationshipTypeRepository, txDB TxBeginner) TransferService {
+	return &transferService{
+		objects:       &objectService{repo: objectRepo, objectTypeRepo: objectTypeRepo, txDB: txDB},
+		relationships: &relationshipService{repo: relRepo, relationshipTypeRepo: relTypeRepo, objectRepo: objectRepo, txDB: txDB},
+		txDB:          txDB,
+	}
+}
+
+// ExportObjects writes the live objects a filter selects to w, in ID order, with their
+// attributes. Parents come before their children only when they were created first.
+func (s *transferService) ExportObjects(ctx context.Context, filter *models.ObjectFilter, w io.Writer, format string) error {
+	out, err := newExportWriter[models.ObjectRecord](s.txDB, w, format)
+	if err != nil {
+		return err
+	}
+
+	return WithinTxOptions(ctx, s.txDB, exportTxOptions, func(tx Transaction) error {
+		concrete := tx.ConcreteTableRepository()
+		err := tx.ObjectRepository().ExportObjects(ctx, filter, transferBatchSize, func(records []*models.ObjectRecord) error {
+			if err := loadRecordAttributes(ctx, concrete, records); err != nil {
+				return err
+			}
+			for _, record := range records {
+				if err := out.Write(record); err != nil {
+					return err
+				}
+			}
+			return out.Flush()
+		})
+		if err != nil {
+			return err
+		}
+		return out.Flush()
+	})
+}
+
+// ExportRelationships writes the live relationships a filter selects to w, in ID order
+func (s *transferService) ExportRelationships(ctx context.Context, filter *models.RelationshipExportFilter, w io.Writer, format string) error {
+	out, err := newExportWriter[models.RelationshipRecord](s.txDB, w, format)
+	if err != nil {
+		return err
+	}
+
+	return WithinTxOptions(ctx, s.txDB, exportTxOptions, func(tx Transaction) error {
+		err := tx.RelationshipRepository().ExportRelationships(ctx, filter, transferBatchSize, func(records []*models.RelationshipRecord) error {
+			for _, record := range records {
+				if err := out.Write(record); err != nil {
+					return err
+				}
+			}
+			return out.Flush()
+		})
+		if err != nil {
+			return err
+		}
+		return out.Flush()
+	})
+}
+
+// newExportWriter checks what every export needs before anything is written
+func newExportWriter[T any](txDB TxBeginner, w io.Writer, format string) (*transfer.Writer[T], error) {
+	if !transfer.ValidFormat(format) {
+		return nil, fmt.Errorf("unknown format %q: %w", format, repository.ErrInvalidInput)
+	}
+	if txDB == nil {
+		return nil, errors.New("exports need a transactional database")
+	}
+	return transfer.NewWriter[T](w, format)
+}
+
+// loadRecordAttributes adds the attributes of their concrete tables to exported objects
+func loadRecordAttributes(ctx context.Context, concrete repository.ConcreteTableRepository, records []*models.ObjectRecord) error {
+	objects := make([]*models.Object, len(records))
+	for i, record := range records {
+		objects[i] = &models.Object{ID: record.ID, ObjectTypeID: record.ObjectTypeID}
+	}
+	if err := concrete.LoadAttributes(ctx, objects); err != nil {
+		return err
+	}
+	for i, object := range objects {
+		if len(object.Attributes) > 0 {
+			records[i].Attributes = object.Attributes
+		}
+	}
+	return nil
+}
+
+// ImportObjects loads objects from r. New objects are bulk-loaded with COPY; records matching a
+// live object by public ID or external key update it when opts.Upsert is set and fail
+// otherwise. A record may name as parent any object already loaded, including one earlier in
+// the stream. Records that fail are reported in the result and the others are loaded.
+func (s *transferService) ImportObjects(ctx context.Context, r io.Reader, opts *models.TransferImportOptions) (*models.TransferImportResult, error) {
+	reader, err := newTransferReader[models.ObjectRecord](r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newTransferImportResult()
+	importer := &objectImporter{
+		objects: s.objects,
+		opts:    opts,
+		result:  result,
+		types:   map[string]*models.ObjectType{},
+		schemas: newMetadataSchemaCache(s.objects.objectTypeRepo),
+		keys:    map[string]bool{},
+	}
+	if err := readTransferRecords(ctx, reader, result, importer.add); err != nil {
+		return nil, err
+	}
+	importer.flush(ctx)
+	return result, nil
+}
+
+// ImportRelationships loads relationships from r, checking each exactly as one created through
+// the API. Records of a relationship that already exists update its status and metadata when
+// opts.Upsert is set and fail otherwise.
+func (s *transferService) ImportRelationships(ctx context.Context, r io.Reader, opts *models.TransferImportOptions) (*models.TransferImportResult, error) {
+	reader, err := newTransferReader[models.RelationshipRecord](r, opts.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newTransferImportResult()
+	importer := &relationshipImporter{relationships: s.relationships, opts: opts, result: result}
+	if err := readTransferRecords(ctx, reader, result, importer.add); err != nil {
+		return nil, err
+	}
+	importer.flush(ctx)
+	return result, nil
+}
+
+func newTransferReader[T any](r io.Reader, format string) (*transfer.Reader[T], error) {
+	if !transfer.ValidFormat(format) {
+		return nil, fmt.Errorf("unknown format %q: %w", format, repository.ErrInvalidInput)
+	}
+	reader, err := transfer.NewReader[T](r, format)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", err, repository.ErrInvalidInput)
+	}
+	return reader, nil
+}
+
+func newTransferImportResult() *models.TransferImportResult {
+	return &models.TransferImportResult{Errors: []models.TransferRecordError{}}
+}
+
+// readTransferRecords hands each record of a stream to add. Records that cannot be decoded are
+// reported in result; a stream that cannot be read on ends the import, keeping what was loaded.
+func readTransferRecords[T any](ctx context.Context, reader *transfer.Reader[T], result *models.TransferImportResult, add func(ctx context.Context, line int, record *T)) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		record, line, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		var recordErr *transfer.RecordError
+		if errors.As(err, &recordErr) {
+			result.Records++
+			addTransferError(result, models.TransferRecordError{Line: line, Error: recordErr.Err.Error()})
+			continue
+		}
+		if err != nil {
+			result.Aborted = err.Error()
+			return nil
+		}
+		result.Records++
+		add(ctx, line, record)
+	}
+}
+
+func addTransferError(result *models.TransferImportResult, recordErr models.TransferRecordError) {
+	result.Failed++
+	if len(result.Errors) < maxTransferErrors {
+		result.Errors = append(result.Errors, recordErr)
+	} else {
+		result.ErrorsTruncated = true
+	}
+}
+
+// transferBatch is what writing a batch of records did. It is added to the result only once
+// the transaction commits.
+type transferBatch struct {
+	created, updated int
+	errors           []models.TransferRecordError
+}
+
+func (b *transferBatch) fail(line int, key string, err error) {
+	b.errors = append(b.errors, models.TransferRecordError{Line: line, Key: key, Error: err.Error()})
+}
+
+func (b *transferBatch) addTo(result *models.TransferImportResult) {
+	result.Created += b.created
+	result.Updated += b.updated
+	for _, recordErr := range b.errors {
+		addTransferError(result, recordErr)
+	}
+}
+
+// pendingObject is an object record waiting in a batch, with its keys parsed
+type pendingObject struct {
+	line           int
+	record         *models.ObjectRecord
+	publicID       uuid.UUID // uuid.Nil when the record has none
+	parentPublicID uuid.UUID
+}
+
+// key names the record in errors
+func (p *pendingObject) key() string {
+	switch {
+	case p.record.PublicID != "":
+		return p.record.PublicID
+	case p.record.ExternalKey != "":
+		return p.record.ExternalKey
+	}
+	return p.record.Name
+}
+
+type objectImporter struct {
+	objects *objectService
+	opts    *models.TransferImportOptions
+	result  *models.TransferImportResult
+	types   map[string]*models.ObjectType
+	schemas *metadataSchemaCache
+
+	batch []*pendingObject
+	keys  map[string]bool // public IDs and external keys of the batch
+}
+
+func (i *objectImporter) add(ctx context.Context, line int, record *models.ObjectRecord) {
+	pending := &pendingObject{line: line, record: record}
+	if err := parseObjectRecord(pending); err != nil {
+		addTransferError(i.result, models.TransferRecordError{Line: line, Key: pending.key(), Error: err.Error()})
+		return
+	}
+
+	// An object is written after its parent, and after an earlier record with the same keys it
+	// may update
+	if i.pendingKey("id:", record.ParentPublicID) || i.pendingKey("key:", record.ParentExternalKey) ||
+		i.pendingKey("id:", record.PublicID) || i.pendingKey("key:", record.ExternalKey) {
+		i.flush(ctx)
+	}
+	i.batch = append(i.batch, pending)
+	if record.PublicID != "" {
+		i.keys["id:"+pending.publicID.String()] = true
+	}
+	if record.ExternalKey != "" {
+		i.keys["key:"+record.ExternalKey] = true
+	}
+	if len(i.batch) >= transferBatchSize {
+		i.flush(ctx)
+	}
+}
+
+func (i *objectImporter) pendingKey(prefix, key string) bool {
+	if key == "" {
+		return false
+	}
+	if prefix == "id:" {
+		if id, err := uuid.Parse(key); err == nil {
+			key = id.String()
+		}
+	}
+	return i.keys[prefix+key]
+}
+
+// parseObjectRecord checks the fields of a record that need no lookup
+func parseObjectRecord(pending *pendingObject) error {
+	record := pending.record
+	if record.ObjectType == "" {
+		return errors.New("object_type is required")
+	}
+	if record.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(record.ExternalKey) > maxExternalKeyLength {
+		return fmt.Errorf("external_key is longer than %d characters", maxExternalKeyLength)
+	}
+	if record.Status != "" && !(&models.Object{Status: record.Status}).IsValidStatus() {
+		return fmt.Errorf("invalid status %q", record.Status)
+	}
+	if record.PublicID != "" {
+		id, err := uuid.Parse(record.PublicID)
+		if err != nil {
+			return errors.New("invalid public_id")
+		}
+		pending.publicID = id
+	}
+	if record.ParentPublicID != "" {
+		id, err := uuid.Parse(record.ParentPublicID)
+		if err != nil {
+			return errors.New("invalid parent_public_id")
+		}
+		pending.parentPublicID = id
+	}
+	if len(record.Metadata) > 0 {
+		var metadata map[string]interface{}
+		if err := json.Unmarshal(record.Metadata, &metadata); err != nil {
+			return errors.New("metadata must be a JSON object")
+		}
+	}
+	return nil
+}
+
+// flush writes the batch in one transaction. When that fails, each record is written on its
+// own so the failure is reported against the records that caused it.
+func (i *objectImporter) flush(ctx context.Context) {
+	batch := i.batch
+	i.batch = nil
+	i.keys = map[string]bool{}
+	if len(batch) == 0 {
+		return
+	}
+
+	written, err := i.write(ctx, batch)
+	if err == nil {
+		written.addTo(i.result)
+		return
+	}
+	if len(batch) == 1 {
+		addTransferError(i.result, models.TransferRecordError{Line: batch[0].line, Key: batch[0].key(), Error: err.Error()})
+		return
+	}
+	for _, pending := range batch {
+		written, err := i.write(ctx, []*pendingObject{pending})
+		if err != nil {
+			addTransferError(i.result, models.TransferRecordError{Line: pending.line, Key: pending.key(), Error: err.Error()})
+			continue
+		}
+		written.addTo(i.result)
+	}
+}
+
+func (i *objectImporter) write(ctx context.Context, batch []*pendingObject) (*transferBatch, error) {
+	var written *transferBatch
+	err := i.objects.withinTx(ctx, func(tx *objectService) error {
+		written = &transferBatch{}
+
+		var publicIDs []uuid.UUID
+		var externalKeys []string
+		for _, pending := range batch {
+			for _, id := range []uuid.UUID{pending.publicID, pending.parentPublicID} {
+				if id != uuid.Nil {
+					publicIDs = append(publicIDs, id)
+				}
+			}
+			for _, key := range []string{pending.record.ExternalKey, pending.record.ParentExternalKey} {
+				if key != "" {
+					externalKeys = append(externalKeys, key)
+				}
+			}
+		}
+		byPublicID, byExternalKey, err := tx.repo.FindByKeys(ctx, publicIDs, externalKeys)
+		if err != nil {
+			return err
+		}
+
+		var creates, updates []*models.ImportedObject
+		var before []*models.Object
+		for _, pending := range batch {
+			input, existing, err := i.resolve(ctx, tx, pending, byPublicID, byExternalKey)
+			if err != nil {
+				var validationErr *MetadataValidationError
+				if !errors.Is(err, repository.ErrInvalidInput) && !errors.As(err, &validationErr) {
+					return err
+				}
+				written.fail(pending.line, pending.key(), err)
+				continue
+			}
+			if existing == nil {
+				creates = append(creates, input)
+			} else {
+				updates = append(updates, input)
+				before = append(before, existing)
+			}
+		}
+
+		created, err := tx.repo.CopyObjects(ctx, creates)
+		if err != nil {
+			return err
+		}
+		for _, object := range created {
+			if err := tx.recordEvent(ctx, models.EventActionCreated, nil, object); err != nil {
+				return err
+			}
+		}
+		updated, err := tx.repo.UpdateImportedObjects(ctx, updates)
+		if err != nil {
+			return err
+		}
+		for j, object := range updated {
+			if err := tx.recordEvent(ctx, models.EventActionUpdated, before[j], object); err != nil {
+				return err
+			}
+		}
+		written.created, written.updated = len(created), len(updated)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return written, nil
+}
+
+// resolve turns a record into the object to write, checked as objects created or updated
+// through the API are. existing is the object the record updates, nil for a new one. Records
+// that cannot be imported fail with ErrInvalidInput or a *MetadataValidationError.
+func (i *objectImporter) resolve(ctx context.Context, tx *objectService, pending *pendingObject, byPublicID map[uuid.UUID]*models.Object, byExternalKey map[string]*models.Object) (*models.ImportedObject, *models.Object, error) {
+	record := pending.record
+
+	objectType, err := i.objectType(ctx, tx, record.ObjectType)
+	if err != nil {
+		return nil, nil, err
+	}
+	if objectType.IsSealed {
+		return nil, nil, fmt.Errorf("cannot import objects of sealed type: %w", repository.ErrInvalidInput)
+	}
+
+	var existing *models.Object
+	if pending.publicID != uuid.Nil {
+		existing = byPublicID[pending.publicID]
+	}
+	if record.ExternalKey != "" {
+		keyed := byExternalKey[record.ExternalKey]
+		if existing != nil && keyed != nil && keyed.ID != existing.ID {
+			return nil, nil, fmt.Errorf("public_id and external_key match different objects: %w", repository.ErrInvalidInput)
+		}
+		if existing == nil {
+			existing = keyed
+		}
+	}
+	if existing != nil {
+		if !i.opts.Upsert {
+			return nil, nil, fmt.Errorf("object %s already exists: %w", existing.PublicID, repository.ErrInvalidInput)
+		}
+		if existing.ObjectTypeID != objectType.ID {
+			return nil, nil, fmt.Errorf("object %s is of another type: %w", existing.PublicID, repository.ErrInvalidInput)
+		}
+	}
+
+	input := &models.ImportedObject{
+		ObjectTypeID: objectType.ID,
+		Name:         record.Name,
+		Description:  record.Description,
+		Metadata:     record.Metadata,
+		Tags:         record.Tags,
+		Status:       record.Status,
+		Attributes:   record.Attributes,
+	}
+	if record.ExternalKey != "" {
+		input.ExternalKey = &record.ExternalKey
+	}
+
+	parent, err := i.parent(pending, byPublicID, byExternalKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	if parent != nil {
+		if parent.ObjectTypeID != objectType.ID {
+			return nil, nil, fmt.Errorf("parent object must be of same type: %w", repository.ErrInvalidInput)
+		}
+		childID := int64(0)
+		if existing != nil {
+			childID = existing.ID
+		}
+		if err := tx.repo.ValidateParentChild(ctx, parent.ID, childID); err != nil {
+			return nil, nil, fmt.Errorf("invalid parent-child relationship: %v: %w", err, repository.ErrInvalidInput)
+		}
+		input.ParentObjectID = &parent.ID
+	}
+
+	var metadata map[string]interface{}
+	if len(record.Metadata) > 0 {
+		if err := json.Unmarshal(record.Metadata, &metadata); err != nil {
+			return nil, nil, fmt.Errorf("metadata must be a JSON object: %w", repository.ErrInvalidInput)
+		}
+	}
+	if err := i.schemas.validate(ctx, objectType.ID, metadata); err != nil {
+		return nil, nil, err
+	}
+
+	actor := i.opts.Actor
+	if actor == "" {
+		actor = "system"
+	}
+	if existing != nil {
+		input.ID = existing.ID
+		input.PublicID = existing.PublicID
+		input.UpdatedBy = actor
+		return input, existing, nil
+	}
+
+	input.PublicID = pending.publicID
+	if input.PublicID == uuid.Nil {
+		input.PublicID = uuid.New()
+	}
+	input.CreatedBy = record.CreatedBy
+	if input.CreatedBy == "" {
+		input.CreatedBy = actor
+	}
+	return input, nil, nil
+}
+
+// objectType returns the object type of a name, looked up once per import
+func (i *objectImporter) objectType(ctx context.Context, tx *objectService, name string) (*models.ObjectType, error) {
+	if objectType, ok := i.types[name]; ok {
+		return objectType, nil
+	}
+	objectType, err := tx.objectTypeRepo.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) || errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("object type %q not found: %w", name, repository.ErrInvalidInput)
+		}
+		return nil, err
+	}
+	i.types[name] = objectType
+	return objectType, nil
+}
+
+// parent returns the live object a record names as its parent, or nil when it names none
+func (i *objectImporter) parent(pending *pendingObject, byPublicID map[uuid.UUID]*models.Object, byExternalKey map[string]*models.Object) (*models.Object, error) {
+	record := pending.record
+	var parent *models.Object
+	if pending.parentPublicID != uuid.Nil {
+		if parent = byPublicID[pending.parentPublicID]; parent == nil {
+			return nil, fmt.Errorf("parent %s not found: %w", record.ParentPublicID, repository.ErrInvalidInput)
+		}
+	}
+	if record.ParentExternalKey != "" {
+		keyed := byExternalKey[record.ParentExternalKey]
+		if keyed == nil {
+			return nil, fmt.Errorf("parent %q not found: %w", record.ParentExternalKey, repository.ErrInvalidInput)
+		}
+		if parent != nil && parent.ID != keyed.ID {
+			return nil, fmt.Errorf("parent_public_id and parent_external_key match different objects: %w", repository.ErrInvalidInput)
+		}
+		parent = keyed
+	}
+	return parent, nil
+}
+
+// pendingRelationship is a relationship record waiting in a batch
+type pendingRelationship struct {
+	line   int
+	record *models.RelationshipRecord
+}
+
+func (p *pendingRelationship) key() string {
+	source := p.record.SourcePublicID
+	if source == "" {
+		source = p.record.SourceExternalKey
+	}
+	target := p.record.TargetPublicID
+	if target == "" {
+		target = p.record.TargetExternalKey
+	}
+	return source + " " + p.record.RelationshipType + " " + target
+}
+
+type relationshipImporter struct {
+	relationships *relationshipService
+	opts          *models.TransferImportOptions
+	result        *models.TransferImportResult
+	batch         []*pendingRelationship
+}
+
+func (i *relationshipImporter) add(ctx context.Context, line int, record *models.RelationshipRecord) {
+	pending := &pendingRelationship{line: line, record: record}
+	if err := checkRelationshipRecord(record); err != nil {
+		addTransferError(i.result, models.TransferRecordError{Line: line, Key: pending.key(), Error: err.Error()})
+		return
+	}
+	i.batch = append(i.batch, pending)
+	if len(i.batch) >= transferBatchSize {
+		i.flush(ctx)
+	}
+}
+
+// checkRelationshipRecord checks the fields of a record that need no lookup
+func checkRelationshipRecord(record *models.RelationshipRecord) error {
+	if record.RelationshipType == "" {
+		return errors.New("relationship_type is required")
+	}
+	if record.SourcePublicID == "" && record.SourceExternalKey == "" {
+		return errors.New("source_public_id or source_external_key is required")
+	}
+	if record.TargetPublicID == "" && record.TargetExternalKey == "" {
+		return errors.New("target_public_id or target_external_key is required")
+	}
+	for field, id := range map[string]string{"source_public_id": record.SourcePublicID, "target_public_id": record.TargetPublicID} {
+		if _, err := uuid.Parse(id); id != "" && err != nil {
+			return fmt.Errorf("invalid %s", field)
+		}
+	}
+	if len(record.Metadata) > 0 {
+		var metadata map[string]interface{}
+		if err := json.Unmarshal(record.Metadata, &metadata); err != nil {
+			return errors.New("metadata must be a JSON object")
+		}
+	}
+	return nil
+}
+
+// flush writes the batch in one transaction. Relationships are checked as they are written, so
+// the first record that fails rolls the batch back and each record is then written on its own.
+func (i *relationshipImporter) flush(ctx context.Context) {
+	batch := i.batch
+	i.batch = nil
+	if len(batch) == 0 {
+		return
+	}
+
+	written, err := i.write(ctx, batch)
+	if err == nil {
+		written.addTo(i.result)
+		return
+	}
+	if len(batch) == 1 {
+		addTransferError(i.result, models.TransferRecordError{Line: batch[0].line, Key: batch[0].key(), Error: err.Error()})
+		return
+	}
+	for _, pending := range batch {
+		written, err := i.write(ctx, []*pendingRelationship{pending})
+		if err != nil {
+			addTransferError(i.result, models.TransferRecordError{Line: pending.line, Key: pending.key(), Error: err.Error()})
+			continue
+		}
+		written.addTo(i.result)
+	}
+}
+
+func (i *relationshipImporter) write(ctx context.Context, batch []*pendingRelationship) (*transferBatch, error) {
+	var written *transferBatch
+	err := i.relationships.withinTx(ctx, func(tx *relationshipService) error {
+		written = &transferBatch{}
+
+		var publicIDs []uuid.UUID
+		var externalKeys []string
+		for _, pending := range batch {
+			for _, id := range []string{pending.record.SourcePublicID, pending.record.TargetPublicID} {
+				if id != "" {
+					publicIDs = append(publicIDs, uuid.MustParse(id))
+				}
+			}
+			for _, key := range []string{pending.record.SourceExternalKey, pending.record.TargetExternalKey} {
+				if key != "" {
+					externalKeys = append(externalKeys, key)
+				}
+			}
+		}
+		byPublicID, byExternalKey, err := tx.objectRepo.FindByKeys(ctx, publicIDs, externalKeys)
+		if err != nil {
+			return err
+		}
+
+		for _, pending := range batch {
+			created, err := i.writeOne(ctx, tx, pending, byPublicID, byExternalKey)
+			if err != nil {
+				return err
+			}
+			if created {
+				written.created++
+			} else {
+				written.updated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return written, nil
+}
+
+// writeOne creates the relationship of a record, or updates it when it exists and the import
+// upserts. It reports whether the relationship was created.
+func (i *relationshipImporter) writeOne(ctx context.Context, tx *relationshipService, pending *pendingRelationship, byPublicID map[uuid.UUID]*models.Object, byExternalKey map[string]*models.Object) (bool, error) {
+	record := pending.record
+
+	source, err := relationshipEnd("source", record.SourcePublicID, record.SourceExternalKey, byPublicID, byExternalKey)
+	if err != nil {
+		return false, err
+	}
+	target, err := relationshipEnd("target", record.TargetPublicID, record.TargetExternalKey, byPublicID, byExternalKey)
+	if err != nil {
+		return false, err
+	}
+	if source.ID == target.ID {
+		return false, fmt.Errorf("%w: %s", ErrSourceTargetSame, repository.ErrInvalidInput)
+	}
+
+	actor := i.opts.Actor
+	if actor == "" {
+		actor = "system"
+	}
+	createdBy := record.CreatedBy
+	if createdBy == "" {
+		createdBy = actor
+	}
+	input := &models.CreateRelationshipRequest{
+		SourceObjectPublicID: source.PublicID.String(),
+		TargetObjectPublicID: target.PublicID.String(),
+		RelationshipTypeKey:  record.RelationshipType,
+		Status:               record.Status,
+		RelationshipMetadata: record.Metadata,
+		CreatedBy:            createdBy,
+	}
+	input.SetDefaults()
+
+	_, err = tx.create(ctx, input, source.PublicID, target.PublicID)
+	if err == nil {
+		return true, nil
+	}
+	if !i.opts.Upsert || !errors.Is(err, ErrDuplicateRelationship) {
+		return false, err
+	}
+
+	existing, findErr := tx.repo.List(ctx, &models.RelationshipFilter{
+		SourceObjectPublicID: &input.SourceObjectPublicID,
+		TargetObjectPublicID: &input.TargetObjectPublicID,
+		RelationshipTypeKey:  &input.RelationshipTypeKey,
+		Page:                 1,
+		PageSize:             1,
+	})
+	if findErr != nil {
+		return false, findErr
+	}
+	if len(existing) == 0 {
+		// Relationships of alias types are stored under their primary type
+		return false, err
+	}
+
+	update := &models.UpdateRelationshipRequest{RelationshipMetadata: record.Metadata, UpdatedBy: actor}
+	if record.Status != "" {
+		update.Status = &record.Status
+	}
+	rel, err := tx.lockRelationship(ctx, existing[0].PublicID)
+	if err != nil {
+		return false, err
+	}
+	if err := tx.validateRelationshipUpdate(ctx, rel, update); err != nil {
+		return false, err
+	}
+	updated, err := tx.repo.Update(ctx, rel.ObjectID, update)
+	if err != nil {
+		return false, err
+	}
+	return false, tx.recordEvent(ctx, models.EventActionUpdated, rel, updated)
+}
+
+// relationshipEnd returns the live object a record names as one end of a relationship
+func relationshipEnd(end, publicID, externalKey string, byPublicID map[uuid.UUID]*models.Object, byExternalKey map[string]*models.Object) (*models.Object, error) {
+	var object *models.Object
+	if publicID != "" {
+		if object = byPublicID[uuid.MustParse(publicID)]; object == nil {
+			return nil, fmt.Errorf("%s object %s not found", end, publicID)
+		}
+	}
+	if externalKey != "" {
+		keyed := byExternalKey[externalKey]
+		if keyed == nil {
+			return nil, fmt.Errorf("%s object %q not found", end, externalKey)
+		}
+		if object != nil && object.ID != keyed.ID {
+			return nil, fmt.Errorf("%s_public_id and %s_external_key match different objects", end, end)
+		}
+		object = keyed
+	}
+	return object, nil
+}
@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+func (r *memoryObjectTypes) GetByName(ctx context.Context, name string) (*models.ObjectType, error) {
+	objectType := r.byName(name)
+	if objectType == nil {
+		return nil, repository.ErrNotFound
+	}
+	copied := *objectType
+	return &copied, nil
+}
+
+// transferObjects adds external keys and the bulk writes of imports to memoryObjects. A copy
+// including an object named failOn fails as a constraint violation would.
+type transferObjects struct {
+	*memoryObjects
+	externalKeys map[int64]string
+	failOn       string
+	copies       int
+}
+
+func newTransferObjects() *transferObjects {
+	return &transferObjects{memoryObjects: newMemoryObjects(), externalKeys: map[int64]string{}}
+}
+
+func (r *transferObjects) FindByKeys(ctx context.Context, publicIDs []uuid.UUID, externalKeys []string) (map[uuid.UUID]*models.Object, map[string]*models.Object, error) {
+	byPublicID := map[uuid.UUID]*models.Object{}
+	byExternalKey := map[string]*models.Object{}
+	for _, id := range publicIDs {
+		if object, err := r.GetByPublicID(ctx, id); err == nil {
+			byPublicID[id] = object
+		}
+	}
+	for _, key := range externalKeys {
+		for id, objectKey := range r.externalKeys {
+			if objectKey == key {
+				object, _ := r.GetByID(ctx, id)
+				byExternalKey[key] = object
+			}
+		}
+	}
+	return byPublicID, byExternalKey, nil
+}
+
+func (r *transferObjects) CopyObjects(ctx context.Context, inputs []*models.ImportedObject) ([]*models.Object, error) {
+	for _, input := range inputs {
+		if input.Name == r.failOn {
+			return nil, errors.New("duplicate key value violates unique constraint")
+		}
+	}
+	if len(inputs) > 0 {
+		r.copies++
+	}
+	objects := []*models.Object{}
+	for _, input := range inputs {
+		r.nextID++
+		object := &models.Object{
+			ID:             r.nextID,
+			PublicID:       input.PublicID,
+			ObjectTypeID:   input.ObjectTypeID,
+			ParentObjectID: input.ParentObjectID,
+			Name:           input.Name,
+			Metadata:       input.Metadata,
+			Tags:           input.Tags,
+			Status:         input.Status,
+			Version:        1,
+			CreatedBy:      input.CreatedBy,
+		}
+		r.objects[object.ID] = object
+		if input.ExternalKey != nil {
+			r.externalKeys[object.ID] = *input.ExternalKey
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func (r *transferObjects) UpdateImportedObjects(ctx context.Context, inputs []*models.ImportedObject) ([]*models.Object, error) {
+	objects := []*models.Object{}
+	for _, input := range inputs {
+		object := r.objects[input.ID]
+		object.Name = input.Name
+		object.ParentObjectID = input.ParentObjectID
+		object.Metadata = input.Metadata
+		object.UpdatedBy = input.UpdatedBy
+		object.Version++
+		if input.ExternalKey != nil {
+			r.externalKeys[object.ID] = *input.ExternalKey
+		}
+		copied := *object
+		objects = append(objects, &copied)
+	}
+	return objects, nil
+}
+
+func (r *memoryRelationships) List(ctx context.Context, filter *models.RelationshipFilter) ([]*models.Relationship, error) {
+	rels := []*models.Relationship{}
+	for _, rel := range r.rels {
+		if rel.SourceObjectPublicID.String() == *filter.SourceObjectPublicID &&
+			rel.TargetObjectPublicID.String() == *filter.TargetObjectPublicID &&
+			rel.RelationshipTypeKey == *filter.RelationshipTypeKey {
+			copied := *rel
+			rels = append(rels, &copied)
+		}
+	}
+	return rels, nil
+}
+
+func (r *memoryRelationships) GetByPublicID(ctx context.Context, publicID uuid.UUID) (*models.Relationship, error) {
+	for _, rel := range r.rels {
+		if rel.PublicID == publicID {
+			copied := *rel
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryRelationships) GetByObjectID(ctx context.Context, objectID int64) (*models.Relationship, error) {
+	for _, rel := range r.rels {
+		if rel.ObjectID == objectID {
+			copied := *rel
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryRelationships) Update(ctx context.Context, objectID int64, input *models.UpdateRelationshipRequest) (*models.Relationship, error) {
+	for _, rel := range r.rels {
+		if rel.ObjectID == objectID {
+			if input.Status != nil {
+				rel.Status = *input.Status
+			}
+			if input.RelationshipMetadata != nil {
+				rel.RelationshipMetadata = input.RelationshipMetadata
+			}
+			copied := *rel
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+// transferFixture holds a Folder type, a sealed Archive type and a links_to relationship type
+type transferFixture struct {
+	types   *memoryObjectTypes
+	objects *transferObjects
+	rels    *memoryRelationships
+	db      *memoryTxDB
+	service TransferService
+	folder  *models.ObjectType
+}
+
+func newTransferFixture() *transferFixture {
+	f := &transferFixture{types: newMemoryObjectTypes(), objects: newTransferObjects()}
+	f.folder = f.types.add("Folder", "", false)
+	f.types.add("Archive", "", true)
+	relTypes := newMemoryRelationshipTypes()
+	relTypes.add("links_to", "", models.CardinalityManyToMany)
+	f.rels = newMemoryRelationships(f.objects.memoryObjects, relTypes)
+
+	f.db = newMemoryTxDB(f.objects, f.types)
+	f.db.relRepo = f.rels
+	f.db.relTypeRepo = relTypes
+	f.service = NewTransferService(f.objects, f.types, f.rels, relTypes, f.db)
+	return f
+}
+
+// object returns the object with an external key
+func (f *transferFixture) object(key string) *models.Object {
+	for id, objectKey := range f.objects.externalKeys {
+		if objectKey == key {
+			return f.objects.objects[id]
+		}
+	}
+	return nil
+}
+
+func TestTransferService_ImportObjects(t *testing.T) {
+	f := newTransferFixture()
+	stream := strings.Join([]string{
+		`{"external_key":"root","object_type":"Folder","name":"Root"}`,
+		`{"external_key":"docs","object_type":"Folder","name":"Docs","parent_external_key":"root","metadata":{"owner":"ops"}}`,
+		`{"object_type":"Folder"}`,
+		`{"object_type":"Unknown","name":"Lost"}`,
+		`not json`,
+		`{"object_type":"Archive","name":"Sealed"}`,
+	}, "\n")
+
+	result, err := f.service.ImportObjects(context.Background(), strings.NewReader(stream), &models.TransferImportOptions{Format: "ndjson", Actor: "alice"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 6, result.Records)
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 4, result.Failed)
+	lines := []int{}
+	for _, recordErr := range result.Errors {
+		lines = append(lines, recordErr.Line)
+	}
+	assert.ElementsMatch(t, []int{3, 4, 5, 6}, lines)
+
+	root, docs := f.object("root"), f.object("docs")
+	require.NotNil(t, root)
+	require.NotNil(t, docs)
+	assert.Equal(t, root.ID, *docs.ParentObjectID)
+	assert.Equal(t, "alice", docs.CreatedBy)
+	assert.JSONEq(t, `{"owner":"ops"}`, string(docs.Metadata))
+
+	// The child waits for its parent to be written, so the stream takes two batches
+	assert.Equal(t, 2, f.objects.copies)
+	assert.Equal(t, []string{"object.created", "object.created"}, f.db.outbox.eventTypes())
+}
+
+func TestTransferService_ImportObjectsUpsert(t *testing.T) {
+	f := newTransferFixture()
+	existing := f.objects.add("Old name", f.folder.ID, nil)
+	f.objects.externalKeys[existing.ID] = "folder-1"
+	stream := `{"external_key":"folder-1","object_type":"Folder","name":"New name"}`
+
+	result, err := f.service.ImportObjects(context.Background(), strings.NewReader(stream), &models.TransferImportOptions{Format: "ndjson"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.Contains(t, result.Errors[0].Error, "already exists")
+	assert.Equal(t, "folder-1", result.Errors[0].Key)
+	assert.Equal(t, "Old name", f.objects.objects[existing.ID].Name)
+
+	result, err = f.service.ImportObjects(context.Background(), strings.NewReader(stream), &models.TransferImportOptions{Format: "ndjson", Upsert: true, Actor: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Updated)
+	assert.Zero(t, result.Failed)
+	assert.Equal(t, "New name", f.objects.objects[existing.ID].Name)
+	assert.Equal(t, "bob", f.objects.objects[existing.ID].UpdatedBy)
+	assert.Equal(t, []string{"object.updated"}, f.db.outbox.eventTypes())
+}
+
+func TestTransferService_ImportObjectsRetriesFailedBatchRecordByRecord(t *testing.T) {
+	f := newTransferFixture()
+	f.objects.failOn = "Clash"
+	stream := "name,object_type\nFirst,Folder\nClash,Folder\nThird,Folder\n"
+
+	result, err := f.service.ImportObjects(context.Background(), strings.NewReader(stream), &models.TransferImportOptions{Format: "csv"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Records)
+	assert.Equal(t, 2, result.Created)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, 3, result.Errors[0].Line)
+	assert.Equal(t, "Clash", result.Errors[0].Key)
+	assert.Contains(t, result.Errors[0].Error, "unique constraint")
+	assert.Equal(t, 2, f.db.rollbacks)
+}
+
+func TestTransferService_ImportObjectsRejectsParentOfAnotherType(t *testing.T) {
+	f := newTransferFixture()
+	binder := f.types.add("Binder", "", false)
+	parent := f.objects.add("binder", binder.ID, nil)
+	stream := `{"object_type":"Folder","name":"Child","parent_public_id":"` + parent.PublicID.String() + `"}`
+
+	result, err := f.service.ImportObjects(context.Background(), strings.NewReader(stream), &models.TransferImportOptions{Format: "ndjson"})
+
+	require.NoError(t, err)
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Error, "same type")
+	assert.Zero(t, f.objects.copies)
+}
+
+func TestTransferService_ImportRejectsUnknownFormat(t *testing.T) {
+	f := newTransferFixture()
+
+	_, err := f.service.ImportObjects(context.Background(), strings.NewReader(""), &models.TransferImportOptions{Format: "xml"})
+
+	assert.ErrorIs(t, err, repository.ErrInvalidInput)
+}
+
+func TestTransferService_ImportRelationships(t *testing.T) {
+	f := newTransferFixture()
+	a := f.objects.add("a", f.folder.ID, nil)
+	b := f.objects.add("b", f.folder.ID, nil)
+	f.objects.externalKeys[b.ID] = "b"
+	existing := f.rels.add("links_to", b, a)
+	stream := strings.Join([]string{
+		`{"relationship_type":"links_to","source_external_key":"missing","target_external_key":"b"}`,
+		`{"relationship_type":"links_to","source_public_id":"` + a.PublicID.String() + `","target_external_key":"b"}`,
+		`{"relationship_type":"links_to","source_external_key":"b","target_public_id":"` + a.PublicID.String() + `","status":"inactive"}`,
+	}, "\n")
+
+	result, err := f.service.ImportRelationships(context.Background(), strings.NewReader(stream), &models.TransferImportOptions{Format: "ndjson", Upsert: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Records)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 1, result.Updated)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, 1, result.Errors[0].Line)
+	assert.Contains(t, result.Errors[0].Error, `source object "missing" not found`)
+
+	updated, err := f.rels.GetByObjectID(context.Background(), existing.ObjectID)
+	require.NoError(t, err)
+	assert.Equal(t, "inactive", updated.Status)
+	created := f.rels.rels[len(f.rels.rels)-1]
+	assert.Equal(t, a.ID, created.SourceObjectID)
+	assert.Equal(t, b.ID, created.TargetObjectID)
+	assert.Equal(t, []string{"relationship.created", "relationship.updated"}, f.db.outbox.eventTypes())
+}
+
+func TestTransferService_ImportRelationshipsWithoutUpsertReportsDuplicates(t *testing.T) {
+	f := newTransferFixture()
+	a := f.objects.add("a", f.folder.ID, nil)
+	b := f.objects.add("b", f.folder.ID, nil)
+	f.rels.add("links_to", a, b)
+	record, _ := json.Marshal(models.RelationshipRecord{
+		RelationshipType: "links_to",
+		SourcePublicID:   a.PublicID.String(),
+		TargetPublicID:   b.PublicID.String(),
+	})
+
+	result, err := f.service.ImportRelationships(context.Background(), strings.NewReader(string(record)), &models.TransferImportOptions{Format: "ndjson"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	assert.Contains(t, result.Errors[0].Error, "already exists")
+	assert.Len(t, f.rels.rels, 1)
+}
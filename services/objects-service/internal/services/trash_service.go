@@ -0,0 +1,397 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+var (
+	ErrObjectNotDeleted   = errors.New("object is not deleted")
+	ErrObjectHasLiveChild = errors.New("object has children that are not deleted")
+)
+
+const (
+	defaultTrashPageSize = 50
+	maxTrashPageSize     = 500
+)
+
+// TrashService lists, restores and purges soft-deleted objects
+type TrashService interface {
+	List(ctx context.Context, filter *models.TrashFilter) ([]*models.Object, int64, error)
+	Get(ctx context.Context, id int64) (*models.Object, error)
+	Restore(ctx context.Context, id int64, req *models.RestoreObjectRequest) (*models.RestoreObjectResult, error)
+	Purge(ctx context.Context, id int64) (*models.PurgeObjectResult, error)
+	PurgeExpired(ctx context.Context, deletedBefore time.Time, batchSize int) (int, error)
+}
+
+type trashService struct {
+	objectRepo     repository.ObjectRepository
+	objectTypeRepo repository.ObjectTypeRepository
+	relRepo        repository.RelationshipRepository
+	relTypeRepo    repository.RelationshipTypeRepository
+	txDB           TxBeginner
+}
+
+// NewTrashService creates a trash service that restores and purges inside a single transaction
+// started by txDB. Without txDB the changes are made directly against the repositories.
+func NewTrashService(objectRepo repository.ObjectRepository, objectTypeRepo repository.ObjectTypeRepository, relRepo repository.RelationshipRepository, relTypeRepo repository.RelationshipTypeRepository, txDB TxBeginner) TrashService {
+	return &trashService{
+		objectRepo:     objectRepo,
+		objectTypeRepo: objectTypeRepo,
+		relRepo:        relRepo,
+		relTypeRepo:    relTypeRepo,
+		txDB:           txDB,
+	}
+}
+
+// withinTx runs fn with an object service and a relationship service bound to a single
+// transaction, so restored relationships are checked as relationships created one by one
+func (s *trashService) withinTx(ctx context.Context, fn func(objects *objectService, relationships *relationshipService) error) error {
+	if s.txDB == nil {
+		return fn(
+			&objectService{repo: s.objectRepo, objectTypeRepo: s.objectTypeRepo},
+			&relationshipService{repo: s.relRepo, relationshipTypeRepo: s.relTypeRepo, objectRepo: s.objectRepo},
+		)
+	}
+	return WithinTxOptions(ctx, s.txDB, relationshipTxOptions, func(tx Transaction) error {
+		return fn(
+			&objectService{repo: tx.ObjectRepository(), objectTypeRepo: tx.ObjectTypeRepository(), outbox: tx.OutboxRepository()},
+			&relationshipService{
+				repo:                 tx.RelationshipRepository(),
+				relationshipTypeRepo: tx.RelationshipTypeRepository(),
+				objectRepo:           tx.ObjectRepository(),
+				outbox:               tx.OutboxRepository(),
+			},
+		)
+	})
+}
+
+// List returns the objects in the trash, most recently deleted first
+func (s *trashService) List(ctx context.Context, filter *models.TrashFilter) ([]*models.Object, int64, error) {
+	if filter == nil {
+		filter = &models.TrashFilter{}
+	}
+	if filter.Offset < 0 {
+		return nil, 0, fmt.Errorf("offset cannot be negative: %w", repository.ErrInvalidInput)
+	}
+	if filter.DeletedAfter != nil && filter.DeletedBefore != nil && filter.DeletedAfter.After(*filter.DeletedBefore) {
+		return nil, 0, fmt.Errorf("deleted_after must not be later than deleted_before: %w", repository.ErrInvalidInput)
+	}
+
+	normalized := *filter
+	if normalized.Limit <= 0 {
+		normalized.Limit = defaultTrashPageSize
+	}
+	if normalized.Limit > maxTrashPageSize {
+		normalized.Limit = maxTrashPageSize
+	}
+	return s.objectRepo.ListDeleted(ctx, &normalized)
+}
+
+// Get returns an object in the trash
+func (s *trashService) Get(ctx context.Context, id int64) (*models.Object, error) {
+	return getDeleted(ctx, s.objectRepo, id)
+}
+
+// getDeleted reads an object that must be in the trash
+func getDeleted(ctx context.Context, repo repository.ObjectRepository, id int64) (*models.Object, error) {
+	if id <= 0 {
+		return nil, fmt.Errorf("invalid id: %w", repository.ErrInvalidInput)
+	}
+	object, err := repo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("object not found: %w", err)
+	}
+	if object.DeletedAt == nil {
+		return nil, fmt.Errorf("object %d: %w", id, ErrObjectNotDeleted)
+	}
+	return object, nil
+}
+
+// Restore takes an object out of the trash, under a parent that is not deleted. With
+// req.IncludeDescendants its descendants deleted below it come back too, and with
+// req.IncludeRelationships the relationships deleted with the restored objects whose ends are
+// all back. A relationship the rules of its type no longer allow stays in the trash.
+func (s *trashService) Restore(ctx context.Context, id int64, req *models.RestoreObjectRequest) (*models.RestoreObjectResult, error) {
+	if req == nil {
+		req = &models.RestoreObjectRequest{}
+	}
+
+	var result *models.RestoreObjectResult
+	err := s.withinTx(ctx, func(objects *objectService, relationships *relationshipService) error {
+		var err error
+		result, err = s.restore(ctx, objects, relationships, id, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *trashService) restore(ctx context.Context, objects *objectService, relationships *relationshipService, id int64, req *models.RestoreObjectRequest) (*models.RestoreObjectResult, error) {
+	root, err := getDeleted(ctx, objects.repo, id)
+	if err != nil {
+		return nil, err
+	}
+	if root.ParentObjectID != nil {
+		if _, err := objects.repo.GetByID(ctx, *root.ParentObjectID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, fmt.Errorf("parent object %d: %w", *root.ParentObjectID, ErrObjectDeleted)
+			}
+			return nil, err
+		}
+	}
+
+	deleted := []*models.Object{root}
+	if req.IncludeDescendants {
+		descendants, err := objects.repo.GetDeletedDescendants(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, descendants...)
+	}
+
+	result := &models.RestoreObjectResult{Descendants: []int64{}, Relationships: []int64{}}
+	restoredIDs := make([]int64, 0, len(deleted))
+	for _, before := range deleted {
+		restored, err := objects.repo.Restore(ctx, before.ID, req.UpdatedBy)
+		if err != nil {
+			return nil, err
+		}
+		if err := objects.recordEvent(ctx, models.EventActionRestored, before, restored); err != nil {
+			return nil, err
+		}
+
+		restoredIDs = append(restoredIDs, restored.ID)
+		if before.ID == id {
+			result.Object = restored
+		} else {
+			result.Descendants = append(result.Descendants, restored.ID)
+		}
+	}
+
+	if req.IncludeRelationships {
+		restorable, err := relationships.repo.ListDeletedForObjects(ctx, restoredIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, rel := range restorable {
+			ok, err := s.restoreRelationship(ctx, relationships, rel)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				result.Relationships = append(result.Relationships, rel.ObjectID)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// restoreRelationship restores a relationship from the trash unless that would break the
+// cardinality of its type or close a cycle, and reports whether it did
+func (s *trashService) restoreRelationship(ctx context.Context, relationships *relationshipService, rel *models.Relationship) (bool, error) {
+	relType, err := relationships.relationshipTypeRepo.GetByID(ctx, rel.RelationshipTypeID)
+	if err != nil {
+		return false, err
+	}
+
+	if relType.Cardinality == models.CardinalityOneToMany || relType.Cardinality == models.CardinalityManyToOne {
+		if err := relationships.repo.LockType(ctx, relType.ObjectID); err != nil {
+			return false, err
+		}
+	}
+	if err := relationships.repo.LockObjects(ctx, relType.ObjectID, rel.SourceObjectID, rel.TargetObjectID); err != nil {
+		return false, err
+	}
+
+	isCircular, err := relationships.repo.CheckCircular(ctx, rel.SourceObjectID, rel.TargetObjectID, relType.ObjectID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check circular relationship: %w", err)
+	}
+	if isCircular {
+		return false, nil
+	}
+	if err := relationships.validateCardinality(ctx, rel.SourceObjectID, rel.TargetObjectID, relType); err != nil {
+		if errors.Is(err, ErrCardinalityViolation) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if err := relationships.repo.Restore(ctx, []int64{rel.ObjectID}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Purge removes an object in the trash for good, with its descendants in the trash and every
+// relationship they take part in. An object with children that are not deleted is kept.
+func (s *trashService) Purge(ctx context.Context, id int64) (*models.PurgeObjectResult, error) {
+	var result *models.PurgeObjectResult
+	err := s.withinTx(ctx, func(objects *objectService, _ *relationshipService) error {
+		root, err := getDeleted(ctx, objects.repo, id)
+		if err != nil {
+			return err
+		}
+		descendants, err := objects.repo.GetDeletedDescendants(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		purged := append([]*models.Object{root}, descendants...)
+		if err := s.purge(ctx, objects, purged); err != nil {
+			return err
+		}
+
+		result = &models.PurgeObjectResult{Purged: make([]int64, 0, len(purged))}
+		for _, object := range purged {
+			result.Purged = append(result.Purged, object.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// purge removes objects in the trash and records their purge events. Live children would lose
+// their parent, so objects that have any are refused.
+func (s *trashService) purge(ctx context.Context, objects *objectService, purged []*models.Object) error {
+	ids := make([]int64, 0, len(purged))
+	for _, object := range purged {
+		canDelete, err := objects.repo.CanDelete(ctx, object.ID)
+		if err != nil {
+			return err
+		}
+		if !canDelete {
+			return fmt.Errorf("object %d: %w", object.ID, ErrObjectHasLiveChild)
+		}
+		ids = append(ids, object.ID)
+	}
+
+	if err := objects.repo.Purge(ctx, ids); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("%s: %w", err.Error(), ErrObjectNotDeleted)
+		}
+		return err
+	}
+	for _, object := range purged {
+		if err := objects.recordEvent(ctx, models.EventActionPurged, object, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PurgeExpired removes everything deleted before deletedBefore for good and returns how many
+// objects and relationships it removed. Objects go leaves first, batchSize per transaction, so
+// a subtree deleted long ago is taken apart over several batches.
+func (s *trashService) PurgeExpired(ctx context.Context, deletedBefore time.Time, batchSize int) (int, error) {
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("batch size must be positive: %w", repository.ErrInvalidInput)
+	}
+
+	total := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		var expired []*models.Object
+		err := s.withinTx(ctx, func(objects *objectService, _ *relationshipService) error {
+			var err error
+			expired, err = objects.repo.ListExpired(ctx, deletedBefore, batchSize)
+			if err != nil || len(expired) == 0 {
+				return err
+			}
+			return s.purge(ctx, objects, expired)
+		})
+		if err != nil {
+			return total, err
+		}
+		total += len(expired)
+		if len(expired) < batchSize {
+			break
+		}
+	}
+
+	var relationships int
+	err := s.withinTx(ctx, func(_ *objectService, rels *relationshipService) error {
+		var err error
+		relationships, err = rels.repo.PurgeDeletedBefore(ctx, deletedBefore)
+		return err
+	})
+	return total + relationships, err
+}
+
+// TrashPurgerConfig configures the retention of deleted objects
+type TrashPurgerConfig struct {
+	RetentionPeriod time.Duration // How long deleted objects stay in the trash
+	Interval        time.Duration // Wait between purges
+	BatchSize       int           // Objects purged per transaction
+}
+
+// TrashPurger removes objects that have been in the trash longer than the retention period
+type TrashPurger struct {
+	trash  TrashService
+	config TrashPurgerConfig
+	logger *logrus.Logger
+}
+
+// NewTrashPurger creates a purger enforcing the retention period on the trash
+func NewTrashPurger(trash TrashService, config TrashPurgerConfig, logger *logrus.Logger) *TrashPurger {
+	if config.RetentionPeriod <= 0 {
+		config.RetentionPeriod = 30 * 24 * time.Hour
+	}
+	if config.Interval <= 0 {
+		config.Interval = time.Hour
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+
+	return &TrashPurger{
+		trash:  trash,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run purges expired objects until ctx is cancelled, starting at once and then every interval
+func (p *TrashPurger) Run(ctx context.Context) {
+	for {
+		purged, err := p.PurgeOnce(ctx)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return
+			}
+			p.logger.WithError(err).Warn("Failed to purge expired objects from the trash")
+		case purged > 0:
+			p.logger.WithField("purged", purged).Info("Purged expired objects from the trash")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(p.config.Interval):
+		}
+	}
+}
+
+// PurgeOnce removes everything deleted longer ago than the retention period and returns how
+// many objects and relationships it removed
+func (p *TrashPurger) PurgeOnce(ctx context.Context) (int, error) {
+	return p.trash.PurgeExpired(ctx, time.Now().Add(-p.config.RetentionPeriod), p.config.BatchSize)
+}
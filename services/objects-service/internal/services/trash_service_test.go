@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// trash marks objects deleted at a time, as objectRepository.Delete does
+func (r *memoryObjects) trash(at time.Time, objects ...*models.Object) {
+	for _, object := range objects {
+		deletedAt := at
+		r.objects[object.ID].DeletedAt = &deletedAt
+		r.objects[object.ID].Status = models.StatusDeleted
+	}
+}
+
+func (r *memoryObjects) GetDeletedDescendants(ctx context.Context, rootID int64) ([]*models.Object, error) {
+	descendants := []*models.Object{}
+	parents := []int64{rootID}
+	for i := 0; i < len(parents); i++ {
+		var children []*models.Object
+		for _, object := range r.objects {
+			if object.ParentObjectID != nil && *object.ParentObjectID == parents[i] && object.DeletedAt != nil {
+				copied := *object
+				children = append(children, &copied)
+			}
+		}
+		sort.Slice(children, func(i, j int) bool { return children[i].ID < children[j].ID })
+		for _, child := range children {
+			descendants = append(descendants, child)
+			parents = append(parents, child.ID)
+		}
+	}
+	return descendants, nil
+}
+
+func (r *memoryObjects) ListExpired(ctx context.Context, deletedBefore time.Time, limit int) ([]*models.Object, error) {
+	expired := []*models.Object{}
+	for _, object := range r.objects {
+		if object.DeletedAt == nil || !object.DeletedAt.Before(deletedBefore) {
+			continue
+		}
+		children, _ := r.GetChildren(ctx, object.ID)
+		if len(children) == 0 {
+			copied := *object
+			expired = append(expired, &copied)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].ID < expired[j].ID })
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+	return expired, nil
+}
+
+func (r *memoryObjects) Restore(ctx context.Context, id int64, updatedBy string) (*models.Object, error) {
+	object, ok := r.objects[id]
+	if !ok || object.DeletedAt == nil {
+		return nil, repository.ErrNotFound
+	}
+	object.DeletedAt = nil
+	object.Status = models.StatusActive
+	object.UpdatedBy = updatedBy
+	object.Version++
+	return r.GetByID(ctx, id)
+}
+
+func (r *memoryObjects) Purge(ctx context.Context, ids []int64) error {
+	for _, id := range ids {
+		if object, ok := r.objects[id]; !ok || object.DeletedAt == nil {
+			return repository.ErrNotFound
+		}
+	}
+	for _, id := range ids {
+		delete(r.objects, id)
+	}
+	return nil
+}
+
+func (r *memoryObjects) CanDelete(ctx context.Context, id int64) (bool, error) {
+	for _, object := range r.objects {
+		if object.ParentObjectID != nil && *object.ParentObjectID == id && object.DeletedAt == nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (r *memoryRelationships) ListDeletedForObjects(ctx context.Context, objectIDs []int64) ([]*models.Relationship, error) {
+	listed := map[int64]bool{}
+	for _, id := range objectIDs {
+		listed[id] = true
+	}
+	rels := []*models.Relationship{}
+	for _, rel := range r.rels {
+		if !r.trashed[rel.ObjectID] || !(listed[rel.SourceObjectID] || listed[rel.TargetObjectID]) {
+			continue
+		}
+		_, sourceErr := r.objects.GetByID(ctx, rel.SourceObjectID)
+		_, targetErr := r.objects.GetByID(ctx, rel.TargetObjectID)
+		if sourceErr == nil && targetErr == nil {
+			copied := *rel
+			rels = append(rels, &copied)
+		}
+	}
+	return rels, nil
+}
+
+func (r *memoryRelationships) Restore(ctx context.Context, objectIDs []int64) error {
+	for _, id := range objectIDs {
+		delete(r.trashed, id)
+	}
+	return nil
+}
+
+func (r *memoryRelationships) PurgeDeletedBefore(ctx context.Context, deletedBefore time.Time) (int, error) {
+	kept := r.rels[:0]
+	for _, rel := range r.rels {
+		if !r.trashed[rel.ObjectID] {
+			kept = append(kept, rel)
+		}
+	}
+	purged := len(r.rels) - len(kept)
+	r.rels = kept
+	r.trashed = map[int64]bool{}
+	return purged, nil
+}
+
+func (r *memoryRelationshipTypes) GetByID(ctx context.Context, id int64) (*models.RelationshipType, error) {
+	relType, ok := r.types[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *relType
+	return &copied, nil
+}
+
+// trashFixture is a tree root > a > a1 and an outside object, with a linked to the outside object
+// and the outside object owning a1
+type trashFixture struct {
+	objects *memoryObjects
+	rels    *memoryRelationships
+	db      *memoryTxDB
+	service TrashService
+	root    *models.Object
+	a       *models.Object
+	a1      *models.Object
+	outside *models.Object
+	link    *models.Relationship
+	owns    *models.Relationship
+}
+
+func newTrashFixture() *trashFixture {
+	types := newMemoryObjectTypes()
+	folder := types.add("Folder", "", false)
+	relTypes := newMemoryRelationshipTypes()
+	relTypes.add("links_to", "", models.CardinalityManyToMany)
+	relTypes.add("owns", "", models.CardinalityOneToMany)
+
+	f := &trashFixture{objects: newMemoryObjects()}
+	f.rels = newMemoryRelationships(f.objects, relTypes)
+	f.root = f.objects.add("root", folder.ID, nil)
+	f.a = f.objects.add("a", folder.ID, f.root)
+	f.a1 = f.objects.add("a1", folder.ID, f.a)
+	f.outside = f.objects.add("outside", folder.ID, nil)
+	f.link = f.rels.add("links_to", f.a, f.outside)
+	f.owns = f.rels.add("owns", f.outside, f.a1)
+
+	f.db = newMemoryTxDB(f.objects, types)
+	f.db.relRepo = f.rels
+	f.db.relTypeRepo = relTypes
+	f.service = NewTrashService(f.objects, types, f.rels, relTypes, f.db)
+	return f
+}
+
+// deleteSubtree sends a and a1 to the trash with their relationships
+func (f *trashFixture) deleteSubtree(at time.Time) {
+	f.objects.trash(at, f.a, f.a1)
+	f.rels.trashed[f.link.ObjectID] = true
+	f.rels.trashed[f.owns.ObjectID] = true
+}
+
+func TestTrashService_RestoreSubtreeWithRelationships(t *testing.T) {
+	f := newTrashFixture()
+	f.deleteSubtree(time.Now())
+
+	result, err := f.service.Restore(context.Background(), f.a.ID, &models.RestoreObjectRequest{
+		IncludeDescendants: true, IncludeRelationships: true, UpdatedBy: "alice",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, f.a.ID, result.Object.ID)
+	assert.Equal(t, models.StatusActive, result.Object.Status)
+	assert.Equal(t, int64(2), result.Object.Version)
+	assert.Equal(t, []int64{f.a1.ID}, result.Descendants)
+	assert.ElementsMatch(t, []int64{f.link.ObjectID, f.owns.ObjectID}, result.Relationships)
+	assert.Nil(t, f.objects.objects[f.a1.ID].DeletedAt)
+	assert.Empty(t, f.rels.trashed)
+	assert.Equal(t, []string{"object.restored", "object.restored"}, f.db.outbox.eventTypes())
+}
+
+func TestTrashService_RestoreObjectOnly(t *testing.T) {
+	f := newTrashFixture()
+	f.deleteSubtree(time.Now())
+
+	result, err := f.service.Restore(context.Background(), f.a.ID, &models.RestoreObjectRequest{})
+
+	require.NoError(t, err)
+	assert.Empty(t, result.Descendants)
+	assert.Empty(t, result.Relationships)
+	assert.NotNil(t, f.objects.objects[f.a1.ID].DeletedAt)
+	assert.True(t, f.rels.trashed[f.link.ObjectID])
+}
+
+func TestTrashService_RestoreKeepsRelationshipsTheRulesNoLongerAllow(t *testing.T) {
+	f := newTrashFixture()
+	f.deleteSubtree(time.Now())
+	// a1 got another owner while the first one was in the trash
+	other := f.objects.add("other", f.outside.ObjectTypeID, nil)
+	f.rels.add("owns", other, f.a1)
+
+	result, err := f.service.Restore(context.Background(), f.a.ID, &models.RestoreObjectRequest{
+		IncludeDescendants: true, IncludeRelationships: true,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{f.link.ObjectID}, result.Relationships)
+	assert.True(t, f.rels.trashed[f.owns.ObjectID])
+}
+
+func TestTrashService_RejectsInvalidRestores(t *testing.T) {
+	f := newTrashFixture()
+	f.deleteSubtree(time.Now())
+	ctx := context.Background()
+
+	_, err := f.service.Restore(ctx, f.a1.ID, &models.RestoreObjectRequest{})
+	assert.ErrorIs(t, err, ErrObjectDeleted, "parent still in the trash")
+
+	_, err = f.service.Restore(ctx, f.root.ID, &models.RestoreObjectRequest{})
+	assert.ErrorIs(t, err, ErrObjectNotDeleted)
+
+	_, err = f.service.Restore(ctx, 999, &models.RestoreObjectRequest{})
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	assert.Empty(t, f.db.outbox.eventTypes())
+}
+
+func TestTrashService_PurgeSubtree(t *testing.T) {
+	f := newTrashFixture()
+	f.deleteSubtree(time.Now())
+
+	result, err := f.service.Purge(context.Background(), f.a.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int64{f.a.ID, f.a1.ID}, result.Purged)
+	assert.NotContains(t, f.objects.objects, f.a.ID)
+	assert.NotContains(t, f.objects.objects, f.a1.ID)
+	assert.Equal(t, []string{"object.purged", "object.purged"}, f.db.outbox.eventTypes())
+}
+
+func TestTrashService_PurgeRejectsObjectsInUse(t *testing.T) {
+	f := newTrashFixture()
+	ctx := context.Background()
+
+	_, err := f.service.Purge(ctx, f.a.ID)
+	assert.ErrorIs(t, err, ErrObjectNotDeleted)
+
+	// A live child would lose its parent
+	f.objects.trash(time.Now(), f.a)
+	_, err = f.service.Purge(ctx, f.a.ID)
+	assert.ErrorIs(t, err, ErrObjectHasLiveChild)
+	assert.Contains(t, f.objects.objects, f.a.ID)
+}
+
+func TestTrashService_PurgeExpiredLeavesFirst(t *testing.T) {
+	f := newTrashFixture()
+	f.deleteSubtree(time.Now().Add(-48 * time.Hour))
+	recent := f.objects.add("recent", f.root.ObjectTypeID, nil)
+	f.objects.trash(time.Now(), recent)
+
+	purger := NewTrashPurger(f.service, TrashPurgerConfig{RetentionPeriod: 24 * time.Hour, BatchSize: 1}, nil)
+	purged, err := purger.PurgeOnce(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, purged, "two objects and their two relationships")
+	assert.NotContains(t, f.objects.objects, f.a.ID)
+	assert.NotContains(t, f.objects.objects, f.a1.ID)
+	assert.Contains(t, f.objects.objects, recent.ID)
+	assert.Equal(t, []string{"object.purged", "object.purged"}, f.db.outbox.eventTypes())
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// Webhook deliveries are two steps. The WebhookSink, an outbox sink, turns every event into a
+// delivery row per matching subscription; the WebhookDeliverer then POSTs due deliveries to their
+// subscriptions, retrying failures with exponential backoff until they run out of attempts.
+
+// Headers of webhook requests
+const (
+	WebhookHeaderDelivery  = "X-Webhook-Delivery"
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderEventID   = "X-Webhook-Event-ID"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+)
+
+// SignWebhookPayload returns the signature header value of a payload sent at timestamp (Unix
+// seconds): "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<payload>" keyed with the secret.
+// Receivers recompute it to authenticate the request and reject old timestamps to stop replays.
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookEventSubject holds the fields of an event snapshot that subscriptions filter on
+type webhookEventSubject struct {
+	ObjectTypeID        int64  `json:"object_type_id"`
+	RelationshipTypeKey string `json:"relationship_type_key"`
+}
+
+// webhookMatches reports whether a subscription wants an event. Event types narrow all events;
+// object type IDs narrow object and object type events, relationship type keys relationship events.
+func webhookMatches(sub *models.WebhookSubscription, event *models.OutboxEvent) bool {
+	if len(sub.EventTypes) > 0 && !containsString(sub.EventTypes, event.EventType) {
+		return false
+	}
+
+	var subject webhookEventSubject
+	snapshot := event.After
+	if len(snapshot) == 0 {
+		snapshot = event.Before
+	}
+	if len(snapshot) > 0 {
+		_ = json.Unmarshal(snapshot, &subject)
+	}
+
+	switch event.EntityType {
+	case models.EventEntityObject:
+		return len(sub.ObjectTypeIDs) == 0 || containsInt64(sub.ObjectTypeIDs, subject.ObjectTypeID)
+	case models.EventEntityObjectType:
+		return len(sub.ObjectTypeIDs) == 0 || containsInt64(sub.ObjectTypeIDs, event.EntityID)
+	case models.EventEntityRelationship:
+		return len(sub.RelationshipTypeKeys) == 0 || containsString(sub.RelationshipTypeKeys, subject.RelationshipTypeKey)
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt64(values []int64, value int64) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSink enqueues a delivery of every event for each active subscription that wants it
+type WebhookSink struct {
+	repo repository.WebhookRepository
+}
+
+// NewWebhookSink creates an outbox sink feeding webhook deliveries
+func NewWebhookSink(repo repository.WebhookRepository) *WebhookSink {
+	return &WebhookSink{repo: repo}
+}
+
+// Name implements EventSink
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+// Publish implements EventSink. Events published again after a failed dispatch are not enqueued
+// twice for a subscription.
+func (s *WebhookSink) Publish(ctx context.Context, events []*models.OutboxEvent) error {
+	subs, err := s.repo.ActiveSubscriptions(ctx)
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+
+	deliveries := []*models.WebhookDelivery{}
+	for _, event := range events {
+		var payload json.RawMessage
+		for _, sub := range subs {
+			if !webhookMatches(sub, event) {
+				continue
+			}
+			if payload == nil {
+				if payload, err = json.Marshal(event); err != nil {
+					return fmt.Errorf("failed to encode event %s: %w", event.EventID, err)
+				}
+			}
+			deliveries = append(deliveries, &models.WebhookDelivery{
+				SubscriptionID: sub.ID,
+				EventID:        event.EventID,
+				EventType:      event.EventType,
+				Payload:        payload,
+			})
+		}
+	}
+	return s.repo.EnqueueDeliveries(ctx, deliveries...)
+}
+
+// WebhookDelivererConfig configures webhook delivery
+type WebhookDelivererConfig struct {
+	BatchSize      int           // Deliveries sent concurrently per poll
+	PollInterval   time.Duration // Wait between polls when nothing is due
+	Timeout        time.Duration // Timeout of a single request
+	MaxAttempts    int           // Attempts before a delivery is dead
+	InitialBackoff time.Duration // Wait before the first retry
+	MaxBackoff     time.Duration // Longest wait between retries
+}
+
+// WebhookDeliverer sends due webhook deliveries and records each attempt. A 2xx response is a
+// success; anything else, redirects included, is retried.
+type WebhookDeliverer struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+	config WebhookDelivererConfig
+	logger *logrus.Logger
+	now    func() time.Time
+}
+
+// NewWebhookDeliverer creates a deliverer sending webhooks with its own HTTP client
+func NewWebhookDeliverer(repo repository.WebhookRepository, config WebhookDelivererConfig, logger *logrus.Logger) *WebhookDeliverer {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 20
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 8
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = 10 * time.Second
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = config.InitialBackoff
+	}
+
+	return &WebhookDeliverer{
+		repo: repo,
+		client: &http.Client{
+			Timeout: config.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		config: config,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Run delivers due webhooks until ctx is cancelled
+func (d *WebhookDeliverer) Run(ctx context.Context) {
+	for {
+		delivered, err := d.DeliverDue(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.logger.WithError(err).Warn("Failed to deliver webhooks")
+		}
+
+		wait := d.config.PollInterval
+		if err == nil && delivered == d.config.BatchSize {
+			wait = 0
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// DeliverDue sends one batch of due deliveries and returns how many were attempted
+func (d *WebhookDeliverer) DeliverDue(ctx context.Context) (int, error) {
+	// A claimed delivery is not due again until its attempt must have finished
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, d.config.BatchSize, 2*d.config.Timeout)
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+
+	subs := map[int64]*models.WebhookSubscription{}
+	for _, delivery := range deliveries {
+		if _, ok := subs[delivery.SubscriptionID]; ok {
+			continue
+		}
+		sub, err := d.repo.GetSubscriptionByID(ctx, delivery.SubscriptionID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return 0, err
+		}
+		// Deliveries of a subscription deleted meanwhile were deleted with it
+		subs[delivery.SubscriptionID] = sub
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(deliveries))
+	for i, delivery := range deliveries {
+		if subs[delivery.SubscriptionID] == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			d.attempt(ctx, subs[delivery.SubscriptionID], delivery)
+			errs[i] = d.repo.RecordAttempt(ctx, delivery)
+		}(i, delivery)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// attempt sends a delivery once and updates it with the outcome
+func (d *WebhookDeliverer) attempt(ctx context.Context, sub *models.WebhookSubscription, delivery *models.WebhookDelivery) {
+	now := d.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+
+	statusCode, err := d.send(ctx, sub, delivery)
+	delivery.LastStatusCode = nil
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+
+	if err == nil {
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = nil
+		return
+	}
+
+	cause := err.Error()
+	delivery.LastError = &cause
+	if delivery.Attempts >= d.config.MaxAttempts || !sub.IsActive {
+		delivery.Status = models.WebhookDeliveryDead
+		d.logger.WithFields(logrus.Fields{
+			"subscription_id": sub.PublicID,
+			"delivery_id":     delivery.PublicID,
+			"event_id":        delivery.EventID,
+			"attempts":        delivery.Attempts,
+		}).WithError(err).Warn("Webhook delivery is dead")
+		return
+	}
+	delivery.Status = models.WebhookDeliveryPending
+	delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+}
+
+// send POSTs the signed payload and returns the response status code, if there was a response
+func (d *WebhookDeliverer) send(ctx context.Context, sub *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	if !sub.IsActive {
+		return 0, fmt.Errorf("subscription is inactive")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.TargetURL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "objects-service-webhooks")
+	req.Header.Set(WebhookHeaderDelivery, delivery.PublicID.String())
+	req.Header.Set(WebhookHeaderEvent, delivery.EventType)
+	req.Header.Set(WebhookHeaderEventID, delivery.EventID.String())
+	req.Header.Set(WebhookHeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookHeaderSignature, SignWebhookPayload(sub.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff doubles the initial backoff for every failed attempt after the first, up to MaxBackoff
+func (d *WebhookDeliverer) backoff(attempts int) time.Duration {
+	wait := d.config.InitialBackoff
+	for i := 1; i < attempts && wait < d.config.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > d.config.MaxBackoff {
+		wait = d.config.MaxBackoff
+	}
+	return wait
+}
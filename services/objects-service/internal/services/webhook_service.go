@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/google/uuid"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+var (
+	ErrWebhookNotFound              = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound      = errors.New("webhook delivery not found")
+	ErrWebhookDeliveryNotReplayable = errors.New("webhook delivery is not replayable")
+)
+
+const minWebhookSecretLength = 16
+
+// webhookEventTypes are the event types a subscription can filter on
+var webhookEventTypes = map[string]bool{
+	models.EventType(models.EventEntityObject, models.EventActionCreated):       true,
+	models.EventType(models.EventEntityObject, models.EventActionUpdated):       true,
+	models.EventType(models.EventEntityObject, models.EventActionDeleted):       true,
+	models.EventType(models.EventEntityObject, models.EventActionRestored):      true,
+	models.EventType(models.EventEntityObject, models.EventActionMoved):         true,
+	models.EventType(models.EventEntityObject, models.EventActionPurged):        true,
+	models.EventType(models.EventEntityObjectType, models.EventActionCreated):   true,
+	models.EventType(models.EventEntityObjectType, models.EventActionUpdated):   true,
+	models.EventType(models.EventEntityObjectType, models.EventActionDeleted):   true,
+	models.EventType(models.EventEntityObjectType, models.EventActionMoved):     true,
+	models.EventType(models.EventEntityRelationship, models.EventActionCreated): true,
+	models.EventType(models.EventEntityRelationship, models.EventActionUpdated): true,
+	models.EventType(models.EventEntityRelationship, models.EventActionDeleted): true,
+}
+
+// WebhookService manages webhook subscriptions and the log of their deliveries
+type WebhookService interface {
+	Create(ctx context.Context, req *models.CreateWebhookSubscriptionRequest) (*models.WebhookSubscription, error)
+	Get(ctx context.Context, publicID uuid.UUID) (*models.WebhookSubscription, error)
+	List(ctx context.Context) ([]*models.WebhookSubscription, error)
+	Update(ctx context.Context, publicID uuid.UUID, req *models.UpdateWebhookSubscriptionRequest) (*models.WebhookSubscription, error)
+	Delete(ctx context.Context, publicID uuid.UUID) error
+	ListDeliveries(ctx context.Context, publicID uuid.UUID, filter *models.WebhookDeliveryFilter) ([]*models.WebhookDelivery, int64, error)
+	ReplayDelivery(ctx context.Context, publicID, deliveryID uuid.UUID) (*models.WebhookDelivery, error)
+}
+
+type webhookService struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookService{repo: repo}
+}
+
+// Create creates a subscription. The returned subscription is the only one to carry its secret;
+// a secret is generated when the request has none.
+func (s *webhookService) Create(ctx context.Context, req *models.CreateWebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	if req.Name == "" {
+		return nil, fmt.Errorf("name is required: %w", repository.ErrInvalidInput)
+	}
+	if err := checkWebhookURL(req.TargetURL); err != nil {
+		return nil, err
+	}
+	if err := checkWebhookFilters(req.EventTypes, req.ObjectTypeIDs, req.RelationshipTypeKeys); err != nil {
+		return nil, err
+	}
+
+	if req.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		req.Secret = secret
+	} else if len(req.Secret) < minWebhookSecretLength {
+		return nil, fmt.Errorf("secret must be at least %d characters: %w", minWebhookSecretLength, repository.ErrInvalidInput)
+	}
+
+	return s.repo.CreateSubscription(ctx, req)
+}
+
+func (s *webhookService) Get(ctx context.Context, publicID uuid.UUID) (*models.WebhookSubscription, error) {
+	sub, err := s.subscription(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+func (s *webhookService) List(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	subs, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		sub.Secret = ""
+	}
+	return subs, nil
+}
+
+func (s *webhookService) Update(ctx context.Context, publicID uuid.UUID, req *models.UpdateWebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	existing, err := s.subscription(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil && *req.Name == "" {
+		return nil, fmt.Errorf("name cannot be empty: %w", repository.ErrInvalidInput)
+	}
+	if req.TargetURL != nil {
+		if err := checkWebhookURL(*req.TargetURL); err != nil {
+			return nil, err
+		}
+	}
+	if req.Secret != nil && len(*req.Secret) < minWebhookSecretLength {
+		return nil, fmt.Errorf("secret must be at least %d characters: %w", minWebhookSecretLength, repository.ErrInvalidInput)
+	}
+
+	eventTypes, objectTypeIDs, relTypeKeys := existing.EventTypes, existing.ObjectTypeIDs, existing.RelationshipTypeKeys
+	if req.EventTypes != nil {
+		eventTypes = *req.EventTypes
+	}
+	if req.ObjectTypeIDs != nil {
+		objectTypeIDs = *req.ObjectTypeIDs
+	}
+	if req.RelationshipTypeKeys != nil {
+		relTypeKeys = *req.RelationshipTypeKeys
+	}
+	if err := checkWebhookFilters(eventTypes, objectTypeIDs, relTypeKeys); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.UpdateSubscription(ctx, existing.ID, req)
+	if err != nil {
+		return nil, err
+	}
+	updated.Secret = ""
+	return updated, nil
+}
+
+func (s *webhookService) Delete(ctx context.Context, publicID uuid.UUID) error {
+	sub, err := s.subscription(ctx, publicID)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteSubscription(ctx, sub.ID)
+}
+
+// ListDeliveries returns the delivery log of a subscription, newest first
+func (s *webhookService) ListDeliveries(ctx context.Context, publicID uuid.UUID, filter *models.WebhookDeliveryFilter) ([]*models.WebhookDelivery, int64, error) {
+	sub, err := s.subscription(ctx, publicID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch filter.Status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliverySucceeded, models.WebhookDeliveryDead:
+	default:
+		return nil, 0, fmt.Errorf("unknown delivery status %q: %w", filter.Status, repository.ErrInvalidInput)
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+	if filter.Limit > 500 {
+		filter.Limit = 500
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return s.repo.ListDeliveries(ctx, sub.ID, filter)
+}
+
+// ReplayDelivery sends a dead delivery again, with a fresh set of attempts
+func (s *webhookService) ReplayDelivery(ctx context.Context, publicID, deliveryID uuid.UUID) (*models.WebhookDelivery, error) {
+	sub, err := s.subscription(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.repo.GetDelivery(ctx, sub.ID, deliveryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrWebhookDeliveryNotFound, deliveryID)
+		}
+		return nil, err
+	}
+	if delivery.Status != models.WebhookDeliveryDead {
+		return nil, fmt.Errorf("%w: delivery is %s, only dead deliveries are replayed", ErrWebhookDeliveryNotReplayable, delivery.Status)
+	}
+
+	return s.repo.ReplayDelivery(ctx, delivery.ID)
+}
+
+func (s *webhookService) subscription(ctx context.Context, publicID uuid.UUID) (*models.WebhookSubscription, error) {
+	sub, err := s.repo.GetSubscription(ctx, publicID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrWebhookNotFound, publicID)
+		}
+		return nil, err
+	}
+	return sub, nil
+}
+
+// checkWebhookURL accepts absolute http and https URLs
+func checkWebhookURL(raw string) error {
+	target, err := url.Parse(raw)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("target_url must be an absolute http or https URL: %w", repository.ErrInvalidInput)
+	}
+	return nil
+}
+
+func checkWebhookFilters(eventTypes []string, objectTypeIDs []int64, relTypeKeys []string) error {
+	for _, eventType := range eventTypes {
+		if !webhookEventTypes[eventType] {
+			return fmt.Errorf("unknown event type %q: %w", eventType, repository.ErrInvalidInput)
+		}
+	}
+	for _, id := range objectTypeIDs {
+		if id <= 0 {
+			return fmt.Errorf("invalid object type id %d: %w", id, repository.ErrInvalidInput)
+		}
+	}
+	for _, key := range relTypeKeys {
+		if key == "" {
+			return fmt.Errorf("relationship type keys cannot be empty: %w", repository.ErrInvalidInput)
+		}
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
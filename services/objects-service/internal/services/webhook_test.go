@@ -0,0 +1,513 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/objects-service/internal/repository"
+)
+
+// memoryWebhooks is an in-memory WebhookRepository with its own clock deciding which deliveries
+// are due
+type memoryWebhooks struct {
+	repository.WebhookRepository
+	mu         sync.Mutex
+	now        time.Time
+	subs       []*models.WebhookSubscription
+	deliveries []*models.WebhookDelivery
+	nextID     int64
+}
+
+func newMemoryWebhooks() *memoryWebhooks {
+	return &memoryWebhooks{now: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (r *memoryWebhooks) clock() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.now
+}
+
+func (r *memoryWebhooks) advance(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.now = r.now.Add(d)
+}
+
+func (r *memoryWebhooks) addSubscription(sub *models.WebhookSubscription) *models.WebhookSubscription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	sub.ID = r.nextID
+	sub.PublicID = uuid.New()
+	r.subs = append(r.subs, sub)
+	return sub
+}
+
+func (r *memoryWebhooks) CreateSubscription(ctx context.Context, input *models.CreateWebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	return r.addSubscription(&models.WebhookSubscription{
+		Name:                 input.Name,
+		TargetURL:            input.TargetURL,
+		Secret:               input.Secret,
+		EventTypes:           input.EventTypes,
+		ObjectTypeIDs:        input.ObjectTypeIDs,
+		RelationshipTypeKeys: input.RelationshipTypeKeys,
+		IsActive:             input.IsActive == nil || *input.IsActive,
+		CreatedBy:            input.CreatedBy,
+	}), nil
+}
+
+func (r *memoryWebhooks) GetSubscription(ctx context.Context, publicID uuid.UUID) (*models.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sub := range r.subs {
+		if sub.PublicID == publicID {
+			copied := *sub
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryWebhooks) GetSubscriptionByID(ctx context.Context, id int64) (*models.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sub := range r.subs {
+		if sub.ID == id {
+			copied := *sub
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryWebhooks) ActiveSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var active []*models.WebhookSubscription
+	for _, sub := range r.subs {
+		if sub.IsActive {
+			active = append(active, sub)
+		}
+	}
+	return active, nil
+}
+
+func (r *memoryWebhooks) EnqueueDeliveries(ctx context.Context, deliveries ...*models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+next:
+	for _, delivery := range deliveries {
+		for _, existing := range r.deliveries {
+			if existing.SubscriptionID == delivery.SubscriptionID && existing.EventID == delivery.EventID {
+				continue next
+			}
+		}
+		r.nextID++
+		copied := *delivery
+		copied.ID = r.nextID
+		copied.PublicID = uuid.New()
+		copied.Status = models.WebhookDeliveryPending
+		copied.NextAttemptAt = r.now
+		copied.CreatedAt = r.now
+		r.deliveries = append(r.deliveries, &copied)
+	}
+	return nil
+}
+
+func (r *memoryWebhooks) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []*models.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if len(claimed) == limit {
+			break
+		}
+		if delivery.Status != models.WebhookDeliveryPending || delivery.NextAttemptAt.After(r.now) {
+			continue
+		}
+		delivery.NextAttemptAt = r.now.Add(lease)
+		copied := *delivery
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+func (r *memoryWebhooks) RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, existing := range r.deliveries {
+		if existing.ID == delivery.ID {
+			copied := *delivery
+			r.deliveries[i] = &copied
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r *memoryWebhooks) GetDelivery(ctx context.Context, subscriptionID int64, publicID uuid.UUID) (*models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, delivery := range r.deliveries {
+		if delivery.SubscriptionID == subscriptionID && delivery.PublicID == publicID {
+			copied := *delivery
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryWebhooks) ReplayDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, delivery := range r.deliveries {
+		if delivery.ID == id {
+			delivery.Status = models.WebhookDeliveryPending
+			delivery.Attempts = 0
+			delivery.NextAttemptAt = r.now
+			delivery.DeliveredAt = nil
+			copied := *delivery
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memoryWebhooks) delivery(i int) models.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.deliveries[i]
+}
+
+// webhookReceiver is a local HTTP stand-in for a subscriber. It verifies signatures with the
+// secret and answers with the queued status codes, then 200.
+type webhookReceiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	secret   string
+	statuses []int
+	received []*http.Request
+	bodies   [][]byte
+	badSigs  int
+}
+
+func newWebhookReceiver(t *testing.T, secret string, statuses ...int) *webhookReceiver {
+	receiver := &webhookReceiver{secret: secret, statuses: statuses}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(receiver.handle))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func (w *webhookReceiver) handle(rw http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	timestamp, _ := strconv.ParseInt(req.Header.Get(WebhookHeaderTimestamp), 10, 64)
+	if req.Header.Get(WebhookHeaderSignature) != SignWebhookPayload(w.secret, timestamp, body) {
+		w.badSigs++
+	}
+	w.received = append(w.received, req)
+	w.bodies = append(w.bodies, body)
+
+	status := http.StatusOK
+	if len(w.statuses) > 0 {
+		status, w.statuses = w.statuses[0], w.statuses[1:]
+	}
+	rw.WriteHeader(status)
+}
+
+func (w *webhookReceiver) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.received)
+}
+
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func newTestDeliverer(repo *memoryWebhooks, config WebhookDelivererConfig) *WebhookDeliverer {
+	deliverer := NewWebhookDeliverer(repo, config, quietLogger())
+	deliverer.now = repo.clock
+	return deliverer
+}
+
+func webhookEvent(entityType, action string, entityID int64, after interface{}) *models.OutboxEvent {
+	snapshot, _ := json.Marshal(after)
+	return &models.OutboxEvent{
+		Offset:     entityID,
+		EventID:    uuid.New(),
+		EventType:  models.EventType(entityType, action),
+		EntityType: entityType,
+		EntityID:   entityID,
+		After:      snapshot,
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	signature := SignWebhookPayload("secret", 1700000000, []byte(`{"a":1}`))
+
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	assert.Equal(t, signature, SignWebhookPayload("secret", 1700000000, []byte(`{"a":1}`)))
+	assert.NotEqual(t, signature, SignWebhookPayload("other", 1700000000, []byte(`{"a":1}`)))
+	assert.NotEqual(t, signature, SignWebhookPayload("secret", 1700000001, []byte(`{"a":1}`)))
+	assert.NotEqual(t, signature, SignWebhookPayload("secret", 1700000000, []byte(`{"a":2}`)))
+}
+
+func TestWebhookMatches(t *testing.T) {
+	objectCreated := webhookEvent(models.EventEntityObject, models.EventActionCreated, 1, map[string]interface{}{"object_type_id": 7})
+	typeUpdated := webhookEvent(models.EventEntityObjectType, models.EventActionUpdated, 7, map[string]interface{}{"id": 7})
+	relCreated := webhookEvent(models.EventEntityRelationship, models.EventActionCreated, 3, map[string]interface{}{"relationship_type_key": "parent_of"})
+	objectDeleted := &models.OutboxEvent{
+		EventType:  models.EventType(models.EventEntityObject, models.EventActionDeleted),
+		EntityType: models.EventEntityObject,
+		Before:     json.RawMessage(`{"object_type_id": 8}`),
+	}
+
+	tests := []struct {
+		name  string
+		sub   models.WebhookSubscription
+		event *models.OutboxEvent
+		want  bool
+	}{
+		{"no filters match everything", models.WebhookSubscription{}, relCreated, true},
+		{"event type filter matches", models.WebhookSubscription{EventTypes: []string{"object.created"}}, objectCreated, true},
+		{"event type filter rejects", models.WebhookSubscription{EventTypes: []string{"object.deleted"}}, objectCreated, false},
+		{"object type filter matches objects", models.WebhookSubscription{ObjectTypeIDs: []int64{7}}, objectCreated, true},
+		{"object type filter rejects objects", models.WebhookSubscription{ObjectTypeIDs: []int64{9}}, objectCreated, false},
+		{"object type filter uses before of deletions", models.WebhookSubscription{ObjectTypeIDs: []int64{8}}, objectDeleted, true},
+		{"object type filter matches the type itself", models.WebhookSubscription{ObjectTypeIDs: []int64{7}}, typeUpdated, true},
+		{"object type filter ignores relationships", models.WebhookSubscription{ObjectTypeIDs: []int64{9}}, relCreated, true},
+		{"relationship type filter matches", models.WebhookSubscription{RelationshipTypeKeys: []string{"parent_of"}}, relCreated, true},
+		{"relationship type filter rejects", models.WebhookSubscription{RelationshipTypeKeys: []string{"owns"}}, relCreated, false},
+		{"relationship type filter ignores objects", models.WebhookSubscription{RelationshipTypeKeys: []string{"owns"}}, objectCreated, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, webhookMatches(&tt.sub, tt.event))
+		})
+	}
+}
+
+func TestWebhookSink_EnqueuesMatchingSubscriptionsOnce(t *testing.T) {
+	repo := newMemoryWebhooks()
+	all := repo.addSubscription(&models.WebhookSubscription{IsActive: true})
+	created := repo.addSubscription(&models.WebhookSubscription{IsActive: true, EventTypes: []string{"object.created"}})
+	repo.addSubscription(&models.WebhookSubscription{IsActive: false})
+
+	events := []*models.OutboxEvent{
+		webhookEvent(models.EventEntityObject, models.EventActionCreated, 1, map[string]interface{}{"object_type_id": 7}),
+		webhookEvent(models.EventEntityObject, models.EventActionUpdated, 1, map[string]interface{}{"object_type_id": 7}),
+	}
+	sink := NewWebhookSink(repo)
+	require.NoError(t, sink.Publish(context.Background(), events))
+	// A redelivered batch does not enqueue duplicates
+	require.NoError(t, sink.Publish(context.Background(), events))
+
+	require.Len(t, repo.deliveries, 3)
+	assert.Equal(t, all.ID, repo.deliveries[0].SubscriptionID)
+	assert.Equal(t, created.ID, repo.deliveries[1].SubscriptionID)
+	assert.Equal(t, all.ID, repo.deliveries[2].SubscriptionID)
+	assert.Equal(t, "object.updated", repo.deliveries[2].EventType)
+
+	var payload models.OutboxEvent
+	require.NoError(t, json.Unmarshal(repo.deliveries[0].Payload, &payload))
+	assert.Equal(t, events[0].EventID, payload.EventID)
+}
+
+func TestWebhookDeliverer_SignedDelivery(t *testing.T) {
+	repo := newMemoryWebhooks()
+	receiver := newWebhookReceiver(t, "0123456789abcdef")
+	sub := repo.addSubscription(&models.WebhookSubscription{TargetURL: receiver.URL, Secret: "0123456789abcdef", IsActive: true})
+	event := webhookEvent(models.EventEntityObject, models.EventActionCreated, 1, map[string]interface{}{"object_type_id": 7})
+	require.NoError(t, NewWebhookSink(repo).Publish(context.Background(), []*models.OutboxEvent{event}))
+
+	delivered, err := newTestDeliverer(repo, WebhookDelivererConfig{}).DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	require.Equal(t, 1, receiver.count())
+	assert.Zero(t, receiver.badSigs)
+	req := receiver.received[0]
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, "object.created", req.Header.Get(WebhookHeaderEvent))
+	assert.Equal(t, event.EventID.String(), req.Header.Get(WebhookHeaderEventID))
+	assert.Equal(t, repo.deliveries[0].PublicID.String(), req.Header.Get(WebhookHeaderDelivery))
+	assert.Equal(t, strconv.FormatInt(repo.clock().Unix(), 10), req.Header.Get(WebhookHeaderTimestamp))
+	assert.JSONEq(t, string(repo.deliveries[0].Payload), string(receiver.bodies[0]))
+
+	delivery := repo.delivery(0)
+	assert.Equal(t, sub.ID, delivery.SubscriptionID)
+	assert.Equal(t, models.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	require.NotNil(t, delivery.LastStatusCode)
+	assert.Equal(t, http.StatusOK, *delivery.LastStatusCode)
+	assert.NotNil(t, delivery.DeliveredAt)
+	assert.Nil(t, delivery.LastError)
+
+	// Nothing is left to deliver
+	delivered, err = newTestDeliverer(repo, WebhookDelivererConfig{}).DeliverDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+}
+
+func TestWebhookDeliverer_RetriesWithBackoffUntilDead(t *testing.T) {
+	repo := newMemoryWebhooks()
+	receiver := newWebhookReceiver(t, "0123456789abcdef", 500, 503, 500)
+	repo.addSubscription(&models.WebhookSubscription{TargetURL: receiver.URL, Secret: "0123456789abcdef", IsActive: true})
+	event := webhookEvent(models.EventEntityObject, models.EventActionCreated, 1, map[string]interface{}{"object_type_id": 7})
+	require.NoError(t, NewWebhookSink(repo).Publish(context.Background(), []*models.OutboxEvent{event}))
+
+	deliverer := newTestDeliverer(repo, WebhookDelivererConfig{MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: time.Hour})
+	ctx := context.Background()
+
+	_, err := deliverer.DeliverDue(ctx)
+	require.NoError(t, err)
+	delivery := repo.delivery(0)
+	assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, repo.clock().Add(time.Minute), delivery.NextAttemptAt)
+	require.NotNil(t, delivery.LastError)
+	assert.Contains(t, *delivery.LastError, "500")
+
+	// Not due before its backoff has passed
+	delivered, err := deliverer.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+
+	repo.advance(time.Minute)
+	_, err = deliverer.DeliverDue(ctx)
+	require.NoError(t, err)
+	delivery = repo.delivery(0)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Equal(t, repo.clock().Add(2*time.Minute), delivery.NextAttemptAt)
+	assert.Equal(t, http.StatusServiceUnavailable, *delivery.LastStatusCode)
+
+	repo.advance(2 * time.Minute)
+	_, err = deliverer.DeliverDue(ctx)
+	require.NoError(t, err)
+	delivery = repo.delivery(0)
+	assert.Equal(t, models.WebhookDeliveryDead, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Nil(t, delivery.DeliveredAt)
+	assert.Equal(t, 3, receiver.count())
+
+	// Dead deliveries are not retried
+	repo.advance(time.Hour)
+	delivered, err = deliverer.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+}
+
+func TestWebhookDeliverer_InactiveSubscriptionIsDeadLettered(t *testing.T) {
+	repo := newMemoryWebhooks()
+	receiver := newWebhookReceiver(t, "0123456789abcdef")
+	sub := repo.addSubscription(&models.WebhookSubscription{TargetURL: receiver.URL, Secret: "0123456789abcdef", IsActive: true})
+	require.NoError(t, NewWebhookSink(repo).Publish(context.Background(), []*models.OutboxEvent{
+		webhookEvent(models.EventEntityObject, models.EventActionCreated, 1, map[string]interface{}{}),
+	}))
+	sub.IsActive = false
+
+	_, err := newTestDeliverer(repo, WebhookDelivererConfig{}).DeliverDue(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, models.WebhookDeliveryDead, repo.delivery(0).Status)
+	assert.Zero(t, receiver.count())
+}
+
+func TestWebhookDeliverer_Backoff(t *testing.T) {
+	deliverer := NewWebhookDeliverer(nil, WebhookDelivererConfig{InitialBackoff: 10 * time.Second, MaxBackoff: time.Minute}, quietLogger())
+
+	assert.Equal(t, 10*time.Second, deliverer.backoff(1))
+	assert.Equal(t, 20*time.Second, deliverer.backoff(2))
+	assert.Equal(t, 40*time.Second, deliverer.backoff(3))
+	assert.Equal(t, time.Minute, deliverer.backoff(4))
+	assert.Equal(t, time.Minute, deliverer.backoff(20))
+}
+
+func TestWebhookService_Create(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("generates a secret", func(t *testing.T) {
+		service := NewWebhookService(newMemoryWebhooks())
+		sub, err := service.Create(ctx, &models.CreateWebhookSubscriptionRequest{Name: "hook", TargetURL: "https://example.com/hook"})
+		require.NoError(t, err)
+		assert.Len(t, sub.Secret, 64)
+		assert.True(t, sub.IsActive)
+
+		got, err := service.Get(ctx, sub.PublicID)
+		require.NoError(t, err)
+		assert.Empty(t, got.Secret)
+	})
+
+	invalid := []struct {
+		name string
+		req  models.CreateWebhookSubscriptionRequest
+	}{
+		{"relative URL", models.CreateWebhookSubscriptionRequest{Name: "hook", TargetURL: "/hook"}},
+		{"unsupported scheme", models.CreateWebhookSubscriptionRequest{Name: "hook", TargetURL: "ftp://example.com"}},
+		{"short secret", models.CreateWebhookSubscriptionRequest{Name: "hook", TargetURL: "http://example.com", Secret: "short"}},
+		{"unknown event type", models.CreateWebhookSubscriptionRequest{Name: "hook", TargetURL: "http://example.com", EventTypes: []string{"object.archived"}}},
+		{"invalid object type", models.CreateWebhookSubscriptionRequest{Name: "hook", TargetURL: "http://example.com", ObjectTypeIDs: []int64{0}}},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWebhookService(newMemoryWebhooks()).Create(ctx, &tt.req)
+			assert.ErrorIs(t, err, repository.ErrInvalidInput)
+		})
+	}
+}
+
+func TestWebhookService_ReplayDelivery(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryWebhooks()
+	receiver := newWebhookReceiver(t, "0123456789abcdef", 500)
+	sub := repo.addSubscription(&models.WebhookSubscription{TargetURL: receiver.URL, Secret: "0123456789abcdef", IsActive: true})
+	require.NoError(t, NewWebhookSink(repo).Publish(ctx, []*models.OutboxEvent{
+		webhookEvent(models.EventEntityObject, models.EventActionCreated, 1, map[string]interface{}{}),
+	}))
+	service := NewWebhookService(repo)
+	deliverer := newTestDeliverer(repo, WebhookDelivererConfig{MaxAttempts: 1})
+	deliveryID := repo.delivery(0).PublicID
+
+	_, err := service.ReplayDelivery(ctx, sub.PublicID, deliveryID)
+	assert.True(t, errors.Is(err, ErrWebhookDeliveryNotReplayable), "pending deliveries are not replayed")
+
+	_, err = deliverer.DeliverDue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, models.WebhookDeliveryDead, repo.delivery(0).Status)
+
+	replayed, err := service.ReplayDelivery(ctx, sub.PublicID, deliveryID)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, replayed.Status)
+	assert.Zero(t, replayed.Attempts)
+
+	_, err = deliverer.DeliverDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliverySucceeded, repo.delivery(0).Status)
+	assert.Equal(t, 2, receiver.count())
+
+	_, err = service.ReplayDelivery(ctx, sub.PublicID, uuid.New())
+	assert.ErrorIs(t, err, ErrWebhookDeliveryNotFound)
+	_, err = service.ReplayDelivery(ctx, uuid.New(), deliveryID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+}
@@ -0,0 +1,343 @@
+// Package transfer reads and writes streams of records as NDJSON, one JSON object per line, or
+// as CSV with a header row. CSV columns are the JSON field names of the record type: strings
+// and timestamps are written as they are, other values as JSON, and an empty cell leaves the
+// field unset. Records are read one at a time, so a stream of any length is processed in
+// constant memory.
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Formats of a record stream
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+)
+
+// MaxRecordSize limits the size of a single record read from a stream
+const MaxRecordSize = 1 << 20
+
+// ValidFormat reports whether format is a stream format
+func ValidFormat(format string) bool {
+	return format == FormatNDJSON || format == FormatCSV
+}
+
+// ContentType returns the media type of a stream format
+func ContentType(format string) string {
+	if format == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// RecordError reports a record that could not be read and the 1-based line it starts on.
+// Reading can go on with the next record.
+type RecordError struct {
+	Line int
+	Err  error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// column is a field of a record type as a CSV column
+type column struct {
+	name  string
+	index int
+	text  bool // written and read as plain text rather than JSON
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// columnsOf returns the CSV columns of a struct type: its JSON fields, in declaration order
+func columnsOf(t reflect.Type) ([]column, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("records must be structs, not %s", t)
+	}
+	var columns []column
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		columns = append(columns, column{
+			name:  name,
+			index: i,
+			text:  fieldType.Kind() == reflect.String || fieldType == timeType,
+		})
+	}
+	return columns, nil
+}
+
+// Writer writes records of type T to a stream. Records are buffered until Flush.
+type Writer[T any] struct {
+	format  string
+	out     *bufio.Writer
+	json    *json.Encoder
+	csv     *csv.Writer
+	columns []column
+	header  bool
+}
+
+// NewWriter returns a writer of records in format to w
+func NewWriter[T any](w io.Writer, format string) (*Writer[T], error) {
+	out := bufio.NewWriter(w)
+	switch format {
+	case FormatNDJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetEscapeHTML(false)
+		return &Writer[T]{format: format, out: out, json: encoder}, nil
+	case FormatCSV:
+		columns, err := columnsOf(reflect.TypeOf((*T)(nil)).Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Writer[T]{format: format, out: out, csv: csv.NewWriter(out), columns: columns}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// Write writes one record. The CSV header is written before the first record.
+func (w *Writer[T]) Write(record *T) error {
+	if w.format == FormatNDJSON {
+		return w.json.Encode(record)
+	}
+
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(record).Elem()
+	cells := make([]string, len(w.columns))
+	for i, col := range w.columns {
+		cell, err := formatCell(value.Field(col.index))
+		if err != nil {
+			return fmt.Errorf("%s: %w", col.name, err)
+		}
+		cells[i] = cell
+	}
+	return w.csv.Write(cells)
+}
+
+// writeHeader writes the CSV header unless it was written already
+func (w *Writer[T]) writeHeader() error {
+	if w.header {
+		return nil
+	}
+	names := make([]string, len(w.columns))
+	for i, col := range w.columns {
+		names[i] = col.name
+	}
+	if err := w.csv.Write(names); err != nil {
+		return err
+	}
+	w.header = true
+	return nil
+}
+
+// Flush writes buffered records to the underlying writer. A CSV stream without records still
+// gets its header.
+func (w *Writer[T]) Flush() error {
+	if w.csv != nil {
+		if err := w.writeHeader(); err != nil {
+			return err
+		}
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return w.out.Flush()
+}
+
+// formatCell returns the CSV cell of a field: empty for a nil or zero value
+func formatCell(field reflect.Value) (string, error) {
+	if field.IsZero() {
+		return "", nil
+	}
+	if field.Kind() == reflect.Pointer {
+		field = field.Elem()
+	}
+	if field.Kind() == reflect.String {
+		return field.String(), nil
+	}
+	if field.Type() == timeType {
+		return field.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	}
+	encoded, err := json.Marshal(field.Interface())
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// Reader reads records of type T from a stream
+type Reader[T any] struct {
+	format  string
+	lines   *bufio.Scanner
+	line    int
+	csv     *csv.Reader
+	columns []column
+}
+
+// NewReader returns a reader of records in format from r. A CSV stream starts with a header
+// naming the columns present, in any order; unknown columns are rejected.
+func NewReader[T any](r io.Reader, format string) (*Reader[T], error) {
+	switch format {
+	case FormatNDJSON:
+		lines := bufio.NewScanner(r)
+		lines.Buffer(make([]byte, 0, 64*1024), MaxRecordSize)
+		return &Reader[T]{format: format, lines: lines}, nil
+	case FormatCSV:
+		known, err := columnsOf(reflect.TypeOf((*T)(nil)).Elem())
+		if err != nil {
+			return nil, err
+		}
+		reader := csv.NewReader(r)
+		reader.ReuseRecord = true
+		header, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, errors.New("missing CSV header")
+			}
+			return nil, fmt.Errorf("invalid CSV header: %w", err)
+		}
+		columns, err := headerColumns(header, known)
+		if err != nil {
+			return nil, err
+		}
+		reader.FieldsPerRecord = len(columns)
+		return &Reader[T]{format: format, csv: reader, columns: columns}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// headerColumns maps the names of a CSV header to the columns of the record type
+func headerColumns(header []string, known []column) ([]column, error) {
+	byName := make(map[string]column, len(known))
+	for _, col := range known {
+		byName[col.name] = col
+	}
+	columns := make([]column, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		col, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown CSV column %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate CSV column %q", name)
+		}
+		seen[name] = true
+		columns[i] = col
+	}
+	return columns, nil
+}
+
+// Next returns the next record and the line it starts on, or io.EOF after the last one. A
+// record that cannot be decoded is reported as a *RecordError; other errors end the stream.
+func (r *Reader[T]) Next() (*T, int, error) {
+	if r.format == FormatNDJSON {
+		return r.nextLine()
+	}
+	return r.nextCSV()
+}
+
+func (r *Reader[T]) nextLine() (*T, int, error) {
+	for r.lines.Scan() {
+		r.line++
+		text := bytes.TrimSpace(r.lines.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		var record T
+		if err := decoder.Decode(&record); err != nil {
+			return nil, r.line, &RecordError{Line: r.line, Err: err}
+		}
+		if decoder.More() {
+			return nil, r.line, &RecordError{Line: r.line, Err: errors.New("more than one JSON value on the line")}
+		}
+		return &record, r.line, nil
+	}
+	if err := r.lines.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, r.line + 1, fmt.Errorf("line %d is longer than %d bytes", r.line+1, MaxRecordSize)
+		}
+		return nil, r.line, err
+	}
+	return nil, r.line, io.EOF
+}
+
+func (r *Reader[T]) nextCSV() (*T, int, error) {
+	cells, err := r.csv.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, parseErr.StartLine, &RecordError{Line: parseErr.StartLine, Err: parseErr.Err}
+		}
+		return nil, 0, err
+	}
+	line, _ := r.csv.FieldPos(0)
+
+	// The cells are assembled into a JSON object, so the record is decoded exactly as the same
+	// record on an NDJSON line would be
+	object := make(map[string]json.RawMessage, len(cells))
+	for i, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		col := r.columns[i]
+		if col.text {
+			encoded, err := json.Marshal(cell)
+			if err != nil {
+				return nil, line, &RecordError{Line: line, Err: err}
+			}
+			object[col.name] = encoded
+			continue
+		}
+		if !json.Valid([]byte(cell)) {
+			return nil, line, &RecordError{Line: line, Err: fmt.Errorf("column %s is not valid JSON", col.name)}
+		}
+		object[col.name] = json.RawMessage(cell)
+	}
+
+	encoded, err := json.Marshal(object)
+	if err != nil {
+		return nil, line, &RecordError{Line: line, Err: err}
+	}
+	var record T
+	if err := json.Unmarshal(encoded, &record); err != nil {
+		return nil, line, &RecordError{Line: line, Err: err}
+	}
+	return &record, line, nil
+}
@@ -0,0 +1,143 @@
+package transfer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	ID       string          `json:"id"`
+	Name     *string         `json:"name,omitempty"`
+	Tags     []string        `json:"tags,omitempty"`
+	Metadata json.RawMessage `json:"metadata,omitempty"`
+	At       *time.Time      `json:"at,omitempty"`
+	Internal int64           `json:"-"`
+}
+
+func readAll(t *testing.T, r io.Reader, format string) ([]*record, []*RecordError) {
+	t.Helper()
+	reader, err := NewReader[record](r, format)
+	require.NoError(t, err)
+
+	var records []*record
+	var recordErrs []*RecordError
+	for {
+		rec, _, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return records, recordErrs
+		}
+		var recordErr *RecordError
+		if errors.As(err, &recordErr) {
+			recordErrs = append(recordErrs, recordErr)
+			continue
+		}
+		require.NoError(t, err)
+		records = append(records, rec)
+	}
+}
+
+func TestWriterAndReader_RoundTrip(t *testing.T) {
+	name := "Spec, v1"
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	written := []*record{
+		{ID: "a", Name: &name, Tags: []string{"x", "y"}, Metadata: json.RawMessage(`{"n":1}`), At: &at, Internal: 7},
+		{ID: "b"},
+	}
+
+	for _, format := range []string{FormatNDJSON, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var out bytes.Buffer
+			writer, err := NewWriter[record](&out, format)
+			require.NoError(t, err)
+			for _, rec := range written {
+				require.NoError(t, writer.Write(rec))
+			}
+			require.NoError(t, writer.Flush())
+
+			read, recordErrs := readAll(t, &out, format)
+			assert.Empty(t, recordErrs)
+			require.Len(t, read, 2)
+			assert.Equal(t, "a", read[0].ID)
+			assert.Equal(t, name, *read[0].Name)
+			assert.Equal(t, []string{"x", "y"}, read[0].Tags)
+			assert.JSONEq(t, `{"n":1}`, string(read[0].Metadata))
+			assert.True(t, at.Equal(*read[0].At))
+			assert.Zero(t, read[0].Internal)
+			assert.Equal(t, &record{ID: "b"}, read[1])
+		})
+	}
+}
+
+func TestWriter_CSVHeader(t *testing.T) {
+	var out bytes.Buffer
+	writer, err := NewWriter[record](&out, FormatCSV)
+	require.NoError(t, err)
+
+	require.NoError(t, writer.Flush())
+
+	assert.Equal(t, "id,name,tags,metadata,at\n", out.String())
+}
+
+func TestReader_NDJSONReportsBadLines(t *testing.T) {
+	stream := "{\"id\":\"a\"}\n\nnot json\n{\"id\":\"b\",\"extra\":1}\n{\"id\":\"c\"} {\"id\":\"d\"}\n{\"id\":\"e\"}\n"
+
+	read, recordErrs := readAll(t, strings.NewReader(stream), FormatNDJSON)
+
+	require.Len(t, read, 2)
+	assert.Equal(t, "a", read[0].ID)
+	assert.Equal(t, "e", read[1].ID)
+	lines := []int{}
+	for _, recordErr := range recordErrs {
+		lines = append(lines, recordErr.Line)
+	}
+	assert.Equal(t, []int{3, 4, 5}, lines)
+}
+
+func TestReader_CSVColumnsInAnyOrder(t *testing.T) {
+	stream := "\ufefftags,id\n\"[\"\"x\"\"]\",a\n,b\nnot json,c\n"
+
+	read, recordErrs := readAll(t, strings.NewReader(stream), FormatCSV)
+
+	require.Len(t, read, 2)
+	assert.Equal(t, []string{"x"}, read[0].Tags)
+	assert.Equal(t, "b", read[1].ID)
+	require.Len(t, recordErrs, 1)
+	assert.Equal(t, 4, recordErrs[0].Line)
+	assert.Contains(t, recordErrs[0].Error(), "column tags is not valid JSON")
+}
+
+func TestReader_CSVRejectsBadHeaders(t *testing.T) {
+	for _, header := range []string{"", "id,unknown\n", "id,id\n"} {
+		_, err := NewReader[record](strings.NewReader(header), FormatCSV)
+		assert.Error(t, err, "header %q", header)
+	}
+}
+
+func TestReader_NDJSONLineTooLong(t *testing.T) {
+	stream := `{"id":"` + strings.Repeat("x", MaxRecordSize) + `"}`
+
+	reader, err := NewReader[record](strings.NewReader(stream), FormatNDJSON)
+	require.NoError(t, err)
+	_, _, err = reader.Next()
+
+	var recordErr *RecordError
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &recordErr))
+}
+
+func TestUnknownFormat(t *testing.T) {
+	_, err := NewWriter[record](io.Discard, "xml")
+	assert.Error(t, err)
+	_, err = NewReader[record](strings.NewReader(""), "xml")
+	assert.Error(t, err)
+	assert.False(t, ValidFormat("xml"))
+	assert.Equal(t, "text/csv; charset=utf-8", ContentType(FormatCSV))
+}
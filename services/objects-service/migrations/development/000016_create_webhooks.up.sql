@@ -0,0 +1,44 @@
+-- Environment: all
+-- Migration: 000016_create_webhooks
+-- Description: Webhook subscriptions to domain events and the log of their deliveries
+
+CREATE TABLE IF NOT EXISTS objects_service.webhook_subscriptions (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    target_url TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    object_type_ids BIGINT[] NOT NULL DEFAULT '{}',
+    relationship_type_keys TEXT[] NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by VARCHAR(255) NOT NULL DEFAULT 'system',
+    updated_by VARCHAR(255) NOT NULL DEFAULT 'system',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS objects_service.webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE,
+    subscription_id BIGINT NOT NULL REFERENCES objects_service.webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_attempt_at TIMESTAMP WITH TIME ZONE,
+    last_status_code INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON objects_service.webhook_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON objects_service.webhook_deliveries(subscription_id, id DESC);
+
+COMMENT ON TABLE objects_service.webhook_subscriptions IS 'Endpoints receiving signed domain events; empty filters match everything';
+COMMENT ON TABLE objects_service.webhook_deliveries IS 'One delivery of an event to a subscription; dead deliveries form the dead-letter list';
+COMMENT ON COLUMN objects_service.webhook_deliveries.next_attempt_at IS 'When the next attempt is due; also leases claimed deliveries to one worker';
@@ -0,0 +1,9 @@
+-- Environment: all
+-- Migration Rollback: 000018_add_move_history_action
+-- Description: Record moves as plain updates again
+
+UPDATE objects_service.object_history SET action = 'update' WHERE action = 'move';
+
+ALTER TABLE objects_service.object_history DROP CONSTRAINT IF EXISTS object_history_action_check;
+ALTER TABLE objects_service.object_history ADD CONSTRAINT object_history_action_check
+    CHECK (action IN ('baseline', 'create', 'update', 'delete', 'restore'));
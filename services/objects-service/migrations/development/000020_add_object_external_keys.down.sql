@@ -0,0 +1,6 @@
+-- Environment: all
+-- Migration Rollback: 000020_add_object_external_keys
+-- Description: Drop external keys of objects
+
+DROP INDEX IF EXISTS objects_service.idx_objects_external_key;
+ALTER TABLE objects_service.objects DROP COLUMN IF EXISTS external_key;
@@ -0,0 +1,3 @@
+// Package migrations holds the objects-service SQL migrations, one directory per environment
+// as listed in environments.json.
+package migrations
@@ -0,0 +1,75 @@
+package migrations
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	createdObjectPattern    = regexp.MustCompile(`(?i)CREATE\s+(?:OR\s+REPLACE\s+)?(?:TABLE|FUNCTION)\s+(?:IF\s+NOT\s+EXISTS\s+)?objects_service\.(\w+)`)
+	referencedObjectPattern = regexp.MustCompile(`objects_service\.(\w+)`)
+)
+
+// migrationSets returns the migration directory of every environment in environments.json
+func migrationSets(t *testing.T) map[string]string {
+	t.Helper()
+	data, err := os.ReadFile("environments.json")
+	require.NoError(t, err)
+
+	var config struct {
+		Environments map[string]struct {
+			Migrations string `json:"migrations"`
+		} `json:"environments"`
+	}
+	require.NoError(t, json.Unmarshal(data, &config))
+	require.NotEmpty(t, config.Environments)
+
+	sets := make(map[string]string, len(config.Environments))
+	for env, settings := range config.Environments {
+		sets[env] = settings.Migrations
+	}
+	return sets
+}
+
+// TestMigrations_ReferenceOnlyExistingObjects applies the up migrations of each environment in
+// order and fails on any schema object used before a migration of the same set creates it.
+// Migrations shared between sets may use an object that only some sets have when they guard
+// it with to_regclass.
+func TestMigrations_ReferenceOnlyExistingObjects(t *testing.T) {
+	for env, dir := range migrationSets(t) {
+		t.Run(env, func(t *testing.T) {
+			files, err := filepath.Glob(filepath.Join(dir, "*.up.sql"))
+			require.NoError(t, err)
+			require.NotEmpty(t, files)
+			sort.Strings(files)
+
+			created := map[string]bool{}
+			for _, file := range files {
+				data, err := os.ReadFile(file)
+				require.NoError(t, err)
+				sql := string(data)
+
+				for _, match := range createdObjectPattern.FindAllStringSubmatch(sql, -1) {
+					created[match[1]] = true
+				}
+				for _, match := range referencedObjectPattern.FindAllStringSubmatch(sql, -1) {
+					name := match[1]
+					if created[name] || strings.Contains(sql, "to_regclass('objects_service."+name+"')") {
+						continue
+					}
+					assert.Failf(t, "migration uses a missing object",
+						"%s uses objects_service.%s, which no earlier %s migration creates", filepath.Base(file), name, env)
+					created[name] = true // report each missing object once
+				}
+			}
+		})
+	}
+}
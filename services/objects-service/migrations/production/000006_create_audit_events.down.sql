@@ -0,0 +1,6 @@
+-- Environment: all
+-- Migration Rollback: 000006_create_audit_events
+-- Description: Remove audit trail table from objects_service
+
+DROP TABLE IF EXISTS objects_service.audit_events CASCADE;
+DROP FUNCTION IF EXISTS objects_service.audit_events_append_only();
@@ -0,0 +1,9 @@
+-- Environment: all
+-- Migration: 000007_add_object_type_metadata_schema
+-- Description: Optional JSON Schema validating the metadata of an object type's objects
+
+ALTER TABLE objects_service.object_types
+ADD COLUMN IF NOT EXISTS metadata_schema JSONB;
+
+COMMENT ON COLUMN objects_service.object_types.metadata_schema IS
+    'JSON Schema for objects.metadata, merged with the schemas of parent types';
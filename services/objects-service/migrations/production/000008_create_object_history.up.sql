@@ -0,0 +1,42 @@
+-- Environment: all
+-- Migration: 000008_create_object_history
+-- Description: Version history of objects and relationships for point-in-time reads and restores
+
+CREATE TABLE IF NOT EXISTS objects_service.object_history (
+    id BIGSERIAL PRIMARY KEY,
+    entity_type VARCHAR(20) NOT NULL CHECK (entity_type IN ('object', 'relationship')),
+    object_id BIGINT NOT NULL,
+    revision BIGINT NOT NULL,
+    object_version BIGINT,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('baseline', 'create', 'update', 'delete', 'restore')),
+    snapshot JSONB NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    updated_by VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255),
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (entity_type, object_id, revision)
+);
+
+-- object_id has no foreign key: history outlives hard-deleted relationships
+CREATE INDEX IF NOT EXISTS idx_object_history_recorded_at ON objects_service.object_history(entity_type, object_id, recorded_at);
+
+-- Existing rows start their history with a baseline of their current state
+INSERT INTO objects_service.object_history (entity_type, object_id, revision, object_version, action, snapshot, updated_by, recorded_at)
+SELECT 'object', o.id, 1, o.version, 'baseline', to_jsonb(o), COALESCE(o.updated_by, 'system'), COALESCE(o.updated_at, o.created_at, CURRENT_TIMESTAMP)
+FROM objects_service.objects o
+ON CONFLICT (entity_type, object_id, revision) DO NOTHING;
+
+-- Production has no relationships table, so relationships get a baseline only where it exists
+DO $$
+BEGIN
+    IF to_regclass('objects_service.objects_relationships') IS NOT NULL THEN
+        INSERT INTO objects_service.object_history (entity_type, object_id, revision, action, snapshot, updated_by, recorded_at)
+        SELECT 'relationship', r.object_id, 1, 'baseline', to_jsonb(r), COALESCE(r.updated_by, 'system'), COALESCE(r.updated_at, r.created_at, CURRENT_TIMESTAMP)
+        FROM objects_service.objects_relationships r
+        ON CONFLICT (entity_type, object_id, revision) DO NOTHING;
+    END IF;
+END $$;
+
+COMMENT ON TABLE objects_service.object_history IS 'Full snapshot of every recorded state of an object or relationship';
+COMMENT ON COLUMN objects_service.object_history.revision IS 'Per-entity sequence number, starting at 1';
+COMMENT ON COLUMN objects_service.object_history.changes IS 'Fields changed since the previous revision as {"field": {"old": ..., "new": ...}}';
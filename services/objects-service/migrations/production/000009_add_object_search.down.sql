@@ -0,0 +1,27 @@
+-- Environment: all
+-- Migration Rollback: 000009_add_object_search
+-- Description: Remove full-text search column, triggers and indexes from objects
+
+DROP INDEX IF EXISTS objects_service.idx_objects_name_trgm;
+DROP INDEX IF EXISTS objects_service.idx_objects_search_vector;
+
+DROP TRIGGER IF EXISTS update_objects_updated_at ON objects_service.objects;
+CREATE TRIGGER update_objects_updated_at
+    BEFORE UPDATE ON objects_service.objects
+    FOR EACH ROW
+    EXECUTE FUNCTION objects_service.update_updated_at_column();
+
+DROP TRIGGER IF EXISTS reindex_object_type_search ON objects_service.object_types;
+DROP TRIGGER IF EXISTS update_objects_search_vector ON objects_service.objects;
+DROP FUNCTION IF EXISTS objects_service.reindex_object_type_search();
+DROP FUNCTION IF EXISTS objects_service.update_object_search_vector();
+DROP FUNCTION IF EXISTS objects_service.object_search_document(TEXT, TEXT, TEXT[], JSONB, BIGINT);
+DROP FUNCTION IF EXISTS objects_service.object_search_metadata_text(JSONB, BIGINT);
+
+ALTER TABLE objects_service.objects
+DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE objects_service.object_types
+DROP COLUMN IF EXISTS search_metadata_keys;
+
+-- pg_trgm is left installed; other schemas may depend on it
@@ -0,0 +1,30 @@
+-- Environment: all
+-- Migration: 000011_create_outbox_events
+-- Description: Transactional outbox of domain events for objects, object types and relationships
+
+CREATE TABLE IF NOT EXISTS objects_service.outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL DEFAULT gen_random_uuid() UNIQUE,
+    event_type VARCHAR(100) NOT NULL,
+    entity_type VARCHAR(30) NOT NULL CHECK (entity_type IN ('object', 'object_type', 'relationship')),
+    entity_id BIGINT NOT NULL,
+    entity_public_id UUID,
+    before JSONB,
+    after JSONB,
+    actor VARCHAR(255) NOT NULL,
+    trace_id VARCHAR(64),
+    request_id VARCHAR(255),
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    transaction_id XID8 NOT NULL DEFAULT pg_current_xact_id(),
+    dispatched_at TIMESTAMP WITH TIME ZONE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON objects_service.outbox_events(id) WHERE dispatched_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_type ON objects_service.outbox_events(event_type, id);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_entity ON objects_service.outbox_events(entity_type, entity_id);
+
+COMMENT ON TABLE objects_service.outbox_events IS 'Domain events written in the transaction of the change they describe';
+COMMENT ON COLUMN objects_service.outbox_events.id IS 'Offset of the event in the log';
+COMMENT ON COLUMN objects_service.outbox_events.transaction_id IS 'Writing transaction; events are only read once every older transaction has ended';
@@ -0,0 +1,8 @@
+-- Environment: all
+-- Migration Rollback: 000012_create_webhooks
+-- Description: Remove webhook subscriptions and their deliveries
+
+DROP INDEX IF EXISTS objects_service.idx_webhook_deliveries_subscription;
+DROP INDEX IF EXISTS objects_service.idx_webhook_deliveries_due;
+DROP TABLE IF EXISTS objects_service.webhook_deliveries;
+DROP TABLE IF EXISTS objects_service.webhook_subscriptions;
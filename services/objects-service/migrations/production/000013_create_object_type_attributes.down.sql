@@ -0,0 +1,5 @@
+-- Environment: all
+-- Migration Rollback: 000013_create_object_type_attributes
+-- Description: Remove attribute declarations; provisioned concrete tables and their data are kept
+
+DROP TABLE IF EXISTS objects_service.object_type_attributes;
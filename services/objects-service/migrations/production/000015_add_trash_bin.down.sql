@@ -0,0 +1,9 @@
+-- Environment: all
+-- Migration Rollback: 000015_add_trash_bin
+-- Description: Record purges as plain deletes again; relationships sent to the trash stay deleted
+
+UPDATE objects_service.object_history SET action = 'delete' WHERE action = 'purge';
+
+ALTER TABLE objects_service.object_history DROP CONSTRAINT IF EXISTS object_history_action_check;
+ALTER TABLE objects_service.object_history ADD CONSTRAINT object_history_action_check
+    CHECK (action IN ('baseline', 'create', 'update', 'delete', 'restore', 'move'));
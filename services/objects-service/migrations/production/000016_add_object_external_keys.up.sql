@@ -0,0 +1,11 @@
+-- Environment: all
+-- Migration: 000016_add_object_external_keys
+-- Description: Key objects by the identifier of the system they were imported from, so that
+-- imports can match existing objects and resolve parents and relationship ends by it
+
+ALTER TABLE objects_service.objects ADD COLUMN IF NOT EXISTS external_key VARCHAR(255);
+
+-- A key names one live object; deleted objects give theirs up
+CREATE UNIQUE INDEX IF NOT EXISTS idx_objects_external_key
+    ON objects_service.objects(external_key)
+    WHERE external_key IS NOT NULL AND deleted_at IS NULL;
@@ -0,0 +1,6 @@
+-- Environment: all
+-- Migration Rollback: 000011_add_object_type_metadata_schema
+-- Description: Remove metadata schema column from object_types
+
+ALTER TABLE objects_service.object_types
+DROP COLUMN IF EXISTS metadata_schema;
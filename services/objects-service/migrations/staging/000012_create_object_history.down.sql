@@ -0,0 +1,5 @@
+-- Environment: all
+-- Migration Rollback: 000012_create_object_history
+-- Description: Remove object version history
+
+DROP TABLE IF EXISTS objects_service.object_history CASCADE;
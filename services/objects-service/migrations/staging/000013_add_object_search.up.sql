@@ -0,0 +1,103 @@
+-- Environment: all
+-- Migration: 000013_add_object_search
+-- Description: Ranked full-text search over objects with a trigram fallback for misspelled queries
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Metadata keys whose values each type contributes to the search document; subtypes inherit them
+ALTER TABLE objects_service.object_types
+ADD COLUMN IF NOT EXISTS search_metadata_keys TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN objects_service.object_types.search_metadata_keys IS
+    'Metadata keys indexed for full-text search, in addition to those of parent types';
+
+ALTER TABLE objects_service.objects
+ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- Values of the indexed metadata keys of an object, following the type chain up to the root
+CREATE OR REPLACE FUNCTION objects_service.object_search_metadata_text(p_metadata JSONB, p_object_type_id BIGINT)
+RETURNS TEXT AS $$
+    WITH RECURSIVE type_chain AS (
+        SELECT id, parent_type_id, search_metadata_keys
+        FROM objects_service.object_types
+        WHERE id = p_object_type_id
+
+        UNION
+
+        SELECT t.id, t.parent_type_id, t.search_metadata_keys
+        FROM objects_service.object_types t
+        INNER JOIN type_chain c ON t.id = c.parent_type_id
+    )
+    SELECT COALESCE(string_agg(DISTINCT p_metadata ->> k.key, ' '), '')
+    FROM type_chain, unnest(type_chain.search_metadata_keys) AS k(key)
+    WHERE p_metadata ? k.key
+$$ LANGUAGE sql STABLE;
+
+-- Search document of an object: name ranks above tags, tags above description, description above metadata
+CREATE OR REPLACE FUNCTION objects_service.object_search_document(
+    p_name TEXT, p_description TEXT, p_tags TEXT[], p_metadata JSONB, p_object_type_id BIGINT
+)
+RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('english', COALESCE(p_name, '')), 'A')
+        || setweight(to_tsvector('english', COALESCE(array_to_string(p_tags, ' '), '')), 'B')
+        || setweight(to_tsvector('english', COALESCE(p_description, '')), 'C')
+        || setweight(to_tsvector('english', objects_service.object_search_metadata_text(p_metadata, p_object_type_id)), 'D')
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION objects_service.update_object_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector = objects_service.object_search_document(
+        NEW.name, NEW.description, NEW.tags, NEW.metadata, NEW.object_type_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER update_objects_search_vector
+    BEFORE INSERT OR UPDATE OF name, description, tags, metadata, object_type_id ON objects_service.objects
+    FOR EACH ROW
+    EXECUTE FUNCTION objects_service.update_object_search_vector();
+
+-- Changing the indexed keys or the parent of a type re-indexes the objects of the type and its subtypes
+CREATE OR REPLACE FUNCTION objects_service.reindex_object_type_search()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE objects_service.objects o
+    SET search_vector = objects_service.object_search_document(
+        o.name, o.description, o.tags, o.metadata, o.object_type_id)
+    WHERE o.object_type_id IN (
+        WITH RECURSIVE subtree AS (
+            SELECT NEW.id AS id
+
+            UNION
+
+            SELECT t.id
+            FROM objects_service.object_types t
+            INNER JOIN subtree s ON t.parent_type_id = s.id
+        )
+        SELECT id FROM subtree
+    );
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER reindex_object_type_search
+    AFTER UPDATE OF search_metadata_keys, parent_type_id ON objects_service.object_types
+    FOR EACH ROW
+    WHEN (OLD.search_metadata_keys IS DISTINCT FROM NEW.search_metadata_keys
+          OR OLD.parent_type_id IS DISTINCT FROM NEW.parent_type_id)
+    EXECUTE FUNCTION objects_service.reindex_object_type_search();
+
+-- Re-indexing is not an edit: keep updated_at (and with it the ETag) when only the search vector changes
+DROP TRIGGER IF EXISTS update_objects_updated_at ON objects_service.objects;
+CREATE TRIGGER update_objects_updated_at
+    BEFORE UPDATE ON objects_service.objects
+    FOR EACH ROW
+    WHEN ((to_jsonb(OLD) - 'search_vector') IS DISTINCT FROM (to_jsonb(NEW) - 'search_vector'))
+    EXECUTE FUNCTION objects_service.update_updated_at_column();
+
+UPDATE objects_service.objects
+SET search_vector = objects_service.object_search_document(name, description, tags, metadata, object_type_id);
+
+CREATE INDEX IF NOT EXISTS idx_objects_search_vector ON objects_service.objects USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_objects_name_trgm ON objects_service.objects USING GIN (name gin_trgm_ops);
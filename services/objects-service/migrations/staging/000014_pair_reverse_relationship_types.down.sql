@@ -0,0 +1,22 @@
+-- Environment: all
+-- Migration Rollback: 000014_pair_reverse_relationship_types
+-- Description: Remove the reverse types created for reverse_type_key
+-- Pairings of types that already existed are kept
+
+-- The created reverse types are the newer type of their pair and carry a 'Relationship: ' base object
+DELETE FROM objects_service.objects_relationship_types r
+USING objects_service.objects o, objects_service.objects_relationship_types rt
+WHERE o.id = r.object_id
+AND o.name = 'Relationship: ' || r.type_key
+AND rt.type_key = r.reverse_type_key
+AND rt.reverse_type_key = r.type_key
+AND rt.object_id < r.object_id;
+
+DELETE FROM objects_service.objects o
+USING objects_service.object_types ot
+WHERE o.object_type_id = ot.id
+AND ot.name = 'RelationshipType'
+AND o.name LIKE 'Relationship: %'
+AND NOT EXISTS (
+    SELECT 1 FROM objects_service.objects_relationship_types r WHERE r.object_id = o.id
+);
@@ -0,0 +1,68 @@
+-- Environment: all
+-- Migration: 000014_pair_reverse_relationship_types
+-- Description: Create the reverse types named by reverse_type_key and pair both types of each reverse
+
+-- Step 1: Base objects for reverse types that do not exist yet
+INSERT INTO objects_service.objects (public_id, object_type_id, name, status, created_at, updated_at)
+SELECT DISTINCT ON (rt.reverse_type_key)
+    gen_random_uuid(),
+    (SELECT id FROM objects_service.object_types WHERE name = 'RelationshipType'),
+    'Relationship: ' || rt.reverse_type_key,
+    'active',
+    NOW(),
+    NOW()
+FROM objects_service.objects_relationship_types rt
+WHERE rt.reverse_type_key IS NOT NULL
+AND rt.reverse_type_key <> ''
+AND rt.reverse_type_key <> rt.type_key
+AND NOT EXISTS (
+    SELECT 1 FROM objects_service.objects_relationship_types r
+    WHERE r.type_key = rt.reverse_type_key
+)
+AND NOT EXISTS (
+    SELECT 1 FROM objects_service.objects o
+    JOIN objects_service.object_types ot ON o.object_type_id = ot.id
+    WHERE ot.name = 'RelationshipType'
+    AND o.name = 'Relationship: ' || rt.reverse_type_key
+)
+ORDER BY rt.reverse_type_key, rt.object_id;
+
+-- Step 2: Reverse types with the mirrored cardinality, naming their type back
+INSERT INTO objects_service.objects_relationship_types (
+    object_id, type_key, relationship_name, reverse_type_key,
+    cardinality, required, min_count, max_count, validation_rules,
+    created_at, updated_at
+)
+SELECT DISTINCT ON (rt.reverse_type_key)
+    o.id,
+    rt.reverse_type_key,
+    rt.reverse_type_key,
+    rt.type_key,
+    CASE rt.cardinality
+        WHEN 'one_to_many' THEN 'many_to_one'
+        WHEN 'many_to_one' THEN 'one_to_many'
+        ELSE rt.cardinality
+    END,
+    false,
+    0,
+    -1,
+    '{}',
+    NOW(),
+    NOW()
+FROM objects_service.objects_relationship_types rt
+JOIN objects_service.objects o ON o.name = 'Relationship: ' || rt.reverse_type_key
+JOIN objects_service.object_types ot ON o.object_type_id = ot.id
+WHERE ot.name = 'RelationshipType'
+AND rt.reverse_type_key IS NOT NULL
+AND rt.reverse_type_key <> ''
+AND rt.reverse_type_key <> rt.type_key
+ORDER BY rt.reverse_type_key, rt.object_id
+ON CONFLICT (type_key) DO NOTHING;
+
+-- Step 3: Existing types named as a reverse but not naming their type back
+UPDATE objects_service.objects_relationship_types r
+SET reverse_type_key = rt.type_key, updated_at = NOW()
+FROM objects_service.objects_relationship_types rt
+WHERE r.type_key = rt.reverse_type_key
+AND r.type_key <> rt.type_key
+AND (r.reverse_type_key IS NULL OR r.reverse_type_key = '');
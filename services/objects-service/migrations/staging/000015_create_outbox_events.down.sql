@@ -0,0 +1,8 @@
+-- Environment: all
+-- Migration Rollback: 000015_create_outbox_events
+-- Description: Remove the outbox of domain events
+
+DROP INDEX IF EXISTS objects_service.idx_outbox_events_entity;
+DROP INDEX IF EXISTS objects_service.idx_outbox_events_type;
+DROP INDEX IF EXISTS objects_service.idx_outbox_events_pending;
+DROP TABLE IF EXISTS objects_service.outbox_events;
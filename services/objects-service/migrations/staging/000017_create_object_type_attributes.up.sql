@@ -0,0 +1,18 @@
+-- Environment: all
+-- Migration: 000017_create_object_type_attributes
+-- Description: Typed attributes declared by object types, stored in provisioned concrete tables
+
+CREATE TABLE IF NOT EXISTS objects_service.object_type_attributes (
+    id BIGSERIAL PRIMARY KEY,
+    object_type_id BIGINT NOT NULL REFERENCES objects_service.object_types(id) ON DELETE CASCADE,
+    name VARCHAR(63) NOT NULL,
+    data_type VARCHAR(20) NOT NULL CHECK (data_type IN ('text', 'integer', 'bigint', 'numeric', 'double', 'boolean', 'date', 'timestamp', 'uuid', 'jsonb')),
+    nullable BOOLEAN NOT NULL DEFAULT true,
+    indexed BOOLEAN NOT NULL DEFAULT false,
+    position INTEGER NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT 'system',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (object_type_id, name)
+);
+
+COMMENT ON TABLE objects_service.object_type_attributes IS 'Typed attributes of object types; each is a column of the concrete table named by object_types.concrete_table_name';
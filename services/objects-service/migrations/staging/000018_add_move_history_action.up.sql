@@ -0,0 +1,7 @@
+-- Environment: all
+-- Migration: 000018_add_move_history_action
+-- Description: Record moves of objects in the version history under their own action
+
+ALTER TABLE objects_service.object_history DROP CONSTRAINT IF EXISTS object_history_action_check;
+ALTER TABLE objects_service.object_history ADD CONSTRAINT object_history_action_check
+    CHECK (action IN ('baseline', 'create', 'update', 'delete', 'restore', 'move'));
@@ -0,0 +1,26 @@
+-- Environment: all
+-- Migration: 000019_add_trash_bin
+-- Description: Record purges from the trash in the version history, and send relationships of
+-- deleted objects to the trash with them
+
+ALTER TABLE objects_service.object_history DROP CONSTRAINT IF EXISTS object_history_action_check;
+ALTER TABLE objects_service.object_history ADD CONSTRAINT object_history_action_check
+    CHECK (action IN ('baseline', 'create', 'update', 'delete', 'restore', 'move', 'purge'));
+
+-- Relationships of objects deleted so far go to the trash at the time their end was deleted.
+-- Production has no relationships table, so there is nothing to send there.
+DO $$
+BEGIN
+    IF to_regclass('objects_service.objects_relationships') IS NOT NULL THEN
+        UPDATE objects_service.objects o
+        SET deleted_at = ends.deleted_at
+        FROM (
+            SELECT r.object_id, GREATEST(s.deleted_at, t.deleted_at) AS deleted_at
+            FROM objects_service.objects_relationships r
+            JOIN objects_service.objects s ON s.id = r.source_object_id
+            JOIN objects_service.objects t ON t.id = r.target_object_id
+            WHERE s.deleted_at IS NOT NULL OR t.deleted_at IS NOT NULL
+        ) ends
+        WHERE o.id = ends.object_id AND o.deleted_at IS NULL;
+    END IF;
+END $$;
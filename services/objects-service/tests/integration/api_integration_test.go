@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -124,11 +125,27 @@ func (m *MockObjectTypeService) ValidateMove(ctx context.Context, typeID int64,
 	return args.Error(0)
 }
 
+func (m *MockObjectTypeService) Move(ctx context.Context, id int64, req *models.MoveObjectTypeRequest) (*models.ObjectType, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ObjectType), args.Error(1)
+}
+
 func (m *MockObjectTypeService) GetSubtreeObjectCount(ctx context.Context, typeID int64) (int64, error) {
 	args := m.Called(ctx, typeID)
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockObjectTypeService) GetEffectiveMetadataSchema(ctx context.Context, id int64) (json.RawMessage, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(json.RawMessage), args.Error(1)
+}
+
 type MockObjectService struct {
 	mock.Mock
 }
@@ -186,12 +203,12 @@ func (m *MockObjectService) List(ctx context.Context, filter *models.ObjectFilte
 	return args.Get(0).([]*models.Object), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockObjectService) Search(ctx context.Context, query string, limit int) ([]*models.Object, error) {
-	args := m.Called(ctx, query, limit)
+func (m *MockObjectService) Search(ctx context.Context, filter *models.ObjectSearchFilter) (*models.ObjectSearchResults, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*models.Object), args.Error(1)
+	return args.Get(0).(*models.ObjectSearchResults), args.Error(1)
 }
 
 func (m *MockObjectService) FindByMetadata(ctx context.Context, key, value string) ([]*models.Object, error) {
@@ -265,12 +282,12 @@ func (m *MockObjectService) BulkCreate(ctx context.Context, objects []*models.Cr
 	return args.Get(0).([]*models.Object), args.Error(1)
 }
 
-func (m *MockObjectService) BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest) ([]*models.Object, error) {
-	args := m.Called(ctx, ids, updates)
+func (m *MockObjectService) BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest, expectedVersions map[int64]int64) (*models.BulkUpdateResult, error) {
+	args := m.Called(ctx, ids, updates, expectedVersions)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*models.Object), args.Error(1)
+	return args.Get(0).(*models.BulkUpdateResult), args.Error(1)
 }
 
 func (m *MockObjectService) BulkDelete(ctx context.Context, ids []int64) error {
@@ -291,6 +308,70 @@ func (m *MockObjectService) GetObjectStats(ctx context.Context, filter *models.O
 	return args.Get(0).(*repository.ObjectStats), args.Error(1)
 }
 
+func (m *MockObjectService) Aggregate(ctx context.Context, req *models.ObjectAggregationRequest) (*models.ObjectAggregationResult, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ObjectAggregationResult), args.Error(1)
+}
+
+func (m *MockObjectService) ValidateExistingMetadata(ctx context.Context, objectTypeID int64, candidate json.RawMessage) (*models.MetadataValidationReport, error) {
+	args := m.Called(ctx, objectTypeID, candidate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MetadataValidationReport), args.Error(1)
+}
+
+func (m *MockObjectService) ListVersions(ctx context.Context, id int64, filter *models.ObjectVersionFilter) ([]*models.ObjectVersion, int64, error) {
+	args := m.Called(ctx, id, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*models.ObjectVersion), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockObjectService) GetVersion(ctx context.Context, entityType string, id, revision int64) (*models.ObjectVersion, error) {
+	args := m.Called(ctx, entityType, id, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ObjectVersion), args.Error(1)
+}
+
+func (m *MockObjectService) DiffVersions(ctx context.Context, entityType string, id, fromRevision, toRevision int64) (*models.ObjectVersionDiff, error) {
+	args := m.Called(ctx, entityType, id, fromRevision, toRevision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ObjectVersionDiff), args.Error(1)
+}
+
+func (m *MockObjectService) GetAsOf(ctx context.Context, id int64, at time.Time) (*models.Object, error) {
+	args := m.Called(ctx, id, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Object), args.Error(1)
+}
+
+func (m *MockObjectService) RestoreVersion(ctx context.Context, id, revision int64, updatedBy string) (*models.Object, error) {
+	args := m.Called(ctx, id, revision, updatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Object), args.Error(1)
+}
+
+func (m *MockObjectService) Move(ctx context.Context, id int64, req *models.MoveObjectRequest) (*models.MoveObjectResult, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MoveObjectResult), args.Error(1)
+}
+
 func createTestRouter() *gin.Engine {
 	router := gin.New()
 	return router
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -446,9 +447,9 @@ func (m *MockObjectServiceForOwnership) List(ctx context.Context, filter *models
 	return args.Get(0).([]*models.Object), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockObjectServiceForOwnership) Search(ctx context.Context, query string, limit int) ([]*models.Object, error) {
-	args := m.Called(ctx, query, limit)
-	return args.Get(0).([]*models.Object), args.Error(1)
+func (m *MockObjectServiceForOwnership) Search(ctx context.Context, filter *models.ObjectSearchFilter) (*models.ObjectSearchResults, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(*models.ObjectSearchResults), args.Error(1)
 }
 
 func (m *MockObjectServiceForOwnership) FindByMetadata(ctx context.Context, key, value string) ([]*models.Object, error) {
@@ -501,9 +502,9 @@ func (m *MockObjectServiceForOwnership) BulkCreate(ctx context.Context, objects
 	return args.Get(0).([]*models.Object), args.Error(1)
 }
 
-func (m *MockObjectServiceForOwnership) BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest) ([]*models.Object, error) {
-	args := m.Called(ctx, ids, updates)
-	return args.Get(0).([]*models.Object), args.Error(1)
+func (m *MockObjectServiceForOwnership) BulkUpdate(ctx context.Context, ids []int64, updates *models.UpdateObjectRequest, expectedVersions map[int64]int64) (*models.BulkUpdateResult, error) {
+	args := m.Called(ctx, ids, updates, expectedVersions)
+	return args.Get(0).(*models.BulkUpdateResult), args.Error(1)
 }
 
 func (m *MockObjectServiceForOwnership) BulkDelete(ctx context.Context, ids []int64) error {
@@ -520,3 +521,67 @@ func (m *MockObjectServiceForOwnership) GetObjectStats(ctx context.Context, filt
 	args := m.Called(ctx, filter)
 	return args.Get(0).(*repository.ObjectStats), args.Error(1)
 }
+
+func (m *MockObjectServiceForOwnership) Aggregate(ctx context.Context, req *models.ObjectAggregationRequest) (*models.ObjectAggregationResult, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ObjectAggregationResult), args.Error(1)
+}
+
+func (m *MockObjectServiceForOwnership) ValidateExistingMetadata(ctx context.Context, objectTypeID int64, candidate json.RawMessage) (*models.MetadataValidationReport, error) {
+	args := m.Called(ctx, objectTypeID, candidate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MetadataValidationReport), args.Error(1)
+}
+
+func (m *MockObjectServiceForOwnership) ListVersions(ctx context.Context, id int64, filter *models.ObjectVersionFilter) ([]*models.ObjectVersion, int64, error) {
+	args := m.Called(ctx, id, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*models.ObjectVersion), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockObjectServiceForOwnership) GetVersion(ctx context.Context, entityType string, id, revision int64) (*models.ObjectVersion, error) {
+	args := m.Called(ctx, entityType, id, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ObjectVersion), args.Error(1)
+}
+
+func (m *MockObjectServiceForOwnership) DiffVersions(ctx context.Context, entityType string, id, fromRevision, toRevision int64) (*models.ObjectVersionDiff, error) {
+	args := m.Called(ctx, entityType, id, fromRevision, toRevision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ObjectVersionDiff), args.Error(1)
+}
+
+func (m *MockObjectServiceForOwnership) GetAsOf(ctx context.Context, id int64, at time.Time) (*models.Object, error) {
+	args := m.Called(ctx, id, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Object), args.Error(1)
+}
+
+func (m *MockObjectServiceForOwnership) RestoreVersion(ctx context.Context, id, revision int64, updatedBy string) (*models.Object, error) {
+	args := m.Called(ctx, id, revision, updatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Object), args.Error(1)
+}
+
+func (m *MockObjectServiceForOwnership) Move(ctx context.Context, id int64, req *models.MoveObjectRequest) (*models.MoveObjectResult, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.MoveObjectResult), args.Error(1)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/v-egorov/service-boilerplate/common/logging"
 	"github.com/v-egorov/service-boilerplate/common/middleware"
 	"github.com/v-egorov/service-boilerplate/common/tracing"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/client"
 	"github.com/v-egorov/service-boilerplate/services/user-service/internal/handlers"
 	"github.com/v-egorov/service-boilerplate/services/user-service/internal/repository"
 	"github.com/v-egorov/service-boilerplate/services/user-service/internal/services"
@@ -75,7 +76,10 @@ func main() {
 
 	// Initialize repository and service only if database is available
 	var userHandler *handlers.UserHandler
+	var organizationHandler *handlers.OrganizationHandler
 	var healthHandler *handlers.HealthHandler
+	var auditSink *logging.BufferedAuditSink
+	var auditQueryHandler *logging.AuditQueryHandler
 
 	if db != nil {
 		// Initialize repository
@@ -83,10 +87,59 @@ func main() {
 
 		// Initialize service
 		userService := services.NewUserService(userRepo, logger.Logger)
+		userService.SetRestoreWindow(time.Duration(cfg.UserLifecycle.RestoreWindowDays) * 24 * time.Hour)
+
+		// Erasure cascades token, session and role cleanup to auth-service;
+		// the listing's role filter resolves role membership there too,
+		// and bulk import assigns roles through it
+		authClient := client.NewAuthClient(client.AuthClientConfig{
+			BaseURL: cfg.AuthService.URL,
+			Timeout: time.Duration(cfg.AuthService.Timeout) * time.Second,
+		}, logger.Logger)
+		userService.SetUserDataPurger(authClient)
+		userService.SetUserRoleLookup(authClient)
+		userService.SetUserRoleAssigner(authClient)
+
+		// Email change verification tokens are mailed over SMTP; only development
+		// may run without a mail transport, where they are dropped undelivered
+		if cfg.Email.SMTPHost != "" {
+			userService.SetEmailVerificationSender(client.NewSMTPSender(client.SMTPSenderConfig{
+				Host:     cfg.Email.SMTPHost,
+				Port:     cfg.Email.SMTPPort,
+				Username: cfg.Email.SMTPUsername,
+				Password: cfg.Email.SMTPPassword,
+				From:     cfg.Email.From,
+				Timeout:  time.Duration(cfg.Email.Timeout) * time.Second,
+			}, logger.Logger))
+		} else if cfg.App.Environment != "development" {
+			logger.Fatal("No email transport configured: set EMAIL_SMTP_HOST outside development")
+		}
 
 		// Initialize handlers
 		userHandler = handlers.NewUserHandler(userService, logger.Logger)
 		healthHandler = handlers.NewHealthHandler(db.GetPool(), logger.Logger, cfg)
+
+		// Organizations, teams and memberships
+		orgRepo := repository.NewOrganizationRepository(db.GetPool(), logger.Logger)
+		orgService := services.NewOrganizationService(orgRepo, userRepo, logger.Logger)
+		organizationHandler = handlers.NewOrganizationHandler(orgService, logger.Logger)
+
+		// Persist audit events to the append-only audit trail
+		if cfg.Audit.Enabled {
+			auditStore, err := logging.NewPostgresAuditStore(db.GetPool(), "user_service.audit_events")
+			if err != nil {
+				logger.Fatal("Failed to initialize audit store", err)
+			}
+			auditSink = logging.NewBufferedAuditSink(auditStore, logging.BufferedAuditSinkConfig{
+				BufferSize:    cfg.Audit.BufferSize,
+				BatchSize:     cfg.Audit.BatchSize,
+				FlushInterval: time.Duration(cfg.Audit.FlushIntervalMs) * time.Millisecond,
+			}, logger.Logger)
+			userHandler.SetAuditSink(auditSink)
+			organizationHandler.SetAuditSink(auditSink)
+			auditQueryHandler = logging.NewAuditQueryHandler(auditStore, logger.Logger)
+			logger.Info("Persistent audit trail enabled")
+		}
 	} else {
 		// Initialize handlers without database
 		healthHandler = handlers.NewHealthHandler(nil, logger.Logger, cfg)
@@ -165,6 +218,16 @@ func main() {
 		if userHandler != nil {
 			users := v1.Group("/users")
 			{
+				// Audit trail (admin only); static segments take precedence over /:id
+				if auditQueryHandler != nil {
+					users.GET("/audit-events", middleware.RequireAuth(), middleware.RequireRole("admin"), auditQueryHandler.ListEvents)
+					users.GET("/audit-events/verify", middleware.RequireAuth(), middleware.RequireRole("admin"), auditQueryHandler.VerifyChain)
+				}
+
+				// Bulk import and streaming export (admin only)
+				users.POST("/import", middleware.RequireAuth(), middleware.RequireRole("admin"), userHandler.ImportUsers)
+				users.GET("/export", middleware.RequireAuth(), middleware.RequireRole("admin"), userHandler.ExportUsers)
+
 				users.POST("", userHandler.CreateUser)
 				users.GET("/:id", userHandler.GetUser)
 				users.GET("/by-email/:email", userHandler.GetUserByEmail)
@@ -173,6 +236,45 @@ func main() {
 				users.PATCH("/:id", userHandler.UpdateUser) // Partial resource update
 				users.DELETE("/:id", userHandler.DeleteUser)
 				users.GET("", userHandler.ListUsers)
+
+				// Self-service profile for the authenticated user
+				users.GET("/me", middleware.RequireAuth(), userHandler.GetMe)
+				users.PATCH("/me", middleware.RequireAuth(), userHandler.UpdateMe)
+				users.POST("/me/email", middleware.RequireAuth(), userHandler.RequestEmailChange)
+				users.POST("/me/email/verify", middleware.RequireAuth(), userHandler.ConfirmEmailChange)
+
+				// Account status management
+				users.POST("/:id/suspend", middleware.RequireAuth(), middleware.RequireRole("admin"), userHandler.SuspendUser)
+				users.POST("/:id/reactivate", middleware.RequireAuth(), middleware.RequireRole("admin"), userHandler.ReactivateUser)
+
+				// Soft-delete recovery and irreversible erasure
+				users.POST("/:id/restore", middleware.RequireAuth(), middleware.RequireRole("admin"), userHandler.RestoreUser)
+				users.POST("/:id/erase", middleware.RequireAuth(), middleware.RequireRole("admin"), userHandler.EraseUser)
+
+				// Organization and team memberships; auth-service reads /:id/memberships when issuing tokens
+				users.GET("/me/memberships", middleware.RequireAuth(), organizationHandler.GetMyMemberships)
+				users.GET("/:id/memberships", organizationHandler.GetUserMemberships)
+			}
+
+			orgs := v1.Group("/organizations", middleware.RequireAuth())
+			{
+				orgs.GET("", organizationHandler.ListOrganizations)
+				orgs.GET("/:org_id", organizationHandler.GetOrganization)
+				orgs.GET("/:org_id/members", organizationHandler.ListOrganizationMembers)
+				orgs.GET("/:org_id/teams", organizationHandler.ListTeams)
+				orgs.GET("/:org_id/teams/:team_id", organizationHandler.GetTeam)
+				orgs.GET("/:org_id/teams/:team_id/members", organizationHandler.ListTeamMembers)
+
+				admin := orgs.Group("", middleware.RequireRole("admin"))
+				admin.POST("", organizationHandler.CreateOrganization)
+				admin.PATCH("/:org_id", organizationHandler.UpdateOrganization)
+				admin.DELETE("/:org_id", organizationHandler.DeleteOrganization)
+				admin.POST("/:org_id/members", organizationHandler.AddOrganizationMember)
+				admin.DELETE("/:org_id/members/:user_id", organizationHandler.RemoveOrganizationMember)
+				admin.POST("/:org_id/teams", organizationHandler.CreateTeam)
+				admin.DELETE("/:org_id/teams/:team_id", organizationHandler.DeleteTeam)
+				admin.POST("/:org_id/teams/:team_id/members", organizationHandler.AddTeamMember)
+				admin.DELETE("/:org_id/teams/:team_id/members/:user_id", organizationHandler.RemoveTeamMember)
 			}
 		}
 	}
@@ -206,6 +308,13 @@ func main() {
 		logger.Error("Server forced to shutdown", err)
 	}
 
+	// Flush queued audit events before the database connection closes
+	if auditSink != nil {
+		if err := auditSink.Close(ctx); err != nil {
+			logger.Error("Failed to flush audit events", err)
+		}
+	}
+
 	logger.Info("Server exited")
 }
 
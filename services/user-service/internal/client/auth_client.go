@@ -0,0 +1,198 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// AuthClient calls auth-service on behalf of user-service
+type AuthClient interface {
+	PurgeUserData(ctx context.Context, userID uuid.UUID, jwtToken string) error
+	ListUserIDsByRole(ctx context.Context, role, jwtToken string) ([]uuid.UUID, error)
+	ListRoleIDs(ctx context.Context, jwtToken string) (map[string]uuid.UUID, error)
+	AssignRole(ctx context.Context, userID, roleID uuid.UUID, jwtToken string) error
+}
+
+type authClient struct {
+	baseURL    string
+	httpClient *http.Client
+	logger     *logrus.Logger
+}
+
+type AuthClientConfig struct {
+	BaseURL string
+	Timeout time.Duration
+}
+
+func NewAuthClient(cfg AuthClientConfig, logger *logrus.Logger) AuthClient {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	return &authClient{
+		baseURL: cfg.BaseURL,
+		httpClient: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+		logger: logger,
+	}
+}
+
+// PurgeUserData asks auth-service to delete the user's tokens, sessions and role assignments.
+// The caller's token is forwarded because the endpoint requires the admin role; there is no
+// service credential, so the call fails if that token has expired. Deleting is idempotent,
+// so the call can be repeated.
+func (c *authClient) PurgeUserData(ctx context.Context, userID uuid.UUID, jwtToken string) error {
+	url := fmt.Sprintf("%s/api/v1/auth/users/%s/data", c.baseURL, userID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if jwtToken != "" {
+		req.Header.Set("Authorization", "Bearer "+jwtToken)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call auth-service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("auth-service returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// ListUserIDsByRole asks auth-service which users hold the named role.
+// Rejections of the forwarded token are returned as models.ForbiddenError.
+func (c *authClient) ListUserIDsByRole(ctx context.Context, role, jwtToken string) ([]uuid.UUID, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/auth/users?role=%s", c.baseURL, url.QueryEscape(role))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if jwtToken != "" {
+		req.Header.Set("Authorization", "Bearer "+jwtToken)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call auth-service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, models.NewForbiddenError("filtering by role requires the admin role")
+	default:
+		return nil, fmt.Errorf("auth-service returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		UserIDs []uuid.UUID `json:"user_ids"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode auth-service response: %w", err)
+	}
+
+	return body.UserIDs, nil
+}
+
+// ListRoleIDs returns the IDs of all auth-service roles keyed by role name.
+// Rejections of the forwarded token are returned as models.ForbiddenError.
+func (c *authClient) ListRoleIDs(ctx context.Context, jwtToken string) (map[string]uuid.UUID, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/auth/roles", c.baseURL)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if jwtToken != "" {
+		req.Header.Set("Authorization", "Bearer "+jwtToken)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call auth-service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, models.NewForbiddenError("assigning roles requires the admin role")
+	default:
+		return nil, fmt.Errorf("auth-service returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Roles []struct {
+			ID   uuid.UUID `json:"id"`
+			Name string    `json:"name"`
+		} `json:"roles"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode auth-service response: %w", err)
+	}
+
+	roles := make(map[string]uuid.UUID, len(body.Roles))
+	for _, role := range body.Roles {
+		roles[role.Name] = role.ID
+	}
+	return roles, nil
+}
+
+// AssignRole grants the role to the user in auth-service
+func (c *authClient) AssignRole(ctx context.Context, userID, roleID uuid.UUID, jwtToken string) error {
+	endpoint := fmt.Sprintf("%s/api/v1/auth/users/%s/roles", c.baseURL, userID)
+
+	payload, err := json.Marshal(map[string]string{"role_id": roleID.String()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if jwtToken != "" {
+		req.Header.Set("Authorization", "Bearer "+jwtToken)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call auth-service: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("auth-service returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
+)
+
+func TestPurgeUserData_Success(t *testing.T) {
+	userID := uuid.New()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/api/v1/auth/users/"+userID.String()+"/data", r.URL.Path)
+		assert.Equal(t, "Bearer admin-token", r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewAuthClient(AuthClientConfig{BaseURL: server.URL}, nil)
+
+	err := client.PurgeUserData(context.Background(), userID, "admin-token")
+	assert.NoError(t, err)
+}
+
+func TestPurgeUserData_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewAuthClient(AuthClientConfig{BaseURL: server.URL}, nil)
+
+	err := client.PurgeUserData(context.Background(), uuid.New(), "")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
+
+func TestListUserIDsByRole_Success(t *testing.T) {
+	userID := uuid.New()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v1/auth/users", r.URL.Path)
+		assert.Equal(t, "team lead", r.URL.Query().Get("role"))
+		assert.Equal(t, "Bearer admin-token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"role":"team lead","user_ids":["` + userID.String() + `"]}`))
+	}))
+	defer server.Close()
+
+	client := NewAuthClient(AuthClientConfig{BaseURL: server.URL}, nil)
+
+	ids, err := client.ListUserIDsByRole(context.Background(), "team lead", "admin-token")
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{userID}, ids)
+}
+
+func TestListUserIDsByRole_Forbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewAuthClient(AuthClientConfig{BaseURL: server.URL}, nil)
+
+	_, err := client.ListUserIDsByRole(context.Background(), "admin", "user-token")
+	assert.IsType(t, models.ForbiddenError{}, err)
+}
+
+func TestListRoleIDs_Success(t *testing.T) {
+	roleID := uuid.New()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/v1/auth/roles", r.URL.Path)
+		assert.Equal(t, "Bearer admin-token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"roles":[{"id":"` + roleID.String() + `","name":"editor"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewAuthClient(AuthClientConfig{BaseURL: server.URL}, nil)
+
+	roles, err := client.ListRoleIDs(context.Background(), "admin-token")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]uuid.UUID{"editor": roleID}, roles)
+}
+
+func TestAssignRole_Success(t *testing.T) {
+	userID := uuid.New()
+	roleID := uuid.New()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/auth/users/"+userID.String()+"/roles", r.URL.Path)
+		var body map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, roleID.String(), body["role_id"])
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewAuthClient(AuthClientConfig{BaseURL: server.URL}, nil)
+
+	assert.NoError(t, client.AssignRole(context.Background(), userID, roleID, "admin-token"))
+}
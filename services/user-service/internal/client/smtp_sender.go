@@ -0,0 +1,166 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// SMTPSenderConfig configures the SMTP transport of outgoing mail
+type SMTPSenderConfig struct {
+	Host     string
+	Port     int
+	Username string // PLAIN auth is used when set
+	Password string
+	From     string
+	Timeout  time.Duration // Deadline for delivering one message, on top of the caller's context
+}
+
+// SMTPSender mails email change verification tokens to the address being verified
+type SMTPSender struct {
+	host    string
+	addr    string
+	auth    smtp.Auth
+	from    string
+	timeout time.Duration
+	dialer  *net.Dialer
+	logger  *logrus.Logger
+}
+
+func NewSMTPSender(cfg SMTPSenderConfig, logger *logrus.Logger) *SMTPSender {
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return &SMTPSender{
+		host:    cfg.Host,
+		addr:    net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		auth:    auth,
+		from:    cfg.From,
+		timeout: cfg.Timeout,
+		dialer:  &net.Dialer{},
+		logger:  logger,
+	}
+}
+
+// SendEmailVerification mails the verification token to email. The token is never logged.
+func (s *SMTPSender) SendEmailVerification(ctx context.Context, userID uuid.UUID, email, token string) error {
+	if strings.ContainsAny(email, "\r\n") {
+		return fmt.Errorf("invalid recipient address")
+	}
+
+	msg := verificationMessage(s.from, email, token, time.Now())
+	if err := s.send(ctx, email, msg); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"user_id": userID,
+			"email":   email,
+		}).WithError(err).Error("Failed to send email verification")
+		return fmt.Errorf("failed to send email verification: %w", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"email":   email,
+	}).Info("Email verification sent")
+	return nil
+}
+
+// send delivers msg over one SMTP session, upgrading to TLS when the server offers STARTTLS.
+// The whole session is bound to ctx and the configured timeout, so a slow or unresponsive
+// server fails the request instead of hanging it.
+func (s *SMTPSender) send(ctx context.Context, to string, msg []byte) error {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	conn, err := s.dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	// Unblock any pending read or write as soon as the context is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	err = s.deliver(conn, to, msg)
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return fmt.Errorf("SMTP delivery aborted: %w", ctxErr)
+	}
+	return err
+}
+
+func (s *SMTPSender) deliver(conn net.Conn, to string, msg []byte) error {
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if s.auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("SMTP server does not support authentication")
+		}
+		if err := c.Auth(s.auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(s.from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// verificationMessage builds the RFC 5322 message carrying a verification token
+func verificationMessage(from, to, token string, date time.Time) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: Confirm your new email address\r\n")
+	b.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString("A change of your account's email address to this address was requested.\r\n")
+	b.WriteString("Confirm it with this verification token, valid for 24 hours:\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(token + "\r\n")
+	b.WriteString("\r\n")
+	b.WriteString("If you did not request this change, ignore this message.\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer accepts SMTP sessions on a loopback port and records the commands and
+// message data it receives. A silent server accepts connections but never greets.
+type fakeSMTPServer struct {
+	listener net.Listener
+	silent   bool
+	mu       sync.Mutex
+	commands []string
+	data     string
+}
+
+func newFakeSMTPServer(t *testing.T, silent bool) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fakeSMTPServer{listener: listener, silent: silent}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.session(conn)
+	}
+}
+
+func (s *fakeSMTPServer) session(conn net.Conn) {
+	defer conn.Close()
+	if s.silent {
+		// Hold the connection open without a greeting until the client gives up
+		conn.Read(make([]byte, 1))
+		return
+	}
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 127.0.0.1 ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		s.mu.Lock()
+		s.commands = append(s.commands, line)
+		s.mu.Unlock()
+
+		switch verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0]); verb {
+		case "EHLO":
+			reply("250-127.0.0.1")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			reply("235 Authenticated")
+		case "DATA":
+			reply("354 Go ahead")
+			var data strings.Builder
+			for {
+				dataLine, err := r.ReadString('\n')
+				if err != nil || dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.mu.Lock()
+			s.data = data.String()
+			s.mu.Unlock()
+			reply("250 Queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTPSender_SendEmailVerification(t *testing.T) {
+	server := newFakeSMTPServer(t, false)
+	logger, hook := test.NewNullLogger()
+	sender := NewSMTPSender(SMTPSenderConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Username: "mailer",
+		Password: "secret",
+		From:     "no-reply@example.com",
+		Timeout:  5 * time.Second,
+	}, logger)
+
+	err := sender.SendEmailVerification(context.Background(), uuid.New(), "new@example.com", "raw-token")
+
+	require.NoError(t, err)
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Contains(t, server.commands, "MAIL FROM:<no-reply@example.com>")
+	assert.Contains(t, server.commands, "RCPT TO:<new@example.com>")
+	assert.True(t, strings.HasPrefix(server.commands[1], "AUTH PLAIN"), server.commands)
+	assert.Contains(t, server.data, "To: new@example.com\r\n")
+	assert.Contains(t, server.data, "\r\nraw-token\r\n")
+	for _, entry := range hook.AllEntries() {
+		assert.NotContains(t, entry.Message, "raw-token")
+		for _, value := range entry.Data {
+			assert.NotEqual(t, "raw-token", value)
+		}
+	}
+}
+
+func TestSMTPSender_UnresponsiveServer(t *testing.T) {
+	server := newFakeSMTPServer(t, true)
+	logger, _ := test.NewNullLogger()
+
+	t.Run("configured timeout", func(t *testing.T) {
+		sender := NewSMTPSender(SMTPSenderConfig{Host: "127.0.0.1", Port: server.port(), Timeout: 50 * time.Millisecond}, logger)
+
+		start := time.Now()
+		err := sender.SendEmailVerification(context.Background(), uuid.New(), "new@example.com", "raw-token")
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+
+	t.Run("request context cancelled", func(t *testing.T) {
+		sender := NewSMTPSender(SMTPSenderConfig{Host: "127.0.0.1", Port: server.port()}, logger)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		err := sender.SendEmailVerification(ctx, uuid.New(), "new@example.com", "raw-token")
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, time.Since(start), 2*time.Second)
+	})
+}
+
+func TestSMTPSender_RejectsHeaderInjection(t *testing.T) {
+	server := newFakeSMTPServer(t, false)
+	logger, _ := test.NewNullLogger()
+	sender := NewSMTPSender(SMTPSenderConfig{Host: "127.0.0.1", Port: server.port(), From: "no-reply@example.com"}, logger)
+
+	err := sender.SendEmailVerification(context.Background(), uuid.New(), "new@example.com\r\nBcc: x@example.com", "raw-token")
+
+	assert.Error(t, err)
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Empty(t, server.commands)
+}
+
+func TestSMTPSender_ConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	logger, _ := test.NewNullLogger()
+	sender := NewSMTPSender(SMTPSenderConfig{Host: "127.0.0.1", Port: port, Timeout: time.Second}, logger)
+
+	err = sender.SendEmailVerification(context.Background(), uuid.New(), "new@example.com", "raw-token")
+
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "raw-token")
+	assert.Contains(t, err.Error(), strconv.Itoa(port))
+}
@@ -0,0 +1,524 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/common/logging"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/services"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// OrganizationServiceInterface defines the service operations needed for organization handlers
+type OrganizationServiceInterface interface {
+	CreateOrganization(ctx context.Context, req *models.CreateOrganizationRequest) (*models.Organization, error)
+	GetOrganization(ctx context.Context, id uuid.UUID) (*models.Organization, error)
+	ListOrganizations(ctx context.Context, limit, offset int) ([]*models.Organization, error)
+	UpdateOrganization(ctx context.Context, id uuid.UUID, req *models.UpdateOrganizationRequest) (*models.Organization, error)
+	DeleteOrganization(ctx context.Context, id uuid.UUID) error
+	AddOrganizationMember(ctx context.Context, orgID uuid.UUID, req *models.AddMemberRequest) (*models.OrganizationMember, error)
+	RemoveOrganizationMember(ctx context.Context, orgID, userID uuid.UUID) error
+	ListOrganizationMembers(ctx context.Context, orgID uuid.UUID) ([]*models.OrganizationMember, error)
+	CreateTeam(ctx context.Context, orgID uuid.UUID, req *models.CreateTeamRequest) (*models.Team, error)
+	GetTeam(ctx context.Context, orgID, teamID uuid.UUID) (*models.Team, error)
+	ListTeams(ctx context.Context, orgID uuid.UUID) ([]*models.Team, error)
+	DeleteTeam(ctx context.Context, orgID, teamID uuid.UUID) error
+	AddTeamMember(ctx context.Context, orgID, teamID uuid.UUID, req *models.AddMemberRequest) (*models.OrganizationMember, error)
+	RemoveTeamMember(ctx context.Context, orgID, teamID, userID uuid.UUID) error
+	ListTeamMembers(ctx context.Context, orgID, teamID uuid.UUID) ([]*models.OrganizationMember, error)
+	ListUserMemberships(ctx context.Context, userID uuid.UUID) ([]*models.Membership, error)
+}
+
+type OrganizationHandler struct {
+	service     OrganizationServiceInterface
+	logger      *logrus.Logger
+	auditLogger *logging.AuditLogger
+}
+
+func NewOrganizationHandler(service *services.OrganizationService, logger *logrus.Logger) *OrganizationHandler {
+	return &OrganizationHandler{
+		service:     service,
+		logger:      logger,
+		auditLogger: logging.NewAuditLogger(logger, "user-service"),
+	}
+}
+
+// NewOrganizationHandlerWithInterface creates a handler with a service interface (for testing)
+func NewOrganizationHandlerWithInterface(service OrganizationServiceInterface, logger *logrus.Logger) *OrganizationHandler {
+	return &OrganizationHandler{
+		service:     service,
+		logger:      logger,
+		auditLogger: logging.NewAuditLogger(logger, "user-service"),
+	}
+}
+
+// SetAuditSink persists the handler's audit events to sink
+func (h *OrganizationHandler) SetAuditSink(sink logging.AuditSink) {
+	h.auditLogger.SetSink(sink)
+}
+
+// CreateOrganization creates an organization (admin only)
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	var req models.CreateOrganizationRequest
+	if !h.bindJSON(c, &req, requestID) {
+		return
+	}
+
+	org, err := h.service.CreateOrganization(c.Request.Context(), &req)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to create organization", requestID)
+		h.audit(c, "create_organization", req.Slug, err)
+		return
+	}
+
+	h.audit(c, "create_organization", org.ID.String(), nil)
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    org,
+		"message": "Organization created successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+
+	org, err := h.service.GetOrganization(c.Request.Context(), orgID)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to get organization", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": org,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	orgs, err := h.service.ListOrganizations(c.Request.Context(), limit, offset)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to list organizations", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": orgs,
+		"pagination": gin.H{
+			"limit":  limit,
+			"offset": offset,
+			"count":  len(orgs),
+		},
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// UpdateOrganization changes an organization's name or description (admin only)
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateOrganizationRequest
+	if !h.bindJSON(c, &req, requestID) {
+		return
+	}
+
+	org, err := h.service.UpdateOrganization(c.Request.Context(), orgID, &req)
+	h.audit(c, "update_organization", orgID.String(), err)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to update organization", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    org,
+		"message": "Organization updated successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+// DeleteOrganization deletes an organization with its teams and memberships (admin only)
+func (h *OrganizationHandler) DeleteOrganization(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+
+	err := h.service.DeleteOrganization(c.Request.Context(), orgID)
+	h.audit(c, "delete_organization", orgID.String(), err)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to delete organization", requestID)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *OrganizationHandler) ListOrganizationMembers(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+
+	members, err := h.service.ListOrganizationMembers(c.Request.Context(), orgID)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to list organization members", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": members,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// AddOrganizationMember adds a user to an organization or changes their role (admin only)
+func (h *OrganizationHandler) AddOrganizationMember(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+
+	var req models.AddMemberRequest
+	if !h.bindJSON(c, &req, requestID) {
+		return
+	}
+
+	member, err := h.service.AddOrganizationMember(c.Request.Context(), orgID, &req)
+	h.audit(c, "add_organization_member", req.UserID.String(), err)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to add organization member", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    member,
+		"message": "Organization member saved successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+// RemoveOrganizationMember removes a user from an organization and its teams (admin only)
+func (h *OrganizationHandler) RemoveOrganizationMember(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+	userID, ok := h.uuidParam(c, "user_id", requestID)
+	if !ok {
+		return
+	}
+
+	err := h.service.RemoveOrganizationMember(c.Request.Context(), orgID, userID)
+	h.audit(c, "remove_organization_member", userID.String(), err)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to remove organization member", requestID)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// CreateTeam creates a team in an organization (admin only)
+func (h *OrganizationHandler) CreateTeam(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+
+	var req models.CreateTeamRequest
+	if !h.bindJSON(c, &req, requestID) {
+		return
+	}
+
+	team, err := h.service.CreateTeam(c.Request.Context(), orgID, &req)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to create team", requestID)
+		h.audit(c, "create_team", orgID.String(), err)
+		return
+	}
+
+	h.audit(c, "create_team", team.ID.String(), nil)
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    team,
+		"message": "Team created successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+func (h *OrganizationHandler) GetTeam(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+	teamID, ok := h.uuidParam(c, "team_id", requestID)
+	if !ok {
+		return
+	}
+
+	team, err := h.service.GetTeam(c.Request.Context(), orgID, teamID)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to get team", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": team,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+func (h *OrganizationHandler) ListTeams(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+
+	teams, err := h.service.ListTeams(c.Request.Context(), orgID)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to list teams", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": teams,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// DeleteTeam deletes a team and its memberships (admin only)
+func (h *OrganizationHandler) DeleteTeam(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+	teamID, ok := h.uuidParam(c, "team_id", requestID)
+	if !ok {
+		return
+	}
+
+	err := h.service.DeleteTeam(c.Request.Context(), orgID, teamID)
+	h.audit(c, "delete_team", teamID.String(), err)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to delete team", requestID)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+func (h *OrganizationHandler) ListTeamMembers(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+	teamID, ok := h.uuidParam(c, "team_id", requestID)
+	if !ok {
+		return
+	}
+
+	members, err := h.service.ListTeamMembers(c.Request.Context(), orgID, teamID)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to list team members", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": members,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// AddTeamMember adds an organization member to a team or changes their team role (admin only)
+func (h *OrganizationHandler) AddTeamMember(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+	teamID, ok := h.uuidParam(c, "team_id", requestID)
+	if !ok {
+		return
+	}
+
+	var req models.AddMemberRequest
+	if !h.bindJSON(c, &req, requestID) {
+		return
+	}
+
+	member, err := h.service.AddTeamMember(c.Request.Context(), orgID, teamID, &req)
+	h.audit(c, "add_team_member", req.UserID.String(), err)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to add team member", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    member,
+		"message": "Team member saved successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+// RemoveTeamMember removes a user from a team (admin only)
+func (h *OrganizationHandler) RemoveTeamMember(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	orgID, ok := h.uuidParam(c, "org_id", requestID)
+	if !ok {
+		return
+	}
+	teamID, ok := h.uuidParam(c, "team_id", requestID)
+	if !ok {
+		return
+	}
+	userID, ok := h.uuidParam(c, "user_id", requestID)
+	if !ok {
+		return
+	}
+
+	err := h.service.RemoveTeamMember(c.Request.Context(), orgID, teamID, userID)
+	h.audit(c, "remove_team_member", userID.String(), err)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to remove team member", requestID)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetUserMemberships lists a user's organization and team memberships; auth-service reads it when issuing tokens
+func (h *OrganizationHandler) GetUserMemberships(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	userID, ok := h.uuidParam(c, "id", requestID)
+	if !ok {
+		return
+	}
+
+	h.respondWithMemberships(c, userID, requestID)
+}
+
+// GetMyMemberships lists the authenticated user's organization and team memberships
+func (h *OrganizationHandler) GetMyMemberships(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	userID, err := uuid.Parse(middleware.GetAuthenticatedUserID(c))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Authentication required",
+			"type":  "unauthorized",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	h.respondWithMemberships(c, userID, requestID)
+}
+
+func (h *OrganizationHandler) respondWithMemberships(c *gin.Context, userID uuid.UUID, requestID string) {
+	memberships, err := h.service.ListUserMemberships(c.Request.Context(), userID)
+	if err != nil {
+		writeServiceError(c, h.logger, err, "Failed to list user memberships", requestID)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": memberships,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// uuidParam parses a UUID path parameter, writing a 400 response when it is malformed
+func (h *OrganizationHandler) uuidParam(c *gin.Context, name, requestID string) (uuid.UUID, bool) {
+	value := c.Param(name)
+	id, err := uuid.Parse(value)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+			name:         value,
+		}).WithError(err).Error("Invalid ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid ID format",
+			"type":  "validation_error",
+			"field": name,
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// bindJSON binds the request body into req, writing a 400 response when it is invalid
+func (h *OrganizationHandler) bindJSON(c *gin.Context, req interface{}, requestID string) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+		}).WithError(err).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return false
+	}
+	return true
+}
+
+// audit records an organization management action performed by the authenticated admin
+func (h *OrganizationHandler) audit(c *gin.Context, action, entityID string, err error) {
+	span := trace.SpanFromContext(c.Request.Context())
+	errMsg := ""
+	if err != nil {
+		errMsg = err.Error()
+	}
+	h.auditLogger.LogAdminAction(middleware.GetAuthenticatedUserID(c), c.GetHeader("X-Request-ID"), entityID,
+		c.ClientIP(), c.GetHeader("User-Agent"), action,
+		span.SpanContext().TraceID().String(), span.SpanContext().SpanID().String(), err == nil, errMsg)
+}
@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
+)
+
+// MockOrganizationService is a mock implementation of OrganizationServiceInterface for testing
+type MockOrganizationService struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationService) CreateOrganization(ctx context.Context, req *models.CreateOrganizationRequest) (*models.Organization, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationService) GetOrganization(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationService) ListOrganizations(ctx context.Context, limit, offset int) ([]*models.Organization, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationService) UpdateOrganization(ctx context.Context, id uuid.UUID, req *models.UpdateOrganizationRequest) (*models.Organization, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationService) DeleteOrganization(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockOrganizationService) AddOrganizationMember(ctx context.Context, orgID uuid.UUID, req *models.AddMemberRequest) (*models.OrganizationMember, error) {
+	args := m.Called(ctx, orgID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationService) RemoveOrganizationMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	return m.Called(ctx, orgID, userID).Error(0)
+}
+
+func (m *MockOrganizationService) ListOrganizationMembers(ctx context.Context, orgID uuid.UUID) ([]*models.OrganizationMember, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationService) CreateTeam(ctx context.Context, orgID uuid.UUID, req *models.CreateTeamRequest) (*models.Team, error) {
+	args := m.Called(ctx, orgID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Team), args.Error(1)
+}
+
+func (m *MockOrganizationService) GetTeam(ctx context.Context, orgID, teamID uuid.UUID) (*models.Team, error) {
+	args := m.Called(ctx, orgID, teamID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Team), args.Error(1)
+}
+
+func (m *MockOrganizationService) ListTeams(ctx context.Context, orgID uuid.UUID) ([]*models.Team, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Team), args.Error(1)
+}
+
+func (m *MockOrganizationService) DeleteTeam(ctx context.Context, orgID, teamID uuid.UUID) error {
+	return m.Called(ctx, orgID, teamID).Error(0)
+}
+
+func (m *MockOrganizationService) AddTeamMember(ctx context.Context, orgID, teamID uuid.UUID, req *models.AddMemberRequest) (*models.OrganizationMember, error) {
+	args := m.Called(ctx, orgID, teamID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationService) RemoveTeamMember(ctx context.Context, orgID, teamID, userID uuid.UUID) error {
+	return m.Called(ctx, orgID, teamID, userID).Error(0)
+}
+
+func (m *MockOrganizationService) ListTeamMembers(ctx context.Context, orgID, teamID uuid.UUID) ([]*models.OrganizationMember, error) {
+	args := m.Called(ctx, orgID, teamID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationService) ListUserMemberships(ctx context.Context, userID uuid.UUID) ([]*models.Membership, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Membership), args.Error(1)
+}
+
+func TestOrganizationHandler_CreateOrganization(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           interface{}
+		setupMock      func(*MockOrganizationService)
+		expectedStatus int
+	}{
+		{
+			name: "created",
+			body: models.CreateOrganizationRequest{Name: "Acme", Slug: "acme"},
+			setupMock: func(m *MockOrganizationService) {
+				m.On("CreateOrganization", mock.Anything, mock.AnythingOfType("*models.CreateOrganizationRequest")).
+					Return(&models.Organization{ID: uuid.New(), Name: "Acme", Slug: "acme"}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "missing slug",
+			body:           map[string]string{"name": "Acme"},
+			setupMock:      func(m *MockOrganizationService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "duplicate slug",
+			body: models.CreateOrganizationRequest{Name: "Acme", Slug: "acme"},
+			setupMock: func(m *MockOrganizationService) {
+				m.On("CreateOrganization", mock.Anything, mock.Anything).
+					Return(nil, models.NewConflictError("Organization", "slug", "acme"))
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(MockOrganizationService)
+			tt.setupMock(service)
+			handler := NewOrganizationHandlerWithInterface(service, createTestLogger())
+
+			c, w := createTestGinContext("POST", "/api/v1/organizations", tt.body)
+			handler.CreateOrganization(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			service.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrganizationHandler_GetTeam(t *testing.T) {
+	orgID := uuid.New()
+	teamID := uuid.New()
+
+	tests := []struct {
+		name           string
+		orgID          string
+		teamID         string
+		setupMock      func(*MockOrganizationService)
+		expectedStatus int
+		expectedField  string
+	}{
+		{
+			name:   "found",
+			orgID:  orgID.String(),
+			teamID: teamID.String(),
+			setupMock: func(m *MockOrganizationService) {
+				m.On("GetTeam", mock.Anything, orgID, teamID).Return(&models.Team{ID: teamID, OrganizationID: orgID}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid team id",
+			orgID:          orgID.String(),
+			teamID:         "not-a-uuid",
+			setupMock:      func(m *MockOrganizationService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "team_id",
+		},
+		{
+			name:   "not found",
+			orgID:  orgID.String(),
+			teamID: teamID.String(),
+			setupMock: func(m *MockOrganizationService) {
+				m.On("GetTeam", mock.Anything, orgID, teamID).Return(nil, models.NewNotFoundError("Team", "id", teamID.String()))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(MockOrganizationService)
+			tt.setupMock(service)
+			handler := NewOrganizationHandlerWithInterface(service, createTestLogger())
+
+			c, w := createTestGinContext("GET", "/api/v1/organizations/"+tt.orgID+"/teams/"+tt.teamID, nil)
+			c.Params = gin.Params{{Key: "org_id", Value: tt.orgID}, {Key: "team_id", Value: tt.teamID}}
+			handler.GetTeam(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedField != "" {
+				var response map[string]interface{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedField, response["field"])
+			}
+			service.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrganizationHandler_RemoveTeamMember(t *testing.T) {
+	orgID := uuid.New()
+	teamID := uuid.New()
+	userID := uuid.New()
+
+	service := new(MockOrganizationService)
+	service.On("RemoveTeamMember", mock.Anything, orgID, teamID, userID).Return(nil)
+	handler := NewOrganizationHandlerWithInterface(service, createTestLogger())
+
+	c, w := createTestGinContext("DELETE", "/", nil)
+	c.Params = gin.Params{
+		{Key: "org_id", Value: orgID.String()},
+		{Key: "team_id", Value: teamID.String()},
+		{Key: "user_id", Value: userID.String()},
+	}
+	handler.RemoveTeamMember(c)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	service.AssertExpectations(t)
+}
+
+func TestOrganizationHandler_GetMyMemberships(t *testing.T) {
+	userID := uuid.New()
+	teamID := uuid.New()
+
+	t.Run("authenticated", func(t *testing.T) {
+		service := new(MockOrganizationService)
+		service.On("ListUserMemberships", mock.Anything, userID).Return([]*models.Membership{
+			{OrganizationID: uuid.New(), OrganizationSlug: "acme", Role: models.OrganizationRoleMember},
+			{OrganizationID: uuid.New(), OrganizationSlug: "acme", TeamID: &teamID, TeamName: "Platform", Role: models.TeamRoleMaintainer},
+		}, nil)
+		handler := NewOrganizationHandlerWithInterface(service, createTestLogger())
+
+		c, w := createTestGinContext("GET", "/api/v1/users/me/memberships", nil)
+		c.Set("user_id", userID.String())
+		handler.GetMyMemberships(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response["data"], 2)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		service := new(MockOrganizationService)
+		handler := NewOrganizationHandlerWithInterface(service, createTestLogger())
+
+		c, w := createTestGinContext("GET", "/api/v1/users/me/memberships", nil)
+		handler.GetMyMemberships(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("service failure", func(t *testing.T) {
+		service := new(MockOrganizationService)
+		service.On("ListUserMemberships", mock.Anything, userID).
+			Return(nil, models.NewInternalError("listing user memberships", errors.New("boom")))
+		handler := NewOrganizationHandlerWithInterface(service, createTestLogger())
+
+		c, w := createTestGinContext("GET", "/api/v1/users/me/memberships", nil)
+		c.Set("user_id", userID.String())
+		handler.GetMyMemberships(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -24,7 +27,16 @@ type UserServiceInterface interface {
 	ReplaceUser(ctx context.Context, id uuid.UUID, req *models.ReplaceUserRequest) (*models.UserResponse, error)
 	UpdateUser(ctx context.Context, id uuid.UUID, req *models.UpdateUserRequest) (*models.UserResponse, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) error
-	ListUsers(ctx context.Context, limit, offset int) ([]*models.UserResponse, error)
+	ListUsers(ctx context.Context, query *models.ListUsersQuery, jwtToken string) (*models.UserListResult, error)
+	UpdateProfile(ctx context.Context, id uuid.UUID, req *models.UpdateProfileRequest) (*models.UserResponse, error)
+	RequestEmailChange(ctx context.Context, id uuid.UUID, req *models.ChangeEmailRequest) (*models.EmailChangeResponse, error)
+	ConfirmEmailChange(ctx context.Context, id uuid.UUID, req *models.ConfirmEmailChangeRequest) (*models.UserResponse, error)
+	SuspendUser(ctx context.Context, id uuid.UUID, reason string) (*models.UserResponse, error)
+	ReactivateUser(ctx context.Context, id uuid.UUID) (*models.UserResponse, error)
+	RestoreUser(ctx context.Context, id uuid.UUID) (*models.UserResponse, error)
+	EraseUser(ctx context.Context, id uuid.UUID, jwtToken string) error
+	ImportUsers(ctx context.Context, body io.Reader, opts models.ImportUsersOptions, jwtToken string) (*models.ImportReport, error)
+	ExportUsers(ctx context.Context, query *models.ListUsersQuery, jwtToken string, emit func(*models.UserResponse) error) error
 }
 
 type UserHandler struct {
@@ -53,9 +65,19 @@ func NewUserHandlerWithInterface(service UserServiceInterface, logger *logrus.Lo
 	}
 }
 
+// SetAuditSink persists the handler's audit events to sink
+func (h *UserHandler) SetAuditSink(sink logging.AuditSink) {
+	h.auditLogger.SetSink(sink)
+}
+
 // handleServiceError handles different types of service errors and returns appropriate HTTP responses
 func (h *UserHandler) handleServiceError(c *gin.Context, err error, operation string, requestID string) {
-	h.logger.WithFields(logrus.Fields{
+	writeServiceError(c, h.logger, err, operation, requestID)
+}
+
+// writeServiceError maps service error types to HTTP responses; shared by the service's handlers
+func writeServiceError(c *gin.Context, logger *logrus.Logger, err error, operation string, requestID string) {
+	logger.WithFields(logrus.Fields{
 		"request_id": requestID,
 	}).WithError(err).Error(operation)
 
@@ -92,6 +114,14 @@ func (h *UserHandler) handleServiceError(c *gin.Context, err error, operation st
 		})
 		return
 
+	case models.ForbiddenError:
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": e.Error(),
+			"type":  "forbidden",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+
 	case models.InternalError:
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":     "Internal server error",
@@ -332,6 +362,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// ListUsers lists users with optional search, filters, sorting and offset or cursor pagination
 func (h *UserHandler) ListUsers(c *gin.Context) {
 	requestID := c.GetHeader("X-Request-ID")
 
@@ -363,7 +394,41 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 		return
 	}
 
-	users, err := h.service.ListUsers(c.Request.Context(), limit, offset)
+	query := &models.ListUsersQuery{
+		Email:  c.Query("email"),
+		Name:   c.Query("name"),
+		Status: c.Query("status"),
+		Role:   c.Query("role"),
+		Sort:   c.Query("sort"),
+		Order:  c.Query("order"),
+		Limit:  limit,
+		Offset: offset,
+		Cursor: c.Query("cursor"),
+	}
+
+	for _, bound := range []struct {
+		field  string
+		target **time.Time
+	}{
+		{"created_after", &query.CreatedAfter},
+		{"created_before", &query.CreatedBefore},
+	} {
+		value := c.Query(bound.field)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.handleServiceError(c, models.NewValidationError(bound.field, "must be an RFC 3339 timestamp"), "Invalid list filter", requestID)
+			return
+		}
+		*bound.target = &t
+	}
+
+	// Forwarded to auth-service when filtering by role
+	jwtToken := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+	result, err := h.service.ListUsers(c.Request.Context(), query, jwtToken)
 	if err != nil {
 		h.handleServiceError(c, err, "Failed to list users", requestID)
 		return
@@ -373,17 +438,24 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 		"request_id": requestID,
 		"limit":      limit,
 		"offset":     offset,
-		"count":      len(users),
+		"count":      len(result.Users),
+		"total":      result.Total,
 	}).Debug("Users listed successfully")
 
+	pagination := gin.H{
+		"limit":  limit,
+		"offset": offset,
+		"count":  len(result.Users),
+		"total":  result.Total,
+	}
+	if result.NextCursor != "" {
+		pagination["next_cursor"] = result.NextCursor
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"data": users,
-		"pagination": gin.H{
-			"limit":  limit,
-			"offset": offset,
-			"count":  len(users),
-		},
-		"meta": gin.H{"request_id": requestID},
+		"data":       result.Users,
+		"pagination": pagination,
+		"meta":       gin.H{"request_id": requestID},
 	})
 }
 
@@ -458,3 +530,360 @@ func (h *UserHandler) GetUserWithPasswordByEmail(c *gin.Context) {
 		"meta":    gin.H{"request_id": requestID},
 	})
 }
+
+// authenticatedUserID resolves the caller's user ID for self-service endpoints.
+// It writes the error response and returns false when the ID is missing or malformed.
+func (h *UserHandler) authenticatedUserID(c *gin.Context, requestID string) (uuid.UUID, bool) {
+	userIDStr := middleware.GetAuthenticatedUserID(c)
+	if userIDStr == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Authentication required",
+			"type":  "unauthorized",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(userIDStr)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    userIDStr,
+		}).WithError(err).Error("Invalid authenticated user ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID format",
+			"type":  "validation_error",
+			"field": "id",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+// GetMe returns the authenticated user's own profile
+func (h *UserHandler) GetMe(c *gin.Context) {
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, ok := h.authenticatedUserID(c, requestID)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.GetUser(c.Request.Context(), id)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to get own profile", requestID)
+		return
+	}
+
+	h.standardLogger.UserOperation(requestID, user.ID.String(), "get_me", true, nil)
+	c.JSON(http.StatusOK, gin.H{
+		"data": user,
+		"meta": gin.H{"request_id": requestID},
+	})
+}
+
+// UpdateMe updates the authenticated user's own name fields
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	span := trace.SpanFromContext(c.Request.Context())
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, ok := h.authenticatedUserID(c, requestID)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    id.String(),
+		}).WithError(err).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	ipAddress := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	user, err := h.service.UpdateProfile(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to update own profile", requestID)
+		h.auditLogger.LogEntityUpdate(id.String(), requestID, id.String(), ipAddress, userAgent, traceID, spanID, false, err.Error())
+		return
+	}
+
+	h.standardLogger.UserOperation(requestID, user.ID.String(), "update_me", true, nil)
+	h.auditLogger.LogEntityUpdate(id.String(), requestID, id.String(), ipAddress, userAgent, traceID, spanID, true, "")
+	c.JSON(http.StatusOK, gin.H{
+		"data":    user,
+		"message": "Profile updated successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+// RequestEmailChange starts an email change for the authenticated user
+func (h *UserHandler) RequestEmailChange(c *gin.Context) {
+	span := trace.SpanFromContext(c.Request.Context())
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, ok := h.authenticatedUserID(c, requestID)
+	if !ok {
+		return
+	}
+
+	var req models.ChangeEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    id.String(),
+		}).WithError(err).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	ipAddress := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	pending, err := h.service.RequestEmailChange(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to request email change", requestID)
+		h.auditLogger.LogEmailChange(id.String(), requestID, id.String(), ipAddress, userAgent, "request", traceID, spanID, false, err.Error())
+		return
+	}
+
+	h.auditLogger.LogEmailChange(id.String(), requestID, id.String(), ipAddress, userAgent, "request", traceID, spanID, true, "")
+	c.JSON(http.StatusAccepted, gin.H{
+		"data":    pending,
+		"message": "Verification token sent to the new email address",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+// ConfirmEmailChange applies a pending email change using its verification token
+func (h *UserHandler) ConfirmEmailChange(c *gin.Context) {
+	span := trace.SpanFromContext(c.Request.Context())
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+	requestID := c.GetHeader("X-Request-ID")
+
+	id, ok := h.authenticatedUserID(c, requestID)
+	if !ok {
+		return
+	}
+
+	var req models.ConfirmEmailChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    id.String(),
+		}).WithError(err).Error("Invalid request body")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid request format",
+			"type":  "validation_error",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	ipAddress := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	user, err := h.service.ConfirmEmailChange(c.Request.Context(), id, &req)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to confirm email change", requestID)
+		h.auditLogger.LogEmailChange(id.String(), requestID, id.String(), ipAddress, userAgent, "confirm", traceID, spanID, false, err.Error())
+		return
+	}
+
+	h.auditLogger.LogEmailChange(id.String(), requestID, id.String(), ipAddress, userAgent, "confirm", traceID, spanID, true, "")
+	c.JSON(http.StatusOK, gin.H{
+		"data":    user,
+		"message": "Email changed successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+// SuspendUser blocks a user from logging in (admin only)
+func (h *UserHandler) SuspendUser(c *gin.Context) {
+	h.changeUserStatus(c, models.UserStatusSuspended)
+}
+
+// ReactivateUser restores a suspended or deactivated user (admin only)
+func (h *UserHandler) ReactivateUser(c *gin.Context) {
+	h.changeUserStatus(c, models.UserStatusActive)
+}
+
+func (h *UserHandler) changeUserStatus(c *gin.Context, status string) {
+	span := trace.SpanFromContext(c.Request.Context())
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+	requestID := c.GetHeader("X-Request-ID")
+	actorUserID := middleware.GetAuthenticatedUserID(c)
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    idStr,
+		}).WithError(err).Error("Invalid user ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID format",
+			"type":  "validation_error",
+			"field": "id",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	// The body is optional; only a reason may be supplied
+	var req models.StatusChangeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"user_id":    id.String(),
+			}).WithError(err).Error("Invalid request body")
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid request format",
+				"type":  "validation_error",
+				"meta":  gin.H{"request_id": requestID},
+			})
+			return
+		}
+	}
+
+	ipAddress := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	var user *models.UserResponse
+	if status == models.UserStatusSuspended {
+		user, err = h.service.SuspendUser(c.Request.Context(), id, req.Reason)
+	} else {
+		user, err = h.service.ReactivateUser(c.Request.Context(), id)
+	}
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to change user status", requestID)
+		h.auditLogger.LogAccountStatusChange(actorUserID, requestID, id.String(), ipAddress, userAgent, status, req.Reason, traceID, spanID, false, err.Error())
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"user_id":    user.ID,
+		"status":     user.Status,
+	}).Info("User status changed successfully")
+	h.standardLogger.UserOperation(requestID, user.ID.String(), "status_"+status, true, nil)
+	h.auditLogger.LogAccountStatusChange(actorUserID, requestID, id.String(), ipAddress, userAgent, status, req.Reason, traceID, spanID, true, "")
+	c.JSON(http.StatusOK, gin.H{
+		"data":    user,
+		"message": "User status changed successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+// RestoreUser undoes a soft delete within the restore window (admin only)
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	span := trace.SpanFromContext(c.Request.Context())
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+	requestID := c.GetHeader("X-Request-ID")
+	actorUserID := middleware.GetAuthenticatedUserID(c)
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    idStr,
+		}).WithError(err).Error("Invalid user ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID format",
+			"type":  "validation_error",
+			"field": "id",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	ipAddress := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	user, err := h.service.RestoreUser(c.Request.Context(), id)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to restore user", requestID)
+		h.auditLogger.LogAdminAction(actorUserID, requestID, id.String(), ipAddress, userAgent, "restore_user", traceID, spanID, false, err.Error())
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"user_id":    user.ID,
+	}).Info("User restored successfully")
+	h.standardLogger.UserOperation(requestID, user.ID.String(), "restore", true, nil)
+	h.auditLogger.LogAdminAction(actorUserID, requestID, id.String(), ipAddress, userAgent, "restore_user", traceID, spanID, true, "")
+	c.JSON(http.StatusOK, gin.H{
+		"data":    user,
+		"message": "User restored successfully",
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+// EraseUser irreversibly anonymizes a user and purges their auth-service data (admin only)
+func (h *UserHandler) EraseUser(c *gin.Context) {
+	span := trace.SpanFromContext(c.Request.Context())
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+	requestID := c.GetHeader("X-Request-ID")
+	actorUserID := middleware.GetAuthenticatedUserID(c)
+
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		h.logger.WithFields(logrus.Fields{
+			"request_id": requestID,
+			"user_id":    idStr,
+		}).WithError(err).Error("Invalid user ID format")
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid user ID format",
+			"type":  "validation_error",
+			"field": "id",
+			"meta":  gin.H{"request_id": requestID},
+		})
+		return
+	}
+
+	ipAddress := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	// The admin's token authorizes the cascade call to auth-service
+	jwtToken := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+	if err := h.service.EraseUser(c.Request.Context(), id, jwtToken); err != nil {
+		h.handleServiceError(c, err, "Failed to erase user", requestID)
+		h.auditLogger.LogAdminAction(actorUserID, requestID, id.String(), ipAddress, userAgent, "erase_user", traceID, spanID, false, err.Error())
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"user_id":    id,
+	}).Info("User erased successfully")
+	h.standardLogger.UserOperation(requestID, id.String(), "erase", true, nil)
+	h.auditLogger.LogAdminAction(actorUserID, requestID, id.String(), ipAddress, userAgent, "erase_user", traceID, spanID, true, "")
+	c.JSON(http.StatusNoContent, nil)
+}
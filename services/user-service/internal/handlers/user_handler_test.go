@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -57,9 +58,68 @@ func (m *MockUserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
-func (m *MockUserService) ListUsers(ctx context.Context, limit, offset int) ([]*models.UserResponse, error) {
-	args := m.Called(ctx, limit, offset)
-	return args.Get(0).([]*models.UserResponse), args.Error(1)
+func (m *MockUserService) ListUsers(ctx context.Context, query *models.ListUsersQuery, jwtToken string) (*models.UserListResult, error) {
+	args := m.Called(ctx, query, jwtToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserListResult), args.Error(1)
+}
+
+func (m *MockUserService) UpdateProfile(ctx context.Context, id uuid.UUID, req *models.UpdateProfileRequest) (*models.UserResponse, error) {
+	args := m.Called(ctx, id, req)
+	return args.Get(0).(*models.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) RequestEmailChange(ctx context.Context, id uuid.UUID, req *models.ChangeEmailRequest) (*models.EmailChangeResponse, error) {
+	args := m.Called(ctx, id, req)
+	return args.Get(0).(*models.EmailChangeResponse), args.Error(1)
+}
+
+func (m *MockUserService) ConfirmEmailChange(ctx context.Context, id uuid.UUID, req *models.ConfirmEmailChangeRequest) (*models.UserResponse, error) {
+	args := m.Called(ctx, id, req)
+	return args.Get(0).(*models.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) SuspendUser(ctx context.Context, id uuid.UUID, reason string) (*models.UserResponse, error) {
+	args := m.Called(ctx, id, reason)
+	return args.Get(0).(*models.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) ReactivateUser(ctx context.Context, id uuid.UUID) (*models.UserResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) RestoreUser(ctx context.Context, id uuid.UUID) (*models.UserResponse, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*models.UserResponse), args.Error(1)
+}
+
+func (m *MockUserService) EraseUser(ctx context.Context, id uuid.UUID, jwtToken string) error {
+	args := m.Called(ctx, id, jwtToken)
+	return args.Error(0)
+}
+
+func (m *MockUserService) ImportUsers(ctx context.Context, body io.Reader, opts models.ImportUsersOptions, jwtToken string) (*models.ImportReport, error) {
+	args := m.Called(ctx, body, opts, jwtToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ImportReport), args.Error(1)
+}
+
+// ExportUsers emits the users given as the first return value, then returns the error
+func (m *MockUserService) ExportUsers(ctx context.Context, query *models.ListUsersQuery, jwtToken string, emit func(*models.UserResponse) error) error {
+	args := m.Called(ctx, query, jwtToken, emit)
+	if users, ok := args.Get(0).([]*models.UserResponse); ok {
+		for _, user := range users {
+			if err := emit(user); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
 }
 
 // Helper functions
@@ -144,6 +204,16 @@ func TestUserHandler_handleServiceError(t *testing.T) {
 				"meta":      map[string]interface{}{"request_id": "test-request-id"},
 			},
 		},
+		{
+			name:           "forbidden error",
+			err:            models.NewForbiddenError("account is suspended"),
+			expectedStatus: http.StatusForbidden,
+			expectedBody: map[string]interface{}{
+				"error": "forbidden: account is suspended",
+				"type":  "forbidden",
+				"meta":  map[string]interface{}{"request_id": "test-request-id"},
+			},
+		},
 		{
 			name:           "unknown error",
 			err:            assert.AnError,
@@ -646,7 +716,7 @@ func TestUserHandler_ListUsers(t *testing.T) {
 						UpdatedAt: updatedAt,
 					},
 				}
-				m.On("ListUsers", mock.Anything, 10, 0).Return(users, nil)
+				m.On("ListUsers", mock.Anything, &models.ListUsersQuery{Limit: 10}, "").Return(&models.UserListResult{Users: users, Total: 2}, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -654,7 +724,7 @@ func TestUserHandler_ListUsers(t *testing.T) {
 			name:        "empty users list",
 			queryParams: "?limit=10&offset=0",
 			mockSetup: func(m *MockUserService) {
-				m.On("ListUsers", mock.Anything, 10, 0).Return([]*models.UserResponse{}, nil)
+				m.On("ListUsers", mock.Anything, &models.ListUsersQuery{Limit: 10}, "").Return(&models.UserListResult{Users: []*models.UserResponse{}}, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -670,11 +740,41 @@ func TestUserHandler_ListUsers(t *testing.T) {
 				"meta":    map[string]interface{}{"request_id": "test-request-id"},
 			},
 		},
+		{
+			name:        "filters, sort and cursor are passed through",
+			queryParams: "?limit=5&email=jo&name=Do&status=active&role=editor&sort=email&order=asc&cursor=abc&created_after=2024-01-01T00:00:00Z",
+			mockSetup: func(m *MockUserService) {
+				after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+				m.On("ListUsers", mock.Anything, mock.MatchedBy(func(q *models.ListUsersQuery) bool {
+					return q.Limit == 5 && q.Email == "jo" && q.Name == "Do" && q.Status == "active" && q.Role == "editor" &&
+						q.Sort == "email" && q.Order == "asc" && q.Cursor == "abc" &&
+						q.CreatedAfter != nil && q.CreatedAfter.Equal(after) && q.CreatedBefore == nil
+				}), "").Return(&models.UserListResult{Users: []*models.UserResponse{}, Total: 7, NextCursor: "next"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]interface{}{
+				"pagination": map[string]interface{}{"limit": float64(5), "offset": float64(0), "count": float64(0), "total": float64(7), "next_cursor": "next"},
+			},
+		},
+		{
+			name:           "invalid created_before",
+			queryParams:    "?created_before=yesterday",
+			mockSetup:      func(m *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "service validation error",
+			queryParams: "?sort=password_hash",
+			mockSetup: func(m *MockUserService) {
+				m.On("ListUsers", mock.Anything, mock.Anything, "").Return(nil, models.NewValidationError("sort", "unsupported sort field"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 		{
 			name:        "default parameters",
 			queryParams: "",
 			mockSetup: func(m *MockUserService) {
-				m.On("ListUsers", mock.Anything, 10, 0).Return([]*models.UserResponse{}, nil)
+				m.On("ListUsers", mock.Anything, &models.ListUsersQuery{Limit: 10}, "").Return(&models.UserListResult{Users: []*models.UserResponse{}}, nil)
 			},
 			expectedStatus: http.StatusOK,
 		},
@@ -866,3 +966,100 @@ func TestUserHandler_GetUserWithPasswordByEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestUserHandler_UpdateMe(t *testing.T) {
+	logger := createTestLogger()
+	userID := uuid.New()
+
+	tests := []struct {
+		name           string
+		authUserID     string
+		mockSetup      func(*MockUserService)
+		expectedStatus int
+	}{
+		{
+			name:       "successful profile update",
+			authUserID: userID.String(),
+			mockSetup: func(m *MockUserService) {
+				userResp := &models.UserResponse{ID: userID, FirstName: "Jane", Status: models.UserStatusActive}
+				m.On("UpdateProfile", mock.Anything, userID, mock.AnythingOfType("*models.UpdateProfileRequest")).Return(userResp, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:       "suspended user",
+			authUserID: userID.String(),
+			mockSetup: func(m *MockUserService) {
+				m.On("UpdateProfile", mock.Anything, userID, mock.AnythingOfType("*models.UpdateProfileRequest")).
+					Return((*models.UserResponse)(nil), models.NewForbiddenError("account is suspended"))
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "unauthenticated",
+			mockSetup:      func(m *MockUserService) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockUserService{}
+			tt.mockSetup(mockService)
+
+			handler := NewUserHandlerWithInterface(mockService, logger)
+			c, w := createTestGinContext("PATCH", "/users/me", models.UpdateProfileRequest{FirstName: "Jane"})
+			if tt.authUserID != "" {
+				c.Set("user_id", tt.authUserID)
+			}
+
+			handler.UpdateMe(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserHandler_SuspendUser(t *testing.T) {
+	logger := createTestLogger()
+	userID := uuid.New()
+
+	mockService := &MockUserService{}
+	userResp := &models.UserResponse{ID: userID, Status: models.UserStatusSuspended}
+	mockService.On("SuspendUser", mock.Anything, userID, "policy violation").Return(userResp, nil)
+
+	handler := NewUserHandlerWithInterface(mockService, logger)
+	c, w := createTestGinContext("POST", "/users/"+userID.String()+"/suspend", models.StatusChangeRequest{Reason: "policy violation"})
+	c.Params = gin.Params{{Key: "id", Value: userID.String()}}
+	c.Set("user_id", uuid.New().String())
+
+	handler.SuspendUser(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, models.UserStatusSuspended, response["data"].(map[string]interface{})["status"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestUserHandler_EraseUser(t *testing.T) {
+	logger := createTestLogger()
+	userID := uuid.New()
+
+	mockService := &MockUserService{}
+	mockService.On("EraseUser", mock.Anything, userID, "admin-token").Return(nil)
+
+	handler := NewUserHandlerWithInterface(mockService, logger)
+	c, w := createTestGinContext("POST", "/users/"+userID.String()+"/erase", nil)
+	c.Request.Header.Set("Authorization", "Bearer admin-token")
+	c.Params = gin.Params{{Key: "id", Value: userID.String()}}
+
+	handler.EraseUser(c)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
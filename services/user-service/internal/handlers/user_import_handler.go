@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/common/middleware"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxImportBodyBytes caps the size of a bulk import upload
+const maxImportBodyBytes = 10 << 20
+
+// exportFlushEvery is how many exported users are written between flushes to the client
+const exportFlushEvery = 100
+
+// exportCSVHeader is the header row of a CSV user export
+var exportCSVHeader = []string{"id", "email", "first_name", "last_name", "status", "email_verified_at", "created_at", "updated_at"}
+
+// importFormat picks the import file format from ?format= or, failing that, the Content-Type
+func importFormat(c *gin.Context) string {
+	if format := strings.ToLower(c.Query("format")); format != "" {
+		return format
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return models.ImportFormatCSV
+	case "application/x-ndjson", "application/jsonl":
+		return models.ImportFormatNDJSON
+	}
+	return ""
+}
+
+// ImportUsers creates users in bulk from a CSV or NDJSON upload (admin only).
+// Query parameters: format (csv|ndjson, defaults from Content-Type), mode (atomic|best_effort)
+// and dry_run. The response is a per-row report, also when rows failed.
+func (h *UserHandler) ImportUsers(c *gin.Context) {
+	span := trace.SpanFromContext(c.Request.Context())
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+	requestID := c.GetHeader("X-Request-ID")
+	actorUserID := middleware.GetAuthenticatedUserID(c)
+	ipAddress := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
+	opts := models.ImportUsersOptions{
+		Format: importFormat(c),
+		Mode:   c.Query("mode"),
+	}
+	if value := c.Query("dry_run"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			h.handleServiceError(c, models.NewValidationError("dry_run", "dry_run must be a boolean"), "Invalid import request", requestID)
+			return
+		}
+		opts.DryRun = dryRun
+	}
+
+	// Forwarded to auth-service to resolve and assign roles
+	jwtToken := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBodyBytes)
+	report, err := h.service.ImportUsers(c.Request.Context(), body, opts, jwtToken)
+	if err != nil {
+		h.handleServiceError(c, err, "Failed to import users", requestID)
+		h.auditLogger.LogAdminAction(actorUserID, requestID, "", ipAddress, userAgent, "import_users", traceID, spanID, false, err.Error())
+		return
+	}
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"mode":       report.Mode,
+		"dry_run":    report.DryRun,
+		"total":      report.Total,
+		"created":    report.Created,
+		"failed":     report.Failed,
+	}).Info("User import processed")
+	if !report.DryRun {
+		h.auditLogger.LogAdminAction(actorUserID, requestID, "", ipAddress, userAgent, "import_users", traceID, spanID, report.Failed == 0,
+			fmt.Sprintf("%d of %d rows failed", report.Failed, report.Total))
+	}
+
+	message := "Users imported"
+	if report.DryRun {
+		message = "Import validated, no users were created"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":    report,
+		"message": message,
+		"meta":    gin.H{"request_id": requestID},
+	})
+}
+
+// ExportUsers streams every user matching the listing filters as CSV or NDJSON (admin only).
+// It accepts the same email, name, status, role, created_after, created_before, sort and order
+// parameters as ListUsers; format selects csv or ndjson (the default).
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	span := trace.SpanFromContext(c.Request.Context())
+	traceID := span.SpanContext().TraceID().String()
+	spanID := span.SpanContext().SpanID().String()
+	requestID := c.GetHeader("X-Request-ID")
+	actorUserID := middleware.GetAuthenticatedUserID(c)
+
+	format := strings.ToLower(c.DefaultQuery("format", models.ImportFormatNDJSON))
+	if !models.IsValidImportFormat(format) {
+		h.handleServiceError(c, models.NewValidationError("format", "format must be csv or ndjson"), "Invalid export request", requestID)
+		return
+	}
+
+	query := &models.ListUsersQuery{
+		Email:  c.Query("email"),
+		Name:   c.Query("name"),
+		Status: c.Query("status"),
+		Role:   c.Query("role"),
+		Sort:   c.Query("sort"),
+		Order:  c.Query("order"),
+	}
+	for _, bound := range []struct {
+		field  string
+		target **time.Time
+	}{
+		{"created_after", &query.CreatedAfter},
+		{"created_before", &query.CreatedBefore},
+	} {
+		value := c.Query(bound.field)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.handleServiceError(c, models.NewValidationError(bound.field, "must be an RFC 3339 timestamp"), "Invalid export filter", requestID)
+			return
+		}
+		*bound.target = &t
+	}
+
+	// Forwarded to auth-service when filtering by role
+	jwtToken := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+	// Headers are only sent with the first user, so filter errors still get a normal error response
+	var csvWriter *csv.Writer
+	encoder := json.NewEncoder(c.Writer)
+	started := false
+	start := func() error {
+		started = true
+		c.Header("Content-Disposition", `attachment; filename="users.`+format+`"`)
+		if format == models.ImportFormatNDJSON {
+			c.Header("Content-Type", "application/x-ndjson")
+			c.Status(http.StatusOK)
+			return nil
+		}
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Status(http.StatusOK)
+		csvWriter = csv.NewWriter(c.Writer)
+		return csvWriter.Write(exportCSVHeader)
+	}
+
+	count := 0
+	emit := func(user *models.UserResponse) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if csvWriter != nil {
+			verifiedAt := ""
+			if user.EmailVerifiedAt != nil {
+				verifiedAt = user.EmailVerifiedAt.UTC().Format(time.RFC3339)
+			}
+			err = csvWriter.Write([]string{
+				user.ID.String(), user.Email, user.FirstName, user.LastName, user.Status, verifiedAt,
+				user.CreatedAt.UTC().Format(time.RFC3339), user.UpdatedAt.UTC().Format(time.RFC3339),
+			})
+		} else {
+			err = encoder.Encode(user)
+		}
+		if err != nil {
+			return err
+		}
+
+		count++
+		if count%exportFlushEvery == 0 {
+			if csvWriter != nil {
+				csvWriter.Flush()
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	}
+
+	err := h.service.ExportUsers(c.Request.Context(), query, jwtToken, emit)
+	if err == nil && !started {
+		// Nothing matched: still answer with an empty file in the requested format
+		err = start()
+	}
+	if csvWriter != nil {
+		csvWriter.Flush()
+	}
+	if err != nil {
+		if !started {
+			h.handleServiceError(c, err, "Failed to export users", requestID)
+		} else {
+			// The response is already streaming; the client sees a truncated file
+			h.logger.WithFields(logrus.Fields{
+				"request_id": requestID,
+				"exported":   count,
+			}).WithError(err).Error("User export aborted")
+		}
+		h.auditLogger.LogAdminAction(actorUserID, requestID, "", c.ClientIP(), c.GetHeader("User-Agent"), "export_users", traceID, spanID, false, err.Error())
+		return
+	}
+	c.Writer.Flush()
+
+	h.logger.WithFields(logrus.Fields{
+		"request_id": requestID,
+		"format":     format,
+		"exported":   count,
+	}).Info("Users exported")
+	h.auditLogger.LogAdminAction(actorUserID, requestID, "", c.ClientIP(), c.GetHeader("User-Agent"), "export_users", traceID, spanID, true, "")
+}
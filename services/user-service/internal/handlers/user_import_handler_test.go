@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
+)
+
+// createTestRawContext builds a test context with a raw (non-JSON) request body
+func createTestRawContext(method, path, contentType, body string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("X-Request-ID", "test-request-id")
+	req.Header.Set("Authorization", "Bearer admin-token")
+	c.Request = req
+
+	return c, w
+}
+
+func TestUserHandler_ImportUsers(t *testing.T) {
+	report := &models.ImportReport{Mode: models.ImportModeAtomic, Total: 1, Created: 1,
+		Rows: []*models.ImportRowResult{{Row: 1, Email: "ann@example.com", Status: models.ImportRowCreated}}}
+
+	tests := []struct {
+		name           string
+		path           string
+		contentType    string
+		setupMock      func(*MockUserService)
+		expectedStatus int
+		expectedField  string
+	}{
+		{
+			name:        "csv from content type",
+			path:        "/api/v1/users/import?mode=best_effort",
+			contentType: "text/csv; charset=utf-8",
+			setupMock: func(m *MockUserService) {
+				m.On("ImportUsers", mock.Anything, mock.Anything,
+					models.ImportUsersOptions{Format: models.ImportFormatCSV, Mode: models.ImportModeBestEffort}, "admin-token").
+					Return(report, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "format query overrides content type",
+			path:        "/api/v1/users/import?format=ndjson&dry_run=true",
+			contentType: "application/octet-stream",
+			setupMock: func(m *MockUserService) {
+				m.On("ImportUsers", mock.Anything, mock.Anything,
+					models.ImportUsersOptions{Format: models.ImportFormatNDJSON, DryRun: true}, "admin-token").
+					Return(report, nil).Once()
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid dry_run",
+			path:           "/api/v1/users/import?dry_run=maybe",
+			contentType:    "text/csv",
+			setupMock:      func(m *MockUserService) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "dry_run",
+		},
+		{
+			name:        "service rejects file",
+			path:        "/api/v1/users/import",
+			contentType: "text/csv",
+			setupMock: func(m *MockUserService) {
+				m.On("ImportUsers", mock.Anything, mock.Anything, mock.Anything, "admin-token").
+					Return(nil, models.NewValidationError("file", "import file is empty")).Once()
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedField:  "file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := new(MockUserService)
+			tt.setupMock(service)
+			handler := NewUserHandlerWithInterface(service, createTestLogger())
+
+			c, w := createTestRawContext("POST", tt.path, tt.contentType, "email,password,first_name,last_name\n")
+			handler.ImportUsers(c)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			var response map[string]interface{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			if tt.expectedField != "" {
+				assert.Equal(t, tt.expectedField, response["field"])
+			} else {
+				assert.Equal(t, float64(1), response["data"].(map[string]interface{})["created"])
+			}
+			service.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserHandler_ExportUsers(t *testing.T) {
+	createdAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	users := []*models.UserResponse{
+		{ID: uuid.New(), Email: "ann@example.com", FirstName: "Ann", LastName: "Smith", Status: models.UserStatusActive, CreatedAt: createdAt, UpdatedAt: createdAt},
+		{ID: uuid.New(), Email: "bob@example.com", FirstName: "Bob", LastName: "Jones", Status: models.UserStatusSuspended, CreatedAt: createdAt, UpdatedAt: createdAt},
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		service := new(MockUserService)
+		service.On("ExportUsers", mock.Anything, &models.ListUsersQuery{Status: models.UserStatusActive}, "admin-token", mock.Anything).
+			Return(users, nil).Once()
+		handler := NewUserHandlerWithInterface(service, createTestLogger())
+
+		c, w := createTestRawContext("GET", "/api/v1/users/export?format=csv&status=active", "", "")
+		handler.ExportUsers(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		assert.Len(t, lines, 3)
+		assert.Equal(t, "id,email,first_name,last_name,status,email_verified_at,created_at,updated_at", lines[0])
+		assert.Equal(t, users[0].ID.String()+",ann@example.com,Ann,Smith,active,,2024-03-01T12:00:00Z,2024-03-01T12:00:00Z", lines[1])
+		service.AssertExpectations(t)
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		service := new(MockUserService)
+		service.On("ExportUsers", mock.Anything, mock.Anything, "admin-token", mock.Anything).Return(users, nil).Once()
+		handler := NewUserHandlerWithInterface(service, createTestLogger())
+
+		c, w := createTestRawContext("GET", "/api/v1/users/export", "", "")
+		handler.ExportUsers(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		assert.Len(t, lines, 2)
+		var first models.UserResponse
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+		assert.Equal(t, "ann@example.com", first.Email)
+	})
+
+	t.Run("empty csv still has a header", func(t *testing.T) {
+		service := new(MockUserService)
+		service.On("ExportUsers", mock.Anything, mock.Anything, "admin-token", mock.Anything).Return(nil, nil).Once()
+		handler := NewUserHandlerWithInterface(service, createTestLogger())
+
+		c, w := createTestRawContext("GET", "/api/v1/users/export?format=csv", "", "")
+		handler.ExportUsers(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "id,email,first_name,last_name,status,email_verified_at,created_at,updated_at\n", w.Body.String())
+	})
+
+	t.Run("filter error before streaming", func(t *testing.T) {
+		service := new(MockUserService)
+		service.On("ExportUsers", mock.Anything, mock.Anything, "admin-token", mock.Anything).
+			Return(nil, models.NewValidationError("status", "unknown account status")).Once()
+		handler := NewUserHandlerWithInterface(service, createTestLogger())
+
+		c, w := createTestRawContext("GET", "/api/v1/users/export?status=bogus", "", "")
+		handler.ExportUsers(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "status", response["field"])
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		service := new(MockUserService)
+		handler := NewUserHandlerWithInterface(service, createTestLogger())
+
+		c, w := createTestRawContext("GET", "/api/v1/users/export?format=xml", "", "")
+		handler.ExportUsers(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		service.AssertNotCalled(t, "ExportUsers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("failure mid-stream truncates", func(t *testing.T) {
+		service := new(MockUserService)
+		service.On("ExportUsers", mock.Anything, mock.Anything, "admin-token", mock.Anything).
+			Return(users[:1], errors.New("connection reset")).Once()
+		handler := NewUserHandlerWithInterface(service, createTestLogger())
+
+		c, w := createTestRawContext("GET", "/api/v1/users/export", "", "")
+		handler.ExportUsers(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 1, strings.Count(w.Body.String(), "\n"))
+	})
+}
@@ -32,6 +32,14 @@ func (e NotFoundError) Error() string {
 	return fmt.Sprintf("%s with %s '%s' not found", e.Resource, e.Field, e.Value)
 }
 
+type ForbiddenError struct {
+	Reason string
+}
+
+func (e ForbiddenError) Error() string {
+	return fmt.Sprintf("forbidden: %s", e.Reason)
+}
+
 type InternalError struct {
 	Operation string
 	Err       error
@@ -58,6 +66,10 @@ func NewNotFoundError(resource, field, value string) NotFoundError {
 	return NotFoundError{Resource: resource, Field: field, Value: value}
 }
 
+func NewForbiddenError(reason string) ForbiddenError {
+	return ForbiddenError{Reason: reason}
+}
+
 func NewInternalError(operation string, err error) InternalError {
 	return InternalError{Operation: operation, Err: err}
 }
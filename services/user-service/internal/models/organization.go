@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Organization membership roles
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// Team membership roles
+const (
+	TeamRoleMaintainer = "maintainer"
+	TeamRoleMember     = "member"
+)
+
+// IsValidOrganizationRole reports whether role is a known organization membership role
+func IsValidOrganizationRole(role string) bool {
+	switch role {
+	case OrganizationRoleOwner, OrganizationRoleAdmin, OrganizationRoleMember:
+		return true
+	}
+	return false
+}
+
+// IsValidTeamRole reports whether role is a known team membership role
+func IsValidTeamRole(role string) bool {
+	switch role {
+	case TeamRoleMaintainer, TeamRoleMember:
+		return true
+	}
+	return false
+}
+
+// Organization is a tenant grouping users and teams
+type Organization struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name"`
+	Slug        string    `json:"slug" db:"slug"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Team is a group of organization members
+type Team struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	OrganizationID uuid.UUID `json:"organization_id" db:"organization_id"`
+	Name           string    `json:"name" db:"name"`
+	Description    string    `json:"description" db:"description"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// OrganizationMember is a user's membership in an organization or, when TeamID is set, in one of its teams
+type OrganizationMember struct {
+	OrganizationID uuid.UUID  `json:"organization_id" db:"organization_id"`
+	TeamID         *uuid.UUID `json:"team_id,omitempty" db:"team_id"`
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	Role           string     `json:"role" db:"role"`
+	Email          string     `json:"email,omitempty" db:"email"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Membership describes one organization or team a user belongs to.
+// auth-service embeds these in token claims and uses TeamID to resolve team-assigned roles.
+type Membership struct {
+	OrganizationID   uuid.UUID  `json:"organization_id"`
+	OrganizationSlug string     `json:"organization_slug"`
+	TeamID           *uuid.UUID `json:"team_id,omitempty"`
+	TeamName         string     `json:"team_name,omitempty"`
+	Role             string     `json:"role"`
+}
+
+type CreateOrganizationRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Slug        string `json:"slug" binding:"required,max=100"`
+	Description string `json:"description" binding:"omitempty,max=1000"`
+}
+
+type UpdateOrganizationRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+}
+
+type CreateTeamRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description" binding:"omitempty,max=1000"`
+}
+
+// AddMemberRequest adds a user to an organization or team, or changes the role of an existing member
+type AddMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Role   string    `json:"role" binding:"omitempty"`
+}
@@ -6,14 +6,51 @@ import (
 	"github.com/google/uuid"
 )
 
+// Account statuses. Suspended and deactivated accounts cannot log in or refresh tokens.
+const (
+	UserStatusPending     = "pending"
+	UserStatusActive      = "active"
+	UserStatusSuspended   = "suspended"
+	UserStatusDeactivated = "deactivated"
+)
+
+// IsValidUserStatus reports whether status is a known account status
+func IsValidUserStatus(status string) bool {
+	switch status {
+	case UserStatusPending, UserStatusActive, UserStatusSuspended, UserStatusDeactivated:
+		return true
+	}
+	return false
+}
+
+// CanTransitionStatus reports whether an account may move from one status to another
+func CanTransitionStatus(from, to string) bool {
+	switch to {
+	case UserStatusActive:
+		return from == UserStatusPending || from == UserStatusSuspended || from == UserStatusDeactivated
+	case UserStatusSuspended:
+		return from == UserStatusPending || from == UserStatusActive
+	case UserStatusDeactivated:
+		return from != UserStatusDeactivated && IsValidUserStatus(from)
+	}
+	return false
+}
+
 type User struct {
-	ID           uuid.UUID `json:"id" db:"id"`
-	Email        string    `json:"email" db:"email"`
-	PasswordHash string    `json:"-" db:"password_hash"` // json:"-" prevents accidental JSON exposure
-	FirstName    string    `json:"first_name" db:"first_name"`
-	LastName     string    `json:"last_name" db:"last_name"`
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID              uuid.UUID  `json:"id" db:"id"`
+	Email           string     `json:"email" db:"email"`
+	PasswordHash    string     `json:"-" db:"password_hash"` // json:"-" prevents accidental JSON exposure
+	FirstName       string     `json:"first_name" db:"first_name"`
+	LastName        string     `json:"last_name" db:"last_name"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+	Status          string     `json:"status" db:"status"`
+	StatusReason    *string    `json:"status_reason,omitempty" db:"status_reason"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty" db:"status_changed_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	PendingEmail    *string    `json:"pending_email,omitempty" db:"pending_email"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	ErasedAt        *time.Time `json:"erased_at,omitempty" db:"erased_at"`
 }
 
 type CreateUserRequest struct {
@@ -40,12 +77,44 @@ type ReplaceUserRequest struct {
 // to prevent accidental exposure in JSON responses. For authentication flows,
 // use UserLoginResponse which includes password data only where needed.
 type UserResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              uuid.UUID  `json:"id"`
+	Email           string     `json:"email"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	Status          string     `json:"status"`
+	StatusReason    *string    `json:"status_reason,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	PendingEmail    *string    `json:"pending_email,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// UpdateProfileRequest is the self-service profile update; email changes go through ChangeEmailRequest
+type UpdateProfileRequest struct {
+	FirstName string `json:"first_name" binding:"omitempty"`
+	LastName  string `json:"last_name" binding:"omitempty"`
+}
+
+// ChangeEmailRequest starts an email change that must be confirmed with a verification token
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" binding:"required,email"`
+}
+
+// ConfirmEmailChangeRequest completes a pending email change
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// EmailChangeResponse describes a pending email change awaiting verification
+type EmailChangeResponse struct {
+	PendingEmail string    `json:"pending_email"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// StatusChangeRequest carries the optional reason for an admin status change
+type StatusChangeRequest struct {
+	Reason string `json:"reason" binding:"omitempty,max=500"`
 }
 
 type UserLoginRequest struct {
@@ -60,3 +129,68 @@ type UserLoginResponse struct {
 	User         *UserResponse `json:"user"`
 	PasswordHash string        `json:"password_hash"`
 }
+
+// Sort fields accepted by the user listing
+const (
+	UserSortCreatedAt = "created_at"
+	UserSortUpdatedAt = "updated_at"
+	UserSortEmail     = "email"
+	UserSortFirstName = "first_name"
+	UserSortLastName  = "last_name"
+)
+
+// IsValidUserSortField reports whether field can be used to sort the user listing
+func IsValidUserSortField(field string) bool {
+	switch field {
+	case UserSortCreatedAt, UserSortUpdatedAt, UserSortEmail, UserSortFirstName, UserSortLastName:
+		return true
+	}
+	return false
+}
+
+// ListUsersQuery holds the search, filter, sort and pagination parameters of a user listing.
+// Cursor and Offset are mutually exclusive; a cursor is only valid for the sort it was issued with.
+type ListUsersQuery struct {
+	Email         string // case-insensitive email prefix
+	Name          string // case-insensitive first or last name prefix
+	Status        string
+	Role          string // role name, resolved to user IDs by auth-service
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Sort          string
+	Order         string // asc or desc
+	Limit         int
+	Offset        int
+	Cursor        string
+}
+
+// UserListFilter is the resolved form of ListUsersQuery passed to the repository
+type UserListFilter struct {
+	EmailPrefix   string
+	NamePrefix    string
+	Status        string
+	UserIDs       []uuid.UUID // nil means unrestricted; empty matches nobody
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	SortBy        string
+	Descending    bool
+	After         *UserCursor // keyset position; nil starts from the beginning
+	Limit         int
+	Offset        int
+}
+
+// UserCursor is the decoded keyset position of an opaque listing cursor:
+// the sort key and ID of the last user on the previous page.
+type UserCursor struct {
+	SortBy     string    `json:"s"`
+	Descending bool      `json:"d"`
+	Value      string    `json:"v"`
+	ID         uuid.UUID `json:"id"`
+}
+
+// UserListResult is one page of a user listing
+type UserListResult struct {
+	Users      []*UserResponse
+	Total      int
+	NextCursor string
+}
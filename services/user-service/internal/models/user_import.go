@@ -0,0 +1,78 @@
+package models
+
+import "github.com/google/uuid"
+
+// Bulk import modes
+const (
+	// ImportModeAtomic creates every row or none; any invalid row aborts the whole file
+	ImportModeAtomic = "atomic"
+	// ImportModeBestEffort creates the valid rows and reports the rest as failed
+	ImportModeBestEffort = "best_effort"
+)
+
+// Bulk import and export file formats
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+)
+
+// Per-row import outcomes
+const (
+	ImportRowCreated = "created" // the user was created
+	ImportRowValid   = "valid"   // dry run: the row would be created
+	ImportRowFailed  = "failed"  // the row is invalid or could not be created
+	ImportRowSkipped = "skipped" // the row is valid but was not created because the atomic import aborted
+)
+
+// MaxImportRows caps the number of data rows accepted in one import
+const MaxImportRows = 1000
+
+// ImportUsersOptions controls how an import file is processed
+type ImportUsersOptions struct {
+	Format string
+	Mode   string
+	DryRun bool
+}
+
+// ImportUserRow is one user parsed from an import file. In CSV files roles are separated by ";".
+type ImportUserRow struct {
+	Email     string   `json:"email"`
+	Password  string   `json:"password"`
+	FirstName string   `json:"first_name"`
+	LastName  string   `json:"last_name"`
+	Roles     []string `json:"roles,omitempty"`
+}
+
+// ImportRowResult reports the outcome of one import row. Row numbers are 1-based data rows,
+// not counting a CSV header.
+type ImportRowResult struct {
+	Row      int        `json:"row"`
+	Email    string     `json:"email,omitempty"`
+	Status   string     `json:"status"`
+	UserID   *uuid.UUID `json:"user_id,omitempty"`
+	Field    string     `json:"field,omitempty"`
+	Error    string     `json:"error,omitempty"`
+	Warnings []string   `json:"warnings,omitempty"`
+}
+
+// ImportReport summarizes a bulk import
+type ImportReport struct {
+	Mode    string             `json:"mode"`
+	DryRun  bool               `json:"dry_run"`
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Valid   int                `json:"valid"`
+	Failed  int                `json:"failed"`
+	Skipped int                `json:"skipped"`
+	Rows    []*ImportRowResult `json:"rows"`
+}
+
+// IsValidImportMode reports whether mode is a supported import mode
+func IsValidImportMode(mode string) bool {
+	return mode == ImportModeAtomic || mode == ImportModeBestEffort
+}
+
+// IsValidImportFormat reports whether format is a supported import/export format
+func IsValidImportFormat(format string) bool {
+	return format == ImportFormatCSV || format == ImportFormatNDJSON
+}
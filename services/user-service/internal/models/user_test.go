@@ -41,3 +41,26 @@ func TestCreateUserRequestValidation(t *testing.T) {
 		t.Error("First name should not be empty")
 	}
 }
+
+func TestCanTransitionStatus(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{UserStatusActive, UserStatusSuspended, true},
+		{UserStatusPending, UserStatusActive, true},
+		{UserStatusSuspended, UserStatusActive, true},
+		{UserStatusDeactivated, UserStatusActive, true},
+		{UserStatusActive, UserStatusDeactivated, true},
+		{UserStatusSuspended, UserStatusSuspended, false},
+		{UserStatusDeactivated, UserStatusSuspended, false},
+		{UserStatusActive, UserStatusActive, false},
+		{UserStatusActive, "unknown", false},
+	}
+
+	for _, tt := range tests {
+		if got := CanTransitionStatus(tt.from, tt.to); got != tt.allowed {
+			t.Errorf("CanTransitionStatus(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.allowed)
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/common/database"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
+)
+
+// organizationColumns is the column list scanned by scanOrganization, in order
+const organizationColumns = "id, name, slug, description, created_at, updated_at"
+
+// teamColumns is the column list scanned by scanTeam, in order
+const teamColumns = "id, organization_id, name, description, created_at, updated_at"
+
+func scanOrganization(row rowScanner, org *models.Organization) error {
+	return row.Scan(&org.ID, &org.Name, &org.Slug, &org.Description, &org.CreatedAt, &org.UpdatedAt)
+}
+
+func scanTeam(row rowScanner, team *models.Team) error {
+	return row.Scan(&team.ID, &team.OrganizationID, &team.Name, &team.Description, &team.CreatedAt, &team.UpdatedAt)
+}
+
+// OrganizationRepository stores organizations, their teams and memberships
+type OrganizationRepository struct {
+	db     DBInterface
+	logger *logrus.Logger
+}
+
+func NewOrganizationRepository(db *pgxpool.Pool, logger *logrus.Logger) *OrganizationRepository {
+	return &OrganizationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// NewOrganizationRepositoryWithInterface creates an OrganizationRepository with a database interface for testing
+func NewOrganizationRepositoryWithInterface(db DBInterface, logger *logrus.Logger) *OrganizationRepository {
+	return &OrganizationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *OrganizationRepository) CreateOrganization(ctx context.Context, org *models.Organization) (*models.Organization, error) {
+	query := `
+		INSERT INTO user_service.organizations (name, slug, description, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING ` + organizationColumns
+
+	err := database.TraceDBInsert(ctx, "user_service.organizations", query, func(ctx context.Context) error {
+		return scanOrganization(r.db.QueryRow(ctx, query, org.Name, org.Slug, org.Description), org)
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to create organization")
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	r.logger.WithField("organization_id", org.ID).Info("Organization created successfully")
+	return org, nil
+}
+
+func (r *OrganizationRepository) GetOrganization(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	query := `SELECT ` + organizationColumns + ` FROM user_service.organizations WHERE id = $1`
+
+	org := &models.Organization{}
+	err := database.TraceDBQuery(ctx, "user_service.organizations", query, func(ctx context.Context) error {
+		return scanOrganization(r.db.QueryRow(ctx, query, id), org)
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("organization not found")
+		}
+		r.logger.WithError(err).Error("Failed to get organization")
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return org, nil
+}
+
+func (r *OrganizationRepository) ListOrganizations(ctx context.Context, limit, offset int) ([]*models.Organization, error) {
+	query := `SELECT ` + organizationColumns + ` FROM user_service.organizations ORDER BY name, id LIMIT $1 OFFSET $2`
+
+	var orgs []*models.Organization
+	err := database.TraceDBQuery(ctx, "user_service.organizations", query, func(ctx context.Context) error {
+		rows, err := r.db.Query(ctx, query, limit, offset)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			org := &models.Organization{}
+			if err := scanOrganization(rows, org); err != nil {
+				return fmt.Errorf("failed to scan organization: %w", err)
+			}
+			orgs = append(orgs, org)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list organizations")
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	return orgs, nil
+}
+
+func (r *OrganizationRepository) UpdateOrganization(ctx context.Context, org *models.Organization) (*models.Organization, error) {
+	query := `
+		UPDATE user_service.organizations
+		SET name = $1, description = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING ` + organizationColumns
+
+	err := database.TraceDBUpdate(ctx, "user_service.organizations", query, func(ctx context.Context) error {
+		return scanOrganization(r.db.QueryRow(ctx, query, org.Name, org.Description, org.ID), org)
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("organization not found")
+		}
+		r.logger.WithError(err).Error("Failed to update organization")
+		return nil, fmt.Errorf("failed to update organization: %w", err)
+	}
+
+	return org, nil
+}
+
+// DeleteOrganization removes the organization with its teams and memberships
+func (r *OrganizationRepository) DeleteOrganization(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM user_service.organizations WHERE id = $1`
+	return r.execExpectingRow(ctx, "user_service.organizations", query, "organization not found", id)
+}
+
+// AddOrganizationMember adds the user to the organization or updates their role
+func (r *OrganizationRepository) AddOrganizationMember(ctx context.Context, orgID, userID uuid.UUID, role string) (*models.OrganizationMember, error) {
+	query := `
+		INSERT INTO user_service.organization_members (organization_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (organization_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING organization_id, user_id, role, created_at`
+
+	member := &models.OrganizationMember{}
+	err := database.TraceDBInsert(ctx, "user_service.organization_members", query, func(ctx context.Context) error {
+		return r.db.QueryRow(ctx, query, orgID, userID, role).Scan(&member.OrganizationID, &member.UserID, &member.Role, &member.CreatedAt)
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to add organization member")
+		return nil, fmt.Errorf("failed to add organization member: %w", err)
+	}
+
+	return member, nil
+}
+
+// RemoveOrganizationMember removes the user from the organization and all of its teams
+func (r *OrganizationRepository) RemoveOrganizationMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	query := `DELETE FROM user_service.organization_members WHERE organization_id = $1 AND user_id = $2`
+	return r.execExpectingRow(ctx, "user_service.organization_members", query, "membership not found", orgID, userID)
+}
+
+// ListOrganizationMembers returns the live users belonging to the organization
+func (r *OrganizationRepository) ListOrganizationMembers(ctx context.Context, orgID uuid.UUID) ([]*models.OrganizationMember, error) {
+	query := `
+		SELECT m.organization_id, m.user_id, m.role, u.email, m.created_at
+		FROM user_service.organization_members m
+		JOIN user_service.users u ON u.id = m.user_id AND u.deleted_at IS NULL
+		WHERE m.organization_id = $1
+		ORDER BY u.email`
+
+	var members []*models.OrganizationMember
+	err := database.TraceDBQuery(ctx, "user_service.organization_members", query, func(ctx context.Context) error {
+		rows, err := r.db.Query(ctx, query, orgID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			member := &models.OrganizationMember{}
+			if err := rows.Scan(&member.OrganizationID, &member.UserID, &member.Role, &member.Email, &member.CreatedAt); err != nil {
+				return fmt.Errorf("failed to scan organization member: %w", err)
+			}
+			members = append(members, member)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list organization members")
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+
+	return members, nil
+}
+
+func (r *OrganizationRepository) CreateTeam(ctx context.Context, team *models.Team) (*models.Team, error) {
+	query := `
+		INSERT INTO user_service.teams (organization_id, name, description, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		RETURNING ` + teamColumns
+
+	err := database.TraceDBInsert(ctx, "user_service.teams", query, func(ctx context.Context) error {
+		return scanTeam(r.db.QueryRow(ctx, query, team.OrganizationID, team.Name, team.Description), team)
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to create team")
+		return nil, fmt.Errorf("failed to create team: %w", err)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"organization_id": team.OrganizationID,
+		"team_id":         team.ID,
+	}).Info("Team created successfully")
+	return team, nil
+}
+
+func (r *OrganizationRepository) GetTeam(ctx context.Context, orgID, teamID uuid.UUID) (*models.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM user_service.teams WHERE id = $1 AND organization_id = $2`
+
+	team := &models.Team{}
+	err := database.TraceDBQuery(ctx, "user_service.teams", query, func(ctx context.Context) error {
+		return scanTeam(r.db.QueryRow(ctx, query, teamID, orgID), team)
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("team not found")
+		}
+		r.logger.WithError(err).Error("Failed to get team")
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+
+	return team, nil
+}
+
+func (r *OrganizationRepository) ListTeams(ctx context.Context, orgID uuid.UUID) ([]*models.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM user_service.teams WHERE organization_id = $1 ORDER BY name`
+
+	var teams []*models.Team
+	err := database.TraceDBQuery(ctx, "user_service.teams", query, func(ctx context.Context) error {
+		rows, err := r.db.Query(ctx, query, orgID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			team := &models.Team{}
+			if err := scanTeam(rows, team); err != nil {
+				return fmt.Errorf("failed to scan team: %w", err)
+			}
+			teams = append(teams, team)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list teams")
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+
+	return teams, nil
+}
+
+func (r *OrganizationRepository) DeleteTeam(ctx context.Context, orgID, teamID uuid.UUID) error {
+	query := `DELETE FROM user_service.teams WHERE id = $1 AND organization_id = $2`
+	return r.execExpectingRow(ctx, "user_service.teams", query, "team not found", teamID, orgID)
+}
+
+// AddTeamMember adds an organization member to the team or updates their team role.
+// The composite foreign key rejects users outside the team's organization.
+func (r *OrganizationRepository) AddTeamMember(ctx context.Context, orgID, teamID, userID uuid.UUID, role string) (*models.OrganizationMember, error) {
+	query := `
+		INSERT INTO user_service.team_members (team_id, organization_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (team_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		RETURNING team_id, organization_id, user_id, role, created_at`
+
+	member := &models.OrganizationMember{}
+	err := database.TraceDBInsert(ctx, "user_service.team_members", query, func(ctx context.Context) error {
+		return r.db.QueryRow(ctx, query, teamID, orgID, userID, role).Scan(&member.TeamID, &member.OrganizationID, &member.UserID, &member.Role, &member.CreatedAt)
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to add team member")
+		return nil, fmt.Errorf("failed to add team member: %w", err)
+	}
+
+	return member, nil
+}
+
+func (r *OrganizationRepository) RemoveTeamMember(ctx context.Context, orgID, teamID, userID uuid.UUID) error {
+	query := `DELETE FROM user_service.team_members WHERE team_id = $1 AND organization_id = $2 AND user_id = $3`
+	return r.execExpectingRow(ctx, "user_service.team_members", query, "membership not found", teamID, orgID, userID)
+}
+
+// ListTeamMembers returns the live users belonging to the team
+func (r *OrganizationRepository) ListTeamMembers(ctx context.Context, orgID, teamID uuid.UUID) ([]*models.OrganizationMember, error) {
+	query := `
+		SELECT m.organization_id, m.team_id, m.user_id, m.role, u.email, m.created_at
+		FROM user_service.team_members m
+		JOIN user_service.users u ON u.id = m.user_id AND u.deleted_at IS NULL
+		WHERE m.organization_id = $1 AND m.team_id = $2
+		ORDER BY u.email`
+
+	var members []*models.OrganizationMember
+	err := database.TraceDBQuery(ctx, "user_service.team_members", query, func(ctx context.Context) error {
+		rows, err := r.db.Query(ctx, query, orgID, teamID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			member := &models.OrganizationMember{}
+			if err := rows.Scan(&member.OrganizationID, &member.TeamID, &member.UserID, &member.Role, &member.Email, &member.CreatedAt); err != nil {
+				return fmt.Errorf("failed to scan team member: %w", err)
+			}
+			members = append(members, member)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list team members")
+		return nil, fmt.Errorf("failed to list team members: %w", err)
+	}
+
+	return members, nil
+}
+
+// ListUserMemberships returns every organization and team membership of the user,
+// organization memberships first. Soft-deleted users have none.
+func (r *OrganizationRepository) ListUserMemberships(ctx context.Context, userID uuid.UUID) ([]*models.Membership, error) {
+	query := `
+		SELECT o.id, o.slug, NULL::uuid, '', om.role
+		FROM user_service.organization_members om
+		JOIN user_service.organizations o ON o.id = om.organization_id
+		JOIN user_service.users u ON u.id = om.user_id AND u.deleted_at IS NULL
+		WHERE om.user_id = $1
+		UNION ALL
+		SELECT o.id, o.slug, t.id, t.name, tm.role
+		FROM user_service.team_members tm
+		JOIN user_service.teams t ON t.id = tm.team_id
+		JOIN user_service.organizations o ON o.id = tm.organization_id
+		JOIN user_service.users u ON u.id = tm.user_id AND u.deleted_at IS NULL
+		WHERE tm.user_id = $1
+		ORDER BY 2, 4`
+
+	var memberships []*models.Membership
+	err := database.TraceDBQuery(ctx, "user_service.organization_members,team_members", query, func(ctx context.Context) error {
+		rows, err := r.db.Query(ctx, query, userID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			m := &models.Membership{}
+			if err := rows.Scan(&m.OrganizationID, &m.OrganizationSlug, &m.TeamID, &m.TeamName, &m.Role); err != nil {
+				return fmt.Errorf("failed to scan membership: %w", err)
+			}
+			memberships = append(memberships, m)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to list user memberships")
+		return nil, fmt.Errorf("failed to list user memberships: %w", err)
+	}
+
+	return memberships, nil
+}
+
+// execExpectingRow runs a delete and reports notFound when it affected no rows
+func (r *OrganizationRepository) execExpectingRow(ctx context.Context, table, query, notFound string, args ...any) error {
+	var result pgconn.CommandTag
+	err := database.TraceDBDelete(ctx, table, query, func(ctx context.Context) error {
+		var execErr error
+		result, execErr = r.db.Exec(ctx, query, args...)
+		return execErr
+	})
+	if err != nil {
+		r.logger.WithError(err).WithField("table", table).Error("Failed to delete")
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s", notFound)
+	}
+
+	return nil
+}
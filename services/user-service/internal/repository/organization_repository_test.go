@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
+)
+
+func TestOrganizationRepository_GetOrganization_NotFound(t *testing.T) {
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...any) pgx.Row {
+			return &MockRow{}
+		},
+	}
+	repo := NewOrganizationRepositoryWithInterface(mockDB, createTestLogger())
+
+	org, err := repo.GetOrganization(context.Background(), uuid.New())
+
+	assert.Nil(t, org)
+	assert.EqualError(t, err, "organization not found")
+}
+
+func TestOrganizationRepository_RemoveTeamMember(t *testing.T) {
+	orgID, teamID, userID := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name          string
+		rowsAffected  int64
+		execErr       error
+		expectedError string
+	}{
+		{name: "removed", rowsAffected: 1},
+		{name: "not a member", rowsAffected: 0, expectedError: "membership not found"},
+		{name: "database error", execErr: errors.New("connection refused"), expectedError: "connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotArgs []any
+			mockDB := &MockDBPool{
+				ExecFunc: func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+					gotArgs = args
+					return newCommandTag(tt.rowsAffected), tt.execErr
+				},
+			}
+			repo := NewOrganizationRepositoryWithInterface(mockDB, createTestLogger())
+
+			err := repo.RemoveTeamMember(context.Background(), orgID, teamID, userID)
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, []any{teamID, orgID, userID}, gotArgs)
+		})
+	}
+}
+
+func TestOrganizationRepository_ListUserMemberships(t *testing.T) {
+	userID := uuid.New()
+	orgID := uuid.New()
+	teamID := uuid.New()
+
+	var gotSQL string
+	rows := &MockRows{
+		ScanResults: [][]any{
+			{orgID, "acme", nil, "", models.OrganizationRoleOwner},
+			{orgID, "acme", teamID, "Platform", models.TeamRoleMaintainer},
+		},
+	}
+	rows.ScanFunc = func(dest ...any) error {
+		values := rows.ScanResults[rows.ScanIndex-1]
+		*dest[0].(*uuid.UUID) = values[0].(uuid.UUID)
+		*dest[1].(*string) = values[1].(string)
+		if id, ok := values[2].(uuid.UUID); ok {
+			*dest[2].(**uuid.UUID) = &id
+		}
+		*dest[3].(*string) = values[3].(string)
+		*dest[4].(*string) = values[4].(string)
+		return nil
+	}
+	mockDB := &MockDBPool{
+		QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			gotSQL = sql
+			return rows, nil
+		},
+	}
+	repo := NewOrganizationRepositoryWithInterface(mockDB, createTestLogger())
+
+	memberships, err := repo.ListUserMemberships(context.Background(), userID)
+
+	assert.NoError(t, err)
+	assert.Contains(t, gotSQL, "u.deleted_at IS NULL")
+	assert.Len(t, memberships, 2)
+	assert.Nil(t, memberships[0].TeamID)
+	assert.Equal(t, models.OrganizationRoleOwner, memberships[0].Role)
+	assert.Equal(t, teamID, *memberships[1].TeamID)
+	assert.Equal(t, "Platform", memberships[1].TeamName)
+}
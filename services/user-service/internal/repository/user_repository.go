@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -22,6 +23,21 @@ type DBInterface interface {
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 }
 
+// userColumns is the column list scanned by scanUser, in order
+const userColumns = "id, email, password_hash, first_name, last_name, created_at, updated_at, status, status_reason, status_changed_at, email_verified_at, pending_email, deleted_at, erased_at"
+
+// rowScanner is satisfied by both pgx.Row and pgx.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser scans a row selected with userColumns
+func scanUser(row rowScanner, user *models.User) error {
+	return row.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt,
+		&user.Status, &user.StatusReason, &user.StatusChangedAt, &user.EmailVerifiedAt, &user.PendingEmail,
+		&user.DeletedAt, &user.ErasedAt)
+}
+
 type UserRepository struct {
 	db     DBInterface
 	logger *logrus.Logger
@@ -44,12 +60,16 @@ func NewUserRepositoryWithInterface(db DBInterface, logger *logrus.Logger) *User
 
 func (r *UserRepository) Create(ctx context.Context, user *models.User) (*models.User, error) {
 	query := `
-		INSERT INTO user_service.users (email, password_hash, first_name, last_name, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		INSERT INTO user_service.users (email, password_hash, first_name, last_name, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
 		RETURNING id, created_at, updated_at`
 
+	if user.Status == "" {
+		user.Status = models.UserStatusActive
+	}
+
 	err := database.TraceDBInsert(ctx, "user_service.users", query, func(ctx context.Context) error {
-		return r.db.QueryRow(ctx, query, user.Email, user.PasswordHash, user.FirstName, user.LastName).Scan(
+		return r.db.QueryRow(ctx, query, user.Email, user.PasswordHash, user.FirstName, user.LastName, user.Status).Scan(
 			&user.ID, &user.CreatedAt, &user.UpdatedAt)
 	})
 	if err != nil {
@@ -62,12 +82,11 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) (*models
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
-	query := `SELECT id, email, password_hash, first_name, last_name, created_at, updated_at FROM user_service.users WHERE id = $1`
+	query := `SELECT ` + userColumns + ` FROM user_service.users WHERE id = $1 AND deleted_at IS NULL`
 
 	user := &models.User{}
 	err := database.TraceDBQuery(ctx, "user_service.users", query, func(ctx context.Context) error {
-		return r.db.QueryRow(ctx, query, id).Scan(
-			&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt)
+		return scanUser(r.db.QueryRow(ctx, query, id), user)
 	})
 	if err != nil {
 		r.logger.WithError(err).WithField("error_type", fmt.Sprintf("%T", err)).Error("Failed to get user by ID - checking error type")
@@ -86,12 +105,11 @@ func (r *UserRepository) Update(ctx context.Context, id uuid.UUID, user *models.
 	query := `
 		UPDATE user_service.users
 		SET email = $1, password_hash = $2, first_name = $3, last_name = $4, updated_at = NOW()
-		WHERE id = $5
-		RETURNING id, email, password_hash, first_name, last_name, created_at, updated_at`
+		WHERE id = $5 AND deleted_at IS NULL
+		RETURNING ` + userColumns
 
 	err := database.TraceDBUpdate(ctx, "user_service.users", query, func(ctx context.Context) error {
-		return r.db.QueryRow(ctx, query, user.Email, user.PasswordHash, user.FirstName, user.LastName, id).Scan(
-			&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt)
+		return scanUser(r.db.QueryRow(ctx, query, user.Email, user.PasswordHash, user.FirstName, user.LastName, id), user)
 	})
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -105,8 +123,10 @@ func (r *UserRepository) Update(ctx context.Context, id uuid.UUID, user *models.
 	return user, nil
 }
 
+// Delete soft-deletes the user. The row is kept so references from other services stay valid
+// and the user can be restored within the retention window.
 func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM user_service.users WHERE id = $1`
+	query := `UPDATE user_service.users SET deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 
 	var result pgconn.CommandTag
 	err := database.TraceDBDelete(ctx, "user_service.users", query, func(ctx context.Context) error {
@@ -127,12 +147,88 @@ func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*models.User, error) {
-	query := `SELECT id, email, password_hash, first_name, last_name, created_at, updated_at FROM user_service.users ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+// userListTimestampSorts are the sort fields whose cursor value is an RFC 3339 timestamp
+var userListTimestampSorts = map[string]bool{
+	models.UserSortCreatedAt: true,
+	models.UserSortUpdatedAt: true,
+}
+
+// escapeLikePattern escapes LIKE wildcards so user input is matched literally
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// buildUserListConditions renders the filter (without the cursor) as WHERE conditions and arguments
+func buildUserListConditions(filter models.UserListFilter) ([]string, []any) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []any
+
+	if filter.EmailPrefix != "" {
+		args = append(args, escapeLikePattern(filter.EmailPrefix)+"%")
+		conditions = append(conditions, fmt.Sprintf("email ILIKE $%d", len(args)))
+	}
+	if filter.NamePrefix != "" {
+		args = append(args, escapeLikePattern(filter.NamePrefix)+"%")
+		conditions = append(conditions, fmt.Sprintf("(first_name ILIKE $%d OR last_name ILIKE $%d)", len(args), len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.CreatedAfter != nil {
+		args = append(args, *filter.CreatedAfter)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.CreatedBefore != nil {
+		args = append(args, *filter.CreatedBefore)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+	if filter.UserIDs != nil {
+		args = append(args, filter.UserIDs)
+		conditions = append(conditions, fmt.Sprintf("id = ANY($%d)", len(args)))
+	}
+
+	return conditions, args
+}
+
+// List returns one page of live users matching filter. Pages are ordered by the sort field with
+// the ID as tie-breaker, so a keyset cursor (filter.After) stays stable under concurrent inserts.
+func (r *UserRepository) List(ctx context.Context, filter models.UserListFilter) ([]*models.User, error) {
+	sortBy := filter.SortBy
+	if !models.IsValidUserSortField(sortBy) {
+		sortBy = models.UserSortCreatedAt
+	}
+	direction, comparison := "ASC", ">"
+	if filter.Descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	conditions, args := buildUserListConditions(filter)
+	if filter.After != nil {
+		var value any = filter.After.Value
+		if userListTimestampSorts[sortBy] {
+			t, err := time.Parse(time.RFC3339Nano, filter.After.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cursor value: %w", err)
+			}
+			value = t
+		}
+		args = append(args, value, filter.After.ID)
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s ($%d, $%d)", sortBy, comparison, len(args)-1, len(args)))
+	}
+
+	query := `SELECT ` + userColumns + ` FROM user_service.users WHERE ` + strings.Join(conditions, " AND ") +
+		fmt.Sprintf(" ORDER BY %s %s, id %s", sortBy, direction, direction)
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" LIMIT $%d", len(args))
+	if filter.After == nil && filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
 
 	var users []*models.User
 	err := database.TraceDBQuery(ctx, "user_service.users", query, func(ctx context.Context) error {
-		rows, err := r.db.Query(ctx, query, limit, offset)
+		rows, err := r.db.Query(ctx, query, args...)
 		if err != nil {
 			return err
 		}
@@ -140,8 +236,7 @@ func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*models
 
 		for rows.Next() {
 			user := &models.User{}
-			err := rows.Scan(&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt)
-			if err != nil {
+			if err := scanUser(rows, user); err != nil {
 				return fmt.Errorf("failed to scan user: %w", err)
 			}
 			users = append(users, user)
@@ -157,13 +252,29 @@ func (r *UserRepository) List(ctx context.Context, limit, offset int) ([]*models
 	return users, nil
 }
 
+// Count returns the number of live users matching filter, ignoring its cursor and pagination
+func (r *UserRepository) Count(ctx context.Context, filter models.UserListFilter) (int, error) {
+	conditions, args := buildUserListConditions(filter)
+	query := `SELECT COUNT(*) FROM user_service.users WHERE ` + strings.Join(conditions, " AND ")
+
+	var count int
+	err := database.TraceDBQuery(ctx, "user_service.users", query, func(ctx context.Context) error {
+		return r.db.QueryRow(ctx, query, args...).Scan(&count)
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to count users")
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	return count, nil
+}
+
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := `SELECT id, email, password_hash, first_name, last_name, created_at, updated_at FROM user_service.users WHERE email = $1`
+	query := `SELECT ` + userColumns + ` FROM user_service.users WHERE email = $1 AND deleted_at IS NULL`
 
 	user := &models.User{}
 	err := database.TraceDBQuery(ctx, "user_service.users", query, func(ctx context.Context) error {
-		return r.db.QueryRow(ctx, query, email).Scan(
-			&user.ID, &user.Email, &user.PasswordHash, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt)
+		return scanUser(r.db.QueryRow(ctx, query, email), user)
 	})
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -175,3 +286,257 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 
 	return user, nil
 }
+
+// UpdateStatus changes the account status and records why and when it changed
+func (r *UserRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status, reason string) (*models.User, error) {
+	query := `
+		UPDATE user_service.users
+		SET status = $1, status_reason = NULLIF($2, ''), status_changed_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL
+		RETURNING ` + userColumns
+
+	user := &models.User{}
+	err := database.TraceDBUpdate(ctx, "user_service.users", query, func(ctx context.Context) error {
+		return scanUser(r.db.QueryRow(ctx, query, status, reason, id), user)
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		r.logger.WithError(err).Error("Failed to update user status")
+		return nil, fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	r.logger.WithFields(logrus.Fields{
+		"user_id": id,
+		"status":  status,
+	}).Info("User status updated successfully")
+	return user, nil
+}
+
+// SetPendingEmail stores an unconfirmed email address together with the hash of its verification token.
+// A new request replaces any earlier pending change.
+func (r *UserRepository) SetPendingEmail(ctx context.Context, id uuid.UUID, email, tokenHash string, expiresAt time.Time) error {
+	query := `
+		UPDATE user_service.users
+		SET pending_email = $1, email_verification_token_hash = $2, email_verification_expires_at = $3, updated_at = NOW()
+		WHERE id = $4 AND deleted_at IS NULL`
+
+	var result pgconn.CommandTag
+	err := database.TraceDBUpdate(ctx, "user_service.users", query, func(ctx context.Context) error {
+		var execErr error
+		result, execErr = r.db.Exec(ctx, query, email, tokenHash, expiresAt, id)
+		return execErr
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to store pending email")
+		return fmt.Errorf("failed to store pending email: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// ConfirmPendingEmail swaps in the pending email when tokenHash matches an unexpired verification token
+func (r *UserRepository) ConfirmPendingEmail(ctx context.Context, id uuid.UUID, tokenHash string) (*models.User, error) {
+	query := `
+		UPDATE user_service.users
+		SET email = pending_email, pending_email = NULL, email_verification_token_hash = NULL,
+			email_verification_expires_at = NULL, email_verified_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+			AND deleted_at IS NULL
+			AND pending_email IS NOT NULL
+			AND email_verification_token_hash = $2
+			AND email_verification_expires_at > NOW()
+		RETURNING ` + userColumns
+
+	user := &models.User{}
+	err := database.TraceDBUpdate(ctx, "user_service.users", query, func(ctx context.Context) error {
+		return scanUser(r.db.QueryRow(ctx, query, id, tokenHash), user)
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("verification token not found")
+		}
+		r.logger.WithError(err).Error("Failed to confirm pending email")
+		return nil, fmt.Errorf("failed to confirm pending email: %w", err)
+	}
+
+	r.logger.WithField("user_id", id).Info("User email changed successfully")
+	return user, nil
+}
+
+// GetByIDWithDeleted returns the user whether or not it has been soft-deleted
+func (r *UserRepository) GetByIDWithDeleted(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM user_service.users WHERE id = $1`
+
+	user := &models.User{}
+	err := database.TraceDBQuery(ctx, "user_service.users", query, func(ctx context.Context) error {
+		return scanUser(r.db.QueryRow(ctx, query, id), user)
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		r.logger.WithError(err).Error("Failed to get user by ID including deleted")
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// Restore clears deleted_at for a user deleted after deletedAfter that has not been erased
+func (r *UserRepository) Restore(ctx context.Context, id uuid.UUID, deletedAfter time.Time) (*models.User, error) {
+	query := `
+		UPDATE user_service.users
+		SET deleted_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NOT NULL AND deleted_at > $2 AND erased_at IS NULL
+		RETURNING ` + userColumns
+
+	user := &models.User{}
+	err := database.TraceDBUpdate(ctx, "user_service.users", query, func(ctx context.Context) error {
+		return scanUser(r.db.QueryRow(ctx, query, id, deletedAfter), user)
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		r.logger.WithError(err).Error("Failed to restore user")
+		return nil, fmt.Errorf("failed to restore user: %w", err)
+	}
+
+	r.logger.WithField("user_id", id).Info("User restored successfully")
+	return user, nil
+}
+
+// Erase irreversibly anonymizes the user's personal data and removes their profile and settings.
+// The row itself stays (soft-deleted) so IDs referenced elsewhere remain resolvable.
+func (r *UserRepository) Erase(ctx context.Context, id uuid.UUID, anonymizedEmail string) (*models.User, error) {
+	// A single statement keeps the cleanup atomic
+	query := `
+		WITH deleted_profiles AS (
+			DELETE FROM user_service.user_profiles WHERE user_id = $1
+		), deleted_settings AS (
+			DELETE FROM user_service.user_settings WHERE user_id = $1
+		), deleted_memberships AS (
+			-- Cascades to team_members
+			DELETE FROM user_service.organization_members WHERE user_id = $1
+		)
+		UPDATE user_service.users
+		SET email = $2, password_hash = '', first_name = '', last_name = '',
+			status = 'deactivated', status_reason = NULL, status_changed_at = NOW(),
+			email_verified_at = NULL, pending_email = NULL,
+			email_verification_token_hash = NULL, email_verification_expires_at = NULL,
+			deleted_at = COALESCE(deleted_at, NOW()), erased_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND erased_at IS NULL
+		RETURNING ` + userColumns
+
+	user := &models.User{}
+	err := database.TraceDBUpdate(ctx, "user_service.users", query, func(ctx context.Context) error {
+		return scanUser(r.db.QueryRow(ctx, query, id, anonymizedEmail), user)
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		r.logger.WithError(err).Error("Failed to erase user")
+		return nil, fmt.Errorf("failed to erase user: %w", err)
+	}
+
+	r.logger.WithField("user_id", id).Info("User erased successfully")
+	return user, nil
+}
+
+// ExistingEmails returns which of emails already belong to live users
+func (r *UserRepository) ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	if len(emails) == 0 {
+		return existing, nil
+	}
+
+	query := `SELECT email FROM user_service.users WHERE email = ANY($1) AND deleted_at IS NULL`
+
+	err := database.TraceDBQuery(ctx, "user_service.users", query, func(ctx context.Context) error {
+		rows, err := r.db.Query(ctx, query, emails)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var email string
+			if err := rows.Scan(&email); err != nil {
+				return fmt.Errorf("failed to scan email: %w", err)
+			}
+			existing[email] = true
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to look up existing emails")
+		return nil, fmt.Errorf("failed to look up existing emails: %w", err)
+	}
+
+	return existing, nil
+}
+
+// CreateBatch inserts users in a single statement, so either all of them are created or none.
+// IDs and timestamps are filled in on the passed users.
+func (r *UserRepository) CreateBatch(ctx context.Context, users []*models.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO user_service.users (email, password_hash, first_name, last_name, status, created_at, updated_at)
+		SELECT email, password_hash, first_name, last_name, $5, NOW(), NOW()
+		FROM unnest($1::text[], $2::text[], $3::text[], $4::text[]) AS u(email, password_hash, first_name, last_name)
+		RETURNING id, email, created_at, updated_at`
+
+	emails := make([]string, len(users))
+	hashes := make([]string, len(users))
+	firstNames := make([]string, len(users))
+	lastNames := make([]string, len(users))
+	byEmail := make(map[string]*models.User, len(users))
+	for i, user := range users {
+		user.Status = models.UserStatusActive
+		emails[i], hashes[i], firstNames[i], lastNames[i] = user.Email, user.PasswordHash, user.FirstName, user.LastName
+		byEmail[user.Email] = user
+	}
+
+	err := database.TraceDBInsert(ctx, "user_service.users", query, func(ctx context.Context) error {
+		rows, err := r.db.Query(ctx, query, emails, hashes, firstNames, lastNames, models.UserStatusActive)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		// Emails are unique among live users, so they identify the returned rows
+		for rows.Next() {
+			var (
+				id                   uuid.UUID
+				email                string
+				createdAt, updatedAt time.Time
+			)
+			if err := rows.Scan(&id, &email, &createdAt, &updatedAt); err != nil {
+				return fmt.Errorf("failed to scan created user: %w", err)
+			}
+			if user, ok := byEmail[email]; ok {
+				user.ID, user.CreatedAt, user.UpdatedAt = id, createdAt, updatedAt
+			}
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.WithError(err).Error("Failed to create users")
+		return fmt.Errorf("failed to create users: %w", err)
+	}
+
+	r.logger.WithField("count", len(users)).Info("Users created successfully")
+	return nil
+}
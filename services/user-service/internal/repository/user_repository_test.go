@@ -529,8 +529,8 @@ func TestUserRepository_List(t *testing.T) {
 			offset: 0,
 			mockRows: &MockRows{
 				ScanResults: [][]any{
-					{userID1, "user1@example.com", "hash1", "John", "Doe", createdAt, updatedAt},
-					{userID2, "user2@example.com", "hash2", "Jane", "Smith", createdAt, updatedAt},
+					{userID1, "user1@example.com", "hash1", "John", "Doe", createdAt, updatedAt, models.UserStatusActive, nil, nil, nil, nil, nil, nil},
+					{userID2, "user2@example.com", "hash2", "Jane", "Smith", createdAt, updatedAt, models.UserStatusSuspended, nil, nil, nil, nil, nil, nil},
 				},
 			},
 			expectedUsers: []*models.User{
@@ -542,6 +542,7 @@ func TestUserRepository_List(t *testing.T) {
 					LastName:     "Doe",
 					CreatedAt:    createdAt,
 					UpdatedAt:    updatedAt,
+					Status:       models.UserStatusActive,
 				},
 				{
 					ID:           userID2,
@@ -551,6 +552,7 @@ func TestUserRepository_List(t *testing.T) {
 					LastName:     "Smith",
 					CreatedAt:    createdAt,
 					UpdatedAt:    updatedAt,
+					Status:       models.UserStatusSuspended,
 				},
 			},
 			expectError: false,
@@ -593,7 +595,7 @@ func TestUserRepository_List(t *testing.T) {
 			repo := NewUserRepositoryWithInterface(mockDB, createTestLogger())
 
 			// Execute
-			result, err := repo.List(context.Background(), tt.limit, tt.offset)
+			result, err := repo.List(context.Background(), models.UserListFilter{SortBy: models.UserSortCreatedAt, Descending: true, Limit: tt.limit, Offset: tt.offset})
 
 			// Assert
 			if tt.expectError {
@@ -607,12 +609,93 @@ func TestUserRepository_List(t *testing.T) {
 					assert.Equal(t, expected.Email, result[i].Email)
 					assert.Equal(t, expected.FirstName, result[i].FirstName)
 					assert.Equal(t, expected.LastName, result[i].LastName)
+					assert.Equal(t, expected.Status, result[i].Status)
 				}
 			}
 		})
 	}
 }
 
+func TestUserRepository_List_FilterAndCursorQuery(t *testing.T) {
+	cursorID := uuid.New()
+	memberID := uuid.New()
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var capturedSQL string
+	var capturedArgs []any
+	mockDB := &MockDBPool{
+		QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+			capturedSQL = sql
+			capturedArgs = args
+			return &MockRows{ScanResults: [][]any{}}, nil
+		},
+	}
+	repo := NewUserRepositoryWithInterface(mockDB, createTestLogger())
+
+	_, err := repo.List(context.Background(), models.UserListFilter{
+		EmailPrefix:  "jo_e",
+		Status:       models.UserStatusActive,
+		CreatedAfter: &after,
+		UserIDs:      []uuid.UUID{memberID},
+		SortBy:       models.UserSortCreatedAt,
+		Descending:   true,
+		After:        &models.UserCursor{SortBy: models.UserSortCreatedAt, Descending: true, Value: "2024-05-01T12:00:00.5Z", ID: cursorID},
+		Limit:        20,
+		Offset:       40,
+	})
+	assert.NoError(t, err)
+
+	assert.Contains(t, capturedSQL, "deleted_at IS NULL")
+	assert.Contains(t, capturedSQL, "email ILIKE $1")
+	assert.Contains(t, capturedSQL, "status = $2")
+	assert.Contains(t, capturedSQL, "created_at >= $3")
+	assert.Contains(t, capturedSQL, "id = ANY($4)")
+	assert.Contains(t, capturedSQL, "(created_at, id) < ($5, $6)")
+	assert.Contains(t, capturedSQL, "ORDER BY created_at DESC, id DESC LIMIT $7")
+	assert.NotContains(t, capturedSQL, "OFFSET", "a cursor replaces the offset")
+	assert.Equal(t, []any{`jo\_e%`, models.UserStatusActive, after, []uuid.UUID{memberID},
+		time.Date(2024, 5, 1, 12, 0, 0, 500000000, time.UTC), cursorID, 20}, capturedArgs)
+}
+
+func TestUserRepository_List_InvalidCursorTimestamp(t *testing.T) {
+	repo := NewUserRepositoryWithInterface(&MockDBPool{}, createTestLogger())
+
+	_, err := repo.List(context.Background(), models.UserListFilter{
+		SortBy: models.UserSortUpdatedAt,
+		After:  &models.UserCursor{SortBy: models.UserSortUpdatedAt, Value: "not-a-time", ID: uuid.New()},
+		Limit:  10,
+	})
+	assert.Error(t, err)
+}
+
+func TestUserRepository_Count(t *testing.T) {
+	var capturedSQL string
+	var capturedArgs []any
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...any) pgx.Row {
+			capturedSQL = sql
+			capturedArgs = args
+			return &MockRow{ScanFunc: func(dest ...any) error {
+				*dest[0].(*int) = 42
+				return nil
+			}}
+		},
+	}
+	repo := NewUserRepositoryWithInterface(mockDB, createTestLogger())
+
+	count, err := repo.Count(context.Background(), models.UserListFilter{
+		NamePrefix: "Ann",
+		After:      &models.UserCursor{ID: uuid.New()},
+		Limit:      10,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 42, count)
+	assert.Contains(t, capturedSQL, "(first_name ILIKE $1 OR last_name ILIKE $1)")
+	assert.NotContains(t, capturedSQL, "LIMIT")
+	assert.Equal(t, []any{"Ann%"}, capturedArgs)
+}
+
 func TestUserRepository_GetByEmail(t *testing.T) {
 	userID := uuid.New()
 	createdAt := time.Now()
@@ -705,3 +788,336 @@ func TestUserRepository_GetByEmail(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepository_UpdateStatus(t *testing.T) {
+	userID := uuid.New()
+	changedAt := time.Now()
+
+	tests := []struct {
+		name           string
+		status         string
+		reason         string
+		mockRow        *MockRow
+		expectedStatus string
+		expectError    bool
+		expectedError  string
+	}{
+		{
+			name:   "successful suspension",
+			status: models.UserStatusSuspended,
+			reason: "terms violation",
+			mockRow: &MockRow{
+				ScanFunc: func(dest ...any) error {
+					*dest[0].(*uuid.UUID) = userID
+					*dest[1].(*string) = "test@example.com"
+					*dest[7].(*string) = models.UserStatusSuspended
+					reason := "terms violation"
+					*dest[8].(**string) = &reason
+					*dest[9].(**time.Time) = &changedAt
+					return nil
+				},
+			},
+			expectedStatus: models.UserStatusSuspended,
+			expectError:    false,
+		},
+		{
+			name:   "user not found",
+			status: models.UserStatusActive,
+			mockRow: &MockRow{
+				ScanFunc: func(dest ...any) error {
+					return pgx.ErrNoRows
+				},
+			},
+			expectError:   true,
+			expectedError: "user not found",
+		},
+		{
+			name:   "database error",
+			status: models.UserStatusActive,
+			mockRow: &MockRow{
+				ScanFunc: func(dest ...any) error {
+					return errors.New("database connection failed")
+				},
+			},
+			expectError:   true,
+			expectedError: "failed to update user status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotArgs []any
+			mockDB := &MockDBPool{
+				QueryRowFunc: func(ctx context.Context, sql string, args ...any) pgx.Row {
+					gotArgs = args
+					return tt.mockRow
+				},
+			}
+
+			repo := NewUserRepositoryWithInterface(mockDB, createTestLogger())
+
+			result, err := repo.UpdateStatus(context.Background(), userID, tt.status, tt.reason)
+
+			assert.Equal(t, []any{tt.status, tt.reason, userID}, gotArgs)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, result.Status)
+				assert.NotNil(t, result.StatusReason)
+				assert.Equal(t, tt.reason, *result.StatusReason)
+				assert.NotNil(t, result.StatusChangedAt)
+			}
+		})
+	}
+}
+
+func TestUserRepository_ConfirmPendingEmail(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name          string
+		mockRow       *MockRow
+		expectedEmail string
+		expectedError string
+	}{
+		{
+			name: "pending email confirmed",
+			mockRow: &MockRow{
+				ScanFunc: func(dest ...any) error {
+					*dest[0].(*uuid.UUID) = userID
+					*dest[1].(*string) = "new@example.com"
+					return nil
+				},
+			},
+			expectedEmail: "new@example.com",
+		},
+		{
+			name: "token invalid or expired",
+			mockRow: &MockRow{
+				ScanFunc: func(dest ...any) error {
+					return pgx.ErrNoRows
+				},
+			},
+			expectedError: "verification token not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := &MockDBPool{
+				QueryRowFunc: func(ctx context.Context, sql string, args ...any) pgx.Row {
+					return tt.mockRow
+				},
+			}
+
+			repo := NewUserRepositoryWithInterface(mockDB, createTestLogger())
+
+			result, err := repo.ConfirmPendingEmail(context.Background(), userID, "token-hash")
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedEmail, result.Email)
+			}
+		})
+	}
+}
+
+func TestUserRepository_Delete_IsSoftDelete(t *testing.T) {
+	var gotSQL string
+	mockDB := &MockDBPool{
+		ExecFunc: func(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+			gotSQL = sql
+			return newCommandTag(1), nil
+		},
+	}
+
+	repo := NewUserRepositoryWithInterface(mockDB, createTestLogger())
+
+	err := repo.Delete(context.Background(), uuid.New())
+
+	assert.NoError(t, err)
+	assert.Contains(t, gotSQL, "SET deleted_at = NOW()")
+	assert.NotContains(t, gotSQL, "DELETE FROM")
+}
+
+func TestUserRepository_Restore(t *testing.T) {
+	userID := uuid.New()
+	cutoff := time.Now().Add(-30 * 24 * time.Hour)
+
+	tests := []struct {
+		name          string
+		mockRow       *MockRow
+		expectError   bool
+		expectedError string
+	}{
+		{
+			name: "successful restore",
+			mockRow: &MockRow{
+				ScanFunc: func(dest ...any) error {
+					*dest[0].(*uuid.UUID) = userID
+					*dest[7].(*string) = models.UserStatusActive
+					return nil
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "not deleted, erased or outside window",
+			mockRow: &MockRow{
+				ScanFunc: func(dest ...any) error {
+					return pgx.ErrNoRows
+				},
+			},
+			expectError:   true,
+			expectedError: "user not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotArgs []any
+			mockDB := &MockDBPool{
+				QueryRowFunc: func(ctx context.Context, sql string, args ...any) pgx.Row {
+					gotArgs = args
+					return tt.mockRow
+				},
+			}
+
+			repo := NewUserRepositoryWithInterface(mockDB, createTestLogger())
+
+			result, err := repo.Restore(context.Background(), userID, cutoff)
+
+			assert.Equal(t, []any{userID, cutoff}, gotArgs)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, userID, result.ID)
+				assert.Nil(t, result.DeletedAt)
+			}
+		})
+	}
+}
+
+func TestUserRepository_Erase(t *testing.T) {
+	userID := uuid.New()
+	erasedAt := time.Now()
+	anonymized := "erased-" + userID.String() + "@erased.invalid"
+
+	var gotSQL string
+	var gotArgs []any
+	mockDB := &MockDBPool{
+		QueryRowFunc: func(ctx context.Context, sql string, args ...any) pgx.Row {
+			gotSQL = sql
+			gotArgs = args
+			return &MockRow{
+				ScanFunc: func(dest ...any) error {
+					*dest[0].(*uuid.UUID) = userID
+					*dest[1].(*string) = anonymized
+					*dest[7].(*string) = models.UserStatusDeactivated
+					*dest[12].(**time.Time) = &erasedAt
+					*dest[13].(**time.Time) = &erasedAt
+					return nil
+				},
+			}
+		},
+	}
+
+	repo := NewUserRepositoryWithInterface(mockDB, createTestLogger())
+
+	result, err := repo.Erase(context.Background(), userID, anonymized)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []any{userID, anonymized}, gotArgs)
+	assert.Contains(t, gotSQL, "DELETE FROM user_service.user_profiles")
+	assert.Contains(t, gotSQL, "DELETE FROM user_service.user_settings")
+	assert.Contains(t, gotSQL, "DELETE FROM user_service.organization_members")
+	assert.Equal(t, anonymized, result.Email)
+	assert.NotNil(t, result.ErasedAt)
+}
+
+func TestUserRepository_ExistingEmails(t *testing.T) {
+	t.Run("returns taken emails", func(t *testing.T) {
+		mockDB := &MockDBPool{
+			QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+				assert.Contains(t, sql, "email = ANY($1)")
+				assert.Contains(t, sql, "deleted_at IS NULL")
+				assert.Equal(t, []string{"ann@example.com", "bob@example.com"}, args[0])
+				return &MockRows{ScanResults: [][]any{{"bob@example.com"}}}, nil
+			},
+		}
+		repo := NewUserRepositoryWithInterface(mockDB, createTestLogger())
+
+		existing, err := repo.ExistingEmails(context.Background(), []string{"ann@example.com", "bob@example.com"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"bob@example.com": true}, existing)
+	})
+
+	t.Run("no emails skips the query", func(t *testing.T) {
+		mockDB := &MockDBPool{
+			QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+				t.Fatal("unexpected query")
+				return nil, nil
+			},
+		}
+		repo := NewUserRepositoryWithInterface(mockDB, createTestLogger())
+
+		existing, err := repo.ExistingEmails(context.Background(), nil)
+		assert.NoError(t, err)
+		assert.Empty(t, existing)
+	})
+}
+
+func TestUserRepository_CreateBatch(t *testing.T) {
+	annID, bobID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	t.Run("fills in generated columns", func(t *testing.T) {
+		mockDB := &MockDBPool{
+			QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+				assert.Contains(t, sql, "unnest(")
+				assert.Equal(t, []string{"ann@example.com", "bob@example.com"}, args[0])
+				assert.Equal(t, []string{"hash-a", "hash-b"}, args[1])
+				assert.Equal(t, models.UserStatusActive, args[4])
+				// Returned rows are matched by email, not by position
+				return &MockRows{ScanResults: [][]any{
+					{bobID, "bob@example.com", now, now},
+					{annID, "ann@example.com", now, now},
+				}}, nil
+			},
+		}
+		repo := NewUserRepositoryWithInterface(mockDB, createTestLogger())
+
+		users := []*models.User{
+			{Email: "ann@example.com", PasswordHash: "hash-a", FirstName: "Ann", LastName: "Smith"},
+			{Email: "bob@example.com", PasswordHash: "hash-b", FirstName: "Bob", LastName: "Jones"},
+		}
+		err := repo.CreateBatch(context.Background(), users)
+		assert.NoError(t, err)
+		assert.Equal(t, annID, users[0].ID)
+		assert.Equal(t, bobID, users[1].ID)
+		assert.Equal(t, models.UserStatusActive, users[0].Status)
+	})
+
+	t.Run("statement failure creates nothing", func(t *testing.T) {
+		mockDB := &MockDBPool{
+			QueryFunc: func(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+				return nil, errors.New("duplicate key value violates unique constraint")
+			},
+		}
+		repo := NewUserRepositoryWithInterface(mockDB, createTestLogger())
+
+		err := repo.CreateBatch(context.Background(), []*models.User{{Email: "ann@example.com"}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "duplicate key")
+	})
+}
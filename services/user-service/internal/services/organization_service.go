@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/repository"
+)
+
+// OrganizationRepositoryInterface defines the repository operations needed by OrganizationService
+type OrganizationRepositoryInterface interface {
+	CreateOrganization(ctx context.Context, org *models.Organization) (*models.Organization, error)
+	GetOrganization(ctx context.Context, id uuid.UUID) (*models.Organization, error)
+	ListOrganizations(ctx context.Context, limit, offset int) ([]*models.Organization, error)
+	UpdateOrganization(ctx context.Context, org *models.Organization) (*models.Organization, error)
+	DeleteOrganization(ctx context.Context, id uuid.UUID) error
+	AddOrganizationMember(ctx context.Context, orgID, userID uuid.UUID, role string) (*models.OrganizationMember, error)
+	RemoveOrganizationMember(ctx context.Context, orgID, userID uuid.UUID) error
+	ListOrganizationMembers(ctx context.Context, orgID uuid.UUID) ([]*models.OrganizationMember, error)
+	CreateTeam(ctx context.Context, team *models.Team) (*models.Team, error)
+	GetTeam(ctx context.Context, orgID, teamID uuid.UUID) (*models.Team, error)
+	ListTeams(ctx context.Context, orgID uuid.UUID) ([]*models.Team, error)
+	DeleteTeam(ctx context.Context, orgID, teamID uuid.UUID) error
+	AddTeamMember(ctx context.Context, orgID, teamID, userID uuid.UUID, role string) (*models.OrganizationMember, error)
+	RemoveTeamMember(ctx context.Context, orgID, teamID, userID uuid.UUID) error
+	ListTeamMembers(ctx context.Context, orgID, teamID uuid.UUID) ([]*models.OrganizationMember, error)
+	ListUserMemberships(ctx context.Context, userID uuid.UUID) ([]*models.Membership, error)
+}
+
+// UserLookup loads live users; memberships may only be granted to them
+type UserLookup interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+}
+
+// organizationSlugPattern allows lowercase words separated by single hyphens
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type OrganizationService struct {
+	repo   OrganizationRepositoryInterface
+	users  UserLookup
+	logger *logrus.Logger
+}
+
+func NewOrganizationService(repo *repository.OrganizationRepository, users *repository.UserRepository, logger *logrus.Logger) *OrganizationService {
+	return &OrganizationService{
+		repo:   repo,
+		users:  users,
+		logger: logger,
+	}
+}
+
+// NewOrganizationServiceWithInterface creates a service with custom repository interfaces (for testing)
+func NewOrganizationServiceWithInterface(repo OrganizationRepositoryInterface, users UserLookup, logger *logrus.Logger) *OrganizationService {
+	return &OrganizationService{
+		repo:   repo,
+		users:  users,
+		logger: logger,
+	}
+}
+
+func (s *OrganizationService) CreateOrganization(ctx context.Context, req *models.CreateOrganizationRequest) (*models.Organization, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, models.NewValidationError("name", "name is required")
+	}
+	if !organizationSlugPattern.MatchString(req.Slug) {
+		return nil, models.NewValidationError("slug", "slug must be lowercase letters and digits separated by hyphens")
+	}
+
+	org, err := s.repo.CreateOrganization(ctx, &models.Organization{
+		Name:        name,
+		Slug:        req.Slug,
+		Description: req.Description,
+	})
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to create organization in repository")
+		if isUniqueViolation(err) {
+			return nil, models.NewConflictError("Organization", "slug", req.Slug)
+		}
+		return nil, models.NewInternalError("creating organization", err)
+	}
+
+	return org, nil
+}
+
+func (s *OrganizationService) GetOrganization(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	org, err := s.repo.GetOrganization(ctx, id)
+	if err != nil {
+		return nil, notFoundOrInternal(err, "Organization", "id", id.String(), "getting organization")
+	}
+	return org, nil
+}
+
+func (s *OrganizationService) ListOrganizations(ctx context.Context, limit, offset int) ([]*models.Organization, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	orgs, err := s.repo.ListOrganizations(ctx, limit, offset)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list organizations in service")
+		return nil, models.NewInternalError("listing organizations", err)
+	}
+	if orgs == nil {
+		orgs = []*models.Organization{}
+	}
+	return orgs, nil
+}
+
+// UpdateOrganization changes the name and description; the slug is immutable
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, id uuid.UUID, req *models.UpdateOrganizationRequest) (*models.Organization, error) {
+	org, err := s.GetOrganization(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, models.NewValidationError("name", "name cannot be empty")
+		}
+		org.Name = name
+	}
+	if req.Description != nil {
+		org.Description = *req.Description
+	}
+
+	updated, err := s.repo.UpdateOrganization(ctx, org)
+	if err != nil {
+		return nil, notFoundOrInternal(err, "Organization", "id", id.String(), "updating organization")
+	}
+	return updated, nil
+}
+
+func (s *OrganizationService) DeleteOrganization(ctx context.Context, id uuid.UUID) error {
+	if err := s.repo.DeleteOrganization(ctx, id); err != nil {
+		return notFoundOrInternal(err, "Organization", "id", id.String(), "deleting organization")
+	}
+	return nil
+}
+
+func (s *OrganizationService) AddOrganizationMember(ctx context.Context, orgID uuid.UUID, req *models.AddMemberRequest) (*models.OrganizationMember, error) {
+	role := req.Role
+	if role == "" {
+		role = models.OrganizationRoleMember
+	}
+	if !models.IsValidOrganizationRole(role) {
+		return nil, models.NewValidationError("role", "role must be owner, admin or member")
+	}
+
+	if _, err := s.GetOrganization(ctx, orgID); err != nil {
+		return nil, err
+	}
+	if err := s.requireLiveUser(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.AddOrganizationMember(ctx, orgID, req.UserID, role)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to add organization member in repository")
+		return nil, models.NewInternalError("adding organization member", err)
+	}
+	return member, nil
+}
+
+// RemoveOrganizationMember removes the user from the organization and its teams
+func (s *OrganizationService) RemoveOrganizationMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	if err := s.repo.RemoveOrganizationMember(ctx, orgID, userID); err != nil {
+		return notFoundOrInternal(err, "Membership", "user_id", userID.String(), "removing organization member")
+	}
+	return nil
+}
+
+func (s *OrganizationService) ListOrganizationMembers(ctx context.Context, orgID uuid.UUID) ([]*models.OrganizationMember, error) {
+	if _, err := s.GetOrganization(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListOrganizationMembers(ctx, orgID)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list organization members in service")
+		return nil, models.NewInternalError("listing organization members", err)
+	}
+	if members == nil {
+		members = []*models.OrganizationMember{}
+	}
+	return members, nil
+}
+
+func (s *OrganizationService) CreateTeam(ctx context.Context, orgID uuid.UUID, req *models.CreateTeamRequest) (*models.Team, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, models.NewValidationError("name", "name is required")
+	}
+
+	if _, err := s.GetOrganization(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	team, err := s.repo.CreateTeam(ctx, &models.Team{
+		OrganizationID: orgID,
+		Name:           name,
+		Description:    req.Description,
+	})
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to create team in repository")
+		if isUniqueViolation(err) {
+			return nil, models.NewConflictError("Team", "name", name)
+		}
+		return nil, models.NewInternalError("creating team", err)
+	}
+
+	return team, nil
+}
+
+func (s *OrganizationService) GetTeam(ctx context.Context, orgID, teamID uuid.UUID) (*models.Team, error) {
+	team, err := s.repo.GetTeam(ctx, orgID, teamID)
+	if err != nil {
+		return nil, notFoundOrInternal(err, "Team", "id", teamID.String(), "getting team")
+	}
+	return team, nil
+}
+
+func (s *OrganizationService) ListTeams(ctx context.Context, orgID uuid.UUID) ([]*models.Team, error) {
+	if _, err := s.GetOrganization(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	teams, err := s.repo.ListTeams(ctx, orgID)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list teams in service")
+		return nil, models.NewInternalError("listing teams", err)
+	}
+	if teams == nil {
+		teams = []*models.Team{}
+	}
+	return teams, nil
+}
+
+func (s *OrganizationService) DeleteTeam(ctx context.Context, orgID, teamID uuid.UUID) error {
+	if err := s.repo.DeleteTeam(ctx, orgID, teamID); err != nil {
+		return notFoundOrInternal(err, "Team", "id", teamID.String(), "deleting team")
+	}
+	return nil
+}
+
+// AddTeamMember adds an existing organization member to one of its teams
+func (s *OrganizationService) AddTeamMember(ctx context.Context, orgID, teamID uuid.UUID, req *models.AddMemberRequest) (*models.OrganizationMember, error) {
+	role := req.Role
+	if role == "" {
+		role = models.TeamRoleMember
+	}
+	if !models.IsValidTeamRole(role) {
+		return nil, models.NewValidationError("role", "role must be maintainer or member")
+	}
+
+	if _, err := s.GetTeam(ctx, orgID, teamID); err != nil {
+		return nil, err
+	}
+	if err := s.requireLiveUser(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.repo.AddTeamMember(ctx, orgID, teamID, req.UserID, role)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to add team member in repository")
+		if strings.Contains(err.Error(), "team_members_org_member_fkey") {
+			return nil, models.NewValidationError("user_id", "user must be a member of the organization before joining its teams")
+		}
+		return nil, models.NewInternalError("adding team member", err)
+	}
+	return member, nil
+}
+
+func (s *OrganizationService) RemoveTeamMember(ctx context.Context, orgID, teamID, userID uuid.UUID) error {
+	if err := s.repo.RemoveTeamMember(ctx, orgID, teamID, userID); err != nil {
+		return notFoundOrInternal(err, "Membership", "user_id", userID.String(), "removing team member")
+	}
+	return nil
+}
+
+func (s *OrganizationService) ListTeamMembers(ctx context.Context, orgID, teamID uuid.UUID) ([]*models.OrganizationMember, error) {
+	if _, err := s.GetTeam(ctx, orgID, teamID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListTeamMembers(ctx, orgID, teamID)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list team members in service")
+		return nil, models.NewInternalError("listing team members", err)
+	}
+	if members == nil {
+		members = []*models.OrganizationMember{}
+	}
+	return members, nil
+}
+
+// ListUserMemberships returns the organizations and teams the user belongs to
+func (s *OrganizationService) ListUserMemberships(ctx context.Context, userID uuid.UUID) ([]*models.Membership, error) {
+	memberships, err := s.repo.ListUserMemberships(ctx, userID)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list user memberships in service")
+		return nil, models.NewInternalError("listing user memberships", err)
+	}
+	if memberships == nil {
+		memberships = []*models.Membership{}
+	}
+	return memberships, nil
+}
+
+func (s *OrganizationService) requireLiveUser(ctx context.Context, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return models.NewValidationError("user_id", "user ID is required")
+	}
+
+	if _, err := s.users.GetByID(ctx, userID); err != nil {
+		return notFoundOrInternal(err, "User", "id", userID.String(), "getting user")
+	}
+	return nil
+}
+
+// notFoundOrInternal maps a repository "not found" error to NotFoundError and anything else to InternalError
+func notFoundOrInternal(err error, resource, field, value, operation string) error {
+	if strings.Contains(err.Error(), "not found") {
+		return models.NewNotFoundError(resource, field, value)
+	}
+	return models.NewInternalError(operation, err)
+}
+
+func isUniqueViolation(err error) bool {
+	return strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "unique constraint")
+}
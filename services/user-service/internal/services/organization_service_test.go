@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
+)
+
+// MockOrganizationRepository is a testify mock for OrganizationRepository
+type MockOrganizationRepository struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationRepository) CreateOrganization(ctx context.Context, org *models.Organization) (*models.Organization, error) {
+	args := m.Called(ctx, org)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) GetOrganization(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) ListOrganizations(ctx context.Context, limit, offset int) ([]*models.Organization, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) UpdateOrganization(ctx context.Context, org *models.Organization) (*models.Organization, error) {
+	args := m.Called(ctx, org)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) DeleteOrganization(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockOrganizationRepository) AddOrganizationMember(ctx context.Context, orgID, userID uuid.UUID, role string) (*models.OrganizationMember, error) {
+	args := m.Called(ctx, orgID, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) RemoveOrganizationMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	return m.Called(ctx, orgID, userID).Error(0)
+}
+
+func (m *MockOrganizationRepository) ListOrganizationMembers(ctx context.Context, orgID uuid.UUID) ([]*models.OrganizationMember, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) CreateTeam(ctx context.Context, team *models.Team) (*models.Team, error) {
+	args := m.Called(ctx, team)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Team), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) GetTeam(ctx context.Context, orgID, teamID uuid.UUID) (*models.Team, error) {
+	args := m.Called(ctx, orgID, teamID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Team), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) ListTeams(ctx context.Context, orgID uuid.UUID) ([]*models.Team, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Team), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) DeleteTeam(ctx context.Context, orgID, teamID uuid.UUID) error {
+	return m.Called(ctx, orgID, teamID).Error(0)
+}
+
+func (m *MockOrganizationRepository) AddTeamMember(ctx context.Context, orgID, teamID, userID uuid.UUID, role string) (*models.OrganizationMember, error) {
+	args := m.Called(ctx, orgID, teamID, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) RemoveTeamMember(ctx context.Context, orgID, teamID, userID uuid.UUID) error {
+	return m.Called(ctx, orgID, teamID, userID).Error(0)
+}
+
+func (m *MockOrganizationRepository) ListTeamMembers(ctx context.Context, orgID, teamID uuid.UUID) ([]*models.OrganizationMember, error) {
+	args := m.Called(ctx, orgID, teamID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.OrganizationMember), args.Error(1)
+}
+
+func (m *MockOrganizationRepository) ListUserMemberships(ctx context.Context, userID uuid.UUID) ([]*models.Membership, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Membership), args.Error(1)
+}
+
+func newTestOrganizationService() (*OrganizationService, *MockOrganizationRepository, *MockUserRepository) {
+	repo := new(MockOrganizationRepository)
+	users := new(MockUserRepository)
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	return NewOrganizationServiceWithInterface(repo, users, logger), repo, users
+}
+
+func TestOrganizationService_CreateOrganization(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       *models.CreateOrganizationRequest
+		mockCreateErr error
+		callsRepo     bool
+		expectedError error
+	}{
+		{
+			name:      "success",
+			request:   &models.CreateOrganizationRequest{Name: " Acme ", Slug: "acme-corp"},
+			callsRepo: true,
+		},
+		{
+			name:          "invalid slug",
+			request:       &models.CreateOrganizationRequest{Name: "Acme", Slug: "Acme Corp"},
+			expectedError: models.ValidationError{},
+		},
+		{
+			name:          "blank name",
+			request:       &models.CreateOrganizationRequest{Name: "  ", Slug: "acme"},
+			expectedError: models.ValidationError{},
+		},
+		{
+			name:          "duplicate slug",
+			request:       &models.CreateOrganizationRequest{Name: "Acme", Slug: "acme"},
+			mockCreateErr: errors.New("ERROR: duplicate key value violates unique constraint \"organizations_slug_key\""),
+			callsRepo:     true,
+			expectedError: models.ConflictError{},
+		},
+		{
+			name:          "repository failure",
+			request:       &models.CreateOrganizationRequest{Name: "Acme", Slug: "acme"},
+			mockCreateErr: errors.New("connection refused"),
+			callsRepo:     true,
+			expectedError: models.InternalError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, _ := newTestOrganizationService()
+
+			if tt.callsRepo {
+				if tt.mockCreateErr != nil {
+					repo.On("CreateOrganization", mock.Anything, mock.Anything).Return(nil, tt.mockCreateErr)
+				} else {
+					repo.On("CreateOrganization", mock.Anything, mock.MatchedBy(func(o *models.Organization) bool {
+						return o.Name == "Acme" && o.Slug == tt.request.Slug
+					})).Return(&models.Organization{ID: uuid.New(), Name: "Acme", Slug: tt.request.Slug}, nil)
+				}
+			}
+
+			org, err := service.CreateOrganization(context.Background(), tt.request)
+
+			if tt.expectedError != nil {
+				assert.Error(t, err)
+				assert.IsType(t, tt.expectedError, err)
+				assert.Nil(t, org)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.request.Slug, org.Slug)
+			}
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOrganizationService_UpdateOrganization(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("updates name and keeps slug", func(t *testing.T) {
+		service, repo, _ := newTestOrganizationService()
+		name := "New Name"
+
+		repo.On("GetOrganization", mock.Anything, orgID).Return(&models.Organization{ID: orgID, Name: "Old", Slug: "acme"}, nil)
+		repo.On("UpdateOrganization", mock.Anything, mock.MatchedBy(func(o *models.Organization) bool {
+			return o.Name == name && o.Slug == "acme"
+		})).Return(&models.Organization{ID: orgID, Name: name, Slug: "acme"}, nil)
+
+		org, err := service.UpdateOrganization(context.Background(), orgID, &models.UpdateOrganizationRequest{Name: &name})
+
+		assert.NoError(t, err)
+		assert.Equal(t, name, org.Name)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("missing organization", func(t *testing.T) {
+		service, repo, _ := newTestOrganizationService()
+		repo.On("GetOrganization", mock.Anything, orgID).Return(nil, errors.New("organization not found"))
+
+		_, err := service.UpdateOrganization(context.Background(), orgID, &models.UpdateOrganizationRequest{})
+
+		assert.IsType(t, models.NotFoundError{}, err)
+	})
+}
+
+func TestOrganizationService_AddOrganizationMember(t *testing.T) {
+	orgID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("defaults role to member", func(t *testing.T) {
+		service, repo, users := newTestOrganizationService()
+		repo.On("GetOrganization", mock.Anything, orgID).Return(&models.Organization{ID: orgID}, nil)
+		users.On("GetByID", mock.Anything, userID).Return(&models.User{ID: userID}, nil)
+		repo.On("AddOrganizationMember", mock.Anything, orgID, userID, models.OrganizationRoleMember).
+			Return(&models.OrganizationMember{OrganizationID: orgID, UserID: userID, Role: models.OrganizationRoleMember}, nil)
+
+		member, err := service.AddOrganizationMember(context.Background(), orgID, &models.AddMemberRequest{UserID: userID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.OrganizationRoleMember, member.Role)
+		repo.AssertExpectations(t)
+		users.AssertExpectations(t)
+	})
+
+	t.Run("rejects unknown role", func(t *testing.T) {
+		service, repo, _ := newTestOrganizationService()
+
+		_, err := service.AddOrganizationMember(context.Background(), orgID, &models.AddMemberRequest{UserID: userID, Role: "maintainer"})
+
+		assert.IsType(t, models.ValidationError{}, err)
+		repo.AssertNotCalled(t, "AddOrganizationMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects deleted user", func(t *testing.T) {
+		service, repo, users := newTestOrganizationService()
+		repo.On("GetOrganization", mock.Anything, orgID).Return(&models.Organization{ID: orgID}, nil)
+		users.On("GetByID", mock.Anything, userID).Return(nil, errors.New("user not found"))
+
+		_, err := service.AddOrganizationMember(context.Background(), orgID, &models.AddMemberRequest{UserID: userID})
+
+		assert.IsType(t, models.NotFoundError{}, err)
+		repo.AssertNotCalled(t, "AddOrganizationMember", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestOrganizationService_AddTeamMember(t *testing.T) {
+	orgID := uuid.New()
+	teamID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("success", func(t *testing.T) {
+		service, repo, users := newTestOrganizationService()
+		repo.On("GetTeam", mock.Anything, orgID, teamID).Return(&models.Team{ID: teamID, OrganizationID: orgID}, nil)
+		users.On("GetByID", mock.Anything, userID).Return(&models.User{ID: userID}, nil)
+		repo.On("AddTeamMember", mock.Anything, orgID, teamID, userID, models.TeamRoleMaintainer).
+			Return(&models.OrganizationMember{OrganizationID: orgID, TeamID: &teamID, UserID: userID, Role: models.TeamRoleMaintainer}, nil)
+
+		member, err := service.AddTeamMember(context.Background(), orgID, teamID, &models.AddMemberRequest{UserID: userID, Role: models.TeamRoleMaintainer})
+
+		assert.NoError(t, err)
+		assert.Equal(t, teamID, *member.TeamID)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("user outside organization", func(t *testing.T) {
+		service, repo, users := newTestOrganizationService()
+		repo.On("GetTeam", mock.Anything, orgID, teamID).Return(&models.Team{ID: teamID, OrganizationID: orgID}, nil)
+		users.On("GetByID", mock.Anything, userID).Return(&models.User{ID: userID}, nil)
+		repo.On("AddTeamMember", mock.Anything, orgID, teamID, userID, models.TeamRoleMember).
+			Return(nil, errors.New("ERROR: insert or update on table \"team_members\" violates foreign key constraint \"team_members_org_member_fkey\""))
+
+		_, err := service.AddTeamMember(context.Background(), orgID, teamID, &models.AddMemberRequest{UserID: userID})
+
+		assert.IsType(t, models.ValidationError{}, err)
+		assert.Equal(t, "user_id", err.(models.ValidationError).Field)
+	})
+
+	t.Run("team in another organization", func(t *testing.T) {
+		service, repo, _ := newTestOrganizationService()
+		repo.On("GetTeam", mock.Anything, orgID, teamID).Return(nil, errors.New("team not found"))
+
+		_, err := service.AddTeamMember(context.Background(), orgID, teamID, &models.AddMemberRequest{UserID: userID})
+
+		assert.IsType(t, models.NotFoundError{}, err)
+	})
+}
+
+func TestOrganizationService_ListUserMemberships(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("returns empty slice when user has no memberships", func(t *testing.T) {
+		service, repo, _ := newTestOrganizationService()
+		repo.On("ListUserMemberships", mock.Anything, userID).Return(nil, nil)
+
+		memberships, err := service.ListUserMemberships(context.Background(), userID)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, memberships)
+		assert.Empty(t, memberships)
+	})
+
+	t.Run("repository failure", func(t *testing.T) {
+		service, repo, _ := newTestOrganizationService()
+		repo.On("ListUserMemberships", mock.Anything, userID).Return(nil, errors.New("connection refused"))
+
+		_, err := service.ListUserMemberships(context.Background(), userID)
+
+		assert.IsType(t, models.InternalError{}, err)
+	})
+}
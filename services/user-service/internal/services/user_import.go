@@ -0,0 +1,423 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// UserRoleAssigner resolves role names and grants roles to users (auth-service)
+type UserRoleAssigner interface {
+	ListRoleIDs(ctx context.Context, jwtToken string) (map[string]uuid.UUID, error)
+	AssignRole(ctx context.Context, userID, roleID uuid.UUID, jwtToken string) error
+}
+
+// exportPageSize is how many users ExportUsers reads from the repository per query
+const exportPageSize = 500
+
+// importCSVColumns are the recognised CSV header columns; email, password, first_name and last_name are required
+var importCSVColumns = []string{"email", "password", "first_name", "last_name", "roles"}
+
+// SetUserRoleAssigner configures the auth-service client used to assign roles during bulk import
+func (s *UserService) SetUserRoleAssigner(assigner UserRoleAssigner) {
+	s.roleAssigner = assigner
+}
+
+// parsedImportRow is an import row together with the error that made it unparseable, if any
+type parsedImportRow struct {
+	row models.ImportUserRow
+	err error
+}
+
+// parseImportCSV reads a CSV import file. The header row names the columns, in any order.
+func parseImportCSV(r io.Reader) ([]parsedImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, models.NewValidationError("file", "import file is empty")
+	}
+	if err != nil {
+		return nil, models.NewValidationError("file", fmt.Sprintf("invalid CSV header: %v", err))
+	}
+
+	index := make(map[string]int, len(header))
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))] = i
+	}
+	for _, column := range importCSVColumns[:4] {
+		if _, ok := index[column]; !ok {
+			return nil, models.NewValidationError("file", fmt.Sprintf("CSV header is missing the %q column", column))
+		}
+	}
+
+	field := func(record []string, column string) string {
+		i, ok := index[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	var rows []parsedImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if len(rows) == models.MaxImportRows {
+			return nil, models.NewValidationError("file", fmt.Sprintf("import is limited to %d rows", models.MaxImportRows))
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, models.NewValidationError("file", fmt.Sprintf("failed to read CSV: %v", err))
+			}
+			rows = append(rows, parsedImportRow{err: models.NewValidationError("row", parseErr.Err.Error())})
+			continue
+		}
+
+		row := models.ImportUserRow{
+			Email:     field(record, "email"),
+			Password:  field(record, "password"),
+			FirstName: field(record, "first_name"),
+			LastName:  field(record, "last_name"),
+		}
+		for _, role := range strings.Split(field(record, "roles"), ";") {
+			if role = strings.TrimSpace(role); role != "" {
+				row.Roles = append(row.Roles, role)
+			}
+		}
+		rows = append(rows, parsedImportRow{row: row})
+	}
+
+	return rows, nil
+}
+
+// parseImportNDJSON reads one JSON object per line; blank lines are ignored
+func parseImportNDJSON(r io.Reader) ([]parsedImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var rows []parsedImportRow
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if len(rows) == models.MaxImportRows {
+			return nil, models.NewValidationError("file", fmt.Sprintf("import is limited to %d rows", models.MaxImportRows))
+		}
+
+		var row models.ImportUserRow
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			rows = append(rows, parsedImportRow{err: models.NewValidationError("row", "invalid JSON object")})
+			continue
+		}
+		rows = append(rows, parsedImportRow{row: row})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, models.NewValidationError("file", fmt.Sprintf("failed to read NDJSON: %v", err))
+	}
+
+	return rows, nil
+}
+
+// ImportUsers creates the users listed in body. Every row is validated like CreateUser and
+// checked for duplicates within the file and against existing users. In atomic mode a single
+// invalid row aborts the import; in best-effort mode the valid rows are created regardless.
+// A dry run validates without creating anything. jwtToken is forwarded to auth-service to
+// resolve and assign roles. The returned report lists the outcome of every row.
+func (s *UserService) ImportUsers(ctx context.Context, body io.Reader, opts models.ImportUsersOptions, jwtToken string) (*models.ImportReport, error) {
+	if opts.Mode == "" {
+		opts.Mode = models.ImportModeAtomic
+	}
+	if !models.IsValidImportMode(opts.Mode) {
+		return nil, models.NewValidationError("mode", "mode must be atomic or best_effort")
+	}
+
+	var parsed []parsedImportRow
+	var err error
+	switch opts.Format {
+	case models.ImportFormatCSV:
+		parsed, err = parseImportCSV(body)
+	case models.ImportFormatNDJSON:
+		parsed, err = parseImportNDJSON(body)
+	default:
+		return nil, models.NewValidationError("format", "format must be csv or ndjson")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed) == 0 {
+		return nil, models.NewValidationError("file", "import file has no rows")
+	}
+
+	report := &models.ImportReport{
+		Mode:   opts.Mode,
+		DryRun: opts.DryRun,
+		Total:  len(parsed),
+		Rows:   make([]*models.ImportRowResult, len(parsed)),
+	}
+	fail := func(i int, err error) {
+		result := report.Rows[i]
+		result.Status = models.ImportRowFailed
+		result.Error = err.Error()
+		var validationErr models.ValidationError
+		if errors.As(err, &validationErr) {
+			result.Field = validationErr.Field
+			result.Error = validationErr.Message
+		}
+	}
+
+	// Per-row validation and duplicate detection within the file
+	seen := make(map[string]int)
+	needsRoles := false
+	for i, p := range parsed {
+		row := &parsed[i].row
+		row.Email = strings.TrimSpace(row.Email)
+		row.FirstName = strings.TrimSpace(row.FirstName)
+		row.LastName = strings.TrimSpace(row.LastName)
+		report.Rows[i] = &models.ImportRowResult{Row: i + 1, Email: row.Email, Status: models.ImportRowValid}
+
+		if p.err != nil {
+			fail(i, p.err)
+			continue
+		}
+		if err := s.validateCreateUserRequest(&models.CreateUserRequest{
+			Email:     row.Email,
+			Password:  row.Password,
+			FirstName: row.FirstName,
+			LastName:  row.LastName,
+		}); err != nil {
+			fail(i, err)
+			continue
+		}
+		key := strings.ToLower(row.Email)
+		if first, ok := seen[key]; ok {
+			fail(i, models.NewValidationError("email", fmt.Sprintf("duplicate of row %d", first)))
+			continue
+		}
+		seen[key] = i + 1
+		if len(row.Roles) > 0 {
+			needsRoles = true
+		}
+	}
+
+	// Role names are resolved once for the whole file
+	var roleIDs map[string]uuid.UUID
+	if needsRoles {
+		if s.roleAssigner == nil {
+			return nil, models.NewInternalError("resolving import roles", fmt.Errorf("auth-service client is not configured"))
+		}
+		if jwtToken == "" {
+			return nil, models.NewForbiddenError("assigning roles requires an admin token")
+		}
+		roleIDs, err = s.roleAssigner.ListRoleIDs(ctx, jwtToken)
+		if err != nil {
+			var forbidden models.ForbiddenError
+			if errors.As(err, &forbidden) {
+				return nil, forbidden
+			}
+			s.logger.WithError(err).Error("Failed to resolve import roles")
+			return nil, models.NewInternalError("resolving import roles", err)
+		}
+		for i, p := range parsed {
+			if report.Rows[i].Status != models.ImportRowValid {
+				continue
+			}
+			for _, role := range p.row.Roles {
+				if _, ok := roleIDs[role]; !ok {
+					fail(i, models.NewValidationError("roles", fmt.Sprintf("unknown role %q", role)))
+					break
+				}
+			}
+		}
+	}
+
+	// Emails already taken by live users
+	var emails []string
+	for i, p := range parsed {
+		if report.Rows[i].Status == models.ImportRowValid {
+			emails = append(emails, p.row.Email)
+		}
+	}
+	existing, err := s.repo.ExistingEmails(ctx, emails)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to check existing users for import")
+		return nil, models.NewInternalError("checking existing users", err)
+	}
+	for i, p := range parsed {
+		if report.Rows[i].Status == models.ImportRowValid && existing[p.row.Email] {
+			fail(i, models.NewConflictError("User", "email", p.row.Email))
+			report.Rows[i].Field = "email"
+		}
+	}
+
+	var valid []int
+	for i, result := range report.Rows {
+		if result.Status == models.ImportRowValid {
+			valid = append(valid, i)
+		}
+	}
+	aborted := opts.Mode == models.ImportModeAtomic && len(valid) < len(parsed)
+
+	if opts.DryRun || aborted || len(valid) == 0 {
+		if aborted && !opts.DryRun {
+			for _, i := range valid {
+				report.Rows[i].Status = models.ImportRowSkipped
+			}
+		}
+		s.tallyImport(report)
+		return report, nil
+	}
+
+	users := make([]*models.User, len(parsed))
+	for _, i := range valid {
+		row := parsed[i].row
+		passwordHash, err := bcrypt.GenerateFromPassword([]byte(row.Password), bcrypt.DefaultCost)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to hash password")
+			return nil, models.NewInternalError("hashing password", err)
+		}
+		users[i] = &models.User{
+			Email:        row.Email,
+			PasswordHash: string(passwordHash),
+			FirstName:    row.FirstName,
+			LastName:     row.LastName,
+		}
+	}
+
+	if opts.Mode == models.ImportModeAtomic {
+		batch := make([]*models.User, 0, len(valid))
+		for _, i := range valid {
+			batch = append(batch, users[i])
+		}
+		if err := s.repo.CreateBatch(ctx, batch); err != nil {
+			s.logger.WithError(err).Error("Failed to create imported users")
+			if isDuplicateKeyError(err) {
+				return nil, models.NewConflictError("User", "email", "one or more imported emails")
+			}
+			return nil, models.NewInternalError("creating imported users", err)
+		}
+		for _, i := range valid {
+			report.Rows[i].Status = models.ImportRowCreated
+		}
+	} else {
+		for _, i := range valid {
+			if _, err := s.repo.Create(ctx, users[i]); err != nil {
+				s.logger.WithError(err).WithField("row", i+1).Error("Failed to create imported user")
+				if isDuplicateKeyError(err) {
+					fail(i, models.NewConflictError("User", "email", users[i].Email))
+					report.Rows[i].Field = "email"
+				} else {
+					fail(i, errors.New("failed to create user"))
+				}
+				continue
+			}
+			report.Rows[i].Status = models.ImportRowCreated
+		}
+	}
+
+	// Roles are granted after the users exist; failures are reported but do not undo creation
+	for _, i := range valid {
+		result := report.Rows[i]
+		if result.Status != models.ImportRowCreated {
+			continue
+		}
+		result.UserID = &users[i].ID
+		for _, role := range parsed[i].row.Roles {
+			if err := s.roleAssigner.AssignRole(ctx, users[i].ID, roleIDs[role], jwtToken); err != nil {
+				s.logger.WithError(err).WithFields(logrus.Fields{
+					"user_id": users[i].ID,
+					"role":    role,
+				}).Warn("Failed to assign role to imported user")
+				result.Warnings = append(result.Warnings, fmt.Sprintf("role %q was not assigned", role))
+			}
+		}
+	}
+
+	s.tallyImport(report)
+	s.logger.WithFields(logrus.Fields{
+		"mode":    report.Mode,
+		"total":   report.Total,
+		"created": report.Created,
+		"failed":  report.Failed,
+	}).Info("Users imported")
+
+	return report, nil
+}
+
+// tallyImport fills in the report's per-status counts from its rows
+func (s *UserService) tallyImport(report *models.ImportReport) {
+	for _, result := range report.Rows {
+		switch result.Status {
+		case models.ImportRowCreated:
+			report.Created++
+		case models.ImportRowValid:
+			report.Valid++
+		case models.ImportRowFailed:
+			report.Failed++
+		case models.ImportRowSkipped:
+			report.Skipped++
+		}
+	}
+}
+
+// isDuplicateKeyError reports whether a repository error is a unique constraint violation
+func isDuplicateKeyError(err error) bool {
+	return strings.Contains(err.Error(), "duplicate key") ||
+		strings.Contains(err.Error(), "unique constraint") ||
+		strings.Contains(err.Error(), "already exists")
+}
+
+// ExportUsers streams every user matching query to emit, in the query's sort order.
+// Pagination fields in query are ignored; the repository is read in keyset pages so the
+// export stays consistent without holding a long-running query open.
+func (s *UserService) ExportUsers(ctx context.Context, query *models.ListUsersQuery, jwtToken string, emit func(*models.UserResponse) error) error {
+	pageQuery := *query
+	pageQuery.Limit, pageQuery.Offset, pageQuery.Cursor = 0, 0, ""
+
+	filter, err := s.buildUserListFilter(ctx, &pageQuery, jwtToken)
+	if err != nil {
+		return err
+	}
+	filter.Limit = exportPageSize
+
+	for {
+		users, err := s.repo.List(ctx, filter)
+		if err != nil {
+			s.logger.WithError(err).Error("Failed to list users for export")
+			return models.NewInternalError("exporting users", err)
+		}
+
+		for _, user := range users {
+			if err := emit(s.toResponse(user)); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < filter.Limit {
+			return nil
+		}
+		last := users[len(users)-1]
+		filter.After = &models.UserCursor{
+			SortBy:     filter.SortBy,
+			Descending: filter.Descending,
+			Value:      userSortValue(last, filter.SortBy),
+			ID:         last.ID,
+		}
+	}
+}
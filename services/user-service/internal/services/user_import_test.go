@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
+)
+
+// MockUserRoleAssigner is a testify mock for UserRoleAssigner
+type MockUserRoleAssigner struct {
+	mock.Mock
+}
+
+func (m *MockUserRoleAssigner) ListRoleIDs(ctx context.Context, jwtToken string) (map[string]uuid.UUID, error) {
+	args := m.Called(ctx, jwtToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]uuid.UUID), args.Error(1)
+}
+
+func (m *MockUserRoleAssigner) AssignRole(ctx context.Context, userID, roleID uuid.UUID, jwtToken string) error {
+	args := m.Called(ctx, userID, roleID, jwtToken)
+	return args.Error(0)
+}
+
+func newTestImportService() (*UserService, *MockUserRepository) {
+	mockRepo := &MockUserRepository{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.FatalLevel)
+	return NewUserServiceWithInterface(mockRepo, logger), mockRepo
+}
+
+const importCSV = `email,password,first_name,last_name,roles
+ann@example.com,secret123,Ann,Smith,
+bob@example.com,secret123,Bob,Jones,
+`
+
+func TestUserService_ImportUsers_AtomicCSV(t *testing.T) {
+	service, mockRepo := newTestImportService()
+
+	mockRepo.On("ExistingEmails", mock.Anything, []string{"ann@example.com", "bob@example.com"}).Return(map[string]bool{}, nil).Once()
+	mockRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(users []*models.User) bool {
+		return len(users) == 2 && users[0].Email == "ann@example.com" && users[0].PasswordHash != "secret123"
+	})).Run(func(args mock.Arguments) {
+		for _, user := range args.Get(1).([]*models.User) {
+			user.ID = uuid.New()
+		}
+	}).Return(nil).Once()
+
+	report, err := service.ImportUsers(context.Background(), strings.NewReader(importCSV),
+		models.ImportUsersOptions{Format: models.ImportFormatCSV}, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.ImportModeAtomic, report.Mode)
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 2, report.Created)
+	for _, row := range report.Rows {
+		assert.Equal(t, models.ImportRowCreated, row.Status)
+		assert.NotNil(t, row.UserID)
+	}
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_ImportUsers_AtomicAbortsOnInvalidRow(t *testing.T) {
+	service, mockRepo := newTestImportService()
+
+	csv := importCSV + "not-an-email,secret123,Cy,Young,\nann@example.com,secret123,Ann,Again,\n"
+	mockRepo.On("ExistingEmails", mock.Anything, []string{"ann@example.com", "bob@example.com"}).
+		Return(map[string]bool{"bob@example.com": true}, nil).Once()
+
+	report, err := service.ImportUsers(context.Background(), strings.NewReader(csv),
+		models.ImportUsersOptions{Format: models.ImportFormatCSV, Mode: models.ImportModeAtomic}, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Created)
+	assert.Equal(t, 3, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, models.ImportRowSkipped, report.Rows[0].Status)
+	assert.Equal(t, "email", report.Rows[1].Field)
+	assert.Contains(t, report.Rows[1].Error, "already exists")
+	assert.Equal(t, "email", report.Rows[2].Field)
+	assert.Equal(t, "duplicate of row 1", report.Rows[3].Error)
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
+
+func TestUserService_ImportUsers_BestEffortNDJSON(t *testing.T) {
+	service, mockRepo := newTestImportService()
+	assigner := &MockUserRoleAssigner{}
+	service.SetUserRoleAssigner(assigner)
+	editorID := uuid.New()
+
+	ndjson := `{"email":"ann@example.com","password":"secret123","first_name":"Ann","last_name":"Smith","roles":["editor"]}
+{not json}
+
+{"email":"bob@example.com","password":"secret123","first_name":"Bob","last_name":"Jones","roles":["auditor"]}
+{"email":"cy@example.com","password":"secret123","first_name":"Cy","last_name":"Young"}
+`
+	assigner.On("ListRoleIDs", mock.Anything, "admin-token").Return(map[string]uuid.UUID{"editor": editorID}, nil).Once()
+	mockRepo.On("ExistingEmails", mock.Anything, []string{"ann@example.com", "cy@example.com"}).Return(map[string]bool{}, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *models.User) bool { return u.Email == "ann@example.com" })).
+		Run(func(args mock.Arguments) { args.Get(1).(*models.User).ID = uuid.New() }).
+		Return(&models.User{}, nil).Once()
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *models.User) bool { return u.Email == "cy@example.com" })).
+		Return(nil, errors.New("duplicate key value violates unique constraint")).Once()
+	assigner.On("AssignRole", mock.Anything, mock.Anything, editorID, "admin-token").Return(errors.New("auth-service returned status 500")).Once()
+
+	report, err := service.ImportUsers(context.Background(), strings.NewReader(ndjson),
+		models.ImportUsersOptions{Format: models.ImportFormatNDJSON, Mode: models.ImportModeBestEffort}, "admin-token")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 1, report.Created)
+	assert.Equal(t, 3, report.Failed)
+	assert.Equal(t, models.ImportRowCreated, report.Rows[0].Status)
+	assert.Equal(t, []string{`role "editor" was not assigned`}, report.Rows[0].Warnings)
+	assert.Equal(t, "row", report.Rows[1].Field)
+	assert.Equal(t, "roles", report.Rows[2].Field)
+	assert.Equal(t, "email", report.Rows[3].Field)
+	mockRepo.AssertExpectations(t)
+	assigner.AssertExpectations(t)
+}
+
+func TestUserService_ImportUsers_DryRun(t *testing.T) {
+	service, mockRepo := newTestImportService()
+	mockRepo.On("ExistingEmails", mock.Anything, mock.Anything).Return(map[string]bool{}, nil).Once()
+
+	report, err := service.ImportUsers(context.Background(), strings.NewReader(importCSV),
+		models.ImportUsersOptions{Format: models.ImportFormatCSV, DryRun: true}, "")
+
+	assert.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.Valid)
+	assert.Equal(t, 0, report.Created)
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
+
+func TestUserService_ImportUsers_Rejected(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		opts          models.ImportUsersOptions
+		jwtToken      string
+		expectedField string
+		expectedType  interface{}
+	}{
+		{
+			name:          "unknown format",
+			body:          importCSV,
+			opts:          models.ImportUsersOptions{Format: "xml"},
+			expectedField: "format",
+		},
+		{
+			name:          "unknown mode",
+			body:          importCSV,
+			opts:          models.ImportUsersOptions{Format: models.ImportFormatCSV, Mode: "sometimes"},
+			expectedField: "mode",
+		},
+		{
+			name:          "missing CSV column",
+			body:          "email,password,first_name\nann@example.com,secret123,Ann\n",
+			opts:          models.ImportUsersOptions{Format: models.ImportFormatCSV},
+			expectedField: "file",
+		},
+		{
+			name:          "no rows",
+			body:          "email,password,first_name,last_name\n",
+			opts:          models.ImportUsersOptions{Format: models.ImportFormatCSV},
+			expectedField: "file",
+		},
+		{
+			name:          "too many rows",
+			body:          "email,password,first_name,last_name\n" + strings.Repeat("a@example.com,secret123,Ann,Smith\n", models.MaxImportRows+1),
+			opts:          models.ImportUsersOptions{Format: models.ImportFormatCSV},
+			expectedField: "file",
+		},
+		{
+			name:         "roles without token",
+			body:         `{"email":"ann@example.com","password":"secret123","first_name":"Ann","last_name":"Smith","roles":["editor"]}`,
+			opts:         models.ImportUsersOptions{Format: models.ImportFormatNDJSON},
+			expectedType: models.ForbiddenError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, mockRepo := newTestImportService()
+			service.SetUserRoleAssigner(&MockUserRoleAssigner{})
+
+			report, err := service.ImportUsers(context.Background(), strings.NewReader(tt.body), tt.opts, tt.jwtToken)
+
+			assert.Nil(t, report)
+			if tt.expectedType != nil {
+				assert.IsType(t, tt.expectedType, err)
+			} else {
+				var validationErr models.ValidationError
+				assert.True(t, errors.As(err, &validationErr))
+				assert.Equal(t, tt.expectedField, validationErr.Field)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_ExportUsers_Pages(t *testing.T) {
+	service, mockRepo := newTestImportService()
+
+	page := make([]*models.User, exportPageSize)
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range page {
+		page[i] = &models.User{ID: uuid.New(), Email: "user@example.com", CreatedAt: base.Add(-time.Duration(i) * time.Minute)}
+	}
+	last := page[len(page)-1]
+
+	mockRepo.On("List", mock.Anything, mock.MatchedBy(func(f models.UserListFilter) bool {
+		return f.After == nil && f.Limit == exportPageSize && f.Status == models.UserStatusActive
+	})).Return(page, nil).Once()
+	mockRepo.On("List", mock.Anything, mock.MatchedBy(func(f models.UserListFilter) bool {
+		return f.After != nil && f.After.ID == last.ID
+	})).Return([]*models.User{{ID: uuid.New(), Email: "tail@example.com"}}, nil).Once()
+
+	var emitted []*models.UserResponse
+	err := service.ExportUsers(context.Background(),
+		&models.ListUsersQuery{Status: models.UserStatusActive, Limit: 5, Offset: 10, Cursor: "ignored"}, "",
+		func(user *models.UserResponse) error {
+			emitted = append(emitted, user)
+			return nil
+		})
+
+	assert.NoError(t, err)
+	assert.Len(t, emitted, exportPageSize+1)
+	assert.Equal(t, "tail@example.com", emitted[exportPageSize].Email)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_ExportUsers_InvalidFilter(t *testing.T) {
+	service, mockRepo := newTestImportService()
+
+	err := service.ExportUsers(context.Background(), &models.ListUsersQuery{Status: "bogus"}, "",
+		func(*models.UserResponse) error { return nil })
+
+	assert.IsType(t, models.ValidationError{}, err)
+	mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
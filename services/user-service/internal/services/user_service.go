@@ -2,9 +2,16 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/mail"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -20,29 +27,113 @@ type UserRepositoryInterface interface {
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	Update(ctx context.Context, id uuid.UUID, user *models.User) (*models.User, error)
 	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context, limit, offset int) ([]*models.User, error)
+	List(ctx context.Context, filter models.UserListFilter) ([]*models.User, error)
+	Count(ctx context.Context, filter models.UserListFilter) (int, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status, reason string) (*models.User, error)
+	SetPendingEmail(ctx context.Context, id uuid.UUID, email, tokenHash string, expiresAt time.Time) error
+	ConfirmPendingEmail(ctx context.Context, id uuid.UUID, tokenHash string) (*models.User, error)
+	GetByIDWithDeleted(ctx context.Context, id uuid.UUID) (*models.User, error)
+	Restore(ctx context.Context, id uuid.UUID, deletedAfter time.Time) (*models.User, error)
+	Erase(ctx context.Context, id uuid.UUID, anonymizedEmail string) (*models.User, error)
+	ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error)
+	CreateBatch(ctx context.Context, users []*models.User) error
 }
 
-type UserService struct {
-	repo   UserRepositoryInterface
+// emailVerificationTTL is how long an email change verification token stays valid
+const emailVerificationTTL = 24 * time.Hour
+
+// defaultRestoreWindow is how long soft-deleted users stay restorable unless configured otherwise
+const defaultRestoreWindow = 30 * 24 * time.Hour
+
+// purgeAttempts bounds the auth-service purge calls made by one erasure; defaultPurgeRetryDelay
+// is the wait before the second attempt and grows linearly after that
+const (
+	purgeAttempts          = 3
+	defaultPurgeRetryDelay = 500 * time.Millisecond
+)
+
+// UserDataPurger removes a user's credentials held outside user-service (auth-service tokens, sessions, roles)
+type UserDataPurger interface {
+	PurgeUserData(ctx context.Context, userID uuid.UUID, jwtToken string) error
+}
+
+// UserRoleLookup resolves a role name to the IDs of the users holding it (auth-service)
+type UserRoleLookup interface {
+	ListUserIDsByRole(ctx context.Context, role, jwtToken string) ([]uuid.UUID, error)
+}
+
+// EmailVerificationSender delivers email change verification tokens to the new address
+type EmailVerificationSender interface {
+	SendEmailVerification(ctx context.Context, userID uuid.UUID, email, token string) error
+}
+
+// logEmailVerificationSender records that a verification was requested without delivering it.
+// It is the default until a mail transport is configured with SetEmailVerificationSender; the
+// token is a credential and is never logged.
+type logEmailVerificationSender struct {
 	logger *logrus.Logger
 }
 
+func (s *logEmailVerificationSender) SendEmailVerification(ctx context.Context, userID uuid.UUID, email, token string) error {
+	s.logger.WithFields(logrus.Fields{
+		"user_id": userID,
+		"email":   email,
+	}).Warn("No email transport configured, email verification token not delivered")
+	return nil
+}
+
+type UserService struct {
+	repo          UserRepositoryInterface
+	logger        *logrus.Logger
+	verifier      EmailVerificationSender
+	purger        UserDataPurger
+	roleLookup    UserRoleLookup
+	roleAssigner  UserRoleAssigner
+	restoreWindow time.Duration
+	purgeRetry    time.Duration
+}
+
 func NewUserService(repo *repository.UserRepository, logger *logrus.Logger) *UserService {
 	return &UserService{
-		repo:   repo,
-		logger: logger,
+		repo:          repo,
+		logger:        logger,
+		verifier:      &logEmailVerificationSender{logger: logger},
+		restoreWindow: defaultRestoreWindow,
+		purgeRetry:    defaultPurgeRetryDelay,
 	}
 }
 
 // NewUserServiceWithInterface creates a service with a custom repository interface (for testing)
 func NewUserServiceWithInterface(repo UserRepositoryInterface, logger *logrus.Logger) *UserService {
 	return &UserService{
-		repo:   repo,
-		logger: logger,
+		repo:          repo,
+		logger:        logger,
+		verifier:      &logEmailVerificationSender{logger: logger},
+		restoreWindow: defaultRestoreWindow,
+		purgeRetry:    defaultPurgeRetryDelay,
 	}
 }
 
+// SetUserDataPurger configures the auth-service cascade used by EraseUser
+func (s *UserService) SetUserDataPurger(purger UserDataPurger) {
+	s.purger = purger
+}
+
+// SetUserRoleLookup configures the auth-service lookup used by the listing's role filter
+func (s *UserService) SetUserRoleLookup(lookup UserRoleLookup) {
+	s.roleLookup = lookup
+}
+
+// SetRestoreWindow sets how long soft-deleted users can be restored
+func (s *UserService) SetRestoreWindow(window time.Duration) {
+	s.restoreWindow = window
+}
+
+// SetEmailVerificationSender replaces the default (logging) verification token delivery
+func (s *UserService) SetEmailVerificationSender(sender EmailVerificationSender) {
+	s.verifier = sender
+}
+
 func (s *UserService) CreateUser(ctx context.Context, req *models.CreateUserRequest) (*models.UserResponse, error) {
 	// Validate input
 	if err := s.validateCreateUserRequest(req); err != nil {
@@ -88,9 +179,7 @@ func (s *UserService) CreateUser(ctx context.Context, req *models.CreateUserRequ
 		s.logger.WithError(err).Error("Failed to create user in repository")
 
 		// Check if it's a constraint violation (duplicate key)
-		if strings.Contains(err.Error(), "duplicate key") ||
-			strings.Contains(err.Error(), "unique constraint") ||
-			strings.Contains(err.Error(), "already exists") {
+		if isDuplicateKeyError(err) {
 			return nil, models.NewConflictError("User", "email", req.Email)
 		}
 
@@ -398,35 +487,517 @@ func (s *UserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-func (s *UserService) ListUsers(ctx context.Context, limit, offset int) ([]*models.UserResponse, error) {
-	if limit <= 0 {
-		limit = 10
+// RestoreUser undoes a soft delete while the user is still inside the restore window
+func (s *UserService) RestoreUser(ctx context.Context, id uuid.UUID) (*models.UserResponse, error) {
+	if id == uuid.Nil {
+		return nil, models.NewValidationError("id", "user ID is required")
 	}
-	if limit > 100 {
-		limit = 100
+
+	existing, err := s.repo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get user for restore")
+
+		if strings.Contains(err.Error(), "not found") {
+			return nil, models.NewNotFoundError("User", "id", id.String())
+		}
+
+		return nil, models.NewInternalError("getting user for restore", err)
+	}
+
+	if existing.ErasedAt != nil {
+		return nil, models.NewValidationError("id", "erased users cannot be restored")
+	}
+	if existing.DeletedAt == nil {
+		return nil, models.NewValidationError("id", "user is not deleted")
+	}
+
+	cutoff := time.Now().Add(-s.restoreWindow)
+	if existing.DeletedAt.Before(cutoff) {
+		return nil, models.NewValidationError("id",
+			fmt.Sprintf("restore window of %d days has expired", int(s.restoreWindow.Hours()/24)))
 	}
 
-	users, err := s.repo.List(ctx, limit, offset)
+	restored, err := s.repo.Restore(ctx, id, cutoff)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to restore user in repository")
+
+		// Another account may have registered the email since the delete
+		if strings.Contains(err.Error(), "duplicate key") ||
+			strings.Contains(err.Error(), "unique constraint") {
+			return nil, models.NewConflictError("User", "email", existing.Email)
+		}
+		if strings.Contains(err.Error(), "not found") {
+			return nil, models.NewNotFoundError("User", "id", id.String())
+		}
+
+		return nil, models.NewInternalError("restoring user", err)
+	}
+
+	return s.toResponse(restored), nil
+}
+
+// EraseUser irreversibly anonymizes a user and removes their auth-service credentials.
+//
+// The local anonymization runs first and is atomic, so personal data is gone before auth-service
+// is called. The auth-service purge only deletes rows and is retried; if it still fails the call
+// returns an error, and erasing the same user again skips the anonymization and re-runs the purge.
+// jwtToken is the calling admin's token, forwarded to auth-service to authorize the purge.
+func (s *UserService) EraseUser(ctx context.Context, id uuid.UUID, jwtToken string) error {
+	if id == uuid.Nil {
+		return models.NewValidationError("id", "user ID is required")
+	}
+
+	if s.purger == nil {
+		return models.NewInternalError("erasing user", fmt.Errorf("auth-service client is not configured"))
+	}
+
+	existing, err := s.repo.GetByIDWithDeleted(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get user for erasure")
+
+		if strings.Contains(err.Error(), "not found") {
+			return models.NewNotFoundError("User", "id", id.String())
+		}
+
+		return models.NewInternalError("getting user for erasure", err)
+	}
+
+	if existing.ErasedAt == nil {
+		// A concurrent erasure winning the race shows up as "not found"; the purge below still runs
+		if _, err := s.repo.Erase(ctx, id, anonymizedEmail(id)); err != nil && !strings.Contains(err.Error(), "not found") {
+			s.logger.WithError(err).Error("Failed to erase user in repository")
+			return models.NewInternalError("erasing user", err)
+		}
+	}
+
+	if err := s.purgeUserData(ctx, id, jwtToken); err != nil {
+		s.logger.WithError(err).WithField("user_id", id).
+			Error("User anonymized but auth-service data not purged; retry the erasure")
+		return models.NewInternalError("purging auth-service user data", err)
+	}
+
+	return nil
+}
+
+// purgeUserData calls the auth-service purge up to purgeAttempts times
+func (s *UserService) purgeUserData(ctx context.Context, id uuid.UUID, jwtToken string) error {
+	var err error
+	for attempt := 1; attempt <= purgeAttempts; attempt++ {
+		if err = s.purger.PurgeUserData(ctx, id, jwtToken); err == nil {
+			return nil
+		}
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"user_id": id,
+			"attempt": attempt,
+		}).Warn("Failed to purge user data in auth-service")
+
+		if attempt == purgeAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * s.purgeRetry):
+		}
+	}
+	return err
+}
+
+// anonymizedEmail is a unique, undeliverable placeholder for an erased user's email
+func anonymizedEmail(id uuid.UUID) string {
+	return fmt.Sprintf("erased-%s@erased.invalid", id)
+}
+
+// ListUsers returns one page of users matching query together with the total match count.
+// NextCursor is set when the page is full; pass it back as query.Cursor to fetch the next page.
+// jwtToken is forwarded to auth-service when filtering by role.
+func (s *UserService) ListUsers(ctx context.Context, query *models.ListUsersQuery, jwtToken string) (*models.UserListResult, error) {
+	filter, err := s.buildUserListFilter(ctx, query, jwtToken)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.repo.List(ctx, filter)
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to list users in service")
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
 
-	var responses []*models.UserResponse
+	total, err := s.repo.Count(ctx, filter)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to count users in service")
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	result := &models.UserListResult{
+		Users: make([]*models.UserResponse, 0, len(users)),
+		Total: total,
+	}
 	for _, user := range users {
-		responses = append(responses, s.toResponse(user))
+		result.Users = append(result.Users, s.toResponse(user))
+	}
+
+	if len(users) == filter.Limit {
+		last := users[len(users)-1]
+		result.NextCursor = encodeUserCursor(&models.UserCursor{
+			SortBy:     filter.SortBy,
+			Descending: filter.Descending,
+			Value:      userSortValue(last, filter.SortBy),
+			ID:         last.ID,
+		})
+	}
+
+	return result, nil
+}
+
+// buildUserListFilter validates query and resolves its cursor and role into a repository filter
+func (s *UserService) buildUserListFilter(ctx context.Context, query *models.ListUsersQuery, jwtToken string) (models.UserListFilter, error) {
+	filter := models.UserListFilter{
+		EmailPrefix:   strings.TrimSpace(query.Email),
+		NamePrefix:    strings.TrimSpace(query.Name),
+		Status:        query.Status,
+		CreatedAfter:  query.CreatedAfter,
+		CreatedBefore: query.CreatedBefore,
+		SortBy:        query.Sort,
+		Limit:         query.Limit,
+		Offset:        query.Offset,
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = 10
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+	if filter.Offset < 0 {
+		return filter, models.NewValidationError("offset", "offset must not be negative")
+	}
+
+	if filter.Status != "" && !models.IsValidUserStatus(filter.Status) {
+		return filter, models.NewValidationError("status", "unknown account status")
+	}
+
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil && !filter.CreatedAfter.Before(*filter.CreatedBefore) {
+		return filter, models.NewValidationError("created_after", "created_after must be before created_before")
+	}
+
+	if filter.SortBy == "" {
+		filter.SortBy = models.UserSortCreatedAt
+	}
+	if !models.IsValidUserSortField(filter.SortBy) {
+		return filter, models.NewValidationError("sort", "unsupported sort field")
+	}
+
+	switch strings.ToLower(query.Order) {
+	case "":
+		// Newest first for timestamps, alphabetical for names and email
+		filter.Descending = filter.SortBy == models.UserSortCreatedAt || filter.SortBy == models.UserSortUpdatedAt
+	case "asc":
+		filter.Descending = false
+	case "desc":
+		filter.Descending = true
+	default:
+		return filter, models.NewValidationError("order", "order must be asc or desc")
+	}
+
+	if query.Cursor != "" {
+		if filter.Offset > 0 {
+			return filter, models.NewValidationError("cursor", "cursor and offset cannot be combined")
+		}
+
+		cursor, err := decodeUserCursor(query.Cursor)
+		if err != nil {
+			return filter, models.NewValidationError("cursor", "invalid cursor")
+		}
+		if cursor.SortBy != filter.SortBy || cursor.Descending != filter.Descending {
+			return filter, models.NewValidationError("cursor", "cursor does not match the requested sort order")
+		}
+		filter.After = cursor
+	}
+
+	if query.Role != "" {
+		if s.roleLookup == nil {
+			return filter, models.NewInternalError("filtering users by role", fmt.Errorf("auth-service client is not configured"))
+		}
+		if jwtToken == "" {
+			return filter, models.NewForbiddenError("filtering by role requires an admin token")
+		}
+
+		ids, err := s.roleLookup.ListUserIDsByRole(ctx, query.Role, jwtToken)
+		if err != nil {
+			var forbidden models.ForbiddenError
+			if errors.As(err, &forbidden) {
+				return filter, forbidden
+			}
+			s.logger.WithError(err).Error("Failed to resolve role filter")
+			return filter, models.NewInternalError("resolving role filter", err)
+		}
+		filter.UserIDs = ids
+		if filter.UserIDs == nil {
+			filter.UserIDs = []uuid.UUID{}
+		}
+	}
+
+	return filter, nil
+}
+
+// userSortValue is the cursor representation of user's sort key
+func userSortValue(user *models.User, sortBy string) string {
+	switch sortBy {
+	case models.UserSortUpdatedAt:
+		return user.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case models.UserSortEmail:
+		return user.Email
+	case models.UserSortFirstName:
+		return user.FirstName
+	case models.UserSortLastName:
+		return user.LastName
+	default:
+		return user.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// encodeUserCursor serializes a keyset position into an opaque URL-safe token
+func encodeUserCursor(cursor *models.UserCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeUserCursor parses a token produced by encodeUserCursor
+func decodeUserCursor(token string) (*models.UserCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor models.UserCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.ID == uuid.Nil {
+		return nil, fmt.Errorf("cursor has no ID")
+	}
+	if cursor.SortBy == models.UserSortCreatedAt || cursor.SortBy == models.UserSortUpdatedAt {
+		if _, err := time.Parse(time.RFC3339Nano, cursor.Value); err != nil {
+			return nil, err
+		}
 	}
 
-	return responses, nil
+	return &cursor, nil
 }
 
 func (s *UserService) toResponse(user *models.User) *models.UserResponse {
 	return &models.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:              user.ID,
+		Email:           user.Email,
+		FirstName:       user.FirstName,
+		LastName:        user.LastName,
+		Status:          user.Status,
+		StatusReason:    user.StatusReason,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		PendingEmail:    user.PendingEmail,
+		DeletedAt:       user.DeletedAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+}
+
+// getUserForSelfService loads the user and rejects accounts that may not change their own data
+func (s *UserService) getUserForSelfService(ctx context.Context, id uuid.UUID, operation string) (*models.User, error) {
+	if id == uuid.Nil {
+		return nil, models.NewValidationError("id", "user ID is required")
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).Errorf("Failed to get user for %s", operation)
+
+		if strings.Contains(err.Error(), "not found") {
+			return nil, models.NewNotFoundError("User", "id", id.String())
+		}
+
+		return nil, models.NewInternalError("getting user for "+operation, err)
+	}
+
+	if user.Status == models.UserStatusSuspended || user.Status == models.UserStatusDeactivated {
+		return nil, models.NewForbiddenError(fmt.Sprintf("account is %s", user.Status))
+	}
+
+	return user, nil
+}
+
+// UpdateProfile updates the authenticated user's own name fields
+func (s *UserService) UpdateProfile(ctx context.Context, id uuid.UUID, req *models.UpdateProfileRequest) (*models.UserResponse, error) {
+	if req.FirstName == "" && req.LastName == "" {
+		return nil, models.NewValidationError("request", "at least one field must be provided for update")
 	}
+
+	// Name rules are the same as for the admin update
+	if err := s.validateUpdateUserRequest(&models.UpdateUserRequest{FirstName: req.FirstName, LastName: req.LastName}); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.getUserForSelfService(ctx, id, "profile update")
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FirstName != "" {
+		existing.FirstName = req.FirstName
+	}
+	if req.LastName != "" {
+		existing.LastName = req.LastName
+	}
+
+	updated, err := s.repo.Update(ctx, id, existing)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to update profile in repository")
+		return nil, models.NewInternalError("updating profile", err)
+	}
+
+	return s.toResponse(updated), nil
+}
+
+// RequestEmailChange records the new address as pending and sends it a verification token.
+// The email is only changed once ConfirmEmailChange is called with that token.
+func (s *UserService) RequestEmailChange(ctx context.Context, id uuid.UUID, req *models.ChangeEmailRequest) (*models.EmailChangeResponse, error) {
+	if _, err := mail.ParseAddress(req.NewEmail); err != nil {
+		return nil, models.NewValidationError("new_email", "invalid email format")
+	}
+
+	existing, err := s.getUserForSelfService(ctx, id, "email change")
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(existing.Email, req.NewEmail) {
+		return nil, models.NewValidationError("new_email", "new email must differ from the current email")
+	}
+
+	taken, err := s.repo.GetByEmail(ctx, req.NewEmail)
+	if err != nil {
+		errMsg := strings.ToLower(err.Error())
+		if !strings.Contains(errMsg, "no rows") && !strings.Contains(errMsg, "not found") {
+			s.logger.WithError(err).Error("Failed to check email availability")
+			return nil, models.NewInternalError("checking email availability", err)
+		}
+	}
+	if taken != nil {
+		return nil, models.NewConflictError("User", "email", req.NewEmail)
+	}
+
+	token, err := generateVerificationToken()
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to generate verification token")
+		return nil, models.NewInternalError("generating verification token", err)
+	}
+
+	expiresAt := time.Now().Add(emailVerificationTTL)
+	if err := s.repo.SetPendingEmail(ctx, id, req.NewEmail, hashVerificationToken(token), expiresAt); err != nil {
+		s.logger.WithError(err).Error("Failed to store pending email")
+		return nil, models.NewInternalError("storing pending email", err)
+	}
+
+	if err := s.verifier.SendEmailVerification(ctx, id, req.NewEmail, token); err != nil {
+		s.logger.WithError(err).Error("Failed to send email verification")
+		return nil, models.NewInternalError("sending email verification", err)
+	}
+
+	return &models.EmailChangeResponse{
+		PendingEmail: req.NewEmail,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// ConfirmEmailChange applies the pending email if the verification token is valid and unexpired
+func (s *UserService) ConfirmEmailChange(ctx context.Context, id uuid.UUID, req *models.ConfirmEmailChangeRequest) (*models.UserResponse, error) {
+	if req.Token == "" {
+		return nil, models.NewValidationError("token", "verification token is required")
+	}
+
+	if _, err := s.getUserForSelfService(ctx, id, "email confirmation"); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.ConfirmPendingEmail(ctx, id, hashVerificationToken(req.Token))
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to confirm email change in repository")
+
+		if strings.Contains(err.Error(), "verification token not found") {
+			return nil, models.NewValidationError("token", "invalid or expired verification token")
+		}
+		if strings.Contains(err.Error(), "duplicate key") ||
+			strings.Contains(err.Error(), "unique constraint") {
+			return nil, models.NewConflictError("User", "email", "pending email")
+		}
+
+		return nil, models.NewInternalError("confirming email change", err)
+	}
+
+	return s.toResponse(updated), nil
+}
+
+// SuspendUser blocks login and token refresh for the user
+func (s *UserService) SuspendUser(ctx context.Context, id uuid.UUID, reason string) (*models.UserResponse, error) {
+	return s.changeStatus(ctx, id, models.UserStatusSuspended, reason)
+}
+
+// ReactivateUser returns a suspended or deactivated user to active status
+func (s *UserService) ReactivateUser(ctx context.Context, id uuid.UUID) (*models.UserResponse, error) {
+	return s.changeStatus(ctx, id, models.UserStatusActive, "")
+}
+
+func (s *UserService) changeStatus(ctx context.Context, id uuid.UUID, status, reason string) (*models.UserResponse, error) {
+	if id == uuid.Nil {
+		return nil, models.NewValidationError("id", "user ID is required")
+	}
+
+	if len(reason) > 500 {
+		return nil, models.NewValidationError("reason", "reason must be less than 500 characters")
+	}
+
+	existing, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get user for status change")
+
+		if strings.Contains(err.Error(), "not found") {
+			return nil, models.NewNotFoundError("User", "id", id.String())
+		}
+
+		return nil, models.NewInternalError("getting user for status change", err)
+	}
+
+	if !models.CanTransitionStatus(existing.Status, status) {
+		return nil, models.NewValidationError("status",
+			fmt.Sprintf("cannot change status from '%s' to '%s'", existing.Status, status))
+	}
+
+	updated, err := s.repo.UpdateStatus(ctx, id, status, reason)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to update user status in repository")
+		return nil, models.NewInternalError("updating user status", err)
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"user_id":         id,
+		"previous_status": existing.Status,
+		"status":          status,
+	}).Info("User status changed")
+
+	return s.toResponse(updated), nil
+}
+
+// generateVerificationToken returns a random, URL-safe verification token
+func generateVerificationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashVerificationToken hashes a token for storage; only hashes are persisted
+func hashVerificationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
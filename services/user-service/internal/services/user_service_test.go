@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/v-egorov/service-boilerplate/services/user-service/internal/models"
@@ -55,14 +56,112 @@ func (m *MockUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) List(ctx context.Context, limit, offset int) ([]*models.User, error) {
-	args := m.Called(ctx, limit, offset)
+func (m *MockUserRepository) List(ctx context.Context, filter models.UserListFilter) ([]*models.User, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) Count(ctx context.Context, filter models.UserListFilter) (int, error) {
+	args := m.Called(ctx, filter)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status, reason string) (*models.User, error) {
+	args := m.Called(ctx, id, status, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) SetPendingEmail(ctx context.Context, id uuid.UUID, email, tokenHash string, expiresAt time.Time) error {
+	args := m.Called(ctx, id, email, tokenHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) ConfirmPendingEmail(ctx context.Context, id uuid.UUID, tokenHash string) (*models.User, error) {
+	args := m.Called(ctx, id, tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetByIDWithDeleted(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Restore(ctx context.Context, id uuid.UUID, deletedAfter time.Time) (*models.User, error) {
+	args := m.Called(ctx, id, deletedAfter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Erase(ctx context.Context, id uuid.UUID, anonymizedEmail string) (*models.User, error) {
+	args := m.Called(ctx, id, anonymizedEmail)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserRepository) ExistingEmails(ctx context.Context, emails []string) (map[string]bool, error) {
+	args := m.Called(ctx, emails)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockUserRepository) CreateBatch(ctx context.Context, users []*models.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
+// MockUserDataPurger is a testify mock for UserDataPurger
+type MockUserDataPurger struct {
+	mock.Mock
+}
+
+func (m *MockUserDataPurger) PurgeUserData(ctx context.Context, userID uuid.UUID, jwtToken string) error {
+	args := m.Called(ctx, userID, jwtToken)
+	return args.Error(0)
+}
+
+// MockUserRoleLookup is a testify mock for UserRoleLookup
+type MockUserRoleLookup struct {
+	mock.Mock
+}
+
+func (m *MockUserRoleLookup) ListUserIDsByRole(ctx context.Context, role, jwtToken string) ([]uuid.UUID, error) {
+	args := m.Called(ctx, role, jwtToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+// captureVerificationSender records the last token it was asked to deliver
+type captureVerificationSender struct {
+	email string
+	token string
+}
+
+func (s *captureVerificationSender) SendEmailVerification(ctx context.Context, userID uuid.UUID, email, token string) error {
+	s.email = email
+	s.token = token
+	return nil
+}
+
 func TestUserService_CreateUser(t *testing.T) {
 	tests := []struct {
 		name              string
@@ -833,49 +932,57 @@ func TestUserService_ListUsers(t *testing.T) {
 	}
 
 	tests := []struct {
-		name          string
-		limit         int
-		offset        int
-		mockUsers     []*models.User
-		mockError     error
-		expectError   bool
-		expectedCount int
+		name           string
+		query          models.ListUsersQuery
+		expectedFilter models.UserListFilter
+		mockUsers      []*models.User
+		mockTotal      int
+		mockError      error
+		expectError    bool
+		expectedCount  int
+		expectCursor   bool
 	}{
 		{
-			name:          "successful user listing",
-			limit:         10,
-			offset:        0,
-			mockUsers:     users,
-			mockError:     nil,
-			expectError:   false,
-			expectedCount: 2,
+			name:           "successful user listing",
+			query:          models.ListUsersQuery{Limit: 10},
+			expectedFilter: models.UserListFilter{SortBy: models.UserSortCreatedAt, Descending: true, Limit: 10},
+			mockUsers:      users,
+			mockTotal:      2,
+			expectedCount:  2,
 		},
 		{
-			name:          "empty user list",
-			limit:         10,
-			offset:        0,
-			mockUsers:     []*models.User{},
-			mockError:     nil,
-			expectError:   false,
-			expectedCount: 0,
+			name:           "empty user list",
+			query:          models.ListUsersQuery{Limit: 10},
+			expectedFilter: models.UserListFilter{SortBy: models.UserSortCreatedAt, Descending: true, Limit: 10},
+			mockUsers:      []*models.User{},
+			expectedCount:  0,
 		},
 		{
-			name:          "with pagination",
-			limit:         1,
-			offset:        1,
-			mockUsers:     []*models.User{users[1]},
-			mockError:     nil,
-			expectError:   false,
-			expectedCount: 1,
+			name:           "with pagination",
+			query:          models.ListUsersQuery{Limit: 1, Offset: 1},
+			expectedFilter: models.UserListFilter{SortBy: models.UserSortCreatedAt, Descending: true, Limit: 1, Offset: 1},
+			mockUsers:      []*models.User{users[1]},
+			mockTotal:      2,
+			expectedCount:  1,
+			expectCursor:   true,
+		},
+		{
+			name:  "filters and text sort default to ascending",
+			query: models.ListUsersQuery{Email: " user ", Name: "Us", Status: models.UserStatusActive, Sort: models.UserSortEmail, Limit: 500},
+			expectedFilter: models.UserListFilter{
+				EmailPrefix: "user", NamePrefix: "Us", Status: models.UserStatusActive,
+				SortBy: models.UserSortEmail, Limit: 100,
+			},
+			mockUsers:     users,
+			mockTotal:     2,
+			expectedCount: 2,
 		},
 		{
-			name:          "database error",
-			limit:         10,
-			offset:        0,
-			mockUsers:     nil,
-			mockError:     errors.New("database connection failed"),
-			expectError:   true,
-			expectedCount: 0,
+			name:           "database error",
+			query:          models.ListUsersQuery{Limit: 10},
+			expectedFilter: models.UserListFilter{SortBy: models.UserSortCreatedAt, Descending: true, Limit: 10},
+			mockError:      errors.New("database connection failed"),
+			expectError:    true,
 		},
 	}
 
@@ -889,10 +996,16 @@ func TestUserService_ListUsers(t *testing.T) {
 			service := NewUserServiceWithInterface(mockRepo, logger)
 
 			// Setup expectations
-			mockRepo.On("List", mock.Anything, tt.limit, tt.offset).Return(tt.mockUsers, tt.mockError).Once()
+			if tt.mockError != nil {
+				mockRepo.On("List", mock.Anything, tt.expectedFilter).Return(nil, tt.mockError).Once()
+			} else {
+				mockRepo.On("List", mock.Anything, tt.expectedFilter).Return(tt.mockUsers, nil).Once()
+				mockRepo.On("Count", mock.Anything, tt.expectedFilter).Return(tt.mockTotal, nil).Once()
+			}
 
 			// Execute
-			result, err := service.ListUsers(context.Background(), tt.limit, tt.offset)
+			query := tt.query
+			result, err := service.ListUsers(context.Background(), &query, "")
 
 			// Assert
 			if tt.expectError {
@@ -900,10 +1013,12 @@ func TestUserService_ListUsers(t *testing.T) {
 				assert.Nil(t, result)
 			} else {
 				assert.NoError(t, err)
-				assert.Len(t, result, tt.expectedCount)
+				assert.Len(t, result.Users, tt.expectedCount)
+				assert.Equal(t, tt.mockTotal, result.Total)
+				assert.Equal(t, tt.expectCursor, result.NextCursor != "")
 				if tt.expectedCount > 0 {
-					assert.Equal(t, tt.mockUsers[0].ID, result[0].ID)
-					assert.Equal(t, tt.mockUsers[0].Email, result[0].Email)
+					assert.Equal(t, tt.mockUsers[0].ID, result.Users[0].ID)
+					assert.Equal(t, tt.mockUsers[0].Email, result.Users[0].Email)
 				}
 			}
 
@@ -912,3 +1027,503 @@ func TestUserService_ListUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_ListUsers_Cursor(t *testing.T) {
+	last := &models.User{ID: uuid.New(), Email: "b@example.com", CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)}
+
+	mockRepo := &MockUserRepository{}
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	service := NewUserServiceWithInterface(mockRepo, logger)
+
+	firstPage := models.UserListFilter{SortBy: models.UserSortCreatedAt, Descending: true, Limit: 1}
+	mockRepo.On("List", mock.Anything, firstPage).Return([]*models.User{last}, nil).Once()
+	mockRepo.On("Count", mock.Anything, firstPage).Return(3, nil).Once()
+
+	result, err := service.ListUsers(context.Background(), &models.ListUsersQuery{Limit: 1}, "")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, result.NextCursor)
+
+	// The cursor resumes after the last user of the previous page
+	secondPage := firstPage
+	secondPage.After = &models.UserCursor{
+		SortBy:     models.UserSortCreatedAt,
+		Descending: true,
+		Value:      "2024-05-01T12:00:00.123456Z",
+		ID:         last.ID,
+	}
+	mockRepo.On("List", mock.Anything, secondPage).Return([]*models.User{}, nil).Once()
+	mockRepo.On("Count", mock.Anything, secondPage).Return(3, nil).Once()
+
+	result, err = service.ListUsers(context.Background(), &models.ListUsersQuery{Limit: 1, Cursor: result.NextCursor}, "")
+	assert.NoError(t, err)
+	assert.Empty(t, result.NextCursor)
+	mockRepo.AssertExpectations(t)
+
+	// A cursor cannot be reused with a different sort
+	_, err = service.ListUsers(context.Background(), &models.ListUsersQuery{Limit: 1, Cursor: encodeUserCursor(secondPage.After), Sort: models.UserSortEmail}, "")
+	assert.IsType(t, models.ValidationError{}, err)
+}
+
+func TestUserService_ListUsers_Validation(t *testing.T) {
+	now := time.Now()
+	validCursor := encodeUserCursor(&models.UserCursor{SortBy: models.UserSortCreatedAt, Descending: true, Value: now.Format(time.RFC3339Nano), ID: uuid.New()})
+
+	tests := []struct {
+		name          string
+		query         models.ListUsersQuery
+		expectedField string
+	}{
+		{name: "unknown status", query: models.ListUsersQuery{Status: "banned"}, expectedField: "status"},
+		{name: "unknown sort field", query: models.ListUsersQuery{Sort: "password_hash"}, expectedField: "sort"},
+		{name: "unknown order", query: models.ListUsersQuery{Order: "sideways"}, expectedField: "order"},
+		{name: "inverted created range", query: models.ListUsersQuery{CreatedAfter: &now, CreatedBefore: &now}, expectedField: "created_after"},
+		{name: "malformed cursor", query: models.ListUsersQuery{Cursor: "not-a-cursor"}, expectedField: "cursor"},
+		{name: "cursor with offset", query: models.ListUsersQuery{Cursor: validCursor, Offset: 10}, expectedField: "cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockUserRepository{}
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+			service := NewUserServiceWithInterface(mockRepo, logger)
+
+			query := tt.query
+			result, err := service.ListUsers(context.Background(), &query, "")
+
+			assert.Nil(t, result)
+			var validationErr models.ValidationError
+			if assert.ErrorAs(t, err, &validationErr) {
+				assert.Equal(t, tt.expectedField, validationErr.Field)
+			}
+			mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUserService_ListUsers_RoleFilter(t *testing.T) {
+	memberID := uuid.New()
+
+	t.Run("role resolves to user IDs", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		lookup := &MockUserRoleLookup{}
+		logger := logrus.New()
+		logger.SetLevel(logrus.ErrorLevel)
+		service := NewUserServiceWithInterface(mockRepo, logger)
+		service.SetUserRoleLookup(lookup)
+
+		expected := models.UserListFilter{SortBy: models.UserSortCreatedAt, Descending: true, Limit: 10, UserIDs: []uuid.UUID{memberID}}
+		lookup.On("ListUserIDsByRole", mock.Anything, "editor", "admin-token").Return([]uuid.UUID{memberID}, nil).Once()
+		mockRepo.On("List", mock.Anything, expected).Return([]*models.User{}, nil).Once()
+		mockRepo.On("Count", mock.Anything, expected).Return(0, nil).Once()
+
+		_, err := service.ListUsers(context.Background(), &models.ListUsersQuery{Limit: 10, Role: "editor"}, "admin-token")
+		assert.NoError(t, err)
+		lookup.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("role with no members matches nobody", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		lookup := &MockUserRoleLookup{}
+		logger := logrus.New()
+		logger.SetLevel(logrus.ErrorLevel)
+		service := NewUserServiceWithInterface(mockRepo, logger)
+		service.SetUserRoleLookup(lookup)
+
+		expected := models.UserListFilter{SortBy: models.UserSortCreatedAt, Descending: true, Limit: 10, UserIDs: []uuid.UUID{}}
+		lookup.On("ListUserIDsByRole", mock.Anything, "ghost", "admin-token").Return(nil, nil).Once()
+		mockRepo.On("List", mock.Anything, expected).Return([]*models.User{}, nil).Once()
+		mockRepo.On("Count", mock.Anything, expected).Return(0, nil).Once()
+
+		_, err := service.ListUsers(context.Background(), &models.ListUsersQuery{Limit: 10, Role: "ghost"}, "admin-token")
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("role filter without token is forbidden", func(t *testing.T) {
+		logger := logrus.New()
+		logger.SetLevel(logrus.ErrorLevel)
+		service := NewUserServiceWithInterface(&MockUserRepository{}, logger)
+		service.SetUserRoleLookup(&MockUserRoleLookup{})
+
+		_, err := service.ListUsers(context.Background(), &models.ListUsersQuery{Role: "editor"}, "")
+		assert.IsType(t, models.ForbiddenError{}, err)
+	})
+
+	t.Run("auth-service rejection is forbidden", func(t *testing.T) {
+		lookup := &MockUserRoleLookup{}
+		logger := logrus.New()
+		logger.SetLevel(logrus.ErrorLevel)
+		service := NewUserServiceWithInterface(&MockUserRepository{}, logger)
+		service.SetUserRoleLookup(lookup)
+
+		lookup.On("ListUserIDsByRole", mock.Anything, "editor", "user-token").Return(nil, models.NewForbiddenError("filtering by role requires the admin role")).Once()
+
+		_, err := service.ListUsers(context.Background(), &models.ListUsersQuery{Role: "editor"}, "user-token")
+		assert.IsType(t, models.ForbiddenError{}, err)
+	})
+}
+
+func TestUserService_SuspendAndReactivateUser(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name              string
+		currentStatus     string
+		suspend           bool
+		expectUpdate      bool
+		expectedErrorType string
+	}{
+		{name: "suspend active user", currentStatus: models.UserStatusActive, suspend: true, expectUpdate: true},
+		{name: "suspend already suspended user", currentStatus: models.UserStatusSuspended, suspend: true, expectedErrorType: "validation"},
+		{name: "suspend deactivated user", currentStatus: models.UserStatusDeactivated, suspend: true, expectedErrorType: "validation"},
+		{name: "reactivate suspended user", currentStatus: models.UserStatusSuspended, expectUpdate: true},
+		{name: "reactivate active user", currentStatus: models.UserStatusActive, expectedErrorType: "validation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockUserRepository{}
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+
+			service := NewUserServiceWithInterface(mockRepo, logger)
+
+			mockRepo.On("GetByID", mock.Anything, userID).Return(&models.User{ID: userID, Status: tt.currentStatus}, nil).Once()
+
+			newStatus, reason := models.UserStatusActive, ""
+			if tt.suspend {
+				newStatus, reason = models.UserStatusSuspended, "policy violation"
+			}
+			if tt.expectUpdate {
+				mockRepo.On("UpdateStatus", mock.Anything, userID, newStatus, reason).
+					Return(&models.User{ID: userID, Status: newStatus}, nil).Once()
+			}
+
+			var result *models.UserResponse
+			var err error
+			if tt.suspend {
+				result, err = service.SuspendUser(context.Background(), userID, reason)
+			} else {
+				result, err = service.ReactivateUser(context.Background(), userID)
+			}
+
+			if tt.expectedErrorType != "" {
+				assert.Error(t, err)
+				assert.Nil(t, result)
+				assert.IsType(t, models.ValidationError{}, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, newStatus, result.Status)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_UpdateProfile(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("suspended user cannot update profile", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		logger := logrus.New()
+		logger.SetLevel(logrus.ErrorLevel)
+		service := NewUserServiceWithInterface(mockRepo, logger)
+
+		mockRepo.On("GetByID", mock.Anything, userID).
+			Return(&models.User{ID: userID, Status: models.UserStatusSuspended}, nil).Once()
+
+		result, err := service.UpdateProfile(context.Background(), userID, &models.UpdateProfileRequest{FirstName: "Jane"})
+
+		assert.Nil(t, result)
+		assert.IsType(t, models.ForbiddenError{}, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("active user updates first name", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		logger := logrus.New()
+		logger.SetLevel(logrus.ErrorLevel)
+		service := NewUserServiceWithInterface(mockRepo, logger)
+
+		existing := &models.User{ID: userID, Email: "john@example.com", FirstName: "John", LastName: "Doe", Status: models.UserStatusActive}
+		mockRepo.On("GetByID", mock.Anything, userID).Return(existing, nil).Once()
+		mockRepo.On("Update", mock.Anything, userID, mock.MatchedBy(func(u *models.User) bool {
+			return u.FirstName == "Jane" && u.LastName == "Doe" && u.Email == "john@example.com"
+		})).Return(&models.User{ID: userID, Email: "john@example.com", FirstName: "Jane", LastName: "Doe", Status: models.UserStatusActive}, nil).Once()
+
+		result, err := service.UpdateProfile(context.Background(), userID, &models.UpdateProfileRequest{FirstName: "Jane"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Jane", result.FirstName)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserService_EmailChange(t *testing.T) {
+	userID := uuid.New()
+	active := &models.User{ID: userID, Email: "john@example.com", Status: models.UserStatusActive}
+
+	t.Run("request stores token hash and sends raw token", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		logger := logrus.New()
+		logger.SetLevel(logrus.ErrorLevel)
+		service := NewUserServiceWithInterface(mockRepo, logger)
+		sender := &captureVerificationSender{}
+		service.SetEmailVerificationSender(sender)
+
+		mockRepo.On("GetByID", mock.Anything, userID).Return(active, nil).Once()
+		mockRepo.On("GetByEmail", mock.Anything, "new@example.com").Return(nil, errors.New("user not found")).Once()
+		mockRepo.On("SetPendingEmail", mock.Anything, userID, "new@example.com", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+			Return(nil).Once()
+
+		result, err := service.RequestEmailChange(context.Background(), userID, &models.ChangeEmailRequest{NewEmail: "new@example.com"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "new@example.com", result.PendingEmail)
+		assert.Equal(t, "new@example.com", sender.email)
+		assert.NotEmpty(t, sender.token)
+
+		storedHash := mockRepo.Calls[2].Arguments.String(3)
+		assert.Equal(t, hashVerificationToken(sender.token), storedHash)
+		assert.NotEqual(t, sender.token, storedHash)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("default sender never logs the token", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		logger, hook := test.NewNullLogger()
+		service := NewUserServiceWithInterface(mockRepo, logger)
+
+		mockRepo.On("GetByID", mock.Anything, userID).Return(active, nil).Once()
+		mockRepo.On("GetByEmail", mock.Anything, "new@example.com").Return(nil, errors.New("user not found")).Once()
+		mockRepo.On("SetPendingEmail", mock.Anything, userID, "new@example.com", mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+			Return(nil).Once()
+
+		_, err := service.RequestEmailChange(context.Background(), userID, &models.ChangeEmailRequest{NewEmail: "new@example.com"})
+
+		assert.NoError(t, err)
+		storedHash := mockRepo.Calls[2].Arguments.String(3)
+		for _, entry := range hook.AllEntries() {
+			assert.NotContains(t, entry.Data, "verification_token")
+			for _, value := range entry.Data {
+				if token, ok := value.(string); ok {
+					assert.NotEqual(t, storedHash, hashVerificationToken(token))
+				}
+			}
+		}
+		assert.Equal(t, "new@example.com", hook.LastEntry().Data["email"])
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("request rejects address used by another user", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		logger := logrus.New()
+		logger.SetLevel(logrus.ErrorLevel)
+		service := NewUserServiceWithInterface(mockRepo, logger)
+
+		mockRepo.On("GetByID", mock.Anything, userID).Return(active, nil).Once()
+		mockRepo.On("GetByEmail", mock.Anything, "taken@example.com").Return(&models.User{ID: uuid.New()}, nil).Once()
+
+		result, err := service.RequestEmailChange(context.Background(), userID, &models.ChangeEmailRequest{NewEmail: "taken@example.com"})
+
+		assert.Nil(t, result)
+		assert.IsType(t, models.ConflictError{}, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("confirm with invalid token", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		logger := logrus.New()
+		logger.SetLevel(logrus.ErrorLevel)
+		service := NewUserServiceWithInterface(mockRepo, logger)
+
+		mockRepo.On("GetByID", mock.Anything, userID).Return(active, nil).Once()
+		mockRepo.On("ConfirmPendingEmail", mock.Anything, userID, hashVerificationToken("bad-token")).
+			Return(nil, errors.New("verification token not found")).Once()
+
+		result, err := service.ConfirmEmailChange(context.Background(), userID, &models.ConfirmEmailChangeRequest{Token: "bad-token"})
+
+		assert.Nil(t, result)
+		assert.IsType(t, models.ValidationError{}, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserService_RestoreUser(t *testing.T) {
+	userID := uuid.New()
+	recent := time.Now().Add(-24 * time.Hour)
+	expired := time.Now().Add(-60 * 24 * time.Hour)
+
+	tests := []struct {
+		name              string
+		existing          *models.User
+		expectRestore     bool
+		restoreErr        error
+		expectedErrorType string
+	}{
+		{
+			name:          "restore within window",
+			existing:      &models.User{ID: userID, Email: "john@example.com", DeletedAt: &recent},
+			expectRestore: true,
+		},
+		{
+			name:              "restore window expired",
+			existing:          &models.User{ID: userID, DeletedAt: &expired},
+			expectedErrorType: "validation",
+		},
+		{
+			name:              "user not deleted",
+			existing:          &models.User{ID: userID},
+			expectedErrorType: "validation",
+		},
+		{
+			name:              "erased user",
+			existing:          &models.User{ID: userID, DeletedAt: &recent, ErasedAt: &recent},
+			expectedErrorType: "validation",
+		},
+		{
+			name:              "email taken by another account",
+			existing:          &models.User{ID: userID, Email: "john@example.com", DeletedAt: &recent},
+			expectRestore:     true,
+			restoreErr:        errors.New("duplicate key value violates unique constraint"),
+			expectedErrorType: "conflict",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &MockUserRepository{}
+			logger := logrus.New()
+			logger.SetLevel(logrus.ErrorLevel)
+
+			service := NewUserServiceWithInterface(mockRepo, logger)
+
+			mockRepo.On("GetByIDWithDeleted", mock.Anything, userID).Return(tt.existing, nil).Once()
+			if tt.expectRestore {
+				if tt.restoreErr != nil {
+					mockRepo.On("Restore", mock.Anything, userID, mock.AnythingOfType("time.Time")).Return(nil, tt.restoreErr).Once()
+				} else {
+					mockRepo.On("Restore", mock.Anything, userID, mock.AnythingOfType("time.Time")).
+						Return(&models.User{ID: userID, Email: "john@example.com", Status: models.UserStatusActive}, nil).Once()
+				}
+			}
+
+			result, err := service.RestoreUser(context.Background(), userID)
+
+			switch tt.expectedErrorType {
+			case "":
+				assert.NoError(t, err)
+				assert.Equal(t, userID, result.ID)
+				assert.Nil(t, result.DeletedAt)
+			case "validation":
+				assert.IsType(t, models.ValidationError{}, err)
+			case "conflict":
+				assert.IsType(t, models.ConflictError{}, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserService_EraseUser(t *testing.T) {
+	userID := uuid.New()
+	anonymized := "erased-" + userID.String() + "@erased.invalid"
+
+	newService := func(mockRepo *MockUserRepository, purger *MockUserDataPurger) *UserService {
+		logger := logrus.New()
+		logger.SetLevel(logrus.ErrorLevel)
+		service := NewUserServiceWithInterface(mockRepo, logger)
+		service.SetUserDataPurger(purger)
+		service.purgeRetry = 0
+		return service
+	}
+
+	t.Run("anonymizes then purges auth data", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		purger := &MockUserDataPurger{}
+		service := newService(mockRepo, purger)
+
+		var order []string
+		mockRepo.On("GetByIDWithDeleted", mock.Anything, userID).Return(&models.User{ID: userID}, nil).Once()
+		mockRepo.On("Erase", mock.Anything, userID, anonymized).
+			Run(func(mock.Arguments) { order = append(order, "erase") }).
+			Return(&models.User{ID: userID}, nil).Once()
+		purger.On("PurgeUserData", mock.Anything, userID, "admin-token").
+			Run(func(mock.Arguments) { order = append(order, "purge") }).
+			Return(nil).Once()
+
+		err := service.EraseUser(context.Background(), userID, "admin-token")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"erase", "purge"}, order)
+		mockRepo.AssertExpectations(t)
+		purger.AssertExpectations(t)
+	})
+
+	t.Run("transient auth-service failure is retried", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		purger := &MockUserDataPurger{}
+		service := newService(mockRepo, purger)
+
+		mockRepo.On("GetByIDWithDeleted", mock.Anything, userID).Return(&models.User{ID: userID}, nil).Once()
+		mockRepo.On("Erase", mock.Anything, userID, anonymized).Return(&models.User{ID: userID}, nil).Once()
+		purger.On("PurgeUserData", mock.Anything, userID, "admin-token").Return(errors.New("auth-service returned status 503")).Twice()
+		purger.On("PurgeUserData", mock.Anything, userID, "admin-token").Return(nil).Once()
+
+		err := service.EraseUser(context.Background(), userID, "admin-token")
+
+		assert.NoError(t, err)
+		purger.AssertNumberOfCalls(t, "PurgeUserData", 3)
+	})
+
+	t.Run("persistent auth-service failure still anonymizes", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		purger := &MockUserDataPurger{}
+		service := newService(mockRepo, purger)
+
+		mockRepo.On("GetByIDWithDeleted", mock.Anything, userID).Return(&models.User{ID: userID}, nil).Once()
+		mockRepo.On("Erase", mock.Anything, userID, anonymized).Return(&models.User{ID: userID}, nil).Once()
+		purger.On("PurgeUserData", mock.Anything, userID, "admin-token").Return(errors.New("auth-service returned status 503"))
+
+		err := service.EraseUser(context.Background(), userID, "admin-token")
+
+		assert.IsType(t, models.InternalError{}, err)
+		purger.AssertNumberOfCalls(t, "PurgeUserData", purgeAttempts)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("repository failure skips the purge", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		purger := &MockUserDataPurger{}
+		service := newService(mockRepo, purger)
+
+		mockRepo.On("GetByIDWithDeleted", mock.Anything, userID).Return(&models.User{ID: userID}, nil).Once()
+		mockRepo.On("Erase", mock.Anything, userID, anonymized).Return(nil, errors.New("failed to erase user: connection reset")).Once()
+
+		err := service.EraseUser(context.Background(), userID, "admin-token")
+
+		assert.IsType(t, models.InternalError{}, err)
+		purger.AssertNotCalled(t, "PurgeUserData", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("already erased re-runs the purge", func(t *testing.T) {
+		mockRepo := &MockUserRepository{}
+		purger := &MockUserDataPurger{}
+		service := newService(mockRepo, purger)
+
+		erasedAt := time.Now()
+		mockRepo.On("GetByIDWithDeleted", mock.Anything, userID).Return(&models.User{ID: userID, ErasedAt: &erasedAt}, nil).Once()
+		purger.On("PurgeUserData", mock.Anything, userID, "admin-token").Return(nil).Once()
+
+		err := service.EraseUser(context.Background(), userID, "admin-token")
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "Erase", mock.Anything, mock.Anything, mock.Anything)
+		purger.AssertExpectations(t)
+	})
+}
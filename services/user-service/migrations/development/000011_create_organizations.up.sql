@@ -0,0 +1,56 @@
+-- Environment: all
+-- Migration: 000011_create_organizations
+-- Description: Organizations, teams and membership with membership roles
+
+CREATE TABLE IF NOT EXISTS user_service.organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_service.teams (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES user_service.organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE(organization_id, name),
+    -- Target of the team_members composite key below
+    UNIQUE(id, organization_id)
+);
+
+CREATE TABLE IF NOT EXISTS user_service.organization_members (
+    organization_id UUID NOT NULL REFERENCES user_service.organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES user_service.users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'admin', 'member')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (organization_id, user_id)
+);
+
+-- Team members must belong to the team's organization; leaving the organization removes them from its teams
+CREATE TABLE IF NOT EXISTS user_service.team_members (
+    team_id UUID NOT NULL,
+    organization_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'member' CHECK (role IN ('maintainer', 'member')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (team_id, user_id),
+    CONSTRAINT team_members_team_fkey FOREIGN KEY (team_id, organization_id)
+        REFERENCES user_service.teams(id, organization_id) ON DELETE CASCADE,
+    CONSTRAINT team_members_org_member_fkey FOREIGN KEY (organization_id, user_id)
+        REFERENCES user_service.organization_members(organization_id, user_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON user_service.organization_members(user_id);
+CREATE INDEX IF NOT EXISTS idx_team_members_user_id ON user_service.team_members(user_id);
+CREATE INDEX IF NOT EXISTS idx_team_members_org_user ON user_service.team_members(organization_id, user_id);
+
+COMMENT ON TABLE user_service.organizations IS 'Tenants grouping users and teams';
+COMMENT ON TABLE user_service.team_members IS 'Team membership; auth-service resolves team-assigned roles from it';
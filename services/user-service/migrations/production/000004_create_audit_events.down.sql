@@ -0,0 +1,6 @@
+-- Environment: all
+-- Migration Rollback: 000004_create_audit_events
+-- Description: Remove audit trail table from user_service
+
+DROP TABLE IF EXISTS user_service.audit_events CASCADE;
+DROP FUNCTION IF EXISTS user_service.audit_events_append_only();
@@ -0,0 +1,50 @@
+-- Environment: all
+-- Migration: 000004_create_audit_events
+-- Description: Append-only, hash-chained audit trail for user_service
+
+CREATE TABLE IF NOT EXISTS user_service.audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    service VARCHAR(100) NOT NULL,
+    user_id VARCHAR(255),
+    entity_id VARCHAR(255),
+    resource VARCHAR(100),
+    action VARCHAR(100) NOT NULL,
+    result VARCHAR(50) NOT NULL,
+    request_id VARCHAR(255),
+    trace_id VARCHAR(64),
+    payload TEXT NOT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes for the admin query API
+CREATE INDEX IF NOT EXISTS idx_audit_events_user_id ON user_service.audit_events(user_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_entity_id ON user_service.audit_events(entity_id, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_event_type ON user_service.audit_events(event_type, occurred_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON user_service.audit_events(occurred_at DESC);
+
+-- Reject any modification of stored events
+CREATE OR REPLACE FUNCTION user_service.audit_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only: % is not allowed', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_no_update_delete ON user_service.audit_events;
+CREATE TRIGGER audit_events_no_update_delete
+    BEFORE UPDATE OR DELETE ON user_service.audit_events
+    FOR EACH ROW EXECUTE FUNCTION user_service.audit_events_append_only();
+
+DROP TRIGGER IF EXISTS audit_events_no_truncate ON user_service.audit_events;
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON user_service.audit_events
+    FOR EACH STATEMENT EXECUTE FUNCTION user_service.audit_events_append_only();
+
+COMMENT ON TABLE user_service.audit_events IS 'Append-only audit trail; each row is chained to the previous one by hash';
+COMMENT ON COLUMN user_service.audit_events.payload IS 'Canonical JSON of the audit event, exactly as hashed';
+COMMENT ON COLUMN user_service.audit_events.prev_hash IS 'Hash of the preceding event (64 zeros for the first event)';
+COMMENT ON COLUMN user_service.audit_events.hash IS 'sha256(prev_hash || payload), hex encoded';
@@ -0,0 +1,8 @@
+-- Environment: all
+-- Migration Rollback: 000007_add_user_list_indexes
+-- Description: Drop user listing keyset indexes
+
+DROP INDEX IF EXISTS user_service.idx_users_list_first_name;
+DROP INDEX IF EXISTS user_service.idx_users_list_last_name;
+DROP INDEX IF EXISTS user_service.idx_users_list_updated_at;
+DROP INDEX IF EXISTS user_service.idx_users_list_created_at;
@@ -0,0 +1,13 @@
+-- Environment: all
+-- Migration: 000007_add_user_list_indexes
+-- Description: Keyset indexes for sorted, cursor-paginated user listing
+
+-- Each listing sort orders by (column, id) over live users; the id tie-breaker keeps cursors stable
+CREATE INDEX IF NOT EXISTS idx_users_list_created_at
+    ON user_service.users(created_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_list_updated_at
+    ON user_service.users(updated_at, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_list_last_name
+    ON user_service.users(last_name, id) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_list_first_name
+    ON user_service.users(first_name, id) WHERE deleted_at IS NULL;
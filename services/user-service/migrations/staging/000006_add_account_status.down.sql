@@ -0,0 +1,16 @@
+-- Environment: all
+-- Migration Rollback: 000006_add_account_status
+-- Description: Remove account status and pending email columns
+
+DROP INDEX IF EXISTS user_service.idx_users_status;
+
+ALTER TABLE user_service.users DROP CONSTRAINT IF EXISTS users_status_check;
+
+ALTER TABLE user_service.users
+    DROP COLUMN IF EXISTS email_verification_expires_at,
+    DROP COLUMN IF EXISTS email_verification_token_hash,
+    DROP COLUMN IF EXISTS pending_email,
+    DROP COLUMN IF EXISTS email_verified_at,
+    DROP COLUMN IF EXISTS status_changed_at,
+    DROP COLUMN IF EXISTS status_reason,
+    DROP COLUMN IF EXISTS status;
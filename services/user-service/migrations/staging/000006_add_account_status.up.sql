@@ -0,0 +1,21 @@
+-- Environment: all
+-- Migration: 000006_add_account_status
+-- Description: Account status lifecycle and verified self-service email changes
+
+ALTER TABLE user_service.users
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS status_reason TEXT,
+    ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS pending_email VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS email_verification_token_hash CHAR(64),
+    ADD COLUMN IF NOT EXISTS email_verification_expires_at TIMESTAMP WITH TIME ZONE;
+
+ALTER TABLE user_service.users DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE user_service.users
+    ADD CONSTRAINT users_status_check CHECK (status IN ('pending', 'active', 'suspended', 'deactivated'));
+
+CREATE INDEX IF NOT EXISTS idx_users_status ON user_service.users(status);
+
+COMMENT ON COLUMN user_service.users.status IS 'Account status: pending, active, suspended or deactivated';
+COMMENT ON COLUMN user_service.users.email_verification_token_hash IS 'sha256 of the pending email verification token, hex encoded';
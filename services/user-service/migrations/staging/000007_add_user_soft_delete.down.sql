@@ -0,0 +1,14 @@
+-- Environment: all
+-- Migration Rollback: 000007_add_user_soft_delete
+-- Description: Remove soft delete columns and restore the global email uniqueness
+
+-- Soft-deleted rows would otherwise resurface as live users
+DELETE FROM user_service.users WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS user_service.idx_users_deleted_at;
+DROP INDEX IF EXISTS user_service.idx_users_email_active;
+ALTER TABLE user_service.users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+ALTER TABLE user_service.users
+    DROP COLUMN IF EXISTS erased_at,
+    DROP COLUMN IF EXISTS deleted_at;
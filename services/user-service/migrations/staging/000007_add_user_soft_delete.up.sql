@@ -0,0 +1,18 @@
+-- Environment: all
+-- Migration: 000007_add_user_soft_delete
+-- Description: Soft delete with restore window and GDPR-style erasure for users
+
+ALTER TABLE user_service.users
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS erased_at TIMESTAMP WITH TIME ZONE;
+
+-- Email only has to be unique among live users so a deleted user's address can be reused
+ALTER TABLE user_service.users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_active
+    ON user_service.users(email) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at
+    ON user_service.users(deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMENT ON COLUMN user_service.users.deleted_at IS 'Soft delete timestamp; the user can be restored within the configured window';
+COMMENT ON COLUMN user_service.users.erased_at IS 'Set when personal data was irreversibly anonymized';
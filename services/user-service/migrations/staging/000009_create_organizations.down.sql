@@ -0,0 +1,8 @@
+-- Environment: all
+-- Migration Rollback: 000009_create_organizations
+-- Description: Drop organizations, teams and membership tables
+
+DROP TABLE IF EXISTS user_service.team_members;
+DROP TABLE IF EXISTS user_service.organization_members;
+DROP TABLE IF EXISTS user_service.teams;
+DROP TABLE IF EXISTS user_service.organizations;